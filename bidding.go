@@ -0,0 +1,1016 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/primev/preconf_blob_bidder/internal/accounting"
+	"github.com/primev/preconf_blob_bidder/internal/backfill"
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"github.com/primev/preconf_blob_bidder/internal/campaign"
+	"github.com/primev/preconf_blob_bidder/internal/competition"
+	"github.com/primev/preconf_blob_bidder/internal/dashboard"
+	"github.com/primev/preconf_blob_bidder/internal/dispute"
+	errs "github.com/primev/preconf_blob_bidder/internal/errors"
+	ee "github.com/primev/preconf_blob_bidder/internal/eth"
+	"github.com/primev/preconf_blob_bidder/internal/feedback"
+	"github.com/primev/preconf_blob_bidder/internal/ident"
+	"github.com/primev/preconf_blob_bidder/internal/inclusion"
+	"github.com/primev/preconf_blob_bidder/internal/labels"
+	"github.com/primev/preconf_blob_bidder/internal/lanes"
+	"github.com/primev/preconf_blob_bidder/internal/latency"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+	"github.com/primev/preconf_blob_bidder/internal/outcome"
+	"github.com/primev/preconf_blob_bidder/internal/provenance"
+	"github.com/primev/preconf_blob_bidder/internal/reconcile"
+	"github.com/primev/preconf_blob_bidder/internal/schedule"
+	"github.com/primev/preconf_blob_bidder/internal/skips"
+	"github.com/primev/preconf_blob_bidder/internal/status"
+	"github.com/primev/preconf_blob_bidder/internal/store"
+	"github.com/primev/preconf_blob_bidder/internal/strategy"
+	"github.com/primev/preconf_blob_bidder/internal/tracing"
+	"github.com/primev/preconf_blob_bidder/internal/tui"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// bidDispatcher sends bids and, depending on the payload privacy mode, submits
+// the transaction to the builder endpoint. With the preconf RPC backend it
+// only submits the transaction to the preconf RPC, which bids for it. In
+// dry-run mode nothing is sent; what would be sent is logged instead.
+type bidDispatcher struct {
+	bidder  bb.BidderInterface
+	privacy bb.PayloadPrivacy
+	hints   ee.BundleHints
+	backend bb.SubmissionBackend
+	dryRun  bool
+	labels  *labels.Source
+
+	// replacement and validity add a replacementUuid and timestamp bounds to
+	// every bundle
+	replacement bool
+	validity    time.Duration
+
+	// Bundles go to the RPC endpoint, named ee.DefaultRelay, and relays,
+	// except those disabled
+	relays   []ee.Relay
+	disabled map[string]bool
+
+	// method is how transactions are submitted; private transactions carry
+	// preferences and are valid up to their target block
+	method      ee.SubmitMethod
+	preferences map[string]interface{}
+
+	// The endpoints can change on a configuration reload
+	mu          sync.RWMutex
+	rpcEndpoint string
+	preconfRPC  string
+}
+
+// endpoints returns the endpoints bundles and preconf RPC submissions go to.
+func (d *bidDispatcher) endpoints() (rpcEndpoint, preconfRPC string) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.rpcEndpoint, d.preconfRPC
+}
+
+// setEndpoints changes the endpoints for the bids sent from now on.
+func (d *bidDispatcher) setEndpoints(rpcEndpoint, preconfRPC string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rpcEndpoint, d.preconfRPC = rpcEndpoint, preconfRPC
+}
+
+// mode is the payload privacy mode, or the submission backend when it is not
+// the bidder node.
+func (d *bidDispatcher) mode() string {
+	if d.backend == bb.BackendPreconfRPC {
+		return string(d.backend)
+	}
+	return string(d.privacy)
+}
+
+// bundleHints returns the hints of the bundle submitting tx for blockNumber.
+func (d *bidDispatcher) bundleHints(tx *types.Transaction, blockNumber uint64) ee.BundleHints {
+	hints := d.hints
+	if d.replacement {
+		if sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+			hints.ReplacementUUID = ee.ReplacementFor(sender, blockNumber)
+		}
+	}
+	if d.validity > 0 {
+		now := time.Now()
+		hints.MinTimestamp = uint64(now.Unix())
+		hints.MaxTimestamp = uint64(now.Add(d.validity).Unix())
+	}
+	return hints
+}
+
+// bundleRelays returns the relays bundles go to.
+func (d *bidDispatcher) bundleRelays() []ee.Relay {
+	rpcEndpoint, _ := d.endpoints()
+	var relays []ee.Relay
+	if rpcEndpoint != "" && !d.disabled[ee.DefaultRelay] {
+		relays = append(relays, ee.Relay{Name: ee.DefaultRelay, URL: rpcEndpoint})
+	}
+	for _, relay := range d.relays {
+		if !d.disabled[relay.Name] {
+			relays = append(relays, relay)
+		}
+	}
+	return relays
+}
+
+// sendBundle sends the bundle of tx for blockNumber, or tx as a private
+// transaction with the private-tx submit method, to every relay at once. It
+// only fails when no relay accepted it; relays failing while others accepted
+// it are logged.
+func (d *bidDispatcher) sendBundle(ctx context.Context, tx *types.Transaction, blockNumber uint64) error {
+	var results ee.BundleResults
+	if d.method == ee.SubmitPrivateTx {
+		results = ee.SendPrivateTxs(ctx, d.bundleRelays(), tx, blockNumber, d.preferences, d.hints)
+	} else {
+		results = ee.SendBundles(ctx, d.bundleRelays(), tx, blockNumber, d.bundleHints(tx, blockNumber))
+	}
+	for _, r := range results {
+		result := "ok"
+		if r.Err != nil {
+			result = "error"
+		}
+		metrics.BundleSubmissions.WithLabelValues(r.Relay, result).Inc()
+	}
+	accepted := results.Accepted()
+	if len(accepted) == 0 {
+		return results.Err()
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			slog.Warn("Relay failed to accept bundle",
+				"relay", r.Relay,
+				"error", r.Err,
+				"txHash", tx.Hash().String(),
+			)
+		}
+	}
+	slog.Debug("Bundle sent",
+		"txHash", tx.Hash().String(),
+		"blockNumber", blockNumber,
+		"submitMethod", d.method,
+		"relays", accepted,
+	)
+	return nil
+}
+
+// dispatch sends the bid for signedTx and returns its outcome and the
+// transaction bid for. The lane and sender of the payload are left to the
+// caller. With a non-nil recovery, submissions rejected for a stale nonce or
+// an underpriced replacement are retried with a rebuilt transaction, which
+// the outcome then carries and which is returned instead of signedTx. Sending
+// is traced as a child of the span of ctx.
+func (d *bidDispatcher) dispatch(ctx context.Context, signedTx *types.Transaction, blockNumber uint64, amount float64, decay time.Duration, recovery *ee.Recovery) (outcome.BlockOutcome, *types.Transaction) {
+	o := outcome.BlockOutcome{
+		TargetBlock: blockNumber,
+		Payload:     outcome.Payload{Mode: d.mode()},
+		Timings:     outcome.Timings{Sent: time.Now()},
+	}
+	l := d.labels.Current()
+	o.Tenant, o.Campaign = l.Tenant, l.Campaign
+	if signedTx != nil {
+		o.Payload.TxHash = signedTx.Hash().String()
+		o.Payload.Nonce = signedTx.Nonce()
+	}
+	ctx, span := tracing.Start(ctx, tracing.SendBid,
+		attribute.Int64("targetBlock", int64(blockNumber)),
+		attribute.String("txHash", o.Payload.TxHash),
+		attribute.String("mode", o.Payload.Mode),
+		attribute.Float64("amountEth", amount),
+	)
+	res := d.send(ctx, signedTx, blockNumber, amount, decay, recovery)
+	o.Timings.Resolved = time.Now()
+	span.SetAttributes(attribute.Bool("sent", res.Report.Sent), attribute.Int("commitments", len(res.Commitments)))
+	tracing.End(span, res.Report.Err)
+	if res.Tx != nil {
+		signedTx = res.Tx
+		o.Payload.TxHash = signedTx.Hash().String()
+		o.Payload.Nonce = signedTx.Nonce()
+	}
+	o.Bid = outcome.Bid{
+		AmountETH:  amount,
+		AmountWei:  res.Report.Amount,
+		DecayStart: res.Report.DecayStart,
+		DecayEnd:   res.Report.DecayEnd,
+		Sent:       res.Report.Sent,
+		Err:        res.Report.Err,
+	}
+	o.Submitted = res.Submitted
+	o.Commitments = res.Commitments
+	o.Costs.BidPayments = outcome.BidPayments(o.Commitments)
+	if o.Submitted {
+		o.Costs.MaxTxFee = maxTxFee(signedTx)
+	}
+	return o, signedTx
+}
+
+// sendResult is what sending a bid returned.
+type sendResult struct {
+	Commitments []*pb.Commitment
+	Submitted   bool
+	Report      bb.BidReport
+	Tx          *types.Transaction // Replacement of the transaction after a recovered submission error.
+}
+
+// send sends the bid for signedTx according to the payload privacy mode,
+// decaying over decay. It returns once the bid stream has ended or ctx has
+// been canceled.
+func (d *bidDispatcher) send(ctx context.Context, signedTx *types.Transaction, blockNumber uint64, amount float64, decay time.Duration, recovery *ee.Recovery) sendResult {
+	if d.dryRun {
+		return d.sendDryRun(signedTx, blockNumber, amount, decay)
+	}
+	bidderClient, privacy := d.bidder, d.privacy
+
+	var res sendResult
+	switch {
+	case d.backend == bb.BackendPreconfRPC && signedTx != nil:
+		return d.submitPreconfRPC(ctx, signedTx, blockNumber, recovery)
+	case privacy == bb.PrivacyPayload:
+		res.Report = bb.SendPreconfBidReport(ctx, bidderClient, signedTx, int64(blockNumber), amount, decay)
+		res.Commitments = res.Report.Commitments
+		res.Submitted = signedTx != nil
+	case signedTx == nil:
+		slog.Warn("Transaction is nil, cannot send bid.")
+	case privacy == bb.PrivacyHash:
+		// The bid commits to the hash, so the transaction can still be rebuilt here
+		sent, err := submitRecovering(ctx, recovery, signedTx, func(tx *types.Transaction) error {
+			return d.sendBundle(ctx, tx, blockNumber)
+		})
+		if sent != signedTx {
+			signedTx, res.Tx = sent, sent
+		}
+		if err != nil {
+			metrics.TxSendErrors.Inc()
+			slog.Error("Failed to send transaction",
+				"error", err,
+			)
+		} else {
+			res.Submitted = true
+		}
+		res.Report = bb.SendPreconfBidReport(ctx, bidderClient, signedTx.Hash().String(), int64(blockNumber), amount, decay)
+		res.Commitments = res.Report.Commitments
+	case privacy == bb.PrivacyCommitReveal:
+		// Only the hash is disclosed until a provider commits; then the payload is revealed
+		res.Report = bb.SendPreconfBidReport(ctx, bidderClient, signedTx.Hash().String(), int64(blockNumber), amount, decay)
+		res.Commitments = res.Report.Commitments
+		if len(res.Commitments) == 0 {
+			slog.Info("No commitment received, payload withheld",
+				"txHash", signedTx.Hash().String(),
+				"blockNumber", blockNumber,
+			)
+			return res
+		}
+		if err := d.sendBundle(ctx, signedTx, blockNumber); err != nil {
+			metrics.TxSendErrors.Inc()
+			slog.Error("Failed to reveal transaction",
+				"error", err,
+			)
+			return res
+		}
+		res.Submitted = true
+		slog.Info("Transaction revealed after commitment",
+			"txHash", signedTx.Hash().String(),
+			"blockNumber", blockNumber,
+			"commitments", len(res.Commitments),
+		)
+	}
+	return res
+}
+
+// sendDryRun logs the bid, bundle or preconf RPC submission send would make
+// for signedTx, serialized as it would go out, without sending any of them.
+// The raw transaction is only logged when raw payloads are retained.
+func (d *bidDispatcher) sendDryRun(signedTx *types.Transaction, blockNumber uint64, amount float64, decay time.Duration) sendResult {
+	var res sendResult
+	if signedTx == nil {
+		slog.Warn("Transaction is nil, cannot send bid.")
+		return res
+	}
+	_, preconfRPC := d.endpoints()
+	switch {
+	case d.backend == bb.BackendPreconfRPC:
+		request, err := ee.RawTransactionRequest(signedTx)
+		if err != nil {
+			res.Report.Err = err
+			return res
+		}
+		slog.Info("Dry run, transaction not submitted to the preconf RPC",
+			"preconfRPCEndpoint", bb.MaskEndpoint(preconfRPC),
+			"txHash", signedTx.Hash().String(),
+			"payloadSize", signedTx.Size(),
+			"blockNumber", blockNumber,
+			"request", bb.RedactRequest(request, signedTx),
+		)
+		res.Report.Err = bb.ErrDryRun
+		return res
+	case d.privacy == bb.PrivacyPayload:
+		res.Report = bb.DryRunBidReport(d.bidder, signedTx, int64(blockNumber), amount, decay)
+		return res
+	}
+	request, err := ee.BundleRequest(signedTx, blockNumber, d.hints)
+	if d.method == ee.SubmitPrivateTx {
+		request, err = ee.PrivateTxRequest(signedTx, blockNumber, d.preferences)
+	}
+	if err != nil {
+		res.Report.Err = err
+		return res
+	}
+	msg := "Dry run, bundle not sent"
+	if d.privacy == bb.PrivacyCommitReveal {
+		msg = "Dry run, bundle not revealed"
+	}
+	var relays []string
+	for _, relay := range d.bundleRelays() {
+		relays = append(relays, relay.Name)
+	}
+	slog.Info(msg,
+		"relays", relays,
+		"submitMethod", d.method,
+		"txHash", signedTx.Hash().String(),
+		"payloadSize", signedTx.Size(),
+		"blockNumber", blockNumber,
+		"request", bb.RedactRequest(request, signedTx),
+	)
+	res.Report = bb.DryRunBidReport(d.bidder, signedTx.Hash().String(), int64(blockNumber), amount, decay)
+	return res
+}
+
+// submitPreconfRPC hands signedTx to the preconf RPC. The RPC bids on the
+// sender's behalf and does not return commitments; they are only observed
+// through commitment feedback from the mev-commit chain.
+func (d *bidDispatcher) submitPreconfRPC(ctx context.Context, signedTx *types.Transaction, blockNumber uint64, recovery *ee.Recovery) sendResult {
+	var res sendResult
+	_, preconfRPC := d.endpoints()
+	sent, err := submitRecovering(ctx, recovery, signedTx, func(tx *types.Transaction) error {
+		_, err := ee.SendRawTransactionContext(ctx, preconfRPC, tx)
+		return err
+	})
+	if sent != signedTx {
+		signedTx, res.Tx = sent, sent
+	}
+	if err != nil {
+		metrics.PreconfRPCSubmissions.WithLabelValues("error").Inc()
+		slog.Error("Failed to submit transaction to the preconf RPC",
+			"preconfRPCEndpoint", bb.MaskEndpoint(preconfRPC),
+			"error", err,
+		)
+		res.Report.Err = err
+		return res
+	}
+	metrics.PreconfRPCSubmissions.WithLabelValues("ok").Inc()
+	res.Submitted = true
+	res.Report.Sent = true
+	slog.Info("Transaction submitted to the preconf RPC",
+		"txHash", signedTx.Hash().String(),
+		"blockNumber", blockNumber,
+	)
+	return res
+}
+
+// submitRecovering submits tx and, while recovery allows, resubmits a rebuilt
+// transaction after a nonce too low or replacement underpriced error. It
+// returns the transaction submitted last.
+func submitRecovering(ctx context.Context, recovery *ee.Recovery, tx *types.Transaction, submit func(*types.Transaction) error) (*types.Transaction, error) {
+	err := submit(tx)
+	for attempt := 0; err != nil && recovery != nil && attempt < ee.MaxRecoveries && ctx.Err() == nil; attempt++ {
+		kind := ee.ClassifySendError(err)
+		replacement, rerr := recovery.Recover(ctx, tx, err)
+		if rerr != nil {
+			if kind != "" {
+				slog.Warn("Failed to recover from transaction submission error", "error", rerr, "txHash", tx.Hash().String())
+			}
+			break
+		}
+		metrics.TxSendRecoveries.WithLabelValues(string(kind)).Inc()
+		tx = replacement
+		err = submit(tx)
+	}
+	return tx, err
+}
+
+// decayWindows returns the decay window of each of the span target blocks
+// from offset on, within bounds.
+func decayWindows(bounds bb.DecayBounds, offset uint64, span int) ([]time.Duration, error) {
+	decays := make([]time.Duration, span)
+	for i := range decays {
+		targetOffset := offset + uint64(i)
+		var err error
+		decays[i], err = bounds.Apply(bb.DecayWindow(targetOffset))
+		if err != nil {
+			return nil, fmt.Errorf("decay window for offset %d: %w", targetOffset, err)
+		}
+	}
+	return decays, nil
+}
+
+// spanOutcome collects which target blocks of a transaction bid for a span of
+// consecutive blocks were committed, and logs the result once every bid of
+// the span has resolved.
+type spanOutcome struct {
+	txHash    string
+	mu        sync.Mutex
+	pending   int
+	committed []uint64
+}
+
+func newSpanOutcome(txHash string, targets int) *spanOutcome {
+	return &spanOutcome{txHash: txHash, pending: targets}
+}
+
+// resolve records the commitments received for the bid on targetBlock. Once
+// every bid of the span has resolved, it reports whether any was committed.
+func (s *spanOutcome) resolve(targetBlock uint64, commitments int) (resolved, committed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if commitments > 0 {
+		s.committed = append(s.committed, targetBlock)
+	}
+	if s.pending--; s.pending > 0 {
+		return false, false
+	}
+	sort.Slice(s.committed, func(i, j int) bool { return s.committed[i] < s.committed[j] })
+	slog.Info("Target block span resolved",
+		"txHash", s.txHash,
+		"committedBlocks", s.committed,
+		"committed", len(s.committed) > 0,
+	)
+	return true, len(s.committed) > 0
+}
+
+// settleNonce reports to nonces whether the transaction of o, whose bids have
+// all resolved, landed. Commitments to preconf RPC submissions are not known
+// here, so the account syncs with the node instead.
+func settleNonce(nonces *ee.NonceManager, o outcome.BlockOutcome, backend bb.SubmissionBackend, committed bool) {
+	switch {
+	case nonces == nil || o.Payload.TxHash == "":
+	case backend == bb.BackendPreconfRPC && o.Submitted:
+		nonces.Reset(o.Payload.From)
+	default:
+		nonces.Settle(o.Payload.From, o.Payload.Nonce, committed)
+	}
+}
+
+// recordActivity adds the wallet activity of a dispatched bid to the ledger.
+func recordActivity(ledger *accounting.Ledger, o outcome.BlockOutcome) {
+	if ledger == nil || o.Payload.TxHash == "" {
+		return
+	}
+	var entries []accounting.Entry
+	if o.Submitted {
+		entries = append(entries, accounting.Entry{
+			Kind:   accounting.KindL1Tx,
+			TxHash: o.Payload.TxHash,
+			Block:  o.TargetBlock,
+			Fee:    o.Costs.MaxTxFee,
+			Note:   "maximum fee; the fee paid is known once the transaction is included",
+		})
+	}
+	for _, c := range o.Commitments {
+		amount, ok := new(big.Int).SetString(c.GetBidAmount(), 10)
+		if !ok {
+			continue
+		}
+		entries = append(entries, accounting.Entry{
+			Kind:         accounting.KindBidPayment,
+			TxHash:       o.Payload.TxHash,
+			Block:        uint64(c.GetBlockNumber()),
+			Amount:       amount,
+			Counterparty: c.GetProviderAddress(),
+			Note:         "committed bid, settled on the mev-commit chain",
+		})
+	}
+	for _, e := range entries {
+		e.Tenant, e.Campaign = o.Tenant, o.Campaign
+		if err := ledger.Add(e); err != nil {
+			slog.Warn("Failed to record wallet activity", "error", err, "txHash", o.Payload.TxHash)
+		}
+	}
+}
+
+// maxTxFee returns the most a transaction can cost in fees, including blob gas.
+func maxTxFee(tx *types.Transaction) *big.Int {
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap())
+	if blobFeeCap := tx.BlobGasFeeCap(); blobFeeCap != nil {
+		fee.Add(fee, new(big.Int).Mul(new(big.Int).SetUint64(tx.BlobGas()), blobFeeCap))
+	}
+	return fee
+}
+
+// marketSnapshot observes the market conditions recorded alongside a bid.
+// Competing commitments are only recorded when competitors are observed.
+func marketSnapshot(client *ethclient.Client, competitors *competition.Observer) *campaign.MarketSnapshot {
+	snapshot := &campaign.MarketSnapshot{}
+	if competitors != nil {
+		now := time.Now()
+		count, own := competitors.LastSlot(now), competitors.LastSlotOwn(now)
+		snapshot.CompetingCommitments, snapshot.OwnCommitments = &count, &own
+	}
+	ctx, cancel := latency.Context(context.Background(), latency.PendingCount)
+	defer cancel()
+	if count, err := client.PendingTransactionCount(ctx); err == nil {
+		snapshot.PendingTxCount = &count
+	} else {
+		slog.Debug("Failed to fetch pending transaction count", "error", latency.Wrap(latency.PendingCount, err))
+	}
+	return snapshot
+}
+
+// observeInclusions records the blob transactions of the block of header,
+// ours and the market's, with competitors.
+func observeInclusions(ctx context.Context, client *ethclient.Client, header *types.Header, competitors *competition.Observer) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	block, err := client.BlockByHash(ctx, header.Hash())
+	if err != nil {
+		slog.Debug("Failed to fetch block for inclusions", "error", err, "blockNumber", header.Number.Uint64())
+		return
+	}
+	competitors.ObserveInclusions(time.Unix(int64(header.Time), 0), competition.BlockInclusions(block))
+}
+
+// disputeCommitment returns the commitment of ev kept for disputes.
+func disputeCommitment(ev bb.CommitmentStoredEvent) dispute.Commitment {
+	var txHashes []string
+	for _, hash := range strings.Split(ev.TxnHash, ",") {
+		if hash = strings.TrimSpace(hash); hash != "" {
+			txHashes = append(txHashes, hash)
+		}
+	}
+	return dispute.Commitment{
+		Index:               hexutil.Encode(ev.CommitmentIndex[:]),
+		Digest:              hexutil.Encode(ev.CommitmentHash[:]),
+		Signature:           hexutil.Encode(ev.CommitmentSignature),
+		BidDigest:           hexutil.Encode(ev.BidHash[:]),
+		BidSignature:        hexutil.Encode(ev.BidSignature),
+		Provider:            ev.Commiter.Hex(),
+		Bidder:              ev.Bidder.Hex(),
+		BidAmount:           strconv.FormatUint(ev.Bid, 10),
+		TxHashes:            txHashes,
+		BlockNumber:         ev.BlockNumber,
+		DecayStartTimestamp: ev.DecayStartTimeStamp,
+		DecayEndTimestamp:   ev.DecayEndTimeStamp,
+		DispatchTimestamp:   ev.DispatchTimestamp,
+	}
+}
+
+// checkDisputes checks the commitments kept for the target block against the
+// block, and reports those not honored.
+func checkDisputes(ctx context.Context, client *ethclient.Client, target uint64, disputes *dispute.Reporter) {
+	commitments := disputes.Take(target)
+	if len(commitments) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(target))
+	if err != nil {
+		slog.Warn("Failed to fetch block to check commitments", "error", err, "blockNumber", target, "commitments", len(commitments))
+		return
+	}
+	for _, b := range disputes.Check(block, commitments) {
+		disputes.Report(ctx, b)
+	}
+}
+
+// checkInclusions checks the bids kept for the target block against the
+// block, and records how their inclusion compares with their commitments. A
+// committed transaction missing from the block is looked up, in case it was
+// included in another one.
+func checkInclusions(ctx context.Context, client *ethclient.Client, target uint64, inclusions *inclusion.Watcher) {
+	bids := inclusions.Take(target)
+	if len(bids) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(target))
+	if err != nil {
+		slog.Warn("Failed to fetch block to check inclusions", "error", err, "blockNumber", target, "bids", len(bids))
+		return
+	}
+	for _, r := range inclusions.Check(block, bids) {
+		if r.Result == inclusion.Violated {
+			if receipt, err := client.TransactionReceipt(ctx, common.HexToHash(r.TxHash)); err == nil {
+				r.IncludedBlock = receipt.BlockNumber.Uint64()
+			}
+		}
+		inclusions.Record(r)
+	}
+}
+
+// backfillReader returns the client missed headers are read from: the RPC
+// endpoint's, or the reconnected WebSocket client without one. The returned
+// function releases it.
+func backfillReader(ctx context.Context, rpcClient *ethclient.Client, rpcEndpoint string, wsClient *ethclient.Client) (backfill.HeaderReader, func()) {
+	if rpcClient != nil {
+		return rpcClient, func() {}
+	}
+	if rpcEndpoint != "" {
+		client, err := ident.DialEth(ctx, rpcEndpoint)
+		if err == nil {
+			return client, client.Close
+		}
+		slog.Warn("Failed to connect to the RPC endpoint, backfilling from the WebSocket endpoint",
+			"error", err,
+			"rpcEndpoint", bb.MaskEndpoint(rpcEndpoint),
+		)
+	}
+	return wsClient, func() {}
+}
+
+// reconcileLanes looks for the transactions a previous run of the lanes left
+// behind. Those waiting in the mempool are adopted by the nonce manager and
+// recognized as ours; pre-signed transactions that already landed are dropped
+// from their raw lane.
+func reconcileLanes(ctx context.Context, client *ethclient.Client, bidLanes []*lanes.Lane, blocks uint64, nonces *ee.NonceManager, ownership *competition.Ownership) error {
+	var accounts []common.Address
+	seen := make(map[common.Address]bool)
+	for _, lane := range bidLanes {
+		if !seen[lane.Account.Address] {
+			seen[lane.Account.Address] = true
+			accounts = append(accounts, lane.Account.Address)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	result, err := reconcile.Run(ctx, client, reconcile.TxpoolContent(client.Client()), accounts, blocks)
+	if err != nil {
+		return err
+	}
+	for _, account := range result.Accounts {
+		nonceList := make([]uint64, 0, len(account.Waiting))
+		for _, tx := range account.Waiting {
+			nonceList = append(nonceList, tx.Nonce)
+			ownership.AddTx(tx.Hash)
+		}
+		if nonces != nil {
+			nonces.Adopt(account.Address, account.Pending, nonceList)
+		}
+	}
+	for _, lane := range bidLanes {
+		remaining := lane.RawTxs[:0:0]
+		for _, tx := range lane.RawTxs {
+			if result.Landed(tx.Hash().Hex()) {
+				slog.Info("Skipping raw transaction included before startup", "txHash", tx.Hash().Hex(), "nonce", tx.Nonce())
+				continue
+			}
+			remaining = append(remaining, tx)
+		}
+		lane.RawTxs = remaining
+	}
+	return nil
+}
+
+// refreshBalance updates the balance of addr on the account status board.
+func refreshBalance(ctx context.Context, accounts *status.Board, client *ethclient.Client, addr common.Address) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	balance, err := client.BalanceAt(ctx, addr, nil)
+	if err != nil {
+		slog.Debug("Failed to fetch account balance", "error", err, "address", addr.Hex())
+		return
+	}
+	accounts.SetBalance(addr, balance)
+}
+
+// refreshBalances updates the balances of addrs older than maxAge on the
+// account status board, dialing the node at endpoint.
+func refreshBalances(ctx context.Context, accounts *status.Board, endpoint string, addrs []common.Address, maxAge time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	client, err := ident.DialEth(ctx, endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the node: %w", err)
+	}
+	defer client.Close()
+	for _, addr := range addrs {
+		if !accounts.BalanceOlderThan(addr, maxAge) {
+			continue
+		}
+		balance, err := client.BalanceAt(ctx, addr, nil)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the balance of %s: %w", addr.Hex(), err)
+		}
+		accounts.SetBalance(addr, balance)
+	}
+	return nil
+}
+
+// campaignCheck returns the prerequisite check of scheduled campaigns: every
+// bidding account holds the campaign's minimum balance, and the bidder node
+// holds its minimum deposit in the bidding window the start falls into,
+// estimated from the head. The node is dialed at endpoint() for every check,
+// which runs at most every schedule.DefaultInterval.
+func campaignCheck(endpoint func() string, bidder *bb.Bidder, addrs []common.Address, blocksPerWindow uint64) schedule.Check {
+	return func(ctx context.Context, c schedule.Campaign) []string {
+		ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+		client, err := ident.DialEth(ctx, endpoint())
+		if err != nil {
+			return []string{fmt.Sprintf("failed to connect to the node: %v", err)}
+		}
+		defer client.Close()
+
+		var problems []string
+		if c.MinBalanceWei != nil {
+			for _, addr := range addrs {
+				balance, err := client.BalanceAt(ctx, addr, nil)
+				switch {
+				case err != nil:
+					problems = append(problems, fmt.Sprintf("failed to fetch the balance of %s: %v", addr.Hex(), err))
+				case balance.Cmp(c.MinBalanceWei) < 0:
+					problems = append(problems, fmt.Sprintf("%s holds %s wei, needs %s wei", addr.Hex(), balance, c.MinBalanceWei))
+				}
+			}
+		}
+		if c.MinDepositWei != nil {
+			head, err := client.HeaderByNumber(ctx, nil)
+			if err != nil {
+				return append(problems, fmt.Sprintf("failed to fetch the head block: %v", err))
+			}
+			startBlock := head.Number.Uint64()
+			if ahead := c.Start.Sub(time.Unix(int64(head.Time), 0)); ahead > 0 {
+				startBlock += uint64((ahead + bb.SlotDuration - 1) / bb.SlotDuration)
+			}
+			window := bb.WindowForBlock(startBlock, blocksPerWindow)
+			deposit, err := bidder.WindowDeposit(ctx, window)
+			switch {
+			case err != nil:
+				problems = append(problems, fmt.Sprintf("failed to fetch the deposit of window %d: %v", window, err))
+			case deposit.Cmp(c.MinDepositWei) < 0:
+				problems = append(problems, fmt.Sprintf("window %d holds a deposit of %s wei, needs %s wei", window, deposit, c.MinDepositWei))
+			}
+		}
+		return problems
+	}
+}
+
+// signBid returns acct's provenance signature of the bid of o, or nothing when
+// the account has no key or no bid was built.
+func signBid(acct bb.AuthAcct, o outcome.BlockOutcome) string {
+	if acct.PrivateKey == nil || o.Payload.TxHash == "" || o.Bid.AmountWei == "" {
+		return ""
+	}
+	sig, err := provenance.Sign(acct.PrivateKey, provenance.Inputs{
+		Bidder:      acct.Address,
+		TxHash:      o.Payload.TxHash,
+		BlockNumber: o.TargetBlock,
+		AmountWei:   o.Bid.AmountWei,
+		DecayStart:  o.Bid.DecayStart,
+		DecayEnd:    o.Bid.DecayEnd,
+	})
+	if err != nil {
+		slog.Warn("Failed to sign bid", "error", err, "txHash", o.Payload.TxHash)
+		return ""
+	}
+	return sig
+}
+
+// recordBid adds a dispatched bid to the bid history.
+func recordBid(history *store.Store, o outcome.BlockOutcome) {
+	if history == nil || o.Payload.TxHash == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := history.Record(ctx, store.FromOutcome(o)); err != nil {
+		slog.Warn("Failed to record bid history", "error", err)
+	}
+}
+
+// recordOutcome updates the bid history with whether a commitment for the bid
+// was stored on the mev-commit chain.
+func recordOutcome(history *store.Store, o feedback.Outcome) {
+	status := store.NotStored
+	if o.Committed() {
+		status = store.Stored
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := history.SetStatus(ctx, o.TxHash, status, o.Providers); err != nil {
+		slog.Warn("Failed to record bid outcome", "error", err)
+	}
+}
+
+// dashboardConnections reports the bidder node connection and every WebSocket
+// endpoint of the pool to the dashboard.
+func dashboardConnections(bidder *bb.Bidder, pool *bb.WSPool) []dashboard.Connection {
+	state := bidder.ConnState()
+	connections := []dashboard.Connection{{
+		Name:    "bidder node",
+		Healthy: state == "READY" || state == "IDLE",
+		Detail:  state,
+	}}
+	for i, endpoint := range pool.Status() {
+		name := "ws primary"
+		if i > 0 {
+			name = fmt.Sprintf("ws fallback %d", i)
+		}
+		detail := ""
+		if endpoint.Current {
+			detail = "in use"
+		}
+		connections = append(connections, dashboard.Connection{
+			Name:     name,
+			Endpoint: bb.MaskEndpoint(endpoint.Endpoint),
+			Healthy:  endpoint.Healthy,
+			Detail:   detail,
+			Standby:  !endpoint.Current,
+		})
+	}
+	return connections
+}
+
+// tuiDeposits reports the deposits of the window of the head block and the
+// next one to the terminal UI, waiting up to timeout for each.
+func tuiDeposits(bidder *bb.Bidder, blocksPerWindow uint64, timeout time.Duration) func(ctx context.Context, head uint64) []tui.Deposit {
+	return func(ctx context.Context, head uint64) []tui.Deposit {
+		current := bb.WindowForBlock(head, blocksPerWindow)
+		deposits := make([]tui.Deposit, 0, 2)
+		for _, window := range []uint64{current, current + 1} {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			wei, err := bidder.WindowDeposit(ctx, window)
+			cancel()
+			deposits = append(deposits, tui.Deposit{Window: window, Wei: wei, Err: err})
+		}
+		return deposits
+	}
+}
+
+// watchCommitments feeds CommitmentStored events of the mev-commit chain into
+// the feedback tracker, and competitors and disputes when set, until ctx is
+// canceled, reconnecting after errors.
+func watchCommitments(ctx context.Context, endpoint string, tracker *feedback.Tracker, competitors *competition.Observer, disputes *dispute.Reporter) {
+	for ctx.Err() == nil {
+		client, err := ident.DialEth(ctx, endpoint)
+		if err == nil {
+			err = bb.ListenForCommitmentStoredEventContext(ctx, client, func(ev bb.CommitmentStoredEvent) {
+				var dispatched time.Time
+				if ev.DispatchTimestamp > 0 {
+					dispatched = time.UnixMilli(int64(ev.DispatchTimestamp))
+				}
+				tracker.ObserveDispatched(ev.TxnHash, ev.Commiter.Hex(), dispatched)
+				if competitors != nil {
+					competitors.ObserveOpened(common.Bytes2Hex(ev.CommitmentHash[:]), ev.TxnHash)
+				}
+				disputes.Stored(disputeCommitment(ev))
+			})
+			client.Close()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("CommitmentStored subscription ended, retrying in 10 seconds", "error", err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+// settlementPollInterval is how often the mev-commit chain is polled for
+// commitments the oracle processed, and settlementBlocks how many of its
+// blocks one poll reads at most.
+const (
+	settlementPollInterval = 30 * time.Second
+	settlementBlocks       = 5000
+)
+
+// watchSettlements feeds the CommitmentProcessed events of the mev-commit
+// chain into disputes, from the head at start on, until ctx is canceled. The
+// chain is polled, so no block is missed across reconnects.
+func watchSettlements(ctx context.Context, endpoint string, disputes *dispute.Reporter) {
+	var (
+		client *ethclient.Client
+		next   uint64 // The first block not read yet, 0 before the head is known.
+	)
+	defer func() {
+		if client != nil {
+			client.Close()
+		}
+	}()
+	for {
+		var err error
+		if client == nil {
+			client, err = ident.DialEth(ctx, endpoint)
+		}
+		if err == nil {
+			next, err = pollSettlements(ctx, client, next, disputes)
+		}
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("Failed to read processed commitments, retrying", "error", err)
+			if client != nil {
+				client.Close()
+				client = nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(settlementPollInterval):
+		}
+	}
+}
+
+// settlementReader is the part of the mev-commit chain client pollSettlements
+// uses.
+type settlementReader interface {
+	bind.ContractFilterer
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// pollSettlements feeds the CommitmentProcessed events from block next up to
+// the head, at most settlementBlocks of them, into disputes, and returns the
+// block to read from next time. With next 0 it only returns the block after
+// the head.
+func pollSettlements(ctx context.Context, client settlementReader, next uint64, disputes *dispute.Reporter) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return next, err
+	}
+	if next == 0 || head < next {
+		return max(next, head+1), nil
+	}
+	to := min(head, next+settlementBlocks-1)
+	events, err := bb.FilterProcessedCommitments(ctx, client, next, to)
+	if err != nil {
+		return next, err
+	}
+	for _, ev := range events {
+		disputes.Processed(hexutil.Encode(ev.CommitmentIndex[:]), ev.IsSlash)
+	}
+	return to + 1, nil
+}
+
+// txSkipReason classifies why a transaction could not be built.
+func txSkipReason(err error) skips.Reason {
+	if errors.Is(err, ee.ErrFeeCap) {
+		return skips.FeeCap
+	}
+	if errs.Is(err, errs.ErrInsufficientFunds) {
+		return skips.InsufficientFunds
+	}
+	return skips.TxError
+}
+
+// recordDecision appends a decision record, logging rather than failing on errors.
+func recordDecision(recorder *campaign.Recorder, rec campaign.Record) {
+	if recorder == nil {
+		return
+	}
+	if err := recorder.Record(rec); err != nil {
+		slog.Warn("Failed to record bid decision", "error", err)
+	}
+}
+
+// loadStrategyPlugins loads a comma separated list of strategy plugins.
+func loadStrategyPlugins(paths string) error {
+	for _, path := range strings.Split(paths, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if err := strategy.LoadPlugin(path); err != nil {
+			return err
+		}
+		slog.Info("Loaded strategy plugin", "path", path, "strategies", strategy.Names())
+	}
+	return nil
+}
+
+// readStrategyScript returns the script itself, or the contents of the file
+// when it starts with @.
+func readStrategyScript(script string) (string, error) {
+	path, ok := strings.CutPrefix(script, "@")
+	if !ok {
+		return script, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read strategy script: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
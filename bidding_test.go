@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/primev/preconf_blob_bidder/internal/contracts/preconfmanager"
+	"github.com/primev/preconf_blob_bidder/internal/dispute"
+	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+	"github.com/stretchr/testify/require"
+)
+
+// settlementChain serves CommitmentProcessed logs in the queried range.
+type settlementChain struct {
+	head    uint64
+	logs    []types.Log
+	queries [][2]uint64
+}
+
+func (c *settlementChain) BlockNumber(context.Context) (uint64, error) { return c.head, nil }
+
+func (c *settlementChain) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	c.queries = append(c.queries, [2]uint64{q.FromBlock.Uint64(), q.ToBlock.Uint64()})
+	var logs []types.Log
+	for _, l := range c.logs {
+		if l.BlockNumber >= q.FromBlock.Uint64() && l.BlockNumber <= q.ToBlock.Uint64() {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func (c *settlementChain) SubscribeFilterLogs(context.Context, ethereum.FilterQuery, chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func processedLog(t *testing.T, index common.Hash, slashed bool, block uint64) types.Log {
+	t.Helper()
+	contractAbi, err := preconfmanager.PreconfManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	event := contractAbi.Events["CommitmentProcessed"]
+	data, err := event.Inputs.NonIndexed().Pack(slashed)
+	require.NoError(t, err)
+	return types.Log{Address: bb.PreconfManagerAddress, Topics: []common.Hash{event.ID, index}, Data: data, BlockNumber: block}
+}
+
+func TestPollSettlements(t *testing.T) {
+	ctx := context.Background()
+	disputes, err := dispute.Open("", "", func(string) bool { return true })
+	require.NoError(t, err)
+	index := common.Hash{7}
+	disputes.Report(ctx, dispute.Bundle{Commitment: dispute.Commitment{Index: hexutil.Encode(index[:]), Provider: "0xprovider"}})
+
+	chain := &settlementChain{head: 100, logs: []types.Log{processedLog(t, index, true, 99)}}
+	next, err := pollSettlements(ctx, chain, 0, disputes)
+	require.NoError(t, err)
+	require.Equal(t, uint64(101), next, "the first poll starts after the head")
+	require.Empty(t, chain.queries)
+
+	next, err = pollSettlements(ctx, chain, next, disputes)
+	require.NoError(t, err)
+	require.Equal(t, uint64(101), next, "nothing to read before a new block")
+
+	chain.head = 101 + settlementBlocks + 10
+	chain.logs = append(chain.logs, processedLog(t, index, true, 150))
+	next, err = pollSettlements(ctx, chain, next, disputes)
+	require.NoError(t, err)
+	require.Equal(t, uint64(101+settlementBlocks), next, "one poll reads at most settlementBlocks")
+	require.Equal(t, [][2]uint64{{101, 100 + settlementBlocks}}, chain.queries)
+	require.False(t, disputes.Processed(hexutil.Encode(index[:]), true), "the dispute was settled by the poll")
+
+	next, err = pollSettlements(ctx, chain, next, disputes)
+	require.NoError(t, err)
+	require.Equal(t, chain.head+1, next)
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/primev/preconf_blob_bidder/internal/config"
+	"github.com/primev/preconf_blob_bidder/internal/doctor"
+	"github.com/primev/preconf_blob_bidder/internal/ident"
+	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	FlagDoctorBidderHTTP   = "bidder-http-address"
+	FlagDoctorCheckTimeout = "check-timeout"
+)
+
+const (
+	// slotDuration is the L1 slot time the clock skew check compares against.
+	slotDuration = 12 * time.Second
+	// lowBalanceWei is the wallet balance below which bidding may soon stall.
+	lowBalanceWei = 1e16
+)
+
+// doctorCommand runs diagnostics against the configured endpoints, bidder node
+// and wallet, and prints prioritized remediation steps. Settings are read from
+// the same flags and environment variables as the bidder itself.
+func doctorCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "doctor",
+		Usage: "Diagnose endpoints, the bidder node, balances, deposits, clock skew and contract configuration",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    FlagDoctorBidderHTTP,
+				Usage:   "HTTP API of the mev-commit bidder node, used for its peer topology",
+				EnvVars: []string{"BIDDER_HTTP_ADDRESS"},
+				Value:   "http://localhost:13523",
+			},
+			&cli.DurationFlag{
+				Name:  FlagDoctorCheckTimeout,
+				Usage: "Timeout of each diagnostic",
+				Value: 5 * time.Second,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := loadConfig(c)
+			if err != nil {
+				return err
+			}
+			d := &diagnosis{
+				rpcEndpoint:     cfg.RPCEndpoint,
+				wsEndpoint:      cfg.WSEndpoint,
+				serverAddress:   cfg.ServerAddress,
+				bidderHTTP:      c.String(FlagDoctorBidderHTTP),
+				privateKeyHex:   cfg.PrivateKey,
+				blocksPerWindow: cfg.BlocksPerWindow,
+				offset:          cfg.Offset,
+				cfg:             cfg,
+			}
+			defer d.close()
+			if err := resolveContracts(c.Context, cfg); err != nil {
+				return err
+			}
+
+			results := doctor.Run(c.Context, d.checks(), c.Duration(FlagDoctorCheckTimeout))
+			doctor.Print(c.App.Writer, results)
+			if doctor.Worst(results) == doctor.Fail {
+				return cli.Exit("doctor found problems that prevent bidding", 1)
+			}
+			return nil
+		},
+	}
+}
+
+// diagnosis holds the configuration under test and state shared between checks.
+type diagnosis struct {
+	rpcEndpoint     string
+	wsEndpoint      string
+	serverAddress   string
+	bidderHTTP      string
+	privateKeyHex   string
+	blocksPerWindow uint64
+	offset          uint64
+	cfg             config.Config
+
+	rpcChainID *big.Int
+	wsChainID  *big.Int
+	wsClient   *ethclient.Client
+	head       *types.Header
+	address    common.Address
+}
+
+func (d *diagnosis) close() {
+	if d.wsClient != nil {
+		d.wsClient.Close()
+	}
+}
+
+func (d *diagnosis) checks() []doctor.Check {
+	return []doctor.Check{
+		{Name: "private key", Run: d.checkPrivateKey},
+		{Name: "rpc endpoint", Run: d.checkRPC},
+		{Name: "ws endpoint", Run: d.checkWS},
+		{Name: "mainnet guardrails", Run: d.checkMainnet},
+		{Name: "clock skew", Run: d.checkClockSkew},
+		{Name: "wallet balance", Run: d.checkBalance},
+		{Name: "bidder node", Run: d.checkBidderNode},
+		{Name: "providers", Run: d.checkProviders},
+		{Name: "contracts", Run: d.checkContracts},
+	}
+}
+
+// signerHints are the settings to check when the primary account fails to
+// open, by where it signs from.
+var signerHints = map[string]string{
+	sourceRemoteSigner: "check REMOTE_SIGNER_URL, REMOTE_SIGNER_KIND and REMOTE_SIGNER_ADDRESS",
+	sourceKeystore:     "check KEYSTORE_PATH, KEYSTORE_PASSWORD_FILE and the keystore password",
+	sourcePrivateKey:   "PRIVATE_KEY must be 64 hex characters",
+}
+
+func (d *diagnosis) checkPrivateKey(ctx context.Context) doctor.Result {
+	cfg := d.cfg
+	cfg.PrivateKey = d.privateKeyHex
+	account, err := openPrimaryAccount(ctx, cfg, nil)
+	if errors.Is(err, errNoSigner) {
+		return doctor.Failure("no private key configured", "set PRIVATE_KEY, KEYSTORE_PATH or REMOTE_SIGNER_URL, or pass --"+FlagPrivateKey)
+	}
+	if err != nil {
+		return doctor.Failure(err.Error(), signerHints[signerSource(cfg)])
+	}
+	defer account.Close()
+	d.address = account.Address
+	if account.Source == sourcePrivateKey {
+		return doctor.Pass("address " + d.address.Hex())
+	}
+	return doctor.Pass("address " + d.address.Hex() + " from " + account.Source)
+}
+
+func (d *diagnosis) checkRPC(ctx context.Context) doctor.Result {
+	if d.rpcEndpoint == "" {
+		return doctor.Skipped("no RPC endpoint configured")
+	}
+	client, err := ident.DialEth(ctx, d.rpcEndpoint)
+	if err != nil {
+		return doctor.Failure("cannot connect to "+bb.MaskEndpoint(d.rpcEndpoint)+": "+err.Error(), "check RPC_ENDPOINT")
+	}
+	defer client.Close()
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return doctor.Failure("chain id request failed: "+err.Error(), "check that RPC_ENDPOINT is reachable and serves eth_chainId")
+	}
+	d.rpcChainID = chainID
+	return doctor.Pass(fmt.Sprintf("%s, chain id %s", bb.MaskEndpoint(d.rpcEndpoint), chainID))
+}
+
+func (d *diagnosis) checkWS(ctx context.Context) doctor.Result {
+	endpoints := bb.ParseWSEndpoints(d.wsEndpoint)
+	if len(endpoints) == 0 {
+		return doctor.Failure("no WebSocket endpoint configured", "set WS_ENDPOINT or pass --"+FlagWsEndpoint)
+	}
+	// Only the primary is checked in depth, fallbacks just need to accept connections
+	primary := endpoints[0]
+	client, err := ident.DialEth(ctx, primary)
+	if err != nil {
+		return doctor.Failure("cannot connect to "+bb.MaskEndpoint(primary)+": "+err.Error(), "check WS_ENDPOINT and that the node accepts WebSocket connections")
+	}
+	d.wsClient = client
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return doctor.Failure("chain id request failed: "+err.Error(), "check that WS_ENDPOINT is reachable")
+	}
+	d.wsChainID = chainID
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return doctor.Failure("latest header request failed: "+err.Error(), "check that the node behind WS_ENDPOINT is synced")
+	}
+	d.head = head
+	if d.rpcChainID != nil && d.rpcChainID.Cmp(chainID) != 0 {
+		return doctor.Failure(
+			fmt.Sprintf("chain id %s differs from RPC endpoint chain id %s", chainID, d.rpcChainID),
+			"point RPC_ENDPOINT and WS_ENDPOINT at the same network",
+		)
+	}
+	for _, fallback := range endpoints[1:] {
+		fallbackClient, err := ident.DialEth(ctx, fallback)
+		if err != nil {
+			return doctor.Warning("cannot connect to fallback "+bb.MaskEndpoint(fallback)+": "+err.Error(), "check the fallback endpoints in WS_ENDPOINT")
+		}
+		fallbackClient.Close()
+	}
+	return doctor.Pass(fmt.Sprintf("%s, chain id %s, head block %d, %d fallback endpoints", bb.MaskEndpoint(primary), chainID, head.Number.Uint64(), len(endpoints)-1))
+}
+
+func (d *diagnosis) checkMainnet(context.Context) doctor.Result {
+	if d.wsChainID == nil {
+		return doctor.Skipped("chain id unknown")
+	}
+	if d.wsChainID.Int64() != config.MainnetChainID {
+		return doctor.Pass("not mainnet, chain id " + d.wsChainID.String())
+	}
+	if err := d.cfg.CheckMainnet(); err != nil {
+		return doctor.Failure(err.Error(), "see Mainnet guardrails in the README")
+	}
+	return doctor.Pass("mainnet run confirmed with explicit budgets")
+}
+
+func (d *diagnosis) checkClockSkew(context.Context) doctor.Result {
+	if d.head == nil {
+		return doctor.Skipped("no head block available")
+	}
+	age := time.Since(time.Unix(int64(d.head.Time), 0))
+	switch {
+	case age < 0:
+		return doctor.Warning(
+			fmt.Sprintf("local clock is %s behind the latest block timestamp", (-age).Round(time.Millisecond)),
+			"synchronize the system clock (e.g. enable NTP); a late clock shifts bid decay timestamps",
+		)
+	case age > 2*slotDuration:
+		return doctor.Warning(
+			fmt.Sprintf("latest block is %s old, more than two %s slots", age.Round(time.Millisecond), slotDuration),
+			"synchronize the system clock, or check that the node behind WS_ENDPOINT is not lagging",
+		)
+	}
+	return doctor.Pass(fmt.Sprintf("latest block is %s old", age.Round(time.Millisecond)))
+}
+
+func (d *diagnosis) checkBalance(ctx context.Context) doctor.Result {
+	if d.wsClient == nil || d.address == (common.Address{}) {
+		return doctor.Skipped("needs a valid private key and WebSocket endpoint")
+	}
+	balance, err := d.wsClient.BalanceAt(ctx, d.address, nil)
+	if err != nil {
+		return doctor.Warning("balance request failed: "+err.Error(), "check WS_ENDPOINT")
+	}
+	detail := fmt.Sprintf("%s wei", balance)
+	switch {
+	case balance.Sign() == 0:
+		return doctor.Failure(detail, "fund "+d.address.Hex()+" on L1 to pay for transactions")
+	case balance.Cmp(big.NewInt(lowBalanceWei)) < 0:
+		return doctor.Warning(detail+", below 0.01 ETH", "top up "+d.address.Hex()+" before gas costs stall bidding")
+	}
+	return doctor.Pass(detail)
+}
+
+func (d *diagnosis) checkBidderNode(ctx context.Context) doctor.Result {
+	bidder, err := bb.NewBidderClient(d.cfg.BidderConfig())
+	if err != nil {
+		return doctor.Failure("cannot create bidder client: "+err.Error(), "check SERVER_ADDRESS")
+	}
+	if d.head == nil {
+		return doctor.Skipped("no head block to derive the bidding window from")
+	}
+	window := bb.WindowForBlock(d.head.Number.Uint64()+d.offset, d.blocksPerWindow)
+	deposit, err := bidder.WindowDeposit(ctx, window)
+	if err != nil {
+		return doctor.Failure(
+			fmt.Sprintf("bidder API at %s unreachable: %v", d.serverAddress, err),
+			"start the mev-commit bidder node or fix SERVER_ADDRESS",
+		)
+	}
+	if deposit.Sign() == 0 {
+		return doctor.Warning(
+			fmt.Sprintf("no deposit in current window %d", window),
+			"enable --"+FlagAutoRollover+" or deposit into the bidding window through the bidder node",
+		)
+	}
+	return doctor.Pass(fmt.Sprintf("%s reachable, window %d deposit %s wei", d.serverAddress, window, deposit))
+}
+
+func (d *diagnosis) checkProviders(ctx context.Context) doctor.Result {
+	if d.bidderHTTP == "" {
+		return doctor.Skipped("no bidder HTTP address configured")
+	}
+	url := strings.TrimRight(d.bidderHTTP, "/") + "/topology"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return doctor.Warning("invalid bidder HTTP address: "+err.Error(), "fix --"+FlagDoctorBidderHTTP)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return doctor.Warning("topology request failed: "+err.Error(), "expose the bidder node's HTTP API or set --"+FlagDoctorBidderHTTP)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return doctor.Warning("topology request returned "+resp.Status, "check --"+FlagDoctorBidderHTTP)
+	}
+
+	var topology struct {
+		Self           map[string]any `json:"self"`
+		ConnectedPeers struct {
+			Providers []any `json:"providers"`
+		} `json:"connected_peers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&topology); err != nil {
+		return doctor.Warning("cannot parse topology: "+err.Error(), "check that --"+FlagDoctorBidderHTTP+" points at a mev-commit node")
+	}
+	providers := len(topology.ConnectedPeers.Providers)
+	version, _ := topology.Self["version"].(string)
+	detail := fmt.Sprintf("%d connected providers", providers)
+	if version != "" {
+		detail = fmt.Sprintf("node version %s, %s", version, detail)
+	}
+	if providers == 0 {
+		return doctor.Failure(detail, "wait for the bidder node to discover providers, or check its bootnodes and network")
+	}
+	return doctor.Pass(detail)
+}
+
+func (d *diagnosis) checkContracts(context.Context) doctor.Result {
+	addresses := []struct {
+		name string
+		addr common.Address
+	}{
+		{"BIDDER_REGISTRY_ADDRESS", bb.BidderRegistryAddress},
+		{"BLOCK_TRACKER_ADDRESS", bb.BlockTrackerAddress},
+		{"PRECONF_MANAGER_ADDRESS", bb.PreconfManagerAddress},
+	}
+	seen := make(map[common.Address]string)
+	for _, a := range addresses {
+		name, addr := a.name, a.addr
+		if addr == (common.Address{}) {
+			return doctor.Failure(name+" is the zero address", "set "+name+" to the deployed contract address")
+		}
+		if other, ok := seen[addr]; ok {
+			return doctor.Failure(name+" and "+other+" are the same address", "check the contract addresses in your environment")
+		}
+		seen[addr] = name
+	}
+
+	var missing []string
+	for _, name := range []string{"BidderRegistry", "BlockTracker", "PreconfManager"} {
+		path := filepath.Join("abi", name+".abi")
+		data, err := os.ReadFile(path)
+		if err == nil {
+			_, err = abi.JSON(strings.NewReader(string(data)))
+		}
+		if err != nil {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) > 0 {
+		return doctor.Warning(
+			"contract ABIs unavailable: "+strings.Join(missing, ", "),
+			"run from a directory containing the abi/ folder to use the contract helpers",
+		)
+	}
+	return doctor.Pass("contract addresses set and ABIs parse")
+}
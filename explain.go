@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/primev/preconf_blob_bidder/internal/explain"
+	"github.com/urfave/cli/v2"
+)
+
+// explainCommand prints what a setting, metric or log event means, from the
+// registry built into the binary, so the answer always matches the running
+// version.
+func explainCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "explain",
+		Usage:     "Describe a setting, metric or log event: its default, valid range and related settings",
+		ArgsUsage: "<flag|metric|event>",
+		Action: func(c *cli.Context) error {
+			usage := make(map[string]string)
+			for _, flag := range c.App.Flags {
+				if doc, ok := flag.(cli.DocGenerationFlag); ok {
+					usage[flag.Names()[0]] = doc.GetUsage()
+				}
+			}
+			registry := explain.New(usage)
+			w := c.App.Writer
+
+			name := strings.Join(c.Args().Slice(), " ")
+			if name == "" {
+				for _, kind := range []explain.Kind{explain.Setting, explain.Metric, explain.Event} {
+					fmt.Fprintf(w, "%ss:\n", kind)
+					for _, e := range registry.Entries() {
+						if e.Kind == kind {
+							fmt.Fprintf(w, "  %s\n", e.Name)
+						}
+					}
+				}
+				return nil
+			}
+			found := registry.Lookup(name)
+			if len(found) == 0 {
+				msg := fmt.Sprintf("no setting, metric or event called %q", name)
+				if suggestions := registry.Suggest(name); len(suggestions) > 0 {
+					msg += ", did you mean: " + strings.Join(suggestions, ", ")
+				}
+				return cli.Exit(msg, 1)
+			}
+			for i, e := range found {
+				if i > 0 {
+					fmt.Fprintln(w)
+				}
+				if err := e.Write(w); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/primev/preconf_blob_bidder/internal/accounting"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	FlagExportLedger = "ledger"
+	FlagExportFormat = "format"
+	FlagExportOut    = "out"
+)
+
+// exportActivityCommand converts the wallet activity ledger into CSV for tax
+// and accounting tools.
+func exportActivityCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "export-activity",
+		Usage: "Export L1 transactions, gas costs, bid payments, deposits and withdrawals as accounting CSV",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     FlagExportLedger,
+				Usage:    "Activity ledger written with --activity-file",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  FlagExportFormat,
+				Usage: "CSV format: " + strings.Join(accounting.Formats, ", "),
+				Value: "generic",
+			},
+			&cli.StringFlag{
+				Name:  FlagExportOut,
+				Usage: "Output file (defaults to stdout)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			f, err := os.Open(c.String(FlagExportLedger))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			entries, err := accounting.ReadEntries(f)
+			if err != nil {
+				return fmt.Errorf("failed to read activity ledger: %w", err)
+			}
+
+			var w io.Writer = c.App.Writer
+			if out := c.String(FlagExportOut); out != "" {
+				file, err := os.Create(out)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				w = file
+			}
+			return accounting.WriteCSV(w, c.String(FlagExportFormat), entries)
+		},
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/primev/preconf_blob_bidder/internal/config"
+	ee "github.com/primev/preconf_blob_bidder/internal/eth"
+	"github.com/primev/preconf_blob_bidder/internal/fanout"
+	"github.com/primev/preconf_blob_bidder/internal/ident"
+	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	FlagFanoutBidsPerBlock = "bids-per-block"
+	FlagFanoutMinAmount    = "min-amount"
+	FlagFanoutMaxAmount    = "max-amount"
+	FlagFanoutDistribution = "amount-distribution"
+	FlagFanoutSpendCap     = "spend-cap"
+	FlagFanoutPrivateKeys  = "fanout-private-keys"
+	FlagFanoutBlocks       = "blocks"
+	FlagFanoutTestnet      = "testnet"
+)
+
+// fanoutCommand stress tests provider-side bid handling with many distinct
+// small bids per block. Connections and the primary account come from the same
+// settings as the bidder itself.
+func fanoutCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "fanout",
+		Usage: "Stress test providers with many small bids per block from several accounts (testnet only)",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  FlagFanoutBidsPerBlock,
+				Usage: fmt.Sprintf("Distinct bids sent per block, at most %d", fanout.MaxBidsPerBlock),
+				Value: 20,
+			},
+			&cli.Float64Flag{
+				Name:  FlagFanoutMinAmount,
+				Usage: "Smallest bid amount in ETH",
+				Value: 0.00001,
+			},
+			&cli.Float64Flag{
+				Name:  FlagFanoutMaxAmount,
+				Usage: "Largest bid amount in ETH",
+				Value: 0.0001,
+			},
+			&cli.StringFlag{
+				Name:  FlagFanoutDistribution,
+				Usage: "How bid amounts are drawn between the minimum and maximum: uniform or exponential",
+				Value: string(fanout.Uniform),
+			},
+			&cli.Float64Flag{
+				Name:     FlagFanoutSpendCap,
+				Usage:    "Most ETH the run may commit to; bids in flight count against it",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    FlagFanoutPrivateKeys,
+				Usage:   "Comma-separated private keys of accounts bidding alongside PRIVATE_KEY or KEYSTORE_PATH",
+				EnvVars: []string{"FANOUT_PRIVATE_KEYS"},
+			},
+			&cli.IntFlag{
+				Name:  FlagFanoutBlocks,
+				Usage: "Blocks to bid on before the run ends",
+				Value: 10,
+			},
+			&cli.BoolFlag{
+				Name:  FlagFanoutTestnet,
+				Usage: "Confirm that the run targets a testnet; fan-out refuses to start without it",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := loadConfig(c)
+			if err != nil {
+				return err
+			}
+			distribution, err := fanout.ParseDistribution(c.String(FlagFanoutDistribution))
+			if err != nil {
+				return err
+			}
+			fcfg := fanout.Config{
+				BidsPerBlock: c.Int(FlagFanoutBidsPerBlock),
+				MinAmount:    c.Float64(FlagFanoutMinAmount),
+				MaxAmount:    c.Float64(FlagFanoutMaxAmount),
+				Distribution: distribution,
+				SpendCap:     c.Float64(FlagFanoutSpendCap),
+			}
+			if err := fcfg.Validate(); err != nil {
+				return fmt.Errorf("invalid fan-out settings: %w", err)
+			}
+			if !c.Bool(FlagFanoutTestnet) {
+				return fmt.Errorf("fan-out stress tests are testnet only; pass --%s to confirm", FlagFanoutTestnet)
+			}
+			if cfg.Network == "mainnet" {
+				return fmt.Errorf("refusing to run a fan-out stress test on the mev-commit mainnet")
+			}
+			if err := resolveContracts(c.Context, cfg); err != nil {
+				return err
+			}
+			decay, err := cfg.DecayBounds().Apply(bb.DecayWindow(cfg.Offset))
+			if err != nil {
+				return err
+			}
+
+			client, err := ident.DialEth(c.Context, bb.ParseWSEndpoints(cfg.WSEndpoint)[0])
+			if err != nil {
+				return fmt.Errorf("failed to connect to the WebSocket endpoint: %w", err)
+			}
+			defer client.Close()
+			chainID, err := client.ChainID(c.Context)
+			if err != nil {
+				return fmt.Errorf("failed to fetch the chain ID: %w", err)
+			}
+			if chainID.Int64() == config.MainnetChainID {
+				return fmt.Errorf("refusing to run a fan-out stress test on Ethereum mainnet")
+			}
+
+			// Fan-out signs in process, so account 0 cannot come from a remote signer
+			primary := cfg
+			primary.RemoteSignerURL = ""
+			account, err := openPrimaryAccount(c.Context, primary, chainID)
+			if errors.Is(err, errNoSigner) {
+				return fmt.Errorf("fan-out requires PRIVATE_KEY or KEYSTORE_PATH")
+			}
+			if err != nil {
+				return fmt.Errorf("failed to authenticate fan-out account 0: %w", err)
+			}
+			accounts := []bb.AuthAcct{account.AuthAcct}
+			for _, key := range strings.Split(c.String(FlagFanoutPrivateKeys), ",") {
+				key = strings.TrimSpace(key)
+				if key == "" {
+					continue
+				}
+				acct, err := bb.AuthenticateAddress(key, client)
+				if err != nil {
+					return fmt.Errorf("failed to authenticate fan-out account %d: %w", len(accounts), err)
+				}
+				accounts = append(accounts, acct)
+			}
+
+			bidderClient, err := bb.NewBidderClient(cfg.BidderConfig())
+			if err != nil {
+				return fmt.Errorf("failed to connect to mev-commit bidder API: %w", err)
+			}
+			defer bidderClient.Close()
+
+			headers := make(chan *types.Header)
+			sub, err := client.SubscribeNewHead(c.Context, headers)
+			if err != nil {
+				return fmt.Errorf("failed to subscribe to new blocks: %w", err)
+			}
+			defer sub.Unsubscribe()
+
+			seed := cfg.Seed
+			if seed == 0 {
+				seed = time.Now().UnixNano()
+			}
+			run := &fanoutRun{
+				cfg:      fcfg,
+				client:   ee.NewClient(client),
+				bidder:   bidderClient,
+				accounts: accounts,
+				offset:   cfg.Offset,
+				decay:    decay,
+				fees:     cfg.FeeOracle(),
+				rng:      rand.New(rand.NewSource(seed)),
+				budget:   fanout.NewBudget(fcfg.SpendCap),
+				stats:    fanout.NewStats(),
+			}
+			slog.Info("Fan-out stress test started",
+				"bidsPerBlock", fcfg.BidsPerBlock,
+				"accounts", len(accounts),
+				"distribution", fcfg.Distribution,
+				"spendCap", fcfg.SpendCap,
+				"blocks", c.Int(FlagFanoutBlocks),
+			)
+
+		loop:
+			for blocks := 0; blocks < c.Int(FlagFanoutBlocks); {
+				select {
+				case <-c.Context.Done():
+					break loop
+				case err := <-sub.Err():
+					slog.Error("Block subscription failed", "error", err)
+					break loop
+				case header := <-headers:
+					run.bidOn(header)
+					blocks++
+					run.stats.Summary().Log("Fan-out progress")
+				}
+			}
+			run.wg.Wait()
+
+			summary := run.stats.Summary()
+			summary.Log("Fan-out stress test finished")
+			enc := json.NewEncoder(c.App.Writer)
+			enc.SetIndent("", "  ")
+			return enc.Encode(summary)
+		},
+	}
+}
+
+// fanoutRun sends the bids of a fan-out stress test.
+type fanoutRun struct {
+	cfg      fanout.Config
+	client   *ee.Client
+	bidder   bb.BidderInterface
+	accounts []bb.AuthAcct
+	offset   uint64
+	decay    time.Duration
+	fees     ee.FeeOracle
+	rng      *rand.Rand
+	budget   *fanout.Budget
+	stats    *fanout.Stats
+	wg       sync.WaitGroup
+}
+
+// bidOn spreads the bids for the block after header over the accounts. Each
+// account signs distinct transfers with consecutive nonces, so every bid
+// carries its own transaction.
+func (r *fanoutRun) bidOn(header *types.Header) {
+	for i, acct := range r.accounts {
+		count := r.cfg.BidsPerBlock / len(r.accounts)
+		if i < r.cfg.BidsPerBlock%len(r.accounts) {
+			count++
+		}
+		if count == 0 {
+			continue
+		}
+		txs, target, err := ee.SelfETHTransfers(r.client, ee.NewLocalSigner(acct.PrivateKey), big.NewInt(0), count, r.offset, r.fees)
+		if err != nil {
+			slog.Error("Failed to build fan-out transactions", "error", err, "address", acct.Address.Hex())
+			continue
+		}
+		for _, tx := range txs {
+			amount := r.cfg.Amount(r.rng)
+			if !r.budget.Reserve(amount) {
+				r.stats.CapReached(target)
+				continue
+			}
+			r.wg.Add(1)
+			go func(tx *types.Transaction, address string, amount float64) {
+				defer r.wg.Done()
+				start := time.Now()
+				report := bb.SendPreconfBidReport(context.Background(), r.bidder, tx, int64(target), amount, r.decay)
+				r.budget.Settle(amount, len(report.Commitments) > 0)
+				r.stats.Record(target, fanout.Result{
+					Account:     address,
+					Amount:      amount,
+					Sent:        report.Sent,
+					Commitments: len(report.Commitments),
+					Latency:     time.Since(start),
+				})
+			}(tx, acct.Address.Hex(), amount)
+		}
+	}
+}
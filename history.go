@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/provenance"
+	"github.com/primev/preconf_blob_bidder/internal/store"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	FlagHistoryDB        = "db"
+	FlagHistoryFormat    = "format"
+	FlagHistoryOut       = "out"
+	FlagHistorySince     = "since"
+	FlagHistoryFromBlock = "from-block"
+	FlagHistoryToBlock   = "to-block"
+	FlagHistoryStatus    = "status"
+	FlagHistoryTenant    = "tenant"
+	FlagHistoryCampaign  = "campaign"
+	FlagHistoryLimit     = "limit"
+	FlagHistoryHourly    = "hourly"
+	FlagHistoryVerify    = "verify"
+)
+
+// historyCommand queries the bid history recorded with --bid-history-file and
+// exports it as CSV or JSON.
+func historyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "history",
+		Usage: "Query the bid history and export it as CSV or JSON",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     FlagHistoryDB,
+				Usage:    "Bid history database written with --bid-history-file",
+				EnvVars:  []string{"BID_HISTORY_FILE"},
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  FlagHistoryFormat,
+				Usage: "Output format: " + strings.Join(store.Formats, ", "),
+				Value: "csv",
+			},
+			&cli.StringFlag{
+				Name:  FlagHistoryOut,
+				Usage: "Output file (defaults to stdout)",
+			},
+			&cli.DurationFlag{
+				Name:  FlagHistorySince,
+				Usage: "Only bids sent within this duration before now, e.g. 24h",
+			},
+			&cli.Uint64Flag{
+				Name:  FlagHistoryFromBlock,
+				Usage: "Only bids for this block number or later",
+			},
+			&cli.Uint64Flag{
+				Name:  FlagHistoryToBlock,
+				Usage: "Only bids for this block number or earlier",
+			},
+			&cli.StringFlag{
+				Name:  FlagHistoryStatus,
+				Usage: "Only bids with this status: failed, no-commitment, committed, stored or not-stored",
+			},
+			&cli.StringFlag{
+				Name:  FlagHistoryTenant,
+				Usage: "Only bids made for this tenant",
+			},
+			&cli.StringFlag{
+				Name:  FlagHistoryCampaign,
+				Usage: "Only bids made in this campaign",
+			},
+			&cli.IntFlag{
+				Name:  FlagHistoryLimit,
+				Usage: "Only the most recent bids, 0 for all",
+			},
+			&cli.BoolFlag{
+				Name:  FlagHistoryHourly,
+				Usage: "Export hourly aggregates per lane and status instead of bids, including bids compacted after the retention; only --since applies",
+			},
+			&cli.BoolFlag{
+				Name:  FlagHistoryVerify,
+				Usage: "Verify the signatures of the selected bids instead of exporting them; fails if any signature is invalid",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			path := c.String(FlagHistoryDB)
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("bid history not found: %w", err)
+			}
+			history, err := store.Open(path)
+			if err != nil {
+				return err
+			}
+			defer history.Close()
+
+			filter := store.Filter{
+				FromBlock: c.Uint64(FlagHistoryFromBlock),
+				ToBlock:   c.Uint64(FlagHistoryToBlock),
+				Status:    store.Status(c.String(FlagHistoryStatus)),
+				Tenant:    c.String(FlagHistoryTenant),
+				Campaign:  c.String(FlagHistoryCampaign),
+				Limit:     c.Int(FlagHistoryLimit),
+			}
+			if since := c.Duration(FlagHistorySince); since > 0 {
+				filter.Since = time.Now().Add(-since)
+			}
+			hourly := c.Bool(FlagHistoryHourly) && !c.Bool(FlagHistoryVerify)
+			var (
+				bids []store.Bid
+				aggs []store.Aggregate
+			)
+			if hourly {
+				aggs, err = history.Aggregates(c.Context, filter.Since)
+			} else {
+				bids, err = history.Query(c.Context, filter)
+			}
+			if err != nil {
+				return err
+			}
+
+			if c.Bool(FlagHistoryVerify) {
+				return verifyBids(c.App.Writer, bids)
+			}
+
+			var w io.Writer = c.App.Writer
+			if out := c.String(FlagHistoryOut); out != "" {
+				file, err := os.Create(out)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				w = file
+			}
+			if hourly {
+				return store.WriteAggregates(w, c.String(FlagHistoryFormat), aggs)
+			}
+			return store.Write(w, c.String(FlagHistoryFormat), bids)
+		},
+	}
+}
+
+// verifyBids checks the provenance signature of every bid, listing those that
+// do not verify.
+func verifyBids(w io.Writer, bids []store.Bid) error {
+	var valid, unsigned, invalid int
+	for _, b := range bids {
+		err := provenance.Verify(b.Provenance(), b.Signature)
+		switch {
+		case err == nil:
+			valid++
+		case errors.Is(err, provenance.ErrUnsigned):
+			unsigned++
+		default:
+			invalid++
+			fmt.Fprintf(w, "bid %d (%s, block %d): %v\n", b.ID, b.TxHash, b.BlockNumber, err)
+		}
+	}
+	fmt.Fprintf(w, "%d bids: %d valid, %d unsigned, %d invalid signatures\n", len(bids), valid, unsigned, invalid)
+	if invalid > 0 {
+		return cli.Exit("bid history contains invalid signatures", 1)
+	}
+	return nil
+}
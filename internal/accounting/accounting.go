@@ -0,0 +1,208 @@
+// Package accounting keeps a ledger of the wallet activity caused by the bidder
+// (L1 transactions, bid payments, deposits and withdrawals) and exports it in
+// CSV formats understood by common tax and accounting tools.
+package accounting
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/money"
+)
+
+// Kind is the type of a ledger entry.
+type Kind string
+
+const (
+	KindL1Tx       Kind = "l1_tx"       // Transaction submitted on L1; Fee is its maximum fee.
+	KindBidPayment Kind = "bid_payment" // Bid amount committed to a provider.
+	KindDeposit    Kind = "deposit"     // Deposit into a bidding window.
+	KindWithdrawal Kind = "withdrawal"  // Refund withdrawn from a settled window.
+)
+
+// Entry is a single wallet activity.
+type Entry struct {
+	Time         time.Time `json:"time"`
+	Kind         Kind      `json:"kind"`
+	TxHash       string    `json:"tx_hash,omitempty"`
+	Block        uint64    `json:"block,omitempty"`
+	Amount       *big.Int  `json:"amount_wei,omitempty"`
+	Fee          *big.Int  `json:"fee_wei,omitempty"`
+	Counterparty string    `json:"counterparty,omitempty"` // Provider address or bidding window.
+	Note         string    `json:"note,omitempty"`
+	Tenant       string    `json:"tenant,omitempty"`   // Customer the bidder runs for, see package labels.
+	Campaign     string    `json:"campaign,omitempty"` // Scheduled campaign the entry arose in.
+}
+
+// Ledger appends entries to a JSON lines file.
+type Ledger struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// NewLedger opens (or creates) a ledger file for appending.
+func NewLedger(path string) (*Ledger, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open activity ledger: %w", err)
+	}
+	return &Ledger{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Add appends an entry, stamping it with the current time if unset.
+func (l *Ledger) Add(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	return l.enc.Encode(e)
+}
+
+// Close closes the underlying file.
+func (l *Ledger) Close() error {
+	return l.f.Close()
+}
+
+// ReadEntries reads every entry from a ledger and sorts them by time.
+func ReadEntries(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return entries, nil
+}
+
+// Formats lists the supported export formats.
+var Formats = []string{"generic", "koinly"}
+
+// WriteCSV writes entries in the given export format.
+func WriteCSV(w io.Writer, format string, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	var err error
+	switch format {
+	case "", "generic":
+		err = writeGeneric(cw, entries)
+	case "koinly":
+		err = writeKoinly(cw, entries)
+	default:
+		return fmt.Errorf("unknown export format %q (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeGeneric(cw *csv.Writer, entries []Entry) error {
+	if err := cw.Write([]string{"timestamp", "kind", "tx_hash", "block", "amount_eth", "fee_eth", "counterparty", "note"}); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		block := ""
+		if e.Block > 0 {
+			block = strconv.FormatUint(e.Block, 10)
+		}
+		if err := cw.Write([]string{
+			e.Time.UTC().Format(time.RFC3339),
+			string(e.Kind),
+			e.TxHash,
+			block,
+			FormatEth(e.Amount),
+			FormatEth(e.Fee),
+			e.Counterparty,
+			e.Note,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeKoinly writes the Koinly universal format. Bid payments and L1 fees are
+// labeled as costs; deposits and withdrawals are transfers between the wallet
+// and the bidder's mev-commit account.
+func writeKoinly(cw *csv.Writer, entries []Entry) error {
+	header := []string{
+		"Date", "Sent Amount", "Sent Currency", "Received Amount", "Received Currency",
+		"Fee Amount", "Fee Currency", "Net Worth Amount", "Net Worth Currency",
+		"Label", "Description", "TxHash",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		var sent, received, fee, label string
+		switch e.Kind {
+		case KindL1Tx:
+			sent, label = FormatEth(e.Fee), "cost"
+		case KindBidPayment:
+			sent, label = FormatEth(e.Amount), "cost"
+		case KindDeposit:
+			sent, fee = FormatEth(e.Amount), FormatEth(e.Fee)
+		case KindWithdrawal:
+			received, fee = FormatEth(e.Amount), FormatEth(e.Fee)
+		default:
+			continue
+		}
+		description := string(e.Kind)
+		if e.Counterparty != "" {
+			description += " " + e.Counterparty
+		}
+		if e.Note != "" {
+			description += ": " + e.Note
+		}
+		if err := cw.Write([]string{
+			e.Time.UTC().Format("2006-01-02 15:04:05 UTC"),
+			sent, currency(sent),
+			received, currency(received),
+			fee, currency(fee),
+			"", "",
+			label,
+			description,
+			e.TxHash,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func currency(amount string) string {
+	if amount == "" {
+		return ""
+	}
+	return "ETH"
+}
+
+// FormatEth formats a wei amount as an exact decimal ETH string. A nil amount
+// formats as an empty string.
+func FormatEth(wei *big.Int) string {
+	return money.FormatEth(wei)
+}
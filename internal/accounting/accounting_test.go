@@ -0,0 +1,50 @@
+package accounting
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerRoundTripAndExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "activity.jsonl")
+	l, err := NewLedger(path)
+	require.NoError(t, err)
+
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, l.Add(Entry{Time: t0.Add(time.Minute), Kind: KindBidPayment, TxHash: "0xabc", Block: 100, Amount: big.NewInt(1e15), Counterparty: "0xprovider"}))
+	require.NoError(t, l.Add(Entry{Time: t0, Kind: KindDeposit, Amount: big.NewInt(1e17), Counterparty: "window 7"}))
+	require.NoError(t, l.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	entries, err := ReadEntries(f)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, KindDeposit, entries[0].Kind, "entries are sorted by time")
+
+	var generic bytes.Buffer
+	require.NoError(t, WriteCSV(&generic, "generic", entries))
+	require.Equal(t, "timestamp,kind,tx_hash,block,amount_eth,fee_eth,counterparty,note\n"+
+		"2024-05-01T12:00:00Z,deposit,,,0.1,,window 7,\n"+
+		"2024-05-01T12:01:00Z,bid_payment,0xabc,100,0.001,,0xprovider,\n", generic.String())
+
+	var koinly bytes.Buffer
+	require.NoError(t, WriteCSV(&koinly, "koinly", entries))
+	require.Contains(t, koinly.String(), "2024-05-01 12:01:00 UTC,0.001,ETH,,,,,,,cost,bid_payment 0xprovider,0xabc\n")
+
+	require.Error(t, WriteCSV(&koinly, "bogus", entries))
+}
+
+func TestFormatEth(t *testing.T) {
+	require.Equal(t, "", FormatEth(nil))
+	require.Equal(t, "0", FormatEth(big.NewInt(0)))
+	require.Equal(t, "1.000000000000000001", FormatEth(new(big.Int).Add(big.NewInt(1e18), big.NewInt(1))))
+	require.Equal(t, "-0.5", FormatEth(big.NewInt(-5e17)))
+}
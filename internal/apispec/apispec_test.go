@@ -0,0 +1,116 @@
+package apispec
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestFromServiceBidderBindings(t *testing.T) {
+	doc := FromService("bidder", "v1", BidderService())
+
+	// SendBid is bound to POST /v1/bidder/bid with the whole Bid as body.
+	bid, ok := doc.Paths["/v1/bidder/bid"]
+	require.True(t, ok, "expected SendBid path to be present")
+	require.NotNil(t, bid.Post)
+	require.NotNil(t, bid.Post.RequestBody)
+	require.Equal(t, "#/components/schemas/bidderapi_v1_Bid",
+		bid.Post.RequestBody.Content["application/json"].Schema.Ref)
+	require.NotEmpty(t, bid.Post.Description, "streaming RPCs should be documented as such")
+
+	// Deposit binds {amount} as a path parameter.
+	deposit, ok := doc.Paths["/v1/bidder/deposit/{amount}"]
+	require.True(t, ok)
+	require.NotNil(t, deposit.Post)
+	require.Equal(t, "amount", deposit.Post.Parameters[0].Name)
+	require.Equal(t, "path", deposit.Post.Parameters[0].In)
+
+	// GetDeposit has no body so its fields become query parameters.
+	get, ok := doc.Paths["/v1/bidder/get_deposit"]
+	require.True(t, ok)
+	require.NotNil(t, get.Get)
+	require.Len(t, get.Get.Parameters, 1)
+	require.Equal(t, "query", get.Get.Parameters[0].In)
+	require.Equal(t, "uint64", get.Get.Parameters[0].Schema.Format)
+
+	bidSchema := doc.Components.Schemas["bidderapi_v1_Bid"]
+	require.NotNil(t, bidSchema)
+	require.Equal(t, "array", bidSchema.Properties["rawTransactions"].Type)
+	require.Equal(t, "int64", bidSchema.Properties["blockNumber"].Format)
+
+	raw, err := doc.JSON()
+	require.NoError(t, err)
+	require.True(t, json.Valid(raw))
+}
+
+func TestBidderDescriptorSetIsSelfContained(t *testing.T) {
+	raw, err := BidderDescriptorSet()
+	require.NoError(t, err)
+
+	var set descriptorpb.FileDescriptorSet
+	require.NoError(t, proto.Unmarshal(raw, &set))
+	require.Equal(t, "bidderapi/v1/bidderapi.proto", set.File[len(set.File)-1].GetName())
+
+	// The set must resolve without the global registry.
+	_, err = protodesc.NewFiles(&set)
+	require.NoError(t, err)
+}
+
+func TestMergeAndOperationIDs(t *testing.T) {
+	a := New("a", "1")
+	a.AddOperation("GET", "/healthz", &Operation{OperationID: "healthz"})
+
+	b := New("b", "1")
+	b.AddTag("status", "")
+	b.AddOperation("get", "/status", &Operation{OperationID: "status"})
+	b.AddSchema("Status", &Schema{Type: "object"})
+
+	a.Merge(b)
+	require.Equal(t, []string{"healthz", "status"}, a.OperationIDs())
+	require.Contains(t, a.Components.Schemas, "Status")
+	require.Len(t, a.Tags, 1)
+}
+
+func TestSchemaOf(t *testing.T) {
+	type Inner struct {
+		Count uint64 `json:"count"`
+	}
+	type Outer struct {
+		Inner
+		At      time.Time         `json:"at"`
+		Wei     *big.Int          `json:"wei,omitempty"`
+		Tags    []string          `json:"tags,omitempty"`
+		ByName  map[string]*Inner `json:"by_name"`
+		Any     interface{}       `json:"any"`
+		Skipped string            `json:"-"`
+		hidden  string
+	}
+	doc := New("a", "1")
+	ref := doc.SchemaOf([]Outer{})
+	require.Equal(t, "array", ref.Type)
+	require.Equal(t, "#/components/schemas/apispec_Outer", ref.Items.Ref)
+
+	outer := doc.Components.Schemas["apispec_Outer"]
+	require.NotNil(t, outer)
+	require.ElementsMatch(t, []string{"count", "at", "wei", "tags", "by_name", "any"}, keys(outer.Properties))
+	require.ElementsMatch(t, []string{"count", "at", "by_name", "any"}, outer.Required)
+	require.Equal(t, "uint64", outer.Properties["count"].Format)
+	require.Equal(t, "date-time", outer.Properties["at"].Format)
+	require.Equal(t, "integer", outer.Properties["wei"].Type)
+	require.Equal(t, "#/components/schemas/apispec_Inner", outer.Properties["by_name"].AdditionalProperties.Ref)
+	require.Contains(t, doc.Components.Schemas, "apispec_Inner")
+}
+
+func keys(m map[string]*Schema) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -0,0 +1,284 @@
+package apispec
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// pathParamRe matches gRPC-gateway path templates such as {amount} or {name=*}.
+var pathParamRe = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?\}`)
+
+// BidderService returns the descriptor of the mev-commit bidder gRPC service
+// the bot talks to.
+func BidderService() protoreflect.ServiceDescriptor {
+	return pb.File_bidderapi_v1_bidderapi_proto.Services().ByName("Bidder")
+}
+
+// DescriptorSet returns a FileDescriptorSet containing the given file and all
+// of its transitive imports, in dependency order. The result can be consumed by
+// protoc, buf or grpcurl to generate clients in any language.
+func DescriptorSet(fd protoreflect.FileDescriptor) *descriptorpb.FileDescriptorSet {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+
+	var visit func(f protoreflect.FileDescriptor)
+	visit = func(f protoreflect.FileDescriptor) {
+		if seen[f.Path()] {
+			return
+		}
+		seen[f.Path()] = true
+		imports := f.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			visit(imports.Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(f))
+	}
+	visit(fd)
+
+	return set
+}
+
+// BidderDescriptorSet returns the serialized FileDescriptorSet of the bidder API.
+func BidderDescriptorSet() ([]byte, error) {
+	return proto.Marshal(DescriptorSet(pb.File_bidderapi_v1_bidderapi_proto))
+}
+
+// FromService builds an OpenAPI document for the HTTP bindings declared on a
+// gRPC service via google.api.http annotations. Methods without an HTTP
+// binding are skipped.
+func FromService(title, version string, sd protoreflect.ServiceDescriptor) *Document {
+	doc := New(title, version)
+	tag := string(sd.Name())
+	doc.AddTag(tag, fmt.Sprintf("gRPC service %s exposed through grpc-gateway.", sd.FullName()))
+
+	methods := sd.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		md := methods.Get(i)
+		rule, ok := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if !ok || rule == nil {
+			continue
+		}
+		method, path := httpBinding(rule)
+		if method == "" {
+			continue
+		}
+
+		op := &Operation{
+			OperationID: fmt.Sprintf("%s_%s", sd.Name(), md.Name()),
+			Summary:     fmt.Sprintf("%s.%s", sd.FullName(), md.Name()),
+			Tags:        []string{tag},
+			Responses: map[string]*Response{
+				"200": {
+					Description: "A successful response.",
+					Content:     JSONBody(schemaRef(doc, md.Output())),
+				},
+				"default": {
+					Description: "An unexpected error response.",
+					Content:     JSONBody(&Schema{Type: "object"}),
+				},
+			},
+		}
+		if md.IsStreamingServer() {
+			op.Description = "Server-streaming RPC: the gateway returns one JSON object per message, each wrapped in a `result` field."
+		}
+
+		pathFields := make(map[string]bool)
+		for _, m := range pathParamRe.FindAllStringSubmatch(path, -1) {
+			name := m[1]
+			pathFields[name] = true
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   fieldSchemaByName(doc, md.Input(), name),
+			})
+		}
+		path = pathParamRe.ReplaceAllString(path, "{$1}")
+
+		switch rule.GetBody() {
+		case "":
+			// Remaining top-level fields are bound to query parameters.
+			fields := md.Input().Fields()
+			for j := 0; j < fields.Len(); j++ {
+				f := fields.Get(j)
+				if pathFields[string(f.Name())] {
+					continue
+				}
+				op.Parameters = append(op.Parameters, Parameter{
+					Name:   f.JSONName(),
+					In:     "query",
+					Schema: fieldSchema(doc, f),
+				})
+			}
+		case "*":
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  JSONBody(schemaRef(doc, md.Input())),
+			}
+		default:
+			f := md.Input().Fields().ByName(protoreflect.Name(rule.GetBody()))
+			if f != nil {
+				op.RequestBody = &RequestBody{
+					Required: true,
+					Content:  JSONBody(fieldSchema(doc, f)),
+				}
+			}
+		}
+
+		doc.AddOperation(method, path, op)
+	}
+
+	return doc
+}
+
+// httpBinding extracts the HTTP method and path template from an HttpRule.
+func httpBinding(rule *annotations.HttpRule) (string, string) {
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return "GET", p.Get
+	case *annotations.HttpRule_Post:
+		return "POST", p.Post
+	case *annotations.HttpRule_Put:
+		return "PUT", p.Put
+	case *annotations.HttpRule_Patch:
+		return "PATCH", p.Patch
+	case *annotations.HttpRule_Delete:
+		return "DELETE", p.Delete
+	default:
+		return "", ""
+	}
+}
+
+// schemaName returns the component name used for a message.
+func schemaName(md protoreflect.MessageDescriptor) string {
+	return strings.ReplaceAll(string(md.FullName()), ".", "_")
+}
+
+// schemaRef registers the message (and any nested messages) as component
+// schemas and returns a reference to it. Well-known wrapper types are inlined.
+func schemaRef(doc *Document, md protoreflect.MessageDescriptor) *Schema {
+	if s := wellKnownSchema(md); s != nil {
+		return s
+	}
+
+	name := schemaName(md)
+	if _, ok := doc.Components.Schemas[name]; ok {
+		return Ref(name)
+	}
+
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	// Register before walking fields so recursive messages terminate.
+	doc.AddSchema(name, s)
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.Get(i)
+		s.Properties[f.JSONName()] = fieldSchema(doc, f)
+	}
+
+	return Ref(name)
+}
+
+// fieldSchemaByName resolves the schema of a (possibly dotted) field path.
+func fieldSchemaByName(doc *Document, md protoreflect.MessageDescriptor, name string) *Schema {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		f := md.Fields().ByName(protoreflect.Name(part))
+		if f == nil {
+			return &Schema{Type: "string"}
+		}
+		if i == len(parts)-1 {
+			return fieldSchema(doc, f)
+		}
+		if f.Message() == nil {
+			return &Schema{Type: "string"}
+		}
+		md = f.Message()
+	}
+	return &Schema{Type: "string"}
+}
+
+// fieldSchema returns the JSON schema of a field following the proto3 JSON mapping.
+func fieldSchema(doc *Document, f protoreflect.FieldDescriptor) *Schema {
+	if f.IsMap() {
+		return &Schema{Type: "object", AdditionalProperties: fieldSchema(doc, f.MapValue())}
+	}
+
+	var s *Schema
+	switch f.Kind() {
+	case protoreflect.BoolKind:
+		s = &Schema{Type: "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		s = &Schema{Type: "integer", Format: "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		s = &Schema{Type: "integer", Format: "int64"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		// 64-bit integers are encoded as strings in the proto3 JSON mapping.
+		s = &Schema{Type: "string", Format: "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		s = &Schema{Type: "string", Format: "uint64"}
+	case protoreflect.FloatKind:
+		s = &Schema{Type: "number", Format: "float"}
+	case protoreflect.DoubleKind:
+		s = &Schema{Type: "number", Format: "double"}
+	case protoreflect.StringKind:
+		s = &Schema{Type: "string"}
+	case protoreflect.BytesKind:
+		s = &Schema{Type: "string", Format: "byte"}
+	case protoreflect.EnumKind:
+		values := f.Enum().Values()
+		s = &Schema{Type: "string"}
+		for i := 0; i < values.Len(); i++ {
+			s.Enum = append(s.Enum, string(values.Get(i).Name()))
+		}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		s = schemaRef(doc, f.Message())
+	default:
+		s = &Schema{Type: "string"}
+	}
+
+	if f.IsList() {
+		return &Schema{Type: "array", Items: s}
+	}
+	return s
+}
+
+// wellKnownSchema inlines google.protobuf wrapper types, which the JSON mapping
+// encodes as their underlying scalar.
+func wellKnownSchema(md protoreflect.MessageDescriptor) *Schema {
+	switch md.FullName() {
+	case "google.protobuf.UInt64Value":
+		return &Schema{Type: "string", Format: "uint64"}
+	case "google.protobuf.Int64Value":
+		return &Schema{Type: "string", Format: "int64"}
+	case "google.protobuf.UInt32Value":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "google.protobuf.Int32Value":
+		return &Schema{Type: "integer", Format: "int32"}
+	case "google.protobuf.BoolValue":
+		return &Schema{Type: "boolean"}
+	case "google.protobuf.StringValue":
+		return &Schema{Type: "string"}
+	case "google.protobuf.BytesValue":
+		return &Schema{Type: "string", Format: "byte"}
+	case "google.protobuf.DoubleValue":
+		return &Schema{Type: "number", Format: "double"}
+	case "google.protobuf.FloatValue":
+		return &Schema{Type: "number", Format: "float"}
+	case "google.protobuf.Timestamp":
+		return &Schema{Type: "string", Format: "date-time"}
+	case "google.protobuf.Duration":
+		return &Schema{Type: "string"}
+	case "google.protobuf.Struct":
+		return &Schema{Type: "object"}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// Package auth guards the status server's HTTP endpoints with bearer tokens
+// in two scopes: read-only tokens see status, metrics and the dashboard, admin
+// tokens can also use controls such as pausing or depositing. A status page
+// can then be shared with a team without handing out the controls.
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MinTokenLength is the minimum length of a token.
+const MinTokenLength = 16
+
+// Scope is what a token may do.
+type Scope int
+
+const (
+	None     Scope = iota
+	ReadOnly       // View status, metrics and the dashboard.
+	Admin          // Everything ReadOnly can, plus controls.
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ReadOnly:
+		return "read-only"
+	case Admin:
+		return "admin"
+	}
+	return "none"
+}
+
+// ParseTokens splits a comma-separated list of tokens, dropping surrounding
+// whitespace and empty entries.
+func ParseTokens(list string) []string {
+	var tokens []string
+	for _, token := range strings.Split(list, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// ValidateTokens checks that read-only and admin tokens are long enough and
+// that no token has both scopes.
+func ValidateTokens(readOnly, admin []string) error {
+	seen := make(map[string]bool)
+	for _, token := range readOnly {
+		if len(token) < MinTokenLength {
+			return fmt.Errorf("read-only tokens must be at least %d characters", MinTokenLength)
+		}
+		seen[token] = true
+	}
+	for _, token := range admin {
+		if len(token) < MinTokenLength {
+			return fmt.Errorf("admin tokens must be at least %d characters", MinTokenLength)
+		}
+		if seen[token] {
+			return fmt.Errorf("a token cannot be both read-only and admin")
+		}
+	}
+	return nil
+}
+
+// Guard checks requests against the configured tokens. A guard without any
+// tokens lets every request through, as before tokens were supported.
+type Guard struct {
+	tokens map[[sha256.Size]byte]Scope
+}
+
+// NewGuard returns a guard accepting the given tokens.
+func NewGuard(readOnly, admin []string) *Guard {
+	g := &Guard{tokens: make(map[[sha256.Size]byte]Scope)}
+	for _, token := range readOnly {
+		g.tokens[sha256.Sum256([]byte(token))] = ReadOnly
+	}
+	for _, token := range admin {
+		g.tokens[sha256.Sum256([]byte(token))] = Admin
+	}
+	return g
+}
+
+// Enabled reports whether the guard requires tokens.
+func (g *Guard) Enabled() bool {
+	return len(g.tokens) > 0
+}
+
+// Scope returns the scope of the token presented with r, in an
+// "Authorization: Bearer" header or a token query parameter for browsers.
+func (g *Guard) Scope(r *http.Request) Scope {
+	if !g.Enabled() {
+		return Admin
+	}
+	token := presented(r)
+	if token == "" {
+		return None
+	}
+	// Tokens are compared by hash in constant time, so timing reveals nothing
+	// about how much of a token matched
+	sum := sha256.Sum256([]byte(token))
+	scope := None
+	for known, s := range g.tokens {
+		if subtle.ConstantTimeCompare(sum[:], known[:]) == 1 {
+			scope = s
+		}
+	}
+	return scope
+}
+
+// presented returns the token presented with r, in an "Authorization:
+// Bearer" header or a token query parameter.
+func presented(r *http.Request) string {
+	token := r.URL.Query().Get("token")
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		token = strings.TrimPrefix(header, "Bearer ")
+	}
+	return token
+}
+
+// Identity names the token presented with r for audit logs without revealing
+// it: "token:" and the first 8 hex digits of its SHA-256, or "anonymous"
+// without a token.
+func Identity(r *http.Request) string {
+	token := presented(r)
+	if token == "" {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:4])
+}
+
+// Require wraps next so it is only served to requests with a token of at
+// least scope.
+func (g *Guard) Require(scope Scope, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch got := g.Scope(r); {
+		case got == None:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="preconf-bidder"`)
+			http.Error(w, "missing or unknown token", http.StatusUnauthorized)
+		case got < scope:
+			http.Error(w, scope.String()+" token required", http.StatusForbidden)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
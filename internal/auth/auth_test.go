@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	readToken  = "read-0123456789abcdef"
+	adminToken = "admin-0123456789abcdef"
+)
+
+func TestGuardScopes(t *testing.T) {
+	g := NewGuard(ParseTokens(" "+readToken+", "), []string{adminToken})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	status := g.Require(ReadOnly, ok)
+	pause := g.Require(Admin, ok)
+
+	for _, tc := range []struct {
+		handler http.Handler
+		token   string
+		query   bool
+		want    int
+	}{
+		{status, "", false, http.StatusUnauthorized},
+		{status, "wrong-0123456789abcdef", false, http.StatusUnauthorized},
+		{status, readToken, false, http.StatusOK},
+		{status, readToken, true, http.StatusOK},
+		{status, adminToken, false, http.StatusOK},
+		{pause, readToken, false, http.StatusForbidden},
+		{pause, adminToken, true, http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tc.query {
+			req = httptest.NewRequest(http.MethodGet, "/?token="+tc.token, nil)
+		} else if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		rec := httptest.NewRecorder()
+		tc.handler.ServeHTTP(rec, req)
+		require.Equal(t, tc.want, rec.Code, "token %q", tc.token)
+	}
+}
+
+func TestGuardWithoutTokensIsOpen(t *testing.T) {
+	g := NewGuard(nil, nil)
+	require.False(t, g.Enabled())
+	rec := httptest.NewRecorder()
+	g.Require(Admin, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).
+		ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestValidateTokens(t *testing.T) {
+	require.NoError(t, ValidateTokens([]string{readToken}, []string{adminToken}))
+	require.Error(t, ValidateTokens([]string{"short"}, nil))
+	require.Error(t, ValidateTokens(nil, []string{"short"}))
+	require.Error(t, ValidateTokens([]string{adminToken}, []string{adminToken}))
+}
+
+func TestIdentityHidesToken(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	require.Equal(t, "anonymous", Identity(req))
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	id := Identity(req)
+	require.Regexp(t, `^token:[0-9a-f]{8}$`, id)
+	require.NotContains(t, id, adminToken[:8])
+	require.Equal(t, id, Identity(httptest.NewRequest(http.MethodPost, "/?token="+adminToken, nil)))
+}
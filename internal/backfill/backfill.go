@@ -0,0 +1,64 @@
+// Package backfill recovers the blocks missed while the block subscription
+// was down. A reconnected subscription only delivers headers from then on, so
+// those of the blocks in between are read from the node: they are accounted
+// for like any other block, and the newest can be bid on right away instead
+// of waiting for the next header.
+package backfill
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/primev/preconf_blob_bidder/internal/latency"
+)
+
+// DefaultMaxBlocks is how many of the newest missed blocks are read.
+const DefaultMaxBlocks = 32
+
+// HeaderReader reads headers, as implemented by *ethclient.Client.
+type HeaderReader interface {
+	// HeaderByNumber returns the header of a block, the latest with nil.
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Missed returns the headers of the blocks after last up to the node's head,
+// oldest first. Only the newest max are read; dropped counts those left out.
+// Nothing was missed while the head is not past last.
+func Missed(ctx context.Context, client HeaderReader, last, max uint64) (headers []*types.Header, dropped uint64, err error) {
+	if max == 0 {
+		return nil, 0, nil
+	}
+	head, err := headerByNumber(ctx, client, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	newest := head.Number.Uint64()
+	if newest <= last {
+		return nil, 0, nil
+	}
+	from := last + 1
+	if newest-from+1 > max {
+		dropped = newest - from + 1 - max
+		from = newest - max + 1
+	}
+	for number := from; number < newest; number++ {
+		header, err := headerByNumber(ctx, client, new(big.Int).SetUint64(number))
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read header of block %d: %w", number, err)
+		}
+		headers = append(headers, header)
+	}
+	return append(headers, head), dropped, nil
+}
+
+func headerByNumber(ctx context.Context, client HeaderReader, number *big.Int) (*types.Header, error) {
+	ctx, cancel := latency.Context(ctx, latency.HeaderFetch)
+	defer cancel()
+	header, err := client.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, latency.Wrap(latency.HeaderFetch, err)
+	}
+	return header, nil
+}
@@ -0,0 +1,65 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// chain is a node whose head is block head.
+type chain struct {
+	head  uint64
+	reads []uint64
+	fail  uint64
+}
+
+func (c *chain) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	n := c.head
+	if number != nil {
+		n = number.Uint64()
+	}
+	c.reads = append(c.reads, n)
+	if n == c.fail {
+		return nil, errors.New("not found")
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(n)}, nil
+}
+
+func numbers(headers []*types.Header) []uint64 {
+	var out []uint64
+	for _, h := range headers {
+		out = append(out, h.Number.Uint64())
+	}
+	return out
+}
+
+func TestMissed(t *testing.T) {
+	ctx := context.Background()
+
+	headers, dropped, err := Missed(ctx, &chain{head: 104}, 100, DefaultMaxBlocks)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{101, 102, 103, 104}, numbers(headers))
+	require.Zero(t, dropped)
+
+	headers, dropped, err = Missed(ctx, &chain{head: 110}, 100, 3)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{108, 109, 110}, numbers(headers))
+	require.Equal(t, uint64(7), dropped)
+
+	headers, _, err = Missed(ctx, &chain{head: 100}, 100, DefaultMaxBlocks)
+	require.NoError(t, err)
+	require.Empty(t, headers, "nothing was missed")
+
+	c := &chain{head: 104}
+	headers, _, err = Missed(ctx, c, 100, 0)
+	require.NoError(t, err)
+	require.Empty(t, headers)
+	require.Empty(t, c.reads, "disabled backfill reads nothing")
+
+	_, _, err = Missed(ctx, &chain{head: 104, fail: 102}, 100, DefaultMaxBlocks)
+	require.ErrorContains(t, err, "failed to read header of block 102")
+}
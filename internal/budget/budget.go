@@ -0,0 +1,208 @@
+// Package budget caps what the bidder spends: the ETH committed in bids within
+// the last hour and the last 24 hours, and the number of bids per target
+// block. Every bid reserves its amount before it is sent, so bids in flight
+// cannot overrun a cap together; the reservation turns into spend when the
+// bid is committed and is released otherwise.
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+var (
+	// ErrExhausted is returned when a bid does not fit in a spend cap.
+	ErrExhausted = errors.New("spend budget exhausted")
+	// ErrBlockLimit is returned when the target block has all the bids it may
+	// get.
+	ErrBlockLimit = errors.New("bid limit per block reached")
+)
+
+// Mode is what happens to a bid larger than the remaining budget.
+type Mode string
+
+const (
+	// Stop skips bids that do not fit in the remaining budget.
+	Stop Mode = "stop"
+	// Reduce lowers bids to the remaining budget, skipping only once it is
+	// used up.
+	Reduce Mode = "reduce"
+)
+
+// ParseMode validates a budget mode.
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(s); mode {
+	case Stop, Reduce:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown budget mode %q, must be stop or reduce", s)
+}
+
+// Config holds the caps of a budget. A zero cap is no cap.
+type Config struct {
+	Hourly   float64 // Most ETH committed within any hour.
+	Daily    float64 // Most ETH committed within any 24 hours.
+	PerBlock int     // Most bids per target block.
+	Mode     Mode
+}
+
+// window is a rolling period capped by a budget.
+type window struct {
+	name   string
+	length time.Duration
+	cap    float64
+}
+
+// spend is the amount of a committed bid.
+type spend struct {
+	at     time.Time
+	amount float64
+}
+
+// Budget enforces the caps of a Config.
+type Budget struct {
+	cfg     Config
+	windows []window
+	now     func() time.Time
+
+	mu        sync.Mutex
+	spends    []spend // Committed bids of the last day, oldest first.
+	reserved  float64
+	blocks    map[uint64]int
+	exhausted bool
+}
+
+// New returns a budget enforcing cfg.
+func New(cfg Config) *Budget {
+	b := &Budget{cfg: cfg, now: time.Now, blocks: make(map[uint64]int)}
+	if cfg.Hourly > 0 {
+		b.windows = append(b.windows, window{name: "hour", length: time.Hour, cap: cfg.Hourly})
+	}
+	if cfg.Daily > 0 {
+		b.windows = append(b.windows, window{name: "day", length: 24 * time.Hour, cap: cfg.Daily})
+	}
+	return b
+}
+
+// Reserve reserves count bids of amount ETH for the target block and returns
+// the amount to bid each. In Reduce mode the amount is lowered to what the
+// caps leave. The error is ErrExhausted or ErrBlockLimit when nothing can be
+// bid.
+func (b *Budget) Reserve(block uint64, amount float64, count int) (float64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cfg.PerBlock > 0 && b.blocks[block]+count > b.cfg.PerBlock {
+		return 0, fmt.Errorf("%w: block %d has %d of %d bids", ErrBlockLimit, block, b.blocks[block], b.cfg.PerBlock)
+	}
+	remaining, name := b.remaining()
+	perBid := amount
+	if amount*float64(count) > remaining {
+		perBid = remaining / float64(count)
+		if b.cfg.Mode != Reduce || perBid <= 0 {
+			b.setExhausted(true, name, remaining)
+			return 0, fmt.Errorf("%w: %g ETH left in the %s, bid of %g ETH", ErrExhausted, math.Max(remaining, 0), name, amount)
+		}
+	}
+	b.setExhausted(false, name, remaining)
+	b.reserved += perBid * float64(count)
+	b.blocks[block] += count
+	b.updateMetrics()
+	return perBid, nil
+}
+
+// Settle releases the reservation of a bid of amount, counting it as spent
+// when the bid was committed.
+func (b *Budget) Settle(amount float64, committed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reserved = math.Max(b.reserved-amount, 0)
+	if committed {
+		b.spends = append(b.spends, spend{at: b.now(), amount: amount})
+	}
+	b.updateMetrics()
+}
+
+// Prune forgets the bid counts of blocks before head.
+func (b *Budget) Prune(head uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for block := range b.blocks {
+		if block < head {
+			delete(b.blocks, block)
+		}
+	}
+}
+
+// Spent returns the ETH committed within the last d.
+func (b *Budget) Spent(d time.Duration) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.spentWithin(d)
+}
+
+// spentWithin sums the spends within the last d. b.mu must be held.
+func (b *Budget) spentWithin(d time.Duration) float64 {
+	since := b.now().Add(-d)
+	var total float64
+	for _, s := range b.spends {
+		if s.at.After(since) {
+			total += s.amount
+		}
+	}
+	return total
+}
+
+// remaining returns the ETH left in the tightest window, and its name.
+// Spends older than a day are dropped. b.mu must be held.
+func (b *Budget) remaining() (float64, string) {
+	since := b.now().Add(-24 * time.Hour)
+	for len(b.spends) > 0 && !b.spends[0].at.After(since) {
+		b.spends = b.spends[1:]
+	}
+	remaining, name := math.Inf(1), ""
+	for _, w := range b.windows {
+		if left := w.cap - b.spentWithin(w.length) - b.reserved; left < remaining {
+			remaining, name = left, w.name
+		}
+	}
+	return remaining, name
+}
+
+// setExhausted logs when the budget runs out and when it is available again.
+// b.mu must be held.
+func (b *Budget) setExhausted(exhausted bool, window string, remaining float64) {
+	if exhausted == b.exhausted {
+		return
+	}
+	b.exhausted = exhausted
+	if exhausted {
+		slog.Warn("Spend budget exhausted, skipping bids",
+			"window", window,
+			"remainingETH", math.Max(remaining, 0),
+			"hourlySpentETH", b.spentWithin(time.Hour),
+			"dailySpentETH", b.spentWithin(24*time.Hour),
+			"reservedETH", b.reserved,
+		)
+	} else {
+		slog.Info("Spend budget available again",
+			"hourlySpentETH", b.spentWithin(time.Hour),
+			"dailySpentETH", b.spentWithin(24*time.Hour),
+		)
+	}
+}
+
+// updateMetrics exports the spend and remaining budget of every window.
+// b.mu must be held.
+func (b *Budget) updateMetrics() {
+	for _, w := range b.windows {
+		spent := b.spentWithin(w.length)
+		metrics.BudgetSpent.WithLabelValues(w.name).Set(spent)
+		metrics.BudgetRemaining.WithLabelValues(w.name).Set(math.Max(w.cap-spent-b.reserved, 0))
+	}
+}
@@ -0,0 +1,77 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetStop(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	b := New(Config{Hourly: 1, Daily: 1.5, Mode: Stop})
+	b.now = func() time.Time { return now }
+
+	amount, err := b.Reserve(100, 0.6, 1)
+	require.NoError(t, err)
+	require.Equal(t, 0.6, amount)
+	_, err = b.Reserve(101, 0.6, 1)
+	require.ErrorIs(t, err, ErrExhausted, "bids in flight count against the budget")
+
+	b.Settle(0.6, true)
+	_, err = b.Reserve(101, 0.5, 1)
+	require.ErrorIs(t, err, ErrExhausted)
+	require.ErrorContains(t, err, "in the hour")
+	_, err = b.Reserve(101, 0.4, 1)
+	require.NoError(t, err)
+	b.Settle(0.4, false)
+	require.Equal(t, 0.6, b.Spent(time.Hour), "uncommitted bids release their reservation")
+
+	now = now.Add(61 * time.Minute)
+	_, err = b.Reserve(400, 0.8, 1)
+	require.NoError(t, err)
+	b.Settle(0.8, true)
+	now = now.Add(61 * time.Minute)
+	_, err = b.Reserve(800, 0.2, 1)
+	require.ErrorIs(t, err, ErrExhausted)
+	require.ErrorContains(t, err, "in the day")
+
+	now = now.Add(23 * time.Hour)
+	_, err = b.Reserve(8000, 1, 1)
+	require.NoError(t, err)
+}
+
+func TestBudgetReduce(t *testing.T) {
+	b := New(Config{Hourly: 1, Mode: Reduce})
+
+	amount, err := b.Reserve(100, 0.4, 2)
+	require.NoError(t, err)
+	require.Equal(t, 0.4, amount)
+	amount, err = b.Reserve(101, 0.4, 2)
+	require.NoError(t, err)
+	require.InDelta(t, 0.1, amount, 1e-9, "the span shares what is left")
+	_, err = b.Reserve(102, 0.4, 1)
+	require.ErrorIs(t, err, ErrExhausted)
+}
+
+func TestBudgetPerBlock(t *testing.T) {
+	b := New(Config{PerBlock: 2})
+
+	_, err := b.Reserve(100, 5, 2)
+	require.NoError(t, err, "without spend caps any amount fits")
+	_, err = b.Reserve(100, 5, 1)
+	require.ErrorIs(t, err, ErrBlockLimit)
+	_, err = b.Reserve(101, 5, 1)
+	require.NoError(t, err)
+
+	b.Prune(101)
+	require.NotContains(t, b.blocks, uint64(100))
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode("reduce")
+	require.NoError(t, err)
+	require.Equal(t, Reduce, mode)
+	_, err = ParseMode("halt")
+	require.ErrorContains(t, err, `unknown budget mode "halt"`)
+}
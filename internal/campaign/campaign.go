@@ -0,0 +1,157 @@
+// Package campaign records the inputs and outputs of every bid decision made
+// during a run and replays them against the current strategy code, so
+// unintended behavior changes between releases show up as decision diffs.
+package campaign
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/outcome"
+	"github.com/primev/preconf_blob_bidder/internal/strategy"
+)
+
+// RecordVersion is the current version of the record format.
+const RecordVersion = 1
+
+// Record captures a single bid decision and everything needed to reproduce it.
+type Record struct {
+	Version    int                   `json:"version"`
+	RecordedAt time.Time             `json:"recorded_at"`
+	Seed       int64                 `json:"seed"`
+	Strategy   string                `json:"strategy"`
+	Params     strategy.Params       `json:"params"`
+	Inputs     strategy.MarketInputs `json:"inputs"`
+	Decision   strategy.Decision     `json:"decision"`
+	Tenant     string                `json:"tenant,omitempty"`   // Customer the bidder runs for, see package labels.
+	Campaign   string                `json:"campaign,omitempty"` // Scheduled campaign the decision was made in.
+
+	// Market and Outcome are not needed for replay; they support analysis of
+	// which market conditions drive acceptance.
+	Market  *MarketSnapshot `json:"market,omitempty"`
+	Outcome *Outcome        `json:"outcome,omitempty"`
+}
+
+// MarketSnapshot holds market conditions observed around a bid in addition to
+// the strategy inputs. Fields are omitted when they could not be observed.
+type MarketSnapshot struct {
+	PendingTxCount       *uint `json:"pending_tx_count,omitempty"`      // Transactions in the node's pending block.
+	CompetingCommitments *int  `json:"competing_commitments,omitempty"` // Commitments by other bidders in the slot before the bid.
+	OwnCommitments       *int  `json:"own_commitments,omitempty"`       // Our commitments in the slot before the bid.
+}
+
+// Outcome is the result of the bid made for a decision.
+type Outcome struct {
+	TargetBlock uint64 `json:"target_block"`
+	TxHash      string `json:"tx_hash,omitempty"`
+	Submitted   bool   `json:"submitted"`   // Whether the transaction reached a provider or builder.
+	Commitments int    `json:"commitments"` // Commitments received for the bid.
+}
+
+// NewOutcome returns the recorded outcome of a bid.
+func NewOutcome(o outcome.BlockOutcome) *Outcome {
+	return &Outcome{
+		TargetBlock: o.TargetBlock,
+		TxHash:      o.Payload.TxHash,
+		Submitted:   o.Submitted,
+		Commitments: len(o.Commitments),
+	}
+}
+
+// Recorder appends decision records to a JSON lines file.
+type Recorder struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// NewRecorder opens (or creates) a campaign file for appending.
+func NewRecorder(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open campaign file: %w", err)
+	}
+	return &Recorder{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Record appends a decision record.
+func (r *Recorder) Record(rec Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec.Version = RecordVersion
+	if rec.RecordedAt.IsZero() {
+		rec.RecordedAt = time.Now().UTC()
+	}
+	return r.enc.Encode(rec)
+}
+
+// Close closes the underlying file.
+func (r *Recorder) Close() error {
+	return r.f.Close()
+}
+
+// ReadRecords reads every record from a campaign file.
+func ReadRecords(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if rec.Version > RecordVersion {
+			return nil, fmt.Errorf("line %d: unsupported record version %d", line, rec.Version)
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+// Diff describes a decision that changed on replay.
+type Diff struct {
+	BlockNumber uint64            `json:"block_number"`
+	Recorded    strategy.Decision `json:"recorded"`
+	Replayed    strategy.Decision `json:"replayed"`
+}
+
+// Report summarizes a replay.
+type Report struct {
+	Total int    `json:"total"`
+	Diffs []Diff `json:"diffs"`
+}
+
+// amountTolerance absorbs float formatting noise below one wei.
+const amountTolerance = 1e-18
+
+// Replay re-runs every recorded decision through the current strategy code
+// using the recorded seed, parameters and market inputs.
+func Replay(records []Record) (Report, error) {
+	report := Report{Total: len(records)}
+	for _, rec := range records {
+		s, err := strategy.New(rec.Strategy, rec.Params)
+		if err != nil {
+			return report, fmt.Errorf("block %d: %w", rec.Inputs.BlockNumber, err)
+		}
+		replayed := s.Decide(rec.Inputs, strategy.BlockRand(rec.Seed, rec.Inputs.BlockNumber))
+		if math.Abs(replayed.BidAmount-rec.Decision.BidAmount) > amountTolerance {
+			report.Diffs = append(report.Diffs, Diff{
+				BlockNumber: rec.Inputs.BlockNumber,
+				Recorded:    rec.Decision,
+				Replayed:    replayed,
+			})
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,112 @@
+package campaign
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/primev/preconf_blob_bidder/internal/strategy"
+	"github.com/stretchr/testify/require"
+)
+
+func recordDecisions(t *testing.T, path string, seed int64, params strategy.Params, blocks []uint64) {
+	t.Helper()
+	rec, err := NewRecorder(path)
+	require.NoError(t, err)
+	defer rec.Close()
+
+	s, err := strategy.New("gaussian", params)
+	require.NoError(t, err)
+	for _, b := range blocks {
+		inputs := strategy.MarketInputs{BlockNumber: b}
+		require.NoError(t, rec.Record(Record{
+			Seed:     seed,
+			Strategy: s.Name(),
+			Params:   params,
+			Inputs:   inputs,
+			Decision: s.Decide(inputs, strategy.BlockRand(seed, b)),
+		}))
+	}
+}
+
+func TestReplayMatchesRecordedDecisions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "campaign.jsonl")
+	params := strategy.Params{BidAmount: 0.001, StdDevPercentage: 100, Offset: 1}
+	recordDecisions(t, path, 42, params, []uint64{100, 101, 105})
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := ReadRecords(f)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	report, err := Replay(records)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Total)
+	require.Empty(t, report.Diffs)
+}
+
+func TestReplayReportsChangedDecisions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "campaign.jsonl")
+	params := strategy.Params{BidAmount: 0.001, StdDevPercentage: 100}
+	recordDecisions(t, path, 7, params, []uint64{200})
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := ReadRecords(f)
+	require.NoError(t, err)
+
+	// Simulate a behavior change by tampering with the recorded output.
+	records[0].Decision.BidAmount += 1
+
+	report, err := Replay(records)
+	require.NoError(t, err)
+	require.Len(t, report.Diffs, 1)
+	require.Equal(t, uint64(200), report.Diffs[0].BlockNumber)
+}
+
+func TestGaussianNeverBidsBelowMean(t *testing.T) {
+	s := strategy.Gaussian{Params: strategy.Params{BidAmount: 0.5, StdDevPercentage: 300}}
+	for b := uint64(0); b < 200; b++ {
+		d := s.Decide(strategy.MarketInputs{BlockNumber: b}, strategy.BlockRand(1, b))
+		require.GreaterOrEqual(t, d.BidAmount, 0.5)
+	}
+}
+
+func TestMarketSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "campaign.jsonl")
+	rec, err := NewRecorder(path)
+	require.NoError(t, err)
+
+	params := strategy.Params{BidAmount: 0.001}
+	inputs := strategy.MarketInputs{BlockNumber: 300, BaseFee: big.NewInt(7), BlobBaseFee: big.NewInt(1)}
+	pending, competing := uint(42), 3
+	require.NoError(t, rec.Record(Record{
+		Seed:     1,
+		Strategy: "gaussian",
+		Params:   params,
+		Inputs:   inputs,
+		Decision: strategy.Gaussian{Params: params}.Decide(inputs, strategy.BlockRand(1, 300)),
+		Market:   &MarketSnapshot{PendingTxCount: &pending, CompetingCommitments: &competing},
+		Outcome:  &Outcome{TargetBlock: 301, Submitted: true, Commitments: 2},
+	}))
+	require.NoError(t, rec.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := ReadRecords(f)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, uint(42), *records[0].Market.PendingTxCount)
+	require.Equal(t, 3, *records[0].Market.CompetingCommitments)
+	require.Equal(t, 0, records[0].Inputs.BlobBaseFee.Cmp(big.NewInt(1)))
+	require.Equal(t, 2, records[0].Outcome.Commitments)
+
+	report, err := Replay(records)
+	require.NoError(t, err)
+	require.Empty(t, report.Diffs)
+}
@@ -0,0 +1,225 @@
+// Package canary rolls out new bidding parameters gradually. When the
+// parameters change at runtime, the new ones are first used on a small share
+// of blocks; once enough canary bids have resolved their outcomes are compared
+// with the old parameters' over the same period, and the new parameters are
+// promoted or rolled back.
+package canary
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"hash/fnv"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/strategy"
+)
+
+// DefaultBlocks is how many canary bids are compared before deciding.
+const DefaultBlocks = 50
+
+// DefaultTolerance is how far, as a fraction, the candidate's acceptance
+// rate may fall below the baseline's and still be promoted.
+const DefaultTolerance = 0.1
+
+// Arm tells which parameters a bid used.
+type Arm string
+
+const (
+	Baseline  Arm = "baseline"  // The parameters in effect.
+	Candidate Arm = "candidate" // The new parameters under test.
+)
+
+// Setting is a strategy with the parameters it was built from.
+type Setting struct {
+	Strategy strategy.BidStrategy
+	Params   strategy.Params
+}
+
+// Result sums the outcomes of the bids of one arm.
+type Result struct {
+	Bids      int     `json:"bids"`
+	Committed int     `json:"committed"` // Bids with at least one commitment.
+	SpendETH  float64 `json:"spend_eth"` // Amount of the committed bids.
+}
+
+// AcceptanceRate returns the share of bids that were committed.
+func (r Result) AcceptanceRate() float64 {
+	if r.Bids == 0 {
+		return 0
+	}
+	return float64(r.Committed) / float64(r.Bids)
+}
+
+// Report is the state or outcome of a canary.
+type Report struct {
+	Started   time.Time       `json:"started"`
+	Finished  time.Time       `json:"finished,omitempty"`
+	Percent   float64         `json:"percent"`
+	Strategy  string          `json:"strategy"`
+	Params    strategy.Params `json:"params"`
+	Baseline  Result          `json:"baseline"`
+	Candidate Result          `json:"candidate"`
+	Promoted  bool            `json:"promoted"`
+	Reason    string          `json:"reason,omitempty"`
+}
+
+// Config configures canaries.
+type Config struct {
+	Percent   float64 // Share of blocks bid on with the candidate, 0 to switch at once.
+	Blocks    int     // Canary bids compared before deciding.
+	Tolerance float64 // See DefaultTolerance.
+}
+
+// Canary holds the parameters in effect and, during a canary, the candidate.
+type Canary struct {
+	cfg Config
+
+	mu        sync.Mutex
+	current   Setting
+	candidate *Setting
+	report    *Report // The running canary, or the last finished one.
+	now       func() time.Time
+}
+
+// New returns a canary bidding with current until new parameters are
+// proposed.
+func New(cfg Config, current Setting) *Canary {
+	if cfg.Blocks <= 0 {
+		cfg.Blocks = DefaultBlocks
+	}
+	if cfg.Tolerance <= 0 {
+		cfg.Tolerance = DefaultTolerance
+	}
+	return &Canary{cfg: cfg, current: current, now: time.Now}
+}
+
+// Propose starts a canary for candidate, replacing any running canary. With
+// a zero percentage the candidate takes effect at once.
+func (c *Canary) Propose(candidate Setting) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cfg.Percent <= 0 {
+		c.current, c.candidate = candidate, nil
+		slog.Info("New bidding parameters applied", "strategy", candidate.Strategy.Name(), "params", candidate.Params)
+		return
+	}
+	if c.report != nil && c.report.Finished.IsZero() {
+		slog.Info("Canary replaced by newer parameters", "strategy", c.report.Strategy)
+	}
+	c.candidate = &candidate
+	c.report = &Report{
+		Started:  c.now().UTC(),
+		Percent:  c.cfg.Percent,
+		Strategy: candidate.Strategy.Name(),
+		Params:   candidate.Params,
+	}
+	slog.Info("Canary started",
+		"strategy", candidate.Strategy.Name(),
+		"params", candidate.Params,
+		"percent", c.cfg.Percent,
+		"blocks", c.cfg.Blocks,
+	)
+}
+
+// Pick returns the setting to bid on block with. A block is always picked for
+// the same arm, so retries and replays use the same parameters.
+func (c *Canary) Pick(block uint64) (Setting, Arm) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.candidate != nil && bucket(block) < c.cfg.Percent {
+		return *c.candidate, Candidate
+	}
+	return c.current, Baseline
+}
+
+// bucket spreads blocks uniformly over [0, 100).
+func bucket(block uint64) float64 {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], block)
+	h := fnv.New64a()
+	h.Write(b[:])
+	return float64(h.Sum64()%10000) / 100
+}
+
+// Observe records the outcome of a bid made with arm. Once enough candidate
+// bids have resolved, the candidate is promoted or rolled back.
+func (c *Canary) Observe(arm Arm, committed bool, amount float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.candidate == nil {
+		return
+	}
+	r := &c.report.Baseline
+	if arm == Candidate {
+		r = &c.report.Candidate
+	}
+	r.Bids++
+	if committed {
+		r.Committed++
+		r.SpendETH += amount
+	}
+	if c.report.Candidate.Bids >= c.cfg.Blocks {
+		c.decide()
+	}
+}
+
+// decide ends the canary. c.mu must be held.
+func (c *Canary) decide() {
+	rep := c.report
+	base, cand := rep.Baseline.AcceptanceRate(), rep.Candidate.AcceptanceRate()
+	switch {
+	case rep.Baseline.Bids == 0:
+		rep.Promoted, rep.Reason = true, "no baseline bids to compare with"
+	case cand >= base-c.cfg.Tolerance:
+		rep.Promoted, rep.Reason = true, "acceptance rate within tolerance of the baseline"
+	default:
+		rep.Reason = "acceptance rate below the baseline"
+	}
+	rep.Finished = c.now().UTC()
+	if rep.Promoted {
+		c.current = *c.candidate
+	}
+	c.candidate = nil
+
+	attrs := []interface{}{
+		"strategy", rep.Strategy,
+		"params", rep.Params,
+		"reason", rep.Reason,
+		"baselineBids", rep.Baseline.Bids,
+		"baselineAcceptance", base,
+		"baselineSpendETH", rep.Baseline.SpendETH,
+		"candidateBids", rep.Candidate.Bids,
+		"candidateAcceptance", cand,
+		"candidateSpendETH", rep.Candidate.SpendETH,
+	}
+	if rep.Promoted {
+		slog.Info("Canary promoted", attrs...)
+	} else {
+		slog.Warn("Canary rolled back", attrs...)
+	}
+}
+
+// Report returns the running or last finished canary, or nil if there was
+// none.
+func (c *Canary) Report() *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.report == nil {
+		return nil
+	}
+	rep := *c.report
+	return &rep
+}
+
+// ServeHTTP serves the report as JSON.
+func (c *Canary) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(struct {
+		Canary *Report `json:"canary"`
+	}{c.Report()}); err != nil {
+		slog.Warn("Failed to write canary report", "error", err)
+	}
+}
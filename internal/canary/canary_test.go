@@ -0,0 +1,67 @@
+package canary
+
+import (
+	"testing"
+
+	"github.com/primev/preconf_blob_bidder/internal/strategy"
+	"github.com/stretchr/testify/require"
+)
+
+func setting(amount float64) Setting {
+	params := strategy.Params{BidAmount: amount}
+	return Setting{Strategy: strategy.Gaussian{Params: params}, Params: params}
+}
+
+func TestCanaryPromotesAndRollsBack(t *testing.T) {
+	c := New(Config{Percent: 20, Blocks: 10}, setting(0.001))
+
+	c.Propose(setting(0.002))
+	candidates := 0
+	for block := uint64(0); block < 1000; block++ {
+		s, arm := c.Pick(block)
+		again, _ := c.Pick(block)
+		require.Equal(t, s, again, "a block always gets the same arm")
+		if arm == Candidate {
+			candidates++
+			require.Equal(t, 0.002, s.Params.BidAmount)
+		}
+	}
+	require.InDelta(t, 200, candidates, 50)
+
+	// The candidate does as well as the baseline and is promoted
+	for i := 0; i < 10; i++ {
+		c.Observe(Baseline, i%2 == 0, 0.001)
+		c.Observe(Candidate, i%2 == 0, 0.002)
+	}
+	rep := c.Report()
+	require.True(t, rep.Promoted)
+	require.False(t, rep.Finished.IsZero())
+	require.InDelta(t, 0.01, rep.Candidate.SpendETH, 1e-12)
+	s, arm := c.Pick(1)
+	require.Equal(t, Baseline, arm)
+	require.Equal(t, 0.002, s.Params.BidAmount)
+
+	// A candidate that is rarely committed is rolled back
+	c.Propose(setting(0.0001))
+	for i := 0; i < 10; i++ {
+		c.Observe(Baseline, true, 0.002)
+		c.Observe(Candidate, i == 0, 0.0001)
+	}
+	rep = c.Report()
+	require.False(t, rep.Promoted)
+	require.Equal(t, 0.1, rep.Candidate.AcceptanceRate())
+	for block := uint64(0); block < 100; block++ {
+		s, arm := c.Pick(block)
+		require.Equal(t, Baseline, arm)
+		require.Equal(t, 0.002, s.Params.BidAmount)
+	}
+}
+
+func TestCanaryDisabledSwitchesAtOnce(t *testing.T) {
+	c := New(Config{}, setting(0.001))
+	c.Propose(setting(0.003))
+	s, arm := c.Pick(7)
+	require.Equal(t, Baseline, arm)
+	require.Equal(t, 0.003, s.Params.BidAmount)
+	require.Nil(t, c.Report())
+}
@@ -0,0 +1,148 @@
+// Package clock estimates the offset of the local clock from chain time and
+// corrects the timestamps the bidder derives from it. Bid decay timestamps are
+// taken from the local clock, so a skewed clock silently shrinks effective bids.
+package clock
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultThreshold is the estimated offset above which the clock is
+	// considered skewed. It leaves room for normal block propagation delay,
+	// which the block based estimate cannot tell apart from skew.
+	DefaultThreshold = 2 * time.Second
+	// DefaultWindow is the number of recent blocks the estimate is based on.
+	DefaultWindow = 32
+	// minSamples is the number of blocks needed before estimating from blocks.
+	minSamples = 3
+)
+
+var offset atomic.Int64
+
+// SetOffset sets the correction applied by Now. A positive offset means the
+// local clock is ahead.
+func SetOffset(d time.Duration) {
+	offset.Store(int64(d))
+}
+
+// Offset returns the correction applied by Now.
+func Offset() time.Duration {
+	return time.Duration(offset.Load())
+}
+
+// Now returns the local time corrected by the current offset.
+func Now() time.Time {
+	return time.Now().Add(-Offset())
+}
+
+// Estimator estimates the local clock offset from block arrival times and,
+// when available, NTP.
+type Estimator struct {
+	mu        sync.Mutex
+	samples   []time.Duration
+	next      int
+	threshold time.Duration
+	ntp       *time.Duration
+	skewed    bool
+}
+
+// NewEstimator returns an estimator over the last window blocks that reports
+// skew above threshold.
+func NewEstimator(window int, threshold time.Duration) *Estimator {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Estimator{samples: make([]time.Duration, 0, window), threshold: threshold}
+}
+
+// ObserveBlock records when a block with the given timestamp was received.
+func (e *Estimator) ObserveBlock(blockTime, received time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	sample := received.Sub(blockTime)
+	if len(e.samples) < cap(e.samples) {
+		e.samples = append(e.samples, sample)
+		return
+	}
+	e.samples[e.next] = sample
+	e.next = (e.next + 1) % len(e.samples)
+}
+
+// SetNTPOffset records an offset measured against an NTP server. It takes
+// precedence over the block based estimate.
+func (e *Estimator) SetNTPOffset(d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ntp = &d
+}
+
+// Estimate returns the estimated offset and its source ("ntp" or "blocks").
+// ok is false until enough blocks have been observed and no NTP measurement
+// is available. The block based estimate is the smallest observed arrival
+// delay, i.e. skew plus the fastest propagation seen.
+func (e *Estimator) Estimate() (d time.Duration, source string, ok bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.ntp != nil {
+		return *e.ntp, "ntp", true
+	}
+	if len(e.samples) < minSamples {
+		return 0, "", false
+	}
+	d = e.samples[0]
+	for _, s := range e.samples[1:] {
+		if s < d {
+			d = s
+		}
+	}
+	return d, "blocks", true
+}
+
+// Update re-estimates the offset. When it exceeds the threshold a warning is
+// logged and, if compensate is set and the offset was measured with NTP, Now
+// is corrected by it; otherwise the correction is cleared. The block based
+// estimate is never compensated: it includes propagation and the node's
+// header delay, and correcting by those would backdate every decay start. It
+// returns the estimate and whether it is skewed.
+func (e *Estimator) Update(compensate bool) (time.Duration, bool) {
+	d, source, ok := e.Estimate()
+	if !ok {
+		return 0, false
+	}
+	skewed := d > e.threshold || d < -e.threshold
+	compensate = compensate && source == "ntp"
+
+	e.mu.Lock()
+	changed := skewed != e.skewed
+	e.skewed = skewed
+	e.mu.Unlock()
+
+	if skewed {
+		if changed {
+			slog.Warn("Clock skew detected, decay timestamps are affected",
+				"offset", d.Round(time.Millisecond),
+				"source", source,
+				"threshold", e.threshold,
+				"compensating", compensate,
+			)
+		}
+		if compensate {
+			SetOffset(d)
+		} else {
+			SetOffset(0)
+		}
+	} else {
+		if changed {
+			slog.Info("Clock skew back within threshold",
+				"offset", d.Round(time.Millisecond),
+				"source", source,
+			)
+		}
+		SetOffset(0)
+	}
+	return d, skewed
+}
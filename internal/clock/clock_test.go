@@ -0,0 +1,104 @@
+package clock
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimatorUsesFastestBlock(t *testing.T) {
+	e := NewEstimator(3, DefaultThreshold)
+	base := time.Unix(1_700_000_000, 0)
+
+	e.ObserveBlock(base, base.Add(5*time.Second))
+	e.ObserveBlock(base, base.Add(3*time.Second))
+	_, _, ok := e.Estimate()
+	require.False(t, ok, "too few samples")
+
+	e.ObserveBlock(base, base.Add(4*time.Second))
+	d, source, ok := e.Estimate()
+	require.True(t, ok)
+	require.Equal(t, "blocks", source)
+	require.Equal(t, 3*time.Second, d)
+
+	// The window only keeps the last three blocks.
+	e.ObserveBlock(base, base.Add(6*time.Second))
+	e.ObserveBlock(base, base.Add(7*time.Second))
+	d, _, _ = e.Estimate()
+	require.Equal(t, 4*time.Second, d)
+}
+
+func TestUpdateCompensatesAboveThreshold(t *testing.T) {
+	defer SetOffset(0)
+	e := NewEstimator(DefaultWindow, time.Second)
+
+	e.SetNTPOffset(1500 * time.Millisecond)
+	d, skewed := e.Update(true)
+	require.True(t, skewed)
+	require.Equal(t, 1500*time.Millisecond, d)
+	require.Equal(t, d, Offset())
+	require.WithinDuration(t, time.Now().Add(-d), Now(), 100*time.Millisecond)
+
+	e.SetNTPOffset(200 * time.Millisecond)
+	_, skewed = e.Update(true)
+	require.False(t, skewed)
+	require.Zero(t, Offset())
+
+	e.SetNTPOffset(-3 * time.Second)
+	_, skewed = e.Update(false)
+	require.True(t, skewed)
+	require.Zero(t, Offset(), "no correction without compensation")
+}
+
+func TestUpdateIgnoresHeaderDelay(t *testing.T) {
+	defer SetOffset(0)
+	e := NewEstimator(DefaultWindow, DefaultThreshold)
+
+	// A clock without skew on a node delivering headers 2-3s into the slot
+	base := time.Unix(1_700_000_000, 0)
+	for i, delay := range []time.Duration{2500 * time.Millisecond, 2200 * time.Millisecond, 3 * time.Second} {
+		slot := base.Add(time.Duration(i) * 12 * time.Second)
+		e.ObserveBlock(slot, slot.Add(delay))
+	}
+	d, skewed := e.Update(true)
+	require.True(t, skewed, "the delay is reported")
+	require.Equal(t, 2200*time.Millisecond, d)
+	require.Zero(t, Offset(), "but decay timestamps are not backdated by it")
+	require.WithinDuration(t, time.Now(), Now(), 100*time.Millisecond)
+
+	// An NTP measurement is compensated, and replaces the block based estimate
+	e.SetNTPOffset(3 * time.Second)
+	e.Update(true)
+	require.Equal(t, 3*time.Second, Offset())
+}
+
+func TestQueryNTP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// A server whose clock is 10s behind ours: our clock is 10s ahead.
+	go func() {
+		req := make([]byte, 48)
+		_, addr, err := conn.ReadFrom(req)
+		if err != nil {
+			return
+		}
+		resp := make([]byte, 48)
+		resp[0] = 0x1c // version 3, mode 4 (server)
+		now := time.Now().Add(-10 * time.Second)
+		copy(resp[24:32], req[40:48])
+		putNTPTime(resp[32:], now)
+		putNTPTime(resp[40:], now)
+		_, _ = conn.WriteTo(resp, addr)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	d, err := QueryNTP(ctx, conn.LocalAddr().String())
+	require.NoError(t, err)
+	require.InDelta(t, float64(10*time.Second), float64(d), float64(100*time.Millisecond))
+}
@@ -0,0 +1,70 @@
+package clock
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ntpEpochOffset is the number of seconds between 1900-01-01 and 1970-01-01.
+const ntpEpochOffset = 2208988800
+
+// QueryNTP measures the local clock offset against an NTP server using a
+// single SNTP request. A positive offset means the local clock is ahead.
+// server may omit the port, which defaults to 123.
+func QueryNTP(ctx context.Context, server string) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", server)
+	if err != nil {
+		return 0, fmt.Errorf("ntp dial %s: %w", server, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	req := make([]byte, 48)
+	req[0] = 0x1b // LI 0, version 3, mode 3 (client)
+	t1 := time.Now()
+	putNTPTime(req[40:], t1) // Transmit timestamp, echoed back as originate timestamp.
+	if _, err := conn.Write(req); err != nil {
+		return 0, fmt.Errorf("ntp request: %w", err)
+	}
+
+	resp := make([]byte, 48)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return 0, fmt.Errorf("ntp response: %w", err)
+	}
+	t4 := time.Now()
+	if n < 48 {
+		return 0, fmt.Errorf("ntp response too short: %d bytes", n)
+	}
+	if mode := resp[0] & 0x7; mode != 4 {
+		return 0, fmt.Errorf("unexpected ntp mode %d", mode)
+	}
+
+	t2 := ntpTime(resp[32:]) // Receive timestamp.
+	t3 := ntpTime(resp[40:]) // Transmit timestamp.
+	// Server offset relative to local, negated so ahead clocks are positive.
+	return -(t2.Sub(t1) + t3.Sub(t4)) / 2, nil
+}
+
+func ntpTime(b []byte) time.Time {
+	sec := binary.BigEndian.Uint32(b[0:4])
+	frac := binary.BigEndian.Uint32(b[4:8])
+	nsec := (int64(frac) * 1e9) >> 32
+	return time.Unix(int64(sec)-ntpEpochOffset, nsec)
+}
+
+func putNTPTime(b []byte, t time.Time) {
+	sec := uint32(t.Unix() + ntpEpochOffset)
+	frac := uint32((int64(t.Nanosecond()) << 32) / 1e9)
+	binary.BigEndian.PutUint32(b[0:4], sec)
+	binary.BigEndian.PutUint32(b[4:8], frac)
+}
@@ -0,0 +1,269 @@
+// Package competition estimates how many other bidders are competing for
+// preconfirmations by observing commitments stored on the mev-commit chain.
+//
+// Commitments are stored unopened while the L1 block is being built, which
+// only reveals the committing provider, the commitment digest and the dispatch
+// time. Commitments whose digest matches one of our own bids, or that are
+// later opened for one of our transactions, are counted apart from the rest,
+// so what remains is the commitment flow of competing bidders. Blob
+// transactions included in L1 blocks are separated the same way, by the
+// addresses of our keys.
+package competition
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+const (
+	// SlotDuration is the L1 slot time commitments are bucketed by.
+	SlotDuration = 12 * time.Second
+	// DefaultWindowSlots is the number of recent slots intensity is averaged over.
+	DefaultWindowSlots = 8
+)
+
+// Commitment is an observed commitment.
+type Commitment struct {
+	Index      string    // Commitment index, hex encoded.
+	Digest     string    // Commitment digest, hex encoded.
+	Committer  string    // Provider address.
+	Dispatched time.Time // When the provider dispatched the commitment.
+}
+
+// Origins of observed commitments and inclusions.
+const (
+	OriginOwn    = "own"
+	OriginMarket = "market"
+)
+
+// Inclusion is a blob transaction observed in an L1 block.
+type Inclusion struct {
+	TxHash string
+	From   common.Address
+}
+
+// Share is the average number of commitments and blob transaction
+// inclusions per slot, ours and the rest of the market's.
+type Share struct {
+	OwnCommitments    float64 `json:"own_commitments"`
+	MarketCommitments float64 `json:"market_commitments"`
+	OwnInclusions     float64 `json:"own_inclusions"`
+	MarketInclusions  float64 `json:"market_inclusions"`
+}
+
+// counted is where an observed commitment was counted.
+type counted struct {
+	slot int64
+	own  bool
+}
+
+// tally holds the counts of a slot.
+type tally struct {
+	ownCommitments, marketCommitments int
+	ownInclusions, marketInclusions   int
+}
+
+// Observer counts commitments and inclusions per slot, separating ours from
+// those of competing bidders.
+type Observer struct {
+	// Ownership, if set, recognizes our commitments when they are opened and
+	// our transactions when they are included. Set it before the observer is
+	// used.
+	Ownership *Ownership
+
+	mu          sync.Mutex
+	windowSlots int64
+	own         map[string]time.Time // Our commitment digests and when they were added.
+	slots       map[int64]*tally
+	counted     map[string]counted // Counted digests, so late own marks can move them.
+}
+
+// NewObserver returns an observer averaging over windowSlots slots.
+func NewObserver(windowSlots int) *Observer {
+	if windowSlots <= 0 {
+		windowSlots = DefaultWindowSlots
+	}
+	return &Observer{
+		windowSlots: int64(windowSlots),
+		own:         make(map[string]time.Time),
+		slots:       make(map[int64]*tally),
+		counted:     make(map[string]counted),
+	}
+}
+
+func normalizeDigest(d string) string {
+	return strings.ToLower(strings.TrimPrefix(d, "0x"))
+}
+
+func slotOf(t time.Time) int64 {
+	return t.UnixMilli() / SlotDuration.Milliseconds()
+}
+
+// tally returns the counts of slot. o.mu must be held.
+func (o *Observer) tally(slot int64) *tally {
+	t, ok := o.slots[slot]
+	if !ok {
+		t = &tally{}
+		o.slots[slot] = t
+	}
+	return t
+}
+
+// MarkOwn registers the digest of a commitment received for one of our bids.
+// It may be called before or after the commitment is observed on chain.
+func (o *Observer) MarkOwn(digest string) {
+	digest = normalizeDigest(digest)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.own[digest] = time.Now()
+	if c, ok := o.counted[digest]; ok && !c.own {
+		t := o.tally(c.slot)
+		t.marketCommitments--
+		t.ownCommitments++
+		o.counted[digest] = counted{slot: c.slot, own: true}
+		o.publish()
+	}
+}
+
+// Observe records a commitment seen on chain.
+func (o *Observer) Observe(c Commitment) {
+	digest := normalizeDigest(c.Digest)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.counted[digest]; ok {
+		return
+	}
+	_, own := o.own[digest]
+	slot := slotOf(c.Dispatched)
+	if own {
+		o.tally(slot).ownCommitments++
+	} else {
+		o.tally(slot).marketCommitments++
+	}
+	o.counted[digest] = counted{slot: slot, own: own}
+	o.prune(slot)
+	o.publish()
+}
+
+// ObserveOpened records that the commitment with digest was opened for the
+// transactions with the comma separated hashes. A commitment to one of our
+// transactions is ours, even if the bid that received it was sent before a
+// restart.
+func (o *Observer) ObserveOpened(digest, txHashes string) {
+	for _, hash := range strings.Split(txHashes, ",") {
+		if o.Ownership.IsTx(strings.TrimSpace(hash)) {
+			o.MarkOwn(digest)
+			return
+		}
+	}
+}
+
+// ObserveInclusions records the blob transactions of a block produced at.
+func (o *Observer) ObserveInclusions(at time.Time, included []Inclusion) {
+	own := 0
+	for _, in := range included {
+		if o.Ownership.IsAddress(in.From) || o.Ownership.IsTx(in.TxHash) {
+			own++
+		}
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	slot := slotOf(at)
+	t := o.tally(slot)
+	t.ownInclusions += own
+	t.marketInclusions += len(included) - own
+	o.prune(slot)
+	o.publish()
+}
+
+// prune drops state older than twice the window.
+func (o *Observer) prune(current int64) {
+	oldest := current - 2*o.windowSlots
+	for slot := range o.slots {
+		if slot < oldest {
+			delete(o.slots, slot)
+		}
+	}
+	for digest, c := range o.counted {
+		if c.slot < oldest {
+			delete(o.counted, digest)
+		}
+	}
+	cutoff := time.Now().Add(-time.Duration(2*o.windowSlots) * SlotDuration)
+	for digest, added := range o.own {
+		if added.Before(cutoff) {
+			delete(o.own, digest)
+		}
+	}
+}
+
+// publish exports the share over the window before now. o.mu must be held.
+func (o *Observer) publish() {
+	s := o.share(time.Now())
+	metrics.CommitmentsPerSlot.WithLabelValues(OriginOwn).Set(s.OwnCommitments)
+	metrics.CommitmentsPerSlot.WithLabelValues(OriginMarket).Set(s.MarketCommitments)
+	metrics.InclusionsPerSlot.WithLabelValues(OriginOwn).Set(s.OwnInclusions)
+	metrics.InclusionsPerSlot.WithLabelValues(OriginMarket).Set(s.MarketInclusions)
+}
+
+// LastSlot returns the number of competing commitments dispatched during the
+// slot before the one containing now.
+func (o *Observer) LastSlot(now time.Time) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if t, ok := o.slots[slotOf(now)-1]; ok {
+		return t.marketCommitments
+	}
+	return 0
+}
+
+// LastSlotOwn returns the number of our commitments dispatched during the
+// slot before the one containing now.
+func (o *Observer) LastSlotOwn(now time.Time) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if t, ok := o.slots[slotOf(now)-1]; ok {
+		return t.ownCommitments
+	}
+	return 0
+}
+
+// Intensity returns the average number of competing commitments per slot over
+// the window of complete slots before now.
+func (o *Observer) Intensity(now time.Time) float64 {
+	return o.Share(now).MarketCommitments
+}
+
+// Share returns the average commitments and inclusions per slot, ours and the
+// market's, over the window of complete slots before now.
+func (o *Observer) Share(now time.Time) Share {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.share(now)
+}
+
+func (o *Observer) share(now time.Time) Share {
+	var total tally
+	current := slotOf(now)
+	for slot := current - o.windowSlots; slot < current; slot++ {
+		t, ok := o.slots[slot]
+		if !ok {
+			continue
+		}
+		total.ownCommitments += t.ownCommitments
+		total.marketCommitments += t.marketCommitments
+		total.ownInclusions += t.ownInclusions
+		total.marketInclusions += t.marketInclusions
+	}
+	n := float64(o.windowSlots)
+	return Share{
+		OwnCommitments:    float64(total.ownCommitments) / n,
+		MarketCommitments: float64(total.marketCommitments) / n,
+		OwnInclusions:     float64(total.ownInclusions) / n,
+		MarketInclusions:  float64(total.marketInclusions) / n,
+	}
+}
@@ -0,0 +1,98 @@
+package competition
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/primev/preconf_blob_bidder/internal/contracts/preconfmanager"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserverExcludesOwnCommitments(t *testing.T) {
+	o := NewObserver(2)
+	now := time.Now()
+	prev := now.Add(-SlotDuration)
+
+	o.MarkOwn("0xAA")
+	o.Observe(Commitment{Digest: "aa", Dispatched: prev})
+	o.Observe(Commitment{Digest: "bb", Dispatched: prev})
+	o.Observe(Commitment{Digest: "bb", Dispatched: prev}) // duplicate log
+	o.Observe(Commitment{Digest: "cc", Dispatched: prev})
+	require.Equal(t, 2, o.LastSlot(now))
+
+	// Our commitment may be observed on chain before the bid stream returns it.
+	o.MarkOwn("cc")
+	require.Equal(t, 1, o.LastSlot(now))
+
+	o.Observe(Commitment{Digest: "dd", Dispatched: now.Add(-2 * SlotDuration)})
+	require.InDelta(t, 1.0, o.Intensity(now), 1e-9, "two competing commitments over a two slot window")
+}
+
+func TestDecodeCommitment(t *testing.T) {
+	contractAbi, err := preconfmanager.PreconfManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	event := contractAbi.Events["UnopenedCommitmentStored"]
+	digest := [32]byte{0x01, 0x02}
+	committer := common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	data, err := event.Inputs.NonIndexed().Pack(
+		committer, digest, []byte{0x05}, uint64(1_700_000_000_000),
+	)
+	require.NoError(t, err)
+
+	index := common.Hash{0x09}
+	c, err := DecodeCommitment(types.Log{Topics: []common.Hash{event.ID, index}, Data: data})
+	require.NoError(t, err)
+	require.Equal(t, common.Bytes2Hex(index[:]), c.Index)
+	require.Equal(t, common.Bytes2Hex(digest[:]), c.Digest)
+	require.Equal(t, committer.Hex(), c.Committer)
+	require.Equal(t, int64(1_700_000_000_000), c.Dispatched.UnixMilli())
+}
+
+func TestOwnershipSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "own.jsonl")
+	key := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	own, err := OpenOwnership(path, []common.Address{key})
+	require.NoError(t, err)
+	own.AddTx("0xAB01")
+	own.AddTx("")
+	require.NoError(t, own.Close())
+
+	own, err = OpenOwnership(path, []common.Address{key})
+	require.NoError(t, err)
+	defer own.Close()
+	require.True(t, own.IsTx("ab01"))
+	require.False(t, own.IsTx("ab02"))
+	require.True(t, own.IsAddress(key))
+
+	// A commitment counted as the market's is ours once opened for our transaction
+	o := NewObserver(2)
+	o.Ownership = own
+	now := time.Now()
+	prev := now.Add(-SlotDuration)
+	o.Observe(Commitment{Digest: "aa", Dispatched: prev})
+	o.Observe(Commitment{Digest: "bb", Dispatched: prev})
+	o.ObserveOpened("0xaa", "cd02,0xab01")
+	o.ObserveOpened("bb", "cd03")
+	require.Equal(t, 1, o.LastSlot(now))
+	require.Equal(t, 1, o.LastSlotOwn(now))
+
+	o.ObserveInclusions(prev, []Inclusion{
+		{TxHash: "0x01", From: key},
+		{TxHash: "0xab01", From: common.HexToAddress("0x00000000000000000000000000000000000000b2")},
+		{TxHash: "0x03", From: common.HexToAddress("0x00000000000000000000000000000000000000b3")},
+	})
+	require.Equal(t, Share{OwnCommitments: 0.5, MarketCommitments: 0.5, OwnInclusions: 1, MarketInclusions: 0.5}, o.Share(now))
+}
+
+func TestDecodeCommitmentRejectsOtherEvents(t *testing.T) {
+	contractAbi, err := preconfmanager.PreconfManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	data, err := contractAbi.Events["CommitmentProcessed"].Inputs.NonIndexed().Pack(true)
+	require.NoError(t, err)
+
+	_, err = DecodeCommitment(types.Log{Topics: []common.Hash{contractAbi.Events["CommitmentProcessed"].ID, {0x09}}, Data: data})
+	require.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package competition
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/primev/preconf_blob_bidder/internal/contracts/preconfmanager"
+	"github.com/primev/preconf_blob_bidder/internal/ident"
+)
+
+// decoder decodes PreconfManager logs; it is never used to subscribe.
+var decoder = func() *preconfmanager.PreconfManagerFilterer {
+	filterer, err := preconfmanager.NewPreconfManagerFilterer(common.Address{}, nil)
+	if err != nil {
+		panic(err)
+	}
+	return filterer
+}()
+
+// DecodeCommitment decodes an UnopenedCommitmentStored log, emitted when a
+// provider stores a commitment before the L1 block is built.
+func DecodeCommitment(log types.Log) (Commitment, error) {
+	ev, err := decoder.ParseUnopenedCommitmentStored(log)
+	if err != nil {
+		return Commitment{}, fmt.Errorf("failed to unpack commitment: %w", err)
+	}
+	return fromEvent(ev), nil
+}
+
+func fromEvent(ev *preconfmanager.PreconfManagerUnopenedCommitmentStored) Commitment {
+	return Commitment{
+		Index:      common.Bytes2Hex(ev.CommitmentIndex[:]),
+		Digest:     common.Bytes2Hex(ev.CommitmentDigest[:]),
+		Committer:  ev.Committer.Hex(),
+		Dispatched: time.UnixMilli(int64(ev.DispatchTimestamp)),
+	}
+}
+
+// Listen feeds commitments stored at the PreconfManager contract into the
+// observer until ctx is canceled, resubscribing after errors.
+func Listen(ctx context.Context, endpoint string, preconfManager common.Address, o *Observer) {
+	for ctx.Err() == nil {
+		err := listenOnce(ctx, endpoint, preconfManager, o)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("Competitor commitment subscription ended, retrying in 10 seconds", "error", err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+func listenOnce(ctx context.Context, endpoint string, address common.Address, o *Observer) error {
+	client, err := ident.DialEth(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	filterer, err := preconfmanager.NewPreconfManagerFilterer(address, client)
+	if err != nil {
+		return err
+	}
+	events := make(chan *preconfmanager.PreconfManagerUnopenedCommitmentStored)
+	sub, err := filterer.WatchUnopenedCommitmentStored(&bind.WatchOpts{Context: ctx}, events, nil)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+	slog.Info("Observing competitor commitments", "preconfManager", address.Hex())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return err
+		case ev := <-events:
+			o.Observe(fromEvent(ev))
+		}
+	}
+}
+
+// BlockInclusions returns the blob transactions of block with their senders.
+// Transactions whose sender cannot be recovered are left out.
+func BlockInclusions(block *types.Block) []Inclusion {
+	var included []Inclusion
+	for _, tx := range block.Transactions() {
+		if tx.Type() != types.BlobTxType {
+			continue
+		}
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			continue
+		}
+		included = append(included, Inclusion{TxHash: tx.Hash().Hex(), From: from})
+	}
+	return included
+}
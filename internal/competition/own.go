@@ -0,0 +1,173 @@
+package competition
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultOwnRetention is how long the hash of a sent transaction is kept.
+// Commitments are opened and transactions included within minutes; a day
+// covers restarts and delayed inclusions.
+const DefaultOwnRetention = 24 * time.Hour
+
+// ownTx is a line of the own transaction file.
+type ownTx struct {
+	Hash string    `json:"hash"`
+	Sent time.Time `json:"sent"`
+}
+
+// Ownership recognizes our own transactions among those observed on chain:
+// those sent from the address of any configured key, and those whose hash
+// was added when they were sent. With a file, added hashes are appended to it
+// and read back on start, so transactions sent before a restart are still
+// recognized when their commitments are opened or they are included.
+type Ownership struct {
+	mu        sync.Mutex
+	addresses map[common.Address]bool
+	txs       map[string]time.Time
+	file      *os.File
+	retention time.Duration
+	now       func() time.Time
+}
+
+// OpenOwnership returns the ownership of addresses and of the transactions
+// recorded in path. The file is rewritten without hashes older than
+// DefaultOwnRetention; an empty path keeps hashes in memory only.
+func OpenOwnership(path string, addresses []common.Address) (*Ownership, error) {
+	o := &Ownership{
+		addresses: make(map[common.Address]bool, len(addresses)),
+		txs:       make(map[string]time.Time),
+		retention: DefaultOwnRetention,
+		now:       time.Now,
+	}
+	for _, addr := range addresses {
+		o.addresses[addr] = true
+	}
+	if path == "" {
+		return o, nil
+	}
+	if err := o.load(path); err != nil {
+		return nil, fmt.Errorf("failed to read own transaction file: %w", err)
+	}
+	if err := o.compact(path); err != nil {
+		return nil, fmt.Errorf("failed to rewrite own transaction file: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open own transaction file: %w", err)
+	}
+	o.file = f
+	return o, nil
+}
+
+// load reads the retained hashes of path, skipping lines it cannot parse.
+func (o *Ownership) load(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cutoff := o.now().Add(-o.retention)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var tx ownTx
+		if json.Unmarshal(scanner.Bytes(), &tx) != nil || tx.Hash == "" || tx.Sent.Before(cutoff) {
+			continue
+		}
+		o.txs[normalizeDigest(tx.Hash)] = tx.Sent
+	}
+	return scanner.Err()
+}
+
+// compact replaces path with the hashes held in memory.
+func (o *Ownership) compact(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	enc := json.NewEncoder(tmp)
+	for hash, sent := range o.txs {
+		if err := enc.Encode(ownTx{Hash: "0x" + hash, Sent: sent}); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Close closes the file.
+func (o *Ownership) Close() error {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file == nil {
+		return nil
+	}
+	return o.file.Close()
+}
+
+// AddTx records the hash of a transaction we send.
+func (o *Ownership) AddTx(hash string) {
+	if o == nil {
+		return
+	}
+	key := normalizeDigest(hash)
+	if key == "" {
+		return
+	}
+	now := o.now()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.txs[key]; ok {
+		return
+	}
+	o.txs[key] = now
+	cutoff := now.Add(-o.retention)
+	for h, sent := range o.txs {
+		if sent.Before(cutoff) {
+			delete(o.txs, h)
+		}
+	}
+	if o.file == nil {
+		return
+	}
+	if err := json.NewEncoder(o.file).Encode(ownTx{Hash: "0x" + key, Sent: now}); err != nil {
+		slog.Warn("Failed to record own transaction", "error", err, "txHash", hash)
+	}
+}
+
+// IsAddress reports whether addr is the address of one of our keys.
+func (o *Ownership) IsAddress(addr common.Address) bool {
+	if o == nil {
+		return false
+	}
+	return o.addresses[addr]
+}
+
+// IsTx reports whether hash is one of the transactions we sent.
+func (o *Ownership) IsTx(hash string) bool {
+	if o == nil {
+		return false
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, ok := o.txs[normalizeDigest(hash)]
+	return ok
+}
@@ -0,0 +1,1083 @@
+// Package config loads the bidder configuration into a single typed struct.
+//
+// Every setting can come from a YAML config file, an environment variable or
+// a command line flag, in increasing order of precedence. YAML keys are the
+// environment variable names in lower case.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/primev/preconf_blob_bidder/internal/auth"
+	"github.com/primev/preconf_blob_bidder/internal/backfill"
+	"github.com/primev/preconf_blob_bidder/internal/budget"
+	"github.com/primev/preconf_blob_bidder/internal/canary"
+	"github.com/primev/preconf_blob_bidder/internal/clock"
+	"github.com/primev/preconf_blob_bidder/internal/coordination"
+	ee "github.com/primev/preconf_blob_bidder/internal/eth"
+	"github.com/primev/preconf_blob_bidder/internal/inflight"
+	"github.com/primev/preconf_blob_bidder/internal/jobs"
+	"github.com/primev/preconf_blob_bidder/internal/labels"
+	"github.com/primev/preconf_blob_bidder/internal/lanes"
+	"github.com/primev/preconf_blob_bidder/internal/logging"
+	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+	"github.com/primev/preconf_blob_bidder/internal/pricing"
+	"github.com/primev/preconf_blob_bidder/internal/providers"
+	"github.com/primev/preconf_blob_bidder/internal/reconcile"
+	"github.com/primev/preconf_blob_bidder/internal/schedule"
+	"github.com/primev/preconf_blob_bidder/internal/slots"
+	"github.com/primev/preconf_blob_bidder/internal/store"
+	"github.com/primev/preconf_blob_bidder/internal/telemetry"
+	"github.com/primev/preconf_blob_bidder/internal/tracing"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxTargetBlockSpan is the most consecutive target blocks a transaction is
+// bid for.
+const MaxTargetBlockSpan = 8
+
+// DefaultDrainTimeout is how long in-flight bids may finish on shutdown.
+const DefaultDrainTimeout = 15 * time.Second
+
+// DefaultStatusInterval is how often the account summary is logged.
+const DefaultStatusInterval = time.Minute
+
+// Transaction types bid with, as set by tx_type.
+const (
+	TxTransfer = "transfer"
+	TxBlob     = "blob"
+	TxERC20    = "erc20"
+	TxCall     = "contract-call"
+	TxRaw      = "raw"
+)
+
+// Config is the bidder configuration.
+type Config struct {
+	AppName string `yaml:"app_name" env:"APP_NAME" flag:"app-name"`
+	Version string `yaml:"version" env:"VERSION" flag:"version"`
+	// UserAgent identifies the deployment on every HTTP, WebSocket and gRPC
+	// request, app_name/version when empty. Request IDs start with
+	// RequestIDPrefix.
+	UserAgent       string `yaml:"user_agent" env:"USER_AGENT" flag:"user-agent"`
+	RequestIDPrefix string `yaml:"request_id_prefix" env:"REQUEST_ID_PREFIX" flag:"request-id-prefix"`
+	// Tenant labels logs, metrics and stored rows in deployments shared
+	// between customers.
+	Tenant string `yaml:"tenant" env:"TENANT" flag:"tenant"`
+
+	ServerAddress string `yaml:"server_address" env:"SERVER_ADDRESS" flag:"server-address"`
+	RPCEndpoint   string `yaml:"rpc_endpoint" env:"RPC_ENDPOINT" flag:"rpc-endpoint"`
+	WSEndpoint    string `yaml:"ws_endpoint" env:"WS_ENDPOINT" flag:"ws-endpoint"`
+	PrivateKey    string `yaml:"private_key" env:"PRIVATE_KEY" flag:"private-key" secret:"true"`
+
+	// After the block subscription reconnects, the headers of up to
+	// BackfillBlocks blocks missed meanwhile are read from rpc_endpoint, or
+	// the node reconnected to without one; 0 skips backfilling.
+	// BidOnReconnect bids on the newest of them right away.
+	BackfillBlocks uint64 `yaml:"backfill_blocks" env:"BACKFILL_BLOCKS" flag:"backfill-blocks"`
+	BidOnReconnect bool   `yaml:"bid_on_reconnect" env:"BID_ON_RECONNECT" flag:"bid-on-reconnect"`
+
+	// A block subscription that delivers no header for WSStallSlots slots is
+	// torn down and re-established; 0 waits for the node to close it.
+	WSStallSlots uint64 `yaml:"ws_stall_slots" env:"WS_STALL_SLOTS" flag:"ws-stall-slots"`
+
+	// An encrypted geth keystore file can hold the key instead of private_key.
+	KeystorePath         string `yaml:"keystore_path" env:"KEYSTORE_PATH" flag:"keystore-path"`
+	KeystorePassword     string `yaml:"keystore_password" env:"KEYSTORE_PASSWORD" flag:"keystore-password" secret:"true"`
+	KeystorePasswordFile string `yaml:"keystore_password_file" env:"KEYSTORE_PASSWORD_FILE" flag:"keystore-password-file"`
+
+	// A remote signer (Clef or web3signer) can hold the key instead, so it
+	// never enters the bidder process.
+	RemoteSignerURL     string `yaml:"remote_signer_url" env:"REMOTE_SIGNER_URL" flag:"remote-signer-url"`
+	RemoteSignerKind    string `yaml:"remote_signer_kind" env:"REMOTE_SIGNER_KIND" flag:"remote-signer-kind"`
+	RemoteSignerAddress string `yaml:"remote_signer_address" env:"REMOTE_SIGNER_ADDRESS" flag:"remote-signer-address"`
+
+	// The bidder node connection is insecure unless TLS is enabled or a CA
+	// or client certificate is given.
+	BidderTLS       bool   `yaml:"bidder_tls" env:"BIDDER_TLS" flag:"bidder-tls"`
+	BidderTLSCACert string `yaml:"bidder_tls_ca_cert" env:"BIDDER_TLS_CA_CERT" flag:"bidder-tls-ca-cert"`
+	BidderTLSCert   string `yaml:"bidder_tls_cert" env:"BIDDER_TLS_CERT" flag:"bidder-tls-cert"`
+	BidderTLSKey    string `yaml:"bidder_tls_key" env:"BIDDER_TLS_KEY" flag:"bidder-tls-key"`
+	BidderToken     string `yaml:"bidder_token" env:"BIDDER_TOKEN" flag:"bidder-token" secret:"true"`
+
+	BidRetryBudget  uint          `yaml:"bid_retry_budget" env:"BID_RETRY_BUDGET" flag:"bid-retry-budget"` // Retries per bid while the node is unavailable.
+	BidRetryBackoff time.Duration `yaml:"bid_retry_backoff" env:"BID_RETRY_BACKOFF" flag:"bid-retry-backoff"`
+
+	// Bids carrying raw transactions, blob sidecars included, are compressed
+	// when enabled, and bids above max_bid_payload_bytes are not sent.
+	BidCompression     bool `yaml:"bid_compression" env:"BID_COMPRESSION" flag:"bid-compression"`
+	MaxBidPayloadBytes uint `yaml:"max_bid_payload_bytes" env:"MAX_BID_PAYLOAD_BYTES" flag:"max-bid-payload-bytes"`
+
+	UsePayload        bool   `yaml:"use_payload" env:"USE_PAYLOAD" flag:"use-payload"`
+	PayloadPrivacy    string `yaml:"payload_privacy" env:"PAYLOAD_PRIVACY" flag:"payload-privacy"`
+	RetainRawPayloads bool   `yaml:"retain_raw_payloads" env:"RETAIN_RAW_PAYLOADS" flag:"retain-raw-payloads"`
+
+	SubmissionBackend  string `yaml:"submission_backend" env:"SUBMISSION_BACKEND" flag:"submission-backend"`
+	PreconfRPCEndpoint string `yaml:"preconf_rpc_endpoint" env:"PRECONF_RPC_ENDPOINT" flag:"preconf-rpc-endpoint"`
+
+	// PublicFallbackBlocks is after how many blocks in a row without a
+	// commitment an account's transaction is broadcast to PublicRPCEndpoint,
+	// rpc_endpoint when empty. 0 never broadcasts.
+	PublicFallbackBlocks uint64 `yaml:"public_fallback_blocks" env:"PUBLIC_FALLBACK_BLOCKS" flag:"public-fallback-blocks"`
+	PublicRPCEndpoint    string `yaml:"public_rpc_endpoint" env:"PUBLIC_RPC_ENDPOINT" flag:"public-rpc-endpoint"`
+
+	Offset           uint64  `yaml:"offset" env:"OFFSET" flag:"offset"`
+	TargetBlockSpan  uint64  `yaml:"target_block_span" env:"TARGET_BLOCK_SPAN" flag:"target-block-span"`
+	BidAmount        float64 `yaml:"bid_amount" env:"BID_AMOUNT" flag:"bid-amount"`
+	StdDevPercentage float64 `yaml:"bid_amount_std_dev_percentage" env:"BID_AMOUNT_STD_DEV_PERCENTAGE" flag:"bid-amount-std-dev-percentage"`
+	PriorityFeeGwei  uint64  `yaml:"priority_fee_gwei" env:"PRIORITY_FEE_GWEI" flag:"priority-fee-gwei"`
+	NumBlob          uint    `yaml:"num_blob" env:"NUM_BLOB" flag:"num-blob"`
+	KZGTrustedSetup  string  `yaml:"kzg_trusted_setup" env:"KZG_TRUSTED_SETUP" flag:"kzg-trusted-setup"` // Empty uses the setup built into the binary.
+
+	// Caps on the ETH committed in bids within any hour or day, and on the
+	// bids per target block; 0 is no cap.
+	HourlyBudget    float64 `yaml:"hourly_budget" env:"HOURLY_BUDGET" flag:"hourly-budget"`
+	DailyBudget     float64 `yaml:"daily_budget" env:"DAILY_BUDGET" flag:"daily-budget"`
+	BudgetMode      string  `yaml:"budget_mode" env:"BUDGET_MODE" flag:"budget-mode"`
+	MaxBidsPerBlock uint    `yaml:"max_bids_per_block" env:"MAX_BIDS_PER_BLOCK" flag:"max-bids-per-block"`
+
+	GasOracle        string  `yaml:"gas_oracle" env:"GAS_ORACLE" flag:"gas-oracle"`
+	GasTipPercentile float64 `yaml:"gas_tip_percentile" env:"GAS_TIP_PERCENTILE" flag:"gas-tip-percentile"`
+	MaxBaseFeeGwei   float64 `yaml:"max_base_fee_gwei" env:"MAX_BASE_FEE_GWEI" flag:"max-base-fee-gwei"` // 0 for no maximum.
+	FeeBump          float64 `yaml:"fee_bump" env:"FEE_BUMP" flag:"fee-bump"`
+
+	BlobFeeSource      string  `yaml:"blob_fee_source" env:"BLOB_FEE_SOURCE" flag:"blob-fee-source"`
+	BlobFeeBump        float64 `yaml:"blob_fee_bump" env:"BLOB_FEE_BUMP" flag:"blob-fee-bump"`
+	MaxBlobFeeCapGwei  float64 `yaml:"max_blob_fee_cap_gwei" env:"MAX_BLOB_FEE_CAP_GWEI" flag:"max-blob-fee-cap-gwei"` // 0 for no maximum.
+	BlobFeeCeilingGwei float64 `yaml:"blob_fee_ceiling_gwei" env:"BLOB_FEE_CEILING_GWEI" flag:"blob-fee-ceiling-gwei"` // 0 for no ceiling.
+
+	TxType         string `yaml:"tx_type" env:"TX_TYPE" flag:"tx-type"` // Empty derives transfer or blob from num_blob.
+	ERC20Token     string `yaml:"erc20_token" env:"ERC20_TOKEN" flag:"erc20-token"`
+	ERC20Recipient string `yaml:"erc20_recipient" env:"ERC20_RECIPIENT" flag:"erc20-recipient"`
+	ERC20Amount    string `yaml:"erc20_amount" env:"ERC20_AMOUNT" flag:"erc20-amount"` // In token base units.
+
+	TransferAmount string `yaml:"transfer_amount" env:"TRANSFER_AMOUNT" flag:"transfer-amount"` // In wei; empty sends 0.001 ETH.
+	TransferTo     string `yaml:"transfer_to" env:"TRANSFER_TO" flag:"transfer-to"`             // Empty sends to the sending account.
+
+	ContractAddress  string `yaml:"contract_address" env:"CONTRACT_ADDRESS" flag:"contract-address"`
+	ContractABI      string `yaml:"contract_abi" env:"CONTRACT_ABI" flag:"contract-abi"` // Path to the ABI JSON file.
+	ContractMethod   string `yaml:"contract_method" env:"CONTRACT_METHOD" flag:"contract-method"`
+	ContractArgs     string `yaml:"contract_args" env:"CONTRACT_ARGS" flag:"contract-args"` // Comma-separated.
+	ContractCalldata string `yaml:"contract_calldata" env:"CONTRACT_CALLDATA" flag:"contract-calldata"`
+
+	RawTxFile string `yaml:"raw_tx_file" env:"RAW_TX_FILE" flag:"raw-tx-file"` // One signed transaction hex per line.
+
+	DecayMin   time.Duration `yaml:"decay_min" env:"DECAY_MIN" flag:"decay-min"`
+	DecayMax   time.Duration `yaml:"decay_max" env:"DECAY_MAX" flag:"decay-max"` // 0 means no maximum.
+	DecayClamp bool          `yaml:"decay_clamp" env:"DECAY_CLAMP" flag:"decay-clamp"`
+
+	// With BidSlotOffset set, bids for the latest header are dispatched that
+	// long after the start of every slot instead of on its arrival. Slots are
+	// counted from GenesisTime, in Unix seconds, or aligned to the first
+	// header when 0.
+	BidSlotOffset time.Duration `yaml:"bid_slot_offset" env:"BID_SLOT_OFFSET" flag:"bid-slot-offset"`
+	GenesisTime   uint64        `yaml:"genesis_time" env:"GENESIS_TIME" flag:"genesis-time"`
+
+	// TransferPrivateKey runs transfer bids from a second account alongside blob bids.
+	TransferPrivateKey string `yaml:"transfer_private_key" env:"TRANSFER_PRIVATE_KEY" flag:"transfer-private-key" secret:"true"`
+
+	// Extra accounts bid the same kind of transaction as the primary one, each
+	// with its own nonces, taking turns or all at once as account_rotation says.
+	ExtraPrivateKeys   string `yaml:"extra_private_keys" env:"EXTRA_PRIVATE_KEYS" flag:"extra-private-keys" secret:"true"` // Comma-separated.
+	ExtraKeystorePaths string `yaml:"extra_keystore_paths" env:"EXTRA_KEYSTORE_PATHS" flag:"extra-keystore-paths"`         // Comma-separated, decrypted with the keystore password.
+	AccountRotation    string `yaml:"account_rotation" env:"ACCOUNT_ROTATION" flag:"account-rotation"`
+
+	// The nonce manager issues nonces locally while transactions are in flight.
+	NonceManager bool          `yaml:"nonce_manager" env:"NONCE_MANAGER" flag:"nonce-manager"`
+	NonceResync  time.Duration `yaml:"nonce_resync" env:"NONCE_RESYNC" flag:"nonce-resync"`
+
+	// StaleTxReplacements is how often the transaction of a nonce whose bid
+	// went without a commitment is bid on again with bumped fees; 0 builds a
+	// new transaction for every block.
+	StaleTxReplacements uint64 `yaml:"stale_tx_replacements" env:"STALE_TX_REPLACEMENTS" flag:"stale-tx-replacements"`
+
+	// ReconcileBlocks is how many recent blocks are scanned on startup for
+	// transactions of a previous run; 0 skips reconciliation.
+	ReconcileBlocks uint64 `yaml:"reconcile_blocks" env:"RECONCILE_BLOCKS" flag:"reconcile-blocks"`
+
+	DefaultTimeout     uint   `yaml:"default_timeout" env:"DEFAULT_TIMEOUT" flag:"default-timeout"`                // Seconds.
+	RunDurationMinutes uint   `yaml:"run_duration_minutes" env:"RUN_DURATION_MINUTES" flag:"run-duration-minutes"` // 0 runs indefinitely.
+	LatencyBudgets     string `yaml:"latency_budgets" env:"LATENCY_BUDGETS" flag:"latency-budgets"`
+	DryRun             bool   `yaml:"dry_run" env:"DRY_RUN" flag:"dry-run"` // Build and log bids without sending them.
+	TUI                bool   `yaml:"tui" env:"TUI" flag:"tui"`             // Terminal status screen instead of scrolling logs.
+
+	// Records are written to the console, and LogFile when set, which is
+	// rotated once it outgrows LogMaxSizeMB or LogMaxAge.
+	LogFormat     string        `yaml:"log_format" env:"LOG_FORMAT" flag:"log-format"`
+	LogLevel      string        `yaml:"log_level" env:"LOG_LEVEL" flag:"log-level"`
+	LogFile       string        `yaml:"log_file" env:"LOG_FILE" flag:"log-file"`
+	LogMaxSizeMB  uint64        `yaml:"log_max_size_mb" env:"LOG_MAX_SIZE_MB" flag:"log-max-size-mb"` // 0 never rotates by size.
+	LogMaxAge     time.Duration `yaml:"log_max_age" env:"LOG_MAX_AGE" flag:"log-max-age"`             // 0 never rotates by age.
+	LogMaxBackups uint64        `yaml:"log_max_backups" env:"LOG_MAX_BACKUPS" flag:"log-max-backups"` // 0 keeps every rotated file.
+
+	// At most LogSampleBurst records of the same warning or error message are
+	// written per LogSampleInterval; 0 writes every record.
+	LogSampleBurst    uint64        `yaml:"log_sample_burst" env:"LOG_SAMPLE_BURST" flag:"log-sample-burst"`
+	LogSampleInterval time.Duration `yaml:"log_sample_interval" env:"LOG_SAMPLE_INTERVAL" flag:"log-sample-interval"`
+
+	// Spans of the bidding path are exported to OTLPEndpoint, an OTLP/HTTP
+	// URL, for TraceSampleRatio of the headers; empty disables tracing.
+	OTLPEndpoint     string  `yaml:"otlp_endpoint" env:"OTLP_ENDPOINT" flag:"otlp-endpoint"`
+	TraceSampleRatio float64 `yaml:"trace_sample_ratio" env:"TRACE_SAMPLE_RATIO" flag:"trace-sample-ratio"`
+
+	AutoRollover       bool    `yaml:"auto_rollover" env:"AUTO_ROLLOVER" flag:"auto-rollover"`
+	DepositAmount      float64 `yaml:"deposit_amount" env:"DEPOSIT_AMOUNT" flag:"deposit-amount"`
+	BlocksPerWindow    uint64  `yaml:"blocks_per_window" env:"BLOCKS_PER_WINDOW" flag:"blocks-per-window"`
+	AutoWithdraw       bool    `yaml:"auto_withdraw" env:"AUTO_WITHDRAW" flag:"auto-withdraw"`
+	AutoWithdrawDryRun bool    `yaml:"auto_withdraw_dry_run" env:"AUTO_WITHDRAW_DRY_RUN" flag:"auto-withdraw-dry-run"`
+
+	HALeaseFile  string `yaml:"ha_lease_file" env:"HA_LEASE_FILE" flag:"ha-lease-file"`
+	HAInstanceID string `yaml:"ha_instance_id" env:"HA_INSTANCE_ID" flag:"ha-instance-id"`
+	HALeaseTTL   uint   `yaml:"ha_lease_ttl" env:"HA_LEASE_TTL" flag:"ha-lease-ttl"` // Seconds.
+
+	Seed            int64  `yaml:"seed" env:"SEED" flag:"seed"` // 0 picks a random seed.
+	RecordFile      string `yaml:"record_file" env:"RECORD_FILE" flag:"record-file"`
+	ActivityFile    string `yaml:"activity_file" env:"ACTIVITY_FILE" flag:"activity-file"`
+	SkipLogFile     string `yaml:"skip_log_file" env:"SKIP_LOG_FILE" flag:"skip-log-file"`
+	ConflictLogFile string `yaml:"conflict_log_file" env:"CONFLICT_LOG_FILE" flag:"conflict-log-file"`
+	OwnTxFile       string `yaml:"own_tx_file" env:"OWN_TX_FILE" flag:"own-tx-file"`
+	DisputeFile     string `yaml:"dispute_file" env:"DISPUTE_FILE" flag:"dispute-file"`
+	DisputeEndpoint string `yaml:"dispute_endpoint" env:"DISPUTE_ENDPOINT" flag:"dispute-endpoint"`
+	BidHistoryFile  string `yaml:"bid_history_file" env:"BID_HISTORY_FILE" flag:"bid-history-file"`
+	StaleBidBlocks  uint64 `yaml:"stale_bid_blocks" env:"STALE_BID_BLOCKS" flag:"stale-bid-blocks"`
+
+	// InclusionTracking checks every bid's target block for its transaction,
+	// and InclusionLogFile records where inclusion and commitments disagree.
+	InclusionTracking bool   `yaml:"inclusion_tracking" env:"INCLUSION_TRACKING" flag:"inclusion-tracking"`
+	InclusionLogFile  string `yaml:"inclusion_log_file" env:"INCLUSION_LOG_FILE" flag:"inclusion-log-file"`
+
+	// BidHistoryRetention is how long bids are kept row by row before being
+	// compacted into hourly aggregates; 0 keeps every row.
+	BidHistoryRetention time.Duration `yaml:"bid_history_retention" env:"BID_HISTORY_RETENTION" flag:"bid-history-retention"`
+
+	ClockSkewThreshold time.Duration `yaml:"clock_skew_threshold" env:"CLOCK_SKEW_THRESHOLD" flag:"clock-skew-threshold"`
+	ClockCompensate    bool          `yaml:"clock_compensate" env:"CLOCK_COMPENSATE" flag:"clock-compensate"`
+	NTPServer          string        `yaml:"ntp_server" env:"NTP_SERVER" flag:"ntp-server"`
+
+	Strategy       string `yaml:"strategy" env:"STRATEGY" flag:"strategy"`
+	StrategyScript string `yaml:"strategy_script" env:"STRATEGY_SCRIPT" flag:"strategy-script"`
+	StrategyPlugin string `yaml:"strategy_plugin" env:"STRATEGY_PLUGIN" flag:"strategy-plugin"`
+
+	AdaptiveTargetRate float64 `yaml:"adaptive_target_rate" env:"ADAPTIVE_TARGET_RATE" flag:"adaptive-target-rate"`
+	AdaptiveStep       float64 `yaml:"adaptive_step" env:"ADAPTIVE_STEP" flag:"adaptive-step"`
+	AdaptiveMinScale   float64 `yaml:"adaptive_min_scale" env:"ADAPTIVE_MIN_SCALE" flag:"adaptive-min-scale"`
+	AdaptiveMaxScale   float64 `yaml:"adaptive_max_scale" env:"ADAPTIVE_MAX_SCALE" flag:"adaptive-max-scale"`
+	// ProviderWeighting weighs commitments by provider stake and commit rate
+	// for the adaptive strategy.
+	ProviderWeighting string `yaml:"provider_weighting" env:"PROVIDER_WEIGHTING" flag:"provider-weighting"`
+
+	CanaryPercent float64 `yaml:"canary_percent" env:"CANARY_PERCENT" flag:"canary-percent"` // 0 applies new parameters at once.
+	CanaryBlocks  int     `yaml:"canary_blocks" env:"CANARY_BLOCKS" flag:"canary-blocks"`
+
+	BackrunTx   string `yaml:"backrun_tx" env:"BACKRUN_TX" flag:"backrun-tx"`
+	BundleHints string `yaml:"bundle_hints" env:"BUNDLE_HINTS" flag:"bundle-hints"`
+	// BundleSearchKey signs bundle requests for the X-Flashbots-Signature
+	// header; it needs no funds.
+	BundleSearchKey   string        `yaml:"bundle_search_key" env:"BUNDLE_SEARCH_KEY" flag:"bundle-search-key" secret:"true"`
+	BundleReplacement bool          `yaml:"bundle_replacement" env:"BUNDLE_REPLACEMENT" flag:"bundle-replacement"` // A replacementUuid per account and target block.
+	BundleValidity    time.Duration `yaml:"bundle_validity" env:"BUNDLE_VALIDITY" flag:"bundle-validity"`          // 0 sends no minTimestamp and maxTimestamp.
+	// BundleRelays lists further relays bundles are sent to along with the
+	// RPC endpoint, as comma-separated name=url pairs.
+	BundleRelays   string `yaml:"bundle_relays" env:"BUNDLE_RELAYS" flag:"bundle-relays"`
+	DisabledRelays string `yaml:"disabled_relays" env:"DISABLED_RELAYS" flag:"disabled-relays"` // Names of relays not sent to, rpc for the RPC endpoint.
+	// SubmitMethod is bundle or private-tx, the latter submitting with
+	// eth_sendPrivateRawTransaction and PrivateTxPreferences.
+	SubmitMethod         string `yaml:"submit_method" env:"SUBMIT_METHOD" flag:"submit-method"`
+	PrivateTxPreferences string `yaml:"private_tx_preferences" env:"PRIVATE_TX_PREFERENCES" flag:"private-tx-preferences"`
+
+	MevCommitWSEndpoint string `yaml:"mev_commit_ws_endpoint" env:"MEV_COMMIT_WS_ENDPOINT" flag:"mev-commit-ws-endpoint"`
+
+	DrainTimeout time.Duration `yaml:"drain_timeout" env:"DRAIN_TIMEOUT" flag:"drain-timeout"`
+
+	// Optional work is shed while resident memory or goroutines are above
+	// these; 0 disables a threshold.
+	MaxRSSMB      uint64 `yaml:"max_rss_mb" env:"MAX_RSS_MB" flag:"max-rss-mb"`
+	MaxGoroutines uint64 `yaml:"max_goroutines" env:"MAX_GOROUTINES" flag:"max-goroutines"`
+
+	// ConfigSnapshotFile keeps the previous run's settings, to log what changed.
+	ConfigSnapshotFile string `yaml:"config_snapshot_file" env:"CONFIG_SNAPSHOT_FILE" flag:"config-snapshot-file"`
+
+	StatusAddress  string        `yaml:"status_address" env:"STATUS_ADDRESS" flag:"status-address"`
+	StatusInterval time.Duration `yaml:"status_interval" env:"STATUS_INTERVAL" flag:"status-interval"`
+	// DisableJobs lists maintenance jobs that are not run, comma-separated.
+	DisableJobs string `yaml:"disable_jobs" env:"DISABLE_JOBS" flag:"disable-jobs"`
+	// Comma-separated bearer tokens for the status server; without any, it is open.
+	StatusReadTokens  string `yaml:"status_read_tokens" env:"STATUS_READ_TOKENS" flag:"status-read-tokens" secret:"true"`
+	StatusAdminTokens string `yaml:"status_admin_tokens" env:"STATUS_ADMIN_TOKENS" flag:"status-admin-tokens" secret:"true"`
+	// Changes made through the admin API are appended to this JSON lines file.
+	ControlAuditFile string `yaml:"control_audit_file" env:"CONTROL_AUDIT_FILE" flag:"control-audit-file"`
+
+	// With a campaign schedule file the bidder bids only while a scheduled
+	// campaign runs; campaigns are managed on the status server.
+	CampaignScheduleFile string        `yaml:"campaign_schedule_file" env:"CAMPAIGN_SCHEDULE_FILE" flag:"campaign-schedule-file"`
+	CampaignLead         time.Duration `yaml:"campaign_lead" env:"CAMPAIGN_LEAD" flag:"campaign-lead"` // Prerequisites are checked from this long before the start.
+
+	Network               string `yaml:"network" env:"NETWORK" flag:"network"`
+	ContractsURL          string `yaml:"contracts_url" env:"CONTRACTS_URL" flag:"contracts-url"`
+	BidderRegistryAddress string `yaml:"bidder_registry_address" env:"BIDDER_REGISTRY_ADDRESS"`
+	BlockTrackerAddress   string `yaml:"block_tracker_address" env:"BLOCK_TRACKER_ADDRESS"`
+	PreconfManagerAddress string `yaml:"preconf_manager_address" env:"PRECONF_MANAGER_ADDRESS"`
+
+	ConfirmMainnet    bool `yaml:"confirm_mainnet" env:"CONFIRM_MAINNET" flag:"confirm-mainnet"`
+	AllowMainnetBlobs bool `yaml:"allow_mainnet_blobs" env:"ALLOW_MAINNET_BLOBS" flag:"allow-mainnet-blobs"`
+
+	// Telemetry opts in to periodic anonymized reports; it is off by default.
+	Telemetry         bool          `yaml:"telemetry" env:"TELEMETRY" flag:"telemetry"`
+	TelemetryEndpoint string        `yaml:"telemetry_endpoint" env:"TELEMETRY_ENDPOINT" flag:"telemetry-endpoint"`
+	TelemetryInterval time.Duration `yaml:"telemetry_interval" env:"TELEMETRY_INTERVAL" flag:"telemetry-interval"`
+
+	// set holds the YAML keys of the settings not left at their default.
+	set map[string]bool
+}
+
+// Default returns the configuration used for settings that are not set.
+func Default() Config {
+	return Config{
+		AppName:             "preconf_bidder",
+		Version:             "0.8.0",
+		ServerAddress:       "localhost:13524",
+		RPCEndpoint:         "https://ethereum-holesky-rpc.publicnode.com",
+		WSEndpoint:          "wss://ethereum-holesky-rpc.publicnode.com",
+		UsePayload:          true,
+		SubmissionBackend:   string(bb.BackendBidder),
+		SubmitMethod:        string(ee.SubmitBundle),
+		BidRetryBudget:      bb.DefaultBidRetries,
+		BidRetryBackoff:     bb.DefaultBidRetryBackoff,
+		MaxBidPayloadBytes:  bb.DefaultMaxBidPayload,
+		Offset:              1,
+		TargetBlockSpan:     1,
+		DecayMin:            bb.DefaultMinDecay,
+		DecayClamp:          true,
+		BidAmount:           0.001,
+		BudgetMode:          string(budget.Stop),
+		StdDevPercentage:    100,
+		PriorityFeeGwei:     1,
+		GasOracle:           string(ee.FixedGasOracle),
+		GasTipPercentile:    50,
+		FeeBump:             1,
+		BlobFeeSource:       string(ee.HeaderBlobFee),
+		BlobFeeBump:         ee.DefaultBlobFeeBump,
+		DefaultTimeout:      15,
+		DepositAmount:       0.1,
+		BlocksPerWindow:     bb.DefaultBlocksPerWindow,
+		HAInstanceID:        coordination.DefaultInstanceID(),
+		HALeaseTTL:          uint(coordination.DefaultLeaseTTL / time.Second),
+		StaleBidBlocks:      inflight.DefaultMaxAgeBlocks,
+		BidHistoryRetention: store.DefaultRetention,
+		ClockSkewThreshold:  clock.DefaultThreshold,
+		ClockCompensate:     true,
+		Strategy:            "gaussian",
+		Network:             bb.DefaultNetwork,
+		DrainTimeout:        DefaultDrainTimeout,
+		LogFormat:           string(logging.Pretty),
+		LogLevel:            "info",
+		LogMaxSizeMB:        logging.DefaultMaxSizeMB,
+		LogMaxBackups:       logging.DefaultMaxBackups,
+		LogSampleBurst:      logging.DefaultSampleBurst,
+		LogSampleInterval:   logging.DefaultSampleInterval,
+		TraceSampleRatio:    tracing.DefaultSampleRatio,
+		BackfillBlocks:      backfill.DefaultMaxBlocks,
+		WSStallSlots:        bb.DefaultWSStallSlots,
+		StatusInterval:      DefaultStatusInterval,
+		CampaignLead:        schedule.DefaultLead,
+		CanaryBlocks:        canary.DefaultBlocks,
+		AdaptiveTargetRate:  pricing.DefaultTargetRate,
+		AdaptiveStep:        pricing.DefaultStep,
+		AdaptiveMinScale:    pricing.DefaultMinScale,
+		AdaptiveMaxScale:    pricing.DefaultMaxScale,
+		ProviderWeighting:   string(providers.None),
+		RemoteSignerKind:    string(ee.Clef),
+		AccountRotation:     string(lanes.RoundRobin),
+		NonceResync:         ee.DefaultNonceResync,
+		StaleTxReplacements: ee.DefaultStaleTxReplacements,
+		ReconcileBlocks:     reconcile.DefaultBlocks,
+		TelemetryEndpoint:   telemetry.DefaultEndpoint,
+		TelemetryInterval:   telemetry.DefaultInterval,
+	}
+}
+
+// FlagSource is the command line, as implemented by *cli.Context.
+type FlagSource interface {
+	IsSet(name string) bool
+	String(name string) string
+}
+
+// Load returns the defaults overridden by the YAML file at path (if not
+// empty), then by the environment and then by flags set on the command line.
+// The result is validated.
+func Load(path string, lookupEnv func(string) (string, bool), flags FlagSource) (Config, error) {
+	cfg := Default()
+	cfg.set = make(map[string]bool)
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return cfg, err
+		}
+	}
+
+	v := reflect.ValueOf(&cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if env := field.Tag.Get("env"); env != "" && lookupEnv != nil {
+			if s, ok := lookupEnv(env); ok && s != "" {
+				if err := setField(v.Field(i), s); err != nil {
+					return cfg, fmt.Errorf("%s: %w", env, err)
+				}
+				cfg.set[field.Tag.Get("yaml")] = true
+			}
+		}
+		if name := field.Tag.Get("flag"); name != "" && flags != nil && flags.IsSet(name) {
+			if err := setField(v.Field(i), flags.String(name)); err != nil {
+				return cfg, fmt.Errorf("--%s: %w", name, err)
+			}
+			cfg.set[field.Tag.Get("yaml")] = true
+		}
+	}
+	return cfg, cfg.Validate()
+}
+
+// loadFile overrides cfg with the settings in a YAML file. Unknown keys are
+// rejected so typos do not silently fall back to defaults.
+func (cfg *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	var keys map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	for key := range keys {
+		cfg.set[key] = true
+	}
+	return nil
+}
+
+// LogRotation returns when the log file is rotated.
+func (cfg Config) LogRotation() logging.Rotation {
+	return logging.Rotation{
+		MaxSize:    int64(cfg.LogMaxSizeMB) << 20,
+		MaxAge:     cfg.LogMaxAge,
+		MaxBackups: int(cfg.LogMaxBackups),
+	}
+}
+
+// BidderConfig returns the settings of the connection to the bidder node.
+func (cfg Config) BidderConfig() bb.BidderConfig {
+	return bb.BidderConfig{
+		ServerAddress: cfg.ServerAddress,
+		TLS:           cfg.BidderTLS,
+		TLSCACert:     cfg.BidderTLSCACert,
+		TLSCert:       cfg.BidderTLSCert,
+		TLSKey:        cfg.BidderTLSKey,
+		Token:         cfg.BidderToken,
+		Retry: bb.RetryPolicy{
+			MaxRetries:     int(cfg.BidRetryBudget),
+			InitialBackoff: cfg.BidRetryBackoff,
+		},
+		Compression: cfg.BidCompression,
+		MaxPayload:  int(cfg.MaxBidPayloadBytes),
+	}
+}
+
+// ReadKeystorePassword returns the password of the keystore file, read from
+// keystore_password_file when set. Trailing line breaks of the file are
+// ignored.
+func (cfg Config) ReadKeystorePassword() (string, error) {
+	if cfg.KeystorePasswordFile == "" {
+		return cfg.KeystorePassword, nil
+	}
+	data, err := os.ReadFile(cfg.KeystorePasswordFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read keystore password file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// IsSet reports whether the setting with the YAML key was set by the config
+// file, the environment or a flag rather than left at its default.
+func (cfg Config) IsSet(key string) bool {
+	return cfg.set[key]
+}
+
+// Key describes a setting: its name in each source and its default.
+type Key struct {
+	Name    string // YAML key.
+	Env     string
+	Flag    string
+	Secret  bool
+	Default string
+}
+
+// Keys returns every setting in declaration order.
+func Keys() []Key {
+	def := reflect.ValueOf(Default())
+	t := def.Type()
+	var keys []Key
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("yaml")
+		if name == "" {
+			continue
+		}
+		keys = append(keys, Key{
+			Name:    name,
+			Env:     field.Tag.Get("env"),
+			Flag:    field.Tag.Get("flag"),
+			Secret:  field.Tag.Get("secret") == "true",
+			Default: fmt.Sprint(def.Field(i).Interface()),
+		})
+	}
+	return keys
+}
+
+// setField parses s into a field of a supported kind.
+func setField(v reflect.Value, s string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported config field kind %s", v.Kind())
+	}
+	return nil
+}
+
+// Validate checks settings that do not depend on external state. The private
+// key and WebSocket endpoint may be empty, in which case they are prompted for.
+func (cfg Config) Validate() error {
+	var problems []string
+	if cfg.PrivateKey != "" && len(cfg.PrivateKey) != 64 {
+		problems = append(problems, "private_key must be 64 hex characters")
+	}
+	if err := labels.ValidateTenant(cfg.Tenant); err != nil {
+		problems = append(problems, "tenant: "+err.Error())
+	}
+	switch {
+	case cfg.KeystorePath == "" && cfg.ExtraKeystorePaths == "" && (cfg.KeystorePassword != "" || cfg.KeystorePasswordFile != ""):
+		problems = append(problems, "keystore_password and keystore_password_file require keystore_path or extra_keystore_paths")
+	case cfg.KeystorePath != "" && cfg.PrivateKey != "":
+		problems = append(problems, "keystore_path and private_key are exclusive")
+	case cfg.KeystorePath != "" && (cfg.KeystorePassword == "") == (cfg.KeystorePasswordFile == ""):
+		problems = append(problems, "keystore_path requires one of keystore_password or keystore_password_file")
+	}
+	if cfg.RemoteSignerURL != "" {
+		if cfg.PrivateKey != "" || cfg.KeystorePath != "" {
+			problems = append(problems, "remote_signer_url is exclusive with private_key and keystore_path")
+		}
+		if !common.IsHexAddress(cfg.RemoteSignerAddress) {
+			problems = append(problems, "remote_signer_url requires remote_signer_address, the account it signs for")
+		}
+		if _, err := ee.ParseRemoteSignerKind(cfg.RemoteSignerKind); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if cfg.TransferPrivateKey != "" {
+		switch {
+		case len(cfg.TransferPrivateKey) != 64:
+			problems = append(problems, "transfer_private_key must be 64 hex characters")
+		case cfg.NumBlob == 0:
+			problems = append(problems, "transfer_private_key requires num_blob, transfers already use private_key")
+		case strings.EqualFold(cfg.TransferPrivateKey, cfg.PrivateKey):
+			problems = append(problems, "transfer_private_key must differ from private_key, blob and transfer transactions cannot share an account")
+		}
+	}
+	if cfg.BundleSearchKey != "" {
+		if _, err := crypto.HexToECDSA(cfg.BundleSearchKey); err != nil {
+			problems = append(problems, "bundle_search_key must be a 64 hex character private key")
+		}
+	}
+	if cfg.BundleValidity < 0 {
+		problems = append(problems, "bundle_validity must not be negative")
+	}
+	if strings.IndexFunc(cfg.UserAgent, func(r rune) bool { return r < ' ' || r == 0x7f }) >= 0 {
+		problems = append(problems, "user_agent must not contain control characters")
+	}
+	if strings.IndexFunc(cfg.RequestIDPrefix, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.')
+	}) >= 0 {
+		problems = append(problems, "request_id_prefix may only contain letters, digits, '-', '_' and '.'")
+	}
+	if relays, err := ee.ParseRelays(cfg.BundleRelays); err != nil {
+		problems = append(problems, "bundle_relays: "+err.Error())
+	} else if _, err := ee.ParseDisabledRelays(cfg.DisabledRelays, relays); err != nil {
+		problems = append(problems, "disabled_relays: "+err.Error())
+	}
+	if method, err := ee.ParseSubmitMethod(cfg.SubmitMethod); err != nil {
+		problems = append(problems, "submit_method: "+err.Error())
+	} else if method == ee.SubmitPrivateTx && cfg.BackrunTx != "" {
+		problems = append(problems, "backrun_tx requires submit_method bundle")
+	}
+	if _, err := ee.ParsePrivateTxPreferences(cfg.PrivateTxPreferences); err != nil {
+		problems = append(problems, "private_tx_preferences: "+err.Error())
+	}
+	if cfg.ExtraKeystorePaths != "" && (cfg.KeystorePassword == "") == (cfg.KeystorePasswordFile == "") {
+		problems = append(problems, "extra_keystore_paths requires one of keystore_password or keystore_password_file")
+	}
+	keys := map[string]bool{}
+	for _, key := range []string{cfg.PrivateKey, cfg.TransferPrivateKey} {
+		if key != "" {
+			keys[strings.ToLower(key)] = true
+		}
+	}
+	for _, key := range cfg.ExtraPrivateKeyList() {
+		if len(key) != 64 {
+			problems = append(problems, "extra_private_keys must each be 64 hex characters")
+			break
+		}
+		if keys[strings.ToLower(key)] {
+			problems = append(problems, "extra_private_keys must differ from each other, private_key and transfer_private_key")
+			break
+		}
+		keys[strings.ToLower(key)] = true
+	}
+	if (cfg.ExtraPrivateKeys != "" || cfg.ExtraKeystorePaths != "") && cfg.TransactionType() == TxRaw {
+		problems = append(problems, "tx_type raw bids with pre-signed transactions, unset extra_private_keys and extra_keystore_paths")
+	}
+	if _, err := ee.ParseGasOracle(cfg.GasOracle); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if cfg.GasTipPercentile < 0 || cfg.GasTipPercentile > 100 {
+		problems = append(problems, "gas_tip_percentile must be between 0 and 100")
+	}
+	if cfg.MaxBaseFeeGwei < 0 {
+		problems = append(problems, "max_base_fee_gwei must not be negative")
+	}
+	if cfg.FeeBump < 1 || cfg.FeeBump > 2 {
+		problems = append(problems, "fee_bump must be between 1 and 2")
+	}
+	if _, err := ee.ParseBlobFeeSource(cfg.BlobFeeSource); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if cfg.BlobFeeBump < 1 || cfg.BlobFeeBump > 10 {
+		problems = append(problems, "blob_fee_bump must be between 1 and 10")
+	}
+	if cfg.MaxBlobFeeCapGwei < 0 || cfg.BlobFeeCeilingGwei < 0 {
+		problems = append(problems, "max_blob_fee_cap_gwei and blob_fee_ceiling_gwei must not be negative")
+	}
+	if cfg.NonceManager && cfg.NonceResync < bb.SlotDuration {
+		problems = append(problems, fmt.Sprintf("nonce_resync must be at least one slot (%s)", bb.SlotDuration))
+	}
+	if _, err := logging.ParseFormat(cfg.LogFormat); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := logging.ParseLevel(cfg.LogLevel); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if cfg.LogMaxAge < 0 {
+		problems = append(problems, "log_max_age must not be negative")
+	}
+	if cfg.LogSampleBurst > 0 && cfg.LogSampleInterval <= 0 {
+		problems = append(problems, "log_sample_interval must be positive with log_sample_burst")
+	}
+	if cfg.BidOnReconnect && cfg.BackfillBlocks == 0 {
+		problems = append(problems, "bid_on_reconnect requires backfill_blocks")
+	}
+	if cfg.WSStallSlots == 1 {
+		problems = append(problems, "ws_stall_slots must be 0 or at least 2, single slots are missed regularly")
+	}
+	if cfg.BidSlotOffset < 0 || cfg.BidSlotOffset >= slots.SlotDuration {
+		problems = append(problems, fmt.Sprintf("bid_slot_offset must be between 0 and one slot (%s)", slots.SlotDuration))
+	}
+	if cfg.GenesisTime > 0 && cfg.BidSlotOffset == 0 {
+		problems = append(problems, "genesis_time requires bid_slot_offset")
+	}
+	if cfg.OTLPEndpoint != "" {
+		if err := tracing.ValidateEndpoint(cfg.OTLPEndpoint); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if cfg.TraceSampleRatio < 0 || cfg.TraceSampleRatio > 1 {
+		problems = append(problems, "trace_sample_ratio must be between 0 and 1")
+	}
+	if cfg.StaleTxReplacements > ee.MaxStaleTxReplacements {
+		problems = append(problems, fmt.Sprintf("stale_tx_replacements must be at most %d", ee.MaxStaleTxReplacements))
+	}
+	if _, err := lanes.ParseRotation(cfg.AccountRotation); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if (cfg.BidderTLSCert == "") != (cfg.BidderTLSKey == "") {
+		problems = append(problems, "bidder_tls_cert and bidder_tls_key must be set together")
+	}
+	if cfg.BidderToken != "" && !cfg.BidderConfig().UsesTLS() {
+		problems = append(problems, "bidder_token requires TLS, set bidder_tls or bidder_tls_ca_cert")
+	}
+	if cfg.BidHistoryRetention != 0 && cfg.BidHistoryRetention < time.Hour {
+		problems = append(problems, "bid_history_retention must be 0 to keep every bid, or at least 1h")
+	}
+	if cfg.Telemetry && cfg.TelemetryEndpoint == "" {
+		problems = append(problems, "telemetry requires telemetry_endpoint")
+	}
+	if cfg.Telemetry && cfg.TelemetryInterval < time.Minute {
+		problems = append(problems, "telemetry_interval must be at least 1m")
+	}
+	if cfg.BidRetryBudget > 0 && cfg.BidRetryBackoff <= 0 {
+		problems = append(problems, "bid_retry_backoff must be positive when bid_retry_budget is set")
+	}
+	if backend, err := bb.ParseSubmissionBackend(cfg.SubmissionBackend); err != nil {
+		problems = append(problems, err.Error())
+	} else if backend == bb.BackendPreconfRPC && cfg.PreconfRPCEndpoint == "" {
+		problems = append(problems, "submission_backend preconf-rpc requires preconf_rpc_endpoint")
+	} else if backend == bb.BackendPreconfRPC && cfg.TargetBlockSpan > 1 {
+		problems = append(problems, "submission_backend preconf-rpc picks the target block itself, target_block_span must be 1")
+	} else if backend == bb.BackendPreconfRPC && cfg.PublicFallbackBlocks > 0 {
+		problems = append(problems, "public_fallback_blocks requires submission_backend bidder, commitments to preconf RPC submissions are not known")
+	} else if backend == bb.BackendPreconfRPC && cfg.InclusionTracking {
+		problems = append(problems, "inclusion_tracking requires submission_backend bidder, commitments to preconf RPC submissions are not known")
+	}
+	if cfg.InclusionLogFile != "" && !cfg.InclusionTracking {
+		problems = append(problems, "inclusion_log_file requires inclusion_tracking")
+	}
+	if cfg.PublicFallbackBlocks > 0 && cfg.PublicRPCEndpoint == "" && cfg.RPCEndpoint == "" {
+		problems = append(problems, "public_fallback_blocks requires public_rpc_endpoint or rpc_endpoint")
+	}
+	switch cfg.TransactionType() {
+	case TxTransfer:
+		if cfg.NumBlob > 0 {
+			problems = append(problems, "tx_type transfer does not attach blobs, unset num_blob")
+		}
+	case TxBlob:
+		if cfg.NumBlob == 0 {
+			problems = append(problems, "tx_type blob requires num_blob")
+		}
+	case TxERC20:
+		if cfg.NumBlob > 0 {
+			problems = append(problems, "tx_type erc20 does not attach blobs, unset num_blob")
+		}
+		if !common.IsHexAddress(cfg.ERC20Token) {
+			problems = append(problems, "tx_type erc20 requires erc20_token, the token contract address")
+		}
+		if !common.IsHexAddress(cfg.ERC20Recipient) {
+			problems = append(problems, "tx_type erc20 requires erc20_recipient, the recipient address")
+		}
+		if amount, ok := new(big.Int).SetString(cfg.ERC20Amount, 10); !ok || amount.Sign() < 0 {
+			problems = append(problems, "erc20_amount must be a non-negative integer in token base units")
+		}
+	case TxCall:
+		if cfg.NumBlob > 0 {
+			problems = append(problems, "tx_type contract-call does not attach blobs, unset num_blob")
+		}
+		if !common.IsHexAddress(cfg.ContractAddress) {
+			problems = append(problems, "tx_type contract-call requires contract_address")
+		}
+		switch {
+		case cfg.ContractCalldata != "" && (cfg.ContractABI != "" || cfg.ContractMethod != ""):
+			problems = append(problems, "contract_calldata and contract_abi/contract_method are exclusive")
+		case cfg.ContractCalldata == "" && (cfg.ContractABI == "" || cfg.ContractMethod == ""):
+			problems = append(problems, "tx_type contract-call requires contract_calldata, or contract_abi and contract_method")
+		}
+	case TxRaw:
+		if cfg.NumBlob > 0 {
+			problems = append(problems, "tx_type raw bids with the file's transactions as they are, unset num_blob")
+		}
+		if cfg.RawTxFile == "" {
+			problems = append(problems, "tx_type raw requires raw_tx_file")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("tx_type must be one of %s, %s, %s, %s or %s", TxTransfer, TxBlob, TxERC20, TxCall, TxRaw))
+	}
+	if cfg.TransferAmount != "" {
+		if amount, ok := new(big.Int).SetString(cfg.TransferAmount, 10); !ok || amount.Sign() < 0 {
+			problems = append(problems, "transfer_amount must be a non-negative integer in wei")
+		}
+	}
+	if cfg.TransferTo != "" && !common.IsHexAddress(cfg.TransferTo) {
+		problems = append(problems, "transfer_to must be an address")
+	}
+	if cfg.Offset == 0 {
+		problems = append(problems, "offset must be at least 1, the head block is already built")
+	}
+	if cfg.TargetBlockSpan < 1 || cfg.TargetBlockSpan > MaxTargetBlockSpan {
+		problems = append(problems, fmt.Sprintf("target_block_span must be between 1 and %d", MaxTargetBlockSpan))
+	}
+	if cfg.DecayMin < 0 || cfg.DecayMax < 0 {
+		problems = append(problems, "decay_min and decay_max must not be negative")
+	}
+	if cfg.DecayMax > 0 && cfg.DecayMax < cfg.DecayMin {
+		problems = append(problems, "decay_max must not be shorter than decay_min")
+	}
+	if cfg.BidAmount < 0 {
+		problems = append(problems, "bid_amount must not be negative")
+	}
+	if cfg.StdDevPercentage < 0 {
+		problems = append(problems, "bid_amount_std_dev_percentage must not be negative")
+	}
+	if cfg.HourlyBudget < 0 || cfg.DailyBudget < 0 {
+		problems = append(problems, "hourly_budget and daily_budget must not be negative")
+	}
+	if _, err := budget.ParseMode(cfg.BudgetMode); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if cfg.BlocksPerWindow == 0 {
+		problems = append(problems, "blocks_per_window must be at least 1")
+	}
+	if cfg.AutoWithdraw && cfg.AutoRollover {
+		problems = append(problems, "auto_withdraw and auto_rollover are exclusive, auto_rollover already withdraws the windows it funds")
+	}
+	if cfg.ClockSkewThreshold <= 0 {
+		problems = append(problems, "clock_skew_threshold must be positive")
+	}
+	if cfg.DrainTimeout < 0 {
+		problems = append(problems, "drain_timeout must not be negative")
+	}
+	if err := cfg.Pricing().Validate(); err != nil {
+		problems = append(problems, "adaptive pricing: "+err.Error())
+	}
+	switch weighting := providers.Weighting(cfg.ProviderWeighting); {
+	case !weighting.Valid():
+		problems = append(problems, fmt.Sprintf("provider_weighting must be one of %s", joinWeightings()))
+	case weighting != providers.None && cfg.Strategy != "adaptive":
+		problems = append(problems, "provider_weighting only applies to strategy adaptive")
+	case weighting != providers.None && cfg.MevCommitWSEndpoint == "":
+		problems = append(problems, "provider_weighting requires mev_commit_ws_endpoint, to observe commitments and read stakes")
+	}
+	if cfg.CanaryPercent < 0 || cfg.CanaryPercent > 100 {
+		problems = append(problems, "canary_percent must be between 0 and 100")
+	}
+	if cfg.CanaryBlocks < 1 {
+		problems = append(problems, "canary_blocks must be at least 1")
+	}
+	if cfg.StatusInterval < 0 {
+		problems = append(problems, "status_interval must not be negative")
+	}
+	if cfg.CampaignLead < 0 {
+		problems = append(problems, "campaign_lead must not be negative")
+	}
+	if _, err := jobs.ParseDisabled(cfg.DisableJobs); err != nil {
+		problems = append(problems, "disable_jobs: "+err.Error())
+	}
+	if err := auth.ValidateTokens(auth.ParseTokens(cfg.StatusReadTokens), auth.ParseTokens(cfg.StatusAdminTokens)); err != nil {
+		problems = append(problems, "status tokens: "+err.Error())
+	}
+	if _, ok := bb.Networks[cfg.Network]; !ok {
+		problems = append(problems, fmt.Sprintf("network must be one of %v", bb.NetworkNames()))
+	}
+	for _, a := range []struct{ key, value string }{
+		{"bidder_registry_address", cfg.BidderRegistryAddress},
+		{"block_tracker_address", cfg.BlockTrackerAddress},
+		{"preconf_manager_address", cfg.PreconfManagerAddress},
+	} {
+		if a.value != "" && !common.IsHexAddress(a.value) {
+			problems = append(problems, a.key+" is not an address")
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// DecayBounds returns the allowed decay window durations.
+func (cfg Config) DecayBounds() bb.DecayBounds {
+	return bb.DecayBounds{Min: cfg.DecayMin, Max: cfg.DecayMax, Clamp: cfg.DecayClamp}
+}
+
+// TransactionType returns tx_type, or transfer or blob depending on num_blob
+// when it is not set.
+func (cfg Config) TransactionType() string {
+	switch {
+	case cfg.TxType != "":
+		return strings.ToLower(cfg.TxType)
+	case cfg.NumBlob > 0:
+		return TxBlob
+	default:
+		return TxTransfer
+	}
+}
+
+// ContractCallArgs returns the arguments of contract_method.
+func (cfg Config) ContractCallArgs() []string {
+	if strings.TrimSpace(cfg.ContractArgs) == "" {
+		return nil
+	}
+	return strings.Split(cfg.ContractArgs, ",")
+}
+
+// ExtraPrivateKeyList returns the keys of extra_private_keys.
+func (cfg Config) ExtraPrivateKeyList() []string {
+	return splitList(cfg.ExtraPrivateKeys)
+}
+
+// ExtraKeystoreList returns the files of extra_keystore_paths.
+func (cfg Config) ExtraKeystoreList() []string {
+	return splitList(cfg.ExtraKeystorePaths)
+}
+
+// splitList splits a comma separated list, dropping blank entries.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// FeeOracle returns the gas oracle picking the fees of the bidder's
+// transactions.
+func (cfg Config) FeeOracle() *ee.GasPricer {
+	var tips ee.TipOracle = ee.FixedTip{Wei: ee.GweiToWei(float64(cfg.PriorityFeeGwei))}
+	if cfg.GasOracle == string(ee.FeeHistoryGasOracle) {
+		tips = ee.NewFeeHistoryTip(cfg.GasTipPercentile, ee.DefaultFeeHistoryBlocks, big.NewInt(1))
+	}
+	var maxBaseFee *big.Int
+	if cfg.MaxBaseFeeGwei > 0 {
+		maxBaseFee = ee.GweiToWei(cfg.MaxBaseFeeGwei)
+	}
+	return ee.NewGasPricer(tips, maxBaseFee, cfg.FeeBump)
+}
+
+// BlobFeeOracle returns the oracle picking the blob fee cap of blob
+// transactions.
+func (cfg Config) BlobFeeOracle() *ee.BlobPricer {
+	var maxCap, ceiling *big.Int
+	if cfg.MaxBlobFeeCapGwei > 0 {
+		maxCap = ee.GweiToWei(cfg.MaxBlobFeeCapGwei)
+	}
+	if cfg.BlobFeeCeilingGwei > 0 {
+		ceiling = ee.GweiToWei(cfg.BlobFeeCeilingGwei)
+	}
+	return ee.NewBlobPricer(ee.BlobFeeSource(cfg.BlobFeeSource), cfg.BlobFeeBump, maxCap, ceiling)
+}
+
+// SpendBudget returns the budget capping what bids commit.
+func (cfg Config) SpendBudget() *budget.Budget {
+	return budget.New(budget.Config{
+		Hourly:   cfg.HourlyBudget,
+		Daily:    cfg.DailyBudget,
+		PerBlock: int(cfg.MaxBidsPerBlock),
+		Mode:     budget.Mode(cfg.BudgetMode),
+	})
+}
+
+// joinWeightings lists the provider weightings for messages.
+func joinWeightings() string {
+	names := make([]string, len(providers.Weightings))
+	for i, w := range providers.Weightings {
+		names[i] = string(w)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Pricing returns the configuration of the adaptive pricing controller.
+func (cfg Config) Pricing() pricing.Config {
+	return pricing.Config{
+		TargetRate: cfg.AdaptiveTargetRate,
+		Step:       cfg.AdaptiveStep,
+		MinScale:   cfg.AdaptiveMinScale,
+		MaxScale:   cfg.AdaptiveMaxScale,
+	}
+}
+
+// ContractOverrides returns the contract addresses set explicitly; the others
+// are zero.
+func (cfg Config) ContractOverrides() bb.Contracts {
+	var c bb.Contracts
+	if cfg.BidderRegistryAddress != "" {
+		c.BidderRegistry = common.HexToAddress(cfg.BidderRegistryAddress)
+	}
+	if cfg.BlockTrackerAddress != "" {
+		c.BlockTracker = common.HexToAddress(cfg.BlockTrackerAddress)
+	}
+	if cfg.PreconfManagerAddress != "" {
+		c.PreconfManager = common.HexToAddress(cfg.PreconfManagerAddress)
+	}
+	return c
+}
+
+// ReadEnvFile returns the KEY=VALUE pairs of a .env file. Blank lines and
+// lines starting with # are skipped, values may be quoted and unquoted values
+// may be followed by a # comment.
+func ReadEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	vars := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		vars[strings.TrimSpace(key)] = envValue(value)
+	}
+	return vars, nil
+}
+
+// LookupEnv looks variables up in the process environment and then in the
+// variables of a .env file, so a variable that is set in the environment is
+// never overridden by the file.
+func LookupEnv(file map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		if value, ok := os.LookupEnv(key); ok {
+			return value, true
+		}
+		value, ok := file[key]
+		return value, ok
+	}
+}
+
+// envValue strips quotes or a trailing comment from a .env value.
+func envValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+		if end := strings.IndexByte(value[1:], value[0]); end >= 0 {
+			return value[1 : end+1]
+		}
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
@@ -0,0 +1,277 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// flagSet is a command line with the given flags set.
+type flagSet map[string]string
+
+func (f flagSet) IsSet(name string) bool {
+	_, ok := f[name]
+	return ok
+}
+
+func (f flagSet) String(name string) string {
+	return f[name]
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+bid_amount: 0.002
+offset: 2
+clock_skew_threshold: 3s
+strategy: script
+network: mainnet
+`)
+	cfg, err := Load(path,
+		env(map[string]string{"OFFSET": "3", "STRATEGY": "gaussian"}),
+		flagSet{"strategy": "script", "auto-rollover": "true"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, 0.002, cfg.BidAmount, "from the file")
+	require.Equal(t, 3*time.Second, cfg.ClockSkewThreshold, "from the file")
+	require.Equal(t, uint64(3), cfg.Offset, "the environment overrides the file")
+	require.Equal(t, "script", cfg.Strategy, "flags override the environment")
+	require.True(t, cfg.AutoRollover)
+	require.Equal(t, "localhost:13524", cfg.ServerAddress, "unset settings keep their default")
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	_, err := Load(writeFile(t, "config.yaml", "bid_ammount: 1\n"), nil, nil)
+	require.ErrorContains(t, err, "bid_ammount")
+
+	_, err = Load("", env(map[string]string{"OFFSET": "soon"}), nil)
+	require.ErrorContains(t, err, "OFFSET")
+
+	_, err = Load("", nil, flagSet{"offset": "0", "network": "moonnet"})
+	require.ErrorContains(t, err, "offset must be at least 1")
+	require.ErrorContains(t, err, "network must be one of")
+
+	key := strings.Repeat("ab", 32)
+	_, err = Load("", env(map[string]string{"PRIVATE_KEY": key, "TRANSFER_PRIVATE_KEY": key}), nil)
+	require.ErrorContains(t, err, "transfer_private_key requires num_blob")
+	_, err = Load("", env(map[string]string{"PRIVATE_KEY": key, "TRANSFER_PRIVATE_KEY": key, "NUM_BLOB": "2"}), nil)
+	require.ErrorContains(t, err, "transfer_private_key must differ from private_key")
+
+	_, err = Load("", env(map[string]string{"DECAY_MIN": "1m", "DECAY_MAX": "30s"}), nil)
+	require.ErrorContains(t, err, "decay_max must not be shorter than decay_min")
+
+	_, err = Load("", env(map[string]string{"SUBMISSION_BACKEND": "preconf-rpc"}), nil)
+	require.ErrorContains(t, err, "submission_backend preconf-rpc requires preconf_rpc_endpoint")
+
+	_, err = Load("", nil, flagSet{"tx-type": "erc20", "erc20-token": "0x1234", "erc20-amount": "1.5"})
+	require.ErrorContains(t, err, "tx_type erc20 requires erc20_token")
+	require.ErrorContains(t, err, "tx_type erc20 requires erc20_recipient")
+	require.ErrorContains(t, err, "erc20_amount must be a non-negative integer")
+
+	_, err = Load("", nil, flagSet{"transfer-amount": "0.1", "transfer-to": "alice"})
+	require.ErrorContains(t, err, "transfer_amount must be a non-negative integer in wei")
+	require.ErrorContains(t, err, "transfer_to must be an address")
+
+	_, err = Load("", nil, flagSet{"tx-type": "contract-call", "contract-address": "0x00000000000000000000000000000000000000aa", "contract-method": "ping"})
+	require.ErrorContains(t, err, "requires contract_calldata, or contract_abi and contract_method")
+
+	_, err = Load("", nil, flagSet{"tx-type": "raw", "num-blob": "1"})
+	require.ErrorContains(t, err, "tx_type raw requires raw_tx_file")
+	require.ErrorContains(t, err, "unset num_blob")
+
+	_, err = Load("", env(map[string]string{"BIDDER_TOKEN": "t0ken", "BIDDER_TLS_CERT": "client.pem"}), nil)
+	require.ErrorContains(t, err, "bidder_tls_cert and bidder_tls_key must be set together")
+	require.NotContains(t, err.Error(), "bidder_token requires TLS", "a client certificate implies TLS")
+	_, err = Load("", env(map[string]string{"BIDDER_TOKEN": "t0ken"}), nil)
+	require.ErrorContains(t, err, "bidder_token requires TLS")
+	_, err = Load("", env(map[string]string{"BID_HISTORY_RETENTION": "30m"}), nil)
+	require.ErrorContains(t, err, "bid_history_retention must be 0 to keep every bid, or at least 1h")
+	_, err = Load("", env(map[string]string{"KEYSTORE_PATH": "key.json", "PRIVATE_KEY": strings.Repeat("ab", 32)}), nil)
+	require.ErrorContains(t, err, "keystore_path and private_key are exclusive")
+	_, err = Load("", env(map[string]string{"KEYSTORE_PATH": "key.json"}), nil)
+	require.ErrorContains(t, err, "keystore_path requires one of keystore_password or keystore_password_file")
+	_, err = Load("", env(map[string]string{"KEYSTORE_PASSWORD_FILE": "pw.txt"}), nil)
+	require.ErrorContains(t, err, "require keystore_path")
+	_, err = Load("", env(map[string]string{"REMOTE_SIGNER_URL": "http://localhost:8550", "KEYSTORE_PATH": "key.json", "KEYSTORE_PASSWORD": "pw"}), nil)
+	require.ErrorContains(t, err, "remote_signer_url is exclusive with private_key and keystore_path")
+	require.ErrorContains(t, err, "remote_signer_url requires remote_signer_address")
+	_, err = Load("", env(map[string]string{"REMOTE_SIGNER_URL": "http://localhost:9000", "REMOTE_SIGNER_KIND": "ledger",
+		"REMOTE_SIGNER_ADDRESS": "0x00000000000000000000000000000000000000aa"}), nil)
+	require.ErrorContains(t, err, `unknown remote signer "ledger"`)
+	_, err = Load("", env(map[string]string{"PRIVATE_KEY": key, "EXTRA_PRIVATE_KEYS": strings.Repeat("cd", 32) + ", " + strings.ToUpper(key)}), nil)
+	require.ErrorContains(t, err, "extra_private_keys must differ from each other, private_key and transfer_private_key")
+	_, err = Load("", env(map[string]string{"EXTRA_KEYSTORE_PATHS": "a.json,b.json", "ACCOUNT_ROTATION": "random"}), nil)
+	require.ErrorContains(t, err, "extra_keystore_paths requires one of keystore_password or keystore_password_file")
+	require.ErrorContains(t, err, "account_rotation must be round-robin or parallel")
+	_, err = Load("", env(map[string]string{"EXTRA_KEYSTORE_PATHS": "a.json", "KEYSTORE_PASSWORD": "pw", "TX_TYPE": "raw", "RAW_TX_FILE": "txs"}), nil)
+	require.ErrorContains(t, err, "unset extra_private_keys and extra_keystore_paths")
+	require.NotContains(t, err.Error(), "require keystore_path", "the password decrypts the extra keystores")
+	_, err = Load("", env(map[string]string{"GAS_ORACLE": "eip-1559", "GAS_TIP_PERCENTILE": "101", "MAX_BASE_FEE_GWEI": "-1", "FEE_BUMP": "0.9"}), nil)
+	require.ErrorContains(t, err, `unknown gas oracle "eip-1559"`)
+	require.ErrorContains(t, err, "gas_tip_percentile must be between 0 and 100")
+	require.ErrorContains(t, err, "max_base_fee_gwei must not be negative")
+	require.ErrorContains(t, err, "fee_bump must be between 1 and 2")
+	_, err = Load("", env(map[string]string{"BLOB_FEE_SOURCE": "beacon", "BLOB_FEE_BUMP": "0.5", "BLOB_FEE_CEILING_GWEI": "-1"}), nil)
+	require.ErrorContains(t, err, `unknown blob fee source "beacon"`)
+	require.ErrorContains(t, err, "blob_fee_bump must be between 1 and 10")
+	require.ErrorContains(t, err, "max_blob_fee_cap_gwei and blob_fee_ceiling_gwei must not be negative")
+	_, err = Load("", env(map[string]string{"NONCE_MANAGER": "true", "NONCE_RESYNC": "1s"}), nil)
+	require.ErrorContains(t, err, "nonce_resync must be at least one slot (12s)")
+	_, err = Load("", env(map[string]string{"LOG_FORMAT": "logfmt", "LOG_LEVEL": "verbose", "LOG_MAX_AGE": "-1h"}), nil)
+	require.ErrorContains(t, err, `unknown log format "logfmt", must be json, text or pretty`)
+	require.ErrorContains(t, err, `unknown log level "verbose", must be debug, info, warn or error`)
+	require.ErrorContains(t, err, "log_max_age must not be negative")
+	_, err = Load("", env(map[string]string{"LOG_SAMPLE_INTERVAL": "0s"}), nil)
+	require.ErrorContains(t, err, "log_sample_interval must be positive with log_sample_burst")
+	_, err = Load("", env(map[string]string{"BID_ON_RECONNECT": "true", "BACKFILL_BLOCKS": "0"}), nil)
+	require.ErrorContains(t, err, "bid_on_reconnect requires backfill_blocks")
+	_, err = Load("", env(map[string]string{"WS_STALL_SLOTS": "1"}), nil)
+	require.ErrorContains(t, err, "ws_stall_slots must be 0 or at least 2, single slots are missed regularly")
+	_, err = Load("", env(map[string]string{"BID_SLOT_OFFSET": "12s", "GENESIS_TIME": "1695902400"}), nil)
+	require.ErrorContains(t, err, "bid_slot_offset must be between 0 and one slot (12s)")
+	_, err = Load("", env(map[string]string{"GENESIS_TIME": "1695902400"}), nil)
+	require.ErrorContains(t, err, "genesis_time requires bid_slot_offset")
+	_, err = Load("", env(map[string]string{"OTLP_ENDPOINT": "localhost:4318", "TRACE_SAMPLE_RATIO": "1.5"}), nil)
+	require.ErrorContains(t, err, `invalid OTLP endpoint "localhost:4318", must be an http or https URL`)
+	require.ErrorContains(t, err, "trace_sample_ratio must be between 0 and 1")
+	_, err = Load("", env(map[string]string{"STALE_TX_REPLACEMENTS": "11"}), nil)
+	require.ErrorContains(t, err, "stale_tx_replacements must be at most 10")
+	_, err = Load("", env(map[string]string{"TELEMETRY_INTERVAL": "10s"}), nil)
+	require.NoError(t, err, "telemetry settings only matter once it is enabled")
+	_, err = Load("", env(map[string]string{"TELEMETRY": "true", "TELEMETRY_INTERVAL": "10s"}), nil)
+	require.ErrorContains(t, err, "telemetry_interval must be at least 1m")
+	_, err = Load("", nil, flagSet{"bid-retry-backoff": "0s"})
+	require.ErrorContains(t, err, "bid_retry_backoff must be positive")
+	_, err = Load("", env(map[string]string{"MAX_BID_PAYLOAD_BYTES": "-1"}), nil)
+	require.ErrorContains(t, err, "MAX_BID_PAYLOAD_BYTES")
+	_, err = Load("", env(map[string]string{"DAILY_BUDGET": "-1", "BUDGET_MODE": "halt"}), nil)
+	require.ErrorContains(t, err, "hourly_budget and daily_budget must not be negative")
+	require.ErrorContains(t, err, `unknown budget mode "halt"`)
+	_, err = Load("", env(map[string]string{"CAMPAIGN_LEAD": "-1m"}), nil)
+	require.ErrorContains(t, err, "campaign_lead must not be negative")
+	_, err = Load("", env(map[string]string{"DISABLE_JOBS": "deposits, rollover"}), nil)
+	require.ErrorContains(t, err, `disable_jobs: unknown job "rollover"`)
+	_, err = Load("", env(map[string]string{"BUNDLE_SEARCH_KEY": "0x1234", "BUNDLE_VALIDITY": "-12s"}), nil)
+	require.ErrorContains(t, err, "bundle_search_key must be a 64 hex character private key")
+	require.ErrorContains(t, err, "bundle_validity must not be negative")
+	_, err = Load("", env(map[string]string{"BUNDLE_RELAYS": "flashbots"}), nil)
+	require.ErrorContains(t, err, `bundle_relays: relay "flashbots" is not a name=url pair`)
+	_, err = Load("", env(map[string]string{"BUNDLE_RELAYS": "titan=https://rpc.titanbuilder.xyz", "DISABLED_RELAYS": "rpc,bloxroute"}), nil)
+	require.ErrorContains(t, err, `disabled_relays: unknown relay "bloxroute"`)
+	_, err = Load("", env(map[string]string{"SUBMIT_METHOD": "raw", "PRIVATE_TX_PREFERENCES": "fast"}), nil)
+	require.ErrorContains(t, err, `submit_method: unknown submit method "raw"`)
+	require.ErrorContains(t, err, `private_tx_preferences: invalid private transaction preference "fast"`)
+	_, err = Load("", env(map[string]string{"SUBMIT_METHOD": "private-tx", "BACKRUN_TX": "0x" + strings.Repeat("ab", 32)}), nil)
+	require.ErrorContains(t, err, "backrun_tx requires submit_method bundle")
+	_, err = Load("", env(map[string]string{"USER_AGENT": "bidder\r\nX-Evil: 1", "REQUEST_ID_PREFIX": "eu 1"}), nil)
+	require.ErrorContains(t, err, "user_agent must not contain control characters")
+	require.ErrorContains(t, err, "request_id_prefix may only contain letters, digits, '-', '_' and '.'")
+	_, err = Load("", env(map[string]string{"PUBLIC_FALLBACK_BLOCKS": "3", "SUBMISSION_BACKEND": "preconf-rpc", "PRECONF_RPC_ENDPOINT": "https://rpc.example"}), nil)
+	require.ErrorContains(t, err, "public_fallback_blocks requires submission_backend bidder")
+	_, err = Load("", env(map[string]string{"INCLUSION_TRACKING": "true", "SUBMISSION_BACKEND": "preconf-rpc", "PRECONF_RPC_ENDPOINT": "https://rpc.example"}), nil)
+	require.ErrorContains(t, err, "inclusion_tracking requires submission_backend bidder")
+	_, err = Load("", env(map[string]string{"INCLUSION_LOG_FILE": "inclusions.jsonl"}), nil)
+	require.ErrorContains(t, err, "inclusion_log_file requires inclusion_tracking")
+
+	_, err = Load("", nil, flagSet{"target-block-span": "9"})
+	require.ErrorContains(t, err, "target_block_span must be between 1 and 8")
+
+	_, err = Load("", env(map[string]string{"STATUS_READ_TOKENS": "team"}), nil)
+	require.ErrorContains(t, err, "status tokens: read-only tokens must be at least")
+}
+
+func TestKeys(t *testing.T) {
+	keys := make(map[string]Key)
+	for _, key := range Keys() {
+		require.NotEmpty(t, key.Env, key.Name)
+		keys[key.Name] = key
+	}
+	require.Equal(t, Key{Name: "offset", Env: "OFFSET", Flag: "offset", Default: "1"}, keys["offset"])
+	require.True(t, keys["private_key"].Secret)
+	require.Empty(t, keys["bidder_registry_address"].Flag, "contract overrides are not flags")
+}
+
+func TestReadEnvFile(t *testing.T) {
+	path := writeFile(t, ".env", `
+# comment
+CONFIG_TEST_QUOTED="localhost:13524"
+CONFIG_TEST_COMMENTED=1                      # blocks ahead
+CONFIG_TEST_PLAIN=a=b
+`)
+	vars, err := ReadEnvFile(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"CONFIG_TEST_QUOTED":    "localhost:13524",
+		"CONFIG_TEST_COMMENTED": "1",
+		"CONFIG_TEST_PLAIN":     "a=b",
+	}, vars)
+	_, set := os.LookupEnv("CONFIG_TEST_QUOTED")
+	require.False(t, set, "the process environment is left alone")
+}
+
+func TestEnvOverridesEnvFile(t *testing.T) {
+	t.Setenv("OFFSET", "3")
+	cfg, err := Load("", LookupEnv(map[string]string{"OFFSET": "2", "BID_AMOUNT": "0.5"}), nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), cfg.Offset, "the environment wins over .env")
+	require.Equal(t, 0.5, cfg.BidAmount, ".env fills in what the environment does not set")
+}
+
+func TestReadKeystorePassword(t *testing.T) {
+	path := writeFile(t, "password.txt", "correct horse\n")
+	cfg, err := Load("", env(map[string]string{"KEYSTORE_PATH": "key.json", "KEYSTORE_PASSWORD_FILE": path}), nil)
+	require.NoError(t, err)
+	password, err := cfg.ReadKeystorePassword()
+	require.NoError(t, err)
+	require.Equal(t, "correct horse", password)
+
+	cfg.KeystorePasswordFile = ""
+	cfg.KeystorePassword = "battery staple"
+	password, err = cfg.ReadKeystorePassword()
+	require.NoError(t, err)
+	require.Equal(t, "battery staple", password)
+}
+
+func TestExampleConfigLoads(t *testing.T) {
+	_, err := Load(filepath.Join("..", "..", "config.example.yaml"), nil, nil)
+	require.NoError(t, err)
+}
+
+func TestCheckMainnet(t *testing.T) {
+	cfg, err := Load("", env(map[string]string{"PRIVATE_KEY": strings.Repeat("ab", 32), "NUM_BLOB": "2", "BID_AMOUNT": "0.002"}), nil)
+	require.NoError(t, err)
+	err = cfg.CheckMainnet()
+	require.ErrorContains(t, err, "confirm_mainnet must be set")
+	require.ErrorContains(t, err, "bid_amount_std_dev_percentage, priority_fee_gwei, deposit_amount must be set explicitly")
+	require.NotContains(t, err.Error(), "bid_amount,", "set in the environment")
+	require.ErrorContains(t, err, "set allow_mainnet_blobs to force them")
+	require.ErrorContains(t, err, "plaintext private key")
+
+	path := writeFile(t, "config.yaml", `
+bid_amount: 0.002
+bid_amount_std_dev_percentage: 10
+deposit_amount: 0.1
+`)
+	cfg, err = Load(path, env(map[string]string{"NUM_BLOB": "2", "ALLOW_MAINNET_BLOBS": "true", "KEYSTORE_PATH": "key.json", "KEYSTORE_PASSWORD": "pw"}),
+		flagSet{"priority-fee-gwei": "2", "confirm-mainnet": "true"})
+	require.NoError(t, err)
+	require.True(t, cfg.IsSet("deposit_amount"), "set in the file even though equal to the default")
+	require.False(t, cfg.IsSet("offset"))
+	require.NoError(t, cfg.CheckMainnet())
+}
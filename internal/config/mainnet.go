@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MainnetChainID is the chain ID of Ethereum mainnet.
+const MainnetChainID = 1
+
+// MainnetBudgets are the settings deciding what the bidder spends. Their
+// defaults suit testnets, so on mainnet each must be set explicitly.
+var MainnetBudgets = []string{"bid_amount", "bid_amount_std_dev_percentage", "priority_fee_gwei", "deposit_amount"}
+
+// CheckMainnet enforces the guardrails for bidding on Ethereum mainnet: the
+// run must be confirmed with confirm_mainnet, every budget setting must be set
+// explicitly, random blob transactions need allow_mainnet_blobs, and the
+// bidder must not be given a plaintext private key but a keystore file or a
+// remote signer.
+func (cfg Config) CheckMainnet() error {
+	var problems []string
+	if !cfg.ConfirmMainnet {
+		problems = append(problems, "confirm_mainnet must be set to bid on mainnet")
+	}
+	var unset []string
+	for _, key := range MainnetBudgets {
+		if !cfg.IsSet(key) {
+			unset = append(unset, key)
+		}
+	}
+	if len(unset) > 0 {
+		problems = append(problems, fmt.Sprintf("%s must be set explicitly on mainnet", strings.Join(unset, ", ")))
+	}
+	if cfg.TransactionType() == TxBlob && !cfg.AllowMainnetBlobs {
+		problems = append(problems, "random blob transactions are disabled on mainnet, set allow_mainnet_blobs to force them")
+	}
+	if cfg.PrivateKey != "" || cfg.TransferPrivateKey != "" || cfg.ExtraPrivateKeys != "" {
+		problems = append(problems, "a plaintext private key is refused on mainnet, keep the key in an encrypted keystore_path or a remote signer instead")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("mainnet guardrails: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
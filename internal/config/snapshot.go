@@ -0,0 +1,126 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Snapshot is the resolved configuration of a run. Settings are keyed by
+// their YAML name; secrets are replaced by a fingerprint so a changed key is
+// still detected without persisting it.
+type Snapshot struct {
+	Hash     string            `json:"hash"`
+	Time     time.Time         `json:"time"`
+	Settings map[string]string `json:"settings"`
+}
+
+// Change is a setting that differs between two snapshots. An empty value
+// means the setting did not exist in that snapshot.
+type Change struct {
+	Key      string
+	Previous string
+	Current  string
+}
+
+// Snapshot returns the snapshot of cfg.
+func (cfg Config) Snapshot() Snapshot {
+	settings := make(map[string]string)
+	v := reflect.ValueOf(cfg)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("yaml")
+		if key == "" {
+			continue
+		}
+		value := fmt.Sprint(v.Field(i).Interface())
+		if field.Tag.Get("secret") == "true" && value != "" {
+			value = "sha256:" + fingerprint(value)
+		}
+		settings[key] = value
+	}
+	return Snapshot{
+		Hash:     hashSettings(settings),
+		Time:     time.Now().UTC(),
+		Settings: settings,
+	}
+}
+
+// fingerprint returns a short hash of a secret.
+func fingerprint(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:4])
+}
+
+// hashSettings hashes settings in key order.
+func hashSettings(settings map[string]string) string {
+	h := sha256.New()
+	for _, key := range sortedKeys(settings) {
+		fmt.Fprintf(h, "%s=%s\n", key, settings[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Diff returns the settings that differ between prev and cur, sorted by key.
+func Diff(prev, cur Snapshot) []Change {
+	keys := make(map[string]string, len(cur.Settings))
+	for key := range prev.Settings {
+		keys[key] = ""
+	}
+	for key := range cur.Settings {
+		keys[key] = ""
+	}
+	var changes []Change
+	for _, key := range sortedKeys(keys) {
+		if prev.Settings[key] != cur.Settings[key] {
+			changes = append(changes, Change{Key: key, Previous: prev.Settings[key], Current: cur.Settings[key]})
+		}
+	}
+	return changes
+}
+
+// ReadSnapshot reads a snapshot written by WriteSnapshot. A missing file is
+// reported with an error satisfying errors.Is(err, fs.ErrNotExist).
+func ReadSnapshot(path string) (Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Snapshot{}, fmt.Errorf("invalid config snapshot %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// WriteSnapshot replaces the snapshot at path.
+func WriteSnapshot(path string, s Snapshot) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
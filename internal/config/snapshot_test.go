@@ -0,0 +1,44 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	prev := Default()
+	prev.PrivateKey = strings.Repeat("ab", 32)
+	cur := prev
+	cur.Offset = 2
+	cur.PrivateKey = strings.Repeat("cd", 32)
+
+	a, b := prev.Snapshot(), cur.Snapshot()
+	require.Equal(t, a.Hash, prev.Snapshot().Hash, "the hash is stable")
+	require.NotEqual(t, a.Hash, b.Hash)
+	require.NotContains(t, b.Settings["private_key"], "cd", "secrets are not kept")
+
+	changes := Diff(a, b)
+	require.Len(t, changes, 2)
+	require.Equal(t, Change{Key: "offset", Previous: "1", Current: "2"}, changes[0])
+	require.Equal(t, "private_key", changes[1].Key)
+
+	require.Empty(t, Diff(b, cur.Snapshot()))
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "last-config.json")
+	_, err := ReadSnapshot(path)
+	require.True(t, errors.Is(err, fs.ErrNotExist))
+
+	s := Default().Snapshot()
+	require.NoError(t, WriteSnapshot(path, s))
+	read, err := ReadSnapshot(path)
+	require.NoError(t, err)
+	require.Equal(t, s.Hash, read.Hash)
+	require.Empty(t, Diff(s, read))
+}
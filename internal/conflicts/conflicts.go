@@ -0,0 +1,155 @@
+// Package conflicts detects commitments for conflicting transactions of our
+// own: transactions from the same account with the same nonce, of which at
+// most one can be included. Bidding on a second transaction for a nonce that
+// already holds a commitment races our own commitment, so such bids are
+// skipped, and conflicts that happen anyway are recorded as incidents.
+package conflicts
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	errs "github.com/primev/preconf_blob_bidder/internal/errors"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+// Holder is the committed transaction holding an account nonce.
+type Holder struct {
+	TxHash      string
+	TargetBlock uint64
+}
+
+// Incident is a commitment received for a transaction conflicting with an
+// already committed one. The first committed transaction wins.
+type Incident struct {
+	Time        time.Time `json:"time"`
+	Account     string    `json:"account"`
+	Nonce       uint64    `json:"nonce"`
+	Winner      string    `json:"winner_tx_hash"`
+	WinnerBlock uint64    `json:"winner_target_block"`
+	Loser       string    `json:"loser_tx_hash"`
+	LoserBlock  uint64    `json:"loser_target_block"`
+}
+
+type key struct {
+	account common.Address
+	nonce   uint64
+}
+
+// Detector keeps the committed transaction of every account nonce until its
+// target block has passed.
+type Detector struct {
+	mu   sync.Mutex
+	held map[key]Holder
+	f    *os.File
+	enc  *json.Encoder
+	now  func() time.Time
+}
+
+// Open returns a detector appending incidents to path as JSON lines, or only
+// logging them when path is empty.
+func Open(path string) (*Detector, error) {
+	d := &Detector{held: make(map[key]Holder), now: time.Now}
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open conflict log: %w", err)
+		}
+		d.f, d.enc = f, json.NewEncoder(f)
+	}
+	return d, nil
+}
+
+// Conflicting returns the committed transaction holding the nonce of account,
+// if it is not txHash.
+func (d *Detector) Conflicting(account common.Address, nonce uint64, txHash string) (Holder, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	h, ok := d.held[key{account, nonce}]
+	if !ok || strings.EqualFold(h.TxHash, txHash) {
+		return Holder{}, false
+	}
+	return h, true
+}
+
+// Check returns an error of kind ErrNonceConflict naming the committed
+// transaction holding the nonce of account, if it is not txHash.
+func (d *Detector) Check(account common.Address, nonce uint64, txHash string) error {
+	h, ok := d.Conflicting(account, nonce, txHash)
+	if !ok {
+		return nil
+	}
+	return fmt.Errorf("%w: nonce %d committed for %s at block %d", errs.ErrNonceConflict, nonce, h.TxHash, h.TargetBlock)
+}
+
+// Committed records a commitment for txHash. When another transaction already
+// holds the nonce of account, the incident is recorded and returned; txHash is
+// the loser.
+func (d *Detector) Committed(account common.Address, nonce uint64, txHash string, targetBlock uint64) (Incident, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	k := key{account, nonce}
+	h, ok := d.held[k]
+	if !ok {
+		d.held[k] = Holder{TxHash: txHash, TargetBlock: targetBlock}
+		return Incident{}, false
+	}
+	if strings.EqualFold(h.TxHash, txHash) {
+		if targetBlock > h.TargetBlock {
+			h.TargetBlock = targetBlock
+			d.held[k] = h
+		}
+		return Incident{}, false
+	}
+
+	incident := Incident{
+		Time:        d.now().UTC(),
+		Account:     account.Hex(),
+		Nonce:       nonce,
+		Winner:      h.TxHash,
+		WinnerBlock: h.TargetBlock,
+		Loser:       txHash,
+		LoserBlock:  targetBlock,
+	}
+	metrics.CommitmentConflicts.Inc()
+	slog.Warn("Conflicting commitments for the same account nonce",
+		"account", incident.Account,
+		"nonce", nonce,
+		"winnerTxHash", incident.Winner,
+		"winnerTargetBlock", incident.WinnerBlock,
+		"loserTxHash", incident.Loser,
+		"loserTargetBlock", incident.LoserBlock,
+	)
+	if d.enc != nil {
+		if err := d.enc.Encode(incident); err != nil {
+			slog.Warn("Failed to write conflict log", "error", err)
+		}
+	}
+	return incident, true
+}
+
+// Prune forgets the holders whose target block is before head; their nonce
+// has either been used or is free again.
+func (d *Detector) Prune(head uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for k, h := range d.held {
+		if h.TargetBlock < head {
+			delete(d.held, k)
+		}
+	}
+}
+
+// Close closes the incident log.
+func (d *Detector) Close() error {
+	if d.f == nil {
+		return nil
+	}
+	return d.f.Close()
+}
@@ -0,0 +1,61 @@
+package conflicts
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	errs "github.com/primev/preconf_blob_bidder/internal/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectorRecordsConflicts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conflicts.jsonl")
+	d, err := Open(path)
+	require.NoError(t, err)
+	account := common.HexToAddress("0x01")
+	other := common.HexToAddress("0x02")
+
+	_, conflict := d.Committed(account, 7, "0xaa", 100)
+	require.False(t, conflict)
+	_, conflict = d.Committed(account, 7, "0xAA", 101)
+	require.False(t, conflict, "the same transaction bid for a span of blocks")
+	_, conflict = d.Committed(other, 7, "0xbb", 100)
+	require.False(t, conflict, "another account")
+
+	holder, ok := d.Conflicting(account, 7, "0xcc")
+	require.True(t, ok)
+	require.Equal(t, Holder{TxHash: "0xaa", TargetBlock: 101}, holder)
+	_, ok = d.Conflicting(account, 7, "0xaa")
+	require.False(t, ok)
+	_, ok = d.Conflicting(account, 8, "0xcc")
+	require.False(t, ok)
+	err = d.Check(account, 7, "0xcc")
+	require.ErrorIs(t, err, errs.ErrNonceConflict)
+	require.Contains(t, err.Error(), "nonce 7 committed for 0xaa at block 101")
+	require.NoError(t, d.Check(account, 7, "0xaa"))
+
+	incident, conflict := d.Committed(account, 7, "0xcc", 102)
+	require.True(t, conflict)
+	require.Equal(t, "0xaa", incident.Winner)
+	require.Equal(t, "0xcc", incident.Loser)
+	require.Equal(t, uint64(102), incident.LoserBlock)
+
+	d.Prune(102)
+	_, ok = d.Conflicting(account, 7, "0xcc")
+	require.False(t, ok, "the winner's target block has passed")
+	require.NoError(t, d.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	require.True(t, scanner.Scan())
+	var logged Incident
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &logged))
+	require.Equal(t, incident, logged)
+	require.False(t, scanner.Scan())
+}
@@ -0,0 +1,6 @@
+// Package preconfmanager is the Go binding of the events of the mev-commit
+// PreconfManager contract, generated from abi/PreconfManager.abi. Events are
+// decoded with their indexed topics, which unpacking the log data alone misses.
+package preconfmanager
+
+//go:generate abigen --abi ../../abi/PreconfManager.abi --pkg preconfmanager --type PreconfManager --out preconfmanager.go
@@ -0,0 +1,789 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package preconfmanager
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// PreconfManagerMetaData contains all meta data concerning the PreconfManager contract.
+var PreconfManagerMetaData = &bind.MetaData{
+	ABI: "[{\"type\":\"event\",\"name\":\"CommitmentProcessed\",\"inputs\":[{\"name\":\"commitmentIndex\",\"type\":\"bytes32\",\"indexed\":true,\"internalType\":\"bytes32\"},{\"name\":\"isSlash\",\"type\":\"bool\",\"indexed\":false,\"internalType\":\"bool\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"CommitmentStored\",\"inputs\":[{\"name\":\"commitmentIndex\",\"type\":\"bytes32\",\"indexed\":true,\"internalType\":\"bytes32\"},{\"name\":\"bidder\",\"type\":\"address\",\"indexed\":false,\"internalType\":\"address\"},{\"name\":\"commiter\",\"type\":\"address\",\"indexed\":false,\"internalType\":\"address\"},{\"name\":\"bid\",\"type\":\"uint64\",\"indexed\":false,\"internalType\":\"uint64\"},{\"name\":\"blockNumber\",\"type\":\"uint64\",\"indexed\":false,\"internalType\":\"uint64\"},{\"name\":\"bidHash\",\"type\":\"bytes32\",\"indexed\":false,\"internalType\":\"bytes32\"},{\"name\":\"decayStartTimeStamp\",\"type\":\"uint64\",\"indexed\":false,\"internalType\":\"uint64\"},{\"name\":\"decayEndTimeStamp\",\"type\":\"uint64\",\"indexed\":false,\"internalType\":\"uint64\"},{\"name\":\"txnHash\",\"type\":\"string\",\"indexed\":false,\"internalType\":\"string\"},{\"name\":\"commitmentHash\",\"type\":\"bytes32\",\"indexed\":false,\"internalType\":\"bytes32\"},{\"name\":\"bidSignature\",\"type\":\"bytes\",\"indexed\":false,\"internalType\":\"bytes\"},{\"name\":\"commitmentSignature\",\"type\":\"bytes\",\"indexed\":false,\"internalType\":\"bytes\"},{\"name\":\"dispatchTimestamp\",\"type\":\"uint64\",\"indexed\":false,\"internalType\":\"uint64\"},{\"name\":\"sharedSecretKey\",\"type\":\"bytes\",\"indexed\":false,\"internalType\":\"bytes\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"OpenedCommitmentStored\",\"inputs\":[{\"name\":\"commitmentIndex\",\"type\":\"bytes32\",\"indexed\":true,\"internalType\":\"bytes32\"},{\"name\":\"bidder\",\"type\":\"address\",\"indexed\":false,\"internalType\":\"address\"},{\"name\":\"committer\",\"type\":\"address\",\"indexed\":false,\"internalType\":\"address\"},{\"name\":\"bidAmt\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"},{\"name\":\"blockNumber\",\"type\":\"uint64\",\"indexed\":false,\"internalType\":\"uint64\"},{\"name\":\"bidHash\",\"type\":\"bytes32\",\"indexed\":false,\"internalType\":\"bytes32\"},{\"name\":\"decayStartTimeStamp\",\"type\":\"uint64\",\"indexed\":false,\"internalType\":\"uint64\"},{\"name\":\"decayEndTimeStamp\",\"type\":\"uint64\",\"indexed\":false,\"internalType\":\"uint64\"},{\"name\":\"txnHash\",\"type\":\"string\",\"indexed\":false,\"internalType\":\"string\"},{\"name\":\"revertingTxHashes\",\"type\":\"string\",\"indexed\":false,\"internalType\":\"string\"},{\"name\":\"commitmentDigest\",\"type\":\"bytes32\",\"indexed\":false,\"internalType\":\"bytes32\"},{\"name\":\"bidSignature\",\"type\":\"bytes\",\"indexed\":false,\"internalType\":\"bytes\"},{\"name\":\"commitmentSignature\",\"type\":\"bytes\",\"indexed\":false,\"internalType\":\"bytes\"},{\"name\":\"dispatchTimestamp\",\"type\":\"uint64\",\"indexed\":false,\"internalType\":\"uint64\"},{\"name\":\"sharedSecretKey\",\"type\":\"bytes\",\"indexed\":false,\"internalType\":\"bytes\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"UnopenedCommitmentStored\",\"inputs\":[{\"name\":\"commitmentIndex\",\"type\":\"bytes32\",\"indexed\":true,\"internalType\":\"bytes32\"},{\"name\":\"committer\",\"type\":\"address\",\"indexed\":false,\"internalType\":\"address\"},{\"name\":\"commitmentDigest\",\"type\":\"bytes32\",\"indexed\":false,\"internalType\":\"bytes32\"},{\"name\":\"commitmentSignature\",\"type\":\"bytes\",\"indexed\":false,\"internalType\":\"bytes\"},{\"name\":\"dispatchTimestamp\",\"type\":\"uint64\",\"indexed\":false,\"internalType\":\"uint64\"}],\"anonymous\":false}]",
+}
+
+// PreconfManagerABI is the input ABI used to generate the binding from.
+// Deprecated: Use PreconfManagerMetaData.ABI instead.
+var PreconfManagerABI = PreconfManagerMetaData.ABI
+
+// PreconfManager is an auto generated Go binding around an Ethereum contract.
+type PreconfManager struct {
+	PreconfManagerCaller     // Read-only binding to the contract
+	PreconfManagerTransactor // Write-only binding to the contract
+	PreconfManagerFilterer   // Log filterer for contract events
+}
+
+// PreconfManagerCaller is an auto generated read-only Go binding around an Ethereum contract.
+type PreconfManagerCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PreconfManagerTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type PreconfManagerTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PreconfManagerFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type PreconfManagerFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PreconfManagerSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type PreconfManagerSession struct {
+	Contract     *PreconfManager   // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// PreconfManagerCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type PreconfManagerCallerSession struct {
+	Contract *PreconfManagerCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts         // Call options to use throughout this session
+}
+
+// PreconfManagerTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type PreconfManagerTransactorSession struct {
+	Contract     *PreconfManagerTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts         // Transaction auth options to use throughout this session
+}
+
+// PreconfManagerRaw is an auto generated low-level Go binding around an Ethereum contract.
+type PreconfManagerRaw struct {
+	Contract *PreconfManager // Generic contract binding to access the raw methods on
+}
+
+// PreconfManagerCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type PreconfManagerCallerRaw struct {
+	Contract *PreconfManagerCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// PreconfManagerTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type PreconfManagerTransactorRaw struct {
+	Contract *PreconfManagerTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewPreconfManager creates a new instance of PreconfManager, bound to a specific deployed contract.
+func NewPreconfManager(address common.Address, backend bind.ContractBackend) (*PreconfManager, error) {
+	contract, err := bindPreconfManager(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &PreconfManager{PreconfManagerCaller: PreconfManagerCaller{contract: contract}, PreconfManagerTransactor: PreconfManagerTransactor{contract: contract}, PreconfManagerFilterer: PreconfManagerFilterer{contract: contract}}, nil
+}
+
+// NewPreconfManagerCaller creates a new read-only instance of PreconfManager, bound to a specific deployed contract.
+func NewPreconfManagerCaller(address common.Address, caller bind.ContractCaller) (*PreconfManagerCaller, error) {
+	contract, err := bindPreconfManager(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PreconfManagerCaller{contract: contract}, nil
+}
+
+// NewPreconfManagerTransactor creates a new write-only instance of PreconfManager, bound to a specific deployed contract.
+func NewPreconfManagerTransactor(address common.Address, transactor bind.ContractTransactor) (*PreconfManagerTransactor, error) {
+	contract, err := bindPreconfManager(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PreconfManagerTransactor{contract: contract}, nil
+}
+
+// NewPreconfManagerFilterer creates a new log filterer instance of PreconfManager, bound to a specific deployed contract.
+func NewPreconfManagerFilterer(address common.Address, filterer bind.ContractFilterer) (*PreconfManagerFilterer, error) {
+	contract, err := bindPreconfManager(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &PreconfManagerFilterer{contract: contract}, nil
+}
+
+// bindPreconfManager binds a generic wrapper to an already deployed contract.
+func bindPreconfManager(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := PreconfManagerMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_PreconfManager *PreconfManagerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _PreconfManager.Contract.PreconfManagerCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_PreconfManager *PreconfManagerRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _PreconfManager.Contract.PreconfManagerTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_PreconfManager *PreconfManagerRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _PreconfManager.Contract.PreconfManagerTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_PreconfManager *PreconfManagerCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _PreconfManager.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_PreconfManager *PreconfManagerTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _PreconfManager.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_PreconfManager *PreconfManagerTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _PreconfManager.Contract.contract.Transact(opts, method, params...)
+}
+
+// PreconfManagerCommitmentProcessedIterator is returned from FilterCommitmentProcessed and is used to iterate over the raw logs and unpacked data for CommitmentProcessed events raised by the PreconfManager contract.
+type PreconfManagerCommitmentProcessedIterator struct {
+	Event *PreconfManagerCommitmentProcessed // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *PreconfManagerCommitmentProcessedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(PreconfManagerCommitmentProcessed)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(PreconfManagerCommitmentProcessed)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *PreconfManagerCommitmentProcessedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *PreconfManagerCommitmentProcessedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// PreconfManagerCommitmentProcessed represents a CommitmentProcessed event raised by the PreconfManager contract.
+type PreconfManagerCommitmentProcessed struct {
+	CommitmentIndex [32]byte
+	IsSlash         bool
+	Raw             types.Log // Blockchain specific contextual infos
+}
+
+// FilterCommitmentProcessed is a free log retrieval operation binding the contract event 0xddc1768a3a762a04e5fd3abea8ae3b60e23bcf290f4a032280e6a726611d41f5.
+//
+// Solidity: event CommitmentProcessed(bytes32 indexed commitmentIndex, bool isSlash)
+func (_PreconfManager *PreconfManagerFilterer) FilterCommitmentProcessed(opts *bind.FilterOpts, commitmentIndex [][32]byte) (*PreconfManagerCommitmentProcessedIterator, error) {
+
+	var commitmentIndexRule []interface{}
+	for _, commitmentIndexItem := range commitmentIndex {
+		commitmentIndexRule = append(commitmentIndexRule, commitmentIndexItem)
+	}
+
+	logs, sub, err := _PreconfManager.contract.FilterLogs(opts, "CommitmentProcessed", commitmentIndexRule)
+	if err != nil {
+		return nil, err
+	}
+	return &PreconfManagerCommitmentProcessedIterator{contract: _PreconfManager.contract, event: "CommitmentProcessed", logs: logs, sub: sub}, nil
+}
+
+// WatchCommitmentProcessed is a free log subscription operation binding the contract event 0xddc1768a3a762a04e5fd3abea8ae3b60e23bcf290f4a032280e6a726611d41f5.
+//
+// Solidity: event CommitmentProcessed(bytes32 indexed commitmentIndex, bool isSlash)
+func (_PreconfManager *PreconfManagerFilterer) WatchCommitmentProcessed(opts *bind.WatchOpts, sink chan<- *PreconfManagerCommitmentProcessed, commitmentIndex [][32]byte) (event.Subscription, error) {
+
+	var commitmentIndexRule []interface{}
+	for _, commitmentIndexItem := range commitmentIndex {
+		commitmentIndexRule = append(commitmentIndexRule, commitmentIndexItem)
+	}
+
+	logs, sub, err := _PreconfManager.contract.WatchLogs(opts, "CommitmentProcessed", commitmentIndexRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(PreconfManagerCommitmentProcessed)
+				if err := _PreconfManager.contract.UnpackLog(event, "CommitmentProcessed", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseCommitmentProcessed is a log parse operation binding the contract event 0xddc1768a3a762a04e5fd3abea8ae3b60e23bcf290f4a032280e6a726611d41f5.
+//
+// Solidity: event CommitmentProcessed(bytes32 indexed commitmentIndex, bool isSlash)
+func (_PreconfManager *PreconfManagerFilterer) ParseCommitmentProcessed(log types.Log) (*PreconfManagerCommitmentProcessed, error) {
+	event := new(PreconfManagerCommitmentProcessed)
+	if err := _PreconfManager.contract.UnpackLog(event, "CommitmentProcessed", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// PreconfManagerCommitmentStoredIterator is returned from FilterCommitmentStored and is used to iterate over the raw logs and unpacked data for CommitmentStored events raised by the PreconfManager contract.
+type PreconfManagerCommitmentStoredIterator struct {
+	Event *PreconfManagerCommitmentStored // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *PreconfManagerCommitmentStoredIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(PreconfManagerCommitmentStored)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(PreconfManagerCommitmentStored)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *PreconfManagerCommitmentStoredIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *PreconfManagerCommitmentStoredIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// PreconfManagerCommitmentStored represents a CommitmentStored event raised by the PreconfManager contract.
+type PreconfManagerCommitmentStored struct {
+	CommitmentIndex     [32]byte
+	Bidder              common.Address
+	Commiter            common.Address
+	Bid                 uint64
+	BlockNumber         uint64
+	BidHash             [32]byte
+	DecayStartTimeStamp uint64
+	DecayEndTimeStamp   uint64
+	TxnHash             string
+	CommitmentHash      [32]byte
+	BidSignature        []byte
+	CommitmentSignature []byte
+	DispatchTimestamp   uint64
+	SharedSecretKey     []byte
+	Raw                 types.Log // Blockchain specific contextual infos
+}
+
+// FilterCommitmentStored is a free log retrieval operation binding the contract event 0xa4aab50afc443b845214b8f4e2e7c32ea42be39a84e532be779802c54ff8ffda.
+//
+// Solidity: event CommitmentStored(bytes32 indexed commitmentIndex, address bidder, address commiter, uint64 bid, uint64 blockNumber, bytes32 bidHash, uint64 decayStartTimeStamp, uint64 decayEndTimeStamp, string txnHash, bytes32 commitmentHash, bytes bidSignature, bytes commitmentSignature, uint64 dispatchTimestamp, bytes sharedSecretKey)
+func (_PreconfManager *PreconfManagerFilterer) FilterCommitmentStored(opts *bind.FilterOpts, commitmentIndex [][32]byte) (*PreconfManagerCommitmentStoredIterator, error) {
+
+	var commitmentIndexRule []interface{}
+	for _, commitmentIndexItem := range commitmentIndex {
+		commitmentIndexRule = append(commitmentIndexRule, commitmentIndexItem)
+	}
+
+	logs, sub, err := _PreconfManager.contract.FilterLogs(opts, "CommitmentStored", commitmentIndexRule)
+	if err != nil {
+		return nil, err
+	}
+	return &PreconfManagerCommitmentStoredIterator{contract: _PreconfManager.contract, event: "CommitmentStored", logs: logs, sub: sub}, nil
+}
+
+// WatchCommitmentStored is a free log subscription operation binding the contract event 0xa4aab50afc443b845214b8f4e2e7c32ea42be39a84e532be779802c54ff8ffda.
+//
+// Solidity: event CommitmentStored(bytes32 indexed commitmentIndex, address bidder, address commiter, uint64 bid, uint64 blockNumber, bytes32 bidHash, uint64 decayStartTimeStamp, uint64 decayEndTimeStamp, string txnHash, bytes32 commitmentHash, bytes bidSignature, bytes commitmentSignature, uint64 dispatchTimestamp, bytes sharedSecretKey)
+func (_PreconfManager *PreconfManagerFilterer) WatchCommitmentStored(opts *bind.WatchOpts, sink chan<- *PreconfManagerCommitmentStored, commitmentIndex [][32]byte) (event.Subscription, error) {
+
+	var commitmentIndexRule []interface{}
+	for _, commitmentIndexItem := range commitmentIndex {
+		commitmentIndexRule = append(commitmentIndexRule, commitmentIndexItem)
+	}
+
+	logs, sub, err := _PreconfManager.contract.WatchLogs(opts, "CommitmentStored", commitmentIndexRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(PreconfManagerCommitmentStored)
+				if err := _PreconfManager.contract.UnpackLog(event, "CommitmentStored", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseCommitmentStored is a log parse operation binding the contract event 0xa4aab50afc443b845214b8f4e2e7c32ea42be39a84e532be779802c54ff8ffda.
+//
+// Solidity: event CommitmentStored(bytes32 indexed commitmentIndex, address bidder, address commiter, uint64 bid, uint64 blockNumber, bytes32 bidHash, uint64 decayStartTimeStamp, uint64 decayEndTimeStamp, string txnHash, bytes32 commitmentHash, bytes bidSignature, bytes commitmentSignature, uint64 dispatchTimestamp, bytes sharedSecretKey)
+func (_PreconfManager *PreconfManagerFilterer) ParseCommitmentStored(log types.Log) (*PreconfManagerCommitmentStored, error) {
+	event := new(PreconfManagerCommitmentStored)
+	if err := _PreconfManager.contract.UnpackLog(event, "CommitmentStored", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// PreconfManagerOpenedCommitmentStoredIterator is returned from FilterOpenedCommitmentStored and is used to iterate over the raw logs and unpacked data for OpenedCommitmentStored events raised by the PreconfManager contract.
+type PreconfManagerOpenedCommitmentStoredIterator struct {
+	Event *PreconfManagerOpenedCommitmentStored // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *PreconfManagerOpenedCommitmentStoredIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(PreconfManagerOpenedCommitmentStored)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(PreconfManagerOpenedCommitmentStored)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *PreconfManagerOpenedCommitmentStoredIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *PreconfManagerOpenedCommitmentStoredIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// PreconfManagerOpenedCommitmentStored represents a OpenedCommitmentStored event raised by the PreconfManager contract.
+type PreconfManagerOpenedCommitmentStored struct {
+	CommitmentIndex     [32]byte
+	Bidder              common.Address
+	Committer           common.Address
+	BidAmt              *big.Int
+	BlockNumber         uint64
+	BidHash             [32]byte
+	DecayStartTimeStamp uint64
+	DecayEndTimeStamp   uint64
+	TxnHash             string
+	RevertingTxHashes   string
+	CommitmentDigest    [32]byte
+	BidSignature        []byte
+	CommitmentSignature []byte
+	DispatchTimestamp   uint64
+	SharedSecretKey     []byte
+	Raw                 types.Log // Blockchain specific contextual infos
+}
+
+// FilterOpenedCommitmentStored is a free log retrieval operation binding the contract event 0x917c84f50cb270289bbf7b88e8c9af08c45e8f4c34105bc9f564bc5e199ad03a.
+//
+// Solidity: event OpenedCommitmentStored(bytes32 indexed commitmentIndex, address bidder, address committer, uint256 bidAmt, uint64 blockNumber, bytes32 bidHash, uint64 decayStartTimeStamp, uint64 decayEndTimeStamp, string txnHash, string revertingTxHashes, bytes32 commitmentDigest, bytes bidSignature, bytes commitmentSignature, uint64 dispatchTimestamp, bytes sharedSecretKey)
+func (_PreconfManager *PreconfManagerFilterer) FilterOpenedCommitmentStored(opts *bind.FilterOpts, commitmentIndex [][32]byte) (*PreconfManagerOpenedCommitmentStoredIterator, error) {
+
+	var commitmentIndexRule []interface{}
+	for _, commitmentIndexItem := range commitmentIndex {
+		commitmentIndexRule = append(commitmentIndexRule, commitmentIndexItem)
+	}
+
+	logs, sub, err := _PreconfManager.contract.FilterLogs(opts, "OpenedCommitmentStored", commitmentIndexRule)
+	if err != nil {
+		return nil, err
+	}
+	return &PreconfManagerOpenedCommitmentStoredIterator{contract: _PreconfManager.contract, event: "OpenedCommitmentStored", logs: logs, sub: sub}, nil
+}
+
+// WatchOpenedCommitmentStored is a free log subscription operation binding the contract event 0x917c84f50cb270289bbf7b88e8c9af08c45e8f4c34105bc9f564bc5e199ad03a.
+//
+// Solidity: event OpenedCommitmentStored(bytes32 indexed commitmentIndex, address bidder, address committer, uint256 bidAmt, uint64 blockNumber, bytes32 bidHash, uint64 decayStartTimeStamp, uint64 decayEndTimeStamp, string txnHash, string revertingTxHashes, bytes32 commitmentDigest, bytes bidSignature, bytes commitmentSignature, uint64 dispatchTimestamp, bytes sharedSecretKey)
+func (_PreconfManager *PreconfManagerFilterer) WatchOpenedCommitmentStored(opts *bind.WatchOpts, sink chan<- *PreconfManagerOpenedCommitmentStored, commitmentIndex [][32]byte) (event.Subscription, error) {
+
+	var commitmentIndexRule []interface{}
+	for _, commitmentIndexItem := range commitmentIndex {
+		commitmentIndexRule = append(commitmentIndexRule, commitmentIndexItem)
+	}
+
+	logs, sub, err := _PreconfManager.contract.WatchLogs(opts, "OpenedCommitmentStored", commitmentIndexRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(PreconfManagerOpenedCommitmentStored)
+				if err := _PreconfManager.contract.UnpackLog(event, "OpenedCommitmentStored", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOpenedCommitmentStored is a log parse operation binding the contract event 0x917c84f50cb270289bbf7b88e8c9af08c45e8f4c34105bc9f564bc5e199ad03a.
+//
+// Solidity: event OpenedCommitmentStored(bytes32 indexed commitmentIndex, address bidder, address committer, uint256 bidAmt, uint64 blockNumber, bytes32 bidHash, uint64 decayStartTimeStamp, uint64 decayEndTimeStamp, string txnHash, string revertingTxHashes, bytes32 commitmentDigest, bytes bidSignature, bytes commitmentSignature, uint64 dispatchTimestamp, bytes sharedSecretKey)
+func (_PreconfManager *PreconfManagerFilterer) ParseOpenedCommitmentStored(log types.Log) (*PreconfManagerOpenedCommitmentStored, error) {
+	event := new(PreconfManagerOpenedCommitmentStored)
+	if err := _PreconfManager.contract.UnpackLog(event, "OpenedCommitmentStored", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// PreconfManagerUnopenedCommitmentStoredIterator is returned from FilterUnopenedCommitmentStored and is used to iterate over the raw logs and unpacked data for UnopenedCommitmentStored events raised by the PreconfManager contract.
+type PreconfManagerUnopenedCommitmentStoredIterator struct {
+	Event *PreconfManagerUnopenedCommitmentStored // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *PreconfManagerUnopenedCommitmentStoredIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(PreconfManagerUnopenedCommitmentStored)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(PreconfManagerUnopenedCommitmentStored)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *PreconfManagerUnopenedCommitmentStoredIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *PreconfManagerUnopenedCommitmentStoredIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// PreconfManagerUnopenedCommitmentStored represents a UnopenedCommitmentStored event raised by the PreconfManager contract.
+type PreconfManagerUnopenedCommitmentStored struct {
+	CommitmentIndex     [32]byte
+	Committer           common.Address
+	CommitmentDigest    [32]byte
+	CommitmentSignature []byte
+	DispatchTimestamp   uint64
+	Raw                 types.Log // Blockchain specific contextual infos
+}
+
+// FilterUnopenedCommitmentStored is a free log retrieval operation binding the contract event 0xbe650dcd1894b46cca996156910a6f10e521dfcb65da07e885f41ae7d2db3c78.
+//
+// Solidity: event UnopenedCommitmentStored(bytes32 indexed commitmentIndex, address committer, bytes32 commitmentDigest, bytes commitmentSignature, uint64 dispatchTimestamp)
+func (_PreconfManager *PreconfManagerFilterer) FilterUnopenedCommitmentStored(opts *bind.FilterOpts, commitmentIndex [][32]byte) (*PreconfManagerUnopenedCommitmentStoredIterator, error) {
+
+	var commitmentIndexRule []interface{}
+	for _, commitmentIndexItem := range commitmentIndex {
+		commitmentIndexRule = append(commitmentIndexRule, commitmentIndexItem)
+	}
+
+	logs, sub, err := _PreconfManager.contract.FilterLogs(opts, "UnopenedCommitmentStored", commitmentIndexRule)
+	if err != nil {
+		return nil, err
+	}
+	return &PreconfManagerUnopenedCommitmentStoredIterator{contract: _PreconfManager.contract, event: "UnopenedCommitmentStored", logs: logs, sub: sub}, nil
+}
+
+// WatchUnopenedCommitmentStored is a free log subscription operation binding the contract event 0xbe650dcd1894b46cca996156910a6f10e521dfcb65da07e885f41ae7d2db3c78.
+//
+// Solidity: event UnopenedCommitmentStored(bytes32 indexed commitmentIndex, address committer, bytes32 commitmentDigest, bytes commitmentSignature, uint64 dispatchTimestamp)
+func (_PreconfManager *PreconfManagerFilterer) WatchUnopenedCommitmentStored(opts *bind.WatchOpts, sink chan<- *PreconfManagerUnopenedCommitmentStored, commitmentIndex [][32]byte) (event.Subscription, error) {
+
+	var commitmentIndexRule []interface{}
+	for _, commitmentIndexItem := range commitmentIndex {
+		commitmentIndexRule = append(commitmentIndexRule, commitmentIndexItem)
+	}
+
+	logs, sub, err := _PreconfManager.contract.WatchLogs(opts, "UnopenedCommitmentStored", commitmentIndexRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(PreconfManagerUnopenedCommitmentStored)
+				if err := _PreconfManager.contract.UnpackLog(event, "UnopenedCommitmentStored", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseUnopenedCommitmentStored is a log parse operation binding the contract event 0xbe650dcd1894b46cca996156910a6f10e521dfcb65da07e885f41ae7d2db3c78.
+//
+// Solidity: event UnopenedCommitmentStored(bytes32 indexed commitmentIndex, address committer, bytes32 commitmentDigest, bytes commitmentSignature, uint64 dispatchTimestamp)
+func (_PreconfManager *PreconfManagerFilterer) ParseUnopenedCommitmentStored(log types.Log) (*PreconfManagerUnopenedCommitmentStored, error) {
+	event := new(PreconfManagerUnopenedCommitmentStored)
+	if err := _PreconfManager.contract.UnpackLog(event, "UnopenedCommitmentStored", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
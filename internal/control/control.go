@@ -0,0 +1,260 @@
+// Package control adjusts bidding while the bidder runs, through admin
+// endpoints of the status server or configuration reloads: the bid amount,
+// its standard deviation and the offset can be changed, and bidding paused
+// and resumed, without a restart. Every change is logged with who made it
+// and, with an audit file, appended to it.
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/auth"
+	"github.com/primev/preconf_blob_bidder/internal/strategy"
+)
+
+// Actions recorded in the audit trail.
+const (
+	ActionParams = "params"
+	ActionPause  = "pause"
+	ActionResume = "resume"
+)
+
+// Update changes the bidding parameters that are set.
+type Update struct {
+	BidAmount        *float64 `json:"bid_amount,omitempty"`
+	StdDevPercentage *float64 `json:"std_dev_percentage,omitempty"`
+	Offset           *uint64  `json:"offset,omitempty"`
+}
+
+// Pause is why and by whom bidding was paused.
+type Pause struct {
+	By     string    `json:"by"`
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// State is the JSON served on GET /control.
+type State struct {
+	Params strategy.Params `json:"params"` // Last parameters set; with a canary they may not be in effect yet.
+	Paused *Pause          `json:"paused,omitempty"`
+}
+
+// Change is an entry of the audit trail.
+type Change struct {
+	Time   time.Time        `json:"time"`
+	Action string           `json:"action"`
+	By     string           `json:"by"` // See auth.Identity.
+	Remote string           `json:"remote,omitempty"`
+	Before *strategy.Params `json:"before,omitempty"`
+	After  *strategy.Params `json:"after,omitempty"`
+	Reason string           `json:"reason,omitempty"`
+}
+
+// Config holds how changes take effect.
+type Config struct {
+	// Propose puts new bid amount and deviation parameters in effect, or
+	// returns why they cannot be.
+	Propose func(strategy.Params) error
+	// CheckOffset returns why an offset cannot be bid with, if it cannot.
+	CheckOffset func(uint64) error
+	// AuditFile is a JSON lines file every change is appended to; empty only
+	// logs changes.
+	AuditFile string
+}
+
+// Controller holds the parameters set at runtime and whether bidding is
+// paused.
+type Controller struct {
+	cfg Config
+	now func() time.Time
+
+	mu     sync.Mutex
+	params strategy.Params
+	pause  *Pause
+	audit  *os.File
+}
+
+// New returns a controller starting from params. The audit file, if any, is
+// opened for appending.
+func New(params strategy.Params, cfg Config) (*Controller, error) {
+	c := &Controller{cfg: cfg, now: time.Now, params: params}
+	if cfg.AuditFile != "" {
+		f, err := os.OpenFile(cfg.AuditFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open control audit file: %w", err)
+		}
+		c.audit = f
+	}
+	return c, nil
+}
+
+// Close closes the audit file.
+func (c *Controller) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.audit == nil {
+		return nil
+	}
+	return c.audit.Close()
+}
+
+// Offset returns how many blocks ahead of the head to bid for.
+func (c *Controller) Offset() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.params.Offset
+}
+
+// Paused reports whether bidding is paused.
+func (c *Controller) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pause != nil
+}
+
+// State returns the parameters and the pause.
+func (c *Controller) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := State{Params: c.params}
+	if c.pause != nil {
+		p := *c.pause
+		s.Paused = &p
+	}
+	return s
+}
+
+// Apply changes the parameters set in u on behalf of by.
+func (c *Controller) Apply(u Update, by, remote string) (strategy.Params, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	before, next := c.params, c.params
+	if u.BidAmount != nil {
+		next.BidAmount = *u.BidAmount
+	}
+	if u.StdDevPercentage != nil {
+		next.StdDevPercentage = *u.StdDevPercentage
+	}
+	if u.Offset != nil {
+		next.Offset = *u.Offset
+	}
+	switch {
+	case next.BidAmount < 0:
+		return before, errors.New("bid_amount must not be negative")
+	case next.StdDevPercentage < 0:
+		return before, errors.New("std_dev_percentage must not be negative")
+	case next.Offset < 1:
+		return before, errors.New("offset must be at least 1")
+	case next == before:
+		return before, nil
+	}
+	if next.Offset != before.Offset && c.cfg.CheckOffset != nil {
+		if err := c.cfg.CheckOffset(next.Offset); err != nil {
+			return before, fmt.Errorf("offset %d: %w", next.Offset, err)
+		}
+	}
+	if (next.BidAmount != before.BidAmount || next.StdDevPercentage != before.StdDevPercentage) && c.cfg.Propose != nil {
+		// The offset is not part of a canary; it takes effect at once
+		proposed := next
+		proposed.Offset = before.Offset
+		if err := c.cfg.Propose(proposed); err != nil {
+			return before, err
+		}
+	}
+	c.params = next
+	c.record(Change{Action: ActionParams, By: by, Remote: remote, Before: &before, After: &next})
+	return next, nil
+}
+
+// SetPaused pauses or resumes bidding on behalf of by. It reports whether
+// anything changed.
+func (c *Controller) SetPaused(paused bool, by, remote, reason string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if paused == (c.pause != nil) {
+		return false
+	}
+	action := ActionResume
+	c.pause = nil
+	if paused {
+		action = ActionPause
+		c.pause = &Pause{By: by, At: c.now().UTC(), Reason: reason}
+	}
+	c.record(Change{Action: action, By: by, Remote: remote, Reason: reason})
+	return true
+}
+
+// record logs ch and appends it to the audit file. c.mu must be held.
+func (c *Controller) record(ch Change) {
+	ch.Time = c.now().UTC()
+	attrs := []any{"action", ch.Action, "by", ch.By, "remote", ch.Remote}
+	if ch.Before != nil {
+		attrs = append(attrs, "before", *ch.Before, "after", *ch.After)
+	}
+	if ch.Reason != "" {
+		attrs = append(attrs, "reason", ch.Reason)
+	}
+	slog.Warn("Bidding changed at runtime", attrs...)
+	if c.audit == nil {
+		return
+	}
+	if err := json.NewEncoder(c.audit).Encode(ch); err != nil {
+		slog.Error("Failed to write control audit entry", "error", err, "action", ch.Action)
+	}
+}
+
+// ServeState serves the state as JSON.
+func (c *Controller) ServeState(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, c.State())
+}
+
+// ServeParams applies the Update in the request body.
+func (c *Controller) ServeParams(w http.ResponseWriter, r *http.Request) {
+	var u Update
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&u); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid parameters: " + err.Error()})
+		return
+	}
+	if _, err := c.Apply(u, auth.Identity(r), r.RemoteAddr); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, c.State())
+}
+
+// ServePause pauses bidding, with the reason of an optional
+// {"reason": "..."} body.
+func (c *Controller) ServePause(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid body: " + err.Error()})
+			return
+		}
+	}
+	c.SetPaused(true, auth.Identity(r), r.RemoteAddr, body.Reason)
+	writeJSON(w, http.StatusOK, c.State())
+}
+
+// ServeResume resumes bidding.
+func (c *Controller) ServeResume(w http.ResponseWriter, r *http.Request) {
+	c.SetPaused(false, auth.Identity(r), r.RemoteAddr, "")
+	writeJSON(w, http.StatusOK, c.State())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,74 @@
+package coordination
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLeaseExclusive(t *testing.T) {
+	ctx := context.Background()
+	lease := NewFileLease(filepath.Join(t.TempDir(), "lease.json"))
+
+	ok, err := lease.TryAcquire(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = lease.TryAcquire(ctx, "b", time.Minute)
+	require.NoError(t, err)
+	require.False(t, ok, "standby must not take over a live lease")
+
+	// Renewal by the holder succeeds.
+	ok, err = lease.TryAcquire(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, lease.Release(ctx, "a"))
+	ok, err = lease.TryAcquire(ctx, "b", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestFileLeaseTakeoverAfterExpiry(t *testing.T) {
+	ctx := context.Background()
+	lease := NewFileLease(filepath.Join(t.TempDir(), "lease.json"))
+	now := time.Now()
+	lease.now = func() time.Time { return now }
+
+	ok, err := lease.TryAcquire(ctx, "a", 6*time.Second)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	now = now.Add(7 * time.Second)
+	ok, err = lease.TryAcquire(ctx, "b", 6*time.Second)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	rec, err := lease.Current()
+	require.NoError(t, err)
+	require.Equal(t, "b", rec.Holder)
+}
+
+func TestElectorTransitions(t *testing.T) {
+	ctx := context.Background()
+	lease := NewFileLease(filepath.Join(t.TempDir(), "lease.json"))
+
+	var changes []bool
+	active := NewElector(lease, "a", time.Minute, func(a bool) { changes = append(changes, a) })
+	standby := NewElector(lease, "b", time.Minute, nil)
+
+	active.Tick(ctx)
+	standby.Tick(ctx)
+	require.True(t, active.IsActive())
+	require.False(t, standby.IsActive())
+
+	require.NoError(t, lease.Release(ctx, "a"))
+	standby.Tick(ctx)
+	active.Tick(ctx)
+	require.True(t, standby.IsActive())
+	require.False(t, active.IsActive())
+	require.Equal(t, []bool{true, false}, changes)
+}
@@ -0,0 +1,107 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLeaseTTL keeps failover within a single 12 second slot: the lease is
+// renewed every TTL/3, so a dead active instance is replaced after at most
+// TTL plus one renewal interval.
+const DefaultLeaseTTL = 6 * time.Second
+
+// Elector decides whether this instance is the active bidder.
+type Elector struct {
+	lease Lease
+	id    string
+	ttl   time.Duration
+
+	active   atomic.Bool
+	onChange func(active bool)
+}
+
+// NewElector creates an elector competing for lease under the given instance ID.
+// onChange, if not nil, is called whenever the instance's role changes.
+func NewElector(lease Lease, id string, ttl time.Duration, onChange func(active bool)) *Elector {
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+	return &Elector{lease: lease, id: id, ttl: ttl, onChange: onChange}
+}
+
+// DefaultInstanceID returns an identifier unique to this process.
+func DefaultInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// ID returns the instance ID used by the elector.
+func (e *Elector) ID() string {
+	return e.id
+}
+
+// IsActive reports whether this instance currently holds the lease.
+func (e *Elector) IsActive() bool {
+	return e.active.Load()
+}
+
+// Tick makes a single acquisition or renewal attempt. A failed attempt demotes
+// the instance to standby so two instances never bid at the same time.
+func (e *Elector) Tick(ctx context.Context) {
+	acquired, err := e.lease.TryAcquire(ctx, e.id, e.ttl)
+	if err != nil {
+		slog.Warn("Failed to renew coordination lease",
+			"error", err,
+			"instance", e.id,
+		)
+		acquired = false
+	}
+	e.setActive(acquired)
+}
+
+// Run keeps competing for the lease until ctx is canceled, then releases it.
+func (e *Elector) Run(ctx context.Context) {
+	interval := e.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	e.Tick(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			if e.IsActive() {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), interval)
+				if err := e.lease.Release(releaseCtx, e.id); err != nil {
+					slog.Warn("Failed to release coordination lease", "error", err, "instance", e.id)
+				}
+				cancel()
+				e.setActive(false)
+			}
+			return
+		case <-ticker.C:
+			e.Tick(ctx)
+		}
+	}
+}
+
+// setActive records the role and reports transitions.
+func (e *Elector) setActive(active bool) {
+	if e.active.Swap(active) == active {
+		return
+	}
+	if active {
+		slog.Info("Instance became active bidder", "instance", e.id)
+	} else {
+		slog.Warn("Instance moved to standby", "instance", e.id)
+	}
+	if e.onChange != nil {
+		e.onChange(active)
+	}
+}
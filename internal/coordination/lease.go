@@ -0,0 +1,143 @@
+// Package coordination lets several bidder instances share a single bidding
+// identity: exactly one active instance holds a lease and bids, while standby
+// instances keep their connections warm and take over when the lease expires.
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Lease is a time-bound exclusive claim shared between bidder instances.
+type Lease interface {
+	// TryAcquire acquires the lease for holder, or renews it if holder already
+	// owns it. It reports whether holder owns the lease afterwards.
+	TryAcquire(ctx context.Context, holder string, ttl time.Duration) (bool, error)
+	// Release gives up the lease if it is held by holder.
+	Release(ctx context.Context, holder string) error
+}
+
+// LeaseRecord is the persisted state of a lease.
+type LeaseRecord struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// staleLockAge is how long a critical-section lock may be held before it is
+// considered abandoned by a crashed instance.
+const staleLockAge = 5 * time.Second
+
+// FileLease is a Lease stored in a JSON file. All instances must see the same
+// file, e.g. through a shared volume.
+type FileLease struct {
+	path string
+	now  func() time.Time
+}
+
+// NewFileLease creates a file backed lease at path.
+func NewFileLease(path string) *FileLease {
+	return &FileLease{path: path, now: time.Now}
+}
+
+// TryAcquire implements Lease.
+func (l *FileLease) TryAcquire(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	acquired := false
+	err := l.withLock(ctx, func() error {
+		rec, err := l.read()
+		if err != nil {
+			return err
+		}
+		now := l.now()
+		if rec.Holder != "" && rec.Holder != holder && now.Before(rec.ExpiresAt) {
+			return nil
+		}
+		acquired = true
+		return l.write(LeaseRecord{Holder: holder, ExpiresAt: now.Add(ttl)})
+	})
+	return acquired, err
+}
+
+// Release implements Lease.
+func (l *FileLease) Release(ctx context.Context, holder string) error {
+	return l.withLock(ctx, func() error {
+		rec, err := l.read()
+		if err != nil || rec.Holder != holder {
+			return err
+		}
+		return l.write(LeaseRecord{})
+	})
+}
+
+// Current returns the persisted lease record.
+func (l *FileLease) Current() (LeaseRecord, error) {
+	return l.read()
+}
+
+// withLock runs fn while holding a lock directory next to the lease file.
+// Directory creation is atomic on every platform, which keeps the lease
+// portable without relying on flock semantics.
+func (l *FileLease) withLock(ctx context.Context, fn func() error) error {
+	lockDir := l.path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+
+	for {
+		err := os.Mkdir(lockDir, 0o755)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to lock lease: %w", err)
+		}
+		if info, statErr := os.Stat(lockDir); statErr == nil && l.now().Sub(info.ModTime()) > staleLockAge {
+			_ = os.Remove(lockDir)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	defer os.Remove(lockDir)
+
+	return fn()
+}
+
+// read loads the lease record, returning an empty record when none exists.
+func (l *FileLease) read() (LeaseRecord, error) {
+	var rec LeaseRecord
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return rec, nil
+	}
+	if err != nil {
+		return rec, err
+	}
+	if len(data) == 0 {
+		return rec, nil
+	}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return rec, fmt.Errorf("corrupt lease file %s: %w", l.path, err)
+	}
+	return rec, nil
+}
+
+// write atomically replaces the lease record.
+func (l *FileLease) write(rec LeaseRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
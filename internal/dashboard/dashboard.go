@@ -0,0 +1,164 @@
+// Package dashboard serves a minimal single-page dashboard on the status
+// server, for operators who don't run Grafana. It samples the bidder's
+// Prometheus metrics periodically and charts bids, acceptance rate, spend and
+// connection health from the samples.
+package dashboard
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// DefaultInterval is how often the metrics are sampled.
+const DefaultInterval = 10 * time.Second
+
+// DefaultSamples is how many samples are kept, an hour at DefaultInterval.
+const DefaultSamples = 360
+
+// Metric families summed into a sample, by sample field.
+const (
+	familyBidsSent     = "preconf_bidder_bids_sent_total"
+	familyBidsAccepted = "preconf_bidder_bids_accepted_total"
+	familyBidFailures  = "preconf_bidder_bid_failures_total"
+	familySpend        = "preconf_bidder_committed_bid_amount_eth_total"
+	familyWSReconnects = "preconf_bidder_ws_reconnects_total"
+)
+
+//go:embed static
+var static embed.FS
+
+// Sample holds the bidder's counters at a point in time. Counters are totals
+// since start; the page charts their differences.
+type Sample struct {
+	Time         time.Time `json:"time"`
+	BidsSent     float64   `json:"bids_sent"`
+	BidsAccepted float64   `json:"bids_accepted"`
+	BidFailures  float64   `json:"bid_failures"`
+	SpendETH     float64   `json:"spend_eth"`
+	WSReconnects float64   `json:"ws_reconnects"`
+}
+
+// Connection is the health of one of the bidder's connections.
+type Connection struct {
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint,omitempty"` // Masked.
+	Healthy  bool   `json:"healthy"`
+	Detail   string `json:"detail,omitempty"`
+	Standby  bool   `json:"standby,omitempty"` // A fallback not in use.
+}
+
+// Dashboard keeps recent samples and serves the page and its data.
+type Dashboard struct {
+	gatherer    prometheus.Gatherer
+	connections func() []Connection
+	files       http.Handler
+
+	mu      sync.Mutex
+	samples []Sample
+	max     int
+	now     func() time.Time
+}
+
+// New returns a dashboard sampling gatherer and reporting the connections
+// returned by connections, which may be nil.
+func New(gatherer prometheus.Gatherer, connections func() []Connection) *Dashboard {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // The directory is embedded at build time
+	}
+	return &Dashboard{
+		gatherer:    gatherer,
+		connections: connections,
+		files:       http.FileServer(http.FS(files)),
+		max:         DefaultSamples,
+		now:         time.Now,
+	}
+}
+
+// Sample records the current counters.
+func (d *Dashboard) Sample() {
+	families, err := d.gatherer.Gather()
+	if err != nil {
+		slog.Warn("Failed to gather metrics for the dashboard", "error", err)
+	}
+	totals := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			totals[family.GetName()] += value(m)
+		}
+	}
+	s := Sample{
+		Time:         d.now().UTC(),
+		BidsSent:     totals[familyBidsSent],
+		BidsAccepted: totals[familyBidsAccepted],
+		BidFailures:  totals[familyBidFailures],
+		SpendETH:     totals[familySpend],
+		WSReconnects: totals[familyWSReconnects],
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.samples = append(d.samples, s)
+	if len(d.samples) > d.max {
+		d.samples = d.samples[len(d.samples)-d.max:]
+	}
+}
+
+func value(m *dto.Metric) float64 {
+	switch {
+	case m.GetCounter() != nil:
+		return m.GetCounter().GetValue()
+	case m.GetGauge() != nil:
+		return m.GetGauge().GetValue()
+	}
+	return 0
+}
+
+// Run samples every interval until ctx is canceled.
+func (d *Dashboard) Run(ctx context.Context, interval time.Duration) {
+	d.Sample()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Sample()
+		}
+	}
+}
+
+// Data is the JSON served to the page on /data.
+type Data struct {
+	Samples     []Sample     `json:"samples"`
+	Connections []Connection `json:"connections"`
+}
+
+// ServeHTTP serves the page, and its data on /data.
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/data" {
+		d.files.ServeHTTP(w, r)
+		return
+	}
+	d.mu.Lock()
+	out := Data{Samples: append([]Sample(nil), d.samples...)}
+	d.mu.Unlock()
+	if d.connections != nil {
+		out.Connections = d.connections()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		slog.Warn("Failed to write dashboard data", "error", err)
+	}
+}
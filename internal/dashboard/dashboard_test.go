@@ -0,0 +1,54 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardServesPageAndSamples(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	sent := prometheus.NewCounter(prometheus.CounterOpts{Name: familyBidsSent})
+	failures := prometheus.NewCounterVec(prometheus.CounterOpts{Name: familyBidFailures}, []string{"stage"})
+	registry.MustRegister(sent, failures)
+
+	d := New(registry, func() []Connection {
+		return []Connection{{Name: "bidder node", Healthy: true, Detail: "READY"}}
+	})
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	d.max = 2
+
+	d.Sample()
+	sent.Add(3)
+	failures.WithLabelValues("send").Inc()
+	failures.WithLabelValues("tx").Inc()
+	now = now.Add(DefaultInterval)
+	d.Sample()
+	sent.Add(1)
+	now = now.Add(DefaultInterval)
+	d.Sample()
+
+	rec := httptest.NewRecorder()
+	d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/data", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got Data
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Len(t, got.Samples, 2, "only the most recent samples are kept")
+	require.Equal(t, 3.0, got.Samples[0].BidsSent)
+	require.Equal(t, 2.0, got.Samples[0].BidFailures, "labels are summed")
+	require.Equal(t, 4.0, got.Samples[1].BidsSent)
+	require.Equal(t, "READY", got.Connections[0].Detail)
+
+	for _, path := range []string{"/", "/dashboard.js", "/dashboard.css"} {
+		rec = httptest.NewRecorder()
+		d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		require.NotEmpty(t, rec.Body.String(), path)
+	}
+}
@@ -0,0 +1,111 @@
+"use strict";
+
+const colors = { sent: "#2e86de", accepted: "#27ae60", failed: "#c0392b", line: "#8e44ad" };
+
+// Per-minute rates between consecutive samples of a counter.
+function rates(samples, key) {
+  const points = [];
+  for (let i = 1; i < samples.length; i++) {
+    const minutes = (new Date(samples[i].time) - new Date(samples[i - 1].time)) / 60000;
+    const delta = Math.max(0, samples[i][key] - samples[i - 1][key]);
+    points.push({ t: new Date(samples[i].time), v: minutes > 0 ? delta / minutes : 0 });
+  }
+  return points;
+}
+
+// Share of bids accepted between consecutive samples, skipping intervals without bids.
+function acceptance(samples) {
+  const points = [];
+  for (let i = 1; i < samples.length; i++) {
+    const sent = samples[i].bids_sent - samples[i - 1].bids_sent;
+    if (sent <= 0) continue;
+    const accepted = samples[i].bids_accepted - samples[i - 1].bids_accepted;
+    points.push({ t: new Date(samples[i].time), v: accepted / sent });
+  }
+  return points;
+}
+
+function totals(samples, key) {
+  return samples.map(s => ({ t: new Date(s.time), v: s[key] }));
+}
+
+function draw(canvas, series, fixedMax) {
+  const ratio = window.devicePixelRatio || 1;
+  canvas.width = canvas.clientWidth * ratio;
+  canvas.height = canvas.clientHeight * ratio;
+  const ctx = canvas.getContext("2d");
+  ctx.scale(ratio, ratio);
+  const w = canvas.clientWidth, h = canvas.clientHeight, pad = 30;
+  ctx.clearRect(0, 0, w, h);
+
+  const all = series.flatMap(s => s.points);
+  if (all.length === 0) {
+    ctx.fillStyle = "#999";
+    ctx.fillText("no data yet", w / 2 - 25, h / 2);
+    return;
+  }
+  const t0 = Math.min(...all.map(p => p.t)), t1 = Math.max(...all.map(p => p.t));
+  const max = fixedMax || Math.max(...all.map(p => p.v), 1e-9);
+  const x = t => pad + (t1 > t0 ? (t - t0) / (t1 - t0) : 1) * (w - pad - 5);
+  const y = v => h - 15 - (v / max) * (h - 25);
+
+  ctx.strokeStyle = "#ddd";
+  ctx.fillStyle = "#666";
+  ctx.font = "10px system-ui";
+  ctx.beginPath();
+  ctx.moveTo(pad, y(0));
+  ctx.lineTo(w - 5, y(0));
+  ctx.stroke();
+  ctx.fillText(max.toPrecision(3), 0, y(max) + 4);
+  ctx.fillText("0", 0, y(0) + 4);
+  ctx.fillText(new Date(t0).toLocaleTimeString(), pad, h - 2);
+
+  for (const s of series) {
+    ctx.strokeStyle = s.color;
+    ctx.lineWidth = 1.5;
+    ctx.beginPath();
+    s.points.forEach((p, i) => (i ? ctx.lineTo(x(p.t), y(p.v)) : ctx.moveTo(x(p.t), y(p.v))));
+    ctx.stroke();
+  }
+}
+
+function renderConnections(connections) {
+  const section = document.getElementById("connections");
+  section.replaceChildren(...(connections || []).map(c => {
+    const div = document.createElement("div");
+    div.className = "connection" + (c.healthy ? " healthy" : "");
+    div.textContent = c.name + (c.healthy ? " ok" : " down");
+    const detail = document.createElement("small");
+    detail.textContent = [c.endpoint, c.detail].filter(Boolean).join(" · ");
+    div.appendChild(detail);
+    return div;
+  }));
+}
+
+// A token in the page URL (?token=...) is passed on to the data requests.
+const token = new URLSearchParams(location.search).get("token");
+
+async function refresh() {
+  try {
+    const headers = token ? { Authorization: "Bearer " + token } : {};
+    const res = await fetch("data", { cache: "no-store", headers });
+    if (!res.ok) throw new Error(res.status + " " + (await res.text()).trim());
+    const data = await res.json();
+    const samples = data.samples || [];
+    renderConnections(data.connections);
+    draw(document.getElementById("bids"), [
+      { color: colors.sent, points: rates(samples, "bids_sent") },
+      { color: colors.accepted, points: rates(samples, "bids_accepted") },
+      { color: colors.failed, points: rates(samples, "bid_failures") },
+    ]);
+    draw(document.getElementById("acceptance"), [{ color: colors.accepted, points: acceptance(samples) }], 1);
+    draw(document.getElementById("spend"), [{ color: colors.line, points: totals(samples, "spend_eth") }]);
+    draw(document.getElementById("reconnects"), [{ color: colors.failed, points: totals(samples, "ws_reconnects") }]);
+    document.getElementById("updated").textContent = "updated " + new Date().toLocaleTimeString();
+  } catch (err) {
+    document.getElementById("updated").textContent = "bidder unreachable: " + err;
+  }
+}
+
+refresh();
+setInterval(refresh, 10000);
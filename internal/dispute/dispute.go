@@ -0,0 +1,266 @@
+// Package dispute assembles the evidence for our commitments that were stored
+// on the mev-commit chain, and so paid for, but not honored: a committed
+// transaction is missing from the target block. The bundle holds what is
+// needed to raise the issue with the oracle or the provider, the commitment
+// index, digests and signatures of the bid and commitment, and the block, and
+// is logged, appended to a file and optionally posted to a reporting endpoint.
+package dispute
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+// CheckDelay is how many blocks after a target block it is checked, so
+// commitments stored late on the mev-commit chain are still covered.
+const CheckDelay = 2
+
+// Commitment is a commitment stored on the mev-commit chain, as opened by the
+// provider.
+type Commitment struct {
+	Index               string   `json:"commitment_index"`
+	Digest              string   `json:"commitment_digest"`
+	Signature           string   `json:"commitment_signature"`
+	BidDigest           string   `json:"bid_digest"`
+	BidSignature        string   `json:"bid_signature"`
+	Provider            string   `json:"provider"`
+	Bidder              string   `json:"bidder"`
+	BidAmount           string   `json:"bid_amount_wei"`
+	TxHashes            []string `json:"tx_hashes"`
+	BlockNumber         uint64   `json:"block_number"`
+	DecayStartTimestamp uint64   `json:"decay_start_timestamp"`
+	DecayEndTimestamp   uint64   `json:"decay_end_timestamp"`
+	DispatchTimestamp   uint64   `json:"dispatch_timestamp"`
+}
+
+// Evidence is the target block as seen by our node.
+type Evidence struct {
+	BlockNumber  uint64   `json:"block_number"`
+	BlockHash    string   `json:"block_hash"`
+	ParentHash   string   `json:"parent_hash"`
+	FeeRecipient string   `json:"fee_recipient"`
+	Timestamp    uint64   `json:"timestamp"`
+	TxRoot       string   `json:"transactions_root"`
+	TxCount      int      `json:"tx_count"`
+	Missing      []string `json:"missing_tx_hashes"` // Committed transactions not in the block.
+}
+
+// Bundle is the data of a dispute.
+type Bundle struct {
+	Time       time.Time  `json:"time"`
+	Commitment Commitment `json:"commitment"`
+	Evidence   Evidence   `json:"evidence"`
+}
+
+// Reporter keeps our stored commitments until their target block is checked.
+type Reporter struct {
+	own      func(txHash string) bool
+	endpoint string
+	client   *http.Client
+	f        *os.File
+	enc      *json.Encoder
+	now      func() time.Time
+
+	mu       sync.Mutex
+	pending  map[uint64][]Commitment // By target block.
+	reported map[string]Commitment   // Not honored, by index, until the oracle processes them.
+}
+
+// Open returns a reporter keeping the commitments for transactions own
+// reports as ours. Bundles are appended to path as JSON lines and posted to
+// endpoint; either may be empty.
+func Open(path, endpoint string, own func(txHash string) bool) (*Reporter, error) {
+	r := &Reporter{
+		own:      own,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		now:      time.Now,
+		pending:  make(map[uint64][]Commitment),
+		reported: make(map[string]Commitment),
+	}
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open dispute file: %w", err)
+		}
+		r.f, r.enc = f, json.NewEncoder(f)
+	}
+	return r, nil
+}
+
+// Close closes the dispute file.
+func (r *Reporter) Close() error {
+	if r == nil || r.f == nil {
+		return nil
+	}
+	return r.f.Close()
+}
+
+// normalize makes hashes from events and blocks comparable.
+func normalize(hash string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(hash), "0x"))
+}
+
+// Stored keeps c when any of its transactions is ours.
+func (r *Reporter) Stored(c Commitment) {
+	if r == nil {
+		return
+	}
+	for _, hash := range c.TxHashes {
+		if r.own(hash) {
+			r.mu.Lock()
+			r.pending[c.BlockNumber] = append(r.pending[c.BlockNumber], c)
+			r.mu.Unlock()
+			return
+		}
+	}
+}
+
+// Due returns the target blocks with kept commitments that are at least
+// CheckDelay blocks behind head, in ascending order.
+func (r *Reporter) Due(head uint64) []uint64 {
+	if r == nil || head < CheckDelay {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []uint64
+	for block := range r.pending {
+		if block <= head-CheckDelay {
+			due = append(due, block)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i] < due[j] })
+	return due
+}
+
+// Take returns and forgets the commitments kept for a target block.
+func (r *Reporter) Take(block uint64) []Commitment {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	commitments := r.pending[block]
+	delete(r.pending, block)
+	return commitments
+}
+
+// Check returns a bundle for every commitment of commitments with a
+// transaction missing from block, the target block.
+func (r *Reporter) Check(block *types.Block, commitments []Commitment) []Bundle {
+	included := make(map[string]bool, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		included[normalize(tx.Hash().Hex())] = true
+	}
+	var bundles []Bundle
+	for _, c := range commitments {
+		var missing []string
+		for _, hash := range c.TxHashes {
+			if !included[normalize(hash)] {
+				missing = append(missing, hash)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		bundles = append(bundles, Bundle{
+			Time:       r.now().UTC(),
+			Commitment: c,
+			Evidence: Evidence{
+				BlockNumber:  block.NumberU64(),
+				BlockHash:    block.Hash().Hex(),
+				ParentHash:   block.ParentHash().Hex(),
+				FeeRecipient: block.Coinbase().Hex(),
+				Timestamp:    block.Time(),
+				TxRoot:       block.TxHash().Hex(),
+				TxCount:      len(block.Transactions()),
+				Missing:      missing,
+			},
+		})
+	}
+	return bundles
+}
+
+// Report logs b, appends it to the dispute file and posts it to the endpoint.
+func (r *Reporter) Report(ctx context.Context, b Bundle) {
+	metrics.CommitmentsNotHonored.WithLabelValues(b.Commitment.Provider).Inc()
+	slog.Warn("Commitment not honored",
+		"provider", b.Commitment.Provider,
+		"commitmentIndex", b.Commitment.Index,
+		"blockNumber", b.Evidence.BlockNumber,
+		"blockHash", b.Evidence.BlockHash,
+		"missingTxHashes", b.Evidence.Missing,
+	)
+	r.mu.Lock()
+	r.reported[normalize(b.Commitment.Index)] = b.Commitment
+	if r.enc != nil {
+		if err := r.enc.Encode(b); err != nil {
+			slog.Warn("Failed to write dispute file", "error", err)
+		}
+	}
+	r.mu.Unlock()
+	if r.endpoint == "" {
+		return
+	}
+	if err := r.Submit(ctx, b); err != nil {
+		slog.Warn("Failed to submit commitment dispute", "error", err, "commitmentIndex", b.Commitment.Index)
+	}
+}
+
+// Processed settles the dispute of the commitment with index once the oracle
+// processed it, slashing its provider or not, and reports whether it was one
+// of ours reported as not honored.
+func (r *Reporter) Processed(index string, slashed bool) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	c, ok := r.reported[normalize(index)]
+	delete(r.reported, normalize(index))
+	r.mu.Unlock()
+	if !ok {
+		return false
+	}
+	if slashed {
+		metrics.DisputesSettled.WithLabelValues(c.Provider, "slashed").Inc()
+		slog.Info("Provider slashed for commitment not honored", "provider", c.Provider, "commitmentIndex", c.Index, "blockNumber", c.BlockNumber)
+	} else {
+		metrics.DisputesSettled.WithLabelValues(c.Provider, "rewarded").Inc()
+		slog.Warn("Provider rewarded for commitment not honored", "provider", c.Provider, "commitmentIndex", c.Index, "blockNumber", c.BlockNumber)
+	}
+	return true
+}
+
+// Submit posts b to the reporting endpoint.
+func (r *Reporter) Submit(ctx context.Context, b Bundle) error {
+	body, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("dispute endpoint returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
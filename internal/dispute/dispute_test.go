@@ -0,0 +1,104 @@
+package dispute
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnhonoredCommitmentReported(t *testing.T) {
+	included := types.NewTx(&types.LegacyTx{Nonce: 1})
+	missing := types.NewTx(&types.LegacyTx{Nonce: 2})
+	own := map[string]bool{
+		normalize(included.Hash().Hex()): true,
+		normalize(missing.Hash().Hex()):  true,
+	}
+
+	var posted []Bundle
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var b Bundle
+		require.NoError(t, json.Unmarshal(body, &b))
+		posted = append(posted, b)
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "disputes.jsonl")
+	r, err := Open(path, ts.URL, func(hash string) bool { return own[normalize(hash)] })
+	require.NoError(t, err)
+
+	// Event hashes come without 0x prefix
+	r.Stored(Commitment{Index: "0x01", Provider: "0xprovider", TxHashes: []string{included.Hash().Hex()[2:]}, BlockNumber: 100})
+	r.Stored(Commitment{Index: "0x02", Provider: "0xprovider", TxHashes: []string{missing.Hash().Hex()[2:]}, BlockNumber: 100})
+	r.Stored(Commitment{Index: "0x03", TxHashes: []string{common.Hash{1}.Hex()}, BlockNumber: 100})
+
+	require.Empty(t, r.Due(101), "not due before CheckDelay blocks")
+	require.Equal(t, []uint64{100}, r.Due(102))
+
+	commitments := r.Take(100)
+	require.Len(t, commitments, 2, "only our commitments are kept")
+	require.Empty(t, r.Due(110))
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(100), Coinbase: common.Address{9}}).
+		WithBody(types.Body{Transactions: types.Transactions{included}})
+	bundles := r.Check(block, commitments)
+	require.Len(t, bundles, 1)
+	b := bundles[0]
+	require.Equal(t, "0x02", b.Commitment.Index)
+	require.Equal(t, []string{missing.Hash().Hex()[2:]}, b.Evidence.Missing)
+	require.Equal(t, block.Hash().Hex(), b.Evidence.BlockHash)
+	require.Equal(t, common.Address{9}.Hex(), b.Evidence.FeeRecipient)
+	require.Equal(t, 1, b.Evidence.TxCount)
+
+	r.Report(context.Background(), b)
+	require.NoError(t, r.Close())
+	require.Len(t, posted, 1)
+	require.Equal(t, "0x02", posted[0].Commitment.Index)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	require.True(t, scanner.Scan())
+	var written Bundle
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &written))
+	require.Equal(t, b.Evidence.BlockHash, written.Evidence.BlockHash)
+	require.False(t, scanner.Scan())
+}
+
+func TestProcessedSettlesReportedDisputes(t *testing.T) {
+	r, err := Open("", "", func(string) bool { return true })
+	require.NoError(t, err)
+	r.Report(context.Background(), Bundle{Commitment: Commitment{Index: "0xAB01", Provider: "0xslashed"}})
+	r.Report(context.Background(), Bundle{Commitment: Commitment{Index: "0x02", Provider: "0xrewarded"}})
+
+	before := testutil.ToFloat64(metrics.DisputesSettled.WithLabelValues("0xslashed", "slashed"))
+	require.True(t, r.Processed("0xab01", true), "indexes compare case-insensitively")
+	require.False(t, r.Processed("0xab01", true), "a dispute is settled once")
+	require.Equal(t, before+1, testutil.ToFloat64(metrics.DisputesSettled.WithLabelValues("0xslashed", "slashed")))
+
+	require.True(t, r.Processed("0x02", false))
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.DisputesSettled.WithLabelValues("0xrewarded", "rewarded")))
+	require.False(t, r.Processed("0x03", true), "commitments not reported are ignored")
+}
+
+func TestNilReporter(t *testing.T) {
+	var r *Reporter
+	r.Stored(Commitment{BlockNumber: 1})
+	require.Empty(t, r.Due(10))
+	require.False(t, r.Processed("0x01", true))
+	require.NoError(t, r.Close())
+}
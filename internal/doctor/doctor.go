@@ -0,0 +1,126 @@
+// Package doctor runs troubleshooting diagnostics and reports their results
+// ordered by how urgently they need attention.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Severity ranks a diagnostic result.
+type Severity int
+
+const (
+	OK Severity = iota
+	Skip
+	Warn
+	Fail
+)
+
+func (s Severity) String() string {
+	switch s {
+	case OK:
+		return "OK"
+	case Skip:
+		return "SKIP"
+	case Warn:
+		return "WARN"
+	case Fail:
+		return "FAIL"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Detail   string   `json:"detail"`
+	Remedy   string   `json:"remedy,omitempty"` // What to do about a warning or failure.
+}
+
+// Check is a single diagnostic.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) Result
+}
+
+// Pass reports a successful check.
+func Pass(detail string) Result {
+	return Result{Severity: OK, Detail: detail}
+}
+
+// Skipped reports a check that could not run, e.g. for lack of configuration.
+func Skipped(detail string) Result {
+	return Result{Severity: Skip, Detail: detail}
+}
+
+// Warning reports a problem that degrades bidding.
+func Warning(detail, remedy string) Result {
+	return Result{Severity: Warn, Detail: detail, Remedy: remedy}
+}
+
+// Failure reports a problem that prevents bidding.
+func Failure(detail, remedy string) Result {
+	return Result{Severity: Fail, Detail: detail, Remedy: remedy}
+}
+
+// Run executes the checks in order, giving each at most timeout, and returns
+// their results in the same order.
+func Run(ctx context.Context, checks []Check, timeout time.Duration) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		r := c.Run(checkCtx)
+		cancel()
+		r.Check = c.Name
+		results = append(results, r)
+	}
+	return results
+}
+
+// Prioritize returns the results ordered by descending severity, keeping the
+// check order within a severity.
+func Prioritize(results []Result) []Result {
+	out := append([]Result(nil), results...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Severity > out[j].Severity })
+	return out
+}
+
+// Worst returns the highest severity among results.
+func Worst(results []Result) Severity {
+	worst := OK
+	for _, r := range results {
+		if r.Severity > worst {
+			worst = r.Severity
+		}
+	}
+	return worst
+}
+
+// Print writes every result followed by the numbered remediation steps of the
+// warnings and failures, most urgent first.
+func Print(w io.Writer, results []Result) {
+	for _, r := range results {
+		fmt.Fprintf(w, "[%-4s] %-24s %s\n", r.Severity, r.Check, r.Detail)
+	}
+
+	step := 0
+	for _, r := range Prioritize(results) {
+		if r.Severity < Warn || r.Remedy == "" {
+			continue
+		}
+		if step == 0 {
+			fmt.Fprintln(w, "\nRemediation steps:")
+		}
+		step++
+		fmt.Fprintf(w, "%d. [%s] %s: %s\n", step, r.Severity, r.Check, r.Remedy)
+	}
+	if step == 0 {
+		fmt.Fprintln(w, "\nNo problems found.")
+	}
+}
@@ -0,0 +1,43 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPrioritizesRemediation(t *testing.T) {
+	checks := []Check{
+		{Name: "rpc", Run: func(context.Context) Result { return Pass("chain id 17000") }},
+		{Name: "balance", Run: func(context.Context) Result { return Warning("low balance", "fund the wallet") }},
+		{Name: "bidder", Run: func(context.Context) Result { return Failure("unreachable", "start the bidder node") }},
+		{Name: "slow", Run: func(ctx context.Context) Result {
+			<-ctx.Done()
+			return Failure("timed out", "check the network")
+		}},
+	}
+
+	results := Run(context.Background(), checks, 10*time.Millisecond)
+	require.Len(t, results, 4)
+	require.Equal(t, "balance", results[1].Check)
+	require.Equal(t, Fail, Worst(results))
+
+	var out bytes.Buffer
+	Print(&out, results)
+	remediation := out.String()[strings.Index(out.String(), "Remediation steps:"):]
+	require.Equal(t, "Remediation steps:\n"+
+		"1. [FAIL] bidder: start the bidder node\n"+
+		"2. [FAIL] slow: check the network\n"+
+		"3. [WARN] balance: fund the wallet\n", remediation)
+}
+
+func TestPrintWithoutProblems(t *testing.T) {
+	var out bytes.Buffer
+	Print(&out, []Result{{Check: "rpc", Severity: OK, Detail: "fine"}})
+	require.Contains(t, out.String(), "No problems found.")
+	require.Equal(t, OK, Worst(nil))
+}
@@ -0,0 +1,108 @@
+// Package errors defines the kinds of errors the bidder branches on.
+//
+// Nodes, builders, the preconf RPC, the bidder node and WebSocket connections
+// report errors as text or gRPC statuses. Classify maps them to kinds where
+// they enter the bidder, so callers and tests match kinds with errors.Is
+// instead of matching strings. A classified error keeps its message.
+package errors
+
+import (
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Kinds of errors.
+var (
+	// ErrWSDropped means a WebSocket connection closed without a close frame
+	// (code 1006), as when the node drops it.
+	ErrWSDropped = errors.New("websocket connection dropped")
+	// ErrWSStalled means a WebSocket subscription stopped delivering headers
+	// without closing.
+	ErrWSStalled = errors.New("websocket subscription stalled")
+	// ErrBidderUnavailable means the bidder node could not be reached.
+	ErrBidderUnavailable = errors.New("bidder node unavailable")
+	// ErrBidRejected means the bidder node refused the bid.
+	ErrBidRejected = errors.New("bid rejected by the bidder node")
+	// ErrInsufficientDeposit means the bidder's deposit does not cover the bid.
+	ErrInsufficientDeposit = errors.New("insufficient deposit")
+	// ErrInsufficientFunds means the account cannot pay for a transaction.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrNonceTooLow means the account already used the transaction's nonce.
+	ErrNonceTooLow = errors.New("nonce too low")
+	// ErrUnderpriced means a pending transaction with the same nonce pays too
+	// much to be replaced.
+	ErrUnderpriced = errors.New("replacement transaction underpriced")
+	// ErrNonceConflict means the nonce is held by another committed
+	// transaction of the same account.
+	ErrNonceConflict = errors.New("nonce held by another committed transaction")
+)
+
+// kindError is an error marked with kinds, keeping its message.
+type kindError struct {
+	err   error
+	kinds []error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return append([]error{e.err}, e.kinds...)
+}
+
+// Mark returns err marked with kinds it is not of already. A nil err stays
+// nil.
+func Mark(err error, kinds ...error) error {
+	if err == nil {
+		return nil
+	}
+	var missing []error
+	for _, kind := range kinds {
+		if !errors.Is(err, kind) {
+			missing = append(missing, kind)
+		}
+	}
+	if len(missing) == 0 {
+		return err
+	}
+	return &kindError{err: err, kinds: missing}
+}
+
+// Classify returns err marked with the kinds its message or gRPC status
+// reveals. It may be called again on a classified error.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	var kinds []error
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "nonce too low"):
+		kinds = append(kinds, ErrNonceTooLow)
+	case strings.Contains(msg, "replacement transaction underpriced"), strings.Contains(msg, "replacement underpriced"):
+		kinds = append(kinds, ErrUnderpriced)
+	case strings.Contains(msg, "insufficient funds"):
+		kinds = append(kinds, ErrInsufficientFunds)
+	case strings.Contains(msg, "websocket: close 1006"):
+		kinds = append(kinds, ErrWSDropped)
+	}
+	if strings.Contains(msg, "deposit") && (strings.Contains(msg, "insufficient") || strings.Contains(msg, "not enough")) {
+		kinds = append(kinds, ErrInsufficientDeposit)
+	}
+	switch status.Code(err) {
+	case codes.Unavailable:
+		kinds = append(kinds, ErrBidderUnavailable)
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.PermissionDenied, codes.ResourceExhausted:
+		kinds = append(kinds, ErrBidRejected)
+	}
+	return Mark(err, kinds...)
+}
+
+// Is reports whether err, once classified, is of kind.
+func Is(err, kind error) bool {
+	return errors.Is(Classify(err), kind)
+}
@@ -0,0 +1,40 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClassify(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want []error
+	}{
+		{errors.New("request failed -32000: nonce too low"), []error{ErrNonceTooLow}},
+		{errors.New("Replacement transaction underpriced"), []error{ErrUnderpriced}},
+		{errors.New("insufficient funds for gas * price + value"), []error{ErrInsufficientFunds}},
+		{fmt.Errorf("subscription: %w", errors.New("websocket: close 1006 (abnormal closure): unexpected EOF")), []error{ErrWSDropped}},
+		{status.Error(codes.Unavailable, "connection refused"), []error{ErrBidderUnavailable}},
+		{status.Error(codes.FailedPrecondition, "insufficient deposit for window 12"), []error{ErrBidRejected, ErrInsufficientDeposit}},
+		{fmt.Errorf("failed to send bid: %w", status.Error(codes.InvalidArgument, "bad decay")), []error{ErrBidRejected}},
+	} {
+		classified := Classify(tc.err)
+		require.Equal(t, tc.err.Error(), classified.Error(), "the message is kept")
+		require.ErrorIs(t, classified, tc.err)
+		for _, kind := range tc.want {
+			require.ErrorIs(t, classified, kind, tc.err.Error())
+		}
+		require.Equal(t, classified, Classify(classified), "classifying again changes nothing")
+	}
+
+	require.Nil(t, Classify(nil))
+	plain := errors.New("connection reset")
+	require.Equal(t, plain, Classify(plain))
+	require.False(t, Is(plain, ErrWSDropped))
+	require.True(t, Is(fmt.Errorf("%w: nonce 3", ErrNonceConflict), ErrNonceConflict))
+}
@@ -0,0 +1,105 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/primev/preconf_blob_bidder/internal/latency"
+	"github.com/primev/preconf_blob_bidder/internal/money"
+)
+
+// DefaultBlobFeeBump is the factor the blob fee cap exceeds the expected blob
+// base fee by, so a transaction for the same nonce can be replaced.
+const DefaultBlobFeeBump = 1.1
+
+// BlobFeeSource is where the blob base fee of the next block comes from.
+type BlobFeeSource string
+
+const (
+	// HeaderBlobFee computes the blob base fee from the head's excess blob gas.
+	HeaderBlobFee BlobFeeSource = "header"
+	// RPCBlobFee asks the node with eth_blobBaseFee, which follows the fork
+	// schedule of the node rather than the one compiled into the bidder.
+	RPCBlobFee BlobFeeSource = "rpc"
+)
+
+// ParseBlobFeeSource validates the source of the blob base fee.
+func ParseBlobFeeSource(s string) (BlobFeeSource, error) {
+	switch source := BlobFeeSource(s); source {
+	case HeaderBlobFee, RPCBlobFee:
+		return source, nil
+	}
+	return "", fmt.Errorf("unknown blob fee source %q, must be header or rpc", s)
+}
+
+// BlobFeeOracle picks the blob fee cap of blob transactions.
+type BlobFeeOracle interface {
+	// BlobFeeCap returns the blob fee cap of a transaction built on header
+	// for the block offset blocks ahead.
+	BlobFeeCap(client *ethclient.Client, header *types.Header, offset uint64) (*big.Int, error)
+}
+
+// BlobPricer prices blob gas. The cap covers the expected blob base fee of the
+// target block, plus 1 wei, times bump, and is lowered to maxCap. Blocks whose
+// blob base fee exceeds ceiling, or the capped fee, are refused with ErrFeeCap
+// rather than bid on with a transaction that cannot be included.
+type BlobPricer struct {
+	source  BlobFeeSource
+	bump    float64
+	maxCap  *big.Int
+	ceiling *big.Int
+}
+
+// NewBlobPricer returns a blob pricer. A nil maxCap or ceiling disables it.
+func NewBlobPricer(source BlobFeeSource, bump float64, maxCap, ceiling *big.Int) *BlobPricer {
+	return &BlobPricer{source: source, bump: bump, maxCap: maxCap, ceiling: ceiling}
+}
+
+// DefaultBlobFees returns a pricer computing the blob base fee from headers,
+// bumped by DefaultBlobFeeBump, without a maximum.
+func DefaultBlobFees() *BlobPricer {
+	return NewBlobPricer(HeaderBlobFee, DefaultBlobFeeBump, nil, nil)
+}
+
+// BlobFeeCap implements BlobFeeOracle.
+func (p *BlobPricer) BlobFeeCap(client *ethclient.Client, header *types.Header, offset uint64) (*big.Int, error) {
+	baseFee, err := p.blobBaseFee(client, header)
+	if err != nil {
+		return nil, err
+	}
+	if p.ceiling != nil && baseFee.Cmp(p.ceiling) > 0 {
+		return nil, fmt.Errorf("%w: blob base fee of %s wei exceeds %s wei", ErrFeeCap, baseFee, p.ceiling)
+	}
+	feeCap := feeHeadroom(baseFee, offset)
+	feeCap.Add(feeCap, big.NewInt(1))
+	feeCap = money.Scale(feeCap, p.bump)
+	if p.maxCap != nil && feeCap.Cmp(p.maxCap) > 0 {
+		feeCap.Set(p.maxCap)
+	}
+	if feeCap.Cmp(baseFee) < 0 {
+		return nil, fmt.Errorf("%w: blob base fee of %s wei exceeds the blob fee cap of %s wei", ErrFeeCap, baseFee, feeCap)
+	}
+	return feeCap, nil
+}
+
+// blobBaseFee returns the blob base fee of the block after header.
+func (p *BlobPricer) blobBaseFee(client *ethclient.Client, header *types.Header) (*big.Int, error) {
+	if p.source == RPCBlobFee {
+		ctx, cancel := latency.Context(context.Background(), latency.GasEstimate)
+		defer cancel()
+		var fee hexutil.Big
+		if err := client.Client().CallContext(ctx, &fee, "eth_blobBaseFee"); err != nil {
+			return nil, fmt.Errorf("eth_blobBaseFee: %w", latency.Wrap(latency.GasEstimate, err))
+		}
+		return fee.ToInt(), nil
+	}
+	if header.ExcessBlobGas == nil || header.BlobGasUsed == nil {
+		return nil, fmt.Errorf("block %d has no blob gas fields, blobs are not supported", header.Number)
+	}
+	return eip4844.CalcBlobFee(eip4844.CalcExcessBlobGas(*header.ExcessBlobGas, *header.BlobGasUsed)), nil
+}
@@ -0,0 +1,61 @@
+package eth
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/require"
+)
+
+// blobHeader returns a head whose next block has a blob base fee of 2 wei.
+func blobHeader() *types.Header {
+	excess, used := uint64(3_338_477), uint64(0x60000)
+	return &types.Header{Number: big.NewInt(100), ExcessBlobGas: &excess, BlobGasUsed: &used}
+}
+
+func TestBlobPricerBumpsExpectedBlobFee(t *testing.T) {
+	feeCap, err := DefaultBlobFees().BlobFeeCap(nil, blobHeader(), 1)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(3), feeCap, "(2 + 1) * 1.1, truncated")
+
+	feeCap, err = NewBlobPricer(HeaderBlobFee, 2, nil, nil).BlobFeeCap(nil, blobHeader(), 1)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(6), feeCap)
+}
+
+func TestBlobPricerAsksNode(t *testing.T) {
+	ts := httptest.NewServer(&rpcServer{})
+	defer ts.Close()
+	client, err := ethclient.Dial(ts.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	feeCap, err := NewBlobPricer(RPCBlobFee, 1, nil, nil).BlobFeeCap(client, blobHeader(), 2)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(11), feeCap, "eth_blobBaseFee of 9 grown for one block, plus 1")
+}
+
+func TestBlobPricerCapsAndRefuses(t *testing.T) {
+	feeCap, err := NewBlobPricer(HeaderBlobFee, 2, big.NewInt(4), nil).BlobFeeCap(nil, blobHeader(), 1)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(4), feeCap, "lowered to the maximum")
+
+	_, err = NewBlobPricer(HeaderBlobFee, 2, big.NewInt(1), nil).BlobFeeCap(nil, blobHeader(), 1)
+	require.ErrorIs(t, err, ErrFeeCap, "a cap below the blob base fee cannot be included")
+
+	_, err = NewBlobPricer(HeaderBlobFee, 2, nil, big.NewInt(1)).BlobFeeCap(nil, blobHeader(), 1)
+	require.ErrorIs(t, err, ErrFeeCap, "blob base fee above the ceiling")
+	_, err = NewBlobPricer(HeaderBlobFee, 2, nil, big.NewInt(2)).BlobFeeCap(nil, blobHeader(), 1)
+	require.NoError(t, err)
+}
+
+func TestParseBlobFeeSource(t *testing.T) {
+	source, err := ParseBlobFeeSource("rpc")
+	require.NoError(t, err)
+	require.Equal(t, RPCBlobFee, source)
+	_, err = ParseBlobFeeSource("beacon")
+	require.Error(t, err)
+}
@@ -5,15 +5,24 @@ package eth
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"log/slog"
 
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	errs "github.com/primev/preconf_blob_bidder/internal/errors"
+	"github.com/primev/preconf_blob_bidder/internal/ident"
+	"github.com/primev/preconf_blob_bidder/internal/latency"
 )
 
 type JSONRPCResponse struct {
@@ -36,47 +45,190 @@ type FlashbotsPayload struct {
 }
 
 
-// SendBundle sends a signed transaction bundle to the specified RPC URL.
-// It returns the result as a string or an error if the operation fails.
-func SendBundle(rpcurl string, signedTx *types.Transaction, blkNum uint64) (string, error) {
-	// Marshal the signed transaction into binary format.
-	binary, err := signedTx.MarshalBinary()
+// BundleHints are optional placement hints for bundle submissions. Builders
+// that do not support a hint ignore or reject it.
+type BundleHints struct {
+	// BackrunTxHash places the transaction directly after this pending
+	// transaction, using the MEV-Share mev_sendBundle method.
+	BackrunTxHash string
+	// Extra holds builder specific fields merged into the eth_sendBundle
+	// parameters, e.g. top-of-block placement hints.
+	Extra map[string]interface{}
+	// ReplacementUUID lets a later bundle with the same UUID replace this one,
+	// or eth_cancelBundle cancel it.
+	ReplacementUUID string
+	// MinTimestamp and MaxTimestamp bound, in Unix seconds, the time the
+	// bundle is valid for; 0 leaves a bound open.
+	MinTimestamp, MaxTimestamp uint64
+	// SearchKey signs every request for the X-Flashbots-Signature header, which
+	// relays such as Flashbots require. Requests are unsigned without it.
+	SearchKey *ecdsa.PrivateKey
+}
+
+// IsZero reports whether no hint is set. The search key is not a hint.
+func (h BundleHints) IsZero() bool {
+	return h.BackrunTxHash == "" && len(h.Extra) == 0 &&
+		h.ReplacementUUID == "" && h.MinTimestamp == 0 && h.MaxTimestamp == 0
+}
+
+// ReplacementFor returns the replacement UUID of the bundles of sender for
+// blkNum. It is the same on every call, so a resubmission for the block
+// replaces the earlier bundle.
+func ReplacementFor(sender common.Address, blkNum uint64) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, append(sender.Bytes(), hexutil.EncodeUint64(blkNum)...)).String()
+}
+
+// FlashbotsSignature returns the X-Flashbots-Signature header of body signed
+// with key: the signer's address and its signature of the body's hash.
+func FlashbotsSignature(body []byte, key *ecdsa.PrivateKey) (string, error) {
+	hash := crypto.Keccak256Hash(body).Hex()
+	sig, err := crypto.Sign(accounts.TextHash([]byte(hash)), key)
 	if err != nil {
-		slog.Error("Error marshaling transaction",
-			"error", err,
-		)
 		return "", err
 	}
+	return crypto.PubkeyToAddress(key.PublicKey).Hex() + ":" + hexutil.Encode(sig), nil
+}
+
+// ParseBundleHints builds hints from a backrun transaction hash and a comma
+// separated list of name=value builder fields. Values that are valid JSON are
+// sent as JSON, anything else as a string.
+func ParseBundleHints(backrunTxHash, extra string) (BundleHints, error) {
+	var h BundleHints
+	if backrunTxHash != "" {
+		b, err := hexutil.Decode(backrunTxHash)
+		if err != nil || len(b) != 32 {
+			return h, fmt.Errorf("invalid backrun transaction hash %q", backrunTxHash)
+		}
+		h.BackrunTxHash = hexutil.Encode(b)
+	}
+	for _, part := range strings.Split(extra, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, err := parseField(part, "bundle hint")
+		if err != nil {
+			return h, err
+		}
+		switch name {
+		case "txs", "blockNumber":
+			return h, fmt.Errorf("bundle hint %q would override the bundle itself", name)
+		}
+		if h.Extra == nil {
+			h.Extra = make(map[string]interface{})
+		}
+		h.Extra[name] = value
+	}
+	return h, nil
+}
+
+// parseField parses a name=value field of a kind of list. Values that are
+// valid JSON are decoded, anything else is kept as a string.
+func parseField(part, kind string) (string, interface{}, error) {
+	name, value, ok := strings.Cut(part, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", nil, fmt.Errorf("invalid %s %q, expected name=value", kind, part)
+	}
+	value = strings.TrimSpace(value)
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+		return name, decoded, nil
+	}
+	return name, value, nil
+}
 
-	// Encode the block number in hex.
+// bundlePayload builds the JSON-RPC request submitting signedTx for blkNum.
+func bundlePayload(binary []byte, blkNum uint64, hints BundleHints) FlashbotsPayload {
 	blockNum := hexutil.EncodeUint64(blkNum)
 
-	// Construct the Flashbots payload.
-	payload := FlashbotsPayload{
-		Jsonrpc: "2.0",
-		Method:  "eth_sendBundle",
-		Params: []map[string]interface{}{
-			{
-				"txs": []string{
-					hexutil.Encode(binary),
+	if hints.BackrunTxHash != "" {
+		// MEV-Share bundles reference the target transaction by hash and keep the body order
+		return FlashbotsPayload{
+			Jsonrpc: "2.0",
+			Method:  "mev_sendBundle",
+			Params: []map[string]interface{}{
+				{
+					"version": "v0.1",
+					"inclusion": map[string]interface{}{
+						"block": blockNum,
+					},
+					"body": []map[string]interface{}{
+						{"hash": hints.BackrunTxHash},
+						{"tx": hexutil.Encode(binary), "canRevert": false},
+					},
 				},
-				"blockNumber": blockNum,
 			},
+			ID: 1,
+		}
+	}
+
+	params := map[string]interface{}{
+		"txs": []string{
+			hexutil.Encode(binary),
 		},
-		ID: 1,
+		"blockNumber": blockNum,
+	}
+	if hints.ReplacementUUID != "" {
+		params["replacementUuid"] = hints.ReplacementUUID
+	}
+	if hints.MinTimestamp != 0 {
+		params["minTimestamp"] = hints.MinTimestamp
+	}
+	if hints.MaxTimestamp != 0 {
+		params["maxTimestamp"] = hints.MaxTimestamp
+	}
+	for k, v := range hints.Extra {
+		params[k] = v
+	}
+	return FlashbotsPayload{
+		Jsonrpc: "2.0",
+		Method:  "eth_sendBundle",
+		Params:  []map[string]interface{}{params},
+		ID:      1,
+	}
+}
+
+// BundleRequest returns the JSON-RPC request SendBundleContext posts for
+// signedTx.
+func BundleRequest(signedTx *types.Transaction, blkNum uint64, hints BundleHints) ([]byte, error) {
+	binary, err := signedTx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transaction: %w", err)
 	}
+	return json.Marshal(bundlePayload(binary, blkNum, hints))
+}
+
+// SendBundle sends a signed transaction bundle to the specified RPC URL.
+// It returns the result as a string or an error if the operation fails.
+func SendBundle(rpcurl string, signedTx *types.Transaction, blkNum uint64) (string, error) {
+	return SendBundleWithHints(rpcurl, signedTx, blkNum, BundleHints{})
+}
+
+// SendBundleWithHints is like SendBundle, but applies the placement hints.
+func SendBundleWithHints(rpcurl string, signedTx *types.Transaction, blkNum uint64, hints BundleHints) (string, error) {
+	return SendBundleContext(context.Background(), rpcurl, signedTx, blkNum, hints)
+}
 
-	// Marshal the payload into JSON.
-	payloadBytes, err := json.Marshal(payload)
+// SendBundleContext is like SendBundleWithHints, but the request is aborted
+// when ctx is canceled.
+func SendBundleContext(parent context.Context, rpcurl string, signedTx *types.Transaction, blkNum uint64, hints BundleHints) (string, error) {
+	// Construct the bundle payload from the signed transaction.
+	payloadBytes, err := BundleRequest(signedTx, blkNum, hints)
 	if err != nil {
 		slog.Error("Error marshaling payload",
 			"error", err,
 		)
 		return "", err
 	}
+	return postRequest(parent, rpcurl, payloadBytes, hints.SearchKey)
+}
 
-	// Create a context with a timeout.
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+// postRequest posts the JSON-RPC request payloadBytes to rpcurl, signed with
+// searchKey unless it is nil, and returns the result.
+func postRequest(parent context.Context, rpcurl string, payloadBytes []byte, searchKey *ecdsa.PrivateKey) (string, error) {
+	// Create a context bounded by the bundle post latency budget.
+	ctx, cancel := latency.Context(parent, latency.BundlePost)
 	defer cancel()
 
 	// Create a new HTTP POST request with the JSON payload.
@@ -88,14 +240,28 @@ func SendBundle(rpcurl string, signedTx *types.Transaction, blkNum uint64) (stri
 		return "", err
 	}
 	req.Header.Add("Content-Type", "application/json")
+	// The relay's logs can be searched for the request ID logged on failures
+	requestID := ident.NewRequestID()
+	req.Header.Set(ident.RequestIDHeader, requestID)
+	if searchKey != nil {
+		signature, err := FlashbotsSignature(payloadBytes, searchKey)
+		if err != nil {
+			slog.Error("Failed to sign bundle request",
+				"error", err,
+			)
+			return "", err
+		}
+		req.Header.Set("X-Flashbots-Signature", signature)
+	}
 
 	// Execute the HTTP request.
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		slog.Error("An error occurred during the request",
 			"error", err,
+			"requestID", requestID,
 		)
-		return "", err
+		return "", latency.Wrap(latency.BundlePost, err)
 	}
 	defer resp.Body.Close()
 
@@ -123,8 +289,9 @@ func SendBundle(rpcurl string, signedTx *types.Transaction, blkNum uint64) (stri
 		slog.Error("Received error from RPC",
 			"code", rpcResp.RPCError.Code,
 			"message", rpcResp.RPCError.Message,
+			"requestID", requestID,
 		)
-		return "", fmt.Errorf("request failed %d: %s", rpcResp.RPCError.Code, rpcResp.RPCError.Message)
+		return "", errs.Classify(fmt.Errorf("request failed %d: %s", rpcResp.RPCError.Code, rpcResp.RPCError.Message))
 	}
 
 	// Marshal the result to a string.
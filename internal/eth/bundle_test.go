@@ -0,0 +1,188 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBundleHints(t *testing.T) {
+	h, err := ParseBundleHints("", "")
+	require.NoError(t, err)
+	require.True(t, h.IsZero())
+
+	h, err = ParseBundleHints("", `position=top, revertingTxHashes=[], minTimestamp=0`)
+	require.NoError(t, err)
+	require.Equal(t, "top", h.Extra["position"])
+	require.Equal(t, []interface{}{}, h.Extra["revertingTxHashes"])
+	require.Equal(t, float64(0), h.Extra["minTimestamp"])
+
+	_, err = ParseBundleHints("", "txs=[]")
+	require.Error(t, err)
+	_, err = ParseBundleHints("0x1234", "")
+	require.Error(t, err)
+}
+
+func TestBundlePayloadHints(t *testing.T) {
+	raw := []byte{0x01, 0x02}
+
+	plain := bundlePayload(raw, 16, BundleHints{Extra: map[string]interface{}{"position": "top"}})
+	require.Equal(t, "eth_sendBundle", plain.Method)
+	require.Equal(t, "0x10", plain.Params[0]["blockNumber"])
+	require.Equal(t, "top", plain.Params[0]["position"])
+
+	target := "0x" + "ab" + "00000000000000000000000000000000000000000000000000000000000000"
+	hints, err := ParseBundleHints(target, "")
+	require.NoError(t, err)
+	backrun := bundlePayload(raw, 16, hints)
+	require.Equal(t, "mev_sendBundle", backrun.Method)
+
+	encoded, err := json.Marshal(backrun.Params[0]["body"])
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"hash":"`+target+`"},{"tx":"0x0102","canRevert":false}]`, string(encoded))
+}
+
+func TestBundleReplacementAndTimestamps(t *testing.T) {
+	sender := common.Address{1}
+	uuid := ReplacementFor(sender, 16)
+	require.Equal(t, uuid, ReplacementFor(sender, 16), "stable for resubmissions")
+	require.NotEqual(t, uuid, ReplacementFor(sender, 17))
+
+	hints := BundleHints{ReplacementUUID: uuid, MinTimestamp: 100, MaxTimestamp: 112}
+	require.False(t, hints.IsZero())
+	payload := bundlePayload([]byte{0x01}, 16, hints)
+	require.Equal(t, uuid, payload.Params[0]["replacementUuid"])
+	require.Equal(t, uint64(100), payload.Params[0]["minTimestamp"])
+	require.Equal(t, uint64(112), payload.Params[0]["maxTimestamp"])
+
+	open := bundlePayload([]byte{0x01}, 16, BundleHints{})
+	require.NotContains(t, open.Params[0], "replacementUuid")
+	require.NotContains(t, open.Params[0], "minTimestamp")
+}
+
+func TestSendBundleSigned(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	var header string
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Flashbots-Signature")
+		body, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x01"}}`))
+	}))
+	defer ts.Close()
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1})
+	_, err = SendBundleContext(context.Background(), ts.URL, tx, 16, BundleHints{SearchKey: key})
+	require.NoError(t, err)
+
+	addr, sig, ok := strings.Cut(header, ":")
+	require.True(t, ok, header)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey).Hex(), addr)
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte(crypto.Keccak256Hash(body).Hex())), hexutil.MustDecode(sig))
+	require.NoError(t, err)
+	require.Equal(t, addr, crypto.PubkeyToAddress(*pub).Hex(), "signs the request body")
+
+	_, err = SendBundleContext(context.Background(), ts.URL, tx, 16, BundleHints{})
+	require.NoError(t, err)
+	require.Empty(t, header, "unsigned without a search key")
+}
+
+func TestParseRelays(t *testing.T) {
+	relays, err := ParseRelays(" flashbots=https://relay.flashbots.net, titan=https://rpc.titanbuilder.xyz ,")
+	require.NoError(t, err)
+	require.Equal(t, []Relay{
+		{Name: "flashbots", URL: "https://relay.flashbots.net"},
+		{Name: "titan", URL: "https://rpc.titanbuilder.xyz"},
+	}, relays)
+
+	for _, list := range []string{"flashbots", "a=https://x,a=https://y", "rpc=https://x", "a=ws://x"} {
+		_, err := ParseRelays(list)
+		require.Error(t, err, list)
+	}
+
+	disabled, err := ParseDisabledRelays("rpc, titan", relays)
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"rpc": true, "titan": true}, disabled)
+	_, err = ParseDisabledRelays("bloxroute", relays)
+	require.Error(t, err)
+}
+
+func TestSendBundles(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x01"}}`))
+	}))
+	defer ok.Close()
+	low := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"nonce too low"}}`))
+	}))
+	defer low.Close()
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1})
+	results := SendBundles(context.Background(), []Relay{{Name: "a", URL: ok.URL}, {Name: "b", URL: low.URL}}, tx, 16, BundleHints{})
+	require.Len(t, results, 2)
+	require.Equal(t, "a", results[0].Relay)
+	require.NoError(t, results[0].Err)
+	require.Error(t, results[1].Err)
+	require.Equal(t, []string{"a"}, results.Accepted())
+	require.NoError(t, results.Err(), "accepted by one relay")
+
+	results = SendBundles(context.Background(), []Relay{{Name: "b", URL: low.URL}}, tx, 16, BundleHints{})
+	require.Empty(t, results.Accepted())
+	require.ErrorContains(t, results.Err(), "b: ")
+	require.Equal(t, NonceTooLow, ClassifySendError(results.Err()), "stays classifiable")
+
+	require.Error(t, BundleResults(nil).Err())
+}
+
+func TestPrivateTx(t *testing.T) {
+	m, err := ParseSubmitMethod("")
+	require.NoError(t, err)
+	require.Equal(t, SubmitBundle, m)
+	m, err = ParseSubmitMethod("private-tx")
+	require.NoError(t, err)
+	require.Equal(t, SubmitPrivateTx, m)
+	_, err = ParseSubmitMethod("raw")
+	require.Error(t, err)
+
+	prefs, err := ParsePrivateTxPreferences("fast=true, privacy={\"builders\":[\"flashbots\"]}")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"fast": true, "privacy": map[string]interface{}{"builders": []interface{}{"flashbots"}}}, prefs)
+	_, err = ParsePrivateTxPreferences("fast")
+	require.ErrorContains(t, err, `invalid private transaction preference "fast"`)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	var header string
+	var req FlashbotsPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Flashbots-Signature")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x02"}`))
+	}))
+	defer ts.Close()
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1})
+	results := SendPrivateTxs(context.Background(), []Relay{{Name: "a", URL: ts.URL}}, tx, 16, prefs, BundleHints{SearchKey: key, ReplacementUUID: "ignored"})
+	require.NoError(t, results.Err())
+	require.Equal(t, `"0x02"`, results[0].Result)
+	require.NotEmpty(t, header, "signed with the search key")
+	require.Equal(t, "eth_sendPrivateRawTransaction", req.Method)
+	require.Len(t, req.Params, 1)
+	binary, _ := tx.MarshalBinary()
+	require.Equal(t, hexutil.Encode(binary), req.Params[0]["tx"])
+	require.Equal(t, "0x10", req.Params[0]["maxBlockNumber"])
+	require.Equal(t, map[string]interface{}{"fast": true, "privacy": map[string]interface{}{"builders": []interface{}{"flashbots"}}}, req.Params[0]["preferences"])
+	require.NotContains(t, req.Params[0], "replacementUuid", "bundle hints do not apply")
+}
@@ -0,0 +1,88 @@
+package eth
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/primev/preconf_blob_bidder/internal/latency"
+)
+
+// gasHeadroomPercent is added to estimated gas limits, so state changes
+// between estimation and inclusion do not make the transaction run out of gas.
+const gasHeadroomPercent = 20
+
+// estimateGas estimates the gas limit of msg within the gas estimation budget
+// and adds headroom.
+func estimateGas(client *ethclient.Client, msg ethereum.CallMsg) (uint64, error) {
+	ctx, cancel := latency.Context(context.Background(), latency.GasEstimate)
+	defer cancel()
+	gas, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		return 0, latency.Wrap(latency.GasEstimate, err)
+	}
+	return withGasHeadroom(gas), nil
+}
+
+func withGasHeadroom(gas uint64) uint64 {
+	return gas + gas*gasHeadroomPercent/100
+}
+
+// signCallTx creates and signs a call of to with data from the signer's
+// account, with an estimated gas limit. kind names the transaction in logs.
+func signCallTx(client *Client, signer Signer, to common.Address, data []byte, offset uint64, fees FeeOracle, kind string) (*types.Transaction, uint64, error) {
+	// Get the nonce, latest header and chain ID in one round trip
+	state, err := fetchTxState(client, signer, 1, fees)
+	if err != nil {
+		slog.Default().Error("Failed to fetch block state",
+			slog.String("function", "fetchTxState"),
+			slog.Any("error", err))
+		return nil, 0, err
+	}
+	nonce, header, chainID := state.nonce, state.header, state.chainID
+	blockNumber := header.Number.Uint64()
+
+	gas, err := estimateGas(client.Client, ethereum.CallMsg{From: signer.Address(), To: &to, Data: data})
+	if err != nil {
+		slog.Default().Error("Failed to estimate gas",
+			slog.String("kind", kind),
+			slog.Any("error", err))
+		releaseNonces(signer, nonce, 1)
+		return nil, 0, err
+	}
+
+	fee, err := fees.Fees(client.Client, header, offset, signer.Address(), nonce)
+	if err != nil {
+		releaseNonces(signer, nonce, 1)
+		return nil, 0, err
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		To:        &to,
+		Gas:       gas,
+		GasFeeCap: fee.FeeCap,
+		GasTipCap: fee.Tip,
+		Data:      data,
+	})
+	signedTx, err := signer.SignTx(tx, chainID)
+	if err != nil {
+		slog.Default().Error("Failed to sign transaction",
+			slog.String("function", "SignTx"),
+			slog.Any("error", err))
+		releaseNonces(signer, nonce, 1)
+		return nil, 0, err
+	}
+
+	slog.Default().Info("Transaction created and signed",
+		slog.String("kind", kind),
+		slog.String("tx_hash", signedTx.Hash().Hex()),
+		slog.Uint64("gas", gas),
+		slog.Uint64("block_number", blockNumber))
+
+	return signedTx, blockNumber + offset, nil
+}
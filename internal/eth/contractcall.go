@@ -0,0 +1,125 @@
+package eth
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SendContractCall creates and signs a call of the contract at to with data
+// from the signer's account.
+func SendContractCall(client *Client, signer Signer, to common.Address, data []byte, offset uint64, fees FeeOracle) (*types.Transaction, uint64, error) {
+	return signCallTx(client, signer, to, data, offset, fees, "contract-call")
+}
+
+// ContractCallData returns the calldata of a contract call, either decoded
+// from calldata hex or packed from the method of the ABI file at abiPath with
+// args given as strings.
+func ContractCallData(calldata, abiPath, method string, args []string) ([]byte, error) {
+	if calldata != "" {
+		data, err := hexutil.Decode(calldata)
+		if err != nil {
+			return nil, fmt.Errorf("invalid calldata: %w", err)
+		}
+		return data, nil
+	}
+	f, err := os.Open(abiPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ABI file: %w", err)
+	}
+	defer f.Close()
+	parsed, err := abi.JSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ABI file %s: %w", abiPath, err)
+	}
+	return PackCall(parsed, method, args)
+}
+
+// PackCall packs a call of method with args converted to the method's input
+// types. Arrays, slices and tuples are not supported.
+func PackCall(parsed abi.ABI, method string, args []string) ([]byte, error) {
+	m, ok := parsed.Methods[method]
+	if !ok {
+		return nil, fmt.Errorf("method %q not found in ABI", method)
+	}
+	if len(args) != len(m.Inputs) {
+		return nil, fmt.Errorf("method %s takes %d arguments, got %d", m.Sig, len(m.Inputs), len(args))
+	}
+	values := make([]interface{}, len(args))
+	for i, input := range m.Inputs {
+		v, err := parseArg(input.Type, strings.TrimSpace(args[i]))
+		if err != nil {
+			return nil, fmt.Errorf("argument %d (%s %s): %w", i, input.Type, input.Name, err)
+		}
+		values[i] = v
+	}
+	return parsed.Pack(method, values...)
+}
+
+// parseArg converts s to the Go type the ABI encoder expects for t.
+func parseArg(t abi.Type, s string) (interface{}, error) {
+	switch t.T {
+	case abi.AddressTy:
+		if !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("invalid address %q", s)
+		}
+		return common.HexToAddress(s), nil
+	case abi.BoolTy:
+		return strconv.ParseBool(s)
+	case abi.StringTy:
+		return s, nil
+	case abi.BytesTy:
+		return hexutil.Decode(s)
+	case abi.FixedBytesTy:
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != t.Size {
+			return nil, fmt.Errorf("expected %d bytes, got %d", t.Size, len(b))
+		}
+		arr := reflect.New(t.GetType()).Elem()
+		reflect.Copy(arr, reflect.ValueOf(b))
+		return arr.Interface(), nil
+	case abi.UintTy, abi.IntTy:
+		n, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", s)
+		}
+		return integerArg(t, n)
+	default:
+		return nil, fmt.Errorf("unsupported argument type %s", t)
+	}
+}
+
+// integerArg converts n to the Go type of the integer type t: sized Go
+// integers up to 64 bits, *big.Int above.
+func integerArg(t abi.Type, n *big.Int) (interface{}, error) {
+	if t.T == abi.UintTy && n.Sign() < 0 {
+		return nil, fmt.Errorf("%s must not be negative", n)
+	}
+	bits := n.BitLen()
+	if t.T == abi.IntTy {
+		bits++ // Sign bit.
+	}
+	if bits > t.Size {
+		return nil, fmt.Errorf("%s overflows %s", n, t)
+	}
+	typ := t.GetType()
+	switch {
+	case typ == reflect.TypeOf(&big.Int{}):
+		return n, nil
+	case t.T == abi.UintTy:
+		return reflect.ValueOf(n.Uint64()).Convert(typ).Interface(), nil
+	default:
+		return reflect.ValueOf(n.Int64()).Convert(typ).Interface(), nil
+	}
+}
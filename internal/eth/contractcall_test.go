@@ -0,0 +1,52 @@
+package eth
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testABI = `[
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"type":"bool"}]},
+	{"type":"function","name":"set","inputs":[{"name":"small","type":"uint8"},{"name":"delta","type":"int64"},{"name":"flag","type":"bool"},{"name":"tag","type":"bytes4"},{"name":"note","type":"string"}],"outputs":[]}
+]`
+
+func TestContractCallData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.abi")
+	require.NoError(t, os.WriteFile(path, []byte(testABI), 0o644))
+
+	data, err := ContractCallData("", path, "transfer", []string{"0x00000000000000000000000000000000000000aa", "1000"})
+	require.NoError(t, err)
+	require.Equal(t, ERC20TransferData(common.HexToAddress("0x00000000000000000000000000000000000000aa"), big.NewInt(1000)), data, "same encoding as the ERC-20 mode")
+
+	data, err = ContractCallData("0xa9059cbb", path, "ignored", nil)
+	require.NoError(t, err)
+	require.Equal(t, "0xa9059cbb", hexutil.Encode(data), "raw calldata takes precedence")
+
+	_, err = ContractCallData("", path, "mint", nil)
+	require.ErrorContains(t, err, `method "mint" not found`)
+	_, err = ContractCallData("", path, "transfer", []string{"0xaa"})
+	require.ErrorContains(t, err, "takes 2 arguments")
+}
+
+func TestPackCallConvertsArguments(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(testABI))
+	require.NoError(t, err)
+
+	_, err = PackCall(parsed, "set", []string{"255", "-5", "true", "0x01020304", "hello"})
+	require.NoError(t, err)
+
+	_, err = PackCall(parsed, "set", []string{"256", "-5", "true", "0x01020304", "hello"})
+	require.ErrorContains(t, err, "overflows uint8")
+	_, err = PackCall(parsed, "set", []string{"1", "-5", "true", "0x0102", "hello"})
+	require.ErrorContains(t, err, "expected 4 bytes")
+	_, err = PackCall(parsed, "transfer", []string{"0x00000000000000000000000000000000000000aa", "-1"})
+	require.ErrorContains(t, err, "must not be negative")
+}
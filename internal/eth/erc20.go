@@ -0,0 +1,28 @@
+package eth
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// erc20TransferSelector is the function selector of transfer(address,uint256).
+var erc20TransferSelector = crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
+
+// ERC20TransferData returns the calldata transferring amount token base units
+// to recipient.
+func ERC20TransferData(recipient common.Address, amount *big.Int) []byte {
+	data := make([]byte, 0, 4+2*32)
+	data = append(data, erc20TransferSelector...)
+	data = append(data, common.LeftPadBytes(recipient.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+	return data
+}
+
+// SendERC20Transfer creates and signs a transfer of amount base units of the
+// ERC-20 token at token from the signer's account to recipient.
+func SendERC20Transfer(client *Client, signer Signer, token, recipient common.Address, amount *big.Int, offset uint64, fees FeeOracle) (*types.Transaction, uint64, error) {
+	return signCallTx(client, signer, token, ERC20TransferData(recipient, amount), offset, fees, "erc20")
+}
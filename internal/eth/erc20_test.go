@@ -0,0 +1,24 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestERC20TransferData(t *testing.T) {
+	recipient := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	data := ERC20TransferData(recipient, big.NewInt(1000))
+	require.Equal(t,
+		"0xa9059cbb"+
+			"00000000000000000000000000000000000000000000000000000000000000aa"+
+			"00000000000000000000000000000000000000000000000000000000000003e8",
+		hexutil.Encode(data))
+}
+
+func TestWithGasHeadroom(t *testing.T) {
+	require.Equal(t, uint64(60_000), withGasHeadroom(50_000))
+}
@@ -0,0 +1,276 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/primev/preconf_blob_bidder/internal/latency"
+	"github.com/primev/preconf_blob_bidder/internal/money"
+)
+
+// DefaultFeeHistoryBlocks is how many recent blocks FeeHistoryTip samples.
+const DefaultFeeHistoryBlocks = 20
+
+// maxFeeBumps bounds how often the tip for one nonce is bumped.
+const maxFeeBumps = 10
+
+// ErrFeeCap is returned when fees exceed a configured maximum, so the block
+// is skipped rather than bid on at any price.
+var ErrFeeCap = errors.New("fees above the configured maximum")
+
+// GasOracleKind selects how the tip of transactions is picked.
+type GasOracleKind string
+
+const (
+	// FixedGasOracle tips the configured priority fee.
+	FixedGasOracle GasOracleKind = "fixed"
+	// FeeHistoryGasOracle tips a percentile of the priority fees paid in recent
+	// blocks.
+	FeeHistoryGasOracle GasOracleKind = "fee-history"
+)
+
+// ParseGasOracle validates the kind of a gas oracle.
+func ParseGasOracle(s string) (GasOracleKind, error) {
+	switch kind := GasOracleKind(s); kind {
+	case FixedGasOracle, FeeHistoryGasOracle:
+		return kind, nil
+	}
+	return "", fmt.Errorf("unknown gas oracle %q, must be fixed or fee-history", s)
+}
+
+// Fees are the EIP-1559 fees of a transaction, in wei per gas.
+type Fees struct {
+	Tip    *big.Int // Max priority fee.
+	FeeCap *big.Int // Max fee, base fee included.
+}
+
+// FeeOracle picks the fees of transactions.
+type FeeOracle interface {
+	// Fees returns the fees of the transaction with nonce sent from from,
+	// built on header for the block offset blocks ahead.
+	Fees(client *ethclient.Client, header *types.Header, offset uint64, from common.Address, nonce uint64) (Fees, error)
+}
+
+// TipOracle suggests the priority fee for the block after header.
+type TipOracle interface {
+	Tip(client *ethclient.Client, header *types.Header) (*big.Int, error)
+}
+
+// stateBatcher is implemented by oracles whose request can ride along in the
+// block state batch of fetchState, saving it a round trip of its own.
+type stateBatcher interface {
+	// batchElem returns the request for the latest block.
+	batchElem() rpc.BatchElem
+	// storeBatch keeps the answer to the request for header, the latest
+	// block. Oracles that cannot use it make their own request later.
+	storeBatch(header *types.Header, elem rpc.BatchElem)
+}
+
+// FixedTip always tips the same amount.
+type FixedTip struct {
+	Wei *big.Int
+}
+
+// Tip returns the fixed tip.
+func (f FixedTip) Tip(*ethclient.Client, *types.Header) (*big.Int, error) {
+	return new(big.Int).Set(f.Wei), nil
+}
+
+// FeeHistoryTip tips the priority fee paid at a percentile of recent blocks'
+// transactions, as reported by eth_feeHistory: the median over the sampled
+// blocks of each block's percentile. The tip is fetched once per head block,
+// along with the block state when the oracle prices through a GasPricer.
+type FeeHistoryTip struct {
+	percentile float64
+	blocks     uint64
+	min        *big.Int
+
+	mu     sync.Mutex
+	head   common.Hash
+	cached *big.Int
+}
+
+// NewFeeHistoryTip returns a tip oracle for the percentile, between 0 and
+// 100, of the last blocks blocks. The tip is at least min, which covers runs
+// of empty blocks.
+func NewFeeHistoryTip(percentile float64, blocks uint64, min *big.Int) *FeeHistoryTip {
+	return &FeeHistoryTip{percentile: percentile, blocks: blocks, min: min}
+}
+
+// Tip returns the tip for the block after header.
+func (f *FeeHistoryTip) Tip(client *ethclient.Client, header *types.Header) (*big.Int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cached != nil && f.head == header.Hash() {
+		return new(big.Int).Set(f.cached), nil
+	}
+	ctx, cancel := latency.Context(context.Background(), latency.GasEstimate)
+	defer cancel()
+	history, err := client.FeeHistory(ctx, f.blocks, header.Number, []float64{f.percentile})
+	if err != nil {
+		return nil, fmt.Errorf("eth_feeHistory: %w", latency.Wrap(latency.GasEstimate, err))
+	}
+	tip := f.store(header, history.Reward)
+	return new(big.Int).Set(tip), nil
+}
+
+// feeHistory is the part of an eth_feeHistory answer FeeHistoryTip uses.
+type feeHistory struct {
+	OldestBlock *hexutil.Big     `json:"oldestBlock"`
+	Reward      [][]*hexutil.Big `json:"reward"`
+}
+
+// batchElem implements stateBatcher.
+func (f *FeeHistoryTip) batchElem() rpc.BatchElem {
+	return rpc.BatchElem{
+		Method: "eth_feeHistory",
+		Args:   []interface{}{hexutil.Uint64(f.blocks), "latest", []float64{f.percentile}},
+		Result: new(feeHistory),
+	}
+}
+
+// storeBatch implements stateBatcher. The history is dropped when it failed
+// or ends at another block than header, as when a block arrived in between.
+func (f *FeeHistoryTip) storeBatch(header *types.Header, elem rpc.BatchElem) {
+	history, ok := elem.Result.(*feeHistory)
+	if elem.Error != nil || !ok || history.OldestBlock == nil || len(history.Reward) == 0 {
+		return
+	}
+	newest := new(big.Int).Add(history.OldestBlock.ToInt(), big.NewInt(int64(len(history.Reward)-1)))
+	if newest.Cmp(header.Number) != 0 {
+		return
+	}
+	rewards := make([][]*big.Int, len(history.Reward))
+	for i, reward := range history.Reward {
+		for _, r := range reward {
+			rewards[i] = append(rewards[i], r.ToInt())
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.store(header, rewards)
+}
+
+// store caches the tip for the block after header from the rewards of the
+// blocks up to header, and returns it. f.mu must be held.
+func (f *FeeHistoryTip) store(header *types.Header, history [][]*big.Int) *big.Int {
+	var rewards []*big.Int
+	for _, reward := range history {
+		if len(reward) > 0 && reward[0] != nil {
+			rewards = append(rewards, reward[0])
+		}
+	}
+	tip := median(rewards)
+	if f.min != nil && tip.Cmp(f.min) < 0 {
+		tip.Set(f.min)
+	}
+	f.head, f.cached = header.Hash(), tip
+	return tip
+}
+
+// median returns the median of values, or 0 without any.
+func median(values []*big.Int) *big.Int {
+	if len(values) == 0 {
+		return new(big.Int)
+	}
+	sorted := append([]*big.Int(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	return new(big.Int).Set(sorted[len(sorted)/2])
+}
+
+// GasPricer prices transactions with the tips of a TipOracle. The fee cap
+// covers the base fee of the target block plus the tip. A nonce priced again
+// in a later block, because its transaction did not land, tips bump times
+// more each time. Blocks whose base fee exceeds maxBaseFee are refused with
+// ErrFeeCap.
+type GasPricer struct {
+	tips       TipOracle
+	maxBaseFee *big.Int
+	bump       float64
+
+	mu      sync.Mutex
+	repeats map[common.Address]nonceRepeats
+}
+
+// nonceRepeats counts how often a nonce was priced in a row.
+type nonceRepeats struct {
+	nonce uint64
+	count int
+}
+
+// NewGasPricer returns a pricer tipping with tips. A nil maxBaseFee allows
+// any base fee; a bump of 1 never bumps.
+func NewGasPricer(tips TipOracle, maxBaseFee *big.Int, bump float64) *GasPricer {
+	return &GasPricer{
+		tips:       tips,
+		maxBaseFee: maxBaseFee,
+		bump:       bump,
+		repeats:    make(map[common.Address]nonceRepeats),
+	}
+}
+
+// FixedTipFees returns a pricer tipping tipGwei, without a base fee maximum
+// or bumps.
+func FixedTipFees(tipGwei uint64) *GasPricer {
+	return NewGasPricer(FixedTip{Wei: GweiToWei(float64(tipGwei))}, nil, 1)
+}
+
+// GweiToWei converts a gwei amount to wei, truncating fractions of a wei.
+// See money.FromGwei.
+func GweiToWei(gwei float64) *big.Int {
+	return money.FromGwei(gwei)
+}
+
+// batcher returns the stateBatcher of the pricer's tip oracle, or nil.
+func (p *GasPricer) batcher() stateBatcher {
+	b, _ := p.tips.(stateBatcher)
+	return b
+}
+
+// Fees implements FeeOracle.
+func (p *GasPricer) Fees(client *ethclient.Client, header *types.Header, offset uint64, from common.Address, nonce uint64) (Fees, error) {
+	if p.maxBaseFee != nil && header.BaseFee.Cmp(p.maxBaseFee) > 0 {
+		return Fees{}, fmt.Errorf("%w: base fee of %s wei exceeds %s wei", ErrFeeCap, header.BaseFee, p.maxBaseFee)
+	}
+	tip, err := p.tips.Tip(client, header)
+	if err != nil {
+		return Fees{}, err
+	}
+	tip = bumpTip(tip, p.bump, p.repeat(from, nonce))
+	return Fees{
+		Tip:    tip,
+		FeeCap: new(big.Int).Add(feeHeadroom(header.BaseFee, offset), tip),
+	}, nil
+}
+
+// repeat returns how often nonce of from was priced right before.
+func (p *GasPricer) repeat(from common.Address, nonce uint64) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r, seen := p.repeats[from]
+	if seen && r.nonce == nonce {
+		r.count++
+	} else {
+		r = nonceRepeats{nonce: nonce}
+	}
+	p.repeats[from] = r
+	return r.count
+}
+
+// bumpTip multiplies tip by bump for each of count repeats, at most
+// maxFeeBumps times.
+func bumpTip(tip *big.Int, bump float64, count int) *big.Int {
+	if bump <= 1 || count == 0 {
+		return tip
+	}
+	return money.ScaleN(tip, bump, min(count, maxFeeBumps))
+}
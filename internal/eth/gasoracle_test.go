@@ -0,0 +1,78 @@
+package eth
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeHistoryTipTakesMedianPercentile(t *testing.T) {
+	srv := &rpcServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	client, err := ethclient.Dial(ts.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	header := &types.Header{Number: big.NewInt(100), BaseFee: big.NewInt(1e9)}
+	tips := NewFeeHistoryTip(50, 3, big.NewInt(1))
+	tip, err := tips.Tip(client, header)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(5), tip, "median of the rewards 3, 9 and 5")
+
+	_, err = tips.Tip(client, header)
+	require.NoError(t, err)
+	require.Len(t, srv.requests, 1, "fee history is fetched once per head")
+
+	tip, err = NewFeeHistoryTip(50, 3, big.NewInt(7)).Tip(client, header)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(7), tip, "the tip is at least the minimum")
+}
+
+func TestGasPricerBumpsRepeatedNonces(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(100), BaseFee: big.NewInt(1000)}
+	from := common.Address{1}
+	p := NewGasPricer(FixedTip{Wei: big.NewInt(100)}, nil, 1.5)
+
+	fees, err := p.Fees(nil, header, 2, from, 7)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(100), fees.Tip)
+	require.Equal(t, big.NewInt(1125+100), fees.FeeCap)
+
+	fees, err = p.Fees(nil, header, 2, from, 7)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(150), fees.Tip, "the nonce did not land, tip more")
+	fees, err = p.Fees(nil, header, 2, from, 7)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(225), fees.Tip)
+
+	fees, err = p.Fees(nil, header, 2, from, 8)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(100), fees.Tip, "a new nonce starts over")
+	fees, err = p.Fees(nil, header, 2, common.Address{2}, 8)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(100), fees.Tip, "accounts are bumped separately")
+
+	require.Equal(t, big.NewInt(5766), bumpTip(big.NewInt(100), 1.5, 20), "bumps are bounded")
+}
+
+func TestGasPricerRefusesHighBaseFees(t *testing.T) {
+	p := NewGasPricer(FixedTip{Wei: big.NewInt(1)}, GweiToWei(10), 1)
+	_, err := p.Fees(nil, &types.Header{Number: big.NewInt(100), BaseFee: GweiToWei(10)}, 1, common.Address{1}, 0)
+	require.NoError(t, err)
+	_, err = p.Fees(nil, &types.Header{Number: big.NewInt(100), BaseFee: GweiToWei(10.5)}, 1, common.Address{1}, 0)
+	require.ErrorIs(t, err, ErrFeeCap)
+}
+
+func TestParseGasOracle(t *testing.T) {
+	kind, err := ParseGasOracle("fee-history")
+	require.NoError(t, err)
+	require.Equal(t, FeeHistoryGasOracle, kind)
+	_, err = ParseGasOracle("eip-1559")
+	require.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package eth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+var (
+	kzgMu sync.RWMutex
+	// kzgContext holds a trusted setup loaded from a file; nil uses the one
+	// embedded in go-ethereum.
+	kzgContext *gokzg4844.Context
+)
+
+// InitKZG initializes the KZG trusted setup that blob commitments and proofs
+// are computed with, and returns how long it took. The setup is read from the
+// JSON file at path, in the format of the consensus specs, or the one built
+// into the binary is used when path is empty. Without it, the setup is
+// initialized by the first blob transaction built, delaying its bid.
+func InitKZG(path string) (time.Duration, error) {
+	start := time.Now()
+	if path == "" {
+		// go-ethereum loads its embedded setup on first use
+		var blob kzg4844.Blob
+		if _, err := kzg4844.BlobToCommitment(&blob); err != nil {
+			return 0, fmt.Errorf("failed to initialize the embedded KZG trusted setup: %w", err)
+		}
+		return time.Since(start), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read KZG trusted setup: %w", err)
+	}
+	setup := new(gokzg4844.JSONTrustedSetup)
+	if err := json.Unmarshal(data, setup); err != nil {
+		return 0, fmt.Errorf("invalid KZG trusted setup %s: %w", path, err)
+	}
+	ctx, err := gokzg4844.NewContext4096(setup)
+	if err != nil {
+		return 0, fmt.Errorf("invalid KZG trusted setup %s: %w", path, err)
+	}
+	kzgMu.Lock()
+	kzgContext = ctx
+	kzgMu.Unlock()
+	return time.Since(start), nil
+}
+
+// blobCommitment returns the commitment of blob and its proof.
+func blobCommitment(blob *kzg4844.Blob) (kzg4844.Commitment, kzg4844.Proof, error) {
+	kzgMu.RLock()
+	ctx := kzgContext
+	kzgMu.RUnlock()
+	if ctx == nil {
+		c, err := kzg4844.BlobToCommitment(blob)
+		if err != nil {
+			return c, kzg4844.Proof{}, err
+		}
+		p, err := kzg4844.ComputeBlobProof(blob, c)
+		return c, p, err
+	}
+	c, err := ctx.BlobToKZGCommitment((*gokzg4844.Blob)(blob), 0)
+	if err != nil {
+		return kzg4844.Commitment{}, kzg4844.Proof{}, err
+	}
+	p, err := ctx.ComputeBlobKZGProof((*gokzg4844.Blob)(blob), c, 0)
+	return kzg4844.Commitment(c), kzg4844.Proof(p), err
+}
@@ -0,0 +1,30 @@
+package eth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitKZG(t *testing.T) {
+	took, err := InitKZG("")
+	require.NoError(t, err)
+	require.Positive(t, took)
+
+	_, err = InitKZG(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorContains(t, err, "failed to read KZG trusted setup")
+
+	short := filepath.Join(t.TempDir(), "short.json")
+	require.NoError(t, os.WriteFile(short, []byte(`{"g2_monomial":["0x00"]}`), 0o644))
+	_, err = InitKZG(short)
+	require.ErrorContains(t, err, "invalid KZG trusted setup")
+
+	// Failed loads keep the embedded setup
+	blob := randBlob()
+	c, p, err := blobCommitment(&blob)
+	require.NoError(t, err)
+	require.NoError(t, kzg4844.VerifyBlobProof(&blob, c, p))
+}
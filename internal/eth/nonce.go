@@ -0,0 +1,157 @@
+package eth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultNonceResync is how long locally issued nonces are trusted before the
+// pending nonce is fetched from the node again.
+const DefaultNonceResync = time.Minute
+
+// NonceManager hands out account nonces from local state. The node's pending
+// nonce does not count transactions only sent to builders or providers, so a
+// transaction built while an earlier one is still in flight would otherwise
+// reuse its nonce. The pending nonce is only fetched to sync: on first use,
+// after Reset and once the resync interval has passed.
+type NonceManager struct {
+	resync time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	accounts map[common.Address]*accountNonces
+}
+
+// accountNonces are the nonces of one account.
+type accountNonces struct {
+	mu       sync.Mutex // Held while syncing, so an account syncs once at a time.
+	next     uint64     // Next nonce to issue, never one in flight.
+	syncedAt time.Time
+	inflight map[uint64]bool // Issued nonces not settled yet.
+}
+
+// NewNonceManager returns a nonce manager syncing with the node every resync.
+func NewNonceManager(resync time.Duration) *NonceManager {
+	return &NonceManager{
+		resync:   resync,
+		now:      time.Now,
+		accounts: make(map[common.Address]*accountNonces),
+	}
+}
+
+func (m *NonceManager) account(address common.Address) *accountNonces {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.accounts[address]
+	if !ok {
+		a = &accountNonces{inflight: make(map[uint64]bool)}
+		m.accounts[address] = a
+	}
+	return a
+}
+
+// Reserve issues count consecutive nonces of address and returns the first.
+// pending fetches the node's pending nonce when the account has to sync.
+func (m *NonceManager) Reserve(address common.Address, count int, pending func() (uint64, error)) (uint64, error) {
+	a := m.account(address)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if now := m.now(); a.syncedAt.IsZero() || now.Sub(a.syncedAt) >= m.resync {
+		nonce, err := pending()
+		if err != nil {
+			return 0, err
+		}
+		a.sync(nonce, now)
+	}
+	first := a.next
+	for i := 0; i < count; i++ {
+		// A nonce reissued after Settle may be followed by nonces still in
+		// flight; issuing them again would replace those transactions.
+		if a.inflight[first+uint64(i)] {
+			first += uint64(i) + 1
+			i = -1
+		}
+	}
+	for i := 0; i < count; i++ {
+		a.inflight[first+uint64(i)] = true
+	}
+	for a.inflight[a.next] {
+		a.next++
+	}
+	return first, nil
+}
+
+// sync continues from the pending nonce, after the nonces in flight that
+// directly follow it. Nonces in flight past a gap can never be included, so
+// the gap is filled first.
+func (a *accountNonces) sync(pending uint64, now time.Time) {
+	for nonce := range a.inflight {
+		if nonce < pending {
+			delete(a.inflight, nonce)
+		}
+	}
+	a.next = pending
+	for a.inflight[a.next] {
+		a.next++
+	}
+	a.syncedAt = now
+}
+
+// Settle reports whether the transaction with nonce of address landed, i.e.
+// received a commitment. A nonce that did not land is issued again, as its
+// successors in flight cannot be included before it; those successors stay
+// in flight and are skipped by the following reservations.
+func (m *NonceManager) Settle(address common.Address, nonce uint64, landed bool) {
+	a := m.account(address)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.inflight, nonce)
+	switch {
+	case landed && nonce >= a.next:
+		a.next = nonce + 1
+	case !landed && nonce < a.next:
+		a.next = nonce
+	}
+}
+
+// Adopt takes over the transactions of address that a previous run left in
+// the mempool: the account syncs from pending, the node's pending nonce, and
+// continues after the adopted nonces that directly follow it. They are not
+// kept in flight, so once dropped from the mempool the next sync issues their
+// nonces again.
+func (m *NonceManager) Adopt(address common.Address, pending uint64, nonces []uint64) {
+	a := m.account(address)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sync(pending, m.now())
+	adopted := make(map[uint64]bool, len(nonces))
+	for _, nonce := range nonces {
+		adopted[nonce] = true
+	}
+	for adopted[a.next] || a.inflight[a.next] {
+		a.next++
+	}
+}
+
+// Reset makes the next Reserve for address sync with the node, e.g. after a
+// transaction was rejected because its nonce was already used.
+func (m *NonceManager) Reset(address common.Address) {
+	a := m.account(address)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.syncedAt = time.Time{}
+}
+
+// Manage returns signer with its nonces handed out by m.
+func (m *NonceManager) Manage(signer Signer) Signer {
+	return &managedSigner{Signer: signer, nonces: m}
+}
+
+// managedSigner is a Signer whose transactions take their nonces from a
+// NonceManager.
+type managedSigner struct {
+	Signer
+	nonces *NonceManager
+}
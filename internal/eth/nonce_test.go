@@ -0,0 +1,137 @@
+package eth
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonceManager(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	m := NewNonceManager(time.Minute)
+	m.now = func() time.Time { return now }
+	addr := common.Address{1}
+	fetches := 0
+	chain := uint64(5)
+	pending := func() (uint64, error) {
+		fetches++
+		return chain, nil
+	}
+	reserve := func(count int) uint64 {
+		nonce, err := m.Reserve(addr, count, pending)
+		require.NoError(t, err)
+		return nonce
+	}
+
+	require.Equal(t, uint64(5), reserve(1))
+	require.Equal(t, uint64(6), reserve(1), "the first transaction is still in flight")
+	require.Equal(t, uint64(7), reserve(2))
+	require.Equal(t, 1, fetches)
+
+	m.Settle(addr, 5, true)
+	m.Settle(addr, 6, false)
+	require.Equal(t, uint64(6), reserve(1), "the gap is filled before the nonces in flight past it")
+
+	// The node has seen 5 and 6 by the next sync; 7 and 8 are still in flight
+	now = now.Add(time.Minute)
+	chain = 7
+	require.Equal(t, uint64(9), reserve(1))
+	require.Equal(t, 2, fetches)
+
+	m.Reset(addr)
+	chain = 12
+	require.Equal(t, uint64(12), reserve(1), "nonces below the pending nonce were used elsewhere")
+	m.Settle(addr, 14, true)
+	require.Equal(t, uint64(15), reserve(1))
+
+	m.Reset(addr)
+	_, err := m.Reserve(addr, 1, func() (uint64, error) { return 0, errors.New("unavailable") })
+	require.ErrorContains(t, err, "unavailable")
+}
+
+func TestNonceManagerSkipsNoncesInFlight(t *testing.T) {
+	m := NewNonceManager(time.Minute)
+	addr := common.Address{1}
+	pending := func() (uint64, error) { return 5, nil }
+	reserve := func(count int) uint64 {
+		nonce, err := m.Reserve(addr, count, pending)
+		require.NoError(t, err)
+		return nonce
+	}
+
+	require.Equal(t, uint64(5), reserve(1))
+	require.Equal(t, uint64(6), reserve(1))
+	m.Settle(addr, 5, false)
+	require.Equal(t, uint64(5), reserve(1), "the nonce that did not land is issued again")
+	require.Equal(t, uint64(7), reserve(1), "6 is still in flight")
+
+	// A run of nonces is reserved past those in flight, leaving the gap for
+	// the next single reservation
+	m.Settle(addr, 5, false)
+	require.Equal(t, uint64(8), reserve(2))
+	require.Equal(t, uint64(5), reserve(1))
+	require.Equal(t, uint64(10), reserve(1))
+}
+
+func TestNonceManagerAdopt(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	m := NewNonceManager(time.Minute)
+	m.now = func() time.Time { return now }
+	addr := common.Address{1}
+	fetches := 0
+	pending := func() (uint64, error) {
+		fetches++
+		return 5, nil
+	}
+
+	// 5 and 6 are queued by a previous run, 9 past a gap
+	m.Adopt(addr, 5, []uint64{5, 6, 9})
+	nonce, err := m.Reserve(addr, 1, pending)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), nonce)
+	require.Zero(t, fetches, "adopting synced the account")
+
+	// Dropped from the mempool, the adopted nonces are issued again
+	now = now.Add(time.Minute)
+	m.Settle(addr, 7, false)
+	nonce, err = m.Reserve(addr, 1, pending)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), nonce)
+}
+
+func TestFetchTxStateUsesNonceManager(t *testing.T) {
+	srv := &rpcServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	conn, err := ethclient.Dial(ts.URL)
+	require.NoError(t, err)
+	client := NewClient(conn)
+	defer client.Close()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewNonceManager(time.Minute).Manage(NewLocalSigner(key))
+
+	state, err := fetchTxState(client, signer, 2, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), state.nonce)
+	state, err = fetchTxState(client, signer, 1, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(9), state.nonce)
+	releaseNonces(signer, 9, 1)
+	state, err = fetchTxState(client, signer, 1, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(9), state.nonce)
+
+	require.Equal(t, [][]string{
+		{"eth_getBlockByNumber", "eth_chainId"},
+		{"eth_getTransactionCount"},
+		{"eth_getBlockByNumber"},
+		{"eth_getBlockByNumber"},
+	}, srv.requests, "the pending nonce is only fetched to sync")
+}
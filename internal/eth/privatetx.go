@@ -0,0 +1,92 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SubmitMethod is how transactions are submitted to relays.
+type SubmitMethod string
+
+// Submit methods.
+const (
+	SubmitBundle    SubmitMethod = "bundle"     // eth_sendBundle, or mev_sendBundle when backrunning.
+	SubmitPrivateTx SubmitMethod = "private-tx" // eth_sendPrivateRawTransaction.
+)
+
+// ParseSubmitMethod parses a submit method, bundle when empty.
+func ParseSubmitMethod(s string) (SubmitMethod, error) {
+	switch m := SubmitMethod(strings.TrimSpace(s)); m {
+	case "":
+		return SubmitBundle, nil
+	case SubmitBundle, SubmitPrivateTx:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown submit method %q, expected %s or %s", s, SubmitBundle, SubmitPrivateTx)
+}
+
+// ParsePrivateTxPreferences parses a comma separated list of name=value
+// preferences of private transactions, e.g. fast=true. Values that are valid
+// JSON are sent as JSON, anything else as a string.
+func ParsePrivateTxPreferences(list string) (map[string]interface{}, error) {
+	var prefs map[string]interface{}
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, err := parseField(part, "private transaction preference")
+		if err != nil {
+			return nil, err
+		}
+		if prefs == nil {
+			prefs = make(map[string]interface{})
+		}
+		prefs[name] = value
+	}
+	return prefs, nil
+}
+
+// PrivateTxRequest returns the JSON-RPC request SendPrivateTxContext posts
+// for signedTx: the transaction is kept private and dropped after
+// maxBlockNumber.
+func PrivateTxRequest(signedTx *types.Transaction, maxBlockNumber uint64, prefs map[string]interface{}) ([]byte, error) {
+	binary, err := signedTx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+	params := map[string]interface{}{
+		"tx":             hexutil.Encode(binary),
+		"maxBlockNumber": hexutil.EncodeUint64(maxBlockNumber),
+	}
+	if len(prefs) > 0 {
+		params["preferences"] = prefs
+	}
+	return json.Marshal(FlashbotsPayload{
+		Jsonrpc: "2.0",
+		Method:  "eth_sendPrivateRawTransaction",
+		Params:  []map[string]interface{}{params},
+		ID:      1,
+	})
+}
+
+// SendPrivateTxContext submits signedTx to rpcurl with
+// eth_sendPrivateRawTransaction, for relays that take single private
+// transactions rather than bundles. The request is signed with the search
+// key of hints, if any; the other hints only apply to bundles.
+func SendPrivateTxContext(ctx context.Context, rpcurl string, signedTx *types.Transaction, maxBlockNumber uint64, prefs map[string]interface{}, hints BundleHints) (string, error) {
+	payloadBytes, err := PrivateTxRequest(signedTx, maxBlockNumber, prefs)
+	if err != nil {
+		slog.Error("Error marshaling payload",
+			"error", err,
+		)
+		return "", err
+	}
+	return postRequest(ctx, rpcurl, payloadBytes, hints.SearchKey)
+}
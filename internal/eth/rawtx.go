@@ -0,0 +1,161 @@
+package eth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	errs "github.com/primev/preconf_blob_bidder/internal/errors"
+	"github.com/primev/preconf_blob_bidder/internal/latency"
+)
+
+type rawTxPayload struct {
+	Jsonrpc string   `json:"jsonrpc"`
+	Method  string   `json:"method"`
+	Params  []string `json:"params"`
+	ID      int      `json:"id"`
+}
+
+// RawTransactionRequest returns the JSON-RPC request
+// SendRawTransactionContext posts for signedTx.
+func RawTransactionRequest(signedTx *types.Transaction) ([]byte, error) {
+	binary, err := signedTx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+	return json.Marshal(rawTxPayload{
+		Jsonrpc: "2.0",
+		Method:  "eth_sendRawTransaction",
+		Params:  []string{hexutil.Encode(binary)},
+		ID:      1,
+	})
+}
+
+// SendRawTransactionContext submits signedTx with eth_sendRawTransaction to
+// rpcurl, e.g. the mev-commit preconf RPC, which bids for the transaction on
+// the sender's behalf. It returns the transaction hash reported by the
+// endpoint.
+func SendRawTransactionContext(parent context.Context, rpcurl string, signedTx *types.Transaction) (common.Hash, error) {
+	payloadBytes, err := RawTransactionRequest(signedTx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	ctx, cancel := latency.Context(parent, latency.RawTxPost)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rpcurl, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return common.Hash{}, latency.Wrap(latency.RawTxPost, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var rpcResp JSONRPCResponse
+	if err := json.Unmarshal(body, &rpcResp); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if rpcResp.RPCError.Code != 0 {
+		return common.Hash{}, errs.Classify(fmt.Errorf("request failed %d: %s", rpcResp.RPCError.Code, rpcResp.RPCError.Message))
+	}
+	var hash common.Hash
+	if err := json.Unmarshal(rpcResp.Result, &hash); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid transaction hash in response: %w", err)
+	}
+	return hash, nil
+}
+
+// ErrRawTxsExhausted is returned once every pre-signed transaction was bid on.
+var ErrRawTxsExhausted = errors.New("no pre-signed transactions left")
+
+// ReadRawTransactions decodes pre-signed transactions, one hex encoded
+// transaction per line as returned by eth_signTransaction. Blank lines and
+// lines starting with # are skipped. Every transaction must come from the same
+// sender, which is returned.
+func ReadRawTransactions(r io.Reader) ([]*types.Transaction, common.Address, error) {
+	var (
+		txs    []*types.Transaction
+		sender common.Address
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024) // Blob transactions with sidecars are large.
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		raw, err := hexutil.Decode(text)
+		if err != nil {
+			return nil, sender, fmt.Errorf("line %d: %w", line, err)
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, sender, fmt.Errorf("line %d: invalid transaction: %w", line, err)
+		}
+		from, err := txSender(tx)
+		if err != nil {
+			return nil, sender, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(txs) == 0 {
+			sender = from
+		} else if from != sender {
+			return nil, sender, fmt.Errorf("line %d: sent from %s, but the first transaction from %s; all must share one sender", line, from.Hex(), sender.Hex())
+		}
+		txs = append(txs, tx)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, sender, err
+	}
+	if len(txs) == 0 {
+		return nil, sender, errors.New("no transactions found")
+	}
+	return txs, sender, nil
+}
+
+// txSender recovers the sender of a signed transaction.
+func txSender(tx *types.Transaction) (common.Address, error) {
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.LatestSignerForChainID(tx.ChainId())
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signature: %w", err)
+	}
+	return from, nil
+}
+
+// NextRawTransaction returns the first of the pre-signed txs whose nonce the
+// sender has not used yet, so each transaction is bid on block after block
+// until it lands and then the next one is. The target block is offset blocks
+// ahead of the head. ErrRawTxsExhausted is returned once every nonce is used.
+func NextRawTransaction(client *Client, sender common.Address, txs []*types.Transaction, offset uint64) (*types.Transaction, uint64, error) {
+	state, err := fetchBlockState(client, sender)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, tx := range txs {
+		if tx.Nonce() >= state.nonce {
+			return tx, state.header.Number.Uint64() + offset, nil
+		}
+	}
+	return nil, 0, ErrRawTxsExhausted
+}
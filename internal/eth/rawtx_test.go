@@ -0,0 +1,76 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendRawTransactionContext(t *testing.T) {
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(17000), Nonce: 3, Gas: 21000})
+	raw, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	var got rawTxPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"` + tx.Hash().Hex() + `"}`))
+	}))
+	defer srv.Close()
+
+	hash, err := SendRawTransactionContext(context.Background(), srv.URL, tx)
+	require.NoError(t, err)
+	require.Equal(t, tx.Hash(), hash)
+	require.Equal(t, "eth_sendRawTransaction", got.Method)
+	require.Equal(t, []string{hexutil.Encode(raw)}, got.Params)
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"nonce too low"}}`))
+	})
+	hash, err = SendRawTransactionContext(context.Background(), srv.URL, tx)
+	require.ErrorContains(t, err, "nonce too low")
+	require.Equal(t, common.Hash{}, hash)
+}
+
+func TestReadRawTransactions(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(17000))
+	var lines []string
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx := types.MustSignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(17000), Nonce: nonce, Gas: 21000})
+		raw, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		lines = append(lines, hexutil.Encode(raw))
+	}
+
+	txs, sender, err := ReadRawTransactions(strings.NewReader("# exported\n" + lines[0] + "\n\n" + lines[1] + "\n"))
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	require.Equal(t, uint64(1), txs[1].Nonce())
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), sender)
+
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	foreign := types.MustSignNewTx(other, signer, &types.DynamicFeeTx{ChainID: big.NewInt(17000), Gas: 21000})
+	raw, err := foreign.MarshalBinary()
+	require.NoError(t, err)
+	_, _, err = ReadRawTransactions(strings.NewReader(lines[0] + "\n" + hexutil.Encode(raw)))
+	require.ErrorContains(t, err, "line 2")
+	require.ErrorContains(t, err, "share one sender")
+
+	_, _, err = ReadRawTransactions(strings.NewReader("0xzz"))
+	require.ErrorContains(t, err, "line 1")
+	_, _, err = ReadRawTransactions(strings.NewReader("\n# nothing\n"))
+	require.ErrorContains(t, err, "no transactions found")
+}
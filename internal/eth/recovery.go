@@ -0,0 +1,204 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/holiman/uint256"
+	errs "github.com/primev/preconf_blob_bidder/internal/errors"
+	"github.com/primev/preconf_blob_bidder/internal/latency"
+)
+
+// SendError is a class of transaction submission errors that can be
+// recovered from by rebuilding the transaction.
+type SendError string
+
+const (
+	// NonceTooLow means the account already used the nonce, usually because
+	// the pending nonce was read from a node that had not seen the last
+	// transaction yet.
+	NonceTooLow SendError = "nonce-too-low"
+	// Underpriced means a transaction with the same nonce is pending and the
+	// new one does not pay enough more to replace it.
+	Underpriced SendError = "replacement-underpriced"
+)
+
+// ClassifySendError returns the recoverable class of err as reported by
+// nodes, builders and the preconf RPC, or "" if err is not recoverable.
+func ClassifySendError(err error) SendError {
+	switch {
+	case errs.Is(err, errs.ErrNonceTooLow):
+		return NonceTooLow
+	case errs.Is(err, errs.ErrUnderpriced):
+		return Underpriced
+	}
+	return ""
+}
+
+// Fee bumps a replacement needs over the transaction it replaces, in percent,
+// as enforced by geth's transaction and blob pools.
+const (
+	replacementBump     = 10
+	blobReplacementBump = 100
+)
+
+// MaxRecoveries bounds how often one transaction is rebuilt.
+const MaxRecoveries = 2
+
+// Recovery rebuilds transactions whose submission failed with a recoverable
+// SendError, until Deadline, e.g. the start of the target block's slot after
+// which the transaction would be bid on too late.
+type Recovery struct {
+	Client   *ethclient.Client
+	Signer   Signer
+	Deadline time.Time
+}
+
+// Recover returns a replacement for tx, whose submission failed with err: for
+// NonceTooLow it is re-signed with the account's pending nonce, synced into
+// the signer's NonceManager if it has one, for
+// Underpriced with fees bumped enough to replace the pending transaction.
+// It returns an error if err is not recoverable or the deadline has passed.
+func (r *Recovery) Recover(ctx context.Context, tx *types.Transaction, err error) (*types.Transaction, error) {
+	kind := ClassifySendError(err)
+	switch {
+	case r == nil || r.Signer == nil || kind == "":
+		return nil, err
+	case !r.Deadline.IsZero() && time.Now().After(r.Deadline):
+		return nil, fmt.Errorf("no time left in the slot to recover: %w", err)
+	case tx.Type() != types.DynamicFeeTxType && tx.Type() != types.BlobTxType:
+		return nil, fmt.Errorf("cannot rebuild %d type transaction: %w", tx.Type(), err)
+	}
+	chainID := tx.ChainId()
+	var (
+		replacement types.TxData
+		nonce       uint64
+		reserved    bool // The nonce was reserved from a NonceManager.
+	)
+	switch kind {
+	case NonceTooLow:
+		pending := func() (uint64, error) {
+			ctx, cancel := latency.Context(ctx, latency.NonceFetch)
+			defer cancel()
+			nonce, err := r.Client.PendingNonceAt(ctx, r.Signer.Address())
+			if err != nil {
+				return 0, latency.Wrap(latency.NonceFetch, err)
+			}
+			if nonce <= tx.Nonce() {
+				// The node still lags behind the one that rejected the transaction
+				nonce = tx.Nonce() + 1
+			}
+			return nonce, nil
+		}
+		var nerr error
+		if managed, ok := r.Signer.(*managedSigner); ok {
+			// The manager's nonces are stale, sync them and take the next one
+			managed.nonces.Reset(r.Signer.Address())
+			nonce, nerr = managed.nonces.Reserve(r.Signer.Address(), 1, pending)
+			reserved = true
+		} else {
+			nonce, nerr = pending()
+		}
+		if nerr != nil {
+			return nil, nerr
+		}
+		replacement = withNonce(tx, nonce)
+	case Underpriced:
+		replacement = withBumpedFees(tx)
+	}
+	signed, serr := r.Signer.SignTx(types.NewTx(replacement), chainID)
+	if serr != nil {
+		if reserved {
+			releaseNonces(r.Signer, nonce, 1)
+		}
+		return nil, serr
+	}
+	slog.Info("Rebuilt transaction after submission error",
+		"error", kind,
+		"txHash", tx.Hash().Hex(),
+		"replacement", signed.Hash().Hex(),
+		"nonce", signed.Nonce(),
+	)
+	return signed, nil
+}
+
+// withNonce returns the data of tx with nonce, or nil for other than dynamic
+// fee and blob transactions.
+func withNonce(tx *types.Transaction, nonce uint64) types.TxData {
+	switch tx.Type() {
+	case types.DynamicFeeTxType:
+		data := dynamicFeeTx(tx)
+		data.Nonce = nonce
+		return data
+	case types.BlobTxType:
+		data := blobTx(tx)
+		data.Nonce = nonce
+		return data
+	}
+	return nil
+}
+
+// withBumpedFees returns the data of tx with fees high enough to replace it,
+// or nil for other than dynamic fee and blob transactions.
+func withBumpedFees(tx *types.Transaction) types.TxData {
+	switch tx.Type() {
+	case types.DynamicFeeTxType:
+		data := dynamicFeeTx(tx)
+		data.GasTipCap = bump(data.GasTipCap, replacementBump)
+		data.GasFeeCap = bump(data.GasFeeCap, replacementBump)
+		return data
+	case types.BlobTxType:
+		data := blobTx(tx)
+		data.GasTipCap = uint256.MustFromBig(bump(data.GasTipCap.ToBig(), replacementBump))
+		data.GasFeeCap = uint256.MustFromBig(bump(data.GasFeeCap.ToBig(), replacementBump))
+		data.BlobFeeCap = uint256.MustFromBig(bump(data.BlobFeeCap.ToBig(), blobReplacementBump))
+		return data
+	}
+	return nil
+}
+
+// bump raises fee by percent, rounding up and by at least 1 wei.
+func bump(fee *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+percent))
+	bumped.Add(bumped, big.NewInt(99)).Div(bumped, big.NewInt(100))
+	if bumped.Cmp(fee) <= 0 {
+		bumped.Add(fee, big.NewInt(1))
+	}
+	return bumped
+}
+
+func dynamicFeeTx(tx *types.Transaction) *types.DynamicFeeTx {
+	return &types.DynamicFeeTx{
+		ChainID:    tx.ChainId(),
+		Nonce:      tx.Nonce(),
+		GasTipCap:  tx.GasTipCap(),
+		GasFeeCap:  tx.GasFeeCap(),
+		Gas:        tx.Gas(),
+		To:         tx.To(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+}
+
+func blobTx(tx *types.Transaction) *types.BlobTx {
+	return &types.BlobTx{
+		ChainID:    uint256.MustFromBig(tx.ChainId()),
+		Nonce:      tx.Nonce(),
+		GasTipCap:  uint256.MustFromBig(tx.GasTipCap()),
+		GasFeeCap:  uint256.MustFromBig(tx.GasFeeCap()),
+		Gas:        tx.Gas(),
+		To:         *tx.To(),
+		Value:      uint256.MustFromBig(tx.Value()),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+		BlobFeeCap: uint256.MustFromBig(tx.BlobGasFeeCap()),
+		BlobHashes: tx.BlobHashes(),
+		Sidecar:    tx.BlobTxSidecar(),
+	}
+}
@@ -0,0 +1,82 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// nonceAPI serves eth_getTransactionCount with a fixed pending nonce.
+type nonceAPI struct{ nonce uint64 }
+
+func (n nonceAPI) GetTransactionCount(common.Address, string) hexutil.Uint64 {
+	return hexutil.Uint64(n.nonce)
+}
+
+func TestClassifySendError(t *testing.T) {
+	require.Equal(t, NonceTooLow, ClassifySendError(errors.New("request failed -32000: nonce too low: next nonce 5, tx nonce 4")))
+	require.Equal(t, Underpriced, ClassifySendError(errors.New("replacement transaction underpriced")))
+	require.Equal(t, Underpriced, ClassifySendError(errors.New("Replacement underpriced")))
+	require.Empty(t, ClassifySendError(errors.New("insufficient funds for gas * price + value")))
+	require.Empty(t, ClassifySendError(nil))
+}
+
+func TestRecovery(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewLocalSigner(key)
+	to := signer.Address()
+	tx, err := signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID: big.NewInt(17000), Nonce: 4, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(3e9), Gas: 21000, To: &to,
+	}), big.NewInt(17000))
+	require.NoError(t, err)
+
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", nonceAPI{nonce: 7}))
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	client, err := ethclient.Dial(ts.URL)
+	require.NoError(t, err)
+	defer client.Close()
+	recovery := &Recovery{Client: client, Signer: signer, Deadline: time.Now().Add(time.Minute)}
+
+	replacement, err := recovery.Recover(context.Background(), tx, errors.New("nonce too low"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), replacement.Nonce())
+	require.Equal(t, tx.GasFeeCap(), replacement.GasFeeCap())
+	from, err := txSender(replacement)
+	require.NoError(t, err)
+	require.Equal(t, signer.Address(), from)
+
+	replacement, err = recovery.Recover(context.Background(), tx, errors.New("replacement transaction underpriced"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), replacement.Nonce())
+	require.Equal(t, big.NewInt(1.1e9), replacement.GasTipCap())
+	require.Equal(t, big.NewInt(3.3e9), replacement.GasFeeCap())
+
+	failure := errors.New("insufficient funds")
+	_, err = recovery.Recover(context.Background(), tx, failure)
+	require.ErrorIs(t, err, failure)
+
+	recovery.Deadline = time.Now().Add(-time.Second)
+	_, err = recovery.Recover(context.Background(), tx, errors.New("nonce too low"))
+	require.ErrorContains(t, err, "no time left")
+}
+
+func TestBump(t *testing.T) {
+	require.Equal(t, big.NewInt(11), bump(big.NewInt(10), 10))
+	require.Equal(t, big.NewInt(2), bump(big.NewInt(1), 10))
+	require.Equal(t, big.NewInt(1), bump(big.NewInt(0), 10))
+	require.Equal(t, big.NewInt(2), bump(big.NewInt(1), 100))
+}
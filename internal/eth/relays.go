@@ -0,0 +1,145 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultRelay is the name of the relay at the RPC endpoint.
+const DefaultRelay = "rpc"
+
+// Relay is a builder or relay endpoint bundles are sent to.
+type Relay struct {
+	Name string
+	URL  string
+}
+
+// ParseRelays parses a comma-separated list of name=url relays, e.g.
+// flashbots=https://relay.flashbots.net. Names must be unique and must not
+// be DefaultRelay.
+func ParseRelays(list string) ([]Relay, error) {
+	var relays []Relay
+	seen := map[string]bool{DefaultRelay: true}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, rawURL, ok := strings.Cut(entry, "=")
+		name, rawURL = strings.TrimSpace(name), strings.TrimSpace(rawURL)
+		if !ok || name == "" || rawURL == "" {
+			return nil, fmt.Errorf("relay %q is not a name=url pair", entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("relay name %q is used twice or reserved", name)
+		}
+		seen[name] = true
+		u, err := url.Parse(rawURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("relay %s has an invalid URL, expected http or https", name)
+		}
+		relays = append(relays, Relay{Name: name, URL: rawURL})
+	}
+	return relays, nil
+}
+
+// ParseDisabledRelays parses a comma-separated list of relay names, each one
+// of relays or DefaultRelay.
+func ParseDisabledRelays(list string, relays []Relay) (map[string]bool, error) {
+	known := map[string]bool{DefaultRelay: true}
+	for _, r := range relays {
+		known[r.Name] = true
+	}
+	disabled := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown relay %q", name)
+		}
+		disabled[name] = true
+	}
+	return disabled, nil
+}
+
+// RelayResult is what one relay answered to a bundle.
+type RelayResult struct {
+	Relay   string
+	Result  string
+	Err     error
+	Latency time.Duration
+}
+
+// BundleResults are the answers of every relay a bundle or private
+// transaction was sent to, in the order of the relays.
+type BundleResults []RelayResult
+
+// Accepted returns the names of the relays that accepted the bundle.
+func (r BundleResults) Accepted() []string {
+	var accepted []string
+	for _, res := range r {
+		if res.Err == nil {
+			accepted = append(accepted, res.Relay)
+		}
+	}
+	return accepted
+}
+
+// Err returns nil when a relay accepted the bundle, and the error of every
+// relay otherwise. The errors are wrapped, so they can still be classified.
+func (r BundleResults) Err() error {
+	if len(r) == 0 {
+		return errors.New("no relay to send the bundle to")
+	}
+	var errList []error
+	for _, res := range r {
+		if res.Err == nil {
+			return nil
+		}
+		errList = append(errList, fmt.Errorf("%s: %w", res.Relay, res.Err))
+	}
+	return errors.Join(errList...)
+}
+
+// SendBundles sends the bundle of signedTx to every relay at once and returns
+// once all of them answered or ctx was canceled.
+func SendBundles(ctx context.Context, relays []Relay, signedTx *types.Transaction, blkNum uint64, hints BundleHints) BundleResults {
+	return fanOut(relays, func(url string) (string, error) {
+		return SendBundleContext(ctx, url, signedTx, blkNum, hints)
+	})
+}
+
+// SendPrivateTxs is like SendBundles, but submits signedTx as a private
+// transaction with SendPrivateTxContext.
+func SendPrivateTxs(ctx context.Context, relays []Relay, signedTx *types.Transaction, maxBlockNumber uint64, prefs map[string]interface{}, hints BundleHints) BundleResults {
+	return fanOut(relays, func(url string) (string, error) {
+		return SendPrivateTxContext(ctx, url, signedTx, maxBlockNumber, prefs, hints)
+	})
+}
+
+// fanOut calls send with the URL of every relay at once and returns their
+// results once all returned.
+func fanOut(relays []Relay, send func(url string) (string, error)) BundleResults {
+	results := make(BundleResults, len(relays))
+	var wg sync.WaitGroup
+	for i, relay := range relays {
+		wg.Add(1)
+		go func(i int, relay Relay) {
+			defer wg.Done()
+			start := time.Now()
+			result, err := send(relay.URL)
+			results[i] = RelayResult{Relay: relay.Name, Result: result, Err: err, Latency: time.Since(start)}
+		}(i, relay)
+	}
+	wg.Wait()
+	return results
+}
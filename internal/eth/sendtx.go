@@ -6,219 +6,149 @@ package eth
 
 import (
 	"context"
-	"crypto/ecdsa"
-	"errors"
 	"log/slog"
 	"math/big"
 	"os"
-	"strconv"
-	"time"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
 	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/holiman/uint256"
-	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+	"github.com/primev/preconf_blob_bidder/internal/latency"
 	"golang.org/x/exp/rand"
 )
 
-var (
-	defaultTimeout time.Duration
-	defaultPriorityFeeGwei = big.NewInt(1) // in wei
-)
-
-// init initializes the defaultTimeout and defaultPriorityFeeGwei variables
-func init() {
-	timeoutStr := os.Getenv("DEFAULT_TIMEOUT")
-	if timeoutStr != "" {
-		timeoutSeconds, err := strconv.Atoi(timeoutStr)
-		if err != nil {
-			slog.Default().Warn("Invalid DEFAULT_TIMEOUT value. Using default of 15 seconds.",
-				slog.String("DEFAULT_TIMEOUT", timeoutStr))
-			defaultTimeout = 15 * time.Second
-		} else {
-			defaultTimeout = time.Duration(timeoutSeconds) * time.Second
-			slog.Default().Info("defaultTimeout loaded from environment",
-				slog.Duration("defaultTimeout", defaultTimeout))
-		}
-	} else {
-		defaultTimeout = 15 * time.Second
-	}
-
-	// Initialize priority fee from environment
-	priorityFeeStr := os.Getenv("PRIORITY_FEE_GWEI")
-	if priorityFeeStr != "" {
-		priorityFeeGwei, err := strconv.ParseInt(priorityFeeStr, 10, 64)
-		if err != nil {
-			slog.Default().Warn("Invalid PRIORITY_FEE_GWEI value. Using default of 1 gwei.",
-				slog.String("PRIORITY_FEE_GWEI", priorityFeeStr))
-		} else {
-			defaultPriorityFeeGwei = big.NewInt(priorityFeeGwei)
-			slog.Default().Info("priorityFee loaded from environment",
-				slog.String("priorityFeeGwei", priorityFeeStr))
-		}
-	}
+// SelfETHTransfer creates an ETH transfer transaction from the signer's account.
+func SelfETHTransfer(client *Client, signer Signer, value *big.Int, offset uint64, fees FeeOracle) (*types.Transaction, uint64, error) {
+	return ETHTransfer(client, signer, signer.Address(), value, offset, fees)
 }
 
-// SelfETHTransfer sends an ETH transfer transaction from the authenticated account.
-func SelfETHTransfer(client *ethclient.Client, authAcct bb.AuthAcct, value *big.Int, offset uint64, priorityFeeGwei *big.Int) (*types.Transaction, uint64, error) {
-	// Set a timeout context
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
-	defer cancel()
-
-	// Get the account's nonce
-	nonce, err := client.PendingNonceAt(ctx, authAcct.Address)
+// ETHTransfer creates an ETH transfer transaction of value wei from the
+// signer's account to to.
+func ETHTransfer(client *Client, signer Signer, to common.Address, value *big.Int, offset uint64, fees FeeOracle) (*types.Transaction, uint64, error) {
+	txs, targetBlock, err := ETHTransfers(client, signer, to, value, 1, offset, fees)
 	if err != nil {
-		slog.Default().Error("Failed to get pending nonce",
-			slog.String("function", "PendingNonceAt"),
-			slog.Any("error", err))
 		return nil, 0, err
 	}
+	return txs[0], targetBlock, nil
+}
 
-	// Get the current base fee per gas from the latest block header
-	header, err := client.HeaderByNumber(ctx, nil)
-	if err != nil {
-		slog.Default().Error("Failed to get latest block header",
-			slog.String("function", "HeaderByNumber"),
-			slog.Any("error", err))
-		return nil, 0, err
-	}
+// SelfETHTransfers creates and signs count distinct ETH transfers from the
+// signer's account to itself, with consecutive nonces starting at the pending
+// nonce. Their fees come from the fee oracle.
+func SelfETHTransfers(client *Client, signer Signer, value *big.Int, count int, offset uint64, fees FeeOracle) ([]*types.Transaction, uint64, error) {
+	return ETHTransfers(client, signer, signer.Address(), value, count, offset, fees)
+}
 
-	// Get the chain ID
-	chainID, err := client.NetworkID(ctx)
+// ETHTransfers creates and signs count ETH transfers of value wei from the
+// signer's account to to, with consecutive nonces starting at the pending
+// nonce. Their fees come from the fee oracle.
+func ETHTransfers(client *Client, signer Signer, to common.Address, value *big.Int, count int, offset uint64, fees FeeOracle) ([]*types.Transaction, uint64, error) {
+	address := signer.Address()
+	// Get the nonce, latest header and chain ID in one round trip
+	state, err := fetchTxState(client, signer, count, fees)
 	if err != nil {
-		slog.Default().Error("Failed to get network ID",
-			slog.String("function", "NetworkID"),
+		slog.Default().Error("Failed to fetch block state",
+			slog.String("function", "fetchTxState"),
 			slog.Any("error", err))
 		return nil, 0, err
 	}
+	nonce, header, chainID := state.nonce, state.header, state.chainID
 
-	baseFee := header.BaseFee
 	blockNumber := header.Number.Uint64()
 
-	// Use provided priority fee or default
-	priorityFee := defaultPriorityFeeGwei
-	if priorityFeeGwei != nil {
-		priorityFee = new(big.Int).Mul(priorityFeeGwei, big.NewInt(1))
-	}
-
-	// Create a transaction with the specified priority fee
-	maxFee := new(big.Int).Add(baseFee, priorityFee)
-	tx := types.NewTx(&types.DynamicFeeTx{
-		Nonce:     nonce,
-		To:        &authAcct.Address,
-		Value:     value,
-		Gas:       1_000_000,
-		GasFeeCap: maxFee,
-		GasTipCap: priorityFee,
-	})
-
-	// Sign the transaction with the authenticated account's private key
-	signer := types.LatestSignerForChainID(chainID)
-	signedTx, err := types.SignTx(tx, signer, authAcct.PrivateKey)
+	fee, err := fees.Fees(client.Client, header, offset, address, nonce)
 	if err != nil {
-		slog.Default().Error("Failed to sign transaction",
-			slog.String("function", "SignTx"),
-			slog.Any("error", err))
+		releaseNonces(signer, nonce, count)
 		return nil, 0, err
 	}
 
-	slog.Default().Info("Self ETH transfer transaction created and signed",
-		slog.String("tx_hash", signedTx.Hash().Hex()),
-		slog.Uint64("block_number", blockNumber))
+	txs := make([]*types.Transaction, 0, count)
+	for i := 0; i < count; i++ {
+		// Create a transaction with the specified priority fee
+		tx := types.NewTx(&types.DynamicFeeTx{
+			Nonce:     nonce + uint64(i),
+			To:        &to,
+			Value:     value,
+			Gas:       1_000_000,
+			GasFeeCap: fee.FeeCap,
+			GasTipCap: fee.Tip,
+		})
+
+		// Sign the transaction with the account's key, wherever it is kept
+		signedTx, err := signer.SignTx(tx, chainID)
+		if err != nil {
+			slog.Default().Error("Failed to sign transaction",
+				slog.String("function", "SignTx"),
+				slog.Any("error", err))
+			releaseNonces(signer, nonce, count)
+			return nil, 0, err
+		}
 
-	return signedTx, blockNumber + offset, nil
+		msg := "ETH transfer transaction created and signed"
+		if to == address {
+			msg = "Self ETH transfer transaction created and signed"
+		}
+		slog.Default().Info(msg,
+			slog.String("tx_hash", signedTx.Hash().Hex()),
+			slog.String("to", to.Hex()),
+			slog.String("value", value.String()),
+			slog.Uint64("block_number", blockNumber))
+		txs = append(txs, signedTx)
+	}
+
+	return txs, blockNumber + offset, nil
 }
 
 // ExecuteBlobTransaction executes a blob transaction with preconfirmation bids.
-func ExecuteBlobTransaction(client *ethclient.Client, authAcct bb.AuthAcct, numBlobs int, offset uint64, priorityFeeGwei *big.Int) (*types.Transaction, uint64, error) {
-
-	pubKey, ok := authAcct.PrivateKey.Public().(*ecdsa.PublicKey)
-	if !ok || pubKey == nil {
-		slog.Default().Error("Failed to cast public key to ECDSA")
-		return nil, 0, errors.New("failed to cast public key to ECDSA")
-	}
-
+// Its execution fees come from the fee oracle, its blob fee cap from the blob
+// fee oracle.
+func ExecuteBlobTransaction(client *Client, signer Signer, numBlobs int, offset uint64, fees FeeOracle, blobFees BlobFeeOracle) (*types.Transaction, uint64, error) {
 	var (
 		gasLimit    = uint64(1_000_000)
 		blockNumber uint64
 		nonce       uint64
 	)
 
-	// Set a timeout context
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
-	defer cancel()
-
-	privateKey := authAcct.PrivateKey
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		slog.Default().Error("Failed to cast public key to ECDSA")
-		return nil, 0, errors.New("failed to cast public key to ECDSA")
-	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	fromAddress := signer.Address()
 
-	nonce, err := client.PendingNonceAt(ctx, authAcct.Address)
+	// Get the nonce, latest header and chain ID in one round trip
+	state, err := fetchTxState(client, signer, 1, fees)
 	if err != nil {
-		slog.Default().Error("Failed to get pending nonce",
-			slog.String("function", "PendingNonceAt"),
+		slog.Default().Error("Failed to fetch block state",
+			slog.String("function", "fetchTxState"),
 			slog.Any("error", err))
 		return nil, 0, err
 	}
+	nonce, header, chainID := state.nonce, state.header, state.chainID
+	blockNumber = header.Number.Uint64()
 
-	header, err := client.HeaderByNumber(ctx, nil)
+	// Price blob gas first, so blobs are only generated for blocks worth bidding on
+	blobFeeCap, err := blobFees.BlobFeeCap(client.Client, header, offset)
 	if err != nil {
-		slog.Default().Error("Failed to get latest block header",
-			slog.String("function", "HeaderByNumber"),
-			slog.Any("error", err))
+		releaseNonces(signer, nonce, 1)
 		return nil, 0, err
 	}
-
-	blockNumber = header.Number.Uint64()
-
-	chainID, err := client.NetworkID(ctx)
+	fee, err := fees.Fees(client.Client, header, offset, fromAddress, nonce)
 	if err != nil {
-		slog.Default().Error("Failed to get network ID",
-			slog.String("function", "NetworkID"),
-			slog.Any("error", err))
+		releaseNonces(signer, nonce, 1)
 		return nil, 0, err
 	}
 
-	// Calculate the blob fee cap and ensure it is sufficient for transaction replacement
-	parentExcessBlobGas := eip4844.CalcExcessBlobGas(*header.ExcessBlobGas, *header.BlobGasUsed)
-	blobFeeCap := eip4844.CalcBlobFee(parentExcessBlobGas)
-	blobFeeCap.Add(blobFeeCap, big.NewInt(1)) // Ensure it's at least 1 unit higher to replace a transaction
-
 	// Generate random blobs and their corresponding sidecar
 	blobs := randBlobs(numBlobs)
 	sideCar := makeSidecar(blobs)
 	blobHashes := sideCar.BlobHashes()
 
-	// Incrementally increase blob fee cap for replacement
-	incrementFactor := big.NewInt(110) // 10% increase
-	blobFeeCap.Mul(blobFeeCap, incrementFactor).Div(blobFeeCap, big.NewInt(100))
-
-	// Use provided priority fee or default
-	priorityFee := defaultPriorityFeeGwei
-	if priorityFeeGwei != nil {
-		priorityFee = new(big.Int).Mul(priorityFeeGwei, big.NewInt(1_000_000_000)) // Convert gwei to wei
-	}
-
-	baseFee := header.BaseFee
-	maxFeePerGas := baseFee
-	maxFeePriority := new(big.Int).Add(maxFeePerGas, priorityFee)
-
 	// Create a new BlobTx transaction
 	tx := types.NewTx(&types.BlobTx{
 		ChainID:    uint256.MustFromBig(chainID),
 		Nonce:      nonce,
-		GasTipCap:  uint256.MustFromBig(priorityFee),
-		GasFeeCap:  uint256.MustFromBig(maxFeePriority),
+		GasTipCap:  uint256.MustFromBig(fee.Tip),
+		GasFeeCap:  uint256.MustFromBig(fee.FeeCap),
 		Gas:        gasLimit,
 		To:         fromAddress,
 		BlobFeeCap: uint256.MustFromBig(blobFeeCap),
@@ -226,21 +156,13 @@ func ExecuteBlobTransaction(client *ethclient.Client, authAcct bb.AuthAcct, numB
 		Sidecar:    sideCar,
 	})
 
-	// Create the transaction options with the private key and chain ID
-	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
-	if err != nil {
-		slog.Default().Error("Failed to create keyed transactor",
-			slog.String("function", "NewKeyedTransactorWithChainID"),
-			slog.Any("error", err))
-		return nil, 0, err
-	}
-
 	// Sign the transaction
-	signedTx, err := auth.Signer(auth.From, tx)
+	signedTx, err := signer.SignTx(tx, chainID)
 	if err != nil {
 		slog.Default().Error("Failed to sign blob transaction",
 			slog.String("function", "Signer"),
 			slog.Any("error", err))
+		releaseNonces(signer, nonce, 1)
 		return nil, 0, err
 	}
 
@@ -252,6 +174,41 @@ func ExecuteBlobTransaction(client *ethclient.Client, authAcct bb.AuthAcct, numB
 	return signedTx, blockNumber + offset, nil
 }
 
+// feeHeadroom scales a fee of the next block by the maximum increase of 12.5%
+// per block for every block the target lies beyond it, so transactions for
+// offsets above 1 stay includable.
+func feeHeadroom(fee *big.Int, offset uint64) *big.Int {
+	scaled := new(big.Int).Set(fee)
+	for i := uint64(1); i < offset; i++ {
+		scaled.Mul(scaled, big.NewInt(9)).Div(scaled, big.NewInt(8))
+	}
+	return scaled
+}
+
+// fetchNonce returns the pending nonce of the account within the nonce fetch budget.
+func fetchNonce(client *ethclient.Client, address common.Address) (uint64, error) {
+	ctx, cancel := latency.Context(context.Background(), latency.NonceFetch)
+	defer cancel()
+	nonce, err := client.PendingNonceAt(ctx, address)
+	return nonce, latency.Wrap(latency.NonceFetch, err)
+}
+
+// fetchHeader returns the latest block header within the header fetch budget.
+func fetchHeader(client *ethclient.Client) (*types.Header, error) {
+	ctx, cancel := latency.Context(context.Background(), latency.HeaderFetch)
+	defer cancel()
+	header, err := client.HeaderByNumber(ctx, nil)
+	return header, latency.Wrap(latency.HeaderFetch, err)
+}
+
+// fetchChainID returns the chain ID within the chain ID budget.
+func fetchChainID(client *ethclient.Client) (*big.Int, error) {
+	ctx, cancel := latency.Context(context.Background(), latency.ChainID)
+	defer cancel()
+	chainID, err := client.ChainID(ctx)
+	return chainID, latency.Wrap(latency.ChainID, err)
+}
+
 // makeSidecar creates a sidecar for the given blobs by generating commitments and proofs.
 func makeSidecar(blobs []kzg4844.Blob) *types.BlobTxSidecar {
 	var (
@@ -261,8 +218,7 @@ func makeSidecar(blobs []kzg4844.Blob) *types.BlobTxSidecar {
 
 	// Generate commitments and proofs for each blob
 	for _, blob := range blobs {
-		c, _ := kzg4844.BlobToCommitment(&blob)
-		p, _ := kzg4844.ComputeBlobProof(&blob, c)
+		c, p, _ := blobCommitment(&blob)
 
 		commitments = append(commitments, c)
 		proofs = append(proofs, p)
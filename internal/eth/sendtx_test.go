@@ -0,0 +1,37 @@
+package eth
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeHeadroom(t *testing.T) {
+	require.Equal(t, int64(800), feeHeadroom(big.NewInt(800), 1).Int64(), "next block fees are used as is")
+	require.Equal(t, int64(900), feeHeadroom(big.NewInt(800), 2).Int64())
+	require.Equal(t, int64(1012), feeHeadroom(big.NewInt(800), 3).Int64())
+}
+
+func TestSelfETHTransferTargetsOffsetBlock(t *testing.T) {
+	ts := httptest.NewServer(&rpcServer{})
+	defer ts.Close()
+	conn, err := ethclient.Dial(ts.URL)
+	require.NoError(t, err)
+	client := NewClient(conn)
+	defer client.Close()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	// The head is block 100 with a base fee of 1 gwei
+	tx, target, err := SelfETHTransfer(client, NewLocalSigner(key), big.NewInt(1), 3, NewGasPricer(FixedTip{Wei: big.NewInt(2)}, nil, 1))
+	require.NoError(t, err)
+	require.Equal(t, uint64(103), target)
+	require.Equal(t, uint64(7), tx.Nonce())
+	require.Equal(t, big.NewInt(1_265_625_000+2), tx.GasFeeCap(), "base fee can grow for two blocks past the next one")
+	require.Equal(t, big.NewInt(2), tx.GasTipCap())
+}
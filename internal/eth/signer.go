@@ -0,0 +1,221 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/primev/preconf_blob_bidder/internal/ident"
+)
+
+// Signer signs the transactions of one account.
+type Signer interface {
+	// Address is the account the signer signs for.
+	Address() common.Address
+	// SignTx signs tx for the chain with chainID.
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// LocalSigner signs with a private key held in the bidder process.
+type LocalSigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewLocalSigner returns a signer for key.
+func NewLocalSigner(key *ecdsa.PrivateKey) *LocalSigner {
+	return &LocalSigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// Address implements Signer.
+func (s *LocalSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx implements Signer.
+func (s *LocalSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+// RemoteSignerKind is the API flavor of a remote signer.
+type RemoteSignerKind string
+
+const (
+	// Clef signs with account_signTransaction and asks its operator, or its
+	// rule file, to approve every transaction.
+	Clef RemoteSignerKind = "clef"
+	// Web3Signer signs with eth_signTransaction, as served by web3signer in
+	// eth1 mode.
+	Web3Signer RemoteSignerKind = "web3signer"
+)
+
+// ParseRemoteSignerKind validates the kind of a remote signer.
+func ParseRemoteSignerKind(s string) (RemoteSignerKind, error) {
+	switch kind := RemoteSignerKind(s); kind {
+	case Clef, Web3Signer:
+		return kind, nil
+	}
+	return "", fmt.Errorf("unknown remote signer %q, must be clef or web3signer", s)
+}
+
+// methods returns the signer's methods to sign a transaction and to list its
+// accounts.
+func (k RemoteSignerKind) methods() (sign, list string) {
+	if k == Clef {
+		return "account_signTransaction", "account_list"
+	}
+	return "eth_signTransaction", "eth_accounts"
+}
+
+// DefaultRemoteSignTimeout bounds a remote signing request. Clef waits for
+// manual approval unless a rule file approves bids, so it is generous.
+const DefaultRemoteSignTimeout = 10 * time.Second
+
+// RemoteSigner signs over JSON-RPC with a Clef or web3signer instance holding
+// the key, so the key never enters the bidder process.
+type RemoteSigner struct {
+	client  *rpc.Client
+	kind    RemoteSignerKind
+	address common.Address
+	timeout time.Duration
+}
+
+// NewRemoteSigner connects to the remote signer at url signing for address.
+func NewRemoteSigner(ctx context.Context, url string, kind RemoteSignerKind, address common.Address) (*RemoteSigner, error) {
+	client, err := ident.Dial(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the remote signer: %w", err)
+	}
+	return &RemoteSigner{client: client, kind: kind, address: address, timeout: DefaultRemoteSignTimeout}, nil
+}
+
+// Check verifies that the signer holds the key of its address.
+func (s *RemoteSigner) Check(ctx context.Context) error {
+	_, list := s.kind.methods()
+	var accounts []common.Address
+	if err := s.client.CallContext(ctx, &accounts, list); err != nil {
+		return fmt.Errorf("failed to list the remote signer's accounts: %w", err)
+	}
+	for _, account := range accounts {
+		if account == s.address {
+			return nil
+		}
+	}
+	return fmt.Errorf("remote signer holds no key for %s", s.address.Hex())
+}
+
+// Close closes the connection to the signer.
+func (s *RemoteSigner) Close() {
+	s.client.Close()
+}
+
+// Address implements Signer.
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// signTxArgs are the transaction fields both Clef and web3signer accept.
+type signTxArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to,omitempty"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *hexutil.Big    `json:"chainId"`
+	BlobFeeCap           *hexutil.Big    `json:"maxFeePerBlobGas,omitempty"`
+	BlobHashes           []common.Hash   `json:"blobVersionedHashes,omitempty"`
+}
+
+// SignTx implements Signer. Blob sidecars are not sent to the signer, the
+// signature only covers the blob hashes; the sidecar is attached again to the
+// signed transaction.
+func (s *RemoteSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := signTxArgs{
+		From:                 s.address,
+		To:                   tx.To(),
+		Gas:                  hexutil.Uint64(tx.Gas()),
+		MaxFeePerGas:         (*hexutil.Big)(tx.GasFeeCap()),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tx.GasTipCap()),
+		Value:                (*hexutil.Big)(tx.Value()),
+		Nonce:                hexutil.Uint64(tx.Nonce()),
+		Data:                 tx.Data(),
+		ChainID:              (*hexutil.Big)(chainID),
+	}
+	if tx.Type() == types.BlobTxType {
+		args.BlobFeeCap = (*hexutil.Big)(tx.BlobGasFeeCap())
+		args.BlobHashes = tx.BlobHashes()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	sign, _ := s.kind.methods()
+	var result json.RawMessage
+	if err := s.client.CallContext(ctx, &result, sign, args); err != nil {
+		return nil, fmt.Errorf("remote signer refused to sign: %w", err)
+	}
+	signed, err := decodeSignResult(result)
+	if err != nil {
+		return nil, err
+	}
+
+	// The signer must have signed exactly the transaction asked for
+	signer := types.LatestSignerForChainID(chainID)
+	if signer.Hash(signed) != signer.Hash(tx.WithoutBlobTxSidecar()) {
+		return nil, errors.New("remote signer returned a different transaction")
+	}
+	if from, err := types.Sender(signer, signed); err != nil || from != s.address {
+		return nil, fmt.Errorf("remote signer did not sign for %s", s.address.Hex())
+	}
+	if sidecar := tx.BlobTxSidecar(); sidecar != nil {
+		signed = signed.WithBlobTxSidecar(sidecar)
+	}
+	return signed, nil
+}
+
+// decodeSignResult decodes the raw signed transaction returned as a hex
+// string by web3signer, or in the raw field of an object by Clef.
+func decodeSignResult(result json.RawMessage) (*types.Transaction, error) {
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(result, &raw); err != nil {
+		var obj struct {
+			Raw hexutil.Bytes `json:"raw"`
+		}
+		if err := json.Unmarshal(result, &obj); err != nil || len(obj.Raw) == 0 {
+			return nil, fmt.Errorf("unexpected remote signer response: %s", result)
+		}
+		raw = obj.Raw
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid transaction: %w", err)
+	}
+	return signed, nil
+}
+
+// TransactOpts returns transaction options signing with s, for contract
+// transactions such as deposits.
+func TransactOpts(s Signer, chainID *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: s.Address(),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != s.Address() {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.SignTx(tx, chainID)
+		},
+		Context: context.Background(),
+	}
+}
@@ -0,0 +1,120 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSigner serves the signing API of Clef (account namespace) and
+// web3signer (eth namespace) for one key.
+type fakeSigner struct {
+	key    *ecdsa.PrivateKey
+	tamper bool // Sign a different value than asked for.
+}
+
+func (f *fakeSigner) sign(args signTxArgs) (hexutil.Bytes, error) {
+	chainID := args.ChainID.ToInt()
+	var inner types.TxData = &types.DynamicFeeTx{
+		ChainID: chainID, Nonce: uint64(args.Nonce), GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+		GasFeeCap: args.MaxFeePerGas.ToInt(), Gas: uint64(args.Gas), To: args.To, Value: args.Value.ToInt(), Data: args.Data,
+	}
+	if args.BlobHashes != nil {
+		inner = &types.BlobTx{
+			ChainID: uint256.MustFromBig(chainID), Nonce: uint64(args.Nonce), GasTipCap: uint256.MustFromBig(args.MaxPriorityFeePerGas.ToInt()),
+			GasFeeCap: uint256.MustFromBig(args.MaxFeePerGas.ToInt()), Gas: uint64(args.Gas), To: *args.To,
+			Value: uint256.MustFromBig(args.Value.ToInt()), Data: args.Data,
+			BlobFeeCap: uint256.MustFromBig(args.BlobFeeCap.ToInt()), BlobHashes: args.BlobHashes,
+		}
+	}
+	if f.tamper {
+		inner.(*types.DynamicFeeTx).Value = big.NewInt(1e18)
+	}
+	tx, err := types.SignNewTx(f.key, types.LatestSignerForChainID(chainID), inner)
+	if err != nil {
+		return nil, err
+	}
+	return tx.MarshalBinary()
+}
+
+type clefAPI struct{ *fakeSigner }
+
+func (c clefAPI) SignTransaction(args signTxArgs) (map[string]interface{}, error) {
+	raw, err := c.sign(args)
+	return map[string]interface{}{"raw": raw}, err
+}
+
+func (c clefAPI) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(c.key.PublicKey)}
+}
+
+type web3signerAPI struct{ *fakeSigner }
+
+func (w web3signerAPI) SignTransaction(args signTxArgs) (hexutil.Bytes, error) {
+	return w.sign(args)
+}
+
+func (w web3signerAPI) Accounts() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(w.key.PublicKey)}
+}
+
+func TestRemoteSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	fake := &fakeSigner{key: key}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("account", clefAPI{fake}))
+	require.NoError(t, server.RegisterName("eth", web3signerAPI{fake}))
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	chainID := big.NewInt(17000)
+	transfer := types.NewTx(&types.DynamicFeeTx{Nonce: 3, To: &address, Value: big.NewInt(1), Gas: 21000,
+		GasFeeCap: big.NewInt(2e9), GasTipCap: big.NewInt(1e9)})
+	local, err := NewLocalSigner(key).SignTx(transfer, chainID)
+	require.NoError(t, err)
+
+	for _, kind := range []RemoteSignerKind{Clef, Web3Signer} {
+		s, err := NewRemoteSigner(context.Background(), ts.URL, kind, address)
+		require.NoError(t, err)
+		require.NoError(t, s.Check(context.Background()), kind)
+		signed, err := s.SignTx(transfer, chainID)
+		require.NoError(t, err, kind)
+		require.Equal(t, local.Hash(), signed.Hash(), "deterministic signatures match the local signer")
+		s.Close()
+	}
+
+	s, err := NewRemoteSigner(context.Background(), ts.URL, Clef, address)
+	require.NoError(t, err)
+	defer s.Close()
+	sidecar := makeSidecar(randBlobs(1))
+	blob := types.NewTx(&types.BlobTx{ChainID: uint256.MustFromBig(chainID), Nonce: 4, To: address, Gas: 21000,
+		GasFeeCap: uint256.NewInt(2e9), GasTipCap: uint256.NewInt(1e9), BlobFeeCap: uint256.NewInt(1),
+		BlobHashes: sidecar.BlobHashes(), Sidecar: sidecar})
+	signed, err := s.SignTx(blob, chainID)
+	require.NoError(t, err)
+	require.NotNil(t, signed.BlobTxSidecar(), "the sidecar is attached again")
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	require.NoError(t, err)
+	require.Equal(t, address, from)
+
+	fake.tamper = true
+	_, err = s.SignTx(transfer, chainID)
+	require.ErrorContains(t, err, "different transaction")
+
+	other, err := NewRemoteSigner(context.Background(), ts.URL, Web3Signer, common.Address{1})
+	require.NoError(t, err)
+	defer other.Close()
+	require.ErrorContains(t, other.Check(context.Background()), "holds no key")
+}
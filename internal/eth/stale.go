@@ -0,0 +1,149 @@
+package eth
+
+import (
+	"log/slog"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+// DefaultStaleTxReplacements is how often a stale transaction is replaced by
+// default.
+const DefaultStaleTxReplacements = 3
+
+// MaxStaleTxReplacements bounds how often a stale transaction is replaced, as
+// every replacement raises its fees again.
+const MaxStaleTxReplacements = maxFeeBumps
+
+// StaleTxs replaces the transactions whose bids went without a commitment.
+// The transaction of such a bid was already handed to builders and providers
+// with its nonce; a new transaction for the nonce that does not pay enough
+// more than it is refused, or lingers next to it. The next transaction built
+// for the nonce is therefore the stale one again, re-signed with its fees
+// bumped enough to replace it, and at least as high as those the new one was
+// priced at.
+type StaleTxs struct {
+	max int
+
+	mu    sync.Mutex
+	stale map[staleKey]staleTx
+}
+
+// staleKey is a nonce of an account.
+type staleKey struct {
+	from  common.Address
+	nonce uint64
+}
+
+// staleTx is the last transaction for a nonce whose bid went without a
+// commitment.
+type staleTx struct {
+	tx       *types.Transaction
+	replaced int // How often the nonce was replaced before.
+}
+
+// NewStaleTxs returns stale transactions replaced at most max times per nonce.
+func NewStaleTxs(max int) *StaleTxs {
+	return &StaleTxs{max: max, stale: make(map[staleKey]staleTx)}
+}
+
+// Resolved records how the bid for tx of from resolved. Without a commitment
+// tx is replaced by the next transaction built for its nonce; with one, the
+// nonce and those before it are done.
+func (s *StaleTxs) Resolved(from common.Address, tx *types.Transaction, committed bool) {
+	if s == nil || tx == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if committed {
+		s.forget(from, tx.Nonce()+1)
+		return
+	}
+	key := staleKey{from: from, nonce: tx.Nonce()}
+	prev := s.stale[key]
+	s.stale[key] = staleTx{tx: tx, replaced: prev.replaced}
+}
+
+// forget drops the stale transactions of from below nonce.
+func (s *StaleTxs) forget(from common.Address, nonce uint64) {
+	for key := range s.stale {
+		if key.from == from && key.nonce < nonce {
+			delete(s.stale, key)
+		}
+	}
+}
+
+// Replace returns the transaction to bid with instead of tx, built by signer
+// for the next block, and whether it is a replacement: the stale transaction
+// of its nonce with bumped fees, or tx itself when the nonce has none or was
+// replaced too often already. The stale transactions of earlier nonces are
+// dropped, as the account moved on.
+func (s *StaleTxs) Replace(signer Signer, tx *types.Transaction) (*types.Transaction, bool) {
+	if s == nil || signer == nil || tx == nil {
+		return tx, false
+	}
+	key := staleKey{from: signer.Address(), nonce: tx.Nonce()}
+	s.mu.Lock()
+	s.forget(key.from, key.nonce)
+	prev, seen := s.stale[key]
+	if seen && prev.replaced >= s.max {
+		// The nonce is left to newly built transactions
+		delete(s.stale, key)
+	}
+	s.mu.Unlock()
+	if !seen || prev.replaced >= s.max || prev.tx.Hash() == tx.Hash() || prev.tx.Type() != tx.Type() {
+		return tx, false
+	}
+
+	replacement := withBumpedFees(prev.tx)
+	if replacement == nil {
+		return tx, false
+	}
+	raiseFees(replacement, tx)
+	signed, err := signer.SignTx(types.NewTx(replacement), prev.tx.ChainId())
+	if err != nil {
+		slog.Warn("Failed to replace stale transaction, bidding with a new one",
+			"error", err,
+			"txHash", prev.tx.Hash().Hex(),
+			"nonce", prev.tx.Nonce(),
+		)
+		return tx, false
+	}
+	s.mu.Lock()
+	s.stale[key] = staleTx{tx: signed, replaced: prev.replaced + 1}
+	s.mu.Unlock()
+	slog.Info("Replaced stale transaction",
+		"txHash", prev.tx.Hash().Hex(),
+		"replacement", signed.Hash().Hex(),
+		"nonce", signed.Nonce(),
+		"replacements", prev.replaced+1,
+		"gasTipCap", signed.GasTipCap(),
+		"gasFeeCap", signed.GasFeeCap(),
+	)
+	return signed, true
+}
+
+// raiseFees raises the fees of data, of the same type as tx, to at least
+// those of tx.
+func raiseFees(data types.TxData, tx *types.Transaction) {
+	switch data := data.(type) {
+	case *types.DynamicFeeTx:
+		data.GasTipCap = maxBig(data.GasTipCap, tx.GasTipCap())
+		data.GasFeeCap = maxBig(data.GasFeeCap, tx.GasFeeCap())
+	case *types.BlobTx:
+		data.GasTipCap = uint256.MustFromBig(maxBig(data.GasTipCap.ToBig(), tx.GasTipCap()))
+		data.GasFeeCap = uint256.MustFromBig(maxBig(data.GasFeeCap.ToBig(), tx.GasFeeCap()))
+		data.BlobFeeCap = uint256.MustFromBig(maxBig(data.BlobFeeCap.ToBig(), tx.BlobGasFeeCap()))
+	}
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return new(big.Int).Set(b)
+}
@@ -0,0 +1,68 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaleTxs(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewLocalSigner(key)
+	to := signer.Address()
+	build := func(nonce uint64, tip, feeCap int64, value int64) *types.Transaction {
+		tx, err := signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+			ChainID: big.NewInt(17000), Nonce: nonce, GasTipCap: big.NewInt(tip), GasFeeCap: big.NewInt(feeCap), Gas: 21000, To: &to, Value: big.NewInt(value),
+		}), big.NewInt(17000))
+		require.NoError(t, err)
+		return tx
+	}
+	s := NewStaleTxs(2)
+	from := signer.Address()
+
+	stale := build(4, 1e9, 3e9, 1)
+	fresh := build(4, 1e9, 3e9, 2)
+	tx, replaced := s.Replace(signer, fresh)
+	require.False(t, replaced, "nothing is stale yet")
+	require.Equal(t, fresh.Hash(), tx.Hash())
+
+	s.Resolved(from, stale, false)
+	tx, replaced = s.Replace(signer, fresh)
+	require.True(t, replaced)
+	require.Equal(t, uint64(4), tx.Nonce())
+	require.Equal(t, stale.Value(), tx.Value(), "the stale transaction is rebuilt")
+	require.Equal(t, big.NewInt(1.1e9), tx.GasTipCap())
+	require.Equal(t, big.NewInt(3.3e9), tx.GasFeeCap())
+
+	s.Resolved(from, tx, false)
+	tx, replaced = s.Replace(signer, build(4, 2e9, 3e9, 2))
+	require.True(t, replaced)
+	require.Equal(t, big.NewInt(2e9), tx.GasTipCap(), "fees are at least those of the new transaction")
+	require.Equal(t, big.NewInt(3.63e9), tx.GasFeeCap())
+
+	s.Resolved(from, tx, false)
+	_, replaced = s.Replace(signer, fresh)
+	require.False(t, replaced, "replaced too often")
+	_, replaced = s.Replace(signer, fresh)
+	require.False(t, replaced)
+
+	s.Resolved(from, build(5, 1e9, 3e9, 1), false)
+	_, replaced = s.Replace(signer, build(6, 1e9, 3e9, 1))
+	require.False(t, replaced)
+	_, replaced = s.Replace(signer, build(5, 1e9, 3e9, 2))
+	require.False(t, replaced, "the account moved past the nonce")
+
+	s.Resolved(from, build(7, 1e9, 3e9, 1), false)
+	s.Resolved(from, build(7, 1e9, 3e9, 3), true)
+	_, replaced = s.Replace(signer, build(7, 1e9, 3e9, 2))
+	require.False(t, replaced, "the nonce landed")
+
+	var nilStale *StaleTxs
+	tx, replaced = nilStale.Replace(signer, fresh)
+	require.False(t, replaced)
+	require.Equal(t, fresh.Hash(), tx.Hash())
+}
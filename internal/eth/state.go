@@ -0,0 +1,172 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/primev/preconf_blob_bidder/internal/latency"
+)
+
+// Client is a connection to the node transactions are built on. It caches
+// the chain ID, which never changes for a connection, so wrap each connection
+// once and let the cache go with it.
+type Client struct {
+	*ethclient.Client
+	chainID atomic.Pointer[big.Int]
+}
+
+// NewClient wraps the connection client.
+func NewClient(client *ethclient.Client) *Client {
+	return &Client{Client: client}
+}
+
+// blockState is the chain state a transaction is built from.
+type blockState struct {
+	nonce   uint64
+	header  *types.Header
+	chainID *big.Int
+}
+
+// fetchBlockState returns the pending nonce, latest header and chain ID in a
+// single JSON-RPC batch, so the latency critical path takes one round trip.
+// The chain ID is only requested once per Client. Endpoints that reject batch
+// requests are queried call by call instead.
+func fetchBlockState(client *Client, address common.Address) (blockState, error) {
+	return fetchState(client, &address, nil)
+}
+
+// fetchTxState returns the state to build count transactions of signer from,
+// priced by fees. Signers managed by a NonceManager take their nonces from it,
+// so the pending nonce is only fetched when the manager syncs.
+func fetchTxState(client *Client, signer Signer, count int, fees FeeOracle) (blockState, error) {
+	managed, ok := signer.(*managedSigner)
+	if !ok {
+		address := signer.Address()
+		return fetchState(client, &address, batcherOf(fees))
+	}
+	state, err := fetchState(client, nil, batcherOf(fees))
+	if err != nil {
+		return state, err
+	}
+	state.nonce, err = managed.nonces.Reserve(signer.Address(), count, func() (uint64, error) {
+		return fetchNonce(client.Client, signer.Address())
+	})
+	if err != nil {
+		return state, fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	return state, nil
+}
+
+// releaseNonces hands nonces reserved by fetchTxState back to the signer's
+// NonceManager when no transaction was signed with them.
+func releaseNonces(signer Signer, first uint64, count int) {
+	if managed, ok := signer.(*managedSigner); ok {
+		for i := 0; i < count; i++ {
+			managed.nonces.Settle(signer.Address(), first+uint64(i), false)
+		}
+	}
+}
+
+// batcherOf returns the stateBatcher behind fees, or nil.
+func batcherOf(fees FeeOracle) stateBatcher {
+	if p, ok := fees.(interface{ batcher() stateBatcher }); ok {
+		return p.batcher()
+	}
+	b, _ := fees.(stateBatcher)
+	return b
+}
+
+// fetchState implements fetchBlockState, leaving out the nonce when address
+// is nil. The request of batcher, if any, is added to the batch, and its
+// failure left for the oracle to handle.
+func fetchState(client *Client, address *common.Address, batcher stateBatcher) (blockState, error) {
+	var (
+		state     blockState
+		nonce     hexutil.Uint64
+		rawHeader json.RawMessage
+		chainID   hexutil.Big
+	)
+
+	batch := []rpc.BatchElem{
+		{Method: "eth_getBlockByNumber", Args: []interface{}{"latest", false}, Result: &rawHeader},
+	}
+	if address != nil {
+		batch = append([]rpc.BatchElem{{Method: "eth_getTransactionCount", Args: []interface{}{*address, "pending"}, Result: &nonce}}, batch...)
+	}
+	cached := client.chainID.Load()
+	if cached == nil {
+		batch = append(batch, rpc.BatchElem{Method: "eth_chainId", Result: &chainID})
+	}
+	oracle := -1
+	if batcher != nil {
+		oracle = len(batch)
+		batch = append(batch, batcher.batchElem())
+	}
+
+	ctx, cancel := latency.Context(context.Background(), latency.BlockState)
+	err := client.Client.Client().BatchCallContext(ctx, batch)
+	cancel()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return state, latency.Wrap(latency.BlockState, err)
+		}
+		slog.Debug("Batch request failed, fetching block state call by call", "error", err)
+		return fetchBlockStateUnbatched(client, address)
+	}
+	for i, elem := range batch {
+		if elem.Error != nil && i != oracle {
+			return state, fmt.Errorf("%s: %w", elem.Method, elem.Error)
+		}
+	}
+
+	if len(rawHeader) == 0 || string(rawHeader) == "null" {
+		return state, fmt.Errorf("eth_getBlockByNumber: latest block not found")
+	}
+	state.header = new(types.Header)
+	if err := json.Unmarshal(rawHeader, state.header); err != nil {
+		return state, fmt.Errorf("eth_getBlockByNumber: %w", err)
+	}
+	state.nonce = uint64(nonce)
+	if cached != nil {
+		state.chainID = cached
+	} else {
+		state.chainID = chainID.ToInt()
+		client.chainID.Store(state.chainID)
+	}
+	if batcher != nil {
+		batcher.storeBatch(state.header, batch[oracle])
+	}
+	return state, nil
+}
+
+// fetchBlockStateUnbatched is the call by call fallback of fetchBlockState.
+func fetchBlockStateUnbatched(client *Client, address *common.Address) (blockState, error) {
+	var state blockState
+	var err error
+	if address != nil {
+		if state.nonce, err = fetchNonce(client.Client, *address); err != nil {
+			return state, fmt.Errorf("failed to get pending nonce: %w", err)
+		}
+	}
+	if state.header, err = fetchHeader(client.Client); err != nil {
+		return state, fmt.Errorf("failed to get latest block header: %w", err)
+	}
+	if state.chainID = client.chainID.Load(); state.chainID != nil {
+		return state, nil
+	}
+	if state.chainID, err = fetchChainID(client.Client); err != nil {
+		return state, fmt.Errorf("failed to get chain id: %w", err)
+	}
+	client.chainID.Store(state.chainID)
+	return state, nil
+}
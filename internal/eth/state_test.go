@@ -0,0 +1,196 @@
+package eth
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/require"
+)
+
+// rpcServer answers the block state methods and records each HTTP request.
+type rpcServer struct {
+	mu         sync.Mutex
+	requests   [][]string // Methods per HTTP request.
+	batchError bool
+}
+
+func (s *rpcServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var reqs []request
+	batch := body[0] == '['
+	if batch {
+		if s.batchError {
+			http.Error(w, "batch requests are not supported", http.StatusBadRequest)
+			return
+		}
+		_ = json.Unmarshal(body, &reqs)
+	} else {
+		var req request
+		_ = json.Unmarshal(body, &req)
+		reqs = []request{req}
+	}
+
+	var methods []string
+	var resps []map[string]interface{}
+	for _, req := range reqs {
+		methods = append(methods, req.Method)
+		var result interface{}
+		switch req.Method {
+		case "eth_getTransactionCount":
+			result = "0x7"
+		case "eth_chainId":
+			result = "0x4268"
+		case "eth_blobBaseFee":
+			result = "0x9"
+		case "eth_feeHistory":
+			result = map[string]interface{}{
+				"oldestBlock":   "0x62",
+				"baseFeePerGas": []string{"0x3b9aca00", "0x3b9aca00", "0x3b9aca00", "0x3b9aca00"},
+				"gasUsedRatio":  []float64{0.5, 0.5, 0.5},
+				"reward":        [][]string{{"0x3"}, {"0x9"}, {"0x5"}},
+			}
+		case "eth_getBlockByNumber":
+			result = map[string]interface{}{
+				"parentHash":       common.Hash{}.Hex(),
+				"sha3Uncles":       common.Hash{}.Hex(),
+				"miner":            common.Address{}.Hex(),
+				"stateRoot":        common.Hash{}.Hex(),
+				"transactionsRoot": common.Hash{}.Hex(),
+				"receiptsRoot":     common.Hash{}.Hex(),
+				"logsBloom":        "0x" + strings.Repeat("0", 512),
+				"difficulty":       "0x0",
+				"number":           "0x64",
+				"gasLimit":         "0x1c9c380",
+				"gasUsed":          "0x0",
+				"timestamp":        "0x6553f100",
+				"extraData":        "0x",
+				"mixHash":          common.Hash{}.Hex(),
+				"nonce":            "0x0000000000000000",
+				"baseFeePerGas":    "0x3b9aca00",
+			}
+		}
+		resps = append(resps, map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, methods)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		_ = json.NewEncoder(w).Encode(resps)
+	} else {
+		_ = json.NewEncoder(w).Encode(resps[0])
+	}
+}
+
+func TestFetchBlockStateBatchesAndCachesChainID(t *testing.T) {
+	srv := &rpcServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	conn, err := ethclient.Dial(ts.URL)
+	require.NoError(t, err)
+	client := NewClient(conn)
+	defer client.Close()
+
+	state, err := fetchBlockState(client, common.Address{1})
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), state.nonce)
+	require.Equal(t, uint64(100), state.header.Number.Uint64())
+	require.Equal(t, int64(17000), state.chainID.Int64())
+
+	_, err = fetchBlockState(client, common.Address{1})
+	require.NoError(t, err)
+	// The cache goes with the Client, a new connection asks again
+	_, err = fetchBlockState(NewClient(conn), common.Address{1})
+	require.NoError(t, err)
+
+	require.Equal(t, [][]string{
+		{"eth_getTransactionCount", "eth_getBlockByNumber", "eth_chainId"},
+		{"eth_getTransactionCount", "eth_getBlockByNumber"},
+		{"eth_getTransactionCount", "eth_getBlockByNumber", "eth_chainId"},
+	}, srv.requests, "one round trip per block, chain id only once per client")
+}
+
+func TestFetchBlockStateFallsBackWithoutBatchSupport(t *testing.T) {
+	srv := &rpcServer{batchError: true}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	conn, err := ethclient.Dial(ts.URL)
+	require.NoError(t, err)
+	client := NewClient(conn)
+	defer client.Close()
+
+	state, err := fetchBlockState(client, common.Address{1})
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), state.nonce)
+	require.Equal(t, int64(17000), state.chainID.Int64())
+}
+
+func TestFetchTxStateBatchesFeeHistory(t *testing.T) {
+	srv := &rpcServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	conn, err := ethclient.Dial(ts.URL)
+	require.NoError(t, err)
+	client := NewClient(conn)
+	defer client.Close()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewLocalSigner(key)
+
+	fees := NewGasPricer(NewFeeHistoryTip(50, 3, big.NewInt(1)), nil, 1)
+	state, err := fetchTxState(client, signer, 1, fees)
+	require.NoError(t, err)
+	fee, err := fees.Fees(client.Client, state.header, 1, signer.Address(), state.nonce)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(5), fee.Tip, "median of the rewards 3, 9 and 5")
+
+	methods := srv.requests[len(srv.requests)-1]
+	require.Len(t, srv.requests, 1, "the fee history needs no round trip of its own")
+	require.Contains(t, methods, "eth_feeHistory")
+
+	_, err = fetchTxState(client, signer, 1, FixedTipFees(1))
+	require.NoError(t, err)
+	require.NotContains(t, srv.requests[1], "eth_feeHistory", "only the fee history oracle batches it")
+}
+
+func TestFetchTxStateIgnoresStaleFeeHistory(t *testing.T) {
+	srv := &rpcServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	client, err := ethclient.Dial(ts.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// A history ending at block 100 is dropped for block 101, whose tip then
+	// needs a request of its own
+	tips := NewFeeHistoryTip(50, 3, big.NewInt(1))
+	elem := tips.batchElem()
+	history := elem.Result.(*feeHistory)
+	history.OldestBlock = (*hexutil.Big)(big.NewInt(98))
+	history.Reward = [][]*hexutil.Big{{(*hexutil.Big)(big.NewInt(3))}, {(*hexutil.Big)(big.NewInt(9))}, {(*hexutil.Big)(big.NewInt(5))}}
+	header := &types.Header{Number: big.NewInt(101), BaseFee: big.NewInt(1e9)}
+	tips.storeBatch(header, elem)
+	_, err = tips.Tip(client, header)
+	require.NoError(t, err)
+	require.Len(t, srv.requests, 1)
+}
@@ -0,0 +1,153 @@
+// Package explain documents the bidder's settings, metrics and log events from
+// within the binary. Settings and metrics are read from the code that defines
+// them, so names and defaults cannot drift; ranges, related settings and
+// events are kept here next to them.
+package explain
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/primev/preconf_blob_bidder/internal/config"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+// Kind is what an entry documents.
+type Kind string
+
+const (
+	Setting Kind = "setting" // A configuration key.
+	Metric  Kind = "metric"  // A Prometheus metric.
+	Event   Kind = "event"   // A log message operators act on.
+)
+
+// Entry documents a setting, metric or event.
+type Entry struct {
+	Kind        Kind     `json:"kind"`
+	Name        string   `json:"name"`              // YAML key, metric name or log message.
+	Aliases     []string `json:"aliases,omitempty"` // Environment variable and flag, or the metric name without namespace.
+	Description string   `json:"description"`
+	Default     string   `json:"default,omitempty"`
+	Range       string   `json:"range,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	Related     []string `json:"related,omitempty"`
+}
+
+// Registry holds the entries of the bidder.
+type Registry struct {
+	entries []Entry
+}
+
+// New returns the registry of the bidder's settings, metrics and events.
+// usage maps flag names to their help text, which describes the settings.
+func New(usage map[string]string) *Registry {
+	r := &Registry{}
+	for _, key := range config.Keys() {
+		info := settings[key.Name]
+		e := Entry{
+			Kind:        Setting,
+			Name:        key.Name,
+			Aliases:     []string{key.Env},
+			Description: usage[key.Flag],
+			Default:     key.Default,
+			Range:       info.Range,
+			Related:     info.Related,
+		}
+		if key.Flag != "" {
+			e.Aliases = append(e.Aliases, "--"+key.Flag)
+		}
+		if info.Description != "" {
+			e.Description = info.Description
+		}
+		if key.Secret {
+			e.Default = ""
+		}
+		r.entries = append(r.entries, e)
+	}
+	for _, m := range metrics.Descriptions() {
+		r.entries = append(r.entries, Entry{
+			Kind:        Metric,
+			Name:        m.Name,
+			Aliases:     []string{strings.TrimPrefix(m.Name, metrics.Namespace+"_")},
+			Description: m.Help,
+			Labels:      m.Labels,
+			Related:     metricRelated[m.Name],
+		})
+	}
+	r.entries = append(r.entries, events...)
+	return r
+}
+
+// Entries returns every entry.
+func (r *Registry) Entries() []Entry {
+	return r.entries
+}
+
+// Lookup returns the entries called name, case-insensitively: settings by
+// YAML key, environment variable or flag, metrics with or without the
+// namespace, and events by log message.
+func (r *Registry) Lookup(name string) []Entry {
+	name = normalize(name)
+	var found []Entry
+	for _, e := range r.entries {
+		for _, n := range append([]string{e.Name}, e.Aliases...) {
+			if normalize(n) == name {
+				found = append(found, e)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// Suggest returns the names of entries containing term, sorted, to point at
+// what an unknown name may have meant.
+func (r *Registry) Suggest(term string) []string {
+	term = normalize(term)
+	var names []string
+	for _, e := range r.entries {
+		if strings.Contains(normalize(e.Name), term) {
+			names = append(names, e.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// normalize makes flag, environment and YAML spellings of a name comparable.
+func normalize(name string) string {
+	name = strings.ToLower(strings.TrimLeft(strings.TrimSpace(name), "-"))
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// Write prints e for people.
+func (e Entry) Write(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s)\n", e.Name, e.Kind)
+	if len(e.Aliases) > 0 {
+		fmt.Fprintf(&b, "  Also:     %s\n", strings.Join(e.Aliases, ", "))
+	}
+	if e.Description != "" {
+		fmt.Fprintf(&b, "  %s\n", e.Description)
+	}
+	if e.Kind == Setting {
+		def := e.Default
+		if def == "" {
+			def = "(empty)"
+		}
+		fmt.Fprintf(&b, "  Default:  %s\n", def)
+	}
+	if e.Range != "" {
+		fmt.Fprintf(&b, "  Range:    %s\n", e.Range)
+	}
+	if len(e.Labels) > 0 {
+		fmt.Fprintf(&b, "  Labels:   %s\n", strings.Join(e.Labels, ", "))
+	}
+	if len(e.Related) > 0 {
+		fmt.Fprintf(&b, "  Related:  %s\n", strings.Join(e.Related, ", "))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,107 @@
+package explain
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	r := New(map[string]string{"offset": "Number of blocks ahead to bid for"})
+
+	for _, name := range []string{"offset", "OFFSET", "--offset"} {
+		found := r.Lookup(name)
+		require.Len(t, found, 1, name)
+		require.Equal(t, Setting, found[0].Kind)
+		require.Equal(t, "Number of blocks ahead to bid for", found[0].Description)
+		require.Equal(t, "1", found[0].Default)
+		require.Equal(t, "at least 1", found[0].Range)
+	}
+
+	found := r.Lookup("blocks_skipped_total")
+	require.Len(t, found, 1)
+	require.Equal(t, "preconf_bidder_blocks_skipped_total", found[0].Name)
+	require.Equal(t, []string{"reason"}, found[0].Labels)
+
+	found = r.Lookup("block skipped")
+	require.Len(t, found, 1)
+	require.Equal(t, Event, found[0].Kind)
+
+	require.Empty(t, r.Lookup("offsets"))
+	require.Contains(t, r.Suggest("offset"), "offset")
+
+	require.Empty(t, r.Lookup("private_key")[0].Default, "secrets never print a default")
+}
+
+func TestEntryWrite(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Entry{
+		Kind:        Setting,
+		Name:        "fee_bump",
+		Aliases:     []string{"FEE_BUMP", "--fee-bump"},
+		Description: "Factor the priority fee grows by",
+		Default:     "1",
+		Range:       "1 to 2",
+		Related:     []string{"gas_oracle"},
+	}.Write(&buf))
+	require.Equal(t, `fee_bump (setting)
+  Also:     FEE_BUMP, --fee-bump
+  Factor the priority fee grows by
+  Default:  1
+  Range:    1 to 2
+  Related:  gas_oracle
+`, buf.String())
+}
+
+// TestRegistryInSync fails when a curated entry refers to a setting, metric
+// or event that no longer exists.
+func TestRegistryInSync(t *testing.T) {
+	r := New(nil)
+	names := make(map[string]bool)
+	for _, e := range r.Entries() {
+		names[e.Name] = true
+	}
+	for key := range settings {
+		require.True(t, names[key], "unknown setting %q", key)
+	}
+	for name := range metricRelated {
+		require.True(t, names[name], "unknown metric %q", name)
+	}
+	for _, e := range r.Entries() {
+		for _, related := range e.Related {
+			require.True(t, names[related], "%s refers to unknown %q", e.Name, related)
+		}
+	}
+
+	source := readSource(t, filepath.Join("..", ".."))
+	for _, e := range events {
+		require.Contains(t, source, strconv.Quote(e.Name), "event %q is not logged anywhere", e.Name)
+	}
+}
+
+// readSource concatenates the non-test Go files under root, outside this
+// package.
+func readSource(t *testing.T, root string) string {
+	var b strings.Builder
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == "explain" {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		b.Write(data)
+		return err
+	})
+	require.NoError(t, err)
+	return b.String()
+}
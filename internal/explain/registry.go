@@ -0,0 +1,422 @@
+package explain
+
+// setting is what the code defining a setting does not say about it.
+type setting struct {
+	Description string // For settings without a flag.
+	Range       string
+	Related     []string
+}
+
+// settings holds the valid ranges and related settings, as enforced by
+// config.Validate.
+var settings = map[string]setting{
+	"private_key":                   {Range: "64 hex characters", Related: []string{"keystore_path", "remote_signer_url", "extra_private_keys"}},
+	"keystore_path":                 {Range: "exclusive with private_key", Related: []string{"keystore_password", "keystore_password_file"}},
+	"keystore_password":             {Related: []string{"keystore_path", "keystore_password_file", "extra_keystore_paths"}},
+	"keystore_password_file":        {Related: []string{"keystore_path", "keystore_password", "extra_keystore_paths"}},
+	"remote_signer_url":             {Range: "exclusive with private_key and keystore_path", Related: []string{"remote_signer_kind", "remote_signer_address"}},
+	"remote_signer_kind":            {Range: "clef or web3signer", Related: []string{"remote_signer_url", "remote_signer_address"}},
+	"remote_signer_address":         {Related: []string{"remote_signer_url"}},
+	"bidder_tls_cert":               {Range: "set together with bidder_tls_key", Related: []string{"bidder_tls", "bidder_tls_key", "bidder_tls_ca_cert"}},
+	"bidder_tls_key":                {Range: "set together with bidder_tls_cert", Related: []string{"bidder_tls", "bidder_tls_cert"}},
+	"bidder_token":                  {Range: "requires TLS", Related: []string{"bidder_tls", "bidder_tls_ca_cert"}},
+	"bid_retry_budget":              {Range: "0 disables retries", Related: []string{"bid_retry_backoff"}},
+	"bid_retry_backoff":             {Range: "positive when bid_retry_budget is set", Related: []string{"bid_retry_budget"}},
+	"bid_compression":               {Related: []string{"max_bid_payload_bytes", "payload_privacy"}},
+	"max_bid_payload_bytes":         {Range: "0 for no limit", Related: []string{"bid_compression", "payload_privacy"}},
+	"payload_privacy":               {Range: "payload, hash or commit-reveal", Related: []string{"use_payload", "rpc_endpoint", "bundle_hints", "backrun_tx"}},
+	"bundle_search_key":             {Range: "64 hex characters", Related: []string{"payload_privacy", "rpc_endpoint", "bundle_replacement"}},
+	"bundle_replacement":            {Related: []string{"bundle_search_key", "bundle_validity", "bundle_hints"}},
+	"bundle_validity":               {Range: "not negative, 0 for open bundles", Related: []string{"bundle_replacement", "bundle_hints"}},
+	"bundle_relays":                 {Range: "name=url pairs, http or https", Related: []string{"rpc_endpoint", "disabled_relays", "bundle_search_key"}},
+	"disabled_relays":               {Range: "names of bundle_relays or rpc", Related: []string{"bundle_relays", "rpc_endpoint"}},
+	"submit_method":                 {Range: "bundle or private-tx", Related: []string{"private_tx_preferences", "bundle_relays", "backrun_tx"}},
+	"private_tx_preferences":        {Range: "name=value pairs", Related: []string{"submit_method"}},
+	"submission_backend":            {Range: "bidder or preconf-rpc", Related: []string{"preconf_rpc_endpoint", "target_block_span"}},
+	"preconf_rpc_endpoint":          {Related: []string{"submission_backend"}},
+	"public_fallback_blocks":        {Range: "0 to never broadcast, requires submission_backend bidder", Related: []string{"public_rpc_endpoint", "submission_backend"}},
+	"public_rpc_endpoint":           {Related: []string{"public_fallback_blocks", "rpc_endpoint"}},
+	"backfill_blocks":               {Range: "0 disables backfilling", Related: []string{"ws_endpoint", "rpc_endpoint", "bid_on_reconnect"}},
+	"bid_on_reconnect":              {Range: "requires backfill_blocks", Related: []string{"backfill_blocks"}},
+	"ws_stall_slots":                {Range: "0 disables stall detection, otherwise at least 2", Related: []string{"ws_endpoint", "backfill_blocks"}},
+	"offset":                        {Range: "at least 1", Related: []string{"target_block_span", "decay_min", "decay_max"}},
+	"target_block_span":             {Range: "1 to 8, 1 with submission_backend preconf-rpc", Related: []string{"offset", "submission_backend"}},
+	"bid_amount":                    {Range: "not negative", Related: []string{"bid_amount_std_dev_percentage", "strategy", "adaptive_target_rate"}},
+	"bid_amount_std_dev_percentage": {Range: "not negative", Related: []string{"bid_amount", "strategy"}},
+	"hourly_budget":                 {Range: "not negative, 0 for no cap", Related: []string{"daily_budget", "budget_mode", "preconf_bidder_budget_remaining_eth"}},
+	"daily_budget":                  {Range: "not negative, 0 for no cap", Related: []string{"hourly_budget", "budget_mode", "preconf_bidder_budget_remaining_eth"}},
+	"budget_mode":                   {Range: "stop or reduce", Related: []string{"hourly_budget", "daily_budget"}},
+	"max_bids_per_block":            {Range: "0 for no limit", Related: []string{"account_rotation", "target_block_span"}},
+	"priority_fee_gwei":             {Related: []string{"gas_oracle", "fee_bump"}},
+	"gas_oracle":                    {Range: "fixed or fee-history", Related: []string{"priority_fee_gwei", "gas_tip_percentile", "max_base_fee_gwei", "fee_bump"}},
+	"gas_tip_percentile":            {Range: "0 to 100", Related: []string{"gas_oracle"}},
+	"max_base_fee_gwei":             {Range: "0 for no maximum, otherwise positive", Related: []string{"gas_oracle", "skip_log_file"}},
+	"fee_bump":                      {Range: "1 to 2", Related: []string{"gas_oracle", "nonce_manager"}},
+	"blob_fee_source":               {Range: "header or rpc", Related: []string{"blob_fee_bump", "num_blob"}},
+	"blob_fee_bump":                 {Range: "1 to 10", Related: []string{"blob_fee_source", "max_blob_fee_cap_gwei"}},
+	"max_blob_fee_cap_gwei":         {Range: "0 for no maximum, otherwise positive", Related: []string{"blob_fee_bump", "blob_fee_ceiling_gwei"}},
+	"blob_fee_ceiling_gwei":         {Range: "0 for no ceiling, otherwise positive", Related: []string{"max_blob_fee_cap_gwei", "skip_log_file"}},
+	"kzg_trusted_setup":             {Range: "a trusted setup JSON file in the consensus specs format, or empty", Related: []string{"num_blob"}},
+	"num_blob":                      {Range: "required with tx_type blob, 0 otherwise", Related: []string{"tx_type", "transfer_private_key"}},
+	"tx_type":                       {Range: "transfer, blob, erc20, contract-call or raw", Related: []string{"num_blob", "erc20_token", "contract_address", "raw_tx_file"}},
+	"erc20_token":                   {Range: "an address, required with tx_type erc20", Related: []string{"tx_type", "erc20_recipient", "erc20_amount"}},
+	"erc20_recipient":               {Range: "an address, required with tx_type erc20", Related: []string{"tx_type", "erc20_token", "erc20_amount"}},
+	"erc20_amount":                  {Range: "a non-negative integer in token base units", Related: []string{"tx_type", "erc20_token", "erc20_recipient"}},
+	"transfer_amount":               {Range: "a non-negative integer in wei", Related: []string{"tx_type", "transfer_to", "transfer_private_key"}},
+	"transfer_to":                   {Range: "an address", Related: []string{"tx_type", "transfer_amount", "transfer_private_key"}},
+	"contract_address":              {Range: "an address, required with tx_type contract-call", Related: []string{"tx_type", "contract_abi", "contract_method", "contract_calldata"}},
+	"contract_abi":                  {Related: []string{"contract_method", "contract_args", "contract_calldata"}},
+	"contract_method":               {Related: []string{"contract_abi", "contract_args"}},
+	"contract_calldata":             {Range: "exclusive with contract_abi and contract_method", Related: []string{"contract_address", "contract_abi"}},
+	"raw_tx_file":                   {Range: "required with tx_type raw", Related: []string{"tx_type"}},
+	"decay_min":                     {Range: "not negative", Related: []string{"decay_max", "decay_clamp", "offset"}},
+	"decay_max":                     {Range: "0 for no maximum, otherwise at least decay_min", Related: []string{"decay_min", "decay_clamp"}},
+	"decay_clamp":                   {Related: []string{"decay_min", "decay_max"}},
+	"bid_slot_offset":               {Range: "0 bids on block arrival, otherwise shorter than a slot (12s)", Related: []string{"genesis_time", "decay_min", "offset"}},
+	"genesis_time":                  {Range: "Unix seconds, 0 aligns slots to the first block; requires bid_slot_offset", Related: []string{"bid_slot_offset"}},
+	"transfer_private_key":          {Range: "64 hex characters, differing from private_key; requires num_blob", Related: []string{"private_key", "num_blob"}},
+	"extra_private_keys":            {Range: "64 hex characters each, all distinct; not with tx_type raw", Related: []string{"extra_keystore_paths", "account_rotation"}},
+	"extra_keystore_paths":          {Range: "not with tx_type raw", Related: []string{"keystore_password", "keystore_password_file", "account_rotation"}},
+	"account_rotation":              {Range: "round-robin or parallel", Related: []string{"extra_private_keys", "extra_keystore_paths"}},
+	"nonce_manager":                 {Related: []string{"nonce_resync", "fee_bump"}},
+	"nonce_resync":                  {Range: "at least one slot (12s)", Related: []string{"nonce_manager"}},
+	"stale_tx_replacements":         {Range: "0 to 10, 0 never replaces", Related: []string{"nonce_manager", "fee_bump", "target_block_span"}},
+	"reconcile_blocks":              {Related: []string{"nonce_manager", "Reconciled transactions of a previous run"}},
+	"latency_budgets":               {Range: "name=duration pairs", Related: []string{"default_timeout", "stale_bid_blocks"}},
+	"tui":                           {Related: []string{"status_address"}},
+	"log_format":                    {Range: "json, text or pretty", Related: []string{"log_level", "log_file"}},
+	"log_level":                     {Range: "debug, info, warn or error", Related: []string{"log_format"}},
+	"log_file":                      {Related: []string{"log_max_size_mb", "log_max_age", "log_max_backups"}},
+	"log_max_size_mb":               {Range: "0 never rotates by size", Related: []string{"log_file", "log_max_backups"}},
+	"log_max_age":                   {Range: "not negative, 0 never rotates by age", Related: []string{"log_file", "log_max_backups"}},
+	"log_max_backups":               {Range: "0 keeps every rotated file", Related: []string{"log_file"}},
+	"log_sample_burst":              {Range: "0 writes every record", Related: []string{"log_sample_interval", "log_level"}},
+	"log_sample_interval":           {Range: "positive with log_sample_burst", Related: []string{"log_sample_burst"}},
+	"otlp_endpoint":                 {Range: "http or https URL, or empty to disable tracing", Related: []string{"trace_sample_ratio"}},
+	"trace_sample_ratio":            {Range: "between 0 and 1", Related: []string{"otlp_endpoint"}},
+	"tenant":                        {Range: "up to 64 letters, digits, '_', '.' or '-', or empty", Related: []string{"campaign_schedule_file", "status_address"}},
+	"user_agent":                    {Range: "printable characters, app_name/version when empty", Related: []string{"request_id_prefix", "app_name", "version"}},
+	"request_id_prefix":             {Range: "letters, digits, '-', '_' and '.'", Related: []string{"user_agent"}},
+	"dry_run":                       {Related: []string{"payload_privacy", "submission_backend", "auto_rollover", "auto_withdraw"}},
+	"auto_rollover":                 {Range: "exclusive with auto_withdraw", Related: []string{"deposit_amount", "blocks_per_window"}},
+	"blocks_per_window":             {Range: "at least 1", Related: []string{"auto_rollover", "auto_withdraw"}},
+	"auto_withdraw":                 {Range: "exclusive with auto_rollover", Related: []string{"auto_withdraw_dry_run"}},
+	"ha_lease_file":                 {Related: []string{"ha_instance_id", "ha_lease_ttl"}},
+	"bid_history_retention":         {Range: "0 to keep every bid, or at least 1h", Related: []string{"bid_history_file"}},
+	"clock_skew_threshold":          {Range: "positive", Related: []string{"clock_compensate", "ntp_server"}},
+	"strategy":                      {Related: []string{"strategy_script", "strategy_plugin", "bid_amount"}},
+	"adaptive_target_rate":          {Range: "between 0 and 1, exclusive", Related: []string{"adaptive_step", "adaptive_min_scale", "adaptive_max_scale"}},
+	"adaptive_step":                 {Range: "above 0 and at most 1", Related: []string{"adaptive_target_rate"}},
+	"adaptive_min_scale":            {Range: "above 0 and at most 1", Related: []string{"adaptive_max_scale", "adaptive_target_rate"}},
+	"adaptive_max_scale":            {Range: "at least 1 and adaptive_min_scale", Related: []string{"adaptive_min_scale", "adaptive_target_rate"}},
+	"provider_weighting":            {Range: "none, stake, reliability or stake-reliability; others require strategy adaptive and mev_commit_ws_endpoint", Related: []string{"strategy", "adaptive_target_rate", "preconf_bidder_provider_weight"}},
+	"canary_percent":                {Range: "0 to 100", Related: []string{"canary_blocks"}},
+	"canary_blocks":                 {Range: "at least 1", Related: []string{"canary_percent"}},
+	"drain_timeout":                 {Range: "not negative"},
+	"max_rss_mb":                    {Range: "MB, 0 disables it", Related: []string{"max_goroutines", "preconf_bidder_degraded", "Resource pressure, shedding optional work"}},
+	"max_goroutines":                {Range: "0 disables it", Related: []string{"max_rss_mb", "preconf_bidder_degraded", "Resource pressure, shedding optional work"}},
+	"status_interval":               {Range: "not negative", Related: []string{"status_address"}},
+	"disable_jobs":                  {Range: "deposits, withdrawals, balances, settlement or compaction", Related: []string{"status_address", "preconf_bidder_job_runs_total"}},
+	"campaign_schedule_file":        {Related: []string{"campaign_lead", "status_address", "status_admin_tokens"}},
+	"own_tx_file":                   {Related: []string{"mev_commit_ws_endpoint", "preconf_bidder_observed_commitments_per_slot", "preconf_bidder_observed_inclusions_per_slot"}},
+	"dispute_file":                  {Related: []string{"mev_commit_ws_endpoint", "dispute_endpoint", "Commitment not honored"}},
+	"dispute_endpoint":              {Related: []string{"mev_commit_ws_endpoint", "dispute_file", "Commitment not honored"}},
+	"inclusion_tracking":            {Range: "requires submission_backend bidder", Related: []string{"inclusion_log_file", "Preconfirmation violated"}},
+	"inclusion_log_file":            {Range: "requires inclusion_tracking", Related: []string{"inclusion_tracking", "Preconfirmation violated", "Transaction included without a commitment"}},
+	"control_audit_file":            {Related: []string{"status_address", "status_admin_tokens", "canary_percent"}},
+	"campaign_lead":                 {Range: "not negative", Related: []string{"campaign_schedule_file"}},
+	"network":                       {Range: "a known mev-commit network", Related: []string{"contracts_url", "confirm_mainnet"}},
+	"bidder_registry_address": {
+		Description: "BidderRegistry contract address, overriding the network's",
+		Range:       "an address",
+		Related:     []string{"network", "contracts_url"},
+	},
+	"block_tracker_address": {
+		Description: "BlockTracker contract address, overriding the network's",
+		Range:       "an address",
+		Related:     []string{"network", "contracts_url"},
+	},
+	"preconf_manager_address": {
+		Description: "PreconfManager contract address, overriding the network's",
+		Range:       "an address",
+		Related:     []string{"network", "contracts_url"},
+	},
+	"confirm_mainnet":    {Related: []string{"allow_mainnet_blobs", "network"}},
+	"telemetry":          {Related: []string{"telemetry_endpoint", "telemetry_interval"}},
+	"telemetry_interval": {Range: "at least 1m", Related: []string{"telemetry"}},
+}
+
+// metricRelated holds the settings and events behind each metric.
+var metricRelated = map[string][]string{
+	"preconf_bidder_bid_failures_total":                {"latency_budgets", "Block skipped"},
+	"preconf_bidder_tx_send_errors_total":              {"rpc_endpoint", "payload_privacy"},
+	"preconf_bidder_bundle_submissions_total":          {"bundle_relays", "disabled_relays", "submit_method", "Relay failed to accept bundle"},
+	"preconf_bidder_public_broadcasts_total":           {"public_fallback_blocks", "public_rpc_endpoint", "Broadcast transaction to the public mempool"},
+	"preconf_bidder_stale_tx_replacements_total":       {"stale_tx_replacements", "Replaced stale transaction"},
+	"preconf_bidder_tx_send_recoveries_total":          {"Rebuilt transaction after submission error"},
+	"preconf_bidder_preconf_rpc_submissions_total":     {"submission_backend", "preconf_rpc_endpoint"},
+	"preconf_bidder_commitment_conflicts_total":        {"conflict_log_file", "nonce_manager", "Conflicting commitments for the same account nonce"},
+	"preconf_bidder_bid_retries_total":                 {"bid_retry_budget", "bid_retry_backoff"},
+	"preconf_bidder_bidder_connected":                  {"server_address"},
+	"preconf_bidder_blocks_skipped_total":              {"skip_log_file", "Block skipped", "Skipped blocks in the last hour"},
+	"preconf_bidder_bids_committed_on_chain_total":     {"mev_commit_ws_endpoint", "Bid commitment stored"},
+	"preconf_bidder_provider_commit_rate":              {"mev_commit_ws_endpoint", "Provider commitment statistics"},
+	"preconf_bidder_provider_stake_eth":                {"provider_weighting", "mev_commit_ws_endpoint"},
+	"preconf_bidder_provider_weight":                   {"provider_weighting", "preconf_bidder_provider_commit_rate", "preconf_bidder_provider_stake_eth"},
+	"preconf_bidder_provider_dispatch_latency_seconds": {"mev_commit_ws_endpoint", "Provider commitment statistics"},
+	"preconf_bidder_bids_not_committed_on_chain_total": {"mev_commit_ws_endpoint", "Bid not committed"},
+	"preconf_bidder_commitments_not_honored_total":     {"dispute_file", "dispute_endpoint", "Commitment not honored"},
+	"preconf_bidder_preconf_inclusions_total":          {"inclusion_tracking", "Preconfirmation violated"},
+	"preconf_bidder_uncommitted_inclusions_total":      {"inclusion_tracking", "Transaction included without a commitment"},
+	"preconf_bidder_job_runs_total":                    {"disable_jobs", "Maintenance job failed", "Maintenance job panicked"},
+	"preconf_bidder_stream_events_dropped_total":       {"status_address"},
+	"preconf_bidder_degraded":                          {"max_rss_mb", "max_goroutines", "Resource pressure, shedding optional work"},
+	"preconf_bidder_observed_commitments_per_slot":     {"mev_commit_ws_endpoint", "own_tx_file"},
+	"preconf_bidder_observed_inclusions_per_slot":      {"mev_commit_ws_endpoint", "own_tx_file"},
+	"preconf_bidder_bid_scale":                         {"adaptive_target_rate", "adaptive_step", "adaptive_min_scale", "adaptive_max_scale"},
+	"preconf_bidder_bid_latency_seconds":               {"latency_budgets"},
+	"preconf_bidder_ws_reconnects_total":               {"ws_endpoint", "WebSocket endpoint switched"},
+	"preconf_bidder_ws_stalls_total":                   {"ws_stall_slots", "WebSocket stall detected"},
+	"preconf_bidder_backfilled_blocks_total":           {"backfill_blocks", "Backfilled blocks missed while reconnecting"},
+	"preconf_bidder_budget_spent_eth":                  {"hourly_budget", "daily_budget"},
+	"preconf_bidder_budget_remaining_eth":              {"hourly_budget", "daily_budget", "Spend budget exhausted, skipping bids"},
+	"preconf_bidder_campaigns":                         {"campaign_schedule_file", "Campaign prerequisites not met"},
+}
+
+// events are the log messages operators act on.
+var events = []Entry{
+	{
+		Kind:        Event,
+		Name:        "Block skipped",
+		Description: "No bid was sent for a block; reason says why: deadline, paused, budget, no-providers, insufficient-funds, filter, busy, tx-error, conflict or fee-cap.",
+		Related:     []string{"skip_log_file", "preconf_bidder_blocks_skipped_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Skipped blocks in the last hour",
+		Description: "Hourly count of skipped blocks per reason, also logged on shutdown.",
+		Related:     []string{"skip_log_file", "Block skipped"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Skipping block, latency budget exceeded",
+		Description: "An external call ran out of its latency budget while building the transaction; budget names it.",
+		Related:     []string{"latency_budgets"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Bid resolved",
+		Description: "Outcome of a bid once its commitment stream ended: commitments, providers and latency.",
+		Related:     []string{"bid_history_file", "preconf_bidder_bid_latency_seconds"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Bid commitment stored",
+		Description: "A commitment for the bid was stored on the mev-commit chain.",
+		Related:     []string{"mev_commit_ws_endpoint", "preconf_bidder_bids_committed_on_chain_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Provider commitment statistics",
+		Description: "Hourly, per provider: the commitments stored for our bids, the bids since the start, the commit rate and the average dispatch latency.",
+		Related:     []string{"mev_commit_ws_endpoint", "preconf_bidder_provider_commit_rate", "preconf_bidder_provider_dispatch_latency_seconds"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Bid not committed",
+		Description: "No commitment for the bid was stored on the mev-commit chain before the timeout.",
+		Related:     []string{"mev_commit_ws_endpoint", "preconf_bidder_bids_not_committed_on_chain_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Commitment not honored",
+		Description: "A transaction of our commitment stored on the mev-commit chain was missing from the target block; the evidence is appended to the dispute file and posted to the dispute endpoint.",
+		Related:     []string{"dispute_file", "dispute_endpoint", "preconf_bidder_commitments_not_honored_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Preconfirmation violated",
+		Description: "A bid was committed but its transaction was missing from the target block, as read from the node; the record names the block it landed in, if any.",
+		Related:     []string{"inclusion_tracking", "inclusion_log_file", "preconf_bidder_preconf_inclusions_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Transaction included without a commitment",
+		Description: "A transaction landed in the target block of its bid although no provider committed to the bid.",
+		Related:     []string{"inclusion_tracking", "inclusion_log_file", "preconf_bidder_uncommitted_inclusions_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Reconciled transactions of a previous run",
+		Description: "On startup the last blocks and the mempool were scanned for transactions of our accounts; those waiting were adopted and included raw transactions are not bid for again.",
+		Related:     []string{"reconcile_blocks", "nonce_manager"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Relay failed to accept bundle",
+		Description: "A relay rejected a bundle or did not answer while another relay accepted it, so the bundle still counts as submitted.",
+		Related:     []string{"bundle_relays", "disabled_relays", "preconf_bidder_bundle_submissions_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Replaced stale transaction",
+		Description: "The bid for a transaction went without a commitment, so the next bid for its nonce is made with the same transaction, its fees bumped to replace it.",
+		Related:     []string{"stale_tx_replacements", "preconf_bidder_stale_tx_replacements_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Broadcast transaction to the public mempool",
+		Description: "An account's bids went public_fallback_blocks blocks in a row without a commitment, so its last transaction was also sent to the public mempool.",
+		Related:     []string{"public_fallback_blocks", "public_rpc_endpoint", "preconf_bidder_public_broadcasts_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Failed to broadcast transaction to the public mempool",
+		Description: "The public RPC endpoint rejected the fallback broadcast or did not answer; the count starts over and the bids go on.",
+		Related:     []string{"public_rpc_endpoint", "preconf_bidder_public_broadcasts_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Maintenance job failed",
+		Description: "A maintenance job returned an error; it runs again at its next interval.",
+		Related:     []string{"disable_jobs", "preconf_bidder_job_runs_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Maintenance job panicked",
+		Description: "A maintenance job panicked; the panic was recovered and logged with its stack, and the job runs again at its next interval.",
+		Related:     []string{"disable_jobs", "preconf_bidder_job_runs_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Conflicting commitments for the same account nonce",
+		Description: "Two committed transactions share an account nonce; only the first committed one can land.",
+		Related:     []string{"conflict_log_file", "nonce_manager", "preconf_bidder_commitment_conflicts_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Rebuilt transaction after submission error",
+		Description: "A transaction rejected with nonce too low or replacement underpriced was re-signed and submitted again.",
+		Related:     []string{"nonce_manager", "preconf_bidder_tx_send_recoveries_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Reaped stale in-flight bid",
+		Description: "A bid still unresolved stale_bid_blocks after its target block was closed and dropped.",
+		Related:     []string{"stale_bid_blocks"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Bidder node unavailable, retrying bid",
+		Description: "The bidder node could not take the bid; it is sent again after a backoff.",
+		Related:     []string{"bid_retry_budget", "bid_retry_backoff", "preconf_bidder_bid_retries_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Bidder node connection lost, reconnecting",
+		Description: "The connection to the bidder node dropped; bids wait for it to be restored.",
+		Related:     []string{"server_address", "preconf_bidder_bidder_connected"},
+	},
+	{
+		Kind:        Event,
+		Name:        "WebSocket endpoint switched",
+		Description: "The block subscription failed over to another WebSocket endpoint.",
+		Related:     []string{"ws_endpoint", "preconf_bidder_ws_reconnects_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "WebSocket stall detected",
+		Description: "No header arrived on the block subscription for ws_stall_slots slots, so it was torn down and re-established; searchable as event=ws_stall_detected.",
+		Related:     []string{"ws_stall_slots", "ws_endpoint", "preconf_bidder_ws_stalls_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Backfilled blocks missed while reconnecting",
+		Description: "The block subscription reconnected after an outage, and the headers of the blocks missed meanwhile were read from the node; they are skipped, except the newest with bid_on_reconnect.",
+		Related:     []string{"backfill_blocks", "bid_on_reconnect", "preconf_bidder_backfilled_blocks_total"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Contract addresses resolved",
+		Description: "The mev-commit contract addresses in use, with the manifest source (remote, cache or embedded) and version.",
+		Related:     []string{"network", "contracts_url"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Clock skew detected, decay timestamps are affected",
+		Description: "The local clock differs from NTP or block time by more than the threshold.",
+		Related:     []string{"clock_skew_threshold", "clock_compensate", "ntp_server"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Instance moved to standby",
+		Description: "Another instance holds the coordination lease; this one stops bidding.",
+		Related:     []string{"ha_lease_file", "ha_instance_id", "ha_lease_ttl"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Account stuck, nonce is not advancing",
+		Description: "The account keeps bidding, but its nonce has not advanced for a while, so its transactions do not land.",
+		Related:     []string{"status_interval", "nonce_manager", "fee_bump"},
+	},
+	{
+		Kind:        Event,
+		Name:        "KZG trusted setup initialized",
+		Description: "The KZG trusted setup blob commitments are computed with was loaded at startup; took is how long it took, latency the first blob bid no longer pays.",
+		Related:     []string{"kzg_trusted_setup", "num_blob"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Dry run, bid not sent",
+		Description: "In dry-run mode, the bid that would have been sent, serialized as the bidder node would receive it; bundles and preconf RPC submissions are logged the same way.",
+		Related:     []string{"dry_run"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Spend budget exhausted, skipping bids",
+		Description: "Bids no longer fit in the hourly or daily budget; blocks are skipped with reason budget until committed bids age out.",
+		Related:     []string{"hourly_budget", "daily_budget", "budget_mode", "preconf_bidder_budget_remaining_eth"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Campaign prerequisites not met",
+		Description: "A scheduled campaign lacks the balance or deposit it needs; it starts once they are funded.",
+		Related:     []string{"campaign_schedule_file", "campaign_lead", "preconf_bidder_campaigns"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Bidding changed at runtime",
+		Description: "Bidding parameters were changed, or bidding paused or resumed, through /control on the status server or by a configuration reload; by names the token used without revealing it, or config reload.",
+		Related:     []string{"control_audit_file", "status_admin_tokens", "canary_percent"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Canary rolled back",
+		Description: "The canary bidding parameters had a lower acceptance rate than the baseline and were dropped.",
+		Related:     []string{"canary_percent", "canary_blocks"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Resource pressure, shedding optional work",
+		Description: "Resident memory or goroutines went above their threshold; market snapshots, observers, telemetry and dashboard sampling stop until usage is back below 90% of it.",
+		Related:     []string{"max_rss_mb", "max_goroutines", "preconf_bidder_degraded"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Configuration changed since previous run",
+		Description: "Settings differ from the snapshot of the last run, listed by key.",
+		Related:     []string{"config_snapshot_file"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Configuration reloaded",
+		Description: "On SIGHUP a changed setting was applied to the running bidder: bid amount and deviation, offset, decay bounds or the RPC endpoints.",
+		Related:     []string{"bid_amount", "offset", "decay_min", "rpc_endpoint"},
+	},
+	{
+		Kind:        Event,
+		Name:        "Configuration changed, restart to apply",
+		Description: "On SIGHUP a setting had changed that the running bidder cannot apply; it takes effect on the next start.",
+		Related:     []string{"config_snapshot_file"},
+	},
+}
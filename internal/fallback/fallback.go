@@ -0,0 +1,86 @@
+// Package fallback broadcasts transactions to the public mempool once no
+// provider committed to an account's bids for a number of blocks in a row, so
+// its transactions eventually land without a preconfirmation. The broadcast
+// transaction is the one of the last bid without a commitment; bids for later
+// blocks go on as usual and compete with it for its nonce.
+package fallback
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ee "github.com/primev/preconf_blob_bidder/internal/eth"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+// Sender broadcasts tx.
+type Sender func(ctx context.Context, tx *types.Transaction) error
+
+// RawTransaction returns a Sender broadcasting with eth_sendRawTransaction to
+// endpoint.
+func RawTransaction(endpoint string) Sender {
+	return func(ctx context.Context, tx *types.Transaction) error {
+		_, err := ee.SendRawTransactionContext(ctx, endpoint, tx)
+		return err
+	}
+}
+
+// Fallback counts the blocks each account's bids went without a commitment.
+type Fallback struct {
+	after int
+	send  Sender
+
+	mu     sync.Mutex
+	misses map[common.Address]int
+}
+
+// New returns a fallback broadcasting with send once after blocks in a row
+// went without a commitment.
+func New(after int, send Sender) *Fallback {
+	return &Fallback{after: after, send: send, misses: make(map[common.Address]int)}
+}
+
+// Resolved counts the resolved bid for tx of from. A commitment starts the
+// count over; without one, tx is broadcast once the count reaches the
+// threshold, which then starts over too. It reports whether tx was broadcast.
+func (f *Fallback) Resolved(ctx context.Context, from common.Address, tx *types.Transaction, committed bool) bool {
+	if f == nil || tx == nil {
+		return false
+	}
+	f.mu.Lock()
+	if committed {
+		delete(f.misses, from)
+		f.mu.Unlock()
+		return false
+	}
+	f.misses[from]++
+	due := f.misses[from] >= f.after
+	if due {
+		delete(f.misses, from)
+	}
+	f.mu.Unlock()
+	if !due {
+		return false
+	}
+
+	if err := f.send(ctx, tx); err != nil {
+		metrics.PublicBroadcasts.WithLabelValues("error").Inc()
+		slog.Warn("Failed to broadcast transaction to the public mempool",
+			"error", err,
+			"txHash", tx.Hash().String(),
+			"from", from.Hex(),
+		)
+		return false
+	}
+	metrics.PublicBroadcasts.WithLabelValues("ok").Inc()
+	slog.Info("Broadcast transaction to the public mempool",
+		"txHash", tx.Hash().String(),
+		"from", from.Hex(),
+		"nonce", tx.Nonce(),
+		"blocksWithoutCommitment", f.after,
+	)
+	return true
+}
@@ -0,0 +1,42 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallback(t *testing.T) {
+	var sent []uint64
+	fail := false
+	f := New(3, func(ctx context.Context, tx *types.Transaction) error {
+		if fail {
+			return errors.New("already known")
+		}
+		sent = append(sent, tx.Nonce())
+		return nil
+	})
+	a, b := common.Address{1}, common.Address{2}
+	tx := func(nonce uint64) *types.Transaction { return types.NewTx(&types.LegacyTx{Nonce: nonce}) }
+
+	require.False(t, f.Resolved(context.Background(), a, tx(1), false))
+	require.False(t, f.Resolved(context.Background(), a, tx(1), false))
+	require.False(t, f.Resolved(context.Background(), a, tx(1), true), "a commitment starts over")
+	require.False(t, f.Resolved(context.Background(), a, tx(2), false))
+	require.False(t, f.Resolved(context.Background(), b, tx(7), false), "accounts are counted separately")
+	require.False(t, f.Resolved(context.Background(), a, tx(2), false))
+	require.True(t, f.Resolved(context.Background(), a, tx(3), false))
+	require.Equal(t, []uint64{3}, sent, "the last transaction is broadcast")
+
+	require.False(t, f.Resolved(context.Background(), a, tx(3), false), "the count starts over")
+	fail = true
+	require.False(t, f.Resolved(context.Background(), b, tx(7), false))
+	require.False(t, f.Resolved(context.Background(), b, tx(7), false), "a failed broadcast is not reported as sent")
+
+	var nilFallback *Fallback
+	require.False(t, nilFallback.Resolved(context.Background(), a, tx(1), false))
+}
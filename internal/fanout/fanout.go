@@ -0,0 +1,248 @@
+// Package fanout drives stress tests of provider-side bid handling: many
+// distinct small bids per block from several accounts, bounded by a spend
+// cap, with acceptance statistics aggregated over the run.
+package fanout
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxBidsPerBlock is the most bids sent for a single block.
+const MaxBidsPerBlock = 500
+
+// Distribution is how bid amounts are drawn between the minimum and maximum.
+type Distribution string
+
+const (
+	// Uniform draws every amount in the range with the same probability.
+	Uniform Distribution = "uniform"
+	// Exponential draws mostly amounts near the minimum, with a tail towards
+	// the maximum.
+	Exponential Distribution = "exponential"
+)
+
+// ParseDistribution parses a distribution name. An empty value is Uniform.
+func ParseDistribution(value string) (Distribution, error) {
+	switch Distribution(strings.ToLower(strings.TrimSpace(value))) {
+	case "", Uniform:
+		return Uniform, nil
+	case Exponential:
+		return Exponential, nil
+	default:
+		return "", fmt.Errorf("invalid amount distribution %q (expected %s or %s)", value, Uniform, Exponential)
+	}
+}
+
+// Config is the shape of a fan-out run.
+type Config struct {
+	BidsPerBlock int
+	MinAmount    float64 // ETH.
+	MaxAmount    float64 // ETH.
+	Distribution Distribution
+	SpendCap     float64 // Most ETH the run may commit to, required.
+}
+
+// Validate reports settings that cannot work.
+func (c Config) Validate() error {
+	var problems []string
+	if c.BidsPerBlock < 1 || c.BidsPerBlock > MaxBidsPerBlock {
+		problems = append(problems, fmt.Sprintf("bids per block must be between 1 and %d", MaxBidsPerBlock))
+	}
+	if c.MinAmount <= 0 || c.MaxAmount < c.MinAmount {
+		problems = append(problems, "amounts must be positive, and the maximum at least the minimum")
+	}
+	if c.SpendCap <= 0 {
+		problems = append(problems, "a spend cap is required")
+	} else if c.SpendCap < c.MaxAmount {
+		problems = append(problems, "the spend cap must cover at least one bid of the maximum amount")
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Amount draws a bid amount.
+func (c Config) Amount(rng *rand.Rand) float64 {
+	span := c.MaxAmount - c.MinAmount
+	switch c.Distribution {
+	case Exponential:
+		// A mean of a quarter of the range keeps the tail well below the maximum
+		return c.MinAmount + math.Min(span, rng.ExpFloat64()*span/4)
+	default:
+		return c.MinAmount + rng.Float64()*span
+	}
+}
+
+// Budget enforces the spend cap. Every bid reserves its amount before it is
+// sent, so bids in flight can never commit more than the cap together; the
+// reservation is released when the bid resolves without a commitment.
+type Budget struct {
+	mu        sync.Mutex
+	cap       float64
+	committed float64
+	reserved  float64
+}
+
+// NewBudget returns a budget capping commitments at cap ETH.
+func NewBudget(cap float64) *Budget {
+	return &Budget{cap: cap}
+}
+
+// Reserve reports whether amount fits in the budget and reserves it if so.
+func (b *Budget) Reserve(amount float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.committed+b.reserved+amount > b.cap {
+		return false
+	}
+	b.reserved += amount
+	return true
+}
+
+// Settle releases a reservation of amount, counting it as spent when the bid
+// was committed.
+func (b *Budget) Settle(amount float64, committed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reserved -= amount
+	if committed {
+		b.committed += amount
+	}
+}
+
+// Spent returns the committed amount in ETH.
+func (b *Budget) Spent() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.committed
+}
+
+// Result is the outcome of one fan-out bid.
+type Result struct {
+	Account     string
+	Amount      float64
+	Sent        bool // Whether the bidder node accepted the bid.
+	Commitments int
+	Latency     time.Duration
+}
+
+// Summary aggregates the results of a run.
+type Summary struct {
+	Blocks         int                `json:"blocks"`
+	Bids           int                `json:"bids"`
+	Sent           int                `json:"sent"`
+	Failed         int                `json:"failed"`
+	Committed      int                `json:"committed"`
+	Commitments    int                `json:"commitments"`
+	CapSkipped     int                `json:"cap_skipped"` // Bids not sent because of the spend cap.
+	AcceptanceRate float64            `json:"acceptance_rate"`
+	SpendETH       float64            `json:"spend_eth"`
+	LatencyP50     time.Duration      `json:"latency_p50"`
+	LatencyP95     time.Duration      `json:"latency_p95"`
+	PerAccount     map[string]Account `json:"per_account"`
+}
+
+// Account is the share of a run sent from one account.
+type Account struct {
+	Sent      int `json:"sent"`
+	Committed int `json:"committed"`
+}
+
+// Stats collects the results of a run.
+type Stats struct {
+	mu         sync.Mutex
+	blocks     map[uint64]struct{}
+	results    []Result
+	capSkipped int
+}
+
+// NewStats returns empty statistics.
+func NewStats() *Stats {
+	return &Stats{blocks: make(map[uint64]struct{})}
+}
+
+// Record adds the result of a bid for block.
+func (s *Stats) Record(block uint64, r Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocks[block] = struct{}{}
+	s.results = append(s.results, r)
+}
+
+// CapReached counts a bid for block that was not sent because of the spend
+// cap.
+func (s *Stats) CapReached(block uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocks[block] = struct{}{}
+	s.capSkipped++
+}
+
+// Summary aggregates the results recorded so far.
+func (s *Stats) Summary() Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sum := Summary{
+		Blocks:     len(s.blocks),
+		Bids:       len(s.results) + s.capSkipped,
+		CapSkipped: s.capSkipped,
+		PerAccount: make(map[string]Account),
+	}
+	var latencies []time.Duration
+	for _, r := range s.results {
+		acct := sum.PerAccount[r.Account]
+		if !r.Sent {
+			sum.Failed++
+			continue
+		}
+		sum.Sent++
+		acct.Sent++
+		latencies = append(latencies, r.Latency)
+		if r.Commitments > 0 {
+			sum.Committed++
+			sum.Commitments += r.Commitments
+			sum.SpendETH += r.Amount
+			acct.Committed++
+		}
+		sum.PerAccount[r.Account] = acct
+	}
+	if sum.Sent > 0 {
+		sum.AcceptanceRate = float64(sum.Committed) / float64(sum.Sent)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	sum.LatencyP50 = percentile(latencies, 0.5)
+	sum.LatencyP95 = percentile(latencies, 0.95)
+	return sum
+}
+
+// Log logs the summary.
+func (sum Summary) Log(msg string) {
+	slog.Info(msg,
+		"blocks", sum.Blocks,
+		"bids", sum.Bids,
+		"sent", sum.Sent,
+		"failed", sum.Failed,
+		"committed", sum.Committed,
+		"capSkipped", sum.CapSkipped,
+		"acceptanceRate", fmt.Sprintf("%.3f", sum.AcceptanceRate),
+		"spendETH", sum.SpendETH,
+		"latencyP50", sum.LatencyP50,
+		"latencyP95", sum.LatencyP95,
+	)
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(math.Ceil(p*float64(len(sorted))))-1]
+}
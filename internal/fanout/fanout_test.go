@@ -0,0 +1,67 @@
+package fanout
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	cfg := Config{BidsPerBlock: 20, MinAmount: 0.0001, MaxAmount: 0.001, SpendCap: 0.05}
+	require.NoError(t, cfg.Validate())
+
+	cfg.SpendCap = 0
+	require.ErrorContains(t, cfg.Validate(), "a spend cap is required")
+	cfg = Config{BidsPerBlock: MaxBidsPerBlock + 1, MinAmount: 0.002, MaxAmount: 0.001, SpendCap: 0.0001}
+	err := cfg.Validate()
+	require.ErrorContains(t, err, "bids per block must be between")
+	require.ErrorContains(t, err, "maximum at least the minimum")
+	require.ErrorContains(t, err, "spend cap must cover")
+}
+
+func TestAmountStaysInRange(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, d := range []Distribution{Uniform, Exponential} {
+		cfg := Config{MinAmount: 0.0001, MaxAmount: 0.001, Distribution: d}
+		for i := 0; i < 1000; i++ {
+			a := cfg.Amount(rng)
+			require.GreaterOrEqual(t, a, cfg.MinAmount, d)
+			require.LessOrEqual(t, a, cfg.MaxAmount, d)
+		}
+	}
+}
+
+func TestBudgetCapsReservedAndCommitted(t *testing.T) {
+	b := NewBudget(1)
+	require.True(t, b.Reserve(0.6))
+	require.False(t, b.Reserve(0.6), "the first bid is still in flight")
+	b.Settle(0.6, false)
+	require.True(t, b.Reserve(0.6))
+	b.Settle(0.6, true)
+	require.False(t, b.Reserve(0.6))
+	require.True(t, b.Reserve(0.4))
+	require.InDelta(t, 0.6, b.Spent(), 1e-9)
+}
+
+func TestStatsSummary(t *testing.T) {
+	s := NewStats()
+	s.Record(10, Result{Account: "a", Amount: 0.1, Sent: true, Commitments: 2, Latency: time.Second})
+	s.Record(10, Result{Account: "b", Amount: 0.1, Sent: true, Latency: 3 * time.Second})
+	s.Record(11, Result{Account: "a", Amount: 0.1})
+	s.CapReached(12)
+
+	sum := s.Summary()
+	require.Equal(t, 3, sum.Blocks)
+	require.Equal(t, 4, sum.Bids)
+	require.Equal(t, 2, sum.Sent)
+	require.Equal(t, 1, sum.Failed)
+	require.Equal(t, 1, sum.Committed)
+	require.Equal(t, 1, sum.CapSkipped)
+	require.Equal(t, 0.5, sum.AcceptanceRate)
+	require.InDelta(t, 0.1, sum.SpendETH, 1e-9)
+	require.Equal(t, time.Second, sum.LatencyP50)
+	require.Equal(t, 3*time.Second, sum.LatencyP95)
+	require.Equal(t, Account{Sent: 1, Committed: 1}, sum.PerAccount["a"])
+}
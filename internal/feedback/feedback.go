@@ -0,0 +1,209 @@
+// Package feedback correlates sent bids with the commitments stored for them
+// on the mev-commit chain, to report whether each bid was committed, by which
+// providers and how long it took.
+package feedback
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+// DefaultTimeout is how long a bid waits for its commitments to be stored
+// before it is reported as not committed.
+const DefaultTimeout = 2 * time.Minute
+
+// Outcome is what happened to a tracked bid.
+type Outcome struct {
+	TxHash      string
+	TargetBlock uint64
+	Providers   []string      // Providers whose commitment was stored, in arrival order.
+	Latency     time.Duration // Time from sending the bid until the first commitment was stored.
+}
+
+// Committed reports whether any provider committed to the bid.
+func (o Outcome) Committed() bool {
+	return len(o.Providers) > 0
+}
+
+type bid struct {
+	Outcome
+	sent time.Time
+}
+
+// Tracker keeps sent bids until their commitments are stored or they time out.
+type Tracker struct {
+	// Notify, if set, is called with the outcome of every expired bid. Set it
+	// before the tracker is used.
+	Notify func(Outcome)
+
+	mu          sync.Mutex
+	bids        map[string]*bid
+	timeout     time.Duration
+	now         func() time.Time
+	expired     int                       // Bids expired since start.
+	providers   map[string]*providerTally // By provider address.
+	lastSummary time.Time
+}
+
+// NewTracker returns a tracker reporting bids without a stored commitment
+// after timeout.
+func NewTracker(timeout time.Duration) *Tracker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Tracker{
+		bids:      make(map[string]*bid),
+		timeout:   timeout,
+		now:       time.Now,
+		providers: make(map[string]*providerTally),
+	}
+}
+
+// normalize makes hashes from bids and events comparable; events carry the
+// transaction hash without 0x prefix.
+func normalize(txHash string) string {
+	return strings.ToLower(strings.TrimPrefix(txHash, "0x"))
+}
+
+// Track starts tracking a bid for txHash sent now.
+func (t *Tracker) Track(txHash string, targetBlock uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := normalize(txHash)
+	if _, ok := t.bids[key]; ok {
+		return
+	}
+	t.bids[key] = &bid{
+		Outcome: Outcome{TxHash: txHash, TargetBlock: targetBlock},
+		sent:    t.now(),
+	}
+}
+
+// Observe records a commitment stored by provider for txHash. It reports
+// whether the transaction belongs to a tracked bid.
+func (t *Tracker) Observe(txHash, provider string) bool {
+	return t.ObserveDispatched(txHash, provider, time.Time{})
+}
+
+// ObserveDispatched records a commitment stored by provider for txHash and
+// dispatched by the provider at dispatched, which may be zero when unknown.
+// It reports whether the transaction belongs to a tracked bid.
+func (t *Tracker) ObserveDispatched(txHash, provider string, dispatched time.Time) bool {
+	t.mu.Lock()
+	b, ok := t.bids[normalize(txHash)]
+	if !ok {
+		t.mu.Unlock()
+		return false
+	}
+	latency := t.now().Sub(b.sent)
+	first := !b.Committed()
+	if first {
+		b.Latency = latency
+	}
+	repeated := false
+	for _, p := range b.Providers {
+		repeated = repeated || p == provider
+	}
+	b.Providers = append(b.Providers, provider)
+	outcome := b.Outcome
+	var dispatchLatency time.Duration
+	if !dispatched.IsZero() && !repeated {
+		dispatchLatency = dispatched.Sub(b.sent)
+		t.tally(provider).dispatched(dispatchLatency)
+	}
+	t.mu.Unlock()
+
+	if first {
+		metrics.BidsCommittedOnChain.Inc()
+	}
+	metrics.CommitmentStoredLatency.Observe(latency.Seconds())
+	attrs := []any{
+		"txHash", outcome.TxHash,
+		"targetBlock", outcome.TargetBlock,
+		"provider", provider,
+		"latency", latency,
+		"providers", len(outcome.Providers),
+	}
+	if !dispatched.IsZero() && !repeated {
+		metrics.ProviderDispatchLatency.WithLabelValues(provider).Observe(dispatchLatency.Seconds())
+		attrs = append(attrs, "dispatchLatency", dispatchLatency)
+	}
+	slog.Info("Bid commitment stored", attrs...)
+	return true
+}
+
+// Expire stops tracking bids sent more than the timeout ago and returns their
+// outcomes, sorted by target block. Bids without a stored commitment are
+// logged and counted as not committed.
+func (t *Tracker) Expire() []Outcome {
+	t.mu.Lock()
+	now := t.now()
+	var expired []Outcome
+	for key, b := range t.bids {
+		if now.Sub(b.sent) > t.timeout {
+			expired = append(expired, b.Outcome)
+			delete(t.bids, key)
+		}
+	}
+	t.countExpired(expired)
+	var summary []ProviderStats
+	if len(t.providers) > 0 && now.Sub(t.lastSummary) >= SummaryInterval {
+		t.lastSummary = now
+		summary = t.providerStats()
+	}
+	t.mu.Unlock()
+
+	for _, p := range summary {
+		slog.Info("Provider commitment statistics",
+			"provider", p.Provider,
+			"committed", p.Committed,
+			"bids", p.Bids,
+			"commitRate", p.CommitRate,
+			"avgDispatchLatency", p.AvgDispatchLatency,
+		)
+	}
+
+	sort.Slice(expired, func(i, j int) bool { return expired[i].TargetBlock < expired[j].TargetBlock })
+	for _, o := range expired {
+		if t.Notify != nil {
+			t.Notify(o)
+		}
+		if o.Committed() {
+			continue
+		}
+		metrics.BidsNotCommittedOnChain.Inc()
+		slog.Info("Bid not committed",
+			"txHash", o.TxHash,
+			"targetBlock", o.TargetBlock,
+			"timeout", t.timeout,
+		)
+	}
+	return expired
+}
+
+// Len returns the number of tracked bids.
+func (t *Tracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.bids)
+}
+
+// Run expires bids every interval until ctx is canceled.
+func (t *Tracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.Expire()
+		}
+	}
+}
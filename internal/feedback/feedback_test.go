@@ -0,0 +1,61 @@
+package feedback
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackerCorrelatesCommitments(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tr := NewTracker(time.Minute)
+	tr.now = func() time.Time { return now }
+	var notified []Outcome
+	tr.Notify = func(o Outcome) { notified = append(notified, o) }
+
+	tr.Track("0xAB01", 10)
+	tr.Track("0xcd02", 11)
+
+	now = now.Add(3 * time.Second)
+	require.True(t, tr.Observe("ab01", "0xprovider1"), "events carry lower case hashes without 0x")
+	now = now.Add(time.Second)
+	require.True(t, tr.Observe("ab01", "0xprovider2"))
+	require.False(t, tr.Observe("ef03", "0xprovider1"), "not our bid")
+
+	now = now.Add(30 * time.Second)
+	require.Empty(t, tr.Expire(), "still within the timeout")
+
+	now = now.Add(time.Minute)
+	outcomes := tr.Expire()
+	require.Len(t, outcomes, 2)
+	require.Equal(t, uint64(10), outcomes[0].TargetBlock)
+	require.True(t, outcomes[0].Committed())
+	require.Equal(t, []string{"0xprovider1", "0xprovider2"}, outcomes[0].Providers)
+	require.Equal(t, 3*time.Second, outcomes[0].Latency)
+	require.False(t, outcomes[1].Committed())
+	require.Zero(t, tr.Len())
+	require.Equal(t, outcomes, notified)
+}
+
+func TestProviderStats(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tr := NewTracker(time.Minute)
+	tr.now = func() time.Time { return now }
+
+	tr.Track("0x01", 10)
+	tr.Track("0x02", 11)
+	require.True(t, tr.ObserveDispatched("01", "0xprovider1", now.Add(200*time.Millisecond)))
+	require.True(t, tr.ObserveDispatched("01", "0xprovider1", now.Add(200*time.Millisecond)), "duplicate event")
+	require.True(t, tr.ObserveDispatched("02", "0xprovider1", now.Add(400*time.Millisecond)))
+	require.True(t, tr.ObserveDispatched("02", "0xprovider2", now.Add(time.Second)))
+	require.True(t, tr.Observe("02", "0xprovider3"), "without dispatch timestamp")
+
+	now = now.Add(2 * time.Minute)
+	tr.Expire()
+	require.Equal(t, []ProviderStats{
+		{Provider: "0xprovider1", Bids: 2, Committed: 2, CommitRate: 1, AvgDispatchLatency: 300 * time.Millisecond},
+		{Provider: "0xprovider2", Bids: 2, Committed: 1, CommitRate: 0.5, AvgDispatchLatency: time.Second},
+		{Provider: "0xprovider3", Bids: 2, Committed: 1, CommitRate: 0.5},
+	}, tr.Providers())
+}
@@ -0,0 +1,91 @@
+package feedback
+
+import (
+	"sort"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+// SummaryInterval is how often the provider statistics are logged.
+const SummaryInterval = time.Hour
+
+// ProviderStats is how a provider committed to our bids since the start.
+type ProviderStats struct {
+	Provider  string `json:"provider"`
+	Bids      int    `json:"bids"`      // Bids expired since the start; every bid is offered to every provider.
+	Committed int    `json:"committed"` // Of which the provider committed to.
+	// CommitRate is Committed over Bids.
+	CommitRate float64 `json:"commit_rate"`
+	// AvgDispatchLatency is the average time from sending a bid until the
+	// provider dispatched its commitment.
+	AvgDispatchLatency time.Duration `json:"avg_dispatch_latency"`
+}
+
+// providerTally accumulates the commitments of a provider.
+type providerTally struct {
+	committed     int
+	dispatches    int
+	dispatchTotal time.Duration
+}
+
+func (p *providerTally) dispatched(latency time.Duration) {
+	p.dispatches++
+	p.dispatchTotal += latency
+}
+
+// tally returns the tally of provider. t.mu must be held.
+func (t *Tracker) tally(provider string) *providerTally {
+	p, ok := t.providers[provider]
+	if !ok {
+		p = &providerTally{}
+		t.providers[provider] = p
+	}
+	return p
+}
+
+// countExpired adds expired bids to the provider statistics and updates the
+// commit rate gauges. t.mu must be held.
+func (t *Tracker) countExpired(expired []Outcome) {
+	if len(expired) == 0 {
+		return
+	}
+	t.expired += len(expired)
+	for _, o := range expired {
+		seen := make(map[string]bool, len(o.Providers))
+		for _, provider := range o.Providers {
+			if !seen[provider] {
+				seen[provider] = true
+				t.tally(provider).committed++
+			}
+		}
+	}
+	for provider, p := range t.providers {
+		metrics.ProviderCommitRate.WithLabelValues(provider).Set(float64(p.committed) / float64(t.expired))
+	}
+}
+
+// providerStats returns the statistics of every provider. t.mu must be held.
+func (t *Tracker) providerStats() []ProviderStats {
+	stats := make([]ProviderStats, 0, len(t.providers))
+	for provider, p := range t.providers {
+		s := ProviderStats{Provider: provider, Bids: t.expired, Committed: p.committed}
+		if t.expired > 0 {
+			s.CommitRate = float64(p.committed) / float64(t.expired)
+		}
+		if p.dispatches > 0 {
+			s.AvgDispatchLatency = p.dispatchTotal / time.Duration(p.dispatches)
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Provider < stats[j].Provider })
+	return stats
+}
+
+// Providers returns the statistics of every provider that committed to one of
+// our bids, by address.
+func (t *Tracker) Providers() []ProviderStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.providerStats()
+}
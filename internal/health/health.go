@@ -0,0 +1,171 @@
+// Package health serves the probes of process supervisors such as Kubernetes
+// and external monitors: /healthz reports whether the bidder is alive,
+// /readyz whether it can bid, and /status what it is doing, as JSON. The
+// bidder is alive while head blocks keep arriving, and ready while they are
+// recent and the connections in use are healthy.
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/dashboard"
+	"github.com/primev/preconf_blob_bidder/internal/jobs"
+	"github.com/primev/preconf_blob_bidder/internal/outcome"
+	"github.com/primev/preconf_blob_bidder/internal/pressure"
+)
+
+const (
+	// DefaultLiveAfter is how long without a head block, or after the start
+	// before the first one, the bidder is no longer alive.
+	DefaultLiveAfter = 2 * time.Minute
+	// DefaultReadyAfter is how long without a head block the bidder is no
+	// longer ready, three slots.
+	DefaultReadyAfter = 36 * time.Second
+)
+
+// Commitment is the last committed bid.
+type Commitment struct {
+	TargetBlock uint64    `json:"target_block"`
+	TxHash      string    `json:"tx_hash"`
+	Providers   []string  `json:"providers"`
+	At          time.Time `json:"at"`
+}
+
+// Status is the JSON served on /status.
+type Status struct {
+	Live           bool                   `json:"live"`
+	Ready          bool                   `json:"ready"`
+	Problems       []string               `json:"problems,omitempty"` // Why the bidder is not ready.
+	Block          uint64                 `json:"block"`              // Latest head block, 0 before the first.
+	BlockAt        time.Time              `json:"block_at,omitempty"`
+	BidsInFlight   int                    `json:"bids_in_flight"`
+	LastCommitment *Commitment            `json:"last_commitment,omitempty"`
+	Connections    []dashboard.Connection `json:"connections"`
+	Pressure       *pressure.State        `json:"pressure,omitempty"` // Resource use, and whether optional work is shed.
+	Jobs           []jobs.Status          `json:"jobs,omitempty"`     // Last runs of the maintenance jobs.
+	Uptime         string                 `json:"uptime"`
+}
+
+// Monitor follows the head block and commitments and answers the probes.
+type Monitor struct {
+	// Pressure, if set, reports resource use and degradation on /status;
+	// degradation does not affect readiness. Set it before the monitor is
+	// used.
+	Pressure func() pressure.State
+	// Jobs, if set, reports the maintenance jobs on /status; failing jobs do
+	// not affect readiness. Set it before the monitor is used.
+	Jobs func() []jobs.Status
+
+	connections func() []dashboard.Connection
+	inFlight    func() int
+	now         func() time.Time
+	started     time.Time
+
+	mu         sync.Mutex
+	block      uint64
+	blockAt    time.Time
+	commitment *Commitment
+}
+
+// New returns a monitor reporting the connections and the number of bids in
+// flight returned by the functions, either of which may be nil.
+func New(connections func() []dashboard.Connection, inFlight func() int) *Monitor {
+	return &Monitor{connections: connections, inFlight: inFlight, now: time.Now, started: time.Now()}
+}
+
+// Head records a new head block.
+func (m *Monitor) Head(block uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.block, m.blockAt = block, m.now()
+}
+
+// Outcome records the outcome of a bid, remembering it when it was committed.
+func (m *Monitor) Outcome(o outcome.BlockOutcome) {
+	if !o.Committed() {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commitment = &Commitment{TargetBlock: o.TargetBlock, TxHash: o.Payload.TxHash, Providers: o.Providers(), At: m.now()}
+}
+
+// Status returns the current status.
+func (m *Monitor) Status() Status {
+	var connections []dashboard.Connection
+	if m.connections != nil {
+		connections = m.connections()
+	}
+	s := Status{Connections: connections}
+	if m.inFlight != nil {
+		s.BidsInFlight = m.inFlight()
+	}
+	if m.Pressure != nil {
+		p := m.Pressure()
+		s.Pressure = &p
+	}
+	if m.Jobs != nil {
+		s.Jobs = m.Jobs()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	s.Block, s.BlockAt, s.Uptime = m.block, m.blockAt, now.Sub(m.started).Round(time.Second).String()
+	if m.commitment != nil {
+		c := *m.commitment
+		s.LastCommitment = &c
+	}
+	since := m.blockAt
+	if since.IsZero() {
+		since = m.started
+	}
+	s.Live = now.Sub(since) < DefaultLiveAfter
+	if m.blockAt.IsZero() {
+		s.Problems = append(s.Problems, "no head block received yet")
+	} else if age := now.Sub(m.blockAt); age >= DefaultReadyAfter {
+		s.Problems = append(s.Problems, "last head block received "+age.Round(time.Second).String()+" ago")
+	}
+	for _, c := range connections {
+		if !c.Healthy && !c.Standby {
+			s.Problems = append(s.Problems, c.Name+" connection is down")
+		}
+	}
+	s.Ready = len(s.Problems) == 0
+	return s
+}
+
+// ServeHealthz answers the liveness probe.
+func (m *Monitor) ServeHealthz(w http.ResponseWriter, _ *http.Request) {
+	s := m.Status()
+	if !s.Live {
+		http.Error(w, "no head block for "+DefaultLiveAfter.String(), http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+// ServeReadyz answers the readiness probe, listing the problems when the
+// bidder is not ready.
+func (m *Monitor) ServeReadyz(w http.ResponseWriter, _ *http.Request) {
+	s := m.Status()
+	if !s.Ready {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		for _, problem := range s.Problems {
+			_, _ = w.Write([]byte(problem + "\n"))
+		}
+		return
+	}
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+// ServeStatus serves the status as JSON.
+func (m *Monitor) ServeStatus(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(m.Status())
+}
@@ -0,0 +1,87 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"github.com/primev/preconf_blob_bidder/internal/dashboard"
+	"github.com/primev/preconf_blob_bidder/internal/outcome"
+	"github.com/primev/preconf_blob_bidder/internal/pressure"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbes(t *testing.T) {
+	healthy := true
+	m := New(func() []dashboard.Connection {
+		return []dashboard.Connection{
+			{Name: "bidder node", Healthy: healthy},
+			{Name: "ws fallback 1", Standby: true},
+		}
+	}, func() int { return 2 })
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	m.started = now
+
+	probe := func(handler http.HandlerFunc) (int, string) {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Code, rec.Body.String()
+	}
+
+	code, _ := probe(m.ServeHealthz)
+	require.Equal(t, http.StatusOK, code, "alive while starting up")
+	code, body := probe(m.ServeReadyz)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Contains(t, body, "no head block received yet")
+
+	m.Head(100)
+	code, _ = probe(m.ServeReadyz)
+	require.Equal(t, http.StatusOK, code)
+
+	healthy = false
+	code, body = probe(m.ServeReadyz)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Contains(t, body, "bidder node connection is down")
+
+	healthy = true
+	now = now.Add(time.Minute)
+	code, body = probe(m.ServeReadyz)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Contains(t, body, "last head block received 1m0s ago")
+	code, _ = probe(m.ServeHealthz)
+	require.Equal(t, http.StatusOK, code)
+
+	now = now.Add(DefaultLiveAfter)
+	code, _ = probe(m.ServeHealthz)
+	require.Equal(t, http.StatusServiceUnavailable, code, "dead once head blocks stop")
+}
+
+func TestStatus(t *testing.T) {
+	m := New(nil, func() int { return 3 })
+	m.Head(100)
+	m.Outcome(outcome.BlockOutcome{TargetBlock: 101, Payload: outcome.Payload{TxHash: "0x01"}})
+	require.Nil(t, m.Status().LastCommitment, "uncommitted bids are not commitments")
+
+	m.Outcome(outcome.BlockOutcome{
+		TargetBlock: 102,
+		Payload:     outcome.Payload{TxHash: "0x02"},
+		Commitments: []*pb.Commitment{{ProviderAddress: "0xprovider"}},
+	})
+	m.Pressure = func() pressure.State {
+		return pressure.State{Usage: pressure.Usage{Goroutines: 900}, Degraded: true, Shed: []string{"telemetry"}}
+	}
+	rec := httptest.NewRecorder()
+	m.ServeStatus(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	require.Contains(t, body, `"block":100`)
+	require.Contains(t, body, `"bids_in_flight":3`)
+	require.Contains(t, body, `"target_block":102`)
+	require.Contains(t, body, `"providers":["0xprovider"]`)
+	require.Contains(t, body, `"ready":true`, "degradation does not affect readiness")
+	require.Contains(t, body, `"degraded":true`)
+	require.Contains(t, body, `"shed":["telemetry"]`)
+}
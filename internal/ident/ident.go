@@ -0,0 +1,145 @@
+// Package ident identifies the bidder's traffic to the nodes, relays and the
+// bidder node it talks to. Every HTTP request carries a User-Agent naming the
+// deployment and its own X-Request-ID; WebSocket connections carry both on
+// their handshake, and gRPC calls in their metadata. Node providers and
+// Primev can then pick the requests of one deployment out of their logs, and
+// a request ID logged by the bidder leads to the matching request on their
+// side.
+package ident
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader is the header, and in lower case the gRPC metadata key,
+// carrying the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+var (
+	mu        sync.RWMutex
+	userAgent = "preconf_bidder"
+	prefix    string
+)
+
+// DefaultUserAgent returns the User-Agent of app at version.
+func DefaultUserAgent(app, version string) string {
+	if version == "" {
+		return app
+	}
+	return app + "/" + version
+}
+
+// Set sets the User-Agent and the prefix of request IDs, none when empty.
+func Set(agent, requestIDPrefix string) {
+	mu.Lock()
+	defer mu.Unlock()
+	userAgent, prefix = agent, requestIDPrefix
+}
+
+// UserAgent returns the User-Agent sent with every request.
+func UserAgent() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return userAgent
+}
+
+// NewRequestID returns a new request ID: a random UUID after the prefix.
+func NewRequestID() string {
+	mu.RLock()
+	p := prefix
+	mu.RUnlock()
+	if p == "" {
+		return uuid.NewString()
+	}
+	return p + "-" + uuid.NewString()
+}
+
+// transport sets the identification headers a request does not have yet.
+type transport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" || req.Header.Get(RequestIDHeader) == "" {
+		req = req.Clone(req.Context())
+		if req.Header.Get("User-Agent") == "" {
+			req.Header.Set("User-Agent", UserAgent())
+		}
+		if req.Header.Get(RequestIDHeader) == "" {
+			req.Header.Set(RequestIDHeader, NewRequestID())
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// Transport returns base setting the identification headers on every request.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return transport{base: base}
+}
+
+var installOnce sync.Once
+
+// Install makes the default HTTP transport set the identification headers,
+// covering every client built without a transport of its own, including the
+// JSON-RPC clients of HTTP endpoints.
+func Install() {
+	installOnce.Do(func() {
+		http.DefaultTransport = Transport(http.DefaultTransport)
+	})
+}
+
+// Dial connects to the JSON-RPC endpoint. A WebSocket connection is
+// identified on its handshake, with one request ID for the connection; HTTP
+// requests are identified one by one by the installed transport.
+func Dial(ctx context.Context, endpoint string) (*rpc.Client, error) {
+	var opts []rpc.ClientOption
+	if strings.HasPrefix(endpoint, "ws://") || strings.HasPrefix(endpoint, "wss://") {
+		header := http.Header{}
+		header.Set("User-Agent", UserAgent())
+		header.Set(RequestIDHeader, NewRequestID())
+		opts = append(opts, rpc.WithHeaders(header))
+	}
+	return rpc.DialOptions(ctx, endpoint, opts...)
+}
+
+// DialEth is like Dial, but returns an ethclient.
+func DialEth(ctx context.Context, endpoint string) (*ethclient.Client, error) {
+	client, err := Dial(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(client), nil
+}
+
+// withRequestID adds a request ID to the outgoing metadata of ctx unless it
+// has one.
+func withRequestID(ctx context.Context) context.Context {
+	key := strings.ToLower(RequestIDHeader)
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(key)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, key, NewRequestID())
+}
+
+// DialOptions returns the options identifying the calls of a gRPC client.
+func DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithUserAgent(UserAgent()),
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(withRequestID(ctx), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(withRequestID(ctx), desc, cc, method, opts...)
+		}),
+	}
+}
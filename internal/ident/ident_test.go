@@ -0,0 +1,57 @@
+package ident
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestTransport(t *testing.T) {
+	Set("bidder/1.0 (eu-1)", "eu-1")
+	defer Set("preconf_bidder", "")
+
+	var agents, ids []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.Header.Get("User-Agent"))
+		ids = append(ids, r.Header.Get(RequestIDHeader))
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: Transport(http.DefaultTransport)}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(ts.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(RequestIDHeader, "fixed")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, []string{"bidder/1.0 (eu-1)", "bidder/1.0 (eu-1)", "bidder/1.0 (eu-1)"}, agents)
+	require.True(t, strings.HasPrefix(ids[0], "eu-1-"), ids[0])
+	require.NotEqual(t, ids[0], ids[1], "one ID per request")
+	require.Equal(t, "fixed", ids[2], "a request ID set by the caller is kept")
+	require.Empty(t, req.Header.Get("User-Agent"), "the caller's request is not modified")
+}
+
+func TestDefaultUserAgent(t *testing.T) {
+	require.Equal(t, "preconf_bidder/0.8.0", DefaultUserAgent("preconf_bidder", "0.8.0"))
+	require.Equal(t, "preconf_bidder", DefaultUserAgent("preconf_bidder", ""))
+}
+
+func TestWithRequestID(t *testing.T) {
+	md, _ := metadata.FromOutgoingContext(withRequestID(context.Background()))
+	require.Len(t, md.Get("x-request-id"), 1)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "fixed")
+	md, _ = metadata.FromOutgoingContext(withRequestID(ctx))
+	require.Equal(t, []string{"fixed"}, md.Get("x-request-id"))
+}
@@ -0,0 +1,212 @@
+// Package inclusion checks whether the transactions of our bids were actually
+// included in their target block, as read from the node, and compares that
+// with the commitments the bids received. A committed transaction in its
+// target block is a preconfirmation honored by its providers, one missing from
+// it is violated; a transaction included in its target block without a
+// commitment landed on its own. The discrepancies are recorded.
+package inclusion
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+// CheckDelay is how many blocks after a target block it is checked, so a
+// target block replaced by a short reorg is read as finally included.
+const CheckDelay = 2
+
+// Result is how the commitments of a bid compare with the target block.
+type Result string
+
+const (
+	// Honored means the bid was committed and its transaction included in
+	// the target block.
+	Honored Result = "honored"
+	// Violated means the bid was committed but its transaction was missing
+	// from the target block.
+	Violated Result = "violated"
+	// Uncommitted means the transaction was included in the target block
+	// although the bid was not committed.
+	Uncommitted Result = "uncommitted"
+)
+
+// Bid is a bid whose target block is checked.
+type Bid struct {
+	TxHash      string
+	TargetBlock uint64
+	Providers   []string // Those that committed to the bid.
+}
+
+// Record is the result of checking a bid against its target block.
+type Record struct {
+	Time          time.Time `json:"time"`
+	TxHash        string    `json:"tx_hash"`
+	TargetBlock   uint64    `json:"target_block"`
+	BlockHash     string    `json:"block_hash"`
+	Providers     []string  `json:"providers,omitempty"`
+	Result        Result    `json:"result"`
+	IncludedBlock uint64    `json:"included_block,omitempty"` // 0 while not included anywhere.
+}
+
+// Discrepancy reports whether the commitments of the bid and its inclusion
+// disagree.
+func (r Record) Discrepancy() bool {
+	return r.Result != Honored
+}
+
+// Watcher keeps bids until their target block is checked.
+type Watcher struct {
+	f   *os.File
+	enc *json.Encoder
+	now func() time.Time
+
+	mu      sync.Mutex
+	pending map[uint64][]Bid // By target block.
+}
+
+// Open returns a watcher appending discrepancies to path as JSON lines, or
+// only logging them when path is empty.
+func Open(path string) (*Watcher, error) {
+	w := &Watcher{now: time.Now, pending: make(map[uint64][]Bid)}
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open inclusion log: %w", err)
+		}
+		w.f, w.enc = f, json.NewEncoder(f)
+	}
+	return w, nil
+}
+
+// Close closes the inclusion log.
+func (w *Watcher) Close() error {
+	if w == nil || w.f == nil {
+		return nil
+	}
+	return w.f.Close()
+}
+
+// Watch keeps b until its target block is checked.
+func (w *Watcher) Watch(b Bid) {
+	if w == nil || b.TxHash == "" {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending[b.TargetBlock] = append(w.pending[b.TargetBlock], b)
+}
+
+// Due returns the target blocks with kept bids that are at least CheckDelay
+// blocks behind head, in ascending order.
+func (w *Watcher) Due(head uint64) []uint64 {
+	if w == nil || head < CheckDelay {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var due []uint64
+	for block := range w.pending {
+		if block <= head-CheckDelay {
+			due = append(due, block)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i] < due[j] })
+	return due
+}
+
+// Take returns and forgets the bids kept for a target block.
+func (w *Watcher) Take(block uint64) []Bid {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	bids := w.pending[block]
+	delete(w.pending, block)
+	return bids
+}
+
+// normalize makes hashes from bids and blocks comparable.
+func normalize(hash string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(hash), "0x"))
+}
+
+// Check returns a record for every bid of bids for block, the target block,
+// that was committed or included. Bids neither committed nor included agree.
+func (w *Watcher) Check(block *types.Block, bids []Bid) []Record {
+	included := make(map[string]bool, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		included[normalize(tx.Hash().Hex())] = true
+	}
+	var records []Record
+	for _, b := range bids {
+		r := Record{
+			Time:        w.now().UTC(),
+			TxHash:      b.TxHash,
+			TargetBlock: block.NumberU64(),
+			BlockHash:   block.Hash().Hex(),
+			Providers:   b.Providers,
+		}
+		in := included[normalize(b.TxHash)]
+		switch {
+		case len(b.Providers) > 0 && in:
+			r.Result = Honored
+		case len(b.Providers) > 0:
+			r.Result = Violated
+		case in:
+			r.Result = Uncommitted
+		default:
+			continue
+		}
+		if in {
+			r.IncludedBlock = block.NumberU64()
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+// Record counts r by provider, and logs a discrepancy and appends it to the
+// inclusion log.
+func (w *Watcher) Record(r Record) {
+	for _, provider := range r.Providers {
+		metrics.PreconfInclusions.WithLabelValues(provider, string(r.Result)).Inc()
+	}
+	if r.Result == Uncommitted {
+		metrics.UncommittedInclusions.Inc()
+	}
+	if !r.Discrepancy() {
+		slog.Debug("Preconfirmation honored", "txHash", r.TxHash, "targetBlock", r.TargetBlock, "providers", r.Providers)
+		return
+	}
+	switch r.Result {
+	case Violated:
+		slog.Warn("Preconfirmation violated",
+			"txHash", r.TxHash,
+			"targetBlock", r.TargetBlock,
+			"blockHash", r.BlockHash,
+			"providers", r.Providers,
+			"includedBlock", r.IncludedBlock,
+		)
+	default:
+		slog.Info("Transaction included without a commitment",
+			"txHash", r.TxHash,
+			"targetBlock", r.TargetBlock,
+			"blockHash", r.BlockHash,
+		)
+	}
+	if w.enc == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.enc.Encode(r); err != nil {
+		slog.Warn("Failed to write inclusion log", "error", err)
+	}
+}
@@ -0,0 +1,75 @@
+package inclusion
+
+import (
+	"bufio"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	honored := types.NewTx(&types.LegacyTx{Nonce: 1})
+	violated := types.NewTx(&types.LegacyTx{Nonce: 2})
+	uncommitted := types.NewTx(&types.LegacyTx{Nonce: 3})
+	missed := types.NewTx(&types.LegacyTx{Nonce: 4})
+
+	path := filepath.Join(t.TempDir(), "inclusions.jsonl")
+	w, err := Open(path)
+	require.NoError(t, err)
+
+	w.Watch(Bid{TxHash: honored.Hash().Hex(), TargetBlock: 100, Providers: []string{"0xa", "0xb"}})
+	w.Watch(Bid{TxHash: violated.Hash().Hex(), TargetBlock: 100, Providers: []string{"0xa"}})
+	w.Watch(Bid{TxHash: uncommitted.Hash().Hex()[2:], TargetBlock: 100})
+	w.Watch(Bid{TxHash: missed.Hash().Hex(), TargetBlock: 100})
+	w.Watch(Bid{TxHash: honored.Hash().Hex(), TargetBlock: 101})
+
+	require.Empty(t, w.Due(101), "not due before CheckDelay blocks")
+	require.Equal(t, []uint64{100, 101}, w.Due(103))
+	bids := w.Take(100)
+	require.Len(t, bids, 4)
+	require.Equal(t, []uint64{101}, w.Due(103))
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(100)}).
+		WithBody(types.Body{Transactions: types.Transactions{honored, uncommitted}})
+	records := w.Check(block, bids)
+	require.Len(t, records, 3, "bids neither committed nor included agree")
+	require.Equal(t, Honored, records[0].Result)
+	require.Equal(t, uint64(100), records[0].IncludedBlock)
+	require.False(t, records[0].Discrepancy())
+	require.Equal(t, Violated, records[1].Result)
+	require.Zero(t, records[1].IncludedBlock)
+	require.Equal(t, Uncommitted, records[2].Result)
+	require.Equal(t, block.Hash().Hex(), records[2].BlockHash)
+
+	for _, r := range records {
+		w.Record(r)
+	}
+	require.NoError(t, w.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	var written []Record
+	for scanner.Scan() {
+		var r Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		written = append(written, r)
+	}
+	require.Len(t, written, 2, "only discrepancies are logged")
+	require.Equal(t, Violated, written[0].Result)
+	require.Equal(t, []string{"0xa"}, written[0].Providers)
+	require.Equal(t, Uncommitted, written[1].Result)
+}
+
+func TestNilWatcher(t *testing.T) {
+	var w *Watcher
+	w.Watch(Bid{TxHash: "0x01", TargetBlock: 1})
+	require.Empty(t, w.Due(10))
+	require.NoError(t, w.Close())
+}
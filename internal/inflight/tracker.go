@@ -0,0 +1,191 @@
+// Package inflight tracks bids that have been dispatched but not yet resolved
+// and reaps the ones whose target block has long passed, so flaky providers
+// cannot make bid state, open streams or nonce reservations grow without bound.
+package inflight
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultMaxAgeBlocks is how many blocks past its target a bid may stay in flight.
+const DefaultMaxAgeBlocks = 2
+
+// Bid is the state kept for a single in-flight bid.
+type Bid struct {
+	TxHash      string
+	TargetBlock uint64
+	Nonce       uint64
+	SentAt      time.Time
+
+	key    string
+	cancel context.CancelFunc
+}
+
+// Tracker holds all in-flight bids.
+type Tracker struct {
+	mu           sync.Mutex
+	bids         map[string]*Bid
+	maxAgeBlocks uint64
+	onReap       func(Bid)
+	running      sync.WaitGroup // Bids whose done function has not been called yet.
+}
+
+// NewTracker creates a tracker. onReap, if not nil, is called for every reaped
+// bid so owners can release resources such as nonce reservations.
+func NewTracker(maxAgeBlocks uint64, onReap func(Bid)) *Tracker {
+	return &Tracker{
+		bids:         make(map[string]*Bid),
+		maxAgeBlocks: maxAgeBlocks,
+		onReap:       onReap,
+	}
+}
+
+// Start registers a bid and returns a context that is canceled when the bid is
+// reaped, plus a function to call once the bid has resolved. A bid still in
+// flight for the same transaction is replaced.
+func (t *Tracker) Start(parent context.Context, txHash string, targetBlock, nonce uint64) (context.Context, func()) {
+	return t.start(parent, txHash, txHash, targetBlock, nonce)
+}
+
+// StartTarget is like Start, but only replaces a bid for the same transaction
+// and target block, so a transaction can be bid for several blocks at once.
+func (t *Tracker) StartTarget(parent context.Context, txHash string, targetBlock, nonce uint64) (context.Context, func()) {
+	return t.start(parent, fmt.Sprintf("%s@%d", txHash, targetBlock), txHash, targetBlock, nonce)
+}
+
+func (t *Tracker) start(parent context.Context, key, txHash string, targetBlock, nonce uint64) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	bid := &Bid{
+		TxHash:      txHash,
+		TargetBlock: targetBlock,
+		Nonce:       nonce,
+		SentAt:      time.Now(),
+		key:         key,
+		cancel:      cancel,
+	}
+
+	t.mu.Lock()
+	if prev, ok := t.bids[key]; ok {
+		prev.cancel()
+	}
+	t.bids[key] = bid
+	t.mu.Unlock()
+
+	t.running.Add(1)
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			t.complete(bid)
+			t.running.Done()
+		})
+	}
+}
+
+// complete removes a resolved bid unless it has been replaced already.
+func (t *Tracker) complete(bid *Bid) {
+	t.mu.Lock()
+	if cur, ok := t.bids[bid.key]; ok && cur == bid {
+		delete(t.bids, bid.key)
+	}
+	t.mu.Unlock()
+	bid.cancel()
+}
+
+// Cancel cancels and removes every in-flight bid for txHash, e.g. the other
+// targets of a transaction that lost a nonce conflict. It returns the number
+// of canceled bids.
+func (t *Tracker) Cancel(txHash string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := 0
+	for key, bid := range t.bids {
+		if bid.TxHash == txHash {
+			bid.cancel()
+			delete(t.bids, key)
+			n++
+		}
+	}
+	return n
+}
+
+// Reap cancels and removes every bid whose target block is more than the
+// configured number of blocks behind head. It returns the number of reaped bids.
+func (t *Tracker) Reap(head uint64) int {
+	var reaped []Bid
+
+	t.mu.Lock()
+	for key, bid := range t.bids {
+		if bid.TargetBlock+t.maxAgeBlocks < head {
+			bid.cancel()
+			delete(t.bids, key)
+			reaped = append(reaped, *bid)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, bid := range reaped {
+		slog.Warn("Reaped stale in-flight bid",
+			"txHash", bid.TxHash,
+			"targetBlock", bid.TargetBlock,
+			"headBlock", head,
+			"age", time.Since(bid.SentAt).Round(time.Millisecond),
+		)
+		if t.onReap != nil {
+			t.onReap(bid)
+		}
+	}
+	return len(reaped)
+}
+
+// Drain waits for all started bids to call their done function. When ctx ends
+// first, the remaining bids are canceled and Drain returns once they are done
+// or after a short grace period, with ctx's error.
+func (t *Tracker) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		t.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	t.mu.Lock()
+	remaining := len(t.bids)
+	for _, bid := range t.bids {
+		bid.cancel()
+	}
+	t.mu.Unlock()
+	slog.Warn("Drain timeout reached, canceled in-flight bids", "bids", remaining)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+	}
+	return ctx.Err()
+}
+
+// Len returns the number of in-flight bids.
+func (t *Tracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.bids)
+}
+
+// Snapshot returns a copy of the in-flight bids.
+func (t *Tracker) Snapshot() []Bid {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]Bid, 0, len(t.bids))
+	for _, bid := range t.bids {
+		out = append(out, *bid)
+	}
+	return out
+}
@@ -0,0 +1,105 @@
+package inflight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReapCancelsStaleBids(t *testing.T) {
+	var reaped []Bid
+	tr := NewTracker(2, func(b Bid) { reaped = append(reaped, b) })
+
+	staleCtx, _ := tr.Start(context.Background(), "stale", 100, 7)
+	freshCtx, _ := tr.Start(context.Background(), "fresh", 103, 8)
+	require.Equal(t, 2, tr.Len())
+
+	// Block 102 is exactly max age past target 100: nothing is reaped yet.
+	require.Zero(t, tr.Reap(102))
+
+	require.Equal(t, 1, tr.Reap(103))
+	require.Error(t, staleCtx.Err(), "reaping must close the bid's stream context")
+	require.NoError(t, freshCtx.Err())
+	require.Len(t, reaped, 1)
+	require.Equal(t, uint64(7), reaped[0].Nonce)
+	require.Equal(t, 1, tr.Len())
+}
+
+func TestCompleteRemovesBid(t *testing.T) {
+	tr := NewTracker(DefaultMaxAgeBlocks, nil)
+	ctx, done := tr.Start(context.Background(), "a", 10, 0)
+	done()
+	require.Zero(t, tr.Len())
+	require.Error(t, ctx.Err())
+	require.Zero(t, tr.Reap(1000))
+}
+
+func TestRestartReplacesPreviousBid(t *testing.T) {
+	tr := NewTracker(DefaultMaxAgeBlocks, nil)
+	first, doneFirst := tr.Start(context.Background(), "a", 10, 0)
+	second, _ := tr.Start(context.Background(), "a", 11, 0)
+
+	require.Error(t, first.Err())
+	// Completing the replaced bid must not drop the newer one.
+	doneFirst()
+	require.Equal(t, 1, tr.Len())
+	require.NoError(t, second.Err())
+}
+
+func TestStartTargetKeepsOtherTargets(t *testing.T) {
+	tr := NewTracker(DefaultMaxAgeBlocks, nil)
+	first, doneFirst := tr.StartTarget(context.Background(), "a", 10, 0)
+	second, doneSecond := tr.StartTarget(context.Background(), "a", 11, 0)
+	require.NoError(t, first.Err())
+	require.Equal(t, 2, tr.Len())
+
+	require.Equal(t, 1, tr.Reap(13), "only the bid for block 10 is stale")
+	require.Error(t, first.Err())
+	require.NoError(t, second.Err())
+	doneFirst()
+	doneSecond()
+	require.Zero(t, tr.Len())
+}
+
+func TestDrainWaitsForBids(t *testing.T) {
+	tr := NewTracker(DefaultMaxAgeBlocks, nil)
+	_, done := tr.Start(context.Background(), "a", 10, 0)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		done()
+		done() // done is idempotent
+	}()
+	require.NoError(t, tr.Drain(context.Background()))
+	require.Zero(t, tr.Len())
+}
+
+func TestDrainTimeoutCancelsBids(t *testing.T) {
+	tr := NewTracker(DefaultMaxAgeBlocks, nil)
+	bidCtx, done := tr.Start(context.Background(), "a", 10, 0)
+	go func() {
+		// A bid stream only ends once its context is canceled
+		<-bidCtx.Done()
+		done()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, tr.Drain(ctx), context.DeadlineExceeded)
+	require.Error(t, bidCtx.Err())
+	require.Zero(t, tr.Len())
+}
+
+func TestCancelStopsEveryTargetOfATransaction(t *testing.T) {
+	tr := NewTracker(DefaultMaxAgeBlocks, nil)
+	first, _ := tr.StartTarget(context.Background(), "a", 10, 0)
+	second, _ := tr.StartTarget(context.Background(), "a", 11, 0)
+	other, _ := tr.StartTarget(context.Background(), "b", 10, 1)
+
+	require.Equal(t, 2, tr.Cancel("a"))
+	require.Error(t, first.Err())
+	require.Error(t, second.Err())
+	require.NoError(t, other.Err())
+	require.Equal(t, 1, tr.Len())
+}
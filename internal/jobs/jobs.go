@@ -0,0 +1,205 @@
+// Package jobs runs the bidder's periodic maintenance: deposits, withdrawals,
+// balances, commitment settlement and bid history compaction. One ticker
+// checks which jobs are due; each run is jittered, so jobs with the same
+// interval do not hit the nodes at once, and isolated, so a job that fails or
+// panics is logged and retried on its next run without affecting the others
+// or the process. The last run of every job is reported on /status.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+// DefaultTick is how often the scheduler checks for due jobs.
+const DefaultTick = time.Second
+
+// DefaultJitter is the share of an interval a run is moved by at most.
+const DefaultJitter = 0.1
+
+// Names of the maintenance jobs.
+const (
+	Deposits    = "deposits"    // Funds the bidding window of the latest target block.
+	Withdrawals = "withdrawals" // Withdraws from settled windows.
+	Balances    = "balances"    // Refreshes and logs account balances.
+	Settlement  = "settlement"  // Settles bids with their stored commitments.
+	Compaction  = "compaction"  // Compacts the bid history.
+)
+
+// Names are the maintenance jobs.
+var Names = []string{Deposits, Withdrawals, Balances, Settlement, Compaction}
+
+// ParseDisabled parses a comma-separated list of job names.
+func ParseDisabled(list string) (map[string]bool, error) {
+	disabled := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, n := range Names {
+			known = known || n == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown job %q, expected one of %s", name, strings.Join(Names, ", "))
+		}
+		disabled[name] = true
+	}
+	return disabled, nil
+}
+
+// Job is a periodic task.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Status is the last run of a job, served on /status.
+type Status struct {
+	Name      string    `json:"name"`
+	Interval  string    `json:"interval"`
+	Running   bool      `json:"running"`
+	Runs      int       `json:"runs"`
+	Failures  int       `json:"failures"` // Runs that returned an error or panicked.
+	LastRun   time.Time `json:"last_run,omitempty"`
+	LastTook  string    `json:"last_took,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	NextRun   time.Time `json:"next_run"`
+}
+
+// entry is a scheduled job.
+type entry struct {
+	job    Job
+	status Status
+}
+
+// Scheduler runs jobs at their intervals.
+type Scheduler struct {
+	jitter   float64
+	disabled map[string]bool
+	now      func() time.Time
+	rand     func() float64
+
+	mu      sync.Mutex
+	entries []*entry
+	wg      sync.WaitGroup
+}
+
+// New returns a scheduler moving runs by up to jitter of their interval, and
+// skipping the jobs in disabled.
+func New(jitter float64, disabled map[string]bool) *Scheduler {
+	return &Scheduler{jitter: jitter, disabled: disabled, now: time.Now, rand: rand.Float64}
+}
+
+// Add schedules job, running it first on the next tick and then every
+// jittered interval. Disabled jobs are logged and skipped.
+func (s *Scheduler) Add(job Job) {
+	if s.disabled[job.Name] {
+		slog.Info("Maintenance job disabled", "job", job.Name)
+		return
+	}
+	e := &entry{job: job, status: Status{Name: job.Name, Interval: job.Interval.String(), NextRun: s.now()}}
+	s.mu.Lock()
+	s.entries = append(s.entries, e)
+	s.mu.Unlock()
+}
+
+// next returns when a job with interval runs after now.
+func (s *Scheduler) next(now time.Time, interval time.Duration) time.Time {
+	jitter := time.Duration((s.rand()*2 - 1) * s.jitter * float64(interval))
+	return now.Add(interval + jitter)
+}
+
+// Run checks for due jobs every tick until ctx is canceled, then waits for
+// the runs in progress.
+func (s *Scheduler) Run(ctx context.Context, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			return
+		case <-ticker.C:
+			s.Due(ctx)
+		}
+	}
+}
+
+// Due starts the jobs that are due and not still running.
+func (s *Scheduler) Due(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for _, e := range s.entries {
+		if e.status.Running || now.Before(e.status.NextRun) {
+			continue
+		}
+		e.status.Running = true
+		s.wg.Add(1)
+		go s.run(ctx, e)
+	}
+}
+
+// run runs the job of e once, recovering from a panic.
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	defer s.wg.Done()
+	start := s.now()
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+				slog.Error("Maintenance job panicked", "job", e.job.Name, "panic", r, "stack", string(debug.Stack()))
+			}
+		}()
+		return e.job.Run(ctx)
+	}()
+	end := s.now()
+
+	result := "ok"
+	if err != nil {
+		result = "error"
+		if ctx.Err() == nil {
+			slog.Warn("Maintenance job failed", "job", e.job.Name, "error", err)
+		}
+	}
+	metrics.JobRuns.WithLabelValues(e.job.Name, result).Inc()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.status.Running = false
+	e.status.Runs++
+	e.status.LastRun, e.status.LastTook = start, end.Sub(start).String()
+	e.status.LastError = ""
+	if err != nil {
+		e.status.Failures++
+		e.status.LastError = err.Error()
+	}
+	e.status.NextRun = s.next(end, e.job.Interval)
+}
+
+// Status returns the status of every scheduled job, by name.
+func (s *Scheduler) Status() []Status {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Status, 0, len(s.entries))
+	for _, e := range s.entries {
+		out = append(out, e.status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	s := New(0.5, map[string]bool{Compaction: true})
+	s.now = func() time.Time { return now }
+	s.rand = func() float64 { return 1 } // Latest within the jitter
+
+	runs := make(chan string, 10)
+	s.Add(Job{Name: Deposits, Interval: 10 * time.Second, Run: func(context.Context) error {
+		runs <- Deposits
+		return nil
+	}})
+	s.Add(Job{Name: Balances, Interval: time.Minute, Run: func(context.Context) error {
+		runs <- Balances
+		panic("balance overflow")
+	}})
+	s.Add(Job{Name: Settlement, Interval: time.Minute, Run: func(context.Context) error {
+		runs <- Settlement
+		return errors.New("node unavailable")
+	}})
+	s.Add(Job{Name: Compaction, Interval: time.Minute, Run: func(context.Context) error {
+		t.Error("disabled job ran")
+		return nil
+	}})
+
+	ctx := context.Background()
+	s.Due(ctx)
+	s.wg.Wait()
+	require.Len(t, runs, 3, "every job runs at startup")
+	for len(runs) > 0 {
+		<-runs
+	}
+
+	status := s.Status()
+	require.Len(t, status, 3)
+	require.Equal(t, Balances, status[0].Name)
+	require.Equal(t, 1, status[0].Failures)
+	require.Equal(t, "panic: balance overflow", status[0].LastError)
+	require.Equal(t, Deposits, status[1].Name)
+	require.Zero(t, status[1].Failures)
+	require.Equal(t, now.Add(15*time.Second), status[1].NextRun, "interval plus the jitter")
+	require.Equal(t, "node unavailable", status[2].LastError)
+
+	now = now.Add(15 * time.Second)
+	s.Due(ctx)
+	s.wg.Wait()
+	require.Equal(t, Deposits, <-runs)
+	require.Empty(t, runs, "the others are not due yet")
+	require.Equal(t, 2, s.Status()[1].Runs)
+}
+
+func TestParseDisabled(t *testing.T) {
+	disabled, err := ParseDisabled(" deposits,compaction ")
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{Deposits: true, Compaction: true}, disabled)
+	_, err = ParseDisabled("deposits,gc")
+	require.ErrorContains(t, err, `unknown job "gc"`)
+}
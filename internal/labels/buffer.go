@@ -0,0 +1,115 @@
+package labels
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBufferSize is how many log records a buffer keeps.
+const DefaultBufferSize = 1000
+
+// Entry is a buffered log record.
+type Entry struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Message string         `json:"msg"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+}
+
+// Buffer keeps the latest log records, so they can be read back filtered by
+// campaign, tenant and level.
+type Buffer struct {
+	mu      sync.Mutex
+	size    int
+	entries []Entry // Ring, oldest at next once full.
+	next    int
+}
+
+// NewBuffer returns a buffer keeping the last size records.
+func NewBuffer(size int) *Buffer {
+	return &Buffer{size: size}
+}
+
+// add keeps r with the attributes of its logger.
+func (b *Buffer) add(r slog.Record, attrs []slog.Attr) {
+	e := Entry{Time: r.Time, Level: r.Level.String(), Message: r.Message, Attrs: make(map[string]any, r.NumAttrs()+len(attrs))}
+	keep := func(a slog.Attr) bool {
+		v := a.Value.Resolve().Any()
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		} else if s, ok := v.(fmt.Stringer); ok {
+			v = s.String()
+		}
+		e.Attrs[a.Key] = v
+		return true
+	}
+	for _, a := range attrs {
+		keep(a)
+	}
+	r.Attrs(keep)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.entries) < b.size {
+		b.entries = append(b.entries, e)
+		return
+	}
+	b.entries[b.next] = e
+	b.next = (b.next + 1) % b.size
+}
+
+// Query returns the buffered records, oldest first, of the campaign and
+// tenant when they are not empty, at level or above, and at most the last
+// limit of them when limit is positive.
+func (b *Buffer) Query(campaign, tenant string, level slog.Level, limit int) []Entry {
+	b.mu.Lock()
+	ordered := append(append(make([]Entry, 0, len(b.entries)), b.entries[b.next:]...), b.entries[:b.next]...)
+	b.mu.Unlock()
+
+	matched := make([]Entry, 0, len(ordered))
+	for _, e := range ordered {
+		if campaign != "" && e.Attrs[CampaignKey] != campaign || tenant != "" && e.Attrs[TenantKey] != tenant {
+			continue
+		}
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(e.Level)); err == nil && l < level {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	if limit > 0 && len(matched) > limit {
+		matched = matched[len(matched)-limit:]
+	}
+	return matched
+}
+
+// ServeHTTP serves the buffered records as JSON, filtered by the campaign,
+// tenant, level and limit query parameters.
+func (b *Buffer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	level := slog.LevelDebug
+	if s := q.Get("level"); s != "" {
+		if err := level.UnmarshalText([]byte(strings.ToUpper(s))); err != nil {
+			http.Error(w, "invalid level "+strconv.Quote(s), http.StatusBadRequest)
+			return
+		}
+	}
+	limit := 0
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid limit "+strconv.Quote(s), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(b.Query(q.Get(CampaignKey), q.Get(TenantKey), level, limit))
+}
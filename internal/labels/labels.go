@@ -0,0 +1,152 @@
+// Package labels tells apart what bidders of different customers report when
+// they share a deployment. Every log record, exported metric and stored row
+// carries the tenant the bidder runs for and the campaign it is bidding in,
+// and recent log records can be read back per campaign from the status
+// server.
+package labels
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sync"
+)
+
+// Attribute and label names.
+const (
+	TenantKey   = "tenant"
+	CampaignKey = "campaign"
+)
+
+// validTenant matches the tenants accepted, which must be usable as label
+// values and file names alike.
+var validTenant = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// ValidateTenant checks that tenant is empty or a usable tenant name.
+func ValidateTenant(tenant string) error {
+	if tenant != "" && !validTenant.MatchString(tenant) {
+		return fmt.Errorf("invalid tenant %q: use up to 64 letters, digits, '_', '.' or '-'", tenant)
+	}
+	return nil
+}
+
+// Labels are the tenant and campaign of a bidder, either of which may be
+// empty.
+type Labels struct {
+	Tenant   string
+	Campaign string
+}
+
+// Map returns the labels that are set, by name.
+func (l Labels) Map() map[string]string {
+	m := make(map[string]string, 2)
+	if l.Tenant != "" {
+		m[TenantKey] = l.Tenant
+	}
+	if l.Campaign != "" {
+		m[CampaignKey] = l.Campaign
+	}
+	return m
+}
+
+// Source holds the tenant of the bidder and looks up the campaign it is
+// bidding in. A nil Source has no labels.
+type Source struct {
+	tenant string
+
+	mu       sync.RWMutex
+	campaign func() string
+}
+
+// NewSource returns a source for tenant, without a campaign until one is
+// set.
+func NewSource(tenant string) *Source {
+	return &Source{tenant: tenant}
+}
+
+// SetCampaign sets the function returning the running campaign, the empty
+// string when none is.
+func (s *Source) SetCampaign(campaign func() string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.campaign = campaign
+}
+
+// Current returns the labels as of now.
+func (s *Source) Current() Labels {
+	if s == nil {
+		return Labels{}
+	}
+	s.mu.RLock()
+	campaign := s.campaign
+	s.mu.RUnlock()
+	l := Labels{Tenant: s.tenant}
+	if campaign != nil {
+		l.Campaign = campaign()
+	}
+	return l
+}
+
+// Handler adds the labels of a source to every log record that does not
+// carry them already, and keeps the records in a buffer if one is given.
+type Handler struct {
+	next   slog.Handler
+	source *Source
+	buffer *Buffer
+	attrs  []slog.Attr // Added through WithAttrs, for the buffer.
+}
+
+// NewHandler returns a handler labeling records for next. buffer may be nil.
+func NewHandler(next slog.Handler, source *Source, buffer *Buffer) *Handler {
+	return &Handler{next: next, source: source, buffer: buffer}
+}
+
+// Enabled implements slog.Handler.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	var has [2]bool
+	check := func(a slog.Attr) bool {
+		switch a.Key {
+		case TenantKey:
+			has[0] = true
+		case CampaignKey:
+			has[1] = true
+		}
+		return true
+	}
+	for _, a := range h.attrs {
+		check(a)
+	}
+	r.Attrs(check)
+	l := h.source.Current()
+	if l.Tenant != "" && !has[0] {
+		r.AddAttrs(slog.String(TenantKey, l.Tenant))
+	}
+	if l.Campaign != "" && !has[1] {
+		r.AddAttrs(slog.String(CampaignKey, l.Campaign))
+	}
+	if h.buffer != nil {
+		h.buffer.add(r, h.attrs)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.next = h.next.WithAttrs(attrs)
+	c.attrs = append(append([]slog.Attr(nil), h.attrs...), attrs...)
+	return &c
+}
+
+// WithGroup implements slog.Handler.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	c := *h
+	c.next = h.next.WithGroup(name)
+	return &c
+}
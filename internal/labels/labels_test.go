@@ -0,0 +1,85 @@
+package labels
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTenant(t *testing.T) {
+	require.NoError(t, ValidateTenant(""))
+	require.NoError(t, ValidateTenant("acme-prod.eu_1"))
+	require.Error(t, ValidateTenant("-acme"))
+	require.Error(t, ValidateTenant("acme corp"))
+	require.Error(t, ValidateTenant(string(bytes.Repeat([]byte("a"), 65))))
+}
+
+func TestHandlerLabelsRecords(t *testing.T) {
+	var out bytes.Buffer
+	source := NewSource("acme")
+	campaign := ""
+	source.SetCampaign(func() string { return campaign })
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&out, nil), source, nil))
+
+	logger.Info("idle")
+	campaign = "c1"
+	logger.Info("bidding")
+	logger.Info("resolved", "campaign", "c0")
+
+	var records []map[string]any
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var r map[string]any
+		require.NoError(t, dec.Decode(&r))
+		records = append(records, r)
+	}
+	require.Len(t, records, 3)
+	require.Equal(t, "acme", records[0]["tenant"])
+	require.NotContains(t, records[0], "campaign")
+	require.Equal(t, "c1", records[1]["campaign"])
+	require.Equal(t, "c0", records[2]["campaign"], "a campaign on the record is kept")
+}
+
+func TestBufferFiltersByCampaign(t *testing.T) {
+	source := NewSource("acme")
+	campaign := "c1"
+	source.SetCampaign(func() string { return campaign })
+	buffer := NewBuffer(3)
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&bytes.Buffer{}, nil), source, buffer)).With("app", "bidder")
+
+	logger.Info("dropped")
+	logger.Warn("first", "error", errors.New("boom"))
+	campaign = "c2"
+	logger.Info("second")
+	campaign = "c1"
+	logger.Info("third")
+
+	entries := buffer.Query("c1", "", slog.LevelDebug, 0)
+	require.Len(t, entries, 2, "the oldest record is dropped once the buffer is full")
+	require.Equal(t, "first", entries[0].Message)
+	require.Equal(t, "boom", entries[0].Attrs["error"])
+	require.Equal(t, "bidder", entries[0].Attrs["app"])
+	require.Equal(t, "third", entries[1].Message)
+
+	rec := httptest.NewRecorder()
+	buffer.ServeHTTP(rec, httptest.NewRequest("GET", "/logs?campaign=c1&tenant=acme&level=warn", nil))
+	var served []Entry
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	require.Len(t, served, 1)
+	require.Equal(t, "first", served[0].Message)
+
+	rec = httptest.NewRecorder()
+	buffer.ServeHTTP(rec, httptest.NewRequest("GET", "/logs?limit=1", nil))
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	require.Len(t, served, 1)
+	require.Equal(t, "third", served[0].Message)
+
+	rec = httptest.NewRecorder()
+	buffer.ServeHTTP(rec, httptest.NewRequest("GET", "/logs?level=loud", nil))
+	require.Equal(t, 400, rec.Code)
+}
@@ -0,0 +1,221 @@
+// Package lanes runs each kind of bid transaction in its own worker, with its
+// own account and therefore its own nonce sequence. A blob transaction that is
+// stuck, or slow to build, then cannot hold up cheap transfer bids.
+package lanes
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ee "github.com/primev/preconf_blob_bidder/internal/eth"
+	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+	"github.com/primev/preconf_blob_bidder/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Kind is the kind of transaction a lane bids with.
+type Kind string
+
+const (
+	Transfer Kind = "transfer"
+	Blob     Kind = "blob"
+	ERC20    Kind = "erc20"
+	Call     Kind = "contract-call"
+	Raw      Kind = "raw"
+)
+
+// TokenTransfer is the ERC-20 transfer an ERC20 lane bids with.
+type TokenTransfer struct {
+	Token     common.Address
+	Recipient common.Address
+	Amount    *big.Int // In token base units.
+}
+
+// DefaultTransferAmount is the wei transfer lanes send unless configured,
+// 0.001 ETH.
+var DefaultTransferAmount = big.NewInt(1e15)
+
+// ETHTransfer is the ETH transfer a Transfer lane bids with.
+type ETHTransfer struct {
+	Recipient common.Address // Zero sends to the lane's own account.
+	Amount    *big.Int       // In wei; nil sends DefaultTransferAmount.
+}
+
+// Job is a new block for a lane to bid on, with the client connected when it
+// was received.
+type Job struct {
+	Header *types.Header
+	Client *ee.Client
+	Ctx    context.Context // Carries the trace of the header; nil for none.
+}
+
+// ContractCall is the contract call a Call lane bids with.
+type ContractCall struct {
+	To   common.Address
+	Data []byte
+}
+
+// Lane builds and bids one kind of transaction from one account.
+type Lane struct {
+	Kind    Kind
+	Account bb.AuthAcct
+	Signer  ee.Signer            // Signs the lane's transactions; nil for raw lanes.
+	NumBlob uint                 // Blobs per transaction for blob lanes.
+	Payment ETHTransfer          // Transfer of Transfer lanes.
+	Token   TokenTransfer        // Transfer of ERC20 lanes.
+	Call    ContractCall         // Call of contract call lanes.
+	RawTxs  []*types.Transaction // Pre-signed transactions of raw lanes, in nonce order.
+
+	jobs chan Job
+}
+
+// New returns a lane. Blob lanes attach numBlob blobs to each transaction.
+// The lane signs with the account's private key; set Signer to sign
+// elsewhere, e.g. with a remote signer.
+func New(kind Kind, account bb.AuthAcct, numBlob uint) *Lane {
+	l := &Lane{
+		Kind:    kind,
+		Account: account,
+		NumBlob: numBlob,
+		jobs:    make(chan Job, 1),
+	}
+	if account.PrivateKey != nil {
+		l.Signer = ee.NewLocalSigner(account.PrivateKey)
+	}
+	return l
+}
+
+// NewTransfer returns a lane bidding with the ETH transfer t.
+func NewTransfer(account bb.AuthAcct, t ETHTransfer) *Lane {
+	l := New(Transfer, account, 0)
+	l.Payment = t
+	return l
+}
+
+// NewERC20 returns a lane bidding with the token transfer t.
+func NewERC20(account bb.AuthAcct, t TokenTransfer) *Lane {
+	l := New(ERC20, account, 0)
+	l.Token = t
+	return l
+}
+
+// NewContractCall returns a lane bidding with the contract call c.
+func NewContractCall(account bb.AuthAcct, c ContractCall) *Lane {
+	l := New(Call, account, 0)
+	l.Call = c
+	return l
+}
+
+// NewRaw returns a lane bidding with the pre-signed txs of sender, one after
+// the other as their nonces are used. The lane cannot sign, so its account has
+// no private key.
+func NewRaw(sender common.Address, txs []*types.Transaction) *Lane {
+	l := New(Raw, bb.AuthAcct{Address: sender}, 0)
+	l.RawTxs = txs
+	return l
+}
+
+// Offer hands job to the lane without blocking. A lane that is still busy
+// holds at most one waiting job, the newest; the job it replaces is returned
+// so the skipped block can be reported. Offer must not be called
+// concurrently.
+func (l *Lane) Offer(job Job) (skipped *Job) {
+	select {
+	case l.jobs <- job:
+		return nil
+	default:
+	}
+	select {
+	case old := <-l.jobs:
+		skipped = &old
+	default:
+	}
+	l.jobs <- job
+	return skipped
+}
+
+// BuildTx creates and signs the lane's transaction for the block offset
+// blocks ahead of the head, priced by fees and, for blob lanes, blobFees, or
+// picks the next pre-signed one for raw lanes. It returns the transaction and
+// its target block. Building and signing are traced as children of the span
+// of ctx.
+func (l *Lane) BuildTx(ctx context.Context, client *ee.Client, offset uint64, fees ee.FeeOracle, blobFees ee.BlobFeeOracle) (*types.Transaction, uint64, error) {
+	ctx, span := tracing.Start(ctx, tracing.BuildTx, attribute.String("lane", string(l.Kind)))
+	tx, target, err := l.buildTx(ctx, client, offset, fees, blobFees)
+	if tx != nil {
+		span.SetAttributes(attribute.String("txHash", tx.Hash().Hex()), attribute.Int64("targetBlock", int64(target)))
+	}
+	tracing.End(span, err)
+	return tx, target, err
+}
+
+func (l *Lane) buildTx(ctx context.Context, client *ee.Client, offset uint64, fees ee.FeeOracle, blobFees ee.BlobFeeOracle) (*types.Transaction, uint64, error) {
+	if l.Kind == Raw {
+		return ee.NextRawTransaction(client, l.Account.Address, l.RawTxs, offset)
+	}
+	if l.Signer == nil {
+		return nil, 0, fmt.Errorf("%s lane has no signer", l.Kind)
+	}
+	signer := tracedSigner{Signer: l.Signer, ctx: ctx}
+	switch l.Kind {
+	case Blob:
+		return ee.ExecuteBlobTransaction(client, signer, int(l.NumBlob), offset, fees, blobFees)
+	case ERC20:
+		return ee.SendERC20Transfer(client, signer, l.Token.Token, l.Token.Recipient, l.Token.Amount, offset, fees)
+	case Call:
+		return ee.SendContractCall(client, signer, l.Call.To, l.Call.Data, offset, fees)
+	}
+	to, amount := l.Payment.Recipient, l.Payment.Amount
+	if to == (common.Address{}) {
+		to = l.Signer.Address()
+	}
+	if amount == nil {
+		amount = DefaultTransferAmount
+	}
+	return ee.ETHTransfer(client, signer, to, amount, offset, fees)
+}
+
+// tracedSigner signs in a span of its own, a child of the span of ctx.
+type tracedSigner struct {
+	ee.Signer
+	ctx context.Context
+}
+
+// SignTx implements ee.Signer.
+func (s tracedSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	_, span := tracing.Start(s.ctx, tracing.Sign, attribute.String("from", s.Address().Hex()))
+	signed, err := s.Signer.SignTx(tx, chainID)
+	tracing.End(span, err)
+	return signed, err
+}
+
+// Start runs handle for the jobs of every lane, one worker per lane. The
+// returned function stops accepting jobs and waits for the workers to finish
+// the jobs they hold.
+func Start(handle func(*Lane, Job), lanes ...*Lane) (stop func()) {
+	var wg sync.WaitGroup
+	for _, l := range lanes {
+		wg.Add(1)
+		go func(l *Lane) {
+			defer wg.Done()
+			for job := range l.jobs {
+				handle(l, job)
+			}
+			slog.Debug("Bid lane stopped", "lane", l.Kind)
+		}(l)
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			for _, l := range lanes {
+				close(l.jobs)
+			}
+			wg.Wait()
+		})
+	}
+}
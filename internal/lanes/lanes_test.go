@@ -0,0 +1,57 @@
+package lanes
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+	"github.com/stretchr/testify/require"
+)
+
+func job(number int64) Job {
+	return Job{Header: &types.Header{Number: big.NewInt(number)}}
+}
+
+func TestOfferKeepsNewestWaitingJob(t *testing.T) {
+	l := New(Blob, bb.AuthAcct{}, 1)
+
+	require.Nil(t, l.Offer(job(1)))
+	skipped := l.Offer(job(2))
+	require.NotNil(t, skipped)
+	require.Equal(t, int64(1), skipped.Header.Number.Int64())
+	require.Equal(t, int64(2), (<-l.jobs).Header.Number.Int64())
+}
+
+func TestStuckLaneDoesNotBlockOthers(t *testing.T) {
+	blob := New(Blob, bb.AuthAcct{}, 1)
+	transfer := New(Transfer, bb.AuthAcct{}, 0)
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var transferred []int64
+	transferDone := make(chan struct{}, 3)
+	stop := Start(func(l *Lane, j Job) {
+		if l.Kind == Blob {
+			<-release
+			return
+		}
+		mu.Lock()
+		transferred = append(transferred, j.Header.Number.Int64())
+		mu.Unlock()
+		transferDone <- struct{}{}
+	}, blob, transfer)
+
+	for n := int64(1); n <= 3; n++ {
+		blob.Offer(job(n))
+		transfer.Offer(job(n))
+		<-transferDone
+	}
+	mu.Lock()
+	require.Equal(t, []int64{1, 2, 3}, transferred)
+	mu.Unlock()
+
+	close(release)
+	stop()
+}
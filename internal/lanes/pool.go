@@ -0,0 +1,53 @@
+package lanes
+
+import "fmt"
+
+// Rotation is how a Pool spreads blocks over its lanes.
+type Rotation string
+
+const (
+	// RoundRobin hands each block to the next lane in turn, so an account only
+	// bids every n-th block and its last transaction has time to land.
+	RoundRobin Rotation = "round-robin"
+	// Parallel hands every block to every lane, for n bids per block.
+	Parallel Rotation = "parallel"
+)
+
+// ParseRotation validates a rotation name.
+func ParseRotation(s string) (Rotation, error) {
+	switch r := Rotation(s); r {
+	case RoundRobin, Parallel:
+		return r, nil
+	}
+	return "", fmt.Errorf("account_rotation must be %s or %s", RoundRobin, Parallel)
+}
+
+// Pool is a group of lanes bidding the same kind of transaction, each from its
+// own account and therefore with its own nonces.
+type Pool struct {
+	rotation Rotation
+	lanes    []*Lane
+	next     int
+}
+
+// NewPool returns a pool of lanes.
+func NewPool(rotation Rotation, lanes ...*Lane) *Pool {
+	return &Pool{rotation: rotation, lanes: lanes}
+}
+
+// Lanes returns the lanes of the pool.
+func (p *Pool) Lanes() []*Lane {
+	return p.lanes
+}
+
+// Next returns the lanes to offer the next block to: every lane in parallel
+// mode, otherwise the lane whose turn it is. Next must not be called
+// concurrently.
+func (p *Pool) Next() []*Lane {
+	if p.rotation == Parallel || len(p.lanes) < 2 {
+		return p.lanes
+	}
+	l := p.lanes[p.next%len(p.lanes)]
+	p.next++
+	return []*Lane{l}
+}
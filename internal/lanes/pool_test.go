@@ -0,0 +1,26 @@
+package lanes
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolRotation(t *testing.T) {
+	a := New(Transfer, bb.AuthAcct{Address: common.HexToAddress("0xa")}, 0)
+	b := New(Transfer, bb.AuthAcct{Address: common.HexToAddress("0xb")}, 0)
+
+	p := NewPool(RoundRobin, a, b)
+	require.Equal(t, []*Lane{a}, p.Next())
+	require.Equal(t, []*Lane{b}, p.Next())
+	require.Equal(t, []*Lane{a}, p.Next())
+
+	p = NewPool(Parallel, a, b)
+	require.Equal(t, []*Lane{a, b}, p.Next())
+	require.Equal(t, []*Lane{a, b}, p.Next())
+
+	_, err := ParseRotation("random")
+	require.ErrorContains(t, err, "account_rotation must be round-robin or parallel")
+}
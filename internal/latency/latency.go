@@ -0,0 +1,161 @@
+// Package latency holds the per-dependency latency budgets of the bidding
+// path. Each external call gets its own timeout instead of sharing a single
+// default, and errors caused by an exhausted budget name the budget.
+package latency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Budget names a single external dependency on the bidding path.
+type Budget string
+
+const (
+	WSSubscribe Budget = "ws_subscribe" // Establishing a new-head subscription.
+	BlockState  Budget = "block_state"  // Batched nonce, latest header and chain ID request.
+	NonceFetch  Budget = "nonce_fetch"  // eth_getTransactionCount (pending).
+	HeaderFetch Budget = "header_fetch" // eth_getBlockByNumber for the latest header.
+	ChainID     Budget = "chain_id"     // net_version / eth_chainId.
+	GasEstimate Budget = "gas_estimate" // eth_estimateGas and fee queries.
+	BundlePost  Budget = "bundle_post"  // eth_sendBundle to the builder endpoint.
+	RawTxPost   Budget = "raw_tx_post"  // eth_sendRawTransaction to the preconf RPC.
+	SendBid     Budget = "send_bid"     // SendBid stream to the bidder node, until it closes.
+
+	PendingCount Budget = "pending_count" // Pending transaction count for market snapshots, off the bidding path.
+)
+
+// Budgets maps each dependency to its timeout.
+type Budgets map[Budget]time.Duration
+
+// Defaults returns the default budgets. The values leave room for the whole
+// pipeline to finish within a 12 second slot.
+func Defaults() Budgets {
+	return Budgets{
+		WSSubscribe: 10 * time.Second,
+		BlockState:  2 * time.Second,
+		NonceFetch:  2 * time.Second,
+		HeaderFetch: 2 * time.Second,
+		ChainID:     2 * time.Second,
+		GasEstimate: 2 * time.Second,
+		BundlePost:  3 * time.Second,
+		RawTxPost:   3 * time.Second,
+		SendBid:     12 * time.Second,
+
+		PendingCount: 2 * time.Second,
+	}
+}
+
+var current atomic.Pointer[Budgets]
+
+func init() {
+	d := Defaults()
+	current.Store(&d)
+}
+
+// Set replaces the active budgets. Budgets missing from b keep their defaults.
+func Set(b Budgets) {
+	merged := Defaults()
+	for k, v := range b {
+		merged[k] = v
+	}
+	current.Store(&merged)
+}
+
+// Current returns a copy of the active budgets.
+func Current() Budgets {
+	out := make(Budgets)
+	for k, v := range *current.Load() {
+		out[k] = v
+	}
+	return out
+}
+
+// Get returns the active timeout of a budget.
+func Get(b Budget) time.Duration {
+	return (*current.Load())[b]
+}
+
+// Context derives a context bounded by the budget's timeout.
+func Context(parent context.Context, b Budget) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, Get(b))
+}
+
+// ExceededError reports that a call ran out of its latency budget.
+type ExceededError struct {
+	Budget Budget
+	Limit  time.Duration
+	Err    error
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("latency budget %s (%s) exceeded: %v", e.Budget, e.Limit, e.Err)
+}
+
+func (e *ExceededError) Unwrap() error {
+	return e.Err
+}
+
+// Wrap annotates err with the budget when it was caused by a deadline.
+// Other errors, and nil, are returned unchanged.
+func Wrap(b Budget, err error) error {
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var exceeded *ExceededError
+	if errors.As(err, &exceeded) {
+		return err
+	}
+	return &ExceededError{Budget: b, Limit: Get(b), Err: err}
+}
+
+// Exceeded returns the budget that err exhausted, if any.
+func Exceeded(err error) (Budget, bool) {
+	var exceeded *ExceededError
+	if errors.As(err, &exceeded) {
+		return exceeded.Budget, true
+	}
+	return "", false
+}
+
+// Parse parses a comma separated list of budget=duration pairs, e.g.
+// "nonce_fetch=1s,send_bid=8s".
+func Parse(s string) (Budgets, error) {
+	out := make(Budgets)
+	known := Defaults()
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid latency budget %q, expected name=duration", part)
+		}
+		b := Budget(strings.TrimSpace(name))
+		if _, ok := known[b]; !ok {
+			return nil, fmt.Errorf("unknown latency budget %q (known: %s)", b, strings.Join(Names(), ", "))
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid duration for latency budget %s: %q", b, value)
+		}
+		out[b] = d
+	}
+	return out, nil
+}
+
+// Names returns the sorted names of all budgets.
+func Names() []string {
+	var names []string
+	for b := range Defaults() {
+		names = append(names, string(b))
+	}
+	sort.Strings(names)
+	return names
+}
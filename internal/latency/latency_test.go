@@ -0,0 +1,51 @@
+package latency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAndSet(t *testing.T) {
+	parsed, err := Parse("nonce_fetch=500ms, send_bid=8s")
+	require.NoError(t, err)
+	require.Equal(t, 500*time.Millisecond, parsed[NonceFetch])
+
+	Set(parsed)
+	defer Set(nil)
+	require.Equal(t, 500*time.Millisecond, Get(NonceFetch))
+	require.Equal(t, 8*time.Second, Get(SendBid))
+	require.Equal(t, Defaults()[HeaderFetch], Get(HeaderFetch), "unset budgets keep their defaults")
+
+	_, err = Parse("bogus=1s")
+	require.Error(t, err)
+	_, err = Parse("nonce_fetch")
+	require.Error(t, err)
+	_, err = Parse("nonce_fetch=-1s")
+	require.Error(t, err)
+}
+
+func TestWrapNamesExceededBudget(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	err := Wrap(HeaderFetch, fmt.Errorf("header: %w", ctx.Err()))
+	b, ok := Exceeded(fmt.Errorf("building tx: %w", err))
+	require.True(t, ok)
+	require.Equal(t, HeaderFetch, b)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The innermost budget wins when errors are wrapped twice.
+	b, _ = Exceeded(Wrap(SendBid, err))
+	require.Equal(t, HeaderFetch, b)
+
+	plain := errors.New("boom")
+	require.Equal(t, plain, Wrap(NonceFetch, plain))
+	_, ok = Exceeded(plain)
+	require.False(t, ok)
+}
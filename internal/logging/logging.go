@@ -0,0 +1,149 @@
+// Package logging sets up the bidder's one logger. Records are written in a
+// selectable format at a configured level, to the console and optionally a
+// rotated file, and go-ethereum's own logs and the standard library's log
+// package go through the same handler. Field names are made consistent, so a
+// search for e.g. wsEndpoint or error finds every record carrying one.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	gethlog "github.com/ethereum/go-ethereum/log"
+)
+
+// Format is how records are written.
+type Format string
+
+const (
+	// JSON writes one compact JSON object per line.
+	JSON Format = "json"
+	// Text writes key=value pairs, one record per line.
+	Text Format = "text"
+	// Pretty writes indented JSON objects, the default.
+	Pretty Format = "pretty"
+)
+
+// ParseFormat validates a log format.
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(s); format {
+	case JSON, Text, Pretty:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown log format %q, must be json, text or pretty", s)
+}
+
+// ParseLevel parses a log level: debug, info, warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, must be debug, info, warn or error", s)
+	}
+	return level, nil
+}
+
+// NewHandler returns a handler writing the records at level and above to w
+// in format.
+func NewHandler(w io.Writer, format Format, level slog.Leveler) slog.Handler {
+	switch format {
+	case JSON:
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	case Text:
+		return slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})
+	}
+	return NewCustomJSONHandler(w, level.Level())
+}
+
+// SetDefault makes logger the default of log/slog, the standard library's log
+// package and go-ethereum.
+func SetDefault(logger *slog.Logger) {
+	slog.SetDefault(logger)
+	gethlog.SetDefault(gethlog.NewLogger(logger.Handler()))
+}
+
+// aliases are field names used for the same thing as a canonical one.
+var aliases = map[string]string{
+	"err": "error",
+}
+
+// CanonicalKey returns the canonical name of a field: its alias target, or
+// else the name in lower camel case.
+func CanonicalKey(key string) string {
+	if canonical, ok := aliases[key]; ok {
+		return canonical
+	}
+	if !strings.Contains(key, "_") {
+		return key
+	}
+	var b strings.Builder
+	upper := false
+	for i, r := range key {
+		switch {
+		case r == '_' && i > 0:
+			upper = true
+		case upper && r >= 'a' && r <= 'z':
+			b.WriteRune(r - 'a' + 'A')
+			upper = false
+		default:
+			b.WriteRune(r)
+			upper = false
+		}
+	}
+	return b.String()
+}
+
+// canonicalAttr returns a with canonical field names, inside groups too.
+func canonicalAttr(a slog.Attr) slog.Attr {
+	a.Key = CanonicalKey(a.Key)
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		canonical := make([]slog.Attr, len(attrs))
+		for i, attr := range attrs {
+			canonical[i] = canonicalAttr(attr)
+		}
+		a.Value = slog.GroupValue(canonical...)
+	}
+	return a
+}
+
+// canonical renames the fields of records to their canonical names.
+type canonical struct {
+	next slog.Handler
+}
+
+// Canonical returns next receiving records with canonical field names.
+func Canonical(next slog.Handler) slog.Handler {
+	return canonical{next: next}
+}
+
+// Enabled implements slog.Handler.
+func (h canonical) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h canonical) Handle(ctx context.Context, r slog.Record) error {
+	c := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		c.AddAttrs(canonicalAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, c)
+}
+
+// WithAttrs implements slog.Handler.
+func (h canonical) WithAttrs(attrs []slog.Attr) slog.Handler {
+	canonical := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		canonical[i] = canonicalAttr(a)
+	}
+	return Canonical(h.next.WithAttrs(canonical))
+}
+
+// WithGroup implements slog.Handler.
+func (h canonical) WithGroup(name string) slog.Handler {
+	return Canonical(h.next.WithGroup(CanonicalKey(name)))
+}
@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"json", "text", "pretty"} {
+		format, err := ParseFormat(s)
+		require.NoError(t, err)
+		require.Equal(t, Format(s), format)
+	}
+	_, err := ParseFormat("logfmt")
+	require.ErrorContains(t, err, `unknown log format "logfmt"`)
+
+	level, err := ParseLevel("debug")
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelDebug, level)
+	level, err = ParseLevel("WARN")
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelWarn, level)
+	_, err = ParseLevel("verbose")
+	require.ErrorContains(t, err, `unknown log level "verbose"`)
+}
+
+func TestCanonicalKey(t *testing.T) {
+	require.Equal(t, "error", CanonicalKey("err"))
+	require.Equal(t, "wsEndpoint", CanonicalKey("ws_endpoint"))
+	require.Equal(t, "decayStartTimestamp", CanonicalKey("decay_start_timestamp"))
+	require.Equal(t, "txHash", CanonicalKey("txHash"))
+	require.Equal(t, "_private", CanonicalKey("_private"))
+}
+
+func TestCanonical(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(Canonical(NewHandler(&buf, JSON, slog.LevelInfo))).With("app_name", "bidder")
+	logger.Debug("Not written")
+	logger.Warn("WebSocket endpoint unavailable", "err", "refused", "ws_endpoint", "wss://node", slog.Group("bid_info", "tx_hash", "0x01"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1, "records below the level are dropped")
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	require.Equal(t, "bidder", record["appName"])
+	require.Equal(t, "refused", record["error"])
+	require.Equal(t, "wss://node", record["wsEndpoint"])
+	require.Equal(t, map[string]any{"txHash": "0x01"}, record["bidInfo"])
+}
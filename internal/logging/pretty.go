@@ -0,0 +1,148 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// CustomJSONHandler is a custom slog.Handler that formats logs as pretty-printed JSON with customized timestamp.
+// Attributes added with With and groups opened with WithGroup are kept, and apply to every record after them.
+type CustomJSONHandler struct {
+	mu      *sync.Mutex // Shared by the handlers derived with With, so records are written whole.
+	encoder *json.Encoder
+	level   slog.Level
+	goas    []groupOrAttrs
+}
+
+// groupOrAttrs is a group opened with WithGroup, or attributes added with WithAttrs.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewCustomJSONHandler creates a new instance of CustomJSONHandler
+func NewCustomJSONHandler(w io.Writer, level slog.Level) *CustomJSONHandler {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ") // Set indentation for pretty-printing
+	return &CustomJSONHandler{
+		mu:      &sync.Mutex{},
+		encoder: encoder,
+		level:   level,
+	}
+}
+
+// Handle processes each log record
+func (h *CustomJSONHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.level {
+		return nil // Skip logs below the set level
+	}
+
+	// Create a map to hold the log entry
+	logEntry := make(map[string]interface{})
+
+	// Customize the timestamp to include only milliseconds
+	logEntry["time"] = r.Time.Format("2006-01-02T15:04:05.000Z07:00") // RFC3339 with milliseconds
+
+	// Set the log level
+	logEntry["level"] = r.Level.String()
+
+	// Set the message
+	logEntry["msg"] = r.Message
+
+	// Attributes added with With come first, each inside the groups opened before it;
+	// a group without any attributes is left out
+	goas := h.goas
+	if r.NumAttrs() == 0 {
+		for len(goas) > 0 && goas[len(goas)-1].group != "" {
+			goas = goas[:len(goas)-1]
+		}
+	}
+	current := logEntry
+	for _, goa := range goas {
+		if goa.group != "" {
+			current = subgroup(current, goa.group)
+			continue
+		}
+		for _, attr := range goa.attrs {
+			addAttr(current, attr)
+		}
+	}
+
+	// Add all other attributes
+	r.Attrs(func(attr slog.Attr) bool {
+		addAttr(current, attr)
+		return true
+	})
+
+	// Encode the log entry as pretty JSON
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.encoder.Encode(logEntry)
+}
+
+// subgroup returns the map of the group name in m, adding it if missing.
+func subgroup(m map[string]interface{}, name string) map[string]interface{} {
+	if group, ok := m[name].(map[string]interface{}); ok {
+		return group
+	}
+	group := make(map[string]interface{})
+	m[name] = group
+	return group
+}
+
+// addAttr adds attr to m: groups as nested objects, inlined without a key,
+// and errors as their message, which would otherwise encode as {}.
+func addAttr(m map[string]interface{}, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		attrs := value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		if attr.Key != "" {
+			m = subgroup(m, attr.Key)
+		}
+		for _, a := range attrs {
+			addAttr(m, a)
+		}
+		return
+	}
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	v := value.Any()
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+	m[attr.Key] = v
+}
+
+// Enabled checks if the handler is enabled for the given level
+func (h *CustomJSONHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+// WithAttrs returns a new handler with the given attributes
+func (h *CustomJSONHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+// WithGroup returns a new handler with the given group name
+func (h *CustomJSONHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *CustomJSONHandler) with(goa groupOrAttrs) *CustomJSONHandler {
+	c := *h
+	c.goas = append(append([]groupOrAttrs(nil), h.goas...), goa)
+	return &c
+}
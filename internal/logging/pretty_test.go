@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// records decodes the pretty-printed records written to buf.
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var r map[string]any
+		require.NoError(t, dec.Decode(&r))
+		out = append(out, r)
+	}
+	return out
+}
+
+func TestCustomJSONHandlerAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCustomJSONHandler(&buf, slog.LevelInfo)).With("app", "preconf_bidder", "version", "0.8.0")
+	logger.Debug("Not written")
+	logger.Info("Bid sent", "txHash", "0x01", "error", errors.New("refused"))
+
+	written := records(t, &buf)
+	require.Len(t, written, 1)
+	r := written[0]
+	require.Equal(t, "INFO", r["level"])
+	require.Equal(t, "Bid sent", r["msg"])
+	require.Equal(t, "preconf_bidder", r["app"], "attributes added with With are kept")
+	require.Equal(t, "0.8.0", r["version"])
+	require.Equal(t, "0x01", r["txHash"])
+	require.Equal(t, "refused", r["error"], "errors are written as their message")
+	require.NotEmpty(t, r["time"])
+}
+
+func TestCustomJSONHandlerGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCustomJSONHandler(&buf, slog.LevelInfo)).
+		With("app", "preconf_bidder").
+		WithGroup("bid").
+		With("lane", "blob")
+	logger.Info("Bid resolved", "targetBlock", 100, slog.Group("timings", "latency", 5), slog.Group("empty"))
+	logger.WithGroup("unused").Info("Without attributes")
+	slog.New(NewCustomJSONHandler(&buf, slog.LevelInfo)).WithGroup("bid").Info("Empty group")
+	slog.New(NewCustomJSONHandler(&buf, slog.LevelInfo)).Info("Inlined", slog.Group("", "a", 1))
+
+	written := records(t, &buf)
+	require.Len(t, written, 4)
+	require.Equal(t, "preconf_bidder", written[0]["app"], "attributes before a group stay at the top")
+	require.Equal(t, map[string]any{
+		"lane":        "blob",
+		"targetBlock": float64(100),
+		"timings":     map[string]any{"latency": float64(5)},
+	}, written[0]["bid"])
+	require.Equal(t, map[string]any{"lane": "blob"}, written[1]["bid"], "a group without attributes is left out")
+	require.NotContains(t, written[1]["bid"], "unused")
+	require.NotContains(t, written[2], "bid")
+	require.Equal(t, float64(1), written[3]["a"], "a group without a key is inlined")
+}
+
+func TestCustomJSONHandlerConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	base := slog.New(NewCustomJSONHandler(&buf, slog.LevelInfo))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(logger *slog.Logger) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				logger.Info("Concurrent", "j", j)
+			}
+		}(base.With("worker", i))
+	}
+	wg.Wait()
+	written := records(t, &buf)
+	require.Len(t, written, 400, "records written by derived handlers do not interleave")
+	require.False(t, strings.Contains(buf.String(), "}{"))
+}
@@ -0,0 +1,148 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Defaults of the log file rotation.
+const (
+	DefaultMaxSizeMB  = 100
+	DefaultMaxBackups = 5
+)
+
+// Rotation is when a log file is rotated and how many rotated files are kept.
+type Rotation struct {
+	MaxSize    int64         // Bytes; 0 never rotates by size.
+	MaxAge     time.Duration // 0 never rotates by age.
+	MaxBackups int           // 0 keeps every rotated file.
+}
+
+// backupLayout names rotated files after the time they were rotated, so they
+// sort in age order.
+const backupLayout = "20060102T150405.000"
+
+// File is a log file rotated by size and age. A rotated file is renamed
+// after the time of rotation, and a new file takes its place.
+type File struct {
+	path     string
+	rotation Rotation
+	now      func() time.Time
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	opened time.Time
+}
+
+// OpenFile opens the log file at path, appending to it, and rotates it as
+// rotation says.
+func OpenFile(path string, rotation Rotation) (*File, error) {
+	f := &File{path: path, rotation: rotation, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	f.f, f.size, f.opened = file, info.Size(), f.now()
+	return nil
+}
+
+// Write implements io.Writer. The file is rotated first if p would take it
+// past the maximum size, or it reached the maximum age.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return 0, os.ErrClosed
+	}
+	bySize := f.rotation.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.rotation.MaxSize
+	byAge := f.rotation.MaxAge > 0 && f.now().Sub(f.opened) >= f.rotation.MaxAge
+	if bySize || byAge {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.f.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate renames the file after the current time, opens a new one and
+// removes the oldest rotated files past the maximum number of backups.
+func (f *File) rotate() error {
+	if err := f.f.Close(); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	f.f = nil
+	backup := f.path + "." + f.now().UTC().Format(backupLayout)
+	if err := os.Rename(f.path, backup); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	return f.prune()
+}
+
+// prune removes the oldest rotated files past the maximum number of backups.
+func (f *File) prune() error {
+	if f.rotation.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := f.Backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > f.rotation.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("failed to remove rotated log file: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// Backups returns the paths of the rotated files, oldest first.
+func (f *File) Backups() ([]string, error) {
+	backups, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	// Other files sharing the prefix are left alone
+	kept := backups[:0]
+	for _, backup := range backups {
+		if _, err := time.Parse(backupLayout, backup[len(f.path)+1:]); err == nil {
+			kept = append(kept, backup)
+		}
+	}
+	sort.Strings(kept)
+	return kept, nil
+}
+
+// Close closes the log file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return nil
+	}
+	err := f.f.Close()
+	f.f = nil
+	return err
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRotation(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "bidder.log")
+	require.NoError(t, os.WriteFile(path+".notes", []byte("kept"), 0o644))
+	f, err := OpenFile(path, Rotation{MaxSize: 10, MaxAge: time.Hour, MaxBackups: 2})
+	require.NoError(t, err)
+	f.now = func() time.Time { return now }
+	f.opened = now
+	write := func(s string) {
+		_, err := f.Write([]byte(s))
+		require.NoError(t, err)
+	}
+
+	write("12345")
+	write("67890")
+	backups, err := f.Backups()
+	require.NoError(t, err)
+	require.Empty(t, backups, "the file is at the maximum size, not past it")
+
+	now = now.Add(time.Second)
+	write("abc")
+	backups, err = f.Backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1, "rotated by size")
+	rotated, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	require.Equal(t, "1234567890", string(rotated))
+
+	now = now.Add(time.Hour)
+	write("def")
+	now = now.Add(time.Hour)
+	write("ghi")
+	backups, err = f.Backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2, "the oldest rotated file is removed")
+	rotated, err = os.ReadFile(backups[0])
+	require.NoError(t, err)
+	require.Equal(t, "abc", string(rotated), "rotated by age")
+
+	require.NoError(t, f.Close())
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "ghi", string(current))
+	_, err = os.Stat(path + ".notes")
+	require.NoError(t, err, "other files are left alone")
+	_, err = f.Write([]byte("x"))
+	require.ErrorIs(t, err, os.ErrClosed)
+}
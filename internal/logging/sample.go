@@ -0,0 +1,127 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Defaults of the sampling of repeated errors.
+const (
+	DefaultSampleBurst    = 10
+	DefaultSampleInterval = time.Minute
+)
+
+// SuppressedKey is the field of the first record of a message written after
+// others were suppressed, counting them.
+const SuppressedKey = "suppressed"
+
+// sampleKey tells repeated records apart: the same message at the same level
+// repeats, whatever its fields.
+type sampleKey struct {
+	level   slog.Level
+	message string
+}
+
+// sampleWindow counts the records of a message in the current interval.
+type sampleWindow struct {
+	start      time.Time
+	written    int
+	suppressed int
+}
+
+// sampler is the state shared by a sampling handler and those derived from it.
+type sampler struct {
+	burst    int
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	windows map[sampleKey]*sampleWindow
+}
+
+// allow reports whether r is written, and how many records of its message
+// were suppressed since the last one written.
+func (s *sampler) allow(r slog.Record) (bool, int) {
+	key := sampleKey{level: r.Level, message: r.Message}
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.windows[key]
+	if !ok || now.Sub(w.start) >= s.interval {
+		// Windows of messages that stopped repeating are dropped as they expire
+		for k, old := range s.windows {
+			if now.Sub(old.start) >= s.interval && old.suppressed == 0 {
+				delete(s.windows, k)
+			}
+		}
+		suppressed := 0
+		if ok {
+			suppressed = w.suppressed
+		}
+		s.windows[key] = &sampleWindow{start: now, written: 1}
+		return true, suppressed
+	}
+	if w.written < s.burst {
+		w.written++
+		return true, 0
+	}
+	w.suppressed++
+	return false, 0
+}
+
+// sampling writes at most burst records of the same warning or error message
+// per interval.
+type sampling struct {
+	next    slog.Handler
+	sampler *sampler
+}
+
+// Sample returns next receiving at most burst records of the same message at
+// warning level and above per interval, so a failure repeating every block
+// does not flood the logs. The first record written after others were
+// suppressed counts them in SuppressedKey. Records below warning level all
+// go through. A burst of 0 samples nothing.
+func Sample(next slog.Handler, burst int, interval time.Duration) slog.Handler {
+	if burst <= 0 || interval <= 0 {
+		return next
+	}
+	return sampling{next: next, sampler: &sampler{
+		burst:    burst,
+		interval: interval,
+		now:      time.Now,
+		windows:  make(map[sampleKey]*sampleWindow),
+	}}
+}
+
+// Enabled implements slog.Handler.
+func (h sampling) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h sampling) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn {
+		return h.next.Handle(ctx, r)
+	}
+	ok, suppressed := h.sampler.allow(r)
+	if !ok {
+		return nil
+	}
+	if suppressed > 0 {
+		r = r.Clone()
+		r.AddAttrs(slog.Int(SuppressedKey, suppressed))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h sampling) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return sampling{next: h.next.WithAttrs(attrs), sampler: h.sampler}
+}
+
+// WithGroup implements slog.Handler.
+func (h sampling) WithGroup(name string) slog.Handler {
+	return sampling{next: h.next.WithGroup(name), sampler: h.sampler}
+}
@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSample(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	var buf bytes.Buffer
+	handler := Sample(NewHandler(&buf, JSON, slog.LevelDebug), 2, time.Minute)
+	handler.(sampling).sampler.now = func() time.Time { return now }
+	logger := slog.New(handler).With("app", "preconf_bidder")
+
+	for i := 0; i < 5; i++ {
+		logger.Error("Failed to send bid", "attempt", i)
+		logger.Info("New block received", "blockNumber", i)
+	}
+	logger.Warn("Subscription error")
+
+	now = now.Add(time.Minute)
+	logger.Error("Failed to send bid", "attempt", 5)
+
+	var written []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &r))
+		written = append(written, r)
+	}
+	var failures []map[string]any
+	infos := 0
+	for _, r := range written {
+		switch r["msg"] {
+		case "Failed to send bid":
+			failures = append(failures, r)
+		case "New block received":
+			infos++
+		}
+	}
+	require.Equal(t, 5, infos, "records below warning level are not sampled")
+	require.Len(t, failures, 3)
+	require.Equal(t, float64(0), failures[0]["attempt"])
+	require.Equal(t, float64(1), failures[1]["attempt"])
+	require.NotContains(t, failures[1], SuppressedKey)
+	require.Equal(t, float64(5), failures[2]["attempt"])
+	require.Equal(t, float64(3), failures[2][SuppressedKey], "the next interval counts the suppressed records")
+	require.Equal(t, "preconf_bidder", failures[2]["app"])
+	require.Contains(t, buf.String(), "Subscription error", "messages are sampled separately")
+}
+
+func TestSampleDisabled(t *testing.T) {
+	next := NewHandler(&bytes.Buffer{}, JSON, slog.LevelInfo)
+	require.Equal(t, next, Sample(next, 0, time.Minute))
+}
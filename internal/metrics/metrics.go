@@ -0,0 +1,424 @@
+// Package metrics exposes the bidder's Prometheus metrics.
+package metrics
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+// Namespace prefixes the name of every bidder metric.
+const Namespace = "preconf_bidder"
+
+// Stages at which a bid can fail, used as the stage label of BidFailures.
+const (
+	StageTx      = "tx"      // The transaction could not be created or signed.
+	StageSend    = "send"    // The bidder node rejected or did not accept the bid.
+	StageReceive = "receive" // The commitment stream failed.
+)
+
+// Registry holds the bidder's metrics. It is separate from the default
+// registry so only the bidder's metrics and the Go runtime are exported.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(&describedRegisterer{Registerer: Registry})
+
+// described holds the bidder's metrics in declaration order.
+var described []prometheus.Collector
+
+// describedRegisterer registers the bidder's metrics and keeps them, so they
+// can be described without being observed first.
+type describedRegisterer struct {
+	prometheus.Registerer
+}
+
+// MustRegister implements prometheus.Registerer.
+func (r *describedRegisterer) MustRegister(cs ...prometheus.Collector) {
+	described = append(described, cs...)
+	r.Registerer.MustRegister(cs...)
+}
+
+var (
+	// BidsSent counts bids accepted by the bidder node.
+	BidsSent = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "bids_sent_total",
+		Help:      "Bids accepted by the bidder node.",
+	})
+	// BidsAccepted counts bids that received at least one commitment.
+	BidsAccepted = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "bids_accepted_total",
+		Help:      "Bids that received at least one commitment.",
+	})
+	// CommittedBidAmount sums the amounts of bids that received at least one
+	// commitment, an upper bound of what bidding cost.
+	CommittedBidAmount = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "committed_bid_amount_eth_total",
+		Help:      "Amount of bids that received at least one commitment, in ETH.",
+	})
+	// CommitmentsReceived counts commitments received from providers.
+	CommitmentsReceived = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "commitments_received_total",
+		Help:      "Commitments received from providers.",
+	})
+	// BidFailures counts bids that failed, by stage.
+	BidFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "bid_failures_total",
+		Help:      "Bids that failed, by stage: tx, send or receive.",
+	}, []string{"stage"})
+	// WSReconnects counts reconnections of the block subscription.
+	WSReconnects = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "ws_reconnects_total",
+		Help:      "Reconnections of the WebSocket block subscription.",
+	})
+	// TxSendErrors counts failures to submit a transaction to the builder endpoint.
+	TxSendErrors = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "tx_send_errors_total",
+		Help:      "Failures to submit a transaction to the builder endpoint.",
+	})
+	// BundleSubmissions counts bundles, or private transactions, sent to each
+	// relay, by result.
+	BundleSubmissions = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "bundle_submissions_total",
+		Help:      "Bundles or private transactions sent to each relay, by relay and result: ok or error.",
+	}, []string{"relay", "result"})
+	// PublicBroadcasts counts transactions broadcast to the public mempool
+	// after blocks without a commitment, by result.
+	PublicBroadcasts = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "public_broadcasts_total",
+		Help:      "Transactions broadcast to the public mempool after blocks without a commitment, by result: ok or error.",
+	}, []string{"result"})
+	// TxSendRecoveries counts transactions rebuilt and submitted again after a
+	// recoverable submission error, by error.
+	TxSendRecoveries = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "tx_send_recoveries_total",
+		Help:      "Transactions rebuilt and submitted again after a submission error, by error: nonce-too-low or replacement-underpriced.",
+	}, []string{"error"})
+	// StaleTxReplacements counts transactions rebuilt with bumped fees for a
+	// later block after their bid went without a commitment.
+	StaleTxReplacements = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "stale_tx_replacements_total",
+		Help:      "Transactions rebuilt with bumped fees for a later block after their bid went without a commitment.",
+	})
+	// WSStalls counts block subscriptions re-established after delivering no
+	// header for ws_stall_slots slots.
+	WSStalls = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "ws_stalls_total",
+		Help:      "Block subscriptions re-established after delivering no header for ws_stall_slots slots.",
+	})
+	// BackfilledBlocks counts blocks missed while the block subscription was
+	// down whose headers were read after it reconnected.
+	BackfilledBlocks = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "backfilled_blocks_total",
+		Help:      "Blocks missed while the block subscription was down whose headers were read after it reconnected.",
+	})
+	// PreconfRPCSubmissions counts transactions submitted to the preconf RPC
+	// instead of being bid for through the bidder node, by result.
+	PreconfRPCSubmissions = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "preconf_rpc_submissions_total",
+		Help:      "Transactions submitted to the mev-commit preconf RPC, by result: ok or error.",
+	}, []string{"result"})
+	// CommitmentConflicts counts commitments received for a transaction whose
+	// account nonce was already held by another committed transaction.
+	CommitmentConflicts = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "commitment_conflicts_total",
+		Help:      "Commitments for a transaction conflicting with an already committed one of the same account and nonce.",
+	})
+	// CommitmentsNotHonored counts our commitments stored on the mev-commit
+	// chain whose transactions were missing from the target block, by provider.
+	CommitmentsNotHonored = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "commitments_not_honored_total",
+		Help:      "Our commitments stored on the mev-commit chain whose transactions were missing from the target block, by provider.",
+	}, []string{"provider"})
+	// DisputesSettled counts our commitments not honored that the oracle
+	// processed, by provider and outcome: slashed or rewarded.
+	DisputesSettled = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "disputes_settled_total",
+		Help:      "Our commitments not honored that the oracle processed, by provider and outcome: slashed or rewarded.",
+	}, []string{"provider", "outcome"})
+	// PreconfInclusions counts committed bids checked against their target
+	// block, by provider and result: honored when the transaction was
+	// included, violated when it was missing.
+	PreconfInclusions = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "preconf_inclusions_total",
+		Help:      "Committed bids checked against their target block, by provider and result: honored or violated.",
+	}, []string{"provider", "result"})
+	// UncommittedInclusions counts transactions included in their target
+	// block although their bid was not committed.
+	UncommittedInclusions = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "uncommitted_inclusions_total",
+		Help:      "Transactions included in their target block although their bid was not committed.",
+	})
+	// BidRetries counts bids sent again because the bidder node was
+	// unavailable.
+	BidRetries = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "bid_retries_total",
+		Help:      "Bids sent again because the bidder node was unavailable.",
+	})
+	// BidderConnected is 1 while the connection to the bidder node is up.
+	BidderConnected = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "bidder_connected",
+		Help:      "Whether the connection to the bidder node is up (1) or being re-established (0).",
+	})
+	// BidderReconnects counts restorations of the bidder node connection.
+	BidderReconnects = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "bidder_reconnects_total",
+		Help:      "Restorations of the connection to the bidder node after it was lost.",
+	})
+	// BlocksSkipped counts blocks without a bid, by reason.
+	BlocksSkipped = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "blocks_skipped_total",
+		Help:      "Blocks without a bid, by skip reason.",
+	}, []string{"reason"})
+	// BidsCommittedOnChain counts bids with at least one commitment stored on
+	// the mev-commit chain.
+	BidsCommittedOnChain = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "bids_committed_on_chain_total",
+		Help:      "Bids with at least one commitment stored on the mev-commit chain.",
+	})
+	// BidsNotCommittedOnChain counts bids without a stored commitment before
+	// the correlation timeout.
+	BidsNotCommittedOnChain = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "bids_not_committed_on_chain_total",
+		Help:      "Bids without a commitment stored on the mev-commit chain before the timeout.",
+	})
+	// BidScale is the multiplier of the adaptive pricing controller.
+	BidScale = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "bid_scale",
+		Help:      "Multiplier of the bid amount set by the adaptive pricing controller.",
+	})
+	// BudgetSpent is the ETH committed in bids within each budget window.
+	BudgetSpent = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "budget_spent_eth",
+		Help:      "ETH committed in bids within the last hour or day.",
+	}, []string{"window"})
+	// BudgetRemaining is the ETH left in each budget window, after bids in
+	// flight.
+	BudgetRemaining = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "budget_remaining_eth",
+		Help:      "ETH left to commit within the last hour or day, after bids in flight.",
+	}, []string{"window"})
+	// Campaigns is the number of scheduled campaigns in each state.
+	Campaigns = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "campaigns",
+		Help:      "Scheduled campaigns, by state; blocked campaigns lack funds to start.",
+	}, []string{"state"})
+	// Degraded is 1 while optional work is shed under resource pressure.
+	Degraded = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "degraded",
+		Help:      "Whether optional work is shed because memory or goroutines are above their thresholds (1) or not (0).",
+	})
+	// CommitmentsPerSlot is the average number of commitments observed on the
+	// mev-commit chain per slot, ours and the rest of the market's.
+	CommitmentsPerSlot = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "observed_commitments_per_slot",
+		Help:      "Average commitments stored on the mev-commit chain per slot over the last 8 slots, by origin: own or market.",
+	}, []string{"origin"})
+	// InclusionsPerSlot is the average number of blob transactions included
+	// in L1 blocks per slot, ours and the rest of the market's.
+	InclusionsPerSlot = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "observed_inclusions_per_slot",
+		Help:      "Average blob transactions included in L1 blocks per slot over the last 8 slots, by origin: own or market.",
+	}, []string{"origin"})
+	// BidLatency observes how long a bid takes, from sending it until its
+	// commitment stream ends.
+	BidLatency = factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "bid_latency_seconds",
+		Help:      "Time from sending a bid until its commitment stream ends.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 4, 8, 12, 24, 36},
+	})
+	// CommitmentRoundTrip observes the time from sending a bid until each of
+	// its commitments is received.
+	CommitmentRoundTrip = factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "commitment_round_trip_seconds",
+		Help:      "Time from sending a bid until a commitment is received.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 4, 8, 12},
+	})
+	// CommitmentStoredLatency observes the time from sending a bid until a
+	// commitment for it is stored on the mev-commit chain.
+	CommitmentStoredLatency = factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "commitment_stored_seconds",
+		Help:      "Time from sending a bid until a commitment for it is stored on the mev-commit chain.",
+		Buckets:   []float64{0.5, 1, 2, 4, 8, 12, 24, 48, 96},
+	})
+	// ProviderCommitRate is the share of our expired bids each provider
+	// committed to.
+	ProviderCommitRate = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "provider_commit_rate",
+		Help:      "Share of our bids, since the start, whose commitment by the provider was stored on the mev-commit chain.",
+	}, []string{"provider"})
+	// ProviderStake is the stake of each provider in the ProviderRegistry.
+	ProviderStake = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "provider_stake_eth",
+		Help:      "Stake of the provider in the ProviderRegistry, in ETH.",
+	}, []string{"provider"})
+	// ProviderWeight is how much a commitment of each provider counts towards
+	// the adaptive commitment rate.
+	ProviderWeight = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "provider_weight",
+		Help:      "How much a commitment of the provider counts towards the adaptive commitment rate, from 0 to 1.",
+	}, []string{"provider"})
+	// ProviderDispatchLatency observes the time from sending a bid until a
+	// provider dispatched its commitment, by provider.
+	ProviderDispatchLatency = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "provider_dispatch_latency_seconds",
+		Help:      "Time from sending a bid until the provider dispatched its commitment, by provider.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 4, 8, 12},
+	}, []string{"provider"})
+	// JobRuns counts runs of the maintenance jobs, by job and result (ok or
+	// error, including panics).
+	JobRuns = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "job_runs_total",
+		Help:      "Runs of the maintenance jobs, by job and result.",
+	}, []string{"job", "result"})
+	// StreamEventsDropped counts bid lifecycle events not sent to a
+	// Server-Sent Events client that fell behind.
+	StreamEventsDropped = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "stream_events_dropped_total",
+		Help:      "Bid lifecycle events dropped for /events clients that fell behind.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Description documents a bidder metric.
+type Description struct {
+	Name   string
+	Help   string
+	Labels []string
+}
+
+// descPattern parses prometheus.Desc.String, the only way to read a
+// descriptor's name, help and labels.
+var descPattern = regexp.MustCompile(`^Desc\{fqName: ("(?:[^"\\]|\\.)*"), help: ("(?:[^"\\]|\\.)*"), constLabels: \{.*\}, variableLabels: \[(.*)\]\}$`)
+
+// Descriptions returns the bidder's metrics in declaration order, without the
+// Go runtime and process metrics.
+func Descriptions() []Description {
+	var descriptions []Description
+	for _, c := range described {
+		descs := make(chan *prometheus.Desc, 1)
+		go func() {
+			c.Describe(descs)
+			close(descs)
+		}()
+		for desc := range descs {
+			m := descPattern.FindStringSubmatch(desc.String())
+			if m == nil {
+				continue
+			}
+			name, _ := strconv.Unquote(m[1])
+			help, _ := strconv.Unquote(m[2])
+			d := Description{Name: name, Help: help}
+			if labels := strings.Fields(m[3]); len(labels) > 0 {
+				d.Labels = labels
+			}
+			descriptions = append(descriptions, d)
+		}
+	}
+	return descriptions
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// LabeledHandler serves the metrics like Handler, with the labels returned by
+// labels added to every metric when they are gathered. Metrics are declared
+// before the labels are known, and the labels may change while the bidder
+// runs, such as the campaign it bids in; a counter's increase is attributed to
+// the labels in effect when it is scraped.
+func LabeledHandler(labels func() map[string]string) http.Handler {
+	return promhttp.HandlerFor(labeledGatherer{Gatherer: Registry, labels: labels}, promhttp.HandlerOpts{})
+}
+
+// labeledGatherer adds labels to the metrics of a gatherer.
+type labeledGatherer struct {
+	prometheus.Gatherer
+	labels func() map[string]string
+}
+
+// Gather implements prometheus.Gatherer. Labels a metric already has are
+// left as they are.
+func (g labeledGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.Gatherer.Gather()
+	labels := g.labels()
+	if len(labels) == 0 {
+		return families, err
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			has := make(map[string]bool, len(m.GetLabel()))
+			for _, l := range m.GetLabel() {
+				has[l.GetName()] = true
+			}
+			for _, name := range names {
+				if !has[name] {
+					m.Label = append(m.Label, &dto.LabelPair{Name: proto.String(name), Value: proto.String(labels[name])})
+				}
+			}
+			sort.Slice(m.Label, func(i, j int) bool { return m.Label[i].GetName() < m.Label[j].GetName() })
+		}
+	}
+	return families, err
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerExposesMetrics(t *testing.T) {
+	WSReconnects.Inc()
+	BidFailures.WithLabelValues(StageReceive).Inc()
+	CommitmentRoundTrip.Observe(0.3)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	require.Contains(t, body, "preconf_bidder_ws_reconnects_total 1")
+	require.Contains(t, body, `preconf_bidder_bid_failures_total{stage="receive"} 1`)
+	require.Contains(t, body, `preconf_bidder_commitment_round_trip_seconds_bucket{le="0.5"} 1`)
+	require.Contains(t, body, "go_goroutines")
+}
+
+func TestLabeledHandlerAddsLabels(t *testing.T) {
+	BidFailures.WithLabelValues(StageSend).Inc()
+
+	campaign := "spring"
+	h := LabeledHandler(func() map[string]string {
+		return map[string]string{"tenant": "acme", "campaign": campaign}
+	})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	require.Contains(t, body, `preconf_bidder_bid_failures_total{campaign="spring",stage="send",tenant="acme"} 1`)
+	require.Contains(t, body, `go_goroutines{campaign="spring",tenant="acme"}`)
+
+	// Gathered metrics are not changed for later scrapes
+	campaign = "summer"
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	require.Contains(t, rec.Body.String(), `preconf_bidder_bid_failures_total{campaign="summer",stage="send",tenant="acme"} 1`)
+}
+
+func TestDescriptionsListEveryMetric(t *testing.T) {
+	descriptions := Descriptions()
+	require.Equal(t, Description{
+		Name: "preconf_bidder_bids_sent_total",
+		Help: "Bids accepted by the bidder node.",
+	}, descriptions[0])
+
+	byName := make(map[string]Description)
+	for _, d := range descriptions {
+		byName[d.Name] = d
+	}
+	require.Len(t, byName, len(described), "one description per metric")
+	require.Equal(t, []string{"stage"}, byName["preconf_bidder_bid_failures_total"].Labels)
+	require.NotContains(t, byName, "go_goroutines")
+}
@@ -5,6 +5,7 @@ package mevcommit
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
@@ -15,44 +16,148 @@ import (
 
 	"github.com/ethereum/go-ethereum/core/types"
 	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"github.com/primev/preconf_blob_bidder/internal/clock"
+	errs "github.com/primev/preconf_blob_bidder/internal/errors"
+	"github.com/primev/preconf_blob_bidder/internal/latency"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+	"github.com/primev/preconf_blob_bidder/internal/money"
+	"github.com/primev/preconf_blob_bidder/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-// Initialize the logger with JSON format.
-// It's recommended to configure the logger in the main package.
-// If you need to initialize it here, uncomment the following init function.
-
-// func init() {
-// 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-// 	slog.SetDefault(logger)
-// }
-
 // BidderInterface defines the methods that Bidder and MockBidderClient must implement.
 type BidderInterface interface {
 	SendBid(input interface{}, amount string, blockNumber, decayStart, decayEnd int64) (pb.Bidder_SendBidClient, error)
 }
 
-// SendPreconfBid sends a preconfirmation bid to the bidder client
-func SendPreconfBid(bidderClient BidderInterface, input interface{}, blockNumber int64, randomEthAmount float64) {
-	// Get current time in milliseconds
-	currentTime := time.Now().UnixMilli()
+// ContextBidder is implemented by bidder clients whose bid streams can be
+// canceled through a context, e.g. when an in-flight bid is reaped.
+type ContextBidder interface {
+	SendBidContext(ctx context.Context, input interface{}, amount string, blockNumber, decayStart, decayEnd int64) (pb.Bidder_SendBidClient, error)
+}
+
+// SlotDuration is the L1 slot time.
+const SlotDuration = 12 * time.Second
+
+// DecayWindow returns how long a bid for the block offset blocks ahead of the
+// head decays for. A next block bid decays over three slots, and every further
+// block adds a slot, so bids for later blocks are not decayed before their
+// block is built.
+func DecayWindow(offset uint64) time.Duration {
+	if offset == 0 {
+		offset = 1
+	}
+	return time.Duration(offset+2) * SlotDuration
+}
+
+// DefaultMinDecay is the shortest decay window allowed by default. Providers
+// need time to see and commit to a bid before it has fully decayed.
+const DefaultMinDecay = SlotDuration
+
+// ErrDecayOutOfBounds is returned for decay windows outside the allowed bounds.
+var ErrDecayOutOfBounds = errors.New("decay window out of bounds")
+
+// DecayBounds are the allowed decay window durations. A zero Max means no
+// upper bound. Windows outside the bounds are clamped when Clamp is set and
+// rejected otherwise.
+type DecayBounds struct {
+	Min   time.Duration
+	Max   time.Duration
+	Clamp bool
+}
+
+// Apply returns decay within the bounds, or an error wrapping
+// ErrDecayOutOfBounds. A window must be positive: the bidder node rejects bids
+// whose decay does not end after it starts.
+func (b DecayBounds) Apply(decay time.Duration) (time.Duration, error) {
+	lower := b.Min
+	if lower < time.Millisecond {
+		lower = time.Millisecond
+	}
+	switch {
+	case decay < lower:
+		if !b.Clamp {
+			return 0, fmt.Errorf("%w: %s is shorter than the minimum %s", ErrDecayOutOfBounds, decay, lower)
+		}
+		return lower, nil
+	case b.Max > 0 && decay > b.Max:
+		if !b.Clamp {
+			return 0, fmt.Errorf("%w: %s is longer than the maximum %s", ErrDecayOutOfBounds, decay, b.Max)
+		}
+		return b.Max, nil
+	}
+	return decay, nil
+}
+
+// SendPreconfBid sends a preconfirmation bid for the next block to the bidder
+// client and returns the commitments received from providers before the
+// response stream ended.
+func SendPreconfBid(bidderClient BidderInterface, input interface{}, blockNumber int64, randomEthAmount float64) []*pb.Commitment {
+	return SendPreconfBidContext(context.Background(), bidderClient, input, blockNumber, randomEthAmount, DecayWindow(1))
+}
+
+// SendPreconfBidContext is like SendPreconfBid, but decays the bid over decay
+// and closes the bid stream when ctx is canceled if the bidder client
+// implements ContextBidder.
+func SendPreconfBidContext(ctx context.Context, bidderClient BidderInterface, input interface{}, blockNumber int64, randomEthAmount float64, decay time.Duration) []*pb.Commitment {
+	return SendPreconfBidReport(ctx, bidderClient, input, blockNumber, randomEthAmount, decay).Commitments
+}
+
+// BidReport describes a bid sent by SendPreconfBidReport and the bidder
+// node's response to it.
+type BidReport struct {
+	Amount      string // Bid amount in wei.
+	DecayStart  int64  // Start of the decay window, in Unix milliseconds.
+	DecayEnd    int64  // End of the decay window, in Unix milliseconds.
+	Sent        bool   // Whether the bidder node accepted the bid.
+	Commitments []*pb.Commitment
+	Err         error // Why the bid was not sent or its response stream failed.
+
+	FirstCommitment time.Duration // From sending the bid until the first commitment arrived.
+	Stream          time.Duration // From sending the bid until its response stream ended.
+}
+
+// SendPreconfBidReport is like SendPreconfBidContext, but reports the decay
+// window and the outcome of the bid along with its commitments.
+func SendPreconfBidReport(ctx context.Context, bidderClient BidderInterface, input interface{}, blockNumber int64, randomEthAmount float64, decay time.Duration) BidReport {
+	// Get current time in milliseconds, corrected for any detected clock skew
+	currentTime := clock.Now().UnixMilli()
 
 	// Define bid decay start and end
 	decayStart := currentTime
-	decayEnd := currentTime + int64(time.Duration(36*time.Second).Milliseconds()) // Bid decay is 36 seconds (2 blocks)
+	decayEnd := currentTime + decay.Milliseconds()
+	report := BidReport{DecayStart: decayStart, DecayEnd: decayEnd}
+	if decayEnd <= decayStart {
+		// The bidder node would reject the bid, fail here with a clear reason instead
+		slog.Warn("Invalid decay window, bid not sent",
+			"decay", decay,
+			"blockNumber", blockNumber,
+		)
+		metrics.BidFailures.WithLabelValues(metrics.StageSend).Inc()
+		report.Err = fmt.Errorf("%w: decay window of %s", ErrDecayOutOfBounds, decay)
+		return report
+	}
 
-	// Convert the random ETH amount to wei (1 ETH = 10^18 wei)
-	bigEthAmount := big.NewFloat(randomEthAmount)
-	weiPerEth := big.NewFloat(1e18)
-	bigWeiAmount := new(big.Float).Mul(bigEthAmount, weiPerEth)
+	// Convert the random ETH amount to wei and then to a string for the bidder;
+	// the ETH amount logged is that of the wei sent
+	wei := money.FromEth(randomEthAmount)
+	amount, sentEth := wei.String(), money.ToEth(wei)
+	report.Amount = amount
 
-	// Convert big.Float to big.Int
-	randomWeiAmount := new(big.Int)
-	bigWeiAmount.Int(randomWeiAmount)
+	// Only the hash and size of the payload are logged unless raw retention is enabled
+	payload := SummarizePayload(input)
 
-	// Convert the amount to a string for the bidder
-	amount := randomWeiAmount.String()
+	sendBid := bidderClient.SendBid
+	if cb, ok := bidderClient.(ContextBidder); ok {
+		sendBid = func(input interface{}, amount string, blockNumber, decayStart, decayEnd int64) (pb.Bidder_SendBidClient, error) {
+			return cb.SendBidContext(ctx, input, amount, blockNumber, decayStart, decayEnd)
+		}
+	}
 
 	// Determine how to handle the input
+	sent := time.Now()
 	var responseClient pb.Bidder_SendBidClient
 	var err error
 	switch v := input.(type) {
@@ -67,89 +172,128 @@ func SendPreconfBid(bidderClient BidderInterface, input interface{}, blockNumber
 			"decayEnd", decayEnd,
 		)
 		// Send the bid with tx hash string
-		responseClient, err = bidderClient.SendBid([]string{txHash}, amount, blockNumber, decayStart, decayEnd)
+		responseClient, err = sendBid([]string{txHash}, amount, blockNumber, decayStart, decayEnd)
 
 	case *types.Transaction:
 		// Check for nil transaction
 		if v == nil {
 			slog.Warn("Transaction is nil, cannot send bid.")
-			return
+			report.Err = fmt.Errorf("transaction is nil")
+			return report
 		}
 		// Input is a transaction object, send the transaction object
 		slog.Info("Sending bid with transaction payload",
-			"txHash", v.Hash().String(),
-			"amount", amount,
-			"blockNumber", blockNumber,
-			"decayStart", decayStart,
-			"decayEnd", decayEnd,
+			append(payload.LogAttrs(),
+				"amount", amount,
+				"blockNumber", blockNumber,
+				"decayStart", decayStart,
+				"decayEnd", decayEnd,
+			)...,
 		)
 		// Send the bid with the full transaction object
-		responseClient, err = bidderClient.SendBid([]*types.Transaction{v}, amount, blockNumber, decayStart, decayEnd)
+		responseClient, err = sendBid([]*types.Transaction{v}, amount, blockNumber, decayStart, decayEnd)
 
 	default:
 		slog.Warn("Unsupported input type, must be string or *types.Transaction",
 			"inputType", fmt.Sprintf("%T", input),
 		)
-		return
+		report.Err = fmt.Errorf("unsupported input type %T", input)
+		return report
 	}
 
 	// Check if there was an error sending the bid
 	if err != nil {
+		metrics.BidFailures.WithLabelValues(metrics.StageSend).Inc()
+		budget, _ := latency.Exceeded(err)
 		slog.Warn("Failed to send bid",
-			"err", err,
-			"txHash", fmt.Sprintf("%v", input),
+			"error", err,
+			"budgetExceeded", budget,
+			"txHash", payload.TxHash,
+			"payloadSize", payload.Size,
 			"amount", amount,
 			"blockNumber", blockNumber,
 			"decayStart", decayStart,
 			"decayEnd", decayEnd,
 		)
-		return
+		report.Err = err
+		return report
 	}
 
-	// Call Recv() to handle the response and complete the expectation in your tests
-	_, recvErr := responseClient.Recv()
-	if recvErr == io.EOF {
-		slog.Info("Bid response received: EOF",
-			"txHash", fmt.Sprintf("%v", input),
+	metrics.BidsSent.Inc()
+	report.Sent = true
+
+	// Drain the response stream, collecting every commitment until EOF
+	_, span := tracing.Start(ctx, tracing.Commitment, attribute.Int64("blockNumber", blockNumber))
+	commitments, first, recvErr := receiveCommitments(responseClient, sent)
+	span.SetAttributes(attribute.Int("commitments", len(commitments)), attribute.Int64("firstCommitmentMs", first.Milliseconds()))
+	tracing.End(span, recvErr)
+	report.Commitments, report.Err = commitments, recvErr
+	report.FirstCommitment, report.Stream = first, time.Since(sent)
+	metrics.BidLatency.Observe(report.Stream.Seconds())
+	if recvErr != nil {
+		metrics.BidFailures.WithLabelValues(metrics.StageReceive).Inc()
+		budget, _ := latency.Exceeded(recvErr)
+		slog.Warn("Error receiving bid response",
+			"error", recvErr,
+			"budgetExceeded", budget,
+			"txHash", payload.TxHash,
+			"payloadSize", payload.Size,
 			"blockNumber", blockNumber,
-			"amount_ETH", randomEthAmount,
 			"decayStart", decayStart,
 			"decayEnd", decayEnd,
 		)
-	} else if recvErr != nil {
-		slog.Warn("Error receiving bid response",
-			"err", recvErr,
-			"txHash", fmt.Sprintf("%v", input),
+	} else if len(commitments) == 0 {
+		slog.Info("Bid response received: EOF",
+			"txHash", payload.TxHash,
+			"payloadSize", payload.Size,
 			"blockNumber", blockNumber,
+			"amount_ETH", sentEth,
 			"decayStart", decayStart,
 			"decayEnd", decayEnd,
 		)
 	} else {
+		metrics.BidsAccepted.Inc()
+		metrics.CommittedBidAmount.Add(sentEth)
 		slog.Info("Sent preconfirmation bid and received response",
 			"block", blockNumber,
-			"amount_ETH", randomEthAmount,
+			"amount_ETH", sentEth,
 			"decayStart", decayStart,
 			"decayEnd", decayEnd,
+			"commitments", len(commitments),
 		)
 	}
+
+	return report
+}
+
+// EthToWei converts an ETH amount to wei (1 ETH = 10^18 wei), truncating any
+// fractional wei. See money.FromEth.
+func EthToWei(eth float64) *big.Int {
+	return money.FromEth(eth)
 }
 
 // SendBid handles sending a bid request after preparing the input data.
 func (b *Bidder) SendBid(input interface{}, amount string, blockNumber, decayStart, decayEnd int64) (pb.Bidder_SendBidClient, error) {
+	return b.SendBidContext(context.Background(), input, amount, blockNumber, decayStart, decayEnd)
+}
+
+// SendBidContext is like SendBid, but the bid stream is closed when ctx is canceled.
+func (b *Bidder) SendBidContext(ctx context.Context, input interface{}, amount string, blockNumber, decayStart, decayEnd int64) (pb.Bidder_SendBidClient, error) {
 	txHashes, rawTransactions, err := b.parseInput(input)
 	if err != nil {
 		return nil, err
 	}
 
 	bidRequest := b.createBidRequest(amount, blockNumber, decayStart, decayEnd, txHashes, rawTransactions)
+	if err := checkBidSize(bidRequest, b.maxPayload); err != nil {
+		return nil, err
+	}
 
-	response, err := b.sendBidRequest(bidRequest)
+	response, err := b.sendBidRequest(ctx, bidRequest)
 	if err != nil {
 		return nil, err
 	}
 
-	b.receiveBidResponses(response)
-
 	return response, nil
 }
 
@@ -170,7 +314,7 @@ func (b *Bidder) parseInput(input interface{}) ([]string, []string, error) {
 			rlpEncodedTx, err := tx.MarshalBinary()
 			if err != nil {
 				slog.Error("Failed to marshal transaction to raw format",
-					"err", err,
+					"error", err,
 				)
 				return nil, nil, fmt.Errorf("failed to marshal transaction: %w", err)
 			}
@@ -204,42 +348,76 @@ func (b *Bidder) createBidRequest(amount string, blockNumber, decayStart, decayE
 	return bidRequest
 }
 
-// sendBidRequest sends the prepared bid request to the mev-commit client.
-func (b *Bidder) sendBidRequest(bidRequest *pb.Bid) (pb.Bidder_SendBidClient, error) {
-	ctx := context.Background()
-	response, err := b.client.SendBid(ctx, bidRequest)
+// sendBidRequest sends the prepared bid request to the mev-commit client,
+// retrying while the node is unavailable. The whole stream, until its last
+// commitment, is bounded by the send bid budget.
+func (b *Bidder) sendBidRequest(parent context.Context, bidRequest *pb.Bid) (pb.Bidder_SendBidClient, error) {
+	ctx, cancel := latency.Context(parent, latency.SendBid)
+	response, err := b.sendBidWithRetry(ctx, bidRequest)
 	if err != nil {
+		cancel()
+		err = errs.Classify(latency.Wrap(latency.SendBid, sizeError(err)))
 		slog.Error("Failed to send bid",
-			"err", err,
+			"error", err,
 		)
 		return nil, fmt.Errorf("failed to send bid: %w", err)
 	}
 
-	return response, nil
+	return &budgetedStream{Bidder_SendBidClient: response, cancel: cancel}, nil
+}
+
+// budgetedStream releases the send bid budget once the stream has ended and
+// reports deadline errors against that budget.
+type budgetedStream struct {
+	pb.Bidder_SendBidClient
+	cancel context.CancelFunc
+}
+
+// Recv implements pb.Bidder_SendBidClient.
+func (s *budgetedStream) Recv() (*pb.Commitment, error) {
+	msg, err := s.Bidder_SendBidClient.Recv()
+	if err != nil {
+		s.cancel()
+		if err != io.EOF {
+			err = errs.Classify(latency.Wrap(latency.SendBid, sizeError(deadlineError(err))))
+		}
+	}
+	return msg, err
 }
 
-// receiveBidResponses processes the responses from the bid request.
-func (b *Bidder) receiveBidResponses(response pb.Bidder_SendBidClient) {
+// deadlineError maps a gRPC DeadlineExceeded status to context.DeadlineExceeded
+// so it can be matched with errors.Is.
+func deadlineError(err error) error {
+	if status.Code(err) == codes.DeadlineExceeded {
+		return fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+	}
+	return err
+}
+
+// receiveCommitments reads commitments from the bid response stream until it
+// is closed, timing each against sent, and returns when the first arrived.
+// Commitments received before an error are still returned.
+func receiveCommitments(response pb.Bidder_SendBidClient, sent time.Time) ([]*pb.Commitment, time.Duration, error) {
+	var commitments []*pb.Commitment
+	var first time.Duration
 	for {
 		msg, err := response.Recv()
 		if err == io.EOF {
 			// End of stream
-			break
+			return commitments, first, nil
 		}
 		if err != nil {
-			slog.Error("Failed to receive bid response",
-				"err", err,
-			)
-			continue
+			return commitments, first, err
 		}
 
+		if len(commitments) == 0 {
+			first = time.Since(sent)
+		}
+		metrics.CommitmentsReceived.Inc()
+		metrics.CommitmentRoundTrip.Observe(time.Since(sent).Seconds())
 		slog.Info("Bid accepted",
 			"commitmentDetails", msg,
 		)
+		commitments = append(commitments, msg)
 	}
-
-	startTimeBeforeSaveResponses := time.Now()
-	slog.Info("End Time",
-		"time", startTimeBeforeSaveResponses,
-	)
 }
@@ -7,9 +7,12 @@ import (
 	"math/big"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
 	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/metadata"
@@ -253,3 +256,93 @@ func TestSendBidRequestError(t *testing.T) {
     require.Error(t, err, "Expected an error due to mock send bid error")
     require.Contains(t, err.Error(), "mock send bid error", "Error message should contain 'mock send bid error'")
 }
+
+func TestSendPreconfBidReturnsCommitments(t *testing.T) {
+	mockBidder := new(MockBidderClient)
+	mockSendBidClient := new(MockBidderSendBidClient)
+
+	commitment := &pb.Commitment{ProviderAddress: "0xprovider", BlockNumber: 100}
+
+	mockBidder.On("SendBid", mock.Anything, mock.Anything, int64(100), mock.Anything, mock.Anything).
+		Return(mockSendBidClient, nil)
+	mockSendBidClient.On("Recv").Return(commitment, nil).Once()
+	mockSendBidClient.On("Recv").Return(nil, io.EOF).Once()
+
+	commitments := SendPreconfBid(mockBidder, "0xabc123", 100, 0.001)
+
+	require.Len(t, commitments, 1)
+	require.Equal(t, "0xprovider", commitments[0].ProviderAddress)
+	mockSendBidClient.AssertExpectations(t)
+}
+
+func TestSendPreconfBidDecayScalesWithOffset(t *testing.T) {
+	for offset, want := range map[uint64]int64{1: 36_000, 2: 48_000, 4: 72_000} {
+		mockBidder := new(MockBidderClient)
+		mockSendBidClient := new(MockBidderSendBidClient)
+
+		var decayStart, decayEnd int64
+		mockBidder.On("SendBid", mock.Anything, mock.Anything, int64(100+offset), mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				decayStart, decayEnd = args.Get(3).(int64), args.Get(4).(int64)
+			}).
+			Return(mockSendBidClient, nil)
+		mockSendBidClient.On("Recv").Return(nil, io.EOF).Once()
+
+		SendPreconfBidContext(context.Background(), mockBidder, "0xabc123", int64(100+offset), 0.001, DecayWindow(offset))
+
+		require.Equal(t, want, decayEnd-decayStart, "offset %d", offset)
+	}
+}
+
+func TestSendPreconfBidRecordsMetrics(t *testing.T) {
+	sent := testutil.ToFloat64(metrics.BidsSent)
+	received := testutil.ToFloat64(metrics.CommitmentsReceived)
+	sendFailures := testutil.ToFloat64(metrics.BidFailures.WithLabelValues(metrics.StageSend))
+	accepted := testutil.ToFloat64(metrics.BidsAccepted)
+	amount := testutil.ToFloat64(metrics.CommittedBidAmount)
+
+	mockBidder := new(MockBidderClient)
+	mockSendBidClient := new(MockBidderSendBidClient)
+	mockBidder.On("SendBid", mock.Anything, mock.Anything, int64(100), mock.Anything, mock.Anything).Return(mockSendBidClient, nil)
+	mockSendBidClient.On("Recv").Return(&pb.Commitment{}, nil).Twice()
+	mockSendBidClient.On("Recv").Return(nil, io.EOF).Once()
+	mockBidder.On("SendBid", mock.Anything, mock.Anything, int64(101), mock.Anything, mock.Anything).Return((*MockBidderSendBidClient)(nil), errors.New("unavailable"))
+
+	require.Len(t, SendPreconfBid(mockBidder, "0xabc123", 100, 0.001), 2)
+	require.Empty(t, SendPreconfBid(mockBidder, "0xabc123", 101, 0.001))
+
+	require.Equal(t, sent+1, testutil.ToFloat64(metrics.BidsSent))
+	require.Equal(t, received+2, testutil.ToFloat64(metrics.CommitmentsReceived))
+	require.Equal(t, sendFailures+1, testutil.ToFloat64(metrics.BidFailures.WithLabelValues(metrics.StageSend)))
+	require.Equal(t, accepted+1, testutil.ToFloat64(metrics.BidsAccepted))
+	require.InDelta(t, amount+0.001, testutil.ToFloat64(metrics.CommittedBidAmount), 1e-12)
+}
+
+func TestDecayBounds(t *testing.T) {
+	clamp := DecayBounds{Min: 24 * time.Second, Max: time.Minute, Clamp: true}
+	for decay, want := range map[time.Duration]time.Duration{
+		12 * time.Second: 24 * time.Second,
+		36 * time.Second: 36 * time.Second,
+		2 * time.Minute:  time.Minute,
+	} {
+		got, err := clamp.Apply(decay)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	reject := DecayBounds{Min: 24 * time.Second}
+	_, err := reject.Apply(12 * time.Second)
+	require.ErrorIs(t, err, ErrDecayOutOfBounds)
+	got, err := reject.Apply(time.Hour)
+	require.NoError(t, err, "no maximum")
+	require.Equal(t, time.Hour, got)
+
+	_, err = DecayBounds{}.Apply(0)
+	require.ErrorIs(t, err, ErrDecayOutOfBounds, "a window must be positive")
+}
+
+func TestSendPreconfBidRejectsEmptyDecay(t *testing.T) {
+	mockBidder := new(MockBidderClient)
+	require.Nil(t, SendPreconfBidContext(context.Background(), mockBidder, "0xabc123", 100, 0.001, 0))
+	mockBidder.AssertNotCalled(t, "SendBid")
+}
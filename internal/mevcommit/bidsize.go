@@ -0,0 +1,56 @@
+package mevcommit
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultMaxBidPayload is the largest bid message sent, the default limit of
+// messages a gRPC server accepts.
+const DefaultMaxBidPayload = 4 << 20
+
+// ErrBidTooLarge is returned for bids larger than the payload limit, or
+// refused by the bidder node for their size.
+var ErrBidTooLarge = errors.New("bid payload too large")
+
+// bidTooLargeHint tells how to get large transactions preconfirmed anyway.
+const bidTooLargeHint = "send the transaction hash instead of the payload (hash or commit-reveal privacy), or attach fewer blobs"
+
+// checkBidSize fails bids above max bytes before they are sent, instead of
+// letting the bidder node reject them with ResourceExhausted. A max of 0
+// disables the check.
+func checkBidSize(bid *pb.Bid, max int) error {
+	if max <= 0 {
+		return nil
+	}
+	if size := proto.Size(bid); size > max {
+		return fmt.Errorf("%w: %d bytes exceeds the limit of %d bytes; %s", ErrBidTooLarge, size, max, bidTooLargeHint)
+	}
+	return nil
+}
+
+// sizeError explains a ResourceExhausted status, which the bidder node returns
+// for bids above its message size limit.
+func sizeError(err error) error {
+	if status.Code(err) == codes.ResourceExhausted {
+		return fmt.Errorf("%w: refused by the bidder node (%v); %s", ErrBidTooLarge, err, bidTooLargeHint)
+	}
+	return err
+}
+
+// callOptions returns the options of the SendBid call for bid. Bids carrying
+// raw transactions, which blob sidecars make large, are gzip compressed when
+// compression is enabled.
+func (b *Bidder) callOptions(bid *pb.Bid) []grpc.CallOption {
+	if b.compress && len(bid.GetRawTransactions()) > 0 {
+		return []grpc.CallOption{grpc.UseCompressor(gzip.Name)}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package mevcommit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// optionsBidderClient records the call options of SendBid.
+type optionsBidderClient struct {
+	pb.BidderClient
+	opts []grpc.CallOption
+}
+
+func (c *optionsBidderClient) SendBid(ctx context.Context, in *pb.Bid, opts ...grpc.CallOption) (pb.Bidder_SendBidClient, error) {
+	c.opts = opts
+	return new(MockBidderSendBidClient), nil
+}
+
+func TestSendBidPayloadSize(t *testing.T) {
+	large := []*types.Transaction{types.NewTx(&types.LegacyTx{Data: make([]byte, 4096)})}
+
+	client := &optionsBidderClient{}
+	b := &Bidder{client: client, maxPayload: 1024}
+	_, err := b.SendBidContext(context.Background(), large, "1", 100, 1000, 2000)
+	require.ErrorIs(t, err, ErrBidTooLarge)
+	require.ErrorContains(t, err, "hash or commit-reveal privacy")
+	require.Nil(t, client.opts, "too large bids are not sent")
+
+	b = &Bidder{client: client, maxPayload: DefaultMaxBidPayload, compress: true}
+	_, err = b.SendBidContext(context.Background(), large, "1", 100, 1000, 2000)
+	require.NoError(t, err)
+	require.Len(t, client.opts, 1, "raw transactions are compressed")
+
+	_, err = b.SendBidContext(context.Background(), []string{"0xabc"}, "1", 100, 1000, 2000)
+	require.NoError(t, err)
+	require.Empty(t, client.opts, "hashes are too small to compress")
+
+	refused := &flakyBidderClient{failures: 1, err: status.Error(codes.ResourceExhausted, "grpc: received message larger than max")}
+	b = &Bidder{client: refused}
+	_, err = b.SendBidContext(context.Background(), large, "1", 100, 1000, 2000)
+	require.ErrorIs(t, err, ErrBidTooLarge)
+	require.ErrorContains(t, err, "refused by the bidder node")
+}
@@ -9,9 +9,16 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"math/big"
+	"strings"
+	"sync"
 	"time"
 
 	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	errs "github.com/primev/preconf_blob_bidder/internal/errors"
+	"github.com/primev/preconf_blob_bidder/internal/ident"
+	"github.com/primev/preconf_blob_bidder/internal/latency"
+	"github.com/primev/preconf_blob_bidder/internal/tracing"
 	"google.golang.org/grpc"
 
 	"github.com/ethereum/go-ethereum"
@@ -20,8 +27,6 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/rpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // BidderConfig holds the configuration settings for the mev-commit bidder node.
@@ -29,11 +34,44 @@ type BidderConfig struct {
 	ServerAddress string `json:"server_address" yaml:"server_address"` // The address of the gRPC server for the bidder node.
 	LogFmt        string `json:"log_fmt" yaml:"log_fmt"`               // The format for logging output.
 	LogLevel      string `json:"log_level" yaml:"log_level"`           // The level of logging detail.
+
+	TLS       bool   // Connect over TLS; implied by TLSCACert and TLSCert.
+	TLSCACert string // PEM file of the CA verifying the node; system roots when empty.
+	TLSCert   string // PEM file of the client certificate for mutual TLS.
+	TLSKey    string // PEM file of the client certificate's key.
+	Token     string // Bearer token sent with every call; requires TLS.
+
+	Retry RetryPolicy // Retries of bids the node was unavailable for.
+
+	Compression bool // Gzip compress bids carrying raw transactions; the node must support gzip.
+	MaxPayload  int  // Largest bid message sent, in bytes; 0 for no limit.
 }
 
 // Bidder utilizes the mev-commit bidder client to interact with the mev-commit chain.
 type Bidder struct {
-	client pb.BidderClient // gRPC client for interacting with the mev-commit bidder service.
+	client pb.BidderClient  // gRPC client for interacting with the mev-commit bidder service.
+	conn   *grpc.ClientConn // Connection behind client; nil for clients built in tests.
+	retry  RetryPolicy
+
+	compress   bool
+	maxPayload int
+}
+
+// Close closes the gRPC connection to the bidder service.
+func (b *Bidder) Close() error {
+	if b.conn == nil {
+		return nil
+	}
+	return b.conn.Close()
+}
+
+// ConnState returns the state of the gRPC connection to the bidder service,
+// such as READY or TRANSIENT_FAILURE.
+func (b *Bidder) ConnState() string {
+	if b.conn == nil {
+		return "UNKNOWN"
+	}
+	return b.conn.GetState().String()
 }
 
 // GethConfig holds configuration settings for a Geth node to connect to the mev-commit chain.
@@ -52,13 +90,27 @@ type AuthAcct struct {
 // NewBidderClient creates a new gRPC client connection to the bidder service and returns a Bidder instance.
 //
 // Parameters:
-// - cfg: The BidderConfig struct containing the server address and logging settings.
+// - cfg: The BidderConfig struct containing the server address, TLS and token settings.
 //
 // Returns:
 // - A pointer to a Bidder struct, or an error if the connection fails.
 func NewBidderClient(cfg BidderConfig) (*Bidder, error) {
+	creds, err := transportCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithConnectParams(grpc.ConnectParams{Backoff: reconnectBackoff, MinConnectTimeout: 5 * time.Second}),
+	}
+	if cfg.Token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(cfg.Token)))
+	}
+	opts = append(opts, ident.DialOptions()...)
+	opts = append(opts, tracing.DialOptions()...)
+
 	// Establish a gRPC connection to the bidder service
-	conn, err := grpc.NewClient(cfg.ServerAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(cfg.ServerAddress, opts...)
 	if err != nil {
 		slog.Error("Failed to connect to gRPC server",
 			"error", err,
@@ -69,7 +121,7 @@ func NewBidderClient(cfg BidderConfig) (*Bidder, error) {
 
 	// Create a new bidder client using the gRPC connection
 	client := pb.NewBidderClient(conn)
-	return &Bidder{client: client}, nil
+	return &Bidder{client: client, conn: conn, retry: cfg.Retry, compress: cfg.Compression, maxPayload: cfg.MaxPayload}, nil
 }
 
 // NewGethClient connects to an Ethereum-compatible chain using the provided RPC endpoint.
@@ -85,7 +137,7 @@ func NewGethClient(endpoint string) (*ethclient.Client, error) {
 	defer cancel()
 
 	// Use DialContext to establish a connection with the 15-second timeout
-	client, err := rpc.DialContext(ctx, endpoint)
+	client, err := ident.Dial(ctx, endpoint)
 	if err != nil {
 		slog.Error("Failed to dial Ethereum RPC endpoint",
 			"error", err,
@@ -126,17 +178,12 @@ func AuthenticateAddress(privateKeyHex string, client *ethclient.Client) (AuthAc
 		return AuthAcct{}, err
 	}
 
-	// Extract the public key from the private key
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		slog.Error("Failed to assert public key type")
-		return AuthAcct{}, fmt.Errorf("failed to assert public key type")
-	}
-
-	// Generate the Ethereum address from the public key
-	address := crypto.PubkeyToAddress(*publicKeyECDSA)
+	return authenticateKey(privateKey, client)
+}
 
+// authenticateKey builds the AuthAcct of privateKey for the chain client is
+// connected to.
+func authenticateKey(privateKey *ecdsa.PrivateKey, client *ethclient.Client) (AuthAcct, error) {
 	// Set up a context with a 15-second timeout for fetching the chain ID
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel() // Ensure the context is canceled after the operation
@@ -148,6 +195,21 @@ func AuthenticateAddress(privateKeyHex string, client *ethclient.Client) (AuthAc
 		)
 		return AuthAcct{}, err
 	}
+	return NewAuthAcct(privateKey, chainID)
+}
+
+// NewAuthAcct builds the AuthAcct of privateKey for the chain with chainID.
+func NewAuthAcct(privateKey *ecdsa.PrivateKey, chainID *big.Int) (AuthAcct, error) {
+	// Extract the public key from the private key
+	publicKey := privateKey.Public()
+	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok {
+		slog.Error("Failed to assert public key type")
+		return AuthAcct{}, fmt.Errorf("failed to assert public key type")
+	}
+
+	// Generate the Ethereum address from the public key
+	address := crypto.PubkeyToAddress(*publicKeyECDSA)
 
 	// Create the transaction options with the private key and chain ID
 	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
@@ -188,7 +250,7 @@ func ConnectRPCClientWithRetries(rpcEndpoint string, maxRetries int, timeout tim
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
 
-		rpcClient, err = ethclient.DialContext(ctx, rpcEndpoint)
+		rpcClient, err = ident.DialEth(ctx, rpcEndpoint)
 		if err == nil {
 			slog.Info("Successfully connected to RPC client",
 				"rpc_endpoint", MaskEndpoint(rpcEndpoint),
@@ -208,7 +270,7 @@ func ConnectRPCClientWithRetries(rpcEndpoint string, maxRetries int, timeout tim
 	slog.Error("Failed to connect to RPC client after maximum retries",
 		"error", err,
 		"rpc_endpoint", MaskEndpoint(rpcEndpoint),
-		"max_retries", maxRetries,
+		"maxRetries", maxRetries,
 	)
 	return nil
 }
@@ -221,6 +283,12 @@ func ConnectRPCClientWithRetries(rpcEndpoint string, maxRetries int, timeout tim
 // Returns:
 // - A pointer to an ethclient.Client if successful, or an error if unable to connect.
 func ConnectWSClient(wsEndpoint string) (*ethclient.Client, error) {
+	return ConnectWSClientContext(context.Background(), wsEndpoint)
+}
+
+// ConnectWSClientContext is like ConnectWSClient, but stops retrying and
+// returns the context's error once ctx is canceled.
+func ConnectWSClientContext(ctx context.Context, wsEndpoint string) (*ethclient.Client, error) {
 	for {
 		wsClient, err := NewGethClient(wsEndpoint)
 		if err == nil {
@@ -228,9 +296,13 @@ func ConnectWSClient(wsEndpoint string) (*ethclient.Client, error) {
 		}
 		slog.Warn("Failed to connect to WebSocket client, retrying in 10 seconds...",
 			"error", err,
-			"ws_endpoint", MaskEndpoint(wsEndpoint),
+			"wsEndpoint", MaskEndpoint(wsEndpoint),
 		)
-		time.Sleep(10 * time.Second)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Second):
+		}
 	}
 }
 
@@ -243,44 +315,57 @@ func ConnectWSClient(wsEndpoint string) (*ethclient.Client, error) {
 // Returns:
 // - A pointer to an ethclient.Client and an ethereum.Subscription if successful, or nil values if all retries fail.
 func ReconnectWSClient(wsEndpoint string, headers chan *types.Header) (*ethclient.Client, ethereum.Subscription) {
+	return ReconnectWSClientContext(context.Background(), wsEndpoint, headers)
+}
+
+// ReconnectWSClientContext is like ReconnectWSClient, but gives up and returns
+// nil values once ctx is canceled.
+func ReconnectWSClientContext(ctx context.Context, wsEndpoint string, headers chan *types.Header) (*ethclient.Client, ethereum.Subscription) {
 	var wsClient *ethclient.Client
 	var sub ethereum.Subscription
 	var err error
 
 	for i := 0; i < 10; i++ { // Retry logic for WebSocket connection
-		wsClient, err = ConnectWSClient(wsEndpoint)
+		wsClient, err = ConnectWSClientContext(ctx, wsEndpoint)
 		if err == nil {
 			slog.Info("WebSocket client reconnected",
-				"ws_endpoint", MaskEndpoint(wsEndpoint),
+				"wsEndpoint", MaskEndpoint(wsEndpoint),
 				"attempt", i+1,
 			)
 
-			// Create a context with a 15-second timeout for the subscription
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-			defer cancel()
-
-			sub, err = wsClient.SubscribeNewHead(ctx, headers)
+			// Bound the subscription by the WS subscribe latency budget
+			subCtx, cancel := latency.Context(ctx, latency.WSSubscribe)
+			sub, err = wsClient.SubscribeNewHead(subCtx, headers)
+			cancel()
 			if err == nil {
 				return wsClient, sub
 			}
+			wsClient.Close()
 
 			slog.Warn("Failed to subscribe to new headers after reconnecting",
 				"error", err,
 			)
 		}
+		if ctx.Err() != nil {
+			return nil, nil
+		}
 
 		slog.Warn("Failed to reconnect WebSocket client, retrying in 5 seconds...",
 			"error", err,
-			"ws_endpoint", MaskEndpoint(wsEndpoint),
+			"wsEndpoint", MaskEndpoint(wsEndpoint),
 			"attempt", i+1,
 		)
-		time.Sleep(5 * time.Second)
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(5 * time.Second):
+		}
 	}
 
 	slog.Error("Failed to reconnect WebSocket client after maximum retries",
 		"error", err,
-		"ws_endpoint", MaskEndpoint(wsEndpoint),
-		"max_retries", 10,
+		"wsEndpoint", MaskEndpoint(wsEndpoint),
+		"maxRetries", 10,
 	)
 	return nil, nil
 }
@@ -298,3 +383,333 @@ func MaskEndpoint(endpoint string) string {
 	}
 	return "*****"
 }
+
+// DefaultWSHealthInterval is how often a WSPool checks its endpoints.
+const DefaultWSHealthInterval = 30 * time.Second
+
+// DefaultWSStallSlots is how many slots the block subscription may go without
+// a header before it is considered stalled.
+const DefaultWSStallSlots = 3
+
+// ParseWSEndpoints splits a comma-separated list of WebSocket endpoints,
+// dropping surrounding whitespace and empty entries. The first endpoint is the
+// primary.
+func ParseWSEndpoints(list string) []string {
+	var endpoints []string
+	for _, endpoint := range strings.Split(list, ",") {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// IsAbnormalClose reports whether err is a WebSocket connection that closed
+// without a close frame (code 1006), as when the node drops the connection.
+func IsAbnormalClose(err error) bool {
+	return errs.Is(err, errs.ErrWSDropped)
+}
+
+// WSPool connects to the first healthy of several WebSocket endpoints, in
+// order of preference, and fails over between them. The first endpoint is the
+// primary; the pool moves back to it once it recovers.
+type WSPool struct {
+	endpoints []string
+	recovered chan struct{}
+
+	mu      sync.Mutex
+	current int
+	healthy []bool
+}
+
+// NewWSPool returns a pool over endpoints, the primary first. All endpoints
+// are assumed healthy until checked.
+func NewWSPool(endpoints []string) *WSPool {
+	healthy := make([]bool, len(endpoints))
+	for i := range healthy {
+		healthy[i] = true
+	}
+	return &WSPool{
+		endpoints: endpoints,
+		recovered: make(chan struct{}, 1),
+		healthy:   healthy,
+	}
+}
+
+// Endpoints returns the pool's endpoints, the primary first.
+func (p *WSPool) Endpoints() []string {
+	return append([]string(nil), p.endpoints...)
+}
+
+// Current returns the endpoint the pool is connected to, or last tried.
+func (p *WSPool) Current() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.endpoints[p.current]
+}
+
+// OnPrimary reports whether the pool is connected to the primary endpoint.
+func (p *WSPool) OnPrimary() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.current == 0
+}
+
+// WSEndpointStatus is the health of one of a WSPool's endpoints.
+type WSEndpointStatus struct {
+	Endpoint string
+	Healthy  bool // Result of the last connection attempt or health check.
+	Current  bool // The pool is connected to this endpoint.
+}
+
+// Status returns the health of every endpoint, the primary first.
+func (p *WSPool) Status() []WSEndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]WSEndpointStatus, len(p.endpoints))
+	for i, endpoint := range p.endpoints {
+		out[i] = WSEndpointStatus{Endpoint: endpoint, Healthy: p.healthy[i], Current: i == p.current}
+	}
+	return out
+}
+
+// Recovered is signaled when the primary endpoint is healthy again while the
+// pool is connected to a fallback.
+func (p *WSPool) Recovered() <-chan struct{} {
+	return p.recovered
+}
+
+// order returns the endpoint indexes to try, starting at start and preferring
+// endpoints that passed their last health check.
+func (p *WSPool) order(start int) []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var healthy, unhealthy []int
+	for i := range p.endpoints {
+		idx := (start + i) % len(p.endpoints)
+		if p.healthy[idx] {
+			healthy = append(healthy, idx)
+		} else {
+			unhealthy = append(unhealthy, idx)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (p *WSPool) setHealth(idx int, healthy bool) (changed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	changed = p.healthy[idx] != healthy
+	p.healthy[idx] = healthy
+	return changed
+}
+
+func (p *WSPool) use(idx int) {
+	p.mu.Lock()
+	previous := p.current
+	p.current = idx
+	p.mu.Unlock()
+	if previous != idx {
+		slog.Warn("WebSocket endpoint switched",
+			"from", MaskEndpoint(p.endpoints[previous]),
+			"to", MaskEndpoint(p.endpoints[idx]),
+			"primary", idx == 0,
+		)
+	}
+}
+
+// connect tries each endpoint once, starting at start, and returns a client
+// subscribed to new headers on the first that works.
+func (p *WSPool) connect(ctx context.Context, start int, headers chan *types.Header) (*ethclient.Client, ethereum.Subscription, error) {
+	if len(p.endpoints) == 0 {
+		return nil, nil, fmt.Errorf("no WebSocket endpoints configured")
+	}
+	var err error
+	for _, idx := range p.order(start) {
+		var client *ethclient.Client
+		var sub ethereum.Subscription
+		client, sub, err = subscribeWS(ctx, p.endpoints[idx], headers)
+		if err == nil {
+			p.setHealth(idx, true)
+			p.use(idx)
+			return client, sub, nil
+		}
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		p.setHealth(idx, false)
+		slog.Warn("WebSocket endpoint unavailable",
+			"error", err,
+			"wsEndpoint", MaskEndpoint(p.endpoints[idx]),
+		)
+	}
+	return nil, nil, err
+}
+
+// ConnectContext connects to the first working endpoint, the primary first,
+// and subscribes to new headers. It retries every 10 seconds until an
+// endpoint works or ctx is canceled.
+func (p *WSPool) ConnectContext(ctx context.Context, headers chan *types.Header) (*ethclient.Client, ethereum.Subscription, error) {
+	for {
+		client, sub, err := p.connect(ctx, 0, headers)
+		if err == nil {
+			return client, sub, nil
+		}
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		slog.Warn("No WebSocket endpoint available, retrying in 10 seconds...",
+			"error", err,
+			"endpoints", len(p.endpoints),
+		)
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+// ReconnectContext replaces a connection that failed with cause. An abnormal
+// close or a stalled subscription fails over to the next endpoint right away;
+// other errors retry the current endpoint first. Like ReconnectWSClientContext it gives up after 10
+// rounds over all endpoints, or once ctx is canceled, and returns nil values.
+func (p *WSPool) ReconnectContext(ctx context.Context, headers chan *types.Header, cause error) (*ethclient.Client, ethereum.Subscription) {
+	p.mu.Lock()
+	start := p.current
+	p.mu.Unlock()
+	// A dropped connection or a stalled node moves on to the next endpoint
+	if (IsAbnormalClose(cause) || errs.Is(cause, errs.ErrWSStalled)) && len(p.endpoints) > 1 {
+		p.setHealth(start, false)
+		start = (start + 1) % len(p.endpoints)
+	}
+
+	var err error
+	for i := 0; i < 10; i++ {
+		var client *ethclient.Client
+		var sub ethereum.Subscription
+		client, sub, err = p.connect(ctx, start, headers)
+		if err == nil {
+			slog.Info("WebSocket client reconnected",
+				"wsEndpoint", MaskEndpoint(p.Current()),
+				"attempt", i+1,
+			)
+			return client, sub
+		}
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+		slog.Warn("Failed to reconnect WebSocket client, retrying in 5 seconds...",
+			"error", err,
+			"attempt", i+1,
+		)
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	slog.Error("Failed to reconnect WebSocket client after maximum retries",
+		"error", err,
+		"endpoints", len(p.endpoints),
+		"maxRetries", 10,
+	)
+	return nil, nil
+}
+
+// ReturnToPrimary connects to the primary endpoint if the pool is on a
+// fallback. It returns nil values when the pool is already on the primary or
+// the primary is still unavailable, in which case the caller keeps its
+// current connection.
+func (p *WSPool) ReturnToPrimary(ctx context.Context, headers chan *types.Header) (*ethclient.Client, ethereum.Subscription) {
+	if p.OnPrimary() {
+		return nil, nil
+	}
+	client, sub, err := subscribeWS(ctx, p.endpoints[0], headers)
+	if err != nil {
+		p.setHealth(0, false)
+		slog.Warn("Primary WebSocket endpoint not ready yet", "error", err)
+		return nil, nil
+	}
+	p.setHealth(0, true)
+	p.use(0)
+	return client, sub
+}
+
+// CheckHealth probes every endpoint with a head block request and records
+// the result. When the pool is on a fallback and the primary is healthy,
+// Recovered is signaled.
+func (p *WSPool) CheckHealth(ctx context.Context) {
+	for idx, endpoint := range p.endpoints {
+		err := probeWS(ctx, endpoint)
+		if ctx.Err() != nil {
+			return
+		}
+		if p.setHealth(idx, err == nil) {
+			if err != nil {
+				slog.Warn("WebSocket endpoint unhealthy", "wsEndpoint", MaskEndpoint(endpoint), "error", err)
+			} else {
+				slog.Info("WebSocket endpoint healthy", "wsEndpoint", MaskEndpoint(endpoint))
+			}
+		}
+		if idx == 0 && err == nil && !p.OnPrimary() {
+			select {
+			case p.recovered <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// Run checks the endpoints' health every interval until ctx is canceled. A
+// pool with a single endpoint has nothing to fail over to and returns
+// immediately.
+func (p *WSPool) Run(ctx context.Context, interval time.Duration) {
+	if len(p.endpoints) < 2 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.CheckHealth(ctx)
+		}
+	}
+}
+
+// subscribeWS dials endpoint and subscribes to new headers, bounded by the
+// WS subscribe latency budget.
+func subscribeWS(ctx context.Context, endpoint string, headers chan *types.Header) (*ethclient.Client, ethereum.Subscription, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	rpcClient, err := ident.Dial(dialCtx, endpoint)
+	cancel()
+	if err != nil {
+		return nil, nil, err
+	}
+	client := ethclient.NewClient(rpcClient)
+	subCtx, cancel := latency.Context(ctx, latency.WSSubscribe)
+	sub, err := client.SubscribeNewHead(subCtx, headers)
+	cancel()
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+	return client, sub, nil
+}
+
+// probeWS dials endpoint and requests the head block number.
+func probeWS(ctx context.Context, endpoint string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	client, err := ident.DialEth(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	_, err = client.BlockNumber(ctx)
+	return err
+}
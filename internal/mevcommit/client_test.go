@@ -0,0 +1,106 @@
+package mevcommit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+type wsEthService struct{}
+
+func (wsEthService) BlockNumber() hexutil.Uint64 { return 1 }
+
+func (wsEthService) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	return notifier.CreateSubscription(), nil
+}
+
+// newWSNode starts a WebSocket node that refuses connections while down is set.
+func newWSNode(t *testing.T, down *atomic.Bool) string {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", wsEthService{}))
+	ws := server.WebsocketHandler([]string{"*"})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		ws.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		srv.Close()
+		server.Stop()
+	})
+	return "ws://" + strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestParseWSEndpoints(t *testing.T) {
+	require.Equal(t, []string{"wss://a", "wss://b"}, ParseWSEndpoints(" wss://a, ,wss://b,"))
+	require.Empty(t, ParseWSEndpoints(""))
+}
+
+func TestWSPoolFailsOverAndReturnsToPrimary(t *testing.T) {
+	var primaryDown, fallbackDown atomic.Bool
+	primaryDown.Store(true)
+	primary := newWSNode(t, &primaryDown)
+	fallback := newWSNode(t, &fallbackDown)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	pool := NewWSPool([]string{primary, fallback})
+	headers := make(chan *types.Header)
+
+	client, sub, err := pool.ConnectContext(ctx, headers)
+	require.NoError(t, err)
+	require.Equal(t, fallback, pool.Current(), "primary is down")
+	require.False(t, pool.OnPrimary())
+
+	// Nothing to return to while the primary is down
+	pool.CheckHealth(ctx)
+	select {
+	case <-pool.Recovered():
+		t.Fatal("primary reported recovered while down")
+	default:
+	}
+
+	primaryDown.Store(false)
+	pool.CheckHealth(ctx)
+	select {
+	case <-pool.Recovered():
+	default:
+		t.Fatal("primary recovery not signaled")
+	}
+	newClient, newSub := pool.ReturnToPrimary(ctx, headers)
+	require.NotNil(t, newSub)
+	sub.Unsubscribe()
+	client.Close()
+	client, sub = newClient, newSub
+	require.True(t, pool.OnPrimary())
+
+	// An abnormal close fails over without retrying the same endpoint
+	client.Close()
+	client, sub = pool.ReconnectContext(ctx, headers, errors.New("websocket: close 1006 (abnormal closure): unexpected EOF"))
+	require.NotNil(t, sub)
+	require.Equal(t, fallback, pool.Current())
+	sub.Unsubscribe()
+	client.Close()
+}
+
+func TestIsAbnormalClose(t *testing.T) {
+	require.True(t, IsAbnormalClose(errors.New("websocket: close 1006 (abnormal closure): unexpected EOF")))
+	require.False(t, IsAbnormalClose(errors.New("websocket: close 1000 (normal)")))
+	require.False(t, IsAbnormalClose(nil))
+}
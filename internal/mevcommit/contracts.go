@@ -12,12 +12,13 @@ import (
 
 	"log/slog"
 
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	ethevent "github.com/ethereum/go-ethereum/event"
+	"github.com/primev/preconf_blob_bidder/internal/contracts/preconfmanager"
 )
 
 // Global contract addresses
@@ -28,100 +29,24 @@ var (
 )
 
 func init() {
-	// Load custom environment file if specified, otherwise default to .env
-	envFile := os.Getenv("ENV_FILE")
-	if envFile == "" {
-		envFile = ".env" // default to .env if ENV_FILE is not set
-	}
-
-	if _, err := os.Stat(envFile); err == nil {
-		if err := loadEnvFile(envFile); err != nil {
-			slog.Error("Error loading .env file",
-				"err", err,
-				"env_file", envFile,
-			)
-			return
-		}
-	}
-
-	// Read environment variables with default values
-	bidderRegistry := os.Getenv("BIDDER_REGISTRY_ADDRESS")
-	if bidderRegistry == "" {
-		bidderRegistry = "0x401B3287364f95694c43ACA3252831cAc02e5C41"
-	}
-	BidderRegistryAddress = common.HexToAddress(bidderRegistry)
-
-	blockTracker := os.Getenv("BLOCK_TRACKER_ADDRESS")
-	if blockTracker == "" {
-		blockTracker = "0x7538F3AaA07dA1990486De21A0B438F55e9639e4"
-	}
-	BlockTrackerAddress = common.HexToAddress(blockTracker)
-
-	preconfManager := os.Getenv("PRECONF_MANAGER_ADDRESS")
-	if preconfManager == "" {
-		preconfManager = "0x9433bCD9e89F923ce587f7FA7E39e120E93eb84D"
-	}
-	PreconfManagerAddress = common.HexToAddress(preconfManager)
-
-	// // Log loaded contract addresses
-	// slog.Info("Loaded contract addresses",
-	// 	"BidderRegistry", BidderRegistryAddress.Hex(),
-	// 	"BlockTracker", BlockTrackerAddress.Hex(),
-	// 	"PreconfManager", PreconfManagerAddress.Hex(),
-	// )
+	// Default to the known addresses of the default network until the
+	// configured network is resolved
+	UseContracts(*Networks[DefaultNetwork].Fallback)
 }
 
-// loadEnvFile loads environment variables from a specified file.
-func loadEnvFile(filePath string) error {
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		slog.Error("Failed to read environment file",
-			"err", err,
-			"file_path", filePath,
-		)
-		return err
-	}
-
-	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-		os.Setenv(key, value)
-	}
-
-	// slog.Info("Environment variables loaded from file",
-	// 	"file_path", filePath,
-	// )
+const defaultTimeout = 15 * time.Second
 
-	return nil
-}
+// CommitmentStoredEvent is a CommitmentStored event of the PreconfManager
+// contract, decoded with its indexed commitment index.
+type CommitmentStoredEvent = preconfmanager.PreconfManagerCommitmentStored
 
-const defaultTimeout = 15 * time.Second
+// OpenedCommitmentStoredEvent is an OpenedCommitmentStored event of the
+// PreconfManager contract.
+type OpenedCommitmentStoredEvent = preconfmanager.PreconfManagerOpenedCommitmentStored
 
-// CommitmentStoredEvent represents the data structure for the CommitmentStored event.
-type CommitmentStoredEvent struct {
-	CommitmentIndex     [32]byte
-	Bidder              common.Address
-	Commiter            common.Address
-	Bid                 uint64
-	BlockNumber         uint64
-	BidHash             [32]byte
-	DecayStartTimeStamp uint64
-	DecayEndTimeStamp   uint64
-	TxnHash             string
-	CommitmentHash      [32]byte
-	BidSignature        []byte
-	CommitmentSignature []byte
-	DispatchTimestamp   uint64
-	SharedSecretKey     []byte
-}
+// CommitmentProcessedEvent is a CommitmentProcessed event of the
+// PreconfManager contract.
+type CommitmentProcessedEvent = preconfmanager.PreconfManagerCommitmentProcessed
 
 // LoadABI loads the ABI from the specified file path and parses it.
 //
@@ -134,7 +59,7 @@ func LoadABI(filePath string) (abi.ABI, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		slog.Error("Failed to load ABI file",
-			"err", err,
+			"error", err,
 			"file_path", filePath,
 		)
 		return abi.ABI{}, err
@@ -143,7 +68,7 @@ func LoadABI(filePath string) (abi.ABI, error) {
 	parsedABI, err := abi.JSON(strings.NewReader(string(data)))
 	if err != nil {
 		slog.Error("Failed to parse ABI file",
-			"err", err,
+			"error", err,
 			"file_path", filePath,
 		)
 		return abi.ABI{}, err
@@ -178,7 +103,7 @@ func WindowHeight(client *ethclient.Client) (*big.Int, error) {
 	err = blockTrackerContract.Call(nil, &currentWindowResult, "getCurrentWindow")
 	if err != nil {
 		slog.Error("Failed to get current window",
-			"err", err,
+			"error", err,
 			"function", "getCurrentWindow",
 		)
 		return nil, fmt.Errorf("failed to get current window: %v", err)
@@ -220,7 +145,7 @@ func GetMinDeposit(client *ethclient.Client) (*big.Int, error) {
 	err = bidderRegistryContract.Call(nil, &minDepositResult, "minDeposit")
 	if err != nil {
 		slog.Error("Failed to call minDeposit function",
-			"err", err,
+			"error", err,
 			"function", "minDeposit",
 		)
 		return nil, fmt.Errorf("failed to call minDeposit function: %v", err)
@@ -272,7 +197,7 @@ func DepositIntoWindow(client *ethclient.Client, depositWindow *big.Int, authAcc
 	tx, err := bidderRegistryContract.Transact(authAcct.Auth, "depositForSpecificWindow", depositWindow)
 	if err != nil {
 		slog.Error("Failed to create deposit transaction",
-			"err", err,
+			"error", err,
 			"function", "depositForSpecificWindow",
 		)
 		return nil, fmt.Errorf("failed to create transaction: %v", err)
@@ -289,7 +214,7 @@ func DepositIntoWindow(client *ethclient.Client, depositWindow *big.Int, authAcc
 	receipt, err := bind.WaitMined(ctx, client, tx)
 	if err != nil {
 		slog.Error("Transaction mining error",
-			"err", err,
+			"error", err,
 			"tx_hash", tx.Hash().Hex(),
 		)
 		return nil, fmt.Errorf("transaction mining error: %v", err)
@@ -333,7 +258,7 @@ func GetDepositAmount(client *ethclient.Client, address common.Address, window b
 	err = bidderRegistryContract.Call(nil, &depositResult, "getDeposit", address, window)
 	if err != nil {
 		slog.Error("Failed to call getDeposit function",
-			"err", err,
+			"error", err,
 			"function", "getDeposit",
 		)
 		return nil, fmt.Errorf("failed to call getDeposit function: %v", err)
@@ -376,7 +301,7 @@ func WithdrawFromWindow(client *ethclient.Client, authAcct *AuthAcct, window *bi
 	withdrawalTx, err := bidderRegistryContract.Transact(authAcct.Auth, "withdrawBidderAmountFromWindow", authAcct.Address, window)
 	if err != nil {
 		slog.Error("Failed to create withdrawal transaction",
-			"err", err,
+			"error", err,
 			"function", "withdrawBidderAmountFromWindow",
 		)
 		return nil, fmt.Errorf("failed to create withdrawal transaction: %v", err)
@@ -393,7 +318,7 @@ func WithdrawFromWindow(client *ethclient.Client, authAcct *AuthAcct, window *bi
 	withdrawalReceipt, err := bind.WaitMined(ctx, client, withdrawalTx)
 	if err != nil {
 		slog.Error("Withdrawal transaction mining error",
-			"err", err,
+			"error", err,
 			"tx_hash", withdrawalTx.Hash().Hex(),
 		)
 		return nil, fmt.Errorf("withdrawal transaction mining error: %v", err)
@@ -418,112 +343,122 @@ func WithdrawFromWindow(client *ethclient.Client, authAcct *AuthAcct, window *bi
 //
 // Parameters:
 // - client: The Ethereum client instance.
-//
-// Note: The event listener uses a timeout of 15 seconds for subscription.
 func ListenForCommitmentStoredEvent(client *ethclient.Client) {
-	// Load the PreConfCommitmentStore contract ABI
-	contractAbi, err := LoadABI("abi/PreConfCommitmentStore.abi")
+	err := ListenForCommitmentStoredEventContext(context.Background(), client, func(event CommitmentStoredEvent) {
+		// Log event details
+		slog.Info("CommitmentStored Event Detected",
+			"commitment_index", fmt.Sprintf("%x", event.CommitmentIndex),
+			"bidder", event.Bidder.Hex(),
+			"commiter", event.Commiter.Hex(),
+			"bid", event.Bid,
+			"block_number", event.BlockNumber,
+			"bid_hash", fmt.Sprintf("%x", event.BidHash),
+			"decay_start_timestamp", event.DecayStartTimeStamp,
+			"decay_end_timestamp", event.DecayEndTimeStamp,
+			"txn_hash", event.TxnHash,
+			"commitment_hash", fmt.Sprintf("%x", event.CommitmentHash),
+			"bid_signature", fmt.Sprintf("%x", event.BidSignature),
+			"commitment_signature", fmt.Sprintf("%x", event.CommitmentSignature),
+			"dispatch_timestamp", event.DispatchTimestamp,
+			"shared_secret_key", fmt.Sprintf("%x", event.SharedSecretKey),
+		)
+	})
 	if err != nil {
-		slog.Error("Failed to load contract ABI",
-			"contract", "PreConfCommitmentStore",
-			"err", err,
+		slog.Error("Error with CommitmentStored subscription",
+			"error", err,
 		)
-		return
 	}
+}
+
+// ListenForCommitmentStoredEventContext calls handle for every CommitmentStored
+// event of the PreconfManager contract until ctx is canceled or the
+// subscription fails. It returns nil when ctx is canceled.
+func ListenForCommitmentStoredEventContext(ctx context.Context, client bind.ContractFilterer, handle func(CommitmentStoredEvent)) error {
+	return watchPreconfManager(ctx, client, "CommitmentStored", (*preconfmanager.PreconfManagerFilterer).WatchCommitmentStored, func(event *CommitmentStoredEvent) {
+		handle(*event)
+	})
+}
+
+// ListenForOpenedCommitmentsContext calls handle for every
+// OpenedCommitmentStored event of the PreconfManager contract, emitted once a
+// commitment is opened after its L1 block, until ctx is canceled or the
+// subscription fails. It returns nil when ctx is canceled.
+func ListenForOpenedCommitmentsContext(ctx context.Context, client bind.ContractFilterer, handle func(*OpenedCommitmentStoredEvent)) error {
+	return watchPreconfManager(ctx, client, "OpenedCommitmentStored", (*preconfmanager.PreconfManagerFilterer).WatchOpenedCommitmentStored, handle)
+}
 
-	// Create a parent context that can be canceled to stop all operations
-	parentCtx, parentCancel := context.WithCancel(context.Background())
-	defer parentCancel()
+// ListenForProcessedCommitmentsContext calls handle for every
+// CommitmentProcessed event of the PreconfManager contract, emitted once the
+// oracle rewarded or slashed the provider of a commitment, until ctx is
+// canceled or the subscription fails. It returns nil when ctx is canceled.
+func ListenForProcessedCommitmentsContext(ctx context.Context, client bind.ContractFilterer, handle func(*CommitmentProcessedEvent)) error {
+	return watchPreconfManager(ctx, client, "CommitmentProcessed", (*preconfmanager.PreconfManagerFilterer).WatchCommitmentProcessed, handle)
+}
 
-	// Subscribe to new block headers
-	headers := make(chan *types.Header)
-	sub, err := client.SubscribeNewHead(parentCtx, headers)
+// watchPreconfManager subscribes to event at the PreconfManager contract
+// through the binding's watch method and calls handle with each event. A log
+// that fails to decode ends the subscription with its error.
+func watchPreconfManager[T any](ctx context.Context, client bind.ContractFilterer, event string, watch func(*preconfmanager.PreconfManagerFilterer, *bind.WatchOpts, chan<- *T, [][32]byte) (ethevent.Subscription, error), handle func(*T)) error {
+	filterer, err := preconfmanager.NewPreconfManagerFilterer(PreconfManagerAddress, client)
 	if err != nil {
-		slog.Error("Failed to subscribe to new block headers",
-			"err", err,
-		)
-		return
+		return fmt.Errorf("failed to bind PreconfManager: %w", err)
+	}
+	events := make(chan *T)
+	sub, err := watch(filterer, &bind.WatchOpts{Context: ctx}, events, nil)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s events: %w", event, err)
 	}
+	defer sub.Unsubscribe()
 
-	slog.Info("Subscribed to new block headers for CommitmentStored events")
+	slog.Info("Subscribed to PreconfManager events", "event", event)
 
-	// Listen for new block headers and filter logs for the CommitmentStored event
 	for {
 		select {
+		case <-ctx.Done():
+			return nil
 		case err := <-sub.Err():
-			slog.Error("Error with header subscription",
-				"err", err,
-			)
-			// Cancel the parent context to terminate all ongoing log subscriptions
-			parentCancel()
-			return
-
-		case header := <-headers:
-			query := ethereum.FilterQuery{
-				Addresses: []common.Address{PreconfManagerAddress},
-				FromBlock: header.Number,
-				ToBlock:   header.Number,
-			}
-
-			logs := make(chan types.Log)
-			ctxLogs, cancelLogs := context.WithTimeout(parentCtx, defaultTimeout)
-
-			// Subscribe to filter logs with the derived context
-			subLogs, err := client.SubscribeFilterLogs(ctxLogs, query, logs)
-			if err != nil {
-				slog.Error("Failed to subscribe to logs",
-					"err", err,
-				)
-				// Ensure cancelLogs is called to release resources
-				cancelLogs()
-				continue
-			}
-
-			// Process incoming logs in a separate goroutine
-			go func() {
-				// Ensure cancelLogs is called when the goroutine exits
-				defer cancelLogs()
-
-				for {
-					select {
-					case err := <-subLogs.Err():
-						slog.Error("Error with log subscription",
-							"err", err,
-						)
-						return
-
-					case vLog := <-logs:
-						var event CommitmentStoredEvent
-
-						// Unpack the log data into the CommitmentStoredEvent struct
-						err := contractAbi.UnpackIntoInterface(&event, "CommitmentStored", vLog.Data)
-						if err != nil {
-							slog.Error("Failed to unpack log data",
-								"err", err,
-							)
-							continue
-						}
-
-						// Log event details
-						slog.Info("CommitmentStored Event Detected",
-							"commitment_index", fmt.Sprintf("%x", event.CommitmentIndex),
-							"bidder", event.Bidder.Hex(),
-							"commiter", event.Commiter.Hex(),
-							"bid", event.Bid,
-							"block_number", event.BlockNumber,
-							"bid_hash", fmt.Sprintf("%x", event.BidHash),
-							"decay_start_timestamp", event.DecayStartTimeStamp,
-							"decay_end_timestamp", event.DecayEndTimeStamp,
-							"txn_hash", event.TxnHash,
-							"commitment_hash", fmt.Sprintf("%x", event.CommitmentHash),
-							"bid_signature", fmt.Sprintf("%x", event.BidSignature),
-							"commitment_signature", fmt.Sprintf("%x", event.CommitmentSignature),
-							"dispatch_timestamp", event.DispatchTimestamp,
-							"shared_secret_key", fmt.Sprintf("%x", event.SharedSecretKey),
-						)
-					}
-				}
-			}()
+			return err
+		case ev := <-events:
+			handle(ev)
 		}
 	}
 }
+
+// FilterOpenedCommitments returns the OpenedCommitmentStored events of the
+// PreconfManager contract emitted in blocks from to to, inclusive.
+func FilterOpenedCommitments(ctx context.Context, client bind.ContractFilterer, from, to uint64) ([]*OpenedCommitmentStoredEvent, error) {
+	filterer, err := preconfmanager.NewPreconfManagerFilterer(PreconfManagerAddress, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind PreconfManager: %w", err)
+	}
+	it, err := filterer.FilterOpenedCommitmentStored(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter OpenedCommitmentStored events: %w", err)
+	}
+	defer it.Close()
+	var events []*OpenedCommitmentStoredEvent
+	for it.Next() {
+		events = append(events, it.Event)
+	}
+	return events, it.Error()
+}
+
+// FilterProcessedCommitments returns the CommitmentProcessed events of the
+// PreconfManager contract emitted in blocks from to to, inclusive, each
+// emitted once the oracle rewarded or slashed the provider of a commitment.
+func FilterProcessedCommitments(ctx context.Context, client bind.ContractFilterer, from, to uint64) ([]*CommitmentProcessedEvent, error) {
+	filterer, err := preconfmanager.NewPreconfManagerFilterer(PreconfManagerAddress, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind PreconfManager: %w", err)
+	}
+	it, err := filterer.FilterCommitmentProcessed(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter CommitmentProcessed events: %w", err)
+	}
+	defer it.Close()
+	var events []*CommitmentProcessedEvent
+	for it.Next() {
+		events = append(events, it.Event)
+	}
+	return events, it.Error()
+}
@@ -0,0 +1,199 @@
+package mevcommit
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/primev/preconf_blob_bidder/internal/contracts/preconfmanager"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCommitmentStored(t *testing.T) {
+	contractAbi, err := preconfmanager.PreconfManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	filterer, err := preconfmanager.NewPreconfManagerFilterer(PreconfManagerAddress, nil)
+	require.NoError(t, err)
+
+	event := contractAbi.Events["CommitmentStored"]
+	data, err := event.Inputs.NonIndexed().Pack(
+		common.HexToAddress("0x01"), common.HexToAddress("0x02"),
+		uint64(1000), uint64(42), [32]byte{1}, uint64(10), uint64(20),
+		"ab01", [32]byte{2}, []byte{3}, []byte{4}, uint64(15), []byte{},
+	)
+	require.NoError(t, err)
+
+	index := common.Hash{9}
+	ev, err := filterer.ParseCommitmentStored(types.Log{Topics: []common.Hash{event.ID, index}, Data: data})
+	require.NoError(t, err)
+	require.Equal(t, "ab01", ev.TxnHash)
+	require.Equal(t, common.HexToAddress("0x02"), ev.Commiter)
+	require.Equal(t, uint64(42), ev.BlockNumber)
+	require.Equal(t, [32]byte(index), ev.CommitmentIndex)
+}
+
+func TestDecodeOpenedCommitmentStored(t *testing.T) {
+	contractAbi, err := preconfmanager.PreconfManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	filterer, err := preconfmanager.NewPreconfManagerFilterer(PreconfManagerAddress, nil)
+	require.NoError(t, err)
+
+	event := contractAbi.Events["OpenedCommitmentStored"]
+	data, err := event.Inputs.NonIndexed().Pack(
+		common.HexToAddress("0x01"), common.HexToAddress("0x02"),
+		big.NewInt(1000), uint64(42), [32]byte{1}, uint64(10), uint64(20),
+		"ab01", "", [32]byte{2}, []byte{3}, []byte{4}, uint64(15), []byte{},
+	)
+	require.NoError(t, err)
+
+	index := common.Hash{9}
+	ev, err := filterer.ParseOpenedCommitmentStored(types.Log{Topics: []common.Hash{event.ID, index}, Data: data})
+	require.NoError(t, err)
+	require.Equal(t, "ab01", ev.TxnHash)
+	require.Equal(t, big.NewInt(1000), ev.BidAmt)
+	require.Equal(t, [32]byte(index), ev.CommitmentIndex)
+
+	_, err = filterer.ParseCommitmentProcessed(types.Log{Topics: []common.Hash{event.ID, index}, Data: data})
+	require.Error(t, err, "a log of another event is not decoded")
+}
+
+// logFilterer returns logs for any query, or streams them to a subscription,
+// and records the last query.
+type logFilterer struct {
+	logs  []types.Log
+	query ethereum.FilterQuery
+}
+
+func (f *logFilterer) FilterLogs(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	f.query = query
+	return f.logs, nil
+}
+
+func (f *logFilterer) SubscribeFilterLogs(_ context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	f.query = query
+	logs := f.logs
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for _, l := range logs {
+			select {
+			case ch <- l:
+			case <-quit:
+				return nil
+			}
+		}
+		<-quit
+		return nil
+	}), nil
+}
+
+// processedLog returns a CommitmentProcessed log of the commitment index.
+func processedLog(t *testing.T, index common.Hash, slashed bool, block uint64) types.Log {
+	t.Helper()
+	contractAbi, err := preconfmanager.PreconfManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	event := contractAbi.Events["CommitmentProcessed"]
+	data, err := event.Inputs.NonIndexed().Pack(slashed)
+	require.NoError(t, err)
+	return types.Log{Address: PreconfManagerAddress, Topics: []common.Hash{event.ID, index}, Data: data, BlockNumber: block}
+}
+
+func TestFilterProcessedCommitments(t *testing.T) {
+	f := &logFilterer{logs: []types.Log{
+		processedLog(t, common.Hash{1}, true, 12),
+		processedLog(t, common.Hash{2}, false, 14),
+	}}
+	events, err := FilterProcessedCommitments(context.Background(), f, 10, 20)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, [32]byte(common.Hash{1}), events[0].CommitmentIndex)
+	require.True(t, events[0].IsSlash)
+	require.Equal(t, [32]byte(common.Hash{2}), events[1].CommitmentIndex)
+	require.False(t, events[1].IsSlash)
+	require.Equal(t, uint64(14), events[1].Raw.BlockNumber)
+
+	require.Equal(t, big.NewInt(10), f.query.FromBlock)
+	require.Equal(t, big.NewInt(20), f.query.ToBlock, "the range is inclusive")
+	require.Equal(t, []common.Address{PreconfManagerAddress}, f.query.Addresses)
+	require.Equal(t, processedLog(t, common.Hash{}, false, 0).Topics[0], f.query.Topics[0][0])
+
+	f.logs = nil
+	events, err = FilterProcessedCommitments(context.Background(), f, 10, 20)
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	// A log of another event fails the iterator
+	bad := processedLog(t, common.Hash{3}, true, 15)
+	bad.Topics[0] = common.Hash{0xff}
+	f.logs = []types.Log{processedLog(t, common.Hash{1}, true, 12), bad}
+	_, err = FilterProcessedCommitments(context.Background(), f, 10, 20)
+	require.Error(t, err)
+}
+
+func TestListenForProcessedCommitments(t *testing.T) {
+	f := &logFilterer{logs: []types.Log{
+		processedLog(t, common.Hash{1}, true, 12),
+		processedLog(t, common.Hash{2}, false, 14),
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got []*CommitmentProcessedEvent
+	err := ListenForProcessedCommitmentsContext(ctx, f, func(ev *CommitmentProcessedEvent) {
+		got = append(got, ev)
+		if len(got) == 2 {
+			cancel()
+		}
+	})
+	require.NoError(t, err, "canceling ends the subscription without an error")
+	require.Len(t, got, 2)
+	require.Equal(t, [32]byte(common.Hash{1}), got[0].CommitmentIndex)
+	require.True(t, got[0].IsSlash)
+	require.False(t, got[1].IsSlash)
+	require.Equal(t, []common.Address{PreconfManagerAddress}, f.query.Addresses)
+	require.Equal(t, processedLog(t, common.Hash{}, false, 0).Topics[0], f.query.Topics[0][0])
+
+	// A log of another event ends the subscription with an error
+	bad := processedLog(t, common.Hash{3}, true, 15)
+	bad.Topics[0] = common.Hash{0xff}
+	f.logs = []types.Log{bad}
+	err = ListenForProcessedCommitmentsContext(context.Background(), f, func(*CommitmentProcessedEvent) {
+		t.Fatal("an undecodable log is not handled")
+	})
+	require.Error(t, err)
+}
+
+// openedLog returns an OpenedCommitmentStored log of the commitment index.
+func openedLog(t *testing.T, index common.Hash, txnHash string, block uint64) types.Log {
+	t.Helper()
+	contractAbi, err := preconfmanager.PreconfManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	event := contractAbi.Events["OpenedCommitmentStored"]
+	data, err := event.Inputs.NonIndexed().Pack(
+		common.HexToAddress("0x01"), common.HexToAddress("0x02"),
+		big.NewInt(1000), uint64(42), [32]byte{1}, uint64(10), uint64(20),
+		txnHash, "", [32]byte{2}, []byte{3}, []byte{4}, uint64(15), []byte{},
+	)
+	require.NoError(t, err)
+	return types.Log{Address: PreconfManagerAddress, Topics: []common.Hash{event.ID, index}, Data: data, BlockNumber: block}
+}
+
+func TestOpenedCommitments(t *testing.T) {
+	f := &logFilterer{logs: []types.Log{openedLog(t, common.Hash{1}, "ab01", 12)}}
+	events, err := FilterOpenedCommitments(context.Background(), f, 10, 20)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "ab01", events[0].TxnHash)
+	require.Equal(t, [32]byte(common.Hash{1}), events[0].CommitmentIndex)
+	require.Equal(t, big.NewInt(20), f.query.ToBlock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = ListenForOpenedCommitmentsContext(ctx, f, func(ev *OpenedCommitmentStoredEvent) {
+		require.Equal(t, big.NewInt(1000), ev.BidAmt)
+		cancel()
+	})
+	require.NoError(t, err)
+	require.Equal(t, openedLog(t, common.Hash{}, "", 0).Topics[0], f.query.Topics[0][0])
+}
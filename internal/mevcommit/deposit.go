@@ -0,0 +1,196 @@
+package mevcommit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"sync"
+
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	// DefaultBlocksPerWindow is the number of L1 blocks in a mev-commit bidding window.
+	DefaultBlocksPerWindow = 10
+	// DefaultSettlementLag is the number of windows after which a window's
+	// unused deposit becomes withdrawable.
+	DefaultSettlementLag = 2
+)
+
+// DepositConfig holds the settings for the deposit manager.
+type DepositConfig struct {
+	AmountPerWindow *big.Int // Deposit to keep in every window the bidder bids into, in wei.
+	BlocksPerWindow uint64   // Number of L1 blocks per bidding window.
+	SettlementLag   uint64   // Windows to wait before a funded window can be withdrawn from.
+
+	// Notify, if set, is called after every successful deposit and withdrawal.
+	Notify func(DepositEvent)
+}
+
+// DepositEvent describes funds moved into or out of a bidding window.
+type DepositEvent struct {
+	Withdrawal bool
+	Window     uint64
+	Amount     *big.Int
+}
+
+func (m *DepositManager) notify(withdrawal bool, window uint64, amount *big.Int) {
+	if m.cfg.Notify != nil {
+		m.cfg.Notify(DepositEvent{Withdrawal: withdrawal, Window: window, Amount: amount})
+	}
+}
+
+// DepositManager keeps the window being bid into funded through the bidder
+// node and rolls the refundable balance of closed windows into the next window,
+// so funds never leave the mev-commit chain between windows.
+type DepositManager struct {
+	client pb.BidderClient
+	cfg    DepositConfig
+
+	mu         sync.Mutex
+	lastWindow uint64
+	funded     map[uint64]bool // Windows deposited into by this manager and not yet withdrawn.
+}
+
+// NewDepositManager creates a deposit manager that operates through the bidder node.
+func NewDepositManager(b *Bidder, cfg DepositConfig) *DepositManager {
+	if cfg.BlocksPerWindow == 0 {
+		cfg.BlocksPerWindow = DefaultBlocksPerWindow
+	}
+	if cfg.SettlementLag == 0 {
+		cfg.SettlementLag = DefaultSettlementLag
+	}
+	return &DepositManager{
+		client: b.client,
+		cfg:    cfg,
+		funded: make(map[uint64]bool),
+	}
+}
+
+// WindowForBlock returns the bidding window an L1 block number belongs to.
+func WindowForBlock(blockNumber, blocksPerWindow uint64) uint64 {
+	if blockNumber == 0 || blocksPerWindow == 0 {
+		return 0
+	}
+	return (blockNumber-1)/blocksPerWindow + 1
+}
+
+// OnTargetBlock makes sure the window containing blockNumber is funded. When a
+// new window is entered, the unused deposit of settled windows is withdrawn and
+// rolled into the new window before topping up from the node's wallet.
+func (m *DepositManager) OnTargetBlock(ctx context.Context, blockNumber uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	window := WindowForBlock(blockNumber, m.cfg.BlocksPerWindow)
+	if window == 0 || window == m.lastWindow {
+		return nil
+	}
+
+	refunded, err := m.withdrawSettled(ctx, window)
+	if err != nil {
+		slog.Warn("Failed to withdraw settled windows, continuing with wallet funds",
+			"error", err,
+			"window", window,
+		)
+	}
+
+	existing, err := depositOf(ctx, m.client, window)
+	if err != nil {
+		return fmt.Errorf("failed to get deposit for window %d: %w", window, err)
+	}
+
+	need := new(big.Int).Sub(m.cfg.AmountPerWindow, existing)
+	if need.Sign() <= 0 {
+		slog.Info("Window already funded",
+			"window", window,
+			"deposit", existing.String(),
+			"refunded", refunded.String(),
+		)
+		m.lastWindow = window
+		return nil
+	}
+
+	_, err = m.client.Deposit(ctx, &pb.DepositRequest{
+		Amount:       need.String(),
+		WindowNumber: wrapperspb.UInt64(window),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to deposit into window %d: %w", window, err)
+	}
+	m.funded[window] = true
+	m.lastWindow = window
+	m.notify(false, window, need)
+
+	fromWallet := new(big.Int).Sub(need, refunded)
+	if fromWallet.Sign() < 0 {
+		fromWallet.SetInt64(0)
+	}
+	slog.Info("Rolled deposit into new window",
+		"window", window,
+		"deposited", need.String(),
+		"refunded", refunded.String(),
+		"fromWallet", fromWallet.String(),
+	)
+	return nil
+}
+
+// withdrawSettled withdraws every funded window that has settled relative to
+// the current window and returns the total amount refunded.
+func (m *DepositManager) withdrawSettled(ctx context.Context, current uint64) (*big.Int, error) {
+	total := new(big.Int)
+
+	var settled []uint64
+	for w := range m.funded {
+		if w+m.cfg.SettlementLag <= current {
+			settled = append(settled, w)
+		}
+	}
+	if len(settled) == 0 {
+		return total, nil
+	}
+	sort.Slice(settled, func(i, j int) bool { return settled[i] < settled[j] })
+
+	windows := make([]*wrapperspb.UInt64Value, len(settled))
+	for i, w := range settled {
+		windows[i] = wrapperspb.UInt64(w)
+	}
+
+	resp, err := m.client.WithdrawFromWindows(ctx, &pb.WithdrawFromWindowsRequest{WindowNumbers: windows})
+	if err != nil {
+		return total, err
+	}
+
+	for _, r := range resp.GetWithdrawResponses() {
+		amount, ok := new(big.Int).SetString(r.GetAmount(), 10)
+		if ok {
+			total.Add(total, amount)
+			m.notify(true, r.GetWindowNumber().GetValue(), amount)
+		}
+	}
+	for _, w := range settled {
+		delete(m.funded, w)
+	}
+	return total, nil
+}
+
+// depositOf returns the current deposit of the bidder in a window.
+func depositOf(ctx context.Context, client pb.BidderClient, window uint64) (*big.Int, error) {
+	resp, err := client.GetDeposit(ctx, &pb.GetDepositRequest{WindowNumber: wrapperspb.UInt64(window)})
+	if err != nil {
+		return nil, err
+	}
+	amount, ok := new(big.Int).SetString(resp.GetAmount(), 10)
+	if !ok {
+		return new(big.Int), nil
+	}
+	return amount, nil
+}
+
+// WindowDeposit returns the bidder's current deposit in a bidding window.
+func (b *Bidder) WindowDeposit(ctx context.Context, window uint64) (*big.Int, error) {
+	return depositOf(ctx, b.client, window)
+}
@@ -0,0 +1,102 @@
+package mevcommit
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// fakeBidderClient records deposit related calls made through the bidder API.
+type fakeBidderClient struct {
+	pb.BidderClient
+
+	deposits  map[uint64]*big.Int
+	withdrawn [][]uint64
+}
+
+func newFakeBidderClient() *fakeBidderClient {
+	return &fakeBidderClient{deposits: make(map[uint64]*big.Int)}
+}
+
+func (f *fakeBidderClient) Deposit(_ context.Context, in *pb.DepositRequest, _ ...grpc.CallOption) (*pb.DepositResponse, error) {
+	amount, _ := new(big.Int).SetString(in.GetAmount(), 10)
+	w := in.GetWindowNumber().GetValue()
+	if f.deposits[w] == nil {
+		f.deposits[w] = new(big.Int)
+	}
+	f.deposits[w].Add(f.deposits[w], amount)
+	return &pb.DepositResponse{Amount: in.GetAmount(), WindowNumber: in.GetWindowNumber()}, nil
+}
+
+func (f *fakeBidderClient) GetDeposit(_ context.Context, in *pb.GetDepositRequest, _ ...grpc.CallOption) (*pb.DepositResponse, error) {
+	amount := f.deposits[in.GetWindowNumber().GetValue()]
+	if amount == nil {
+		amount = new(big.Int)
+	}
+	return &pb.DepositResponse{Amount: amount.String(), WindowNumber: in.GetWindowNumber()}, nil
+}
+
+func (f *fakeBidderClient) WithdrawFromWindows(_ context.Context, in *pb.WithdrawFromWindowsRequest, _ ...grpc.CallOption) (*pb.WithdrawFromWindowsResponse, error) {
+	var windows []uint64
+	resp := &pb.WithdrawFromWindowsResponse{}
+	for _, w := range in.GetWindowNumbers() {
+		windows = append(windows, w.GetValue())
+		amount := f.deposits[w.GetValue()]
+		delete(f.deposits, w.GetValue())
+		if amount == nil {
+			continue
+		}
+		resp.WithdrawResponses = append(resp.WithdrawResponses, &pb.WithdrawResponse{
+			Amount:       amount.String(),
+			WindowNumber: wrapperspb.UInt64(w.GetValue()),
+		})
+	}
+	f.withdrawn = append(f.withdrawn, windows)
+	return resp, nil
+}
+
+func TestWindowForBlock(t *testing.T) {
+	require.Equal(t, uint64(1), WindowForBlock(1, 10))
+	require.Equal(t, uint64(1), WindowForBlock(10, 10))
+	require.Equal(t, uint64(2), WindowForBlock(11, 10))
+	require.Equal(t, uint64(0), WindowForBlock(0, 10))
+}
+
+func TestDepositManagerRollsOverSettledWindows(t *testing.T) {
+	fake := newFakeBidderClient()
+	m := NewDepositManager(&Bidder{client: fake}, DepositConfig{
+		AmountPerWindow: big.NewInt(100),
+		BlocksPerWindow: 10,
+		SettlementLag:   2,
+	})
+	ctx := context.Background()
+
+	// Window 1 gets funded once, repeated blocks in the same window are no-ops.
+	require.NoError(t, m.OnTargetBlock(ctx, 5))
+	require.NoError(t, m.OnTargetBlock(ctx, 6))
+	require.Equal(t, "100", fake.deposits[1].String())
+
+	// Window 2 is funded, window 1 has not settled yet.
+	require.NoError(t, m.OnTargetBlock(ctx, 15))
+	require.Empty(t, fake.withdrawn)
+
+	// Window 3 settles window 1 and rolls it over.
+	require.NoError(t, m.OnTargetBlock(ctx, 25))
+	require.Equal(t, [][]uint64{{1}}, fake.withdrawn)
+	require.Nil(t, fake.deposits[1])
+	require.Equal(t, "100", fake.deposits[3].String())
+}
+
+func TestDepositManagerTopsUpPartialDeposit(t *testing.T) {
+	fake := newFakeBidderClient()
+	fake.deposits[1] = big.NewInt(40)
+	m := NewDepositManager(&Bidder{client: fake}, DepositConfig{AmountPerWindow: big.NewInt(100)})
+
+	require.NoError(t, m.OnTargetBlock(context.Background(), 1))
+	require.Equal(t, "100", fake.deposits[1].String())
+}
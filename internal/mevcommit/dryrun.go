@@ -0,0 +1,89 @@
+package mevcommit
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"github.com/primev/preconf_blob_bidder/internal/clock"
+	"github.com/primev/preconf_blob_bidder/internal/money"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrDryRun is the error of bids built but not sent in dry-run mode.
+var ErrDryRun = errors.New("dry run, bid not sent")
+
+// DryRunBidReport builds the bid SendPreconfBidReport would send and logs it,
+// serialized as the bidder node would receive it, instead of sending it. Raw
+// transactions in the logged bid are replaced by RedactedPayload unless raw
+// payloads are retained. The report carries the amount and decay window with
+// ErrDryRun, or the reason the bid could not have been sent. Bids are checked
+// against the payload limit of bidderClient when it is a *Bidder.
+func DryRunBidReport(bidderClient BidderInterface, input interface{}, blockNumber int64, randomEthAmount float64, decay time.Duration) BidReport {
+	currentTime := clock.Now().UnixMilli()
+	decayStart, decayEnd := currentTime, currentTime+decay.Milliseconds()
+	report := BidReport{Amount: EthToWei(randomEthAmount).String(), DecayStart: decayStart, DecayEnd: decayEnd}
+	if decayEnd <= decayStart {
+		report.Err = fmt.Errorf("%w: decay window of %s", ErrDecayOutOfBounds, decay)
+		return report
+	}
+
+	var b Bidder
+	if bidder, ok := bidderClient.(*Bidder); ok {
+		b.maxPayload = bidder.maxPayload
+	}
+	var txHashes, rawTransactions []string
+	var err error
+	switch v := input.(type) {
+	case string:
+		txHashes, rawTransactions, err = b.parseInput([]string{v})
+	case *types.Transaction:
+		if v == nil {
+			err = fmt.Errorf("transaction is nil")
+			break
+		}
+		txHashes, rawTransactions, err = b.parseInput([]*types.Transaction{v})
+	default:
+		err = fmt.Errorf("unsupported input type %T", input)
+	}
+	if err != nil {
+		report.Err = err
+		return report
+	}
+	bid := b.createBidRequest(report.Amount, blockNumber, decayStart, decayEnd, txHashes, rawTransactions)
+	if err := checkBidSize(bid, b.maxPayload); err != nil {
+		slog.Warn("Dry run, bid would be refused", "error", err, "blockNumber", blockNumber)
+		report.Err = err
+		return report
+	}
+	logged := bid
+	if !RetainRawPayloads() && len(bid.RawTransactions) > 0 {
+		logged = proto.Clone(bid).(*pb.Bid)
+		for i := range logged.RawTransactions {
+			logged.RawTransactions[i] = RedactedPayload
+		}
+	}
+	serialized, err := protojson.Marshal(logged)
+	if err != nil {
+		report.Err = err
+		return report
+	}
+	// The raw transaction, when retained, is already part of the bid
+	summary := SummarizePayload(input)
+	summary.Raw = ""
+	attrs := append([]any{
+		"amount", report.Amount,
+		"amount_ETH", money.ToEth(EthToWei(randomEthAmount)),
+		"blockNumber", blockNumber,
+		"decayStart", decayStart,
+		"decayEnd", decayEnd,
+		"bidSize", proto.Size(bid),
+	}, summary.LogAttrs()...)
+	slog.Info("Dry run, bid not sent", append(attrs, "bid", string(serialized))...)
+	report.Err = ErrDryRun
+	return report
+}
@@ -0,0 +1,55 @@
+package mevcommit
+
+import (
+	"bytes"
+	"encoding/hex"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunBidReport(t *testing.T) {
+	client := &optionsBidderClient{}
+	tx := types.NewTx(&types.LegacyTx{Nonce: 7, Data: make([]byte, 4096)})
+
+	report := DryRunBidReport(&Bidder{client: client}, tx, 100, 0.001, 36*time.Second)
+	require.ErrorIs(t, report.Err, ErrDryRun)
+	require.False(t, report.Sent)
+	require.Equal(t, "1000000000000000", report.Amount)
+	require.Equal(t, int64(36000), report.DecayEnd-report.DecayStart)
+	require.Nil(t, client.opts, "dry runs send nothing")
+
+	report = DryRunBidReport(&Bidder{client: client, maxPayload: 1024}, tx, 100, 0.001, 36*time.Second)
+	require.ErrorIs(t, report.Err, ErrBidTooLarge, "bids the bidder node would refuse are reported")
+
+	report = DryRunBidReport(&Bidder{client: client, maxPayload: 1024}, tx.Hash().String(), 100, 0.001, 36*time.Second)
+	require.ErrorIs(t, report.Err, ErrDryRun)
+
+	report = DryRunBidReport(nil, tx, 100, 0.001, 0)
+	require.ErrorIs(t, report.Err, ErrDecayOutOfBounds)
+}
+
+func TestDryRunBidReportRedactsRawTransactions(t *testing.T) {
+	var out bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&out, nil)))
+	tx := newTestTx()
+	raw, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	SetRetainRawPayloads(false)
+	report := DryRunBidReport(&Bidder{client: &optionsBidderClient{}}, tx, 100, 0.001, 36*time.Second)
+	require.ErrorIs(t, report.Err, ErrDryRun)
+	require.NotContains(t, out.String(), hex.EncodeToString(raw))
+	require.Contains(t, out.String(), `\"redacted\"`)
+	require.Contains(t, out.String(), `"payloadSize":`)
+
+	out.Reset()
+	SetRetainRawPayloads(true)
+	defer SetRetainRawPayloads(false)
+	DryRunBidReport(&Bidder{client: &optionsBidderClient{}}, tx, 100, 0.001, 36*time.Second)
+	require.Contains(t, out.String(), hex.EncodeToString(raw), "retained payloads are logged")
+}
@@ -0,0 +1,63 @@
+package mevcommit
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// UsesTLS reports whether the bidder node is reached over TLS.
+func (cfg BidderConfig) UsesTLS() bool {
+	return cfg.TLS || cfg.TLSCACert != "" || cfg.TLSCert != ""
+}
+
+// transportCredentials returns the credentials securing the connection to the
+// bidder node: insecure without TLS, otherwise TLS verifying the server with
+// the CA certificate (or the system roots) and presenting the client
+// certificate, if any.
+func transportCredentials(cfg BidderConfig) (credentials.TransportCredentials, error) {
+	if !cfg.UsesTLS() {
+		return insecure.NewCredentials(), nil
+	}
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLSCACert != "" {
+		pem, err := os.ReadFile(cfg.TLSCACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.TLSCACert)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	if (cfg.TLSCert == "") != (cfg.TLSKey == "") {
+		return nil, errors.New("the client certificate and key must be set together")
+	}
+	if cfg.TLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}
+
+// bearerToken sends a token in the authorization metadata of every call.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity keeps the token from being sent in plaintext.
+func (bearerToken) RequireTransportSecurity() bool {
+	return true
+}
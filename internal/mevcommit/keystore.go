@@ -0,0 +1,34 @@
+package mevcommit
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// LoadKeystore decrypts the private key of an encrypted geth keystore file,
+// as written by geth account new or clef.
+func LoadKeystore(path, password string) (*ecdsa.PrivateKey, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore %s: %w", path, err)
+	}
+	return key.PrivateKey, nil
+}
+
+// AuthenticateKeystore is AuthenticateAddress for a key kept in an encrypted
+// keystore file, so the key is never passed to the bidder in plaintext.
+func AuthenticateKeystore(path, password string, client *ethclient.Client) (AuthAcct, error) {
+	privateKey, err := LoadKeystore(path, password)
+	if err != nil {
+		return AuthAcct{}, err
+	}
+	return authenticateKey(privateKey, client)
+}
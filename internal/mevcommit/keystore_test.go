@@ -0,0 +1,34 @@
+package mevcommit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadKeystore(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{
+		Id:         uuid.New(),
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}, "s3cret", keystore.LightScryptN, keystore.LightScryptP)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(path, keyJSON, 0o600))
+
+	loaded, err := LoadKeystore(path, "s3cret")
+	require.NoError(t, err)
+	require.True(t, privateKey.Equal(loaded))
+
+	_, err = LoadKeystore(path, "wrong")
+	require.ErrorContains(t, err, "could not decrypt key with given password")
+	_, err = LoadKeystore(filepath.Join(t.TempDir(), "missing.json"), "s3cret")
+	require.ErrorContains(t, err, "failed to read keystore")
+}
@@ -0,0 +1,296 @@
+package mevcommit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultNetwork is the mev-commit network used when none is selected.
+const DefaultNetwork = "testnet"
+
+// Contracts are the mev-commit contract addresses of a network.
+type Contracts struct {
+	BidderRegistry common.Address `json:"BidderRegistry"`
+	BlockTracker   common.Address `json:"BlockTracker"`
+	PreconfManager common.Address `json:"PreconfManager"`
+}
+
+// Network describes where the contract addresses of a mev-commit network are
+// published and which addresses to use when they cannot be fetched.
+type Network struct {
+	ContractsURL string     // Official contracts JSON endpoint, if any.
+	Fallback     *Contracts // Known addresses used offline, if any.
+}
+
+// Networks are the selectable mev-commit networks.
+var Networks = map[string]Network{
+	"mainnet": {
+		ContractsURL: "https://contracts.mev-commit.xyz",
+		Fallback: &Contracts{
+			BidderRegistry: common.HexToAddress("0x145a9f4cbae2ec281f417195ea3464dbd04289a2"),
+			BlockTracker:   common.HexToAddress("0x5d64b933739558101f9359e2750acc228f0cb64f"),
+			PreconfManager: common.HexToAddress("0x2ee9e88f57a7db801e114a4df7a99eb7d257deb8"),
+		},
+	},
+	"testnet": {
+		ContractsURL: "https://contracts.testnet.mev-commit.xyz",
+		Fallback: &Contracts{
+			BidderRegistry: common.HexToAddress("0x401B3287364f95694c43ACA3252831cAc02e5C41"),
+			BlockTracker:   common.HexToAddress("0x7538F3AaA07dA1990486De21A0B438F55e9639e4"),
+			PreconfManager: common.HexToAddress("0x9433bCD9e89F923ce587f7FA7E39e120E93eb84D"),
+		},
+	},
+	// Devnets are redeployed frequently, so their addresses have to come from
+	// a contracts URL or the address environment variables.
+	"devnet": {},
+}
+
+// NetworkNames returns the selectable network names, sorted.
+func NetworkNames() []string {
+	names := make([]string, 0, len(Networks))
+	for name := range Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Sources of a manifest, from the first tried to the last.
+const (
+	SourceRemote   = "remote"   // Fetched from the contracts URL.
+	SourceCache    = "cache"    // The copy cached by the last successful fetch.
+	SourceEmbedded = "embedded" // The network's known addresses built into the bidder.
+)
+
+// Manifest is the contract addresses of a network with the version and source
+// they come from.
+type Manifest struct {
+	Contracts
+	// Version is the manifest's own version, or a digest of its addresses
+	// when it has none.
+	Version string `json:"Version,omitempty"`
+	Source  string `json:"-"`
+}
+
+// newManifest returns the manifest of c from source, versioned by a digest of
+// the addresses unless version is set.
+func newManifest(c Contracts, version, source string) Manifest {
+	if version == "" {
+		sum := sha256.Sum256(append(append(c.BidderRegistry.Bytes(), c.BlockTracker.Bytes()...), c.PreconfManager.Bytes()...))
+		version = "sha256:" + hex.EncodeToString(sum[:4])
+	}
+	return Manifest{Contracts: c, Version: version, Source: source}
+}
+
+var (
+	resolvedMu sync.Mutex
+	resolved   = make(map[string]Manifest) // Fetched manifests per contracts URL.
+)
+
+// ResolveContracts returns the contract addresses of network, as resolved by
+// ResolveManifest.
+func ResolveContracts(ctx context.Context, network, contractsURL, cacheDir string) (Contracts, error) {
+	m, err := ResolveManifest(ctx, network, contractsURL, cacheDir)
+	return m.Contracts, err
+}
+
+// ResolveManifest returns the contract manifest of network. It is fetched from
+// contractsURL, or the network's official endpoint when empty, and cached in
+// memory and in cacheDir. When fetching fails the cached copy is used, then
+// the network's embedded addresses. The source used is logged.
+func ResolveManifest(ctx context.Context, network, contractsURL, cacheDir string) (Manifest, error) {
+	m, err := resolveManifest(ctx, network, contractsURL, cacheDir)
+	if err == nil {
+		slog.Info("Contract addresses resolved",
+			"network", network,
+			"source", m.Source,
+			"version", m.Version,
+			"bidderRegistry", m.BidderRegistry.Hex(),
+			"blockTracker", m.BlockTracker.Hex(),
+			"preconfManager", m.PreconfManager.Hex(),
+		)
+	}
+	return m, err
+}
+
+func resolveManifest(ctx context.Context, network, contractsURL, cacheDir string) (Manifest, error) {
+	n, ok := Networks[network]
+	if !ok {
+		return Manifest{}, fmt.Errorf("unknown network %q, expected one of %v", network, NetworkNames())
+	}
+	if contractsURL == "" {
+		contractsURL = n.ContractsURL
+	}
+	if contractsURL == "" {
+		if n.Fallback == nil {
+			return Manifest{}, fmt.Errorf("network %s has no published contract addresses, set a contracts URL or the contract address variables", network)
+		}
+		return newManifest(*n.Fallback, "", SourceEmbedded), nil
+	}
+
+	resolvedMu.Lock()
+	defer resolvedMu.Unlock()
+	if m, ok := resolved[contractsURL]; ok {
+		return m, nil
+	}
+
+	cacheFile := ""
+	if cacheDir != "" {
+		cacheFile = filepath.Join(cacheDir, "contracts-"+network+".json")
+	}
+	m, err := fetchManifest(ctx, contractsURL)
+	if err == nil {
+		resolved[contractsURL] = m
+		if cacheFile != "" {
+			if cached, cacheErr := readContractsCache(cacheFile); cacheErr == nil && cached.Version != m.Version {
+				slog.Info("Contract manifest version changed", "network", network, "previous", cached.Version, "current", m.Version)
+			}
+			if err := writeContractsCache(cacheFile, m); err != nil {
+				slog.Warn("Failed to cache contract addresses", "error", err, "file", cacheFile)
+			}
+		}
+		return m, nil
+	}
+
+	slog.Warn("Failed to fetch contract addresses, using cached or known addresses",
+		"error", err,
+		"network", network,
+		"url", contractsURL,
+	)
+	if cacheFile != "" {
+		if cached, cacheErr := readContractsCache(cacheFile); cacheErr == nil {
+			return cached, nil
+		}
+	}
+	if n.Fallback != nil {
+		return newManifest(*n.Fallback, "", SourceEmbedded), nil
+	}
+	return Manifest{}, fmt.Errorf("failed to fetch contract addresses for %s: %w", network, err)
+}
+
+// fetchManifest reads the contracts JSON endpoint.
+func fetchManifest(ctx context.Context, url string) (Manifest, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Manifest{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Manifest{}, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Manifest{}, err
+	}
+	return parseManifest(body, SourceRemote)
+}
+
+// parseManifest decodes contract addresses, requiring all of them, and the
+// manifest version if there is one.
+func parseManifest(data []byte, source string) (Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Manifest{}, fmt.Errorf("invalid contracts JSON: %w", err)
+	}
+	if !m.Complete() {
+		return Manifest{}, fmt.Errorf("contracts JSON is missing BidderRegistry, BlockTracker or PreconfManager")
+	}
+	return newManifest(m.Contracts, m.Version, source), nil
+}
+
+func readContractsCache(path string) (Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Manifest{}, err
+	}
+	return parseManifest(data, SourceCache)
+}
+
+func writeContractsCache(path string, m Manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Override returns c with the non-zero addresses of o.
+func (c Contracts) Override(o Contracts) Contracts {
+	zero := common.Address{}
+	if o.BidderRegistry != zero {
+		c.BidderRegistry = o.BidderRegistry
+	}
+	if o.BlockTracker != zero {
+		c.BlockTracker = o.BlockTracker
+	}
+	if o.PreconfManager != zero {
+		c.PreconfManager = o.PreconfManager
+	}
+	return c
+}
+
+// Complete reports whether every address is set.
+func (c Contracts) Complete() bool {
+	zero := common.Address{}
+	return c.BidderRegistry != zero && c.BlockTracker != zero && c.PreconfManager != zero
+}
+
+// UseContracts sets the global contract addresses.
+func UseContracts(c Contracts) {
+	BidderRegistryAddress = c.BidderRegistry
+	BlockTrackerAddress = c.BlockTracker
+	PreconfManagerAddress = c.PreconfManager
+}
+
+var (
+	inUseMu sync.Mutex
+	inUse   *Manifest // Manifest whose addresses are in use, once one is.
+)
+
+// UseManifest sets the global contract addresses from m, replaced by the
+// non-zero addresses of overrides, which are logged. Once the addresses of a
+// manifest are in use, those of another manifest version are refused, so one
+// run never mixes addresses of different versions.
+func UseManifest(m Manifest, overrides Contracts) error {
+	inUseMu.Lock()
+	defer inUseMu.Unlock()
+	if inUse != nil && inUse.Version != m.Version {
+		return fmt.Errorf("contract manifest version %s (%s) differs from version %s (%s) already in use, restart to switch",
+			m.Version, m.Source, inUse.Version, inUse.Source)
+	}
+	for _, o := range []struct {
+		name string
+		addr common.Address
+	}{
+		{"bidderRegistry", overrides.BidderRegistry},
+		{"blockTracker", overrides.BlockTracker},
+		{"preconfManager", overrides.PreconfManager},
+	} {
+		if o.addr != (common.Address{}) {
+			slog.Warn("Contract address overridden", "contract", o.name, "address", o.addr.Hex(), "manifestVersion", m.Version)
+		}
+	}
+	pinned := m
+	inUse = &pinned
+	UseContracts(m.Override(overrides))
+	return nil
+}
@@ -0,0 +1,104 @@
+package mevcommit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveContractsFetchesAndCaches(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{
+			"BidderRegistry": "0x00000000000000000000000000000000000000a1",
+			"BlockTracker": "0x00000000000000000000000000000000000000a2",
+			"PreconfManager": "0x00000000000000000000000000000000000000a3",
+			"Oracle": "0x00000000000000000000000000000000000000a4"
+		}`))
+	}))
+	cacheDir := t.TempDir()
+
+	c, err := ResolveContracts(context.Background(), "devnet", ts.URL, cacheDir)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress("0xa3"), c.PreconfManager)
+
+	_, err = ResolveContracts(context.Background(), "devnet", ts.URL, cacheDir)
+	require.NoError(t, err)
+	require.Equal(t, 1, requests, "fetched addresses are cached in memory")
+
+	// Once the endpoint is gone, a fresh URL falls back to the file cache
+	ts.Close()
+	c, err = ResolveContracts(context.Background(), "devnet", ts.URL+"/gone", cacheDir)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress("0xa1"), c.BidderRegistry)
+}
+
+func TestResolveContractsOfflineFallback(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c, err := ResolveContracts(context.Background(), "mainnet", ts.URL, t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, *Networks["mainnet"].Fallback, c)
+
+	_, err = ResolveContracts(context.Background(), "devnet", ts.URL, t.TempDir())
+	require.Error(t, err, "devnet has no known addresses")
+
+	_, err = ResolveContracts(context.Background(), "moonnet", "", "")
+	require.Error(t, err)
+}
+
+func TestManifestSourcesAndVersions(t *testing.T) {
+	up := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"version": "v1.2.0",
+			"BidderRegistry": "0x00000000000000000000000000000000000000b1",
+			"BlockTracker": "0x00000000000000000000000000000000000000b2",
+			"PreconfManager": "0x00000000000000000000000000000000000000b3"
+		}`))
+	}))
+	defer ts.Close()
+	cacheDir := t.TempDir()
+
+	remote, err := ResolveManifest(context.Background(), "mainnet", ts.URL, cacheDir)
+	require.NoError(t, err)
+	require.Equal(t, SourceRemote, remote.Source)
+	require.Equal(t, "v1.2.0", remote.Version)
+
+	up = false
+	cached, err := ResolveManifest(context.Background(), "mainnet", ts.URL+"/v2", cacheDir)
+	require.NoError(t, err)
+	require.Equal(t, SourceCache, cached.Source)
+	require.Equal(t, "v1.2.0", cached.Version)
+	require.Equal(t, remote.Contracts, cached.Contracts)
+
+	embedded, err := ResolveManifest(context.Background(), "mainnet", ts.URL+"/v3", t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, SourceEmbedded, embedded.Source)
+	require.Regexp(t, `^sha256:[0-9a-f]{8}$`, embedded.Version)
+
+	previous := Contracts{BidderRegistry: BidderRegistryAddress, BlockTracker: BlockTrackerAddress, PreconfManager: PreconfManagerAddress}
+	t.Cleanup(func() {
+		inUse = nil
+		UseContracts(previous)
+	})
+	override := common.HexToAddress("0xc3")
+	require.NoError(t, UseManifest(cached, Contracts{PreconfManager: override}))
+	require.Equal(t, common.HexToAddress("0xb1"), BidderRegistryAddress)
+	require.Equal(t, override, PreconfManagerAddress)
+	require.NoError(t, UseManifest(remote, Contracts{}), "the same version again")
+	require.ErrorContains(t, UseManifest(embedded, Contracts{}), "differs from version v1.2.0")
+	require.Equal(t, common.HexToAddress("0xb1"), BidderRegistryAddress, "addresses in use are kept")
+}
@@ -0,0 +1,153 @@
+package mevcommit
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// retainRawPayloads controls whether raw transaction bytes may be written to
+// logs, metrics or stores. Raw payloads can carry sensitive calldata, so only
+// the transaction hash and size are retained unless explicitly enabled.
+var retainRawPayloads atomic.Bool
+
+// SetRetainRawPayloads enables or disables retention of raw transaction bytes.
+func SetRetainRawPayloads(enabled bool) {
+	retainRawPayloads.Store(enabled)
+}
+
+// RetainRawPayloads reports whether raw transaction bytes may be retained.
+func RetainRawPayloads() bool {
+	return retainRawPayloads.Load()
+}
+
+// PayloadSummary is the view of a bid payload that is safe to log or persist.
+type PayloadSummary struct {
+	TxHash string `json:"tx_hash"`
+	Size   int    `json:"size"`          // Size of the RLP-encoded transaction in bytes, 0 for hash-only bids.
+	Raw    string `json:"raw,omitempty"` // Hex-encoded raw transaction, only set when retention is enabled.
+}
+
+// SummarizePayload builds a PayloadSummary for a bid input. Supported inputs
+// are a transaction hash string or a *types.Transaction.
+func SummarizePayload(input interface{}) PayloadSummary {
+	switch v := input.(type) {
+	case string:
+		return PayloadSummary{TxHash: strings.TrimPrefix(v, "0x")}
+	case *types.Transaction:
+		if v == nil {
+			return PayloadSummary{}
+		}
+		summary := PayloadSummary{
+			TxHash: strings.TrimPrefix(v.Hash().Hex(), "0x"),
+			Size:   int(v.Size()),
+		}
+		if RetainRawPayloads() {
+			if raw, err := v.MarshalBinary(); err == nil {
+				summary.Raw = hex.EncodeToString(raw)
+			}
+		}
+		return summary
+	default:
+		return PayloadSummary{TxHash: fmt.Sprintf("unsupported(%T)", input)}
+	}
+}
+
+// LogAttrs returns the summary as slog key/value pairs.
+func (p PayloadSummary) LogAttrs() []any {
+	attrs := []any{"txHash", p.TxHash, "payloadSize", p.Size}
+	if p.Raw != "" {
+		attrs = append(attrs, "rawTx", p.Raw)
+	}
+	return attrs
+}
+
+// RedactedPayload stands in for raw transactions in logged bids and requests
+// while raw payloads are not retained.
+const RedactedPayload = "redacted"
+
+// RedactRequest returns request, a JSON-RPC request carrying tx, for logging:
+// as is when raw payloads are retained, and with the raw transaction replaced
+// by RedactedPayload otherwise. The rest of the request, such as bundle hints
+// or private transaction preferences, is kept.
+func RedactRequest(request []byte, tx *types.Transaction) string {
+	if RetainRawPayloads() || tx == nil {
+		return string(request)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return RedactedPayload
+	}
+	return strings.ReplaceAll(string(request), hexutil.Encode(raw), RedactedPayload)
+}
+
+// PayloadPrivacy selects how much of a transaction is disclosed to providers
+// when bidding.
+type PayloadPrivacy string
+
+const (
+	// PrivacyPayload sends the raw signed transaction inside the bid.
+	PrivacyPayload PayloadPrivacy = "payload"
+	// PrivacyHash submits the transaction to the builder endpoint first and
+	// bids on its hash only.
+	PrivacyHash PayloadPrivacy = "hash"
+	// PrivacyCommitReveal bids on the transaction hash only and reveals the
+	// raw transaction to the builder endpoint once a provider has committed.
+	// The bidder API has no reveal call, so the reveal goes through the bundle
+	// endpoint rather than the bid stream.
+	PrivacyCommitReveal PayloadPrivacy = "commit-reveal"
+)
+
+// ParsePayloadPrivacy parses a privacy mode. An empty value falls back to the
+// legacy use-payload switch.
+func ParsePayloadPrivacy(value string, usePayload bool) (PayloadPrivacy, error) {
+	switch PayloadPrivacy(strings.ToLower(strings.TrimSpace(value))) {
+	case "":
+		if usePayload {
+			return PrivacyPayload, nil
+		}
+		return PrivacyHash, nil
+	case PrivacyPayload:
+		return PrivacyPayload, nil
+	case PrivacyHash:
+		return PrivacyHash, nil
+	case PrivacyCommitReveal:
+		return PrivacyCommitReveal, nil
+	default:
+		return "", fmt.Errorf("invalid payload privacy %q (expected %s, %s or %s)", value, PrivacyPayload, PrivacyHash, PrivacyCommitReveal)
+	}
+}
+
+// SendsRawPayload reports whether the raw transaction is part of the bid itself.
+func (p PayloadPrivacy) SendsRawPayload() bool {
+	return p == PrivacyPayload
+}
+
+// SubmissionBackend selects how transactions reach providers.
+type SubmissionBackend string
+
+const (
+	// BackendBidder bids for every transaction through the bidder node.
+	BackendBidder SubmissionBackend = "bidder"
+	// BackendPreconfRPC submits transactions to the mev-commit preconf RPC,
+	// which bids for them on the sender's behalf. The bidder node is not
+	// used for bids, so commitments are only known from the mev-commit chain.
+	BackendPreconfRPC SubmissionBackend = "preconf-rpc"
+)
+
+// ParseSubmissionBackend parses a submission backend. An empty value selects
+// the bidder node.
+func ParseSubmissionBackend(value string) (SubmissionBackend, error) {
+	switch SubmissionBackend(strings.ToLower(strings.TrimSpace(value))) {
+	case "", BackendBidder:
+		return BackendBidder, nil
+	case BackendPreconfRPC:
+		return BackendPreconfRPC, nil
+	default:
+		return "", fmt.Errorf("invalid submission backend %q (expected %s or %s)", value, BackendBidder, BackendPreconfRPC)
+	}
+}
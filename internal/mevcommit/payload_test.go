@@ -0,0 +1,98 @@
+package mevcommit
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTx() *types.Transaction {
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	return types.NewTx(&types.DynamicFeeTx{
+		Nonce:     1,
+		To:        &to,
+		Value:     big.NewInt(1),
+		Gas:       21000,
+		GasFeeCap: big.NewInt(2),
+		GasTipCap: big.NewInt(1),
+		Data:      []byte("sensitive calldata"),
+	})
+}
+
+func TestSummarizePayloadOmitsRawByDefault(t *testing.T) {
+	SetRetainRawPayloads(false)
+	tx := newTestTx()
+
+	summary := SummarizePayload(tx)
+	require.Equal(t, strings.TrimPrefix(tx.Hash().Hex(), "0x"), summary.TxHash)
+	require.Equal(t, int(tx.Size()), summary.Size)
+	require.Empty(t, summary.Raw)
+	require.NotContains(t, summary.LogAttrs(), "rawTx")
+}
+
+func TestSummarizePayloadRetainsRawWhenEnabled(t *testing.T) {
+	SetRetainRawPayloads(true)
+	defer SetRetainRawPayloads(false)
+
+	summary := SummarizePayload(newTestTx())
+	require.NotEmpty(t, summary.Raw)
+	require.Contains(t, summary.LogAttrs(), "rawTx")
+}
+
+func TestSummarizePayloadHash(t *testing.T) {
+	summary := SummarizePayload("0xabc123")
+	require.Equal(t, "abc123", summary.TxHash)
+	require.Zero(t, summary.Size)
+}
+
+func TestParsePayloadPrivacy(t *testing.T) {
+	mode, err := ParsePayloadPrivacy("", true)
+	require.NoError(t, err)
+	require.Equal(t, PrivacyPayload, mode)
+
+	mode, err = ParsePayloadPrivacy("", false)
+	require.NoError(t, err)
+	require.Equal(t, PrivacyHash, mode)
+
+	mode, err = ParsePayloadPrivacy("Commit-Reveal", true)
+	require.NoError(t, err)
+	require.Equal(t, PrivacyCommitReveal, mode)
+	require.False(t, mode.SendsRawPayload())
+
+	_, err = ParsePayloadPrivacy("plaintext", true)
+	require.Error(t, err)
+}
+
+func TestParseSubmissionBackend(t *testing.T) {
+	backend, err := ParseSubmissionBackend("")
+	require.NoError(t, err)
+	require.Equal(t, BackendBidder, backend)
+
+	backend, err = ParseSubmissionBackend("Preconf-RPC")
+	require.NoError(t, err)
+	require.Equal(t, BackendPreconfRPC, backend)
+
+	_, err = ParseSubmissionBackend("relay")
+	require.Error(t, err)
+}
+
+func TestRedactRequest(t *testing.T) {
+	SetRetainRawPayloads(false)
+	tx := newTestTx()
+	raw, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	request := `{"method":"eth_sendPrivateTransaction","params":[{"tx":"` + hexutil.Encode(raw) + `","preferences":{"fast":true}}]}`
+
+	redacted := RedactRequest([]byte(request), tx)
+	require.NotContains(t, redacted, hexutil.Encode(raw))
+	require.Equal(t, `{"method":"eth_sendPrivateTransaction","params":[{"tx":"redacted","preferences":{"fast":true}}]}`, redacted)
+
+	SetRetainRawPayloads(true)
+	defer SetRetainRawPayloads(false)
+	require.Equal(t, request, RedactRequest([]byte(request), tx))
+}
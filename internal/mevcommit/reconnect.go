@@ -0,0 +1,118 @@
+package mevcommit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+// Default retry policy of bids the bidder node could not take.
+const (
+	DefaultBidRetries      = 3
+	DefaultBidRetryBackoff = 250 * time.Millisecond
+	maxBidRetryBackoff     = 2 * time.Second
+)
+
+// reconnectBackoff paces reconnection attempts to the bidder node. gRPC's
+// default gives up to two minutes between attempts, far longer than a node
+// restart takes.
+var reconnectBackoff = backoff.Config{
+	BaseDelay:  500 * time.Millisecond,
+	Multiplier: 1.6,
+	Jitter:     0.2,
+	MaxDelay:   10 * time.Second,
+}
+
+// RetryPolicy bounds the retries of a bid the bidder node was unavailable for,
+// e.g. while it restarts. Retries only happen before the bid reached the node,
+// so a bid is never sent twice, and they share the bid's send_bid budget.
+type RetryPolicy struct {
+	MaxRetries     int           // Retries after the first attempt; 0 disables them.
+	InitialBackoff time.Duration // Wait before the first retry, doubled for each further one.
+}
+
+// backoff returns the wait before retry attempt, counted from 0.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 0; i < attempt && wait < maxBidRetryBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBidRetryBackoff)
+}
+
+// retryable reports whether a failed SendBid call can be sent again: the node
+// was unreachable, so it never received the bid.
+func retryable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// sendBidWithRetry sends bidRequest, retrying while the node is unavailable
+// until the retry budget or ctx is exhausted.
+func (b *Bidder) sendBidWithRetry(ctx context.Context, bidRequest *pb.Bid) (pb.Bidder_SendBidClient, error) {
+	for attempt := 0; ; attempt++ {
+		response, err := b.client.SendBid(ctx, bidRequest, b.callOptions(bidRequest)...)
+		if err == nil || !retryable(err) || attempt >= b.retry.MaxRetries {
+			return response, err
+		}
+		wait := b.retry.backoff(attempt)
+		slog.Warn("Bidder node unavailable, retrying bid",
+			"error", err,
+			"attempt", attempt+1,
+			"backoff", wait,
+		)
+		metrics.BidRetries.Inc()
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// Watch follows the state of the connection to the bidder node until ctx is
+// canceled. It logs when the connection is lost and restored, exports it as
+// the bidder_connected metric, and reconnects an idle connection right away
+// instead of on the next bid.
+func (b *Bidder) Watch(ctx context.Context) {
+	if b.conn == nil {
+		return
+	}
+	connected := true
+	for {
+		state := b.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			if !connected {
+				slog.Info("Bidder node connection restored")
+				metrics.BidderReconnects.Inc()
+			}
+			connected = true
+		case connectivity.Shutdown:
+			return
+		case connectivity.TransientFailure:
+			if connected {
+				slog.Warn("Bidder node connection lost, reconnecting", "state", state.String())
+			}
+			connected = false
+		case connectivity.Idle:
+			b.conn.Connect()
+		}
+		if connected {
+			metrics.BidderConnected.Set(1)
+		} else {
+			metrics.BidderConnected.Set(0)
+		}
+		if !b.conn.WaitForStateChange(ctx, state) {
+			return
+		}
+	}
+}
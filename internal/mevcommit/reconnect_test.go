@@ -0,0 +1,63 @@
+package mevcommit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakyBidderClient fails SendBid with err the first failures times.
+type flakyBidderClient struct {
+	pb.BidderClient
+	failures int
+	err      error
+	calls    int
+}
+
+func (c *flakyBidderClient) SendBid(ctx context.Context, in *pb.Bid, opts ...grpc.CallOption) (pb.Bidder_SendBidClient, error) {
+	c.calls++
+	if c.calls <= c.failures {
+		return nil, c.err
+	}
+	return new(MockBidderSendBidClient), nil
+}
+
+func TestSendBidRetriesWhileUnavailable(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	retries := testutil.ToFloat64(metrics.BidRetries)
+
+	client := &flakyBidderClient{failures: 2, err: unavailable}
+	b := &Bidder{client: client, retry: RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond}}
+	_, err := b.SendBidContext(context.Background(), []string{"0xabc"}, "1", 100, 1000, 2000)
+	require.NoError(t, err)
+	require.Equal(t, 3, client.calls)
+	require.Equal(t, retries+2, testutil.ToFloat64(metrics.BidRetries))
+
+	client = &flakyBidderClient{failures: 5, err: unavailable}
+	b = &Bidder{client: client, retry: RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}}
+	_, err = b.SendBidContext(context.Background(), []string{"0xabc"}, "1", 100, 1000, 2000)
+	require.Equal(t, codes.Unavailable, status.Code(errors.Unwrap(err)), "the retry budget is spent: %v", err)
+	require.Equal(t, 3, client.calls)
+
+	client = &flakyBidderClient{failures: 1, err: status.Error(codes.InvalidArgument, "bad bid")}
+	b = &Bidder{client: client, retry: RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond}}
+	_, err = b.SendBidContext(context.Background(), []string{"0xabc"}, "1", 100, 1000, 2000)
+	require.ErrorContains(t, err, "bad bid")
+	require.Equal(t, 1, client.calls, "rejected bids are not retried")
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 250 * time.Millisecond}
+	require.Equal(t, 250*time.Millisecond, p.backoff(0))
+	require.Equal(t, time.Second, p.backoff(2))
+	require.Equal(t, maxBidRetryBackoff, p.backoff(10))
+}
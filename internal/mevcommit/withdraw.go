@@ -0,0 +1,149 @@
+package mevcommit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"sync"
+
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// DefaultWithdrawLookback is the number of windows before the current one
+// checked for leftover deposits when the withdrawer starts.
+const DefaultWithdrawLookback = 20
+
+// WithdrawConfig holds the settings for the withdrawer.
+type WithdrawConfig struct {
+	BlocksPerWindow uint64 // Number of L1 blocks per bidding window.
+	SettlementLag   uint64 // Windows to wait before a window can be withdrawn from.
+	DryRun          bool   // Only report withdrawable deposits.
+
+	// Notify, if set, is called after every successful withdrawal.
+	Notify func(DepositEvent)
+}
+
+// Withdrawal is the unused deposit of a settled window.
+type Withdrawal struct {
+	Window uint64
+	Amount *big.Int
+	DryRun bool // Reported only, not withdrawn.
+}
+
+// Withdrawer tracks the windows the bidder bids into and withdraws their
+// unused deposit through the bidder node once they have settled.
+type Withdrawer struct {
+	client pb.BidderClient
+	cfg    WithdrawConfig
+
+	mu      sync.Mutex
+	pending map[uint64]bool // Windows to check once settled.
+}
+
+// NewWithdrawer creates a withdrawer that operates through the bidder node.
+func NewWithdrawer(b *Bidder, cfg WithdrawConfig) *Withdrawer {
+	if cfg.BlocksPerWindow == 0 {
+		cfg.BlocksPerWindow = DefaultBlocksPerWindow
+	}
+	if cfg.SettlementLag == 0 {
+		cfg.SettlementLag = DefaultSettlementLag
+	}
+	return &Withdrawer{
+		client:  b.client,
+		cfg:     cfg,
+		pending: make(map[uint64]bool),
+	}
+}
+
+// TrackLookback adds the lookback windows before the window of blockNumber,
+// so deposits left behind by earlier runs are withdrawn as well.
+func (w *Withdrawer) TrackLookback(blockNumber, lookback uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	current := WindowForBlock(blockNumber, w.cfg.BlocksPerWindow)
+	for i := uint64(1); i <= lookback && i < current; i++ {
+		w.pending[current-i] = true
+	}
+}
+
+// OnTargetBlock tracks the window of blockNumber and withdraws, or in dry-run
+// mode reports, the deposits of tracked windows that have settled.
+func (w *Withdrawer) OnTargetBlock(ctx context.Context, blockNumber uint64) ([]Withdrawal, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	current := WindowForBlock(blockNumber, w.cfg.BlocksPerWindow)
+	if current == 0 {
+		return nil, nil
+	}
+	w.pending[current] = true
+
+	var settled []uint64
+	for window := range w.pending {
+		if window+w.cfg.SettlementLag <= current {
+			settled = append(settled, window)
+		}
+	}
+	if len(settled) == 0 {
+		return nil, nil
+	}
+	sort.Slice(settled, func(i, j int) bool { return settled[i] < settled[j] })
+
+	// Only windows with a deposit left are withdrawn
+	var withdrawable []Withdrawal
+	for _, window := range settled {
+		amount, err := depositOf(ctx, w.client, window)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deposit for window %d: %w", window, err)
+		}
+		if amount.Sign() > 0 {
+			withdrawable = append(withdrawable, Withdrawal{Window: window, Amount: amount, DryRun: w.cfg.DryRun})
+		}
+	}
+
+	if w.cfg.DryRun || len(withdrawable) == 0 {
+		for _, wd := range withdrawable {
+			slog.Info("Withdrawable deposit in settled window (dry run)",
+				"window", wd.Window,
+				"amount", wd.Amount.String(),
+			)
+		}
+		for _, window := range settled {
+			delete(w.pending, window)
+		}
+		return withdrawable, nil
+	}
+
+	windows := make([]*wrapperspb.UInt64Value, len(withdrawable))
+	for i, wd := range withdrawable {
+		windows[i] = wrapperspb.UInt64(wd.Window)
+	}
+	resp, err := w.client.WithdrawFromWindows(ctx, &pb.WithdrawFromWindowsRequest{WindowNumbers: windows})
+	if err != nil {
+		return nil, fmt.Errorf("failed to withdraw from settled windows: %w", err)
+	}
+
+	var withdrawn []Withdrawal
+	for _, r := range resp.GetWithdrawResponses() {
+		amount, ok := new(big.Int).SetString(r.GetAmount(), 10)
+		if !ok {
+			continue
+		}
+		window := r.GetWindowNumber().GetValue()
+		withdrawn = append(withdrawn, Withdrawal{Window: window, Amount: amount})
+		if w.cfg.Notify != nil {
+			w.cfg.Notify(DepositEvent{Withdrawal: true, Window: window, Amount: amount})
+		}
+		slog.Info("Withdrew deposit from settled window",
+			"window", window,
+			"amount", amount.String(),
+		)
+	}
+	for _, window := range settled {
+		delete(w.pending, window)
+	}
+	return withdrawn, nil
+}
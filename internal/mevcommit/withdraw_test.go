@@ -0,0 +1,58 @@
+package mevcommit
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithdrawerWithdrawsSettledWindows(t *testing.T) {
+	fake := newFakeBidderClient()
+	fake.deposits[3] = big.NewInt(40) // Left behind by an earlier run
+	fake.deposits[5] = big.NewInt(70)
+	var events []DepositEvent
+	w := NewWithdrawer(&Bidder{client: fake}, WithdrawConfig{
+		BlocksPerWindow: 10,
+		SettlementLag:   2,
+		Notify:          func(ev DepositEvent) { events = append(events, ev) },
+	})
+	ctx := context.Background()
+
+	w.TrackLookback(55, 3) // Windows 3, 4 and 5 before window 6
+	withdrawn, err := w.OnTargetBlock(ctx, 55)
+	require.NoError(t, err)
+	require.Len(t, withdrawn, 1, "only window 3 has settled")
+	require.Equal(t, uint64(3), withdrawn[0].Window)
+	require.Equal(t, int64(40), withdrawn[0].Amount.Int64())
+
+	// Window 4 has nothing left and is not withdrawn from
+	withdrawn, err = w.OnTargetBlock(ctx, 75)
+	require.NoError(t, err)
+	require.Len(t, withdrawn, 1)
+	require.Equal(t, uint64(5), withdrawn[0].Window)
+	require.Equal(t, [][]uint64{{3}, {5}}, fake.withdrawn)
+	require.Len(t, events, 2)
+	require.True(t, events[1].Withdrawal)
+
+	// Windows are only checked once
+	withdrawn, err = w.OnTargetBlock(ctx, 76)
+	require.NoError(t, err)
+	require.Empty(t, withdrawn)
+}
+
+func TestWithdrawerDryRunOnlyReports(t *testing.T) {
+	fake := newFakeBidderClient()
+	fake.deposits[1] = big.NewInt(25)
+	w := NewWithdrawer(&Bidder{client: fake}, WithdrawConfig{BlocksPerWindow: 10, SettlementLag: 2, DryRun: true})
+
+	_, err := w.OnTargetBlock(context.Background(), 5)
+	require.NoError(t, err)
+	reported, err := w.OnTargetBlock(context.Background(), 25)
+	require.NoError(t, err)
+	require.Len(t, reported, 1)
+	require.True(t, reported[0].DryRun)
+	require.Empty(t, fake.withdrawn)
+	require.Equal(t, int64(25), fake.deposits[1].Int64())
+}
@@ -0,0 +1,127 @@
+// Package money converts and rounds ETH, gwei and wei amounts. Amounts are
+// exact in wei. Float amounts, as configured or chosen by a bid strategy, are
+// converted through their shortest decimal representation, the one logs
+// print, so the wei sent always matches the amount logged; binary floating
+// point would turn 0.001 ETH into 999999999999999 wei. Rounding is the same
+// everywhere:
+//
+//   - ETH and gwei to wei truncates fractions of a wei, toward zero.
+//   - Wei to a float returns the nearest float.
+//   - Exact formatting keeps every wei; fixed-point formatting rounds half
+//     away from zero.
+package money
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimals of ETH and gwei amounts in wei.
+const (
+	EthDecimals  = 18
+	GweiDecimals = 9
+)
+
+// unit returns 10^decimals.
+func unit(decimals int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+}
+
+// decimal returns x exactly as its shortest decimal representation. NaN and
+// infinities are 0.
+func decimal(x float64) *big.Rat {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return new(big.Rat)
+	}
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(x, 'g', -1, 64))
+	return r
+}
+
+// truncate returns r without its fraction, rounded toward zero.
+func truncate(r *big.Rat) *big.Int {
+	return new(big.Int).Quo(r.Num(), r.Denom())
+}
+
+func fromFloat(x float64, decimals int) *big.Int {
+	r := decimal(x)
+	return truncate(r.Mul(r, new(big.Rat).SetInt(unit(decimals))))
+}
+
+func toFloat(wei *big.Int, decimals int) float64 {
+	if wei == nil {
+		return 0
+	}
+	f, _ := new(big.Rat).SetFrac(wei, unit(decimals)).Float64()
+	return f
+}
+
+func format(wei *big.Int, decimals int) string {
+	if wei == nil {
+		return ""
+	}
+	s := new(big.Rat).SetFrac(wei, unit(decimals)).FloatString(decimals)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// FromEth returns eth in wei, truncating fractions of a wei.
+func FromEth(eth float64) *big.Int {
+	return fromFloat(eth, EthDecimals)
+}
+
+// FromGwei returns gwei in wei, truncating fractions of a wei.
+func FromGwei(gwei float64) *big.Int {
+	return fromFloat(gwei, GweiDecimals)
+}
+
+// ToEth returns wei in ETH, as the nearest float. A nil amount is 0.
+func ToEth(wei *big.Int) float64 {
+	return toFloat(wei, EthDecimals)
+}
+
+// ToGwei returns wei in gwei, as the nearest float. A nil amount is 0.
+func ToGwei(wei *big.Int) float64 {
+	return toFloat(wei, GweiDecimals)
+}
+
+// FormatEth formats wei as an exact decimal ETH amount, without trailing
+// zeros. A nil amount formats as an empty string.
+func FormatEth(wei *big.Int) string {
+	return format(wei, EthDecimals)
+}
+
+// FormatGwei formats wei as an exact decimal gwei amount, without trailing
+// zeros. A nil amount formats as an empty string.
+func FormatGwei(wei *big.Int) string {
+	return format(wei, GweiDecimals)
+}
+
+// FormatEthFixed formats wei in ETH with places decimals, rounding half away
+// from zero. A nil amount formats as 0.
+func FormatEthFixed(wei *big.Int, places int) string {
+	if wei == nil {
+		wei = new(big.Int)
+	}
+	return new(big.Rat).SetFrac(wei, unit(EthDecimals)).FloatString(places)
+}
+
+// Scale returns wei multiplied by factor, truncating fractions of a wei. The
+// factor is taken as its shortest decimal representation, so a bump of 1.1
+// adds exactly 10%.
+func Scale(wei *big.Int, factor float64) *big.Int {
+	return ScaleN(wei, factor, 1)
+}
+
+// ScaleN returns wei multiplied by factor n times, truncating fractions of a
+// wei once at the end.
+func ScaleN(wei *big.Int, factor float64, n int) *big.Int {
+	r, f := new(big.Rat).SetInt(wei), decimal(factor)
+	for i := 0; i < n; i++ {
+		r.Mul(r, f)
+	}
+	return truncate(r)
+}
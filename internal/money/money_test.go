@@ -0,0 +1,132 @@
+package money
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func wei(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic(s)
+	}
+	return n
+}
+
+func TestFromEth(t *testing.T) {
+	for _, tc := range []struct {
+		eth  float64
+		want string
+	}{
+		{0, "0"},
+		{1, "1000000000000000000"},
+		{0.001, "1000000000000000"},
+		{0.1, "100000000000000000"},
+		{0.3, "300000000000000000"},
+		{0.0015, "1500000000000000"},
+		{0.000123456789, "123456789000000"},
+		{1e-18, "1"},
+		{1e-19, "0"},                               // A fraction of a wei
+		{1.9e-18, "1"},                             // Truncated, not rounded
+		{1.2345678901234567e-05, "12345678901234"}, // 21 decimals
+		{-0.5, "-500000000000000000"},
+		{-1.9e-18, "-1"}, // Toward zero
+		{12345.678, "12345678000000000000000"},
+		{math.NaN(), "0"},
+		{math.Inf(1), "0"},
+	} {
+		require.Equal(t, tc.want, FromEth(tc.eth).String(), "%v", tc.eth)
+	}
+}
+
+func TestFromGwei(t *testing.T) {
+	for _, tc := range []struct {
+		gwei float64
+		want string
+	}{
+		{0, "0"},
+		{1, "1000000000"},
+		{10.5, "10500000000"},
+		{0.1, "100000000"},
+		{2.000000001, "2000000001"},
+		{1e-10, "0"},
+		{1.23e-9, "1"},
+	} {
+		require.Equal(t, tc.want, FromGwei(tc.gwei).String(), "%v", tc.gwei)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	// Every amount a strategy picks converts to the wei its logged value names
+	for _, eth := range []float64{0.001, 0.0017, 0.002, 0.0123, 0.05, 0.07, 0.1, 0.2, 0.3, 0.7, 1.1} {
+		w := FromEth(eth)
+		require.Equal(t, eth, ToEth(w), "%v", eth)
+		require.Equal(t, eth, mustFloat(FormatEth(w)), "%v", eth)
+	}
+}
+
+func mustFloat(s string) float64 {
+	f, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic(s)
+	}
+	v, _ := f.Float64()
+	return v
+}
+
+func TestToFloat(t *testing.T) {
+	require.Equal(t, 0.0, ToEth(nil))
+	require.Equal(t, 0.0, ToGwei(nil))
+	require.Equal(t, 1.5, ToEth(wei("1500000000000000000")))
+	require.Equal(t, 1e-18, ToEth(big.NewInt(1)))
+	require.Equal(t, 30.000000001, ToGwei(wei("30000000001")))
+	require.Equal(t, -2.0, ToGwei(wei("-2000000000")))
+}
+
+func TestFormat(t *testing.T) {
+	for _, tc := range []struct {
+		wei       *big.Int
+		eth, gwei string
+		fixed6    string
+	}{
+		{nil, "", "", "0.000000"},
+		{big.NewInt(0), "0", "0", "0.000000"},
+		{big.NewInt(1), "0.000000000000000001", "0.000000001", "0.000000"},
+		{wei("1000000000000000001"), "1.000000000000000001", "1000000000.000000001", "1.000000"},
+		{wei("1000000000"), "0.000000001", "1", "0.000000"},
+		{wei("1500000000000000000"), "1.5", "1500000000", "1.500000"},
+		{wei("-500000000000000000"), "-0.5", "-500000000", "-0.500000"},
+		{wei("1234565000000000"), "0.001234565", "1234565", "0.001235"}, // Half away from zero
+		{wei("-1234565000000000"), "-0.001234565", "-1234565", "-0.001235"},
+		{wei("1234500000000000"), "0.0012345", "1234500", "0.001235"},
+		{wei("1234499999999999"), "0.001234499999999999", "1234499.999999999", "0.001234"},
+	} {
+		require.Equal(t, tc.eth, FormatEth(tc.wei), "%v", tc.wei)
+		require.Equal(t, tc.gwei, FormatGwei(tc.wei), "%v", tc.wei)
+		require.Equal(t, tc.fixed6, FormatEthFixed(tc.wei, 6), "%v", tc.wei)
+	}
+}
+
+func TestScale(t *testing.T) {
+	for _, tc := range []struct {
+		wei    string
+		factor float64
+		want   string
+	}{
+		{"1000000000", 1.1, "1100000000"}, // Exactly 10% more
+		{"1000000001", 1.1, "1100000001"}, // 1100000001.1, truncated
+		{"3", 1.125, "3"},
+		{"100", 2, "200"},
+		{"100", 0, "0"},
+		{"100", 1, "100"},
+		{"7", 0.5, "3"},
+		{"-7", 0.5, "-3"},
+	} {
+		require.Equal(t, tc.want, Scale(wei(tc.wei), tc.factor).String(), "%s * %v", tc.wei, tc.factor)
+	}
+	require.Equal(t, "5766", ScaleN(big.NewInt(100), 1.5, 10).String(), "truncated once, not per step")
+	require.Equal(t, "100", ScaleN(big.NewInt(100), 1.5, 0).String())
+}
@@ -0,0 +1,132 @@
+// Package outcome holds the result of bidding on one target block. The
+// bidding loop produces a BlockOutcome for every bid, and every reporter —
+// logs, the bid history, the wallet ledger, campaign records and the account
+// status board — consumes the same value instead of its own partial view.
+package outcome
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+)
+
+// Payload is the transaction a bid was made for.
+type Payload struct {
+	TxHash string
+	Lane   string         // Kind of lane that built the transaction.
+	From   common.Address // Account that signed the transaction.
+	Nonce  uint64
+	Mode   string // Payload privacy mode, or the submission backend when it is not the bidder node.
+}
+
+// Bid is the bid as sent and the bidder node's response.
+type Bid struct {
+	AmountETH  float64
+	AmountWei  string
+	DecayStart int64 // Unix milliseconds.
+	DecayEnd   int64 // Unix milliseconds.
+	Sent       bool  // Whether the bid, or the transaction for the preconf RPC, was accepted.
+	Err        error // Why the bid was not sent or its response stream failed.
+	// Signature is the bidding account's signature of the bid, see package
+	// provenance; empty when the account has no key.
+	Signature string
+}
+
+// Inclusion is whether the transaction landed on L1.
+type Inclusion struct {
+	Included bool
+	Block    uint64 // Block the transaction was included in, if it was.
+}
+
+// Costs are what the bid may cost.
+type Costs struct {
+	MaxTxFee    *big.Int // Most the transaction can cost in fees, if it was submitted.
+	BidPayments *big.Int // Sum of the committed bid amounts, in wei.
+}
+
+// Timings are when the bid was sent and resolved.
+type Timings struct {
+	Sent     time.Time
+	Resolved time.Time
+}
+
+// Latency is the time from sending the bid until it resolved.
+func (t Timings) Latency() time.Duration {
+	return t.Resolved.Sub(t.Sent)
+}
+
+// BlockOutcome is the result of bidding on one target block.
+type BlockOutcome struct {
+	TargetBlock uint64
+	Payload     Payload
+	Bid         Bid
+	Submitted   bool // Whether the transaction was handed to a provider, builder or the preconf RPC.
+	Commitments []*pb.Commitment
+	Inclusion   *Inclusion // Nil until inclusion is known.
+	Costs       Costs
+	Timings     Timings
+	// Tenant and Campaign label the bid in deployments shared between
+	// customers, see package labels; Campaign is the scheduled campaign the
+	// bid was made in.
+	Tenant   string
+	Campaign string
+}
+
+// Committed reports whether any provider committed to the bid.
+func (o BlockOutcome) Committed() bool {
+	return len(o.Commitments) > 0
+}
+
+// Providers returns the providers that committed to the bid, in arrival
+// order.
+func (o BlockOutcome) Providers() []string {
+	providers := make([]string, 0, len(o.Commitments))
+	for _, c := range o.Commitments {
+		providers = append(providers, c.GetProviderAddress())
+	}
+	return providers
+}
+
+// BidPayments sums the bid amounts of commitments, in wei. Amounts that do
+// not parse are skipped.
+func BidPayments(commitments []*pb.Commitment) *big.Int {
+	total := new(big.Int)
+	for _, c := range commitments {
+		if amount, ok := new(big.Int).SetString(c.GetBidAmount(), 10); ok {
+			total.Add(total, amount)
+		}
+	}
+	return total
+}
+
+// LogAttrs returns the outcome as slog attributes.
+func (o BlockOutcome) LogAttrs() []any {
+	attrs := []any{
+		"targetBlock", o.TargetBlock,
+		"txHash", o.Payload.TxHash,
+		"lane", o.Payload.Lane,
+		"mode", o.Payload.Mode,
+		"sent", o.Bid.Sent,
+		"submitted", o.Submitted,
+		"commitments", len(o.Commitments),
+		"latency", o.Timings.Latency(),
+	}
+	if o.Bid.Err != nil {
+		attrs = append(attrs, "error", o.Bid.Err)
+	}
+	if o.Costs.BidPayments != nil && o.Costs.BidPayments.Sign() > 0 {
+		attrs = append(attrs, "bidPaymentsWei", o.Costs.BidPayments.String())
+	}
+	if o.Inclusion != nil {
+		attrs = append(attrs, "included", o.Inclusion.Included)
+	}
+	if o.Tenant != "" {
+		attrs = append(attrs, "tenant", o.Tenant)
+	}
+	if o.Campaign != "" {
+		attrs = append(attrs, "campaign", o.Campaign)
+	}
+	return attrs
+}
@@ -0,0 +1,38 @@
+package outcome
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockOutcome(t *testing.T) {
+	sent := time.Unix(1_700_000_000, 0)
+	commitments := []*pb.Commitment{
+		{ProviderAddress: "0xprovider1", BidAmount: "1000"},
+		{ProviderAddress: "0xprovider2", BidAmount: "250"},
+		{ProviderAddress: "0xprovider3", BidAmount: "not a number"},
+	}
+	o := BlockOutcome{
+		TargetBlock: 10,
+		Payload:     Payload{TxHash: "0xab", Lane: "blob", Mode: "payload"},
+		Bid:         Bid{Sent: true},
+		Commitments: commitments,
+		Costs:       Costs{BidPayments: BidPayments(commitments)},
+		Timings:     Timings{Sent: sent, Resolved: sent.Add(1500 * time.Millisecond)},
+	}
+	require.True(t, o.Committed())
+	require.Equal(t, []string{"0xprovider1", "0xprovider2", "0xprovider3"}, o.Providers())
+	require.Equal(t, big.NewInt(1250), o.Costs.BidPayments, "amounts that do not parse are skipped")
+	require.Equal(t, 1500*time.Millisecond, o.Timings.Latency())
+	require.Contains(t, o.LogAttrs(), "bidPaymentsWei")
+
+	failed := BlockOutcome{Bid: Bid{Err: errors.New("unavailable")}}
+	require.False(t, failed.Committed())
+	require.Empty(t, failed.Providers())
+	require.Contains(t, failed.LogAttrs(), "error")
+}
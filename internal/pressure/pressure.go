@@ -0,0 +1,229 @@
+// Package pressure watches the bidder's own memory and goroutine use and
+// sheds optional work, such as market snapshots, competition observing and
+// telemetry, while either is above its threshold, before the bidding path is
+// affected. Work resumes once usage is back below 90% of the thresholds, so
+// it does not flap around them.
+package pressure
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+// DefaultInterval is how often usage is sampled.
+const DefaultInterval = 5 * time.Second
+
+// recoverRatio is the share of a threshold usage must fall below for
+// degradation to end.
+const recoverRatio = 0.9
+
+// Thresholds above which optional work is shed; 0 disables a threshold.
+type Thresholds struct {
+	MaxRSS        uint64 // Bytes.
+	MaxGoroutines int
+}
+
+// Usage is a sample of the process's resource use.
+type Usage struct {
+	RSS        uint64 `json:"rss_bytes"`
+	Goroutines int    `json:"goroutines"`
+}
+
+// State is the degradation state served on /status.
+type State struct {
+	Usage
+	Degraded bool      `json:"degraded"`
+	Reasons  []string  `json:"reasons,omitempty"` // Thresholds crossed while degraded.
+	Since    time.Time `json:"since,omitempty"`   // When degradation started.
+	Shed     []string  `json:"shed,omitempty"`    // Optional work stopped while degraded.
+}
+
+// Monitor samples usage and tracks whether the bidder is degraded.
+type Monitor struct {
+	thresholds Thresholds
+	sample     func() Usage
+	now        func() time.Time
+
+	mu      sync.Mutex
+	state   State
+	changed chan struct{}   // Closed and replaced when degradation starts or ends.
+	work    map[string]bool // Optional work known to the monitor.
+}
+
+// New returns a monitor shedding work above thresholds.
+func New(thresholds Thresholds) *Monitor {
+	return &Monitor{
+		thresholds: thresholds,
+		sample:     sample,
+		now:        time.Now,
+		changed:    make(chan struct{}),
+		work:       make(map[string]bool),
+	}
+}
+
+// Enabled reports whether any threshold is set.
+func (m *Monitor) Enabled() bool {
+	return m != nil && (m.thresholds.MaxRSS > 0 || m.thresholds.MaxGoroutines > 0)
+}
+
+// Run samples usage every interval until ctx is canceled.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m.Check()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check samples usage once and updates the degradation state.
+func (m *Monitor) Check() {
+	u := m.sample()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state.Usage = u
+	if !m.Enabled() {
+		return
+	}
+	limit := 1.0
+	if m.state.Degraded {
+		limit = recoverRatio
+	}
+	var reasons []string
+	if threshold := m.thresholds.MaxRSS; threshold > 0 && float64(u.RSS) > limit*float64(threshold) {
+		reasons = append(reasons, fmt.Sprintf("rss %d MB above %d MB", u.RSS>>20, threshold>>20))
+	}
+	if threshold := m.thresholds.MaxGoroutines; threshold > 0 && float64(u.Goroutines) > limit*float64(threshold) {
+		reasons = append(reasons, fmt.Sprintf("%d goroutines above %d", u.Goroutines, threshold))
+	}
+	degraded := len(reasons) > 0
+	if degraded {
+		m.state.Reasons = reasons
+	}
+	if degraded == m.state.Degraded {
+		return
+	}
+	m.state.Degraded = degraded
+	if degraded {
+		m.state.Since = m.now().UTC()
+		metrics.Degraded.Set(1)
+		slog.Warn("Resource pressure, shedding optional work", "reasons", reasons, "rssBytes", u.RSS, "goroutines", u.Goroutines)
+	} else {
+		slog.Info("Resource pressure cleared, resuming optional work", "degradedFor", m.now().Sub(m.state.Since).Round(time.Second), "rssBytes", u.RSS, "goroutines", u.Goroutines)
+		m.state.Reasons, m.state.Since = nil, time.Time{}
+		metrics.Degraded.Set(0)
+	}
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// Degraded reports whether optional work should be shed. A nil monitor is
+// never degraded.
+func (m *Monitor) Degraded() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state.Degraded
+}
+
+// State returns the latest usage and degradation state.
+func (m *Monitor) State() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.state
+	s.Reasons = append([]string(nil), s.Reasons...)
+	if s.Degraded {
+		for name := range m.work {
+			s.Shed = append(s.Shed, name)
+		}
+		sort.Strings(s.Shed)
+	}
+	return s
+}
+
+// watch registers the optional work name and returns whether the bidder is
+// degraded and a channel closed when that changes.
+func (m *Monitor) watch(name string) (bool, <-chan struct{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.work[name] = true
+	return m.state.Degraded, m.changed
+}
+
+// Allow reports whether the optional work name may run now. A nil monitor
+// allows everything.
+func (m *Monitor) Allow(name string) bool {
+	if m == nil {
+		return true
+	}
+	degraded, _ := m.watch(name)
+	return !degraded
+}
+
+// Optional runs run as the optional work name until ctx is canceled, stopping
+// it while the bidder is degraded: its context is canceled when degradation
+// starts, and it is started again once it ends. Optional returns when run
+// returns on its own.
+func (m *Monitor) Optional(ctx context.Context, name string, run func(context.Context)) {
+	for ctx.Err() == nil {
+		degraded, changed := m.watch(name)
+		if degraded {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+				continue
+			}
+		}
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			run(runCtx)
+		}()
+		select {
+		case <-done:
+			cancel()
+			return
+		case <-changed:
+			cancel()
+			<-done
+			slog.Info("Optional work stopped under resource pressure", "work", name)
+		}
+	}
+}
+
+// sample reads the resident set size from /proc on Linux, and falls back to
+// the memory obtained by the Go runtime elsewhere.
+func sample() Usage {
+	u := Usage{Goroutines: runtime.NumGoroutine()}
+	if data, err := os.ReadFile("/proc/self/statm"); err == nil {
+		if fields := strings.Fields(string(data)); len(fields) > 1 {
+			if pages, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+				u.RSS = pages * uint64(os.Getpagesize())
+				return u
+			}
+		}
+	}
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	u.RSS = ms.Sys
+	return u
+}
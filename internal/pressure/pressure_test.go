@@ -0,0 +1,81 @@
+package pressure
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDegradationWithHysteresis(t *testing.T) {
+	m := New(Thresholds{MaxRSS: 100 << 20, MaxGoroutines: 50})
+	usage := Usage{RSS: 80 << 20, Goroutines: 10}
+	m.sample = func() Usage { return usage }
+
+	m.Check()
+	require.False(t, m.Degraded())
+	require.True(t, m.Allow("market snapshot"))
+
+	usage.RSS = 120 << 20
+	m.Check()
+	require.True(t, m.Degraded())
+	require.False(t, m.Allow("market snapshot"))
+	s := m.State()
+	require.Equal(t, []string{"rss 120 MB above 100 MB"}, s.Reasons)
+	require.Equal(t, []string{"market snapshot"}, s.Shed)
+	require.False(t, s.Since.IsZero())
+
+	// Between 90% and 100% of the threshold the bidder stays degraded
+	usage.RSS = 95 << 20
+	m.Check()
+	require.True(t, m.Degraded())
+
+	usage.RSS = 85 << 20
+	m.Check()
+	require.False(t, m.Degraded())
+	require.Empty(t, m.State().Shed)
+}
+
+func TestDisabledNeverDegrades(t *testing.T) {
+	m := New(Thresholds{})
+	m.sample = func() Usage { return Usage{RSS: 1 << 40, Goroutines: 1 << 20} }
+	m.Check()
+	require.False(t, m.Enabled())
+	require.False(t, m.Degraded())
+	require.Equal(t, 1<<20, m.State().Goroutines)
+	require.True(t, (*Monitor)(nil).Allow("telemetry"))
+}
+
+func TestOptionalStopsAndRestarts(t *testing.T) {
+	m := New(Thresholds{MaxGoroutines: 50})
+	var goroutines atomic.Int64
+	m.sample = func() Usage { return Usage{Goroutines: int(goroutines.Load())} }
+
+	var starts, stops atomic.Int64
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Optional(ctx, "observer", func(ctx context.Context) {
+			starts.Add(1)
+			<-ctx.Done()
+			stops.Add(1)
+		})
+	}()
+	require.Eventually(t, func() bool { return starts.Load() == 1 }, time.Second, time.Millisecond)
+
+	goroutines.Store(100)
+	m.Check()
+	require.Eventually(t, func() bool { return stops.Load() == 1 }, time.Second, time.Millisecond)
+	require.Equal(t, int64(1), starts.Load(), "not restarted while degraded")
+
+	goroutines.Store(10)
+	m.Check()
+	require.Eventually(t, func() bool { return starts.Load() == 2 }, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+	require.Equal(t, int64(2), stops.Load())
+}
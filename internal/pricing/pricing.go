@@ -0,0 +1,110 @@
+// Package pricing adapts the bid amount to the observed commitment rate. The
+// controller raises its scale after every bid that receives no commitment and
+// lowers it after every committed bid, with steps weighted so the scale
+// settles where the commitment rate matches the target.
+package pricing
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+// Defaults for Config.
+const (
+	DefaultTargetRate = 0.8
+	DefaultStep       = 0.05
+	DefaultMinScale   = 0.5
+	DefaultMaxScale   = 4.0
+	DefaultWindow     = 50
+)
+
+// Config configures a Controller.
+type Config struct {
+	TargetRate float64 // Share of bids that should receive a commitment, in (0, 1).
+	Step       float64 // Log-scale step per observed bid.
+	MinScale   float64 // Lowest multiple of the configured bid amount.
+	MaxScale   float64 // Highest multiple of the configured bid amount.
+}
+
+// Validate checks that the configuration describes a working controller.
+func (c Config) Validate() error {
+	switch {
+	case c.TargetRate <= 0 || c.TargetRate >= 1:
+		return fmt.Errorf("target rate must be between 0 and 1, exclusive")
+	case c.Step <= 0 || c.Step > 1:
+		return fmt.Errorf("step must be above 0 and at most 1")
+	case c.MinScale <= 0 || c.MaxScale < c.MinScale:
+		return fmt.Errorf("scales must be positive with the minimum not above the maximum")
+	case c.MinScale > 1 || c.MaxScale < 1:
+		return fmt.Errorf("scales must include 1, the configured bid amount")
+	}
+	return nil
+}
+
+// Controller holds the current bid scale.
+type Controller struct {
+	cfg Config
+
+	mu     sync.Mutex
+	scale  float64
+	recent []float64 // Outcomes of the last DefaultWindow bids, oldest first.
+}
+
+// NewController returns a controller starting at scale 1.
+func NewController(cfg Config) *Controller {
+	metrics.BidScale.Set(1)
+	return &Controller{cfg: cfg, scale: 1}
+}
+
+// Scale returns the multiplier to apply to the configured bid amount.
+func (c *Controller) Scale() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.scale
+}
+
+// Observe adjusts the scale for the outcome of a bid. An uncommitted bid
+// raises the scale by Step*TargetRate and a committed one lowers it by
+// Step*(1-TargetRate), in log space, so the scale is stable exactly when the
+// commitment rate equals the target.
+func (c *Controller) Observe(committed bool) {
+	outcome := 0.0
+	if committed {
+		outcome = 1
+	}
+	c.ObserveWeighted(outcome)
+}
+
+// ObserveWeighted adjusts the scale for a bid whose commitments count as
+// outcome, from 0 for none to 1 for a commitment that counts fully. Between
+// the two the scale moves as for a partly committed bid.
+func (c *Controller) ObserveWeighted(outcome float64) {
+	outcome = math.Min(math.Max(outcome, 0), 1)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scale *= math.Exp(c.cfg.Step * (c.cfg.TargetRate - outcome))
+	c.scale = math.Min(math.Max(c.scale, c.cfg.MinScale), c.cfg.MaxScale)
+	c.recent = append(c.recent, outcome)
+	if len(c.recent) > DefaultWindow {
+		c.recent = c.recent[1:]
+	}
+	metrics.BidScale.Set(c.scale)
+}
+
+// RecentRate returns the commitment rate of the last bids observed, up to
+// DefaultWindow of them, with weighted outcomes counted as observed.
+func (c *Controller) RecentRate() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.recent) == 0 {
+		return 0
+	}
+	committed := 0.0
+	for _, outcome := range c.recent {
+		committed += outcome
+	}
+	return committed / float64(len(c.recent))
+}
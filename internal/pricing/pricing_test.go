@@ -0,0 +1,72 @@
+package pricing
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() Config {
+	return Config{TargetRate: DefaultTargetRate, Step: DefaultStep, MinScale: DefaultMinScale, MaxScale: DefaultMaxScale}
+}
+
+func TestControllerConvergesOnTargetRate(t *testing.T) {
+	c := NewController(testConfig())
+
+	// Uncommitted bids raise the scale, committed ones lower it
+	c.Observe(false)
+	raised := c.Scale()
+	require.Greater(t, raised, 1.0)
+	c.Observe(true)
+	require.Less(t, c.Scale(), raised)
+
+	// Providers commit with a probability growing with the bid; the
+	// controller settles where the rate matches the target
+	rng := rand.New(rand.NewSource(1))
+	accept := func(scale float64) bool { return rng.Float64() < scale/2.5 }
+	committed := 0
+	for i := 0; i < 4000; i++ {
+		ok := accept(c.Scale())
+		c.Observe(ok)
+		if i >= 2000 && ok {
+			committed++
+		}
+	}
+	require.InDelta(t, DefaultTargetRate, float64(committed)/2000, 0.05)
+	require.InDelta(t, 2.0, c.Scale(), 0.4)
+}
+
+func TestControllerStaysWithinBounds(t *testing.T) {
+	c := NewController(testConfig())
+	for i := 0; i < 1000; i++ {
+		c.Observe(false)
+	}
+	require.Equal(t, DefaultMaxScale, c.Scale())
+	require.Zero(t, c.RecentRate())
+	for i := 0; i < 1000; i++ {
+		c.Observe(true)
+	}
+	require.Equal(t, DefaultMinScale, c.Scale())
+	require.Equal(t, 1.0, c.RecentRate())
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, testConfig().Validate())
+	bad := testConfig()
+	bad.TargetRate = 1
+	require.Error(t, bad.Validate())
+	bad = testConfig()
+	bad.MinScale = 2
+	require.Error(t, bad.Validate())
+}
+
+func TestWeightedOutcomes(t *testing.T) {
+	c := NewController(Config{TargetRate: 0.5, Step: 0.1, MinScale: 0.5, MaxScale: 4})
+	c.ObserveWeighted(0.5)
+	require.InDelta(t, 1, c.Scale(), 1e-9, "a half-counted commitment at a 50% target keeps the scale")
+	c.ObserveWeighted(0.25)
+	require.Greater(t, c.Scale(), 1.0)
+	c.ObserveWeighted(2)
+	require.InDelta(t, (0.5+0.25+1)/3, c.RecentRate(), 1e-9, "outcomes are capped at 1")
+}
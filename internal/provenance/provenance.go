@@ -0,0 +1,73 @@
+// Package provenance signs bids with the key of the account that bids, so the
+// bid history is a non-repudiable record of what the bidder bid. The bidder
+// API has no field for such a signature, so it is kept in the history only.
+//
+// A signature is an EIP-191 personal signature over the text of Inputs.Message,
+// which standard tools such as cast wallet verify can check as well.
+package provenance
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrUnsigned is returned by Verify for a bid without a signature, e.g. one
+// bid on a pre-signed transaction without a key.
+var ErrUnsigned = errors.New("bid is not signed")
+
+// Inputs are what the signature of a bid covers.
+type Inputs struct {
+	Bidder      common.Address // Account signing the bid.
+	TxHash      string
+	BlockNumber uint64
+	AmountWei   string
+	DecayStart  int64 // Unix milliseconds.
+	DecayEnd    int64 // Unix milliseconds.
+}
+
+// Message is the text that is signed.
+func (in Inputs) Message() string {
+	return fmt.Sprintf("mev-commit bid v1\nbidder: %s\ntx: %s\nblock: %d\namount_wei: %s\ndecay_ms: %d-%d",
+		in.Bidder.Hex(), strings.ToLower(in.TxHash), in.BlockNumber, in.AmountWei, in.DecayStart, in.DecayEnd)
+}
+
+// Sign signs in with key, which must be the key of in.Bidder, and returns the
+// hex encoded signature.
+func Sign(key *ecdsa.PrivateKey, in Inputs) (string, error) {
+	if crypto.PubkeyToAddress(key.PublicKey) != in.Bidder {
+		return "", fmt.Errorf("key does not belong to bidder %s", in.Bidder.Hex())
+	}
+	sig, err := crypto.Sign(accounts.TextHash([]byte(in.Message())), key)
+	if err != nil {
+		return "", err
+	}
+	sig[crypto.RecoveryIDOffset] += 27 // Ethereum's v, as eth_sign returns it
+	return hexutil.Encode(sig), nil
+}
+
+// Verify checks that signature is in.Bidder's signature of in.
+func Verify(in Inputs, signature string) error {
+	if signature == "" {
+		return ErrUnsigned
+	}
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return fmt.Errorf("malformed signature")
+	}
+	sig[crypto.RecoveryIDOffset] -= 27
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte(in.Message())), sig)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pub); signer != in.Bidder {
+		return fmt.Errorf("signed by %s, not the bidder %s", signer.Hex(), in.Bidder.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package provenance
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	in := Inputs{
+		Bidder:      crypto.PubkeyToAddress(key.PublicKey),
+		TxHash:      "0xAB01",
+		BlockNumber: 100,
+		AmountWei:   "1000000000000000",
+		DecayStart:  1_700_000_000_000,
+		DecayEnd:    1_700_000_012_000,
+	}
+	sig, err := Sign(key, in)
+	require.NoError(t, err)
+	require.NoError(t, Verify(in, sig))
+
+	in.TxHash = "0xab01"
+	require.NoError(t, Verify(in, sig), "hashes are compared in lower case")
+
+	tampered := in
+	tampered.AmountWei = "2000000000000000"
+	require.ErrorContains(t, Verify(tampered, sig), "not the bidder")
+
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	_, err = Sign(other, in)
+	require.ErrorContains(t, err, "does not belong to bidder")
+
+	require.ErrorIs(t, Verify(in, ""), ErrUnsigned)
+	require.ErrorContains(t, Verify(in, "0x1234"), "malformed signature")
+	require.ErrorContains(t, Verify(Inputs{Bidder: common.Address{1}}, sig), "not the bidder")
+}
@@ -0,0 +1,256 @@
+// Package providers scores the providers committing to our bids by their stake
+// in the ProviderRegistry and their commit rate. The adaptive pricing
+// controller counts a commitment by the weight of its best provider, so with
+// a weighting other than none it keeps raising bids until well-staked,
+// reliable providers commit, rather than settling once any provider does.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/primev/preconf_blob_bidder/internal/feedback"
+	"github.com/primev/preconf_blob_bidder/internal/ident"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+	"github.com/primev/preconf_blob_bidder/internal/money"
+)
+
+// DefaultInterval is how often stakes are read again.
+const DefaultInterval = 10 * time.Minute
+
+// Weighting is how a provider's stake and commit rate weigh its commitments.
+type Weighting string
+
+// Weightings.
+const (
+	None        Weighting = "none"              // Every commitment counts fully.
+	Stake       Weighting = "stake"             // Stake relative to the best-staked provider.
+	Reliability Weighting = "reliability"       // Commit rate of the provider.
+	Both        Weighting = "stake-reliability" // Product of both.
+)
+
+// Weightings are the valid weightings.
+var Weightings = []Weighting{None, Stake, Reliability, Both}
+
+// Valid reports whether w is a known weighting.
+func (w Weighting) Valid() bool {
+	for _, known := range Weightings {
+		if w == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Score is what the weighting of a provider is based on.
+type Score struct {
+	Provider   string   `json:"provider"`
+	Stake      *big.Int `json:"stake_wei,omitempty"`   // Nil until read.
+	CommitRate *float64 `json:"commit_rate,omitempty"` // Nil until a bid expired.
+	Weight     float64  `json:"weight"`
+}
+
+// weigh returns the weight of s, with maxStake the largest stake read. An
+// unknown stake or commit rate counts fully, so new providers are not
+// penalized before they are known.
+func (w Weighting) weigh(s Score, maxStake *big.Int) float64 {
+	stake, rate := 1.0, 1.0
+	if s.Stake != nil && maxStake != nil && maxStake.Sign() > 0 {
+		stake, _ = new(big.Rat).SetFrac(s.Stake, maxStake).Float64()
+	}
+	if s.CommitRate != nil {
+		rate = *s.CommitRate
+	}
+	switch w {
+	case Stake:
+		return stake
+	case Reliability:
+		return rate
+	case Both:
+		return stake * rate
+	}
+	return 1
+}
+
+// StakeFunc reads the stake of a provider.
+type StakeFunc func(ctx context.Context, provider common.Address) (*big.Int, error)
+
+// Scoreboard weighs providers by their stake and commit rate.
+type Scoreboard struct {
+	weighting Weighting
+	stats     func() []feedback.ProviderStats
+	stake     StakeFunc
+
+	mu     sync.Mutex
+	stakes map[string]*big.Int // By lower case address.
+}
+
+// New returns a scoreboard weighing providers by weighting, with their commit
+// rates from stats and their stakes read with stake.
+func New(weighting Weighting, stats func() []feedback.ProviderStats, stake StakeFunc) *Scoreboard {
+	return &Scoreboard{
+		weighting: weighting,
+		stats:     stats,
+		stake:     stake,
+		stakes:    make(map[string]*big.Int),
+	}
+}
+
+// Run reads the stakes of the known providers every interval until ctx is
+// canceled.
+func (s *Scoreboard) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh reads the stakes of the providers that committed to our bids.
+func (s *Scoreboard) Refresh(ctx context.Context) {
+	for _, p := range s.stats() {
+		if !common.IsHexAddress(p.Provider) {
+			continue
+		}
+		stake, err := s.stake(ctx, common.HexToAddress(p.Provider))
+		if err != nil {
+			slog.Debug("Failed to read provider stake", "error", err, "provider", p.Provider)
+			continue
+		}
+		s.mu.Lock()
+		s.stakes[strings.ToLower(p.Provider)] = stake
+		s.mu.Unlock()
+		metrics.ProviderStake.WithLabelValues(p.Provider).Set(money.ToEth(stake))
+	}
+	for _, score := range s.Scores() {
+		metrics.ProviderWeight.WithLabelValues(score.Provider).Set(score.Weight)
+	}
+}
+
+// Scores returns the score of every provider that committed to our bids, by
+// address.
+func (s *Scoreboard) Scores() []Score {
+	stats := s.stats()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scores := make([]Score, 0, len(stats))
+	for _, p := range stats {
+		score := Score{Provider: p.Provider, Stake: s.stakes[strings.ToLower(p.Provider)]}
+		if p.Bids > 0 {
+			rate := p.CommitRate
+			score.CommitRate = &rate
+		}
+		scores = append(scores, score)
+	}
+	maxStake := s.maxStake()
+	for i := range scores {
+		scores[i].Weight = s.weighting.weigh(scores[i], maxStake)
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].Provider < scores[j].Provider })
+	return scores
+}
+
+// maxStake returns the largest stake read. s.mu must be held.
+func (s *Scoreboard) maxStake() *big.Int {
+	var largest *big.Int
+	for _, stake := range s.stakes {
+		if largest == nil || stake.Cmp(largest) > 0 {
+			largest = stake
+		}
+	}
+	return largest
+}
+
+// Weight returns how much a bid committed to by providers counts: the weight
+// of the best of them, 0 without any.
+func (s *Scoreboard) Weight(providers []string) float64 {
+	if len(providers) == 0 {
+		return 0
+	}
+	if s == nil || s.weighting == None {
+		return 1
+	}
+	weights := make(map[string]float64)
+	for _, score := range s.Scores() {
+		weights[strings.ToLower(score.Provider)] = score.Weight
+	}
+	best := 0.0
+	for _, provider := range providers {
+		w, ok := weights[strings.ToLower(provider)]
+		if !ok {
+			// Not yet in the statistics, see weigh
+			w = s.weighting.weigh(Score{Provider: provider}, nil)
+		}
+		if w > best {
+			best = w
+		}
+	}
+	return best
+}
+
+// registryABI holds the views reading provider stakes: the PreconfManager's
+// provider registry and the registry's stakes.
+const registryABI = `[
+	{"type": "function", "name": "providerRegistry", "stateMutability": "view", "inputs": [], "outputs": [{"name": "", "type": "address"}]},
+	{"type": "function", "name": "providerStakes", "stateMutability": "view", "inputs": [{"name": "", "type": "address"}], "outputs": [{"name": "", "type": "uint256"}]}
+]`
+
+var registry = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(registryABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// RegistryStake returns a StakeFunc reading stakes from the ProviderRegistry
+// of the PreconfManager contract at preconfManager, through the mev-commit
+// chain endpoint. The registry address is looked up on the first read.
+func RegistryStake(endpoint string, preconfManager common.Address) StakeFunc {
+	var (
+		mu           sync.Mutex
+		registryAddr *common.Address
+	)
+	return func(ctx context.Context, provider common.Address) (*big.Int, error) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		client, err := ident.DialEth(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		defer client.Close()
+		opts := &bind.CallOpts{Context: ctx}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if registryAddr == nil {
+			var out []interface{}
+			manager := bind.NewBoundContract(preconfManager, registry, client, nil, nil)
+			if err := manager.Call(opts, &out, "providerRegistry"); err != nil {
+				return nil, fmt.Errorf("failed to look up the provider registry: %w", err)
+			}
+			addr := out[0].(common.Address)
+			registryAddr = &addr
+		}
+		var out []interface{}
+		providerRegistry := bind.NewBoundContract(*registryAddr, registry, client, nil, nil)
+		if err := providerRegistry.Call(opts, &out, "providerStakes", provider); err != nil {
+			return nil, fmt.Errorf("failed to read provider stake: %w", err)
+		}
+		return out[0].(*big.Int), nil
+	}
+}
@@ -0,0 +1,67 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/primev/preconf_blob_bidder/internal/feedback"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	wellStaked = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	lowStaked  = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	unread     = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+)
+
+func scoreboard(weighting Weighting) *Scoreboard {
+	stats := func() []feedback.ProviderStats {
+		return []feedback.ProviderStats{
+			{Provider: wellStaked.Hex(), Bids: 10, Committed: 5, CommitRate: 0.5},
+			{Provider: lowStaked.Hex(), Bids: 10, Committed: 10, CommitRate: 1},
+			{Provider: unread.Hex(), Bids: 10, Committed: 2, CommitRate: 0.2},
+		}
+	}
+	stakes := map[common.Address]*big.Int{wellStaked: big.NewInt(40), lowStaked: big.NewInt(10)}
+	stake := func(_ context.Context, provider common.Address) (*big.Int, error) {
+		if s, ok := stakes[provider]; ok {
+			return s, nil
+		}
+		return nil, errors.New("execution reverted")
+	}
+	s := New(weighting, stats, stake)
+	s.Refresh(context.Background())
+	return s
+}
+
+func TestWeightings(t *testing.T) {
+	for _, tc := range []struct {
+		weighting                   Weighting
+		wellStaked, lowStaked, both float64
+	}{
+		{None, 1, 1, 1},
+		{Stake, 1, 0.25, 1},
+		{Reliability, 0.5, 1, 1},
+		{Both, 0.5, 0.25, 0.5},
+	} {
+		s := scoreboard(tc.weighting)
+		require.Equal(t, tc.wellStaked, s.Weight([]string{wellStaked.Hex()}), tc.weighting)
+		require.Equal(t, tc.lowStaked, s.Weight([]string{lowStaked.Hex()}), tc.weighting)
+		require.Equal(t, tc.both, s.Weight([]string{lowStaked.Hex(), wellStaked.Hex()}), "the best provider counts")
+		require.Zero(t, s.Weight(nil), "no commitment")
+	}
+}
+
+func TestUnknownCountsFully(t *testing.T) {
+	s := scoreboard(Both)
+	scores := s.Scores()
+	require.Len(t, scores, 3)
+	require.Nil(t, scores[2].Stake, "stake could not be read")
+	require.Equal(t, 0.2, scores[2].Weight, "only the commit rate weighs")
+	require.Equal(t, 1.0, s.Weight([]string{"0x00000000000000000000000000000000000000dd"}), "not in the statistics yet")
+	require.Equal(t, 1.0, (*Scoreboard)(nil).Weight([]string{wellStaked.Hex()}))
+	require.False(t, Weighting("stake-only").Valid())
+}
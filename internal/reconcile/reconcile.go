@@ -0,0 +1,160 @@
+// Package reconcile finds, on startup, the transactions of our accounts that
+// a previous run left behind: those included in the last blocks, and those
+// still waiting in the node's mempool. The bidder adopts the waiting ones, so
+// its nonces follow them and their inclusion is recognized as ours, and does
+// not bid again for pre-signed transactions that already landed. Running it
+// twice finds the same transactions, so restarts in quick succession are
+// harmless.
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultBlocks is how many blocks back from the head are scanned.
+const DefaultBlocks = 32
+
+// Tx is a transaction of one of our accounts.
+type Tx struct {
+	Hash  string
+	From  common.Address
+	Nonce uint64
+	Block uint64 // 0 while in the mempool.
+}
+
+// Account is what was found for one account.
+type Account struct {
+	Address common.Address
+	Pending uint64 // The node's pending nonce.
+	Landed  []Tx   // Included in the scanned blocks, by nonce.
+	Waiting []Tx   // In the node's mempool, by nonce.
+}
+
+// Result is what reconciliation found.
+type Result struct {
+	From, To uint64 // Scanned blocks, inclusive.
+	Accounts []Account
+}
+
+// Landed reports whether the transaction with hash was included in the
+// scanned blocks.
+func (r Result) Landed(hash string) bool {
+	for _, a := range r.Accounts {
+		for _, tx := range a.Landed {
+			if strings.EqualFold(tx.Hash, hash) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Chain is what reconciliation reads from the node.
+type Chain interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// Mempool returns the transactions of account waiting in the node's mempool.
+type Mempool func(ctx context.Context, account common.Address) ([]*types.Transaction, error)
+
+// Run scans the last blocks blocks and the mempool for transactions of
+// accounts. A mempool that cannot be read, as on nodes without the txpool
+// API, is logged and skipped.
+func Run(ctx context.Context, chain Chain, mempool Mempool, accounts []common.Address, blocks uint64) (Result, error) {
+	head, err := chain.BlockNumber(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to fetch the head: %w", err)
+	}
+	r := Result{To: head}
+	if blocks > head+1 {
+		blocks = head + 1
+	}
+	r.From = head + 1 - blocks
+
+	ours := make(map[common.Address]int, len(accounts))
+	for i, addr := range accounts {
+		ours[addr] = i
+		r.Accounts = append(r.Accounts, Account{Address: addr})
+	}
+	for n := r.From; n <= head; n++ {
+		block, err := chain.BlockByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to fetch block %d: %w", n, err)
+		}
+		for _, tx := range block.Transactions() {
+			from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+			if err != nil {
+				continue
+			}
+			if i, ok := ours[from]; ok {
+				r.Accounts[i].Landed = append(r.Accounts[i].Landed, Tx{Hash: tx.Hash().Hex(), From: from, Nonce: tx.Nonce(), Block: n})
+			}
+		}
+	}
+	for i := range r.Accounts {
+		a := &r.Accounts[i]
+		sort.Slice(a.Landed, func(i, j int) bool { return a.Landed[i].Nonce < a.Landed[j].Nonce })
+		if a.Pending, err = chain.PendingNonceAt(ctx, a.Address); err != nil {
+			return Result{}, fmt.Errorf("failed to fetch the pending nonce of %s: %w", a.Address.Hex(), err)
+		}
+		if mempool == nil {
+			continue
+		}
+		waiting, err := mempool(ctx, a.Address)
+		if err != nil {
+			slog.Debug("Failed to read the mempool for reconciliation", "error", err, "account", a.Address.Hex())
+			continue
+		}
+		for _, tx := range waiting {
+			a.Waiting = append(a.Waiting, Tx{Hash: tx.Hash().Hex(), From: a.Address, Nonce: tx.Nonce()})
+		}
+		sort.Slice(a.Waiting, func(i, j int) bool { return a.Waiting[i].Nonce < a.Waiting[j].Nonce })
+	}
+	landed, waiting := 0, 0
+	for _, a := range r.Accounts {
+		landed += len(a.Landed)
+		waiting += len(a.Waiting)
+	}
+	slog.Info("Reconciled transactions of a previous run",
+		"fromBlock", r.From,
+		"toBlock", r.To,
+		"accounts", len(r.Accounts),
+		"landed", landed,
+		"waiting", waiting,
+	)
+	return r, nil
+}
+
+// TxpoolContent returns a Mempool reading the txpool_contentFrom method of
+// the node at client, both pending and queued transactions.
+func TxpoolContent(client *rpc.Client) Mempool {
+	return func(ctx context.Context, account common.Address) ([]*types.Transaction, error) {
+		var content map[string]map[string]json.RawMessage
+		if err := client.CallContext(ctx, &content, "txpool_contentFrom", account); err != nil {
+			return nil, err
+		}
+		var txs []*types.Transaction
+		for _, pool := range []string{"pending", "queued"} {
+			for _, raw := range content[pool] {
+				tx := new(types.Transaction)
+				if err := tx.UnmarshalJSON(raw); err != nil {
+					return nil, fmt.Errorf("failed to decode %s transaction: %w", pool, err)
+				}
+				txs = append(txs, tx)
+			}
+		}
+		return txs, nil
+	}
+}
@@ -0,0 +1,92 @@
+package reconcile
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type chain struct {
+	blocks  map[uint64]*types.Block
+	head    uint64
+	pending uint64
+}
+
+func (c *chain) BlockNumber(context.Context) (uint64, error) { return c.head, nil }
+
+func (c *chain) BlockByNumber(_ context.Context, number *big.Int) (*types.Block, error) {
+	if b, ok := c.blocks[number.Uint64()]; ok {
+		return b, nil
+	}
+	return types.NewBlockWithHeader(&types.Header{Number: number}), nil
+}
+
+func (c *chain) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return c.pending, nil
+}
+
+func TestRun(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	ours := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	sign := func(k *ecdsa.PrivateKey, nonce uint64) *types.Transaction {
+		tx, err := types.SignNewTx(k, signer, &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: nonce})
+		require.NoError(t, err)
+		return tx
+	}
+
+	old, landed, theirs := sign(key, 1), sign(key, 3), sign(other, 3)
+	waiting := []*types.Transaction{sign(key, 5), sign(key, 4)}
+	c := &chain{head: 100, pending: 4, blocks: map[uint64]*types.Block{
+		// Before the scanned blocks
+		90: types.NewBlockWithHeader(&types.Header{Number: big.NewInt(90)}).
+			WithBody(types.Body{Transactions: types.Transactions{old}}),
+		99: types.NewBlockWithHeader(&types.Header{Number: big.NewInt(99)}).
+			WithBody(types.Body{Transactions: types.Transactions{theirs, landed}}),
+	}}
+	mempool := func(_ context.Context, account common.Address) ([]*types.Transaction, error) {
+		require.Equal(t, ours, account)
+		return waiting, nil
+	}
+
+	r, err := Run(context.Background(), c, mempool, []common.Address{ours}, 5)
+	require.NoError(t, err)
+	require.Equal(t, uint64(96), r.From)
+	require.Equal(t, uint64(100), r.To)
+	require.Len(t, r.Accounts, 1)
+	a := r.Accounts[0]
+	require.Equal(t, uint64(4), a.Pending)
+	require.Equal(t, []Tx{{Hash: landed.Hash().Hex(), From: ours, Nonce: 3, Block: 99}}, a.Landed)
+	require.Len(t, a.Waiting, 2)
+	require.Equal(t, uint64(4), a.Waiting[0].Nonce, "sorted by nonce")
+	require.True(t, r.Landed(landed.Hash().Hex()))
+	require.False(t, r.Landed(old.Hash().Hex()))
+	require.False(t, r.Landed(theirs.Hash().Hex()))
+
+	// Running again finds the same
+	again, err := Run(context.Background(), c, mempool, []common.Address{ours}, 5)
+	require.NoError(t, err)
+	require.Equal(t, r, again)
+}
+
+func TestRunWithoutMempool(t *testing.T) {
+	c := &chain{head: 2, pending: 7}
+	failing := func(context.Context, common.Address) ([]*types.Transaction, error) {
+		return nil, errors.New("the method txpool_contentFrom does not exist")
+	}
+	r, err := Run(context.Background(), c, failing, []common.Address{{1}}, DefaultBlocks)
+	require.NoError(t, err)
+	require.Zero(t, r.From, "not scanned past genesis")
+	require.Equal(t, uint64(7), r.Accounts[0].Pending)
+	require.Empty(t, r.Accounts[0].Waiting)
+}
@@ -0,0 +1,311 @@
+// Package schedule runs bidding campaigns registered ahead of time. A campaign
+// has a start and end time and funding prerequisites, which are checked from
+// a lead time before the start on, so a missing deposit or balance is
+// reported while there is still time to fund it. The campaign starts by
+// itself once its start time has come and its prerequisites are met; the
+// bidder bids only while a campaign runs.
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+const (
+	// DefaultLead is how long before its start a campaign's prerequisites are
+	// first checked.
+	DefaultLead = 15 * time.Minute
+	// DefaultInterval is how often campaigns are checked and started.
+	DefaultInterval = 30 * time.Second
+)
+
+// State is where a campaign is in its life.
+type State string
+
+const (
+	Scheduled State = "scheduled" // Waiting for the lead time before the start.
+	Ready     State = "ready"     // Prerequisites met, waiting for the start.
+	Blocked   State = "blocked"   // Prerequisites not met; started once they are.
+	Running   State = "running"   // Bidding.
+	Ended     State = "ended"     // The end time has passed.
+	Canceled  State = "canceled"  // Canceled through the API.
+)
+
+// final reports whether a campaign in state s is done.
+func (s State) final() bool {
+	return s == Ended || s == Canceled
+}
+
+// Campaign is a bidding period with the funds it needs.
+type Campaign struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name,omitempty"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	MinBalanceWei *big.Int  `json:"min_balance_wei,omitempty"` // Balance every bidding account needs.
+	MinDepositWei *big.Int  `json:"min_deposit_wei,omitempty"` // Deposit needed in the bidding window of the start.
+
+	State     State     `json:"state"`
+	Problems  []string  `json:"problems,omitempty"` // Unmet prerequisites found by the last check.
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+}
+
+// Check returns the unmet prerequisites of c, none when it can start.
+type Check func(ctx context.Context, c Campaign) []string
+
+// Scheduler holds the registered campaigns and moves them through their
+// states. Campaigns are kept in a JSON file, so they survive restarts.
+type Scheduler struct {
+	path  string
+	check Check
+	lead  time.Duration
+	now   func() time.Time
+
+	mu        sync.Mutex
+	campaigns []*Campaign
+}
+
+// Open loads the campaigns of the file at path, which need not exist yet.
+// Prerequisites are checked with check from lead before a campaign's start.
+func Open(path string, check Check, lead time.Duration) (*Scheduler, error) {
+	s := &Scheduler{path: path, check: check, lead: lead, now: time.Now}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read campaign schedule: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.campaigns); err != nil {
+			return nil, fmt.Errorf("corrupt campaign schedule %s: %w", path, err)
+		}
+	}
+	return s, nil
+}
+
+// Add registers a campaign and returns it with its ID. Campaigns must end in
+// the future and may not overlap with another campaign that is not done.
+func (s *Scheduler) Add(c Campaign) (Campaign, error) {
+	if c.Start.IsZero() || c.End.IsZero() {
+		return Campaign{}, errors.New("start and end are required")
+	}
+	if !c.End.After(c.Start) {
+		return Campaign{}, errors.New("end must be after start")
+	}
+	if !c.End.After(s.now()) {
+		return Campaign{}, errors.New("end must be in the future")
+	}
+	if c.MinBalanceWei != nil && c.MinBalanceWei.Sign() < 0 || c.MinDepositWei != nil && c.MinDepositWei.Sign() < 0 {
+		return Campaign{}, errors.New("min_balance_wei and min_deposit_wei must not be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, other := range s.campaigns {
+		if !other.State.final() && c.Start.Before(other.End) && other.Start.Before(c.End) {
+			return Campaign{}, fmt.Errorf("overlaps with campaign %s", other.ID)
+		}
+	}
+	c.ID = strconv.Itoa(len(s.campaigns) + 1)
+	c.State, c.Problems, c.CheckedAt = Scheduled, nil, time.Time{}
+	s.campaigns = append(s.campaigns, &c)
+	slog.Info("Campaign scheduled", "campaign", c.ID, "name", c.Name, "start", c.Start, "end", c.End)
+	return c, s.save()
+}
+
+// Cancel cancels the campaign with id, stopping it if it runs.
+func (s *Scheduler) Cancel(id string) (Campaign, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.campaigns {
+		if c.ID != id {
+			continue
+		}
+		if c.State.final() {
+			return *c, fmt.Errorf("campaign %s is already %s", id, c.State)
+		}
+		c.State = Canceled
+		slog.Info("Campaign canceled", "campaign", c.ID, "name", c.Name)
+		s.updateMetrics()
+		return *c, s.save()
+	}
+	return Campaign{}, fmt.Errorf("no campaign %s", id)
+}
+
+// List returns a copy of every campaign, in the order they were added.
+func (s *Scheduler) List() []Campaign {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Campaign, 0, len(s.campaigns))
+	for _, c := range s.campaigns {
+		out = append(out, *c)
+	}
+	return out
+}
+
+// Running returns the running campaign, if any.
+func (s *Scheduler) Running() (Campaign, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.campaigns {
+		if c.State == Running {
+			return *c, true
+		}
+	}
+	return Campaign{}, false
+}
+
+// Tick ends campaigns past their end, checks the prerequisites of campaigns
+// within the lead time of their start, and starts those that are due and
+// ready. A campaign that turns out not to be fundable is logged as a warning
+// once, and again should it recover.
+func (s *Scheduler) Tick(ctx context.Context) {
+	now := s.now()
+	for _, c := range s.List() {
+		if c.State.final() || c.State == Running && now.Before(c.End) || now.Before(c.Start.Add(-s.lead)) {
+			continue
+		}
+		var problems []string
+		if now.Before(c.End) {
+			problems = s.check(ctx, c)
+		}
+		s.advance(c.ID, now, problems)
+	}
+}
+
+// advance moves the campaign with id on after a check at now found problems.
+func (s *Scheduler) advance(id string, now time.Time, problems []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var c *Campaign
+	for _, candidate := range s.campaigns {
+		if candidate.ID == id {
+			c = candidate
+		}
+	}
+	// Canceled while its prerequisites were checked
+	if c == nil || c.State.final() {
+		return
+	}
+	attrs := []interface{}{"campaign", c.ID, "name", c.Name, "start", c.Start, "end", c.End}
+	switch {
+	case !now.Before(c.End):
+		if c.State == Running {
+			slog.Info("Campaign ended", attrs...)
+		} else {
+			slog.Warn("Campaign ended without starting", append(attrs, "problems", c.Problems)...)
+		}
+		c.State = Ended
+	case len(problems) > 0:
+		if c.State != Blocked {
+			slog.Warn("Campaign prerequisites not met", append(attrs, "problems", problems, "startsIn", c.Start.Sub(now).Round(time.Second))...)
+		}
+		c.State = Blocked
+	case !now.Before(c.Start):
+		slog.Info("Campaign started", attrs...)
+		c.State = Running
+	default:
+		if c.State == Blocked {
+			slog.Info("Campaign prerequisites met", attrs...)
+		}
+		c.State = Ready
+	}
+	c.Problems, c.CheckedAt = problems, now
+	s.updateMetrics()
+	if err := s.save(); err != nil {
+		slog.Warn("Failed to save campaign schedule", "error", err, "file", s.path)
+	}
+}
+
+// updateMetrics exports the number of campaigns per state. s.mu must be held.
+func (s *Scheduler) updateMetrics() {
+	counts := make(map[State]int)
+	for _, c := range s.campaigns {
+		counts[c.State]++
+	}
+	for _, state := range []State{Scheduled, Ready, Blocked, Running, Ended, Canceled} {
+		metrics.Campaigns.WithLabelValues(string(state)).Set(float64(counts[state]))
+	}
+}
+
+// save atomically replaces the schedule file. s.mu must be held.
+func (s *Scheduler) save() error {
+	data, err := json.MarshalIndent(s.campaigns, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Run ticks every interval until ctx is canceled.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	s.mu.Lock()
+	s.updateMetrics()
+	s.mu.Unlock()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.Tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ServeList serves every campaign as JSON.
+func (s *Scheduler) ServeList(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"campaigns": s.List()})
+}
+
+// ServeAdd registers the campaign in the request body.
+func (s *Scheduler) ServeAdd(w http.ResponseWriter, r *http.Request) {
+	var c Campaign
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid campaign: " + err.Error()})
+		return
+	}
+	added, err := s.Add(c)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusCreated, added)
+}
+
+// ServeCancel cancels the campaign named by the id path value.
+func (s *Scheduler) ServeCancel(w http.ResponseWriter, r *http.Request) {
+	c, err := s.Cancel(r.PathValue("id"))
+	if err != nil {
+		status := http.StatusConflict
+		if c.ID == "" {
+			status = http.StatusNotFound
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,117 @@
+package schedule
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "campaigns.json")
+	var problems []string
+	checks := 0
+	s, err := Open(path, func(context.Context, Campaign) []string {
+		checks++
+		return problems
+	}, 10*time.Minute)
+	require.NoError(t, err)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	c, err := s.Add(Campaign{Name: "june", Start: now.Add(time.Hour), End: now.Add(2 * time.Hour), MinBalanceWei: big.NewInt(1)})
+	require.NoError(t, err)
+	require.Equal(t, "1", c.ID)
+	_, err = s.Add(Campaign{Start: now.Add(90 * time.Minute), End: now.Add(3 * time.Hour)})
+	require.ErrorContains(t, err, "overlaps with campaign 1")
+
+	s.Tick(context.Background())
+	require.Zero(t, checks, "prerequisites are checked from the lead time on")
+	require.Equal(t, Scheduled, s.List()[0].State)
+
+	now = now.Add(55 * time.Minute)
+	problems = []string{"deposit missing"}
+	s.Tick(context.Background())
+	require.Equal(t, Blocked, s.List()[0].State)
+	require.Equal(t, []string{"deposit missing"}, s.List()[0].Problems)
+
+	problems = nil
+	s.Tick(context.Background())
+	require.Equal(t, Ready, s.List()[0].State)
+	_, running := s.Running()
+	require.False(t, running)
+
+	now = now.Add(5 * time.Minute)
+	s.Tick(context.Background())
+	current, running := s.Running()
+	require.True(t, running)
+	require.Equal(t, "june", current.Name)
+
+	reopened, err := Open(path, nil, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, Running, reopened.List()[0].State, "campaigns survive restarts")
+
+	checks = 0
+	now = now.Add(time.Hour)
+	s.Tick(context.Background())
+	require.Zero(t, checks)
+	require.Equal(t, Ended, s.List()[0].State)
+	_, running = s.Running()
+	require.False(t, running)
+}
+
+func TestSchedulerRejectsInvalidCampaigns(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "campaigns.json"), nil, time.Minute)
+	require.NoError(t, err)
+	now := time.Now()
+
+	_, err = s.Add(Campaign{Start: now})
+	require.ErrorContains(t, err, "start and end are required")
+	_, err = s.Add(Campaign{Start: now.Add(time.Hour), End: now})
+	require.ErrorContains(t, err, "end must be after start")
+	_, err = s.Add(Campaign{Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)})
+	require.ErrorContains(t, err, "end must be in the future")
+	_, err = s.Add(Campaign{Start: now, End: now.Add(time.Hour), MinDepositWei: big.NewInt(-1)})
+	require.ErrorContains(t, err, "must not be negative")
+}
+
+func TestSchedulerAPI(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "campaigns.json"), nil, time.Minute)
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /campaigns", s.ServeList)
+	mux.HandleFunc("POST /campaigns", s.ServeAdd)
+	mux.HandleFunc("DELETE /campaigns/{id}", s.ServeCancel)
+
+	start := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	end := time.Now().Add(2 * time.Hour).UTC().Format(time.RFC3339)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/campaigns",
+		strings.NewReader(`{"name":"launch","start":"`+start+`","end":"`+end+`","min_balance_wei":1000000000000000000}`)))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), `"state":"scheduled"`)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/campaigns", strings.NewReader(`{"name":"open-ended","start":"`+start+`"}`)))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/campaigns/1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/campaigns/1", nil))
+	require.Equal(t, http.StatusConflict, rec.Code)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/campaigns/7", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/campaigns", nil))
+	require.Contains(t, rec.Body.String(), `"state":"canceled"`)
+}
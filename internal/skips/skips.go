@@ -0,0 +1,195 @@
+// Package skips records every block the bidder did not bid on, with a
+// machine-readable reason, and summarizes the skips per hour so gaps in
+// bidding can be explained after the fact.
+package skips
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/labels"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+)
+
+// Reason is why a block was skipped.
+type Reason string
+
+const (
+	Deadline          Reason = "deadline"           // A latency budget was exceeded.
+	Paused            Reason = "paused"             // Bidding is paused, e.g. on a standby instance.
+	Budget            Reason = "budget"             // The spend budget or the bid limit per block is exhausted.
+	NoProviders       Reason = "no-providers"       // No provider is connected to the bidder node.
+	InsufficientFunds Reason = "insufficient-funds" // The account cannot pay for the transaction.
+	Filter            Reason = "filter"             // The block did not pass a bidding filter.
+	Busy              Reason = "busy"               // The lane was still busy with an earlier block.
+	TxError           Reason = "tx-error"           // The transaction could not be built.
+	Conflict          Reason = "conflict"           // The nonce is held by another committed transaction.
+	FeeCap            Reason = "fee-cap"            // Fees exceed a configured maximum.
+	Superseded        Reason = "superseded"         // A newer block arrived before the slot tick the block waited for.
+	Missed            Reason = "missed"             // The block arrived while the block subscription was down.
+	Decay             Reason = "decay"              // The decay bounds reject the offset in use.
+)
+
+// Record is a skipped block, or an hourly summary when Summary is set.
+type Record struct {
+	Time   time.Time `json:"time"`
+	Block  uint64    `json:"block,omitempty"`
+	Reason Reason    `json:"reason,omitempty"`
+	Lane   string    `json:"lane,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+	// Tenant and Campaign label the skip, see package labels.
+	Tenant   string         `json:"tenant,omitempty"`
+	Campaign string         `json:"campaign,omitempty"`
+	Summary  map[Reason]int `json:"summary,omitempty"` // Skips per reason in the hour starting at Time.
+}
+
+// Log records skips and their hourly summaries, to a JSON lines file if one
+// is configured.
+type Log struct {
+	mu     sync.Mutex
+	f      *os.File
+	enc    *json.Encoder
+	hour   time.Time
+	counts map[Reason]int
+	now    func() time.Time
+
+	// Labels labels skipped blocks, when set.
+	Labels *labels.Source
+	// Notify, if set, is called with every skipped block. Set it before the
+	// log is used.
+	Notify func(Record)
+}
+
+// Open returns a skip log appending to path, or only logging when path is
+// empty.
+func Open(path string) (*Log, error) {
+	l := &Log{counts: make(map[Reason]int), now: time.Now}
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open skip log: %w", err)
+		}
+		l.f, l.enc = f, json.NewEncoder(f)
+	}
+	return l, nil
+}
+
+// Skip records that block was skipped for reason. lane and detail are
+// optional.
+func (l *Log) Skip(block uint64, reason Reason, lane, detail string) {
+	metrics.BlocksSkipped.WithLabelValues(string(reason)).Inc()
+	slog.Info("Block skipped",
+		"blockNumber", block,
+		"reason", reason,
+		"lane", lane,
+		"detail", detail,
+	)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now().UTC()
+	l.rollover(now)
+	l.counts[reason]++
+	current := l.Labels.Current()
+	rec := Record{Time: now, Block: block, Reason: reason, Lane: lane, Detail: detail, Tenant: current.Tenant, Campaign: current.Campaign}
+	l.write(rec)
+	if l.Notify != nil {
+		l.Notify(rec)
+	}
+}
+
+// rollover writes the summary of the previous hour once now is past it.
+// l.mu must be held.
+func (l *Log) rollover(now time.Time) {
+	hour := now.Truncate(time.Hour)
+	if l.hour.IsZero() {
+		l.hour = hour
+		return
+	}
+	if hour.Equal(l.hour) {
+		return
+	}
+	l.summarize()
+	l.hour = hour
+}
+
+// summarize writes and logs the summary of the current hour and resets it.
+// l.mu must be held.
+func (l *Log) summarize() {
+	if len(l.counts) == 0 {
+		return
+	}
+	attrs := []interface{}{"hour", l.hour}
+	total := 0
+	for _, reason := range sortedReasons(l.counts) {
+		attrs = append(attrs, string(reason), l.counts[reason])
+		total += l.counts[reason]
+	}
+	slog.Info("Skipped blocks in the last hour", append(attrs, "total", total)...)
+	l.write(Record{Time: l.hour, Summary: l.counts})
+	l.counts = make(map[Reason]int)
+}
+
+func (l *Log) write(rec Record) {
+	if l.enc == nil {
+		return
+	}
+	if err := l.enc.Encode(rec); err != nil {
+		slog.Warn("Failed to write skip log", "error", err)
+	}
+}
+
+// Summary returns the skips per reason in the current hour.
+func (l *Log) Summary() map[Reason]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollover(l.now().UTC())
+	out := make(map[Reason]int, len(l.counts))
+	for reason, n := range l.counts {
+		out[reason] = n
+	}
+	return out
+}
+
+// Run writes hourly summaries on time even when no further blocks are
+// skipped, until ctx is canceled.
+func (l *Log) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.mu.Lock()
+			l.rollover(l.now().UTC())
+			l.mu.Unlock()
+		}
+	}
+}
+
+// Close writes the summary of the current, partial hour and closes the file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.summarize()
+	if l.f == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+func sortedReasons(counts map[Reason]int) []Reason {
+	reasons := make([]Reason, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool { return reasons[i] < reasons[j] })
+	return reasons
+}
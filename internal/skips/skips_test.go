@@ -0,0 +1,49 @@
+package skips
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogSummarizesPerHour(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skips.jsonl")
+	l, err := Open(path)
+	require.NoError(t, err)
+	now := time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	l.Skip(100, Deadline, "blob", "header_fetch")
+	l.Skip(101, Paused, "", "")
+	l.Skip(102, Deadline, "blob", "nonce_fetch")
+	require.Equal(t, map[Reason]int{Deadline: 2, Paused: 1}, l.Summary())
+
+	now = now.Add(time.Hour)
+	l.Skip(400, InsufficientFunds, "transfer", "")
+	require.Equal(t, map[Reason]int{InsufficientFunds: 1}, l.Summary())
+	require.NoError(t, l.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+		records = append(records, rec)
+	}
+
+	require.Len(t, records, 6)
+	require.Equal(t, Deadline, records[0].Reason)
+	require.Equal(t, "blob", records[0].Lane)
+	require.Equal(t, map[Reason]int{Deadline: 2, Paused: 1}, records[3].Summary, "summary of the first hour")
+	require.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), records[3].Time)
+	require.Equal(t, uint64(400), records[4].Block)
+	require.Equal(t, map[Reason]int{InsufficientFunds: 1}, records[5].Summary, "partial hour on close")
+}
@@ -0,0 +1,102 @@
+// Package slots is the beacon chain's slot clock: slots of SlotDuration
+// counted from genesis, read in chain time as corrected by the clock package.
+// Bids can then be dispatched at a fixed offset into every slot rather than
+// whenever a header happens to arrive, so their decay windows start at the
+// same point of the slot every time.
+package slots
+
+import (
+	"context"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/clock"
+)
+
+// SlotDuration is the time between two slots.
+const SlotDuration = 12 * time.Second
+
+// Clock maps times to slots.
+type Clock struct {
+	genesis time.Time
+	slot    time.Duration
+	now     func() time.Time
+}
+
+// New returns the clock of a chain whose first slot started at genesis.
+func New(genesis time.Time) *Clock {
+	return &Clock{genesis: genesis, slot: SlotDuration, now: clock.Now}
+}
+
+// FromHeader returns a clock whose slots start when the slot of a header with
+// timestamp headerTime did, for a chain whose genesis is not known. Block
+// timestamps are slot starts, so the slot boundaries are right, but slots are
+// numbered from the first boundary after the Unix epoch instead of genesis.
+func FromHeader(headerTime uint64) *Clock {
+	seconds := uint64(SlotDuration / time.Second)
+	return New(time.Unix(int64(headerTime%seconds), 0))
+}
+
+// Genesis returns when slot 0 started.
+func (c *Clock) Genesis() time.Time {
+	return c.genesis
+}
+
+// Slot returns the slot t falls in, 0 before genesis.
+func (c *Clock) Slot(t time.Time) uint64 {
+	if t.Before(c.genesis) {
+		return 0
+	}
+	return uint64(t.Sub(c.genesis) / c.slot)
+}
+
+// Start returns when slot starts.
+func (c *Clock) Start(slot uint64) time.Time {
+	return c.genesis.Add(time.Duration(slot) * c.slot)
+}
+
+// Next returns the first slot whose start plus offset is after now, and that
+// time.
+func (c *Clock) Next(offset time.Duration) (uint64, time.Time) {
+	now := c.now()
+	slot := c.Slot(now)
+	at := c.Start(slot).Add(offset)
+	if !at.After(now) {
+		slot++
+		at = c.Start(slot).Add(offset)
+	}
+	return slot, at
+}
+
+// Ticks sends every slot at offset into it until ctx is done. A tick the
+// receiver is not ready for waits until it is; later ticks then follow for
+// the slots still ahead.
+func (c *Clock) Ticks(ctx context.Context, offset time.Duration) <-chan uint64 {
+	ticks := make(chan uint64)
+	go func() {
+		defer close(ticks)
+		var last uint64
+		sent := false
+		for {
+			slot, at := c.Next(offset)
+			// A timer firing early must not tick the same slot twice
+			if sent && slot <= last {
+				slot = last + 1
+				at = c.Start(slot).Add(offset)
+			}
+			timer := time.NewTimer(at.Sub(c.now()))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case ticks <- slot:
+				last, sent = slot, true
+			}
+		}
+	}()
+	return ticks
+}
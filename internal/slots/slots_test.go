@@ -0,0 +1,55 @@
+package slots
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClock(t *testing.T) {
+	genesis := time.Unix(1_695_902_400, 0)
+	c := New(genesis)
+	require.Equal(t, uint64(0), c.Slot(genesis.Add(-time.Hour)))
+	require.Equal(t, uint64(0), c.Slot(genesis.Add(11*time.Second)))
+	require.Equal(t, uint64(10), c.Slot(genesis.Add(120*time.Second+500*time.Millisecond)))
+	require.Equal(t, genesis.Add(120*time.Second), c.Start(10))
+
+	now := genesis.Add(120*time.Second + 100*time.Millisecond)
+	c.now = func() time.Time { return now }
+	slot, at := c.Next(200 * time.Millisecond)
+	require.Equal(t, uint64(10), slot)
+	require.Equal(t, genesis.Add(120*time.Second+200*time.Millisecond), at)
+
+	now = genesis.Add(120*time.Second + 200*time.Millisecond)
+	slot, at = c.Next(200 * time.Millisecond)
+	require.Equal(t, uint64(11), slot)
+	require.Equal(t, genesis.Add(132*time.Second+200*time.Millisecond), at)
+}
+
+func TestFromHeader(t *testing.T) {
+	headerTime := uint64(1_700_000_003)
+	c := FromHeader(headerTime)
+	header := time.Unix(int64(headerTime), 0)
+	require.Equal(t, header, c.Start(c.Slot(header)))
+	require.Equal(t, header.Add(SlotDuration), c.Start(c.Slot(header.Add(13*time.Second))))
+}
+
+func TestTicks(t *testing.T) {
+	c := New(time.Now().Add(-SlotDuration + 50*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ticks := c.Ticks(ctx, 20*time.Millisecond)
+	select {
+	case slot := <-ticks:
+		require.Equal(t, uint64(1), slot)
+		require.WithinDuration(t, c.Start(1).Add(20*time.Millisecond), time.Now(), 50*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("no tick")
+	}
+	cancel()
+	for range ticks {
+	}
+}
@@ -0,0 +1,180 @@
+// Package status keeps a per-account view of the bidder's wallets, so a stuck
+// account in a fleet stands out: nonce progression, balance, in-flight
+// transactions and bid counts. The view is served as JSON and summarized in
+// the log periodically.
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultStuckAfter is how long an account may bid without its nonce
+// advancing before it is reported as stuck.
+const DefaultStuckAfter = 5 * time.Minute
+
+// Account is the status of a single account.
+type Account struct {
+	Address          string    `json:"address"`
+	Nonce            uint64    `json:"nonce"`
+	NonceAdvancedAt  time.Time `json:"nonce_advanced_at"`
+	Balance          *big.Int  `json:"balance_wei,omitempty"`
+	BalanceUpdatedAt time.Time `json:"balance_updated_at,omitempty"`
+	InFlight         int       `json:"in_flight"`
+	BidsSent         uint64    `json:"bids_sent"`
+	BidsCommitted    uint64    `json:"bids_committed"` // Bids with at least one commitment.
+	Commitments      uint64    `json:"commitments"`
+	LastBidAt        time.Time `json:"last_bid_at,omitempty"`
+	Stuck            bool      `json:"stuck"`
+}
+
+// Board holds the status of every account.
+type Board struct {
+	mu         sync.Mutex
+	accounts   map[common.Address]*Account
+	stuckAfter time.Duration
+	now        func() time.Time
+}
+
+// NewBoard returns an empty board. An account that keeps bidding without its
+// nonce advancing for stuckAfter is reported as stuck.
+func NewBoard(stuckAfter time.Duration) *Board {
+	if stuckAfter <= 0 {
+		stuckAfter = DefaultStuckAfter
+	}
+	return &Board{
+		accounts:   make(map[common.Address]*Account),
+		stuckAfter: stuckAfter,
+		now:        time.Now,
+	}
+}
+
+// account returns the entry for addr, creating it. b.mu must be held.
+func (b *Board) account(addr common.Address) *Account {
+	a, ok := b.accounts[addr]
+	if !ok {
+		a = &Account{Address: addr.Hex(), NonceAdvancedAt: b.now()}
+		b.accounts[addr] = a
+	}
+	return a
+}
+
+// Register adds an account so it is listed before it bids.
+func (b *Board) Register(addr common.Address) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.account(addr)
+}
+
+// BidSent records a bid for a transaction with the given nonce.
+func (b *Board) BidSent(addr common.Address, nonce uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	a := b.account(addr)
+	if nonce > a.Nonce || a.BidsSent == 0 {
+		a.Nonce = nonce
+		a.NonceAdvancedAt = b.now()
+	}
+	a.InFlight++
+	a.BidsSent++
+	a.LastBidAt = b.now()
+}
+
+// BidResolved records the outcome of a bid recorded with BidSent.
+func (b *Board) BidResolved(addr common.Address, commitments int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	a := b.account(addr)
+	if a.InFlight > 0 {
+		a.InFlight--
+	}
+	if commitments > 0 {
+		a.BidsCommitted++
+		a.Commitments += uint64(commitments)
+	}
+}
+
+// SetBalance records the balance of an account.
+func (b *Board) SetBalance(addr common.Address, balance *big.Int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	a := b.account(addr)
+	a.Balance = new(big.Int).Set(balance)
+	a.BalanceUpdatedAt = b.now()
+}
+
+// BalanceOlderThan reports whether the balance of addr was last updated more
+// than d ago, or never.
+func (b *Board) BalanceOlderThan(addr common.Address, d time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	a := b.account(addr)
+	return a.Balance == nil || b.now().Sub(a.BalanceUpdatedAt) > d
+}
+
+// Snapshot returns a copy of every account, sorted by address.
+func (b *Board) Snapshot() []Account {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	out := make([]Account, 0, len(b.accounts))
+	for _, a := range b.accounts {
+		acc := *a
+		if a.Balance != nil {
+			acc.Balance = new(big.Int).Set(a.Balance)
+		}
+		acc.Stuck = a.BidsSent > 0 && now.Sub(a.NonceAdvancedAt) > b.stuckAfter && now.Sub(a.LastBidAt) < b.stuckAfter
+		out = append(out, acc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Address < out[j].Address })
+	return out
+}
+
+// ServeHTTP serves the snapshot as JSON.
+func (b *Board) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"accounts": b.Snapshot()})
+}
+
+// LogSummary logs one line per account, as a warning for stuck accounts.
+func (b *Board) LogSummary() {
+	for _, a := range b.Snapshot() {
+		attrs := []interface{}{
+			"address", a.Address,
+			"nonce", a.Nonce,
+			"nonceAge", time.Since(a.NonceAdvancedAt).Round(time.Second),
+			"balance", a.Balance,
+			"inFlight", a.InFlight,
+			"bidsSent", a.BidsSent,
+			"bidsCommitted", a.BidsCommitted,
+			"commitments", a.Commitments,
+		}
+		if a.Stuck {
+			slog.Warn("Account stuck, nonce is not advancing", attrs...)
+		} else {
+			slog.Info("Account status", attrs...)
+		}
+	}
+}
+
+// Run logs a summary every interval until ctx is canceled.
+func (b *Board) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.LogSummary()
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package status
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardTracksAccounts(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	b := NewBoard(time.Minute)
+	b.now = func() time.Time { return now }
+
+	healthy, stuck := common.Address{1}, common.Address{2}
+	b.Register(healthy)
+	b.Register(stuck)
+
+	b.BidSent(healthy, 5)
+	b.BidSent(stuck, 9)
+	b.BidResolved(healthy, 2)
+	b.SetBalance(healthy, big.NewInt(1e18))
+
+	// The stuck account keeps bidding with the same nonce
+	now = now.Add(2 * time.Minute)
+	b.BidSent(healthy, 6)
+	b.BidSent(stuck, 9)
+	b.BidResolved(stuck, 0)
+
+	accounts := b.Snapshot()
+	require.Len(t, accounts, 2)
+	require.Equal(t, healthy.Hex(), accounts[0].Address)
+	require.Equal(t, uint64(6), accounts[0].Nonce)
+	require.Equal(t, 1, accounts[0].InFlight)
+	require.Equal(t, uint64(2), accounts[0].BidsSent)
+	require.Equal(t, uint64(1), accounts[0].BidsCommitted)
+	require.Equal(t, uint64(2), accounts[0].Commitments)
+	require.False(t, accounts[0].Stuck)
+	require.Equal(t, int64(1e18), accounts[0].Balance.Int64())
+
+	require.True(t, accounts[1].Stuck)
+	require.Equal(t, 1, accounts[1].InFlight)
+	require.True(t, b.BalanceOlderThan(stuck, time.Minute), "balance never fetched")
+	require.False(t, b.BalanceOlderThan(healthy, 3*time.Minute))
+}
+
+func TestBoardServesJSON(t *testing.T) {
+	b := NewBoard(0)
+	b.BidSent(common.Address{1}, 3)
+
+	rec := httptest.NewRecorder()
+	b.ServeHTTP(rec, httptest.NewRequest("GET", "/accounts", nil))
+
+	var body struct {
+		Accounts []Account `json:"accounts"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Accounts, 1)
+	require.Equal(t, uint64(3), body.Accounts[0].Nonce)
+}
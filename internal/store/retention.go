@@ -0,0 +1,272 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRetention is how long bids are kept row by row. Older bids are
+// compacted into hourly aggregates, which are kept forever.
+const DefaultRetention = 30 * 24 * time.Hour
+
+// DefaultCompactInterval is how often bids past the retention are compacted.
+const DefaultCompactInterval = time.Hour
+
+// compactBatch bounds the bids compacted in one transaction, so compaction
+// never holds the database for long.
+const compactBatch = 5000
+
+// Aggregate summarizes the bids of one hour, lane and status.
+type Aggregate struct {
+	Hour        time.Time `json:"hour"`
+	Lane        string    `json:"lane,omitempty"`
+	Status      Status    `json:"status"`
+	Bids        int       `json:"bids"`
+	Commitments int       `json:"commitments"`
+	AmountWei   string    `json:"amount_wei"`
+}
+
+type aggregateKey struct {
+	hourMs int64
+	lane   string
+	status string
+}
+
+// aggregates sums bids into hourly aggregates.
+type aggregates map[aggregateKey]*Aggregate
+
+func (a aggregates) add(timeMs int64, lane, status string, bids, commitments int, amountWei string) {
+	hour := time.UnixMilli(timeMs).UTC().Truncate(time.Hour)
+	key := aggregateKey{hourMs: hour.UnixMilli(), lane: lane, status: status}
+	agg, ok := a[key]
+	if !ok {
+		agg = &Aggregate{Hour: hour, Lane: lane, Status: Status(status), AmountWei: "0"}
+		a[key] = agg
+	}
+	agg.Bids += bids
+	agg.Commitments += commitments
+	agg.AmountWei = addWei(agg.AmountWei, amountWei)
+}
+
+// sorted returns the aggregates by hour, lane and status.
+func (a aggregates) sorted() []Aggregate {
+	out := make([]Aggregate, 0, len(a))
+	for _, agg := range a {
+		out = append(out, *agg)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Hour.Equal(out[j].Hour) {
+			return out[i].Hour.Before(out[j].Hour)
+		}
+		if out[i].Lane != out[j].Lane {
+			return out[i].Lane < out[j].Lane
+		}
+		return out[i].Status < out[j].Status
+	})
+	return out
+}
+
+// addWei adds two decimal wei amounts. Amounts that do not parse count as 0.
+func addWei(a, b string) string {
+	x, _ := new(big.Int).SetString(a, 10)
+	y, _ := new(big.Int).SetString(b, 10)
+	if x == nil {
+		x = new(big.Int)
+	}
+	if y != nil {
+		x.Add(x, y)
+	}
+	return x.String()
+}
+
+// Compact folds the bids sent before cutoff into the hourly aggregates and
+// deletes them, freeing their pages for new bids. It returns the number of
+// bids compacted.
+func (s *Store) Compact(ctx context.Context, cutoff time.Time) (int, error) {
+	total := 0
+	for {
+		n, err := s.compactBatch(ctx, cutoff.UnixMilli())
+		total += n
+		if err != nil || n < compactBatch {
+			return total, err
+		}
+	}
+}
+
+// compactBatch compacts the oldest bids before cutoffMs in one transaction.
+func (s *Store) compactBatch(ctx context.Context, cutoffMs int64) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compact bid history: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, time_ms, lane, status, commitments, amount_wei FROM bids
+		WHERE time_ms < ? ORDER BY id LIMIT ?`, cutoffMs, compactBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compact bid history: %w", err)
+	}
+	batch := make(aggregates)
+	var n int
+	var maxID int64
+	for rows.Next() {
+		var id, timeMs int64
+		var lane, status, amountWei string
+		var commitments int
+		if err := rows.Scan(&id, &timeMs, &lane, &status, &commitments, &amountWei); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to compact bid history: %w", err)
+		}
+		batch.add(timeMs, lane, status, 1, commitments, amountWei)
+		maxID = id
+		n++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to compact bid history: %w", err)
+	}
+	if n == 0 {
+		return 0, nil
+	}
+
+	for key, agg := range batch {
+		var bids, commitments int
+		amountWei := "0"
+		err := tx.QueryRowContext(ctx, `SELECT bids, commitments, amount_wei FROM bid_aggregates
+			WHERE hour_ms = ? AND lane = ? AND status = ?`, key.hourMs, key.lane, key.status).Scan(&bids, &commitments, &amountWei)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, fmt.Errorf("failed to compact bid history: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO bid_aggregates
+			(hour_ms, lane, status, bids, commitments, amount_wei) VALUES (?, ?, ?, ?, ?, ?)`,
+			key.hourMs, key.lane, key.status, bids+agg.Bids, commitments+agg.Commitments, addWei(amountWei, agg.AmountWei)); err != nil {
+			return 0, fmt.Errorf("failed to compact bid history: %w", err)
+		}
+	}
+	// The batch holds every bid before the cutoff up to maxID
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bids WHERE id <= ? AND time_ms < ?`, maxID, cutoffMs); err != nil {
+		return 0, fmt.Errorf("failed to compact bid history: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to compact bid history: %w", err)
+	}
+	return n, nil
+}
+
+// Retain compacts the bids older than retention.
+func (s *Store) Retain(ctx context.Context, retention time.Duration) error {
+	n, err := s.Compact(ctx, time.Now().Add(-retention))
+	if n > 0 {
+		slog.Info("Bid history compacted", "bids", n, "retention", retention)
+	}
+	return err
+}
+
+// RunRetention compacts the bids older than retention right away and then
+// every interval, until ctx is canceled.
+func (s *Store) RunRetention(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Retain(ctx, retention); err != nil && ctx.Err() == nil {
+			slog.Warn("Failed to compact the bid history", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Aggregates returns hourly aggregates of every bid sent since since (all
+// when zero), compacted or not, by hour, lane and status.
+func (s *Store) Aggregates(ctx context.Context, since time.Time) ([]Aggregate, error) {
+	sinceMs := int64(0)
+	if !since.IsZero() {
+		sinceMs = since.UTC().Truncate(time.Hour).UnixMilli()
+	}
+	out := make(aggregates)
+
+	rows, err := s.db.QueryContext(ctx, `SELECT hour_ms, lane, status, bids, commitments, amount_wei FROM bid_aggregates
+		WHERE hour_ms >= ?`, sinceMs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bid aggregates: %w", err)
+	}
+	for rows.Next() {
+		var hourMs int64
+		var lane, status, amountWei string
+		var bids, commitments int
+		if err := rows.Scan(&hourMs, &lane, &status, &bids, &commitments, &amountWei); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read bid aggregates: %w", err)
+		}
+		out.add(hourMs, lane, status, bids, commitments, amountWei)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read bid aggregates: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `SELECT time_ms, lane, status, commitments, amount_wei FROM bids WHERE time_ms >= ?`, sinceMs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bid history: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var timeMs int64
+		var lane, status, amountWei string
+		var commitments int
+		if err := rows.Scan(&timeMs, &lane, &status, &commitments, &amountWei); err != nil {
+			return nil, fmt.Errorf("failed to read bid history: %w", err)
+		}
+		out.add(timeMs, lane, status, 1, commitments, amountWei)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read bid history: %w", err)
+	}
+	return out.sorted(), nil
+}
+
+// WriteAggregates exports aggregates in format, csv or json.
+func WriteAggregates(w io.Writer, format string, aggs []Aggregate) error {
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"hour", "lane", "status", "bids", "commitments", "amount_wei"}); err != nil {
+			return err
+		}
+		for _, a := range aggs {
+			if err := cw.Write([]string{
+				a.Hour.Format(time.RFC3339),
+				a.Lane,
+				string(a.Status),
+				strconv.Itoa(a.Bids),
+				strconv.Itoa(a.Commitments),
+				a.AmountWei,
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if aggs == nil {
+			aggs = []Aggregate{}
+		}
+		return enc.Encode(aggs)
+	}
+	return fmt.Errorf("unknown format %q, must be one of: %s", format, strings.Join(Formats, ", "))
+}
@@ -0,0 +1,369 @@
+// Package store keeps a persistent history of every bid in a local SQLite
+// database: what was bid, how the bidder node responded and whether the bid
+// was eventually committed, so bidding can be analyzed across restarts.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	_ "github.com/mattn/go-sqlite3" // SQLite driver
+	"github.com/primev/preconf_blob_bidder/internal/outcome"
+	"github.com/primev/preconf_blob_bidder/internal/provenance"
+)
+
+// Status is the commitment status of a bid.
+type Status string
+
+const (
+	Failed       Status = "failed"        // The bidder node did not accept the bid.
+	NoCommitment Status = "no-commitment" // No provider committed to the bid.
+	Committed    Status = "committed"     // At least one provider committed to the bid.
+	Stored       Status = "stored"        // A commitment was stored on the mev-commit chain.
+	NotStored    Status = "not-stored"    // No commitment was stored on the mev-commit chain in time.
+)
+
+// Bid is a recorded bid.
+type Bid struct {
+	ID          int64     `json:"id"`
+	Time        time.Time `json:"time"`
+	Lane        string    `json:"lane,omitempty"`
+	TxHash      string    `json:"tx_hash"`
+	BlockNumber uint64    `json:"block_number"`
+	AmountWei   string    `json:"amount_wei"`
+	DecayStart  int64     `json:"decay_start_ms"`
+	DecayEnd    int64     `json:"decay_end_ms"`
+	PayloadMode string    `json:"payload_mode"` // Payload privacy mode: payload, hash or commit-reveal, or preconf-rpc.
+	Response    string    `json:"response"`     // "ok", or the gRPC error of the bid.
+	Commitments int       `json:"commitments"`
+	Status      Status    `json:"status"`
+	Providers   []string  `json:"providers,omitempty"` // Providers whose commitment was stored on chain.
+	Signer      string    `json:"signer,omitempty"`    // Account that signed the bid, see package provenance.
+	Signature   string    `json:"signature,omitempty"`
+	Tenant      string    `json:"tenant,omitempty"`   // Customer the bidder runs for, see package labels.
+	Campaign    string    `json:"campaign,omitempty"` // Scheduled campaign the bid was made in.
+}
+
+// Provenance returns what the signature of the bid covers.
+func (b Bid) Provenance() provenance.Inputs {
+	return provenance.Inputs{
+		Bidder:      common.HexToAddress(b.Signer),
+		TxHash:      b.TxHash,
+		BlockNumber: b.BlockNumber,
+		AmountWei:   b.AmountWei,
+		DecayStart:  b.DecayStart,
+		DecayEnd:    b.DecayEnd,
+	}
+}
+
+// ResponseStatus returns the commitment status of a bid from the bidder node's
+// response.
+func ResponseStatus(sent bool, commitments int) Status {
+	switch {
+	case !sent:
+		return Failed
+	case commitments == 0:
+		return NoCommitment
+	}
+	return Committed
+}
+
+// FromOutcome returns the bid history record of a block outcome.
+func FromOutcome(o outcome.BlockOutcome) Bid {
+	response := "ok"
+	if o.Bid.Err != nil {
+		response = o.Bid.Err.Error()
+	}
+	var signer string
+	if o.Bid.Signature != "" {
+		signer = o.Payload.From.Hex()
+	}
+	return Bid{
+		Lane:        o.Payload.Lane,
+		TxHash:      o.Payload.TxHash,
+		BlockNumber: o.TargetBlock,
+		AmountWei:   o.Bid.AmountWei,
+		DecayStart:  o.Bid.DecayStart,
+		DecayEnd:    o.Bid.DecayEnd,
+		PayloadMode: o.Payload.Mode,
+		Response:    response,
+		Commitments: len(o.Commitments),
+		Status:      ResponseStatus(o.Bid.Sent, len(o.Commitments)),
+		Signer:      signer,
+		Signature:   o.Bid.Signature,
+		Tenant:      o.Tenant,
+		Campaign:    o.Campaign,
+	}
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS bids (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	time_ms      INTEGER NOT NULL,
+	lane         TEXT NOT NULL DEFAULT '',
+	tx_hash      TEXT NOT NULL,
+	block_number INTEGER NOT NULL,
+	amount_wei   TEXT NOT NULL,
+	decay_start  INTEGER NOT NULL,
+	decay_end    INTEGER NOT NULL,
+	payload_mode TEXT NOT NULL,
+	response     TEXT NOT NULL,
+	commitments  INTEGER NOT NULL,
+	status       TEXT NOT NULL,
+	providers    TEXT NOT NULL DEFAULT '',
+	signer       TEXT NOT NULL DEFAULT '',
+	signature    TEXT NOT NULL DEFAULT '',
+	tenant       TEXT NOT NULL DEFAULT '',
+	campaign     TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS bids_tx_hash ON bids (tx_hash);
+CREATE INDEX IF NOT EXISTS bids_block_number ON bids (block_number);
+CREATE INDEX IF NOT EXISTS bids_time ON bids (time_ms);
+CREATE TABLE IF NOT EXISTS bid_aggregates (
+	hour_ms      INTEGER NOT NULL,
+	lane         TEXT NOT NULL,
+	status       TEXT NOT NULL,
+	bids         INTEGER NOT NULL,
+	commitments  INTEGER NOT NULL,
+	amount_wei   TEXT NOT NULL,
+	PRIMARY KEY (hour_ms, lane, status)
+);
+`
+
+// addedColumns are bids columns added after the table was first created,
+// which databases written by earlier versions lack.
+var addedColumns = []string{"signer", "signature", "tenant", "campaign"}
+
+// migrate adds the missing addedColumns to the bids table.
+func migrate(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('bids')`)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, column := range addedColumns {
+		if existing[column] {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE bids ADD COLUMN ` + column + ` TEXT NOT NULL DEFAULT ''`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Store is a bid history database.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the database at path, creating it if needed.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bid history: %w", err)
+	}
+	// SQLite allows a single writer; one connection avoids lock contention
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create bid history schema: %w", err)
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate bid history schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// normalize makes transaction hashes from bids and events comparable.
+func normalize(txHash string) string {
+	return strings.ToLower(strings.TrimPrefix(txHash, "0x"))
+}
+
+// Record adds a bid and returns its ID. The time is set to now if unset.
+func (s *Store) Record(ctx context.Context, b Bid) (int64, error) {
+	if b.Time.IsZero() {
+		b.Time = time.Now()
+	}
+	res, err := s.db.ExecContext(ctx, `INSERT INTO bids
+		(time_ms, lane, tx_hash, block_number, amount_wei, decay_start, decay_end, payload_mode, response, commitments, status, providers,
+		signer, signature, tenant, campaign)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		b.Time.UnixMilli(), b.Lane, normalize(b.TxHash), b.BlockNumber, b.AmountWei, b.DecayStart, b.DecayEnd,
+		b.PayloadMode, b.Response, b.Commitments, string(b.Status), strings.Join(b.Providers, ","),
+		b.Signer, b.Signature, b.Tenant, b.Campaign,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record bid: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// SetStatus updates the commitment status of the bids for txHash, with the
+// providers whose commitment was stored on chain.
+func (s *Store) SetStatus(ctx context.Context, txHash string, status Status, providers []string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE bids SET status = ?, providers = ? WHERE tx_hash = ?`,
+		string(status), strings.Join(providers, ","), normalize(txHash))
+	if err != nil {
+		return fmt.Errorf("failed to update bid status: %w", err)
+	}
+	return nil
+}
+
+// Filter selects bids in Query. Zero fields don't filter.
+type Filter struct {
+	Since     time.Time
+	Until     time.Time
+	FromBlock uint64
+	ToBlock   uint64
+	Status    Status
+	Tenant    string
+	Campaign  string
+	Limit     int // Most recent bids only.
+}
+
+// Query returns the bids matching f, oldest first.
+func (s *Store) Query(ctx context.Context, f Filter) ([]Bid, error) {
+	var where []string
+	var args []interface{}
+	if !f.Since.IsZero() {
+		where, args = append(where, "time_ms >= ?"), append(args, f.Since.UnixMilli())
+	}
+	if !f.Until.IsZero() {
+		where, args = append(where, "time_ms < ?"), append(args, f.Until.UnixMilli())
+	}
+	if f.FromBlock > 0 {
+		where, args = append(where, "block_number >= ?"), append(args, f.FromBlock)
+	}
+	if f.ToBlock > 0 {
+		where, args = append(where, "block_number <= ?"), append(args, f.ToBlock)
+	}
+	if f.Status != "" {
+		where, args = append(where, "status = ?"), append(args, string(f.Status))
+	}
+	if f.Tenant != "" {
+		where, args = append(where, "tenant = ?"), append(args, f.Tenant)
+	}
+	if f.Campaign != "" {
+		where, args = append(where, "campaign = ?"), append(args, f.Campaign)
+	}
+	query := `SELECT id, time_ms, lane, tx_hash, block_number, amount_wei, decay_start, decay_end,
+		payload_mode, response, commitments, status, providers, signer, signature, tenant, campaign FROM bids`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if f.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(f.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bid history: %w", err)
+	}
+	defer rows.Close()
+	var bids []Bid
+	for rows.Next() {
+		var b Bid
+		var timeMs int64
+		var status, providers string
+		if err := rows.Scan(&b.ID, &timeMs, &b.Lane, &b.TxHash, &b.BlockNumber, &b.AmountWei, &b.DecayStart, &b.DecayEnd,
+			&b.PayloadMode, &b.Response, &b.Commitments, &status, &providers, &b.Signer, &b.Signature, &b.Tenant, &b.Campaign); err != nil {
+			return nil, fmt.Errorf("failed to read bid history: %w", err)
+		}
+		b.Time = time.UnixMilli(timeMs).UTC()
+		b.TxHash = "0x" + b.TxHash
+		b.Status = Status(status)
+		if providers != "" {
+			b.Providers = strings.Split(providers, ",")
+		}
+		bids = append(bids, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read bid history: %w", err)
+	}
+	// Selected newest first for the limit, returned oldest first
+	for i, j := 0, len(bids)-1; i < j; i, j = i+1, j-1 {
+		bids[i], bids[j] = bids[j], bids[i]
+	}
+	return bids, nil
+}
+
+// Formats are the supported export formats.
+var Formats = []string{"csv", "json"}
+
+// Write exports bids in format, csv or json.
+func Write(w io.Writer, format string, bids []Bid) error {
+	switch format {
+	case "csv":
+		return writeCSV(w, bids)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if bids == nil {
+			bids = []Bid{}
+		}
+		return enc.Encode(bids)
+	}
+	return fmt.Errorf("unknown format %q, must be one of: %s", format, strings.Join(Formats, ", "))
+}
+
+func writeCSV(w io.Writer, bids []Bid) error {
+	cw := csv.NewWriter(w)
+	header := []string{"id", "time", "lane", "tx_hash", "block_number", "amount_wei", "decay_start_ms", "decay_end_ms",
+		"payload_mode", "response", "commitments", "status", "providers", "signer", "signature", "tenant", "campaign"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, b := range bids {
+		if err := cw.Write([]string{
+			strconv.FormatInt(b.ID, 10),
+			b.Time.Format(time.RFC3339Nano),
+			b.Lane,
+			b.TxHash,
+			strconv.FormatUint(b.BlockNumber, 10),
+			b.AmountWei,
+			strconv.FormatInt(b.DecayStart, 10),
+			strconv.FormatInt(b.DecayEnd, 10),
+			b.PayloadMode,
+			b.Response,
+			strconv.Itoa(b.Commitments),
+			string(b.Status),
+			strings.Join(b.Providers, ";"),
+			b.Signer,
+			b.Signature,
+			b.Tenant,
+			b.Campaign,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
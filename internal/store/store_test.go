@@ -0,0 +1,202 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/primev/preconf_blob_bidder/internal/provenance"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreRecordsAndQueriesBids(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "bids.db")
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i, b := range []Bid{
+		{TxHash: "0xAA01", BlockNumber: 100, Status: ResponseStatus(true, 2), Commitments: 2, Response: "ok", PayloadMode: "payload"},
+		{TxHash: "0xaa02", BlockNumber: 101, Status: ResponseStatus(false, 0), Response: "unavailable", PayloadMode: "hash"},
+		{TxHash: "0xaa03", BlockNumber: 102, Status: ResponseStatus(true, 0), Response: "ok", PayloadMode: "payload", Lane: "blob"},
+	} {
+		b.Time = start.Add(time.Duration(i) * time.Minute)
+		b.AmountWei = "1000"
+		b.DecayStart, b.DecayEnd = 1, 12001
+		_, err := s.Record(ctx, b)
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.SetStatus(ctx, "aa01", Stored, []string{"0xp1", "0xp2"}))
+	require.NoError(t, s.Close())
+
+	// History survives a restart
+	s, err = Open(path)
+	require.NoError(t, err)
+	defer s.Close()
+
+	all, err := s.Query(ctx, Filter{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	require.Equal(t, "0xaa01", all[0].TxHash)
+	require.Equal(t, Stored, all[0].Status)
+	require.Equal(t, []string{"0xp1", "0xp2"}, all[0].Providers)
+	require.Equal(t, start, all[0].Time)
+	require.Equal(t, Failed, all[1].Status)
+	require.Equal(t, NoCommitment, all[2].Status)
+
+	latest, err := s.Query(ctx, Filter{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{101, 102}, []uint64{latest[0].BlockNumber, latest[1].BlockNumber}, "most recent, oldest first")
+
+	filtered, err := s.Query(ctx, Filter{FromBlock: 101, Since: start.Add(90 * time.Second)})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	require.Equal(t, "blob", filtered[0].Lane)
+
+	failed, err := s.Query(ctx, Filter{Status: Failed})
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, "csv", all))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	require.Equal(t, "0xp1;0xp2", records[1][12])
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, "json", all))
+	var decoded []Bid
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, all, decoded)
+
+	require.Error(t, Write(&buf, "xml", all))
+}
+
+func TestStoreKeepsSignaturesOfMigratedDatabase(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "bids.db")
+	// A database written before bids were signed
+	db, err := sql.Open("sqlite3", "file:"+path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE bids (
+		id INTEGER PRIMARY KEY AUTOINCREMENT, time_ms INTEGER NOT NULL, lane TEXT NOT NULL DEFAULT '',
+		tx_hash TEXT NOT NULL, block_number INTEGER NOT NULL, amount_wei TEXT NOT NULL,
+		decay_start INTEGER NOT NULL, decay_end INTEGER NOT NULL, payload_mode TEXT NOT NULL,
+		response TEXT NOT NULL, commitments INTEGER NOT NULL, status TEXT NOT NULL, providers TEXT NOT NULL DEFAULT '')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO bids (time_ms, tx_hash, block_number, amount_wei, decay_start, decay_end, payload_mode, response, commitments, status)
+		VALUES (1, 'aa00', 99, '1', 0, 1, 'payload', 'ok', 0, 'no-commitment')`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	s, err := Open(path)
+	require.NoError(t, err)
+	defer s.Close()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	b := Bid{TxHash: "0xaa01", BlockNumber: 100, AmountWei: "1000", DecayStart: 1, DecayEnd: 12001, Status: Committed,
+		Signer: crypto.PubkeyToAddress(key.PublicKey).Hex()}
+	b.Signature, err = provenance.Sign(key, b.Provenance())
+	require.NoError(t, err)
+	_, err = s.Record(ctx, b)
+	require.NoError(t, err)
+
+	bids, err := s.Query(ctx, Filter{})
+	require.NoError(t, err)
+	require.Len(t, bids, 2)
+	require.ErrorIs(t, provenance.Verify(bids[0].Provenance(), bids[0].Signature), provenance.ErrUnsigned)
+	require.Equal(t, b.Signer, bids[1].Signer)
+	require.NoError(t, provenance.Verify(bids[1].Provenance(), bids[1].Signature))
+}
+
+func TestQueryByTenantAndCampaign(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open(filepath.Join(t.TempDir(), "bids.db"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	for i, labels := range [][2]string{{"acme", "c1"}, {"acme", "c2"}, {"globex", "c1"}, {"", ""}} {
+		_, err := s.Record(ctx, Bid{TxHash: fmt.Sprintf("0x%02x", i), BlockNumber: uint64(100 + i), AmountWei: "1",
+			Status: NoCommitment, Tenant: labels[0], Campaign: labels[1]})
+		require.NoError(t, err)
+	}
+
+	bids, err := s.Query(ctx, Filter{Tenant: "acme"})
+	require.NoError(t, err)
+	require.Len(t, bids, 2)
+	bids, err = s.Query(ctx, Filter{Tenant: "acme", Campaign: "c1"})
+	require.NoError(t, err)
+	require.Len(t, bids, 1)
+	require.Equal(t, uint64(100), bids[0].BlockNumber)
+	require.Equal(t, "c1", bids[0].Campaign)
+
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, "csv", bids))
+	require.Contains(t, out.String(), ",signer,signature,tenant,campaign\n")
+	require.Contains(t, out.String(), ",acme,c1\n")
+}
+
+func TestCompactKeepsHourlyAggregates(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open(filepath.Join(t.TempDir(), "bids.db"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	hour := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i, b := range []Bid{
+		{Time: hour.Add(5 * time.Minute), Status: Committed, Commitments: 2, AmountWei: "9000000000000000000"},
+		{Time: hour.Add(50 * time.Minute), Status: Committed, Commitments: 1, AmountWei: "9000000000000000000"},
+		{Time: hour.Add(55 * time.Minute), Status: Failed, AmountWei: "1000"},
+		{Time: hour.Add(70 * time.Minute), Status: Committed, Commitments: 1, AmountWei: "5"},
+		{Time: hour.Add(48 * time.Hour), Status: Committed, Commitments: 1, AmountWei: "7"},
+	} {
+		b.TxHash = "0x0" + strconv.Itoa(i)
+		_, err := s.Record(ctx, b)
+		require.NoError(t, err)
+	}
+
+	n, err := s.Compact(ctx, hour.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	remaining, err := s.Query(ctx, Filter{})
+	require.NoError(t, err)
+	require.Len(t, remaining, 1, "bids within the retention stay")
+
+	// Compacting again adds to the existing aggregates
+	_, err = s.Record(ctx, Bid{Time: hour.Add(10 * time.Minute), TxHash: "0x05", Status: Committed, Commitments: 3, AmountWei: "1"})
+	require.NoError(t, err)
+	n, err = s.Compact(ctx, hour.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	aggs, err := s.Aggregates(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []Aggregate{
+		{Hour: hour, Status: Committed, Bids: 3, Commitments: 6, AmountWei: "18000000000000000001"},
+		{Hour: hour, Status: Failed, Bids: 1, AmountWei: "1000"},
+		{Hour: hour.Add(time.Hour), Status: Committed, Bids: 1, Commitments: 1, AmountWei: "5"},
+		{Hour: hour.Add(48 * time.Hour), Status: Committed, Bids: 1, Commitments: 1, AmountWei: "7"},
+	}, aggs, "compacted and raw bids alike")
+
+	recent, err := s.Aggregates(ctx, hour.Add(90*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, recent, 2, "from the hour of since on")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteAggregates(&buf, "csv", aggs))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+	require.Equal(t, []string{"2024-05-01T10:00:00Z", "", "committed", "3", "6", "18000000000000000001"}, records[1])
+}
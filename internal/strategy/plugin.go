@@ -0,0 +1,20 @@
+package strategy
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// LoadPlugin opens a Go plugin built with -buildmode=plugin. The plugin must
+// call Register from its init function; it has to be built with the same Go
+// toolchain and module versions as the bidder.
+func LoadPlugin(path string) error {
+	before := len(Names())
+	if _, err := plugin.Open(path); err != nil {
+		return fmt.Errorf("failed to load strategy plugin %s: %w", path, err)
+	}
+	if len(Names()) == before {
+		return fmt.Errorf("strategy plugin %s did not register a strategy", path)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package strategy
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"math/rand"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/primev/preconf_blob_bidder/internal/money"
+)
+
+// Script is a strategy defined by an expression, so bid logic can be iterated
+// on without rebuilding the bidder. The expression sees the parameters and
+// market inputs and returns the bid amount in ETH.
+//
+// Variables: bid_amount, std_dev_percentage, offset, block_number, timestamp,
+// base_fee_gwei, blob_base_fee_gwei, competition, bid_scale. Functions: normal() draws from a standard
+// normal distribution and uniform() from [0, 1), both from the block's seeded
+// random source; max and min are built in.
+type Script struct {
+	Params  Params
+	program *vm.Program
+}
+
+// scriptEnv is the environment scripts are compiled and run against.
+type scriptEnv struct {
+	BidAmount        float64        `expr:"bid_amount"`
+	StdDevPercentage float64        `expr:"std_dev_percentage"`
+	Offset           uint64         `expr:"offset"`
+	BlockNumber      uint64         `expr:"block_number"`
+	Timestamp        uint64         `expr:"timestamp"`
+	BaseFeeGwei      float64        `expr:"base_fee_gwei"`
+	BlobBaseFeeGwei  float64        `expr:"blob_base_fee_gwei"`
+	Competition      float64        `expr:"competition"`
+	BidScale         float64        `expr:"bid_scale"`
+	Normal           func() float64 `expr:"normal"`
+	Uniform          func() float64 `expr:"uniform"`
+}
+
+// NewScript compiles params.Script.
+func NewScript(params Params) (*Script, error) {
+	if params.Script == "" {
+		return nil, fmt.Errorf("script strategy requires a script")
+	}
+	program, err := expr.Compile(params.Script, expr.Env(scriptEnv{}), expr.AsFloat64())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile strategy script: %w", err)
+	}
+	return &Script{Params: params, program: program}, nil
+}
+
+// Name implements BidStrategy.
+func (s *Script) Name() string {
+	return "script"
+}
+
+// Decide implements BidStrategy. A script that fails or returns a negative or
+// non-finite amount falls back to the configured bid amount.
+func (s *Script) Decide(inputs MarketInputs, rng *rand.Rand) Decision {
+	env := scriptEnv{
+		BidAmount:        s.Params.BidAmount,
+		StdDevPercentage: s.Params.StdDevPercentage,
+		Offset:           s.Params.Offset,
+		BlockNumber:      inputs.BlockNumber,
+		Timestamp:        inputs.Timestamp,
+		BaseFeeGwei:      gwei(inputs.BaseFee),
+		BlobBaseFeeGwei:  gwei(inputs.BlobBaseFee),
+		Competition:      inputs.Competition,
+		BidScale:         inputs.BidScale,
+		Normal:           rng.NormFloat64,
+		Uniform:          rng.Float64,
+	}
+	out, err := expr.Run(s.program, env)
+	amount, _ := out.(float64)
+	if err != nil || amount < 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		slog.Warn("Strategy script failed, using configured bid amount",
+			"error", err,
+			"result", out,
+			"blockNumber", inputs.BlockNumber,
+		)
+		return Decision{BidAmount: s.Params.BidAmount}
+	}
+	return Decision{BidAmount: amount}
+}
+
+func gwei(wei *big.Int) float64 {
+	return money.ToGwei(wei)
+}
@@ -0,0 +1,162 @@
+// Package strategy contains the bid decision logic. Strategies are pure
+// functions of their parameters, the observed market inputs and a seeded
+// random source, so recorded campaigns can be replayed deterministically.
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Params are the user supplied bidding parameters.
+type Params struct {
+	BidAmount        float64 `json:"bid_amount"`         // Mean bid amount in ETH.
+	StdDevPercentage float64 `json:"std_dev_percentage"` // Standard deviation as a percentage of BidAmount.
+	Offset           uint64  `json:"offset"`             // How many blocks ahead of the head to bid for.
+	Script           string  `json:"script,omitempty"`   // Expression evaluated by the "script" strategy.
+	// ProviderWeighting is how the stake and commit rate of the providers
+	// weigh their commitments for the "adaptive" strategy; see package
+	// providers.
+	ProviderWeighting string `json:"provider_weighting,omitempty"`
+}
+
+// MarketInputs are the observations a strategy bases its decision on.
+type MarketInputs struct {
+	BlockNumber uint64   `json:"block_number"` // Head block the decision is made on.
+	Timestamp   uint64   `json:"timestamp"`    // Head block timestamp in seconds.
+	BaseFee     *big.Int `json:"base_fee,omitempty"`
+	BlobBaseFee *big.Int `json:"blob_base_fee,omitempty"` // Derived from the head's excess blob gas.
+	Competition float64  `json:"competition,omitempty"`   // Competing commitments per slot, when observed.
+	BidScale    float64  `json:"bid_scale,omitempty"`     // Bid multiplier of the adaptive pricing controller, when enabled.
+}
+
+// InputsFromHeader returns the market inputs observable from a head block.
+func InputsFromHeader(header *types.Header) MarketInputs {
+	inputs := MarketInputs{
+		BlockNumber: header.Number.Uint64(),
+		Timestamp:   header.Time,
+		BaseFee:     header.BaseFee,
+	}
+	if header.ExcessBlobGas != nil {
+		inputs.BlobBaseFee = eip4844.CalcBlobFee(*header.ExcessBlobGas)
+	}
+	return inputs
+}
+
+// Decision is the output of a strategy for a single block.
+type Decision struct {
+	BidAmount float64 `json:"bid_amount"` // Bid amount in ETH.
+}
+
+// BidStrategy decides how much to bid for a block.
+type BidStrategy interface {
+	// Name returns the registered name of the strategy.
+	Name() string
+	// Decide returns the bid decision. All randomness must come from rng.
+	Decide(inputs MarketInputs, rng *rand.Rand) Decision
+}
+
+// Gaussian draws bid amounts from a normal distribution around the configured
+// amount, never bidding below it.
+type Gaussian struct {
+	Params Params
+}
+
+// Name implements BidStrategy.
+func (g Gaussian) Name() string {
+	return "gaussian"
+}
+
+// Decide implements BidStrategy.
+func (g Gaussian) Decide(_ MarketInputs, rng *rand.Rand) Decision {
+	stdDev := g.Params.BidAmount * g.Params.StdDevPercentage / 100.0
+	amount := rng.NormFloat64()*stdDev + g.Params.BidAmount
+	return Decision{BidAmount: math.Max(amount, g.Params.BidAmount)}
+}
+
+// Adaptive bids the configured amount scaled by the adaptive pricing
+// controller, which raises the scale while bids go uncommitted and lowers it
+// while they are committed. It adds no randomness of its own.
+type Adaptive struct {
+	Params Params
+}
+
+// Name implements BidStrategy.
+func (a Adaptive) Name() string {
+	return "adaptive"
+}
+
+// Decide implements BidStrategy. Without a scale it bids the configured
+// amount.
+func (a Adaptive) Decide(inputs MarketInputs, _ *rand.Rand) Decision {
+	scale := inputs.BidScale
+	if scale <= 0 {
+		scale = 1
+	}
+	return Decision{BidAmount: a.Params.BidAmount * scale}
+}
+
+// Factory builds a strategy from the bidding parameters.
+type Factory func(params Params) (BidStrategy, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{
+		"gaussian": func(p Params) (BidStrategy, error) { return Gaussian{Params: p}, nil },
+		"adaptive": func(p Params) (BidStrategy, error) { return Adaptive{Params: p}, nil },
+		"script":   func(p Params) (BidStrategy, error) { return NewScript(p) },
+	}
+)
+
+// Register makes a strategy available under name. Strategy plugins call it
+// from their init function. Registering a name twice panics.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("strategy %q registered twice", name))
+	}
+	registry[name] = factory
+}
+
+// Names returns the sorted names of the registered strategies.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the strategy registered under name. An empty name selects the
+// gaussian strategy.
+func New(name string, params Params) (BidStrategy, error) {
+	if name == "" {
+		name = "gaussian"
+	}
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q (registered: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(params)
+}
+
+// BlockRand returns the random source for a block. Deriving it from the
+// campaign seed and block number keeps each decision reproducible regardless
+// of which blocks were skipped before it.
+func BlockRand(seed int64, blockNumber uint64) *rand.Rand {
+	return rand.New(rand.NewSource(seed ^ int64(blockNumber*0x9E3779B97F4A7C15)))
+}
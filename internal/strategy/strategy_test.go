@@ -0,0 +1,70 @@
+package strategy
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fixedStrategy struct{ amount float64 }
+
+func (f fixedStrategy) Name() string { return "fixed-test" }
+func (f fixedStrategy) Decide(MarketInputs, *rand.Rand) Decision {
+	return Decision{BidAmount: f.amount}
+}
+
+func TestRegisterAndNew(t *testing.T) {
+	Register("fixed-test", func(p Params) (BidStrategy, error) { return fixedStrategy{amount: p.BidAmount * 2}, nil })
+	require.Contains(t, Names(), "fixed-test")
+	require.Panics(t, func() { Register("fixed-test", nil) })
+
+	s, err := New("fixed-test", Params{BidAmount: 0.5})
+	require.NoError(t, err)
+	require.Equal(t, 1.0, s.Decide(MarketInputs{}, BlockRand(1, 1)).BidAmount)
+
+	s, err = New("", Params{BidAmount: 0.5})
+	require.NoError(t, err)
+	require.Equal(t, "gaussian", s.Name())
+
+	_, err = New("missing", Params{})
+	require.ErrorContains(t, err, "registered:")
+}
+
+func TestScriptStrategy(t *testing.T) {
+	params := Params{
+		BidAmount: 0.001,
+		Script:    "bid_amount * (1 + base_fee_gwei / 100) + max(normal(), 0) * 0",
+	}
+	s, err := New("script", params)
+	require.NoError(t, err)
+
+	inputs := MarketInputs{BlockNumber: 10, BaseFee: big.NewInt(50e9)}
+	d := s.Decide(inputs, BlockRand(3, 10))
+	require.InDelta(t, 0.0015, d.BidAmount, 1e-12)
+
+	// Randomness comes from the block's seeded source, so decisions replay.
+	params.Script = "bid_amount + uniform()"
+	s, err = New("script", params)
+	require.NoError(t, err)
+	require.Equal(t, s.Decide(inputs, BlockRand(3, 10)), s.Decide(inputs, BlockRand(3, 10)))
+
+	// Negative results fall back to the configured amount.
+	params.Script = "-1"
+	s, err = New("script", params)
+	require.NoError(t, err)
+	require.Equal(t, 0.001, s.Decide(inputs, BlockRand(3, 10)).BidAmount)
+
+	_, err = New("script", Params{Script: "bid_amount +"})
+	require.Error(t, err)
+	_, err = New("script", Params{})
+	require.Error(t, err)
+}
+
+func TestAdaptiveStrategy(t *testing.T) {
+	s, err := New("adaptive", Params{BidAmount: 0.002})
+	require.NoError(t, err)
+	require.Equal(t, 0.002, s.Decide(MarketInputs{}, BlockRand(1, 1)).BidAmount, "unscaled without a controller")
+	require.Equal(t, 0.003, s.Decide(MarketInputs{BidScale: 1.5}, BlockRand(1, 1)).BidAmount)
+}
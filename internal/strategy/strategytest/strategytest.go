@@ -0,0 +1,174 @@
+// Package strategytest is a test kit for bid strategies. It ships synthetic
+// market scenarios as fixtures and a harness that runs any BidStrategy
+// through them, scoring each bid against the price that would have won the
+// block. Reports are deterministic for a seed, so they can be compared
+// between strategies and kept as golden files next to a strategy's tests.
+package strategytest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"sort"
+	"testing"
+
+	"github.com/primev/preconf_blob_bidder/internal/pricing"
+	"github.com/primev/preconf_blob_bidder/internal/strategy"
+)
+
+// DefaultSeed is the seed of the random source of strategies when Options
+// sets none.
+const DefaultSeed = 1
+
+//go:embed scenarios/*.json
+var fixtures embed.FS
+
+// Block is a block of a scenario: what the strategy observes, and the lowest
+// bid that would have been committed.
+type Block struct {
+	strategy.MarketInputs
+	ClearingPriceETH float64 `json:"clearing_price_eth"`
+}
+
+// Scenario is a synthetic market a strategy is run through.
+type Scenario struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Blocks      []Block `json:"blocks"`
+}
+
+// Scenarios returns the built-in scenarios, sorted by name: high-competition,
+// low-competition and fee-spike.
+func Scenarios() ([]Scenario, error) {
+	entries, err := fixtures.ReadDir("scenarios")
+	if err != nil {
+		return nil, err
+	}
+	scenarios := make([]Scenario, 0, len(entries))
+	for _, entry := range entries {
+		data, err := fixtures.ReadFile(path.Join("scenarios", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var sc Scenario
+		if err := json.Unmarshal(data, &sc); err != nil {
+			return nil, fmt.Errorf("corrupt scenario %s: %w", entry.Name(), err)
+		}
+		scenarios = append(scenarios, sc)
+	}
+	sort.Slice(scenarios, func(i, j int) bool { return scenarios[i].Name < scenarios[j].Name })
+	return scenarios, nil
+}
+
+// Options configures a run.
+type Options struct {
+	Seed int64 // Campaign seed the per-block random sources derive from.
+	// Pricing enables the adaptive pricing controller, which observes whether
+	// each bid won and feeds its scale to the strategy.
+	Pricing *pricing.Config
+}
+
+// Report scores a strategy on a scenario. Amounts are in ETH.
+type Report struct {
+	Strategy   string  `json:"strategy"`
+	Scenario   string  `json:"scenario"`
+	Blocks     int     `json:"blocks"`
+	Won        int     `json:"won"` // Bids at or above the clearing price.
+	WinRate    float64 `json:"win_rate"`
+	SpendETH   float64 `json:"spend_eth"`   // Sum of the winning bids.
+	OverpayETH float64 `json:"overpay_eth"` // What the winning bids paid above the clearing price.
+	MeanBidETH float64 `json:"mean_bid_eth"`
+	// CostPerWinETH is the spend per won block, zero when nothing was won.
+	CostPerWinETH float64 `json:"cost_per_win_eth"`
+}
+
+// Run runs s through every block of sc. A bid wins its block when it is at
+// least the clearing price, and only winning bids are paid.
+func Run(s strategy.BidStrategy, sc Scenario, opts Options) Report {
+	seed := opts.Seed
+	if seed == 0 {
+		seed = DefaultSeed
+	}
+	var controller *pricing.Controller
+	if opts.Pricing != nil {
+		controller = pricing.NewController(*opts.Pricing)
+	}
+	r := Report{Strategy: s.Name(), Scenario: sc.Name, Blocks: len(sc.Blocks)}
+	var bids float64
+	for _, block := range sc.Blocks {
+		inputs := block.MarketInputs
+		if controller != nil {
+			inputs.BidScale = controller.Scale()
+		}
+		amount := s.Decide(inputs, strategy.BlockRand(seed, inputs.BlockNumber)).BidAmount
+		bids += amount
+		won := amount >= block.ClearingPriceETH
+		if won {
+			r.Won++
+			r.SpendETH += amount
+			r.OverpayETH += amount - block.ClearingPriceETH
+		}
+		if controller != nil {
+			controller.Observe(won)
+		}
+	}
+	if r.Blocks > 0 {
+		r.WinRate = float64(r.Won) / float64(r.Blocks)
+		r.MeanBidETH = bids / float64(r.Blocks)
+	}
+	if r.Won > 0 {
+		r.CostPerWinETH = r.SpendETH / float64(r.Won)
+	}
+	r.round()
+	return r
+}
+
+// RunAll runs s through every built-in scenario.
+func RunAll(s strategy.BidStrategy, opts Options) ([]Report, error) {
+	scenarios, err := Scenarios()
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]Report, 0, len(scenarios))
+	for _, sc := range scenarios {
+		reports = append(reports, Run(s, sc, opts))
+	}
+	return reports, nil
+}
+
+// round rounds the amounts to the gwei and the rate to four decimals, so
+// golden files do not change with the last bits of floating point sums.
+func (r *Report) round() {
+	for _, v := range []*float64{&r.SpendETH, &r.OverpayETH, &r.MeanBidETH, &r.CostPerWinETH} {
+		*v = math.Round(*v*1e9) / 1e9
+	}
+	r.WinRate = math.Round(r.WinRate*1e4) / 1e4
+}
+
+// CheckGolden compares reports with the golden file at file, failing t on a
+// difference. With UPDATE_GOLDEN=1 in the environment the file is rewritten
+// instead.
+func CheckGolden(t testing.TB, file string, reports []Report) {
+	t.Helper()
+	got, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+	if os.Getenv("UPDATE_GOLDEN") == "1" {
+		if err := os.WriteFile(file, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run with UPDATE_GOLDEN=1 to create it: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("reports differ from %s, run with UPDATE_GOLDEN=1 to accept them\ngot:\n%s\nwant:\n%s", file, got, want)
+	}
+}
@@ -0,0 +1,73 @@
+package strategytest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/primev/preconf_blob_bidder/internal/pricing"
+	"github.com/primev/preconf_blob_bidder/internal/strategy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenarios(t *testing.T) {
+	scenarios, err := Scenarios()
+	require.NoError(t, err)
+	names := make([]string, 0, len(scenarios))
+	for _, sc := range scenarios {
+		names = append(names, sc.Name)
+		require.NotEmpty(t, sc.Blocks, sc.Name)
+		for _, block := range sc.Blocks {
+			require.NotNil(t, block.BaseFee, sc.Name)
+			require.Positive(t, block.ClearingPriceETH, sc.Name)
+		}
+	}
+	require.Equal(t, []string{"fee-spike", "high-competition", "low-competition"}, names)
+}
+
+func TestRunScoresBids(t *testing.T) {
+	sc := Scenario{Name: "two blocks", Blocks: []Block{
+		{MarketInputs: strategy.MarketInputs{BlockNumber: 1}, ClearingPriceETH: 0.001},
+		{MarketInputs: strategy.MarketInputs{BlockNumber: 2}, ClearingPriceETH: 0.003},
+	}}
+	r := Run(strategy.Adaptive{Params: strategy.Params{BidAmount: 0.002}}, sc, Options{})
+	require.Equal(t, Report{
+		Strategy:      "adaptive",
+		Scenario:      "two blocks",
+		Blocks:        2,
+		Won:           1,
+		WinRate:       0.5,
+		SpendETH:      0.002,
+		OverpayETH:    0.001,
+		MeanBidETH:    0.002,
+		CostPerWinETH: 0.002,
+	}, r)
+}
+
+func TestBuiltinStrategiesGolden(t *testing.T) {
+	params := strategy.Params{BidAmount: 0.0015, StdDevPercentage: 50}
+	pricingCfg := pricing.Config{
+		TargetRate: pricing.DefaultTargetRate,
+		Step:       pricing.DefaultStep,
+		MinScale:   pricing.DefaultMinScale,
+		MaxScale:   pricing.DefaultMaxScale,
+	}
+	for _, tc := range []struct {
+		name string
+		opts Options
+	}{
+		{"gaussian", Options{}},
+		{"adaptive", Options{Pricing: &pricingCfg}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := strategy.New(tc.name, params)
+			require.NoError(t, err)
+			reports, err := RunAll(s, tc.opts)
+			require.NoError(t, err)
+			CheckGolden(t, filepath.Join("testdata", tc.name+".golden.json"), reports)
+
+			again, err := RunAll(s, tc.opts)
+			require.NoError(t, err)
+			require.Equal(t, reports, again, "runs are deterministic")
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// Package stream pushes the lifecycle of every bid, from a skipped block or a
+// sent bid to its resolution and the commitments stored for it, to clients of
+// the status server as Server-Sent Events. Dashboards and scripts follow the
+// bidder with a plain HTTP request, e.g. curl -N or EventSource, instead of a
+// message broker. Events are not stored: a client only sees those published
+// while it is connected, and one that falls behind loses events rather than
+// holding up the bidder.
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+	"github.com/primev/preconf_blob_bidder/internal/outcome"
+)
+
+// Event types.
+const (
+	BidSkipped  = "bid_skipped"  // A block was not bid on; the data is a skips.Record.
+	BidSent     = "bid_sent"     // A bid is being sent; the data is a Bid.
+	BidResolved = "bid_resolved" // The bidder node answered a bid; the data is a Bid.
+	BidSettled  = "bid_settled"  // The commitments stored for a bid are known; the data is a Settlement.
+)
+
+// Types are the event types.
+var Types = []string{BidSkipped, BidSent, BidResolved, BidSettled}
+
+const (
+	// DefaultBuffer is how many events a client can fall behind before
+	// events are dropped for it.
+	DefaultBuffer = 256
+	// DefaultHeartbeat is how often an idle stream sends a comment, so
+	// proxies do not close it.
+	DefaultHeartbeat = 15 * time.Second
+)
+
+// Event is one message of the stream.
+type Event struct {
+	ID   uint64      `json:"id"`
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// Bid is a sent or resolved bid.
+type Bid struct {
+	TargetBlock uint64   `json:"target_block"`
+	TxHash      string   `json:"tx_hash"`
+	Lane        string   `json:"lane,omitempty"`
+	From        string   `json:"from,omitempty"`
+	Nonce       uint64   `json:"nonce"`
+	AmountETH   float64  `json:"amount_eth"`
+	Decay       string   `json:"decay,omitempty"`
+	Sent        bool     `json:"sent"`
+	Submitted   bool     `json:"submitted"`
+	Commitments int      `json:"commitments"`
+	Providers   []string `json:"providers,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	LatencyMs   int64    `json:"latency_ms,omitempty"`
+	Tenant      string   `json:"tenant,omitempty"`
+	Campaign    string   `json:"campaign,omitempty"`
+}
+
+// Resolved returns the resolved bid of o.
+func Resolved(o outcome.BlockOutcome) Bid {
+	b := Bid{
+		TargetBlock: o.TargetBlock,
+		TxHash:      o.Payload.TxHash,
+		Lane:        o.Payload.Lane,
+		From:        o.Payload.From.Hex(),
+		Nonce:       o.Payload.Nonce,
+		AmountETH:   o.Bid.AmountETH,
+		Sent:        o.Bid.Sent,
+		Submitted:   o.Submitted,
+		Commitments: len(o.Commitments),
+		Providers:   o.Providers(),
+		LatencyMs:   o.Timings.Latency().Milliseconds(),
+		Tenant:      o.Tenant,
+		Campaign:    o.Campaign,
+	}
+	if o.Bid.Err != nil {
+		b.Error = o.Bid.Err.Error()
+	}
+	return b
+}
+
+// Settlement is the commitments stored on the mev-commit chain for a bid.
+type Settlement struct {
+	TargetBlock uint64   `json:"target_block"`
+	TxHash      string   `json:"tx_hash"`
+	Committed   bool     `json:"committed"`
+	Providers   []string `json:"providers,omitempty"`
+	LatencyMs   int64    `json:"latency_ms,omitempty"` // Until the first commitment was stored.
+}
+
+// Hub fans events out to the connected clients.
+type Hub struct {
+	buffer    int
+	heartbeat time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	nextID  uint64
+	clients map[chan Event]map[string]bool // Event types each client wants, nil for all.
+}
+
+// New returns a hub without clients.
+func New() *Hub {
+	return &Hub{
+		buffer:    DefaultBuffer,
+		heartbeat: DefaultHeartbeat,
+		now:       time.Now,
+		clients:   make(map[chan Event]map[string]bool),
+	}
+}
+
+// Publish sends an event of type typ with data to every client that wants
+// it, without blocking.
+func (h *Hub) Publish(typ string, data interface{}) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.clients) == 0 {
+		return
+	}
+	h.nextID++
+	ev := Event{ID: h.nextID, Type: typ, Time: h.now().UTC(), Data: data}
+	for ch, types := range h.clients {
+		if types != nil && !types[typ] {
+			continue
+		}
+		select {
+		case ch <- ev:
+		default:
+			metrics.StreamEventsDropped.Inc()
+		}
+	}
+}
+
+// subscribe adds a client wanting types, all when nil.
+func (h *Hub) subscribe(types map[string]bool) chan Event {
+	ch := make(chan Event, h.buffer)
+	h.mu.Lock()
+	h.clients[ch] = types
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *Hub) unsubscribe(ch chan Event) {
+	h.mu.Lock()
+	delete(h.clients, ch)
+	h.mu.Unlock()
+}
+
+// parseTypes parses the comma-separated types query parameter.
+func parseTypes(list string) (map[string]bool, error) {
+	if list == "" {
+		return nil, nil
+	}
+	types := make(map[string]bool)
+	for _, typ := range strings.Split(list, ",") {
+		typ = strings.TrimSpace(typ)
+		known := false
+		for _, t := range Types {
+			known = known || t == typ
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown event type %q, expected one of %s", typ, strings.Join(Types, ", "))
+		}
+		types[typ] = true
+	}
+	return types, nil
+}
+
+// ServeHTTP streams events to the client until it disconnects. The types
+// query parameter limits the stream to a comma-separated list of types.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	types, err := parseTypes(r.URL.Query().Get("types"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ch := h.subscribe(types)
+	defer h.unsubscribe(ch)
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case ev := <-ch:
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
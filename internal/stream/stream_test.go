@@ -0,0 +1,82 @@
+package stream
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/primev/preconf_blob_bidder/internal/outcome"
+	"github.com/stretchr/testify/require"
+)
+
+func (h *Hub) clientCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+func TestStream(t *testing.T) {
+	h := New()
+	h.Publish(BidSent, Bid{TxHash: "0x00"}) // Nobody listening
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "?types=" + BidResolved + "," + BidSettled)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	require.Eventually(t, func() bool { return h.clientCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	h.Publish(BidSent, Bid{TxHash: "0x01"}) // Filtered out
+	h.Publish(BidResolved, Resolved(outcome.BlockOutcome{
+		TargetBlock: 100,
+		Payload:     outcome.Payload{TxHash: "0x01", Lane: "blob", From: common.Address{1}},
+		Bid:         outcome.Bid{AmountETH: 0.001, Err: errors.New("no providers")},
+	}))
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+	require.Equal(t, "id: 2", lines[0], "ids count every published event")
+	require.Equal(t, "event: "+BidResolved, lines[1])
+	var ev struct {
+		Type string `json:"type"`
+		Data Bid    `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &ev))
+	require.Equal(t, BidResolved, ev.Type)
+	require.Equal(t, uint64(100), ev.Data.TargetBlock)
+	require.Equal(t, "no providers", ev.Data.Error)
+
+	resp.Body.Close()
+	require.Eventually(t, func() bool { return h.clientCount() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestStreamRejectsUnknownTypes(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events?types=bid_won", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), `unknown event type "bid_won"`)
+}
+
+func TestSlowClientDropsEvents(t *testing.T) {
+	h := New()
+	h.buffer = 1
+	ch := h.subscribe(nil)
+	h.Publish(BidSent, nil)
+	h.Publish(BidSent, nil) // Dropped, not blocking
+	require.Len(t, ch, 1)
+
+	var nilHub *Hub
+	nilHub.Publish(BidSent, nil)
+}
@@ -0,0 +1,242 @@
+// Package telemetry reports anonymized aggregate statistics of the bidder to
+// its maintainers, so recurring failures such as WebSocket drops can be
+// prioritized. It is opt-in: nothing is sent unless TELEMETRY is enabled.
+//
+// A report holds only the bidder version, platform, mev-commit network, uptime
+// and counters taken from the Prometheus metrics: bids, acceptance rate,
+// failures by stage, skipped blocks by reason and reconnections. Addresses,
+// transaction hashes, amounts, endpoints and keys are never included.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// DefaultEndpoint receives the reports.
+const DefaultEndpoint = "https://telemetry.mev-commit.xyz/v1/bidder"
+
+// DefaultInterval is how often a report is sent.
+const DefaultInterval = time.Hour
+
+// Metric families a report is built from.
+const (
+	familyBidsSent      = "preconf_bidder_bids_sent_total"
+	familyBidsAccepted  = "preconf_bidder_bids_accepted_total"
+	familyBidFailures   = "preconf_bidder_bid_failures_total"
+	familyBlocksSkipped = "preconf_bidder_blocks_skipped_total"
+	familyWSReconnects  = "preconf_bidder_ws_reconnects_total"
+	familyBidderRecon   = "preconf_bidder_bidder_reconnects_total"
+)
+
+// Report is what is sent. Counters are totals since the bidder started.
+type Report struct {
+	// Session is random per run, so reports of one run can be told apart
+	// without identifying the operator.
+	Session        string             `json:"session"`
+	Version        string             `json:"version"`
+	Platform       string             `json:"platform"`
+	Network        string             `json:"network"`
+	Uptime         float64            `json:"uptime_seconds"`
+	BidsSent       float64            `json:"bids_sent"`
+	BidsAccepted   float64            `json:"bids_accepted"`
+	AcceptanceRate float64            `json:"acceptance_rate"`
+	BidFailures    map[string]float64 `json:"bid_failures"`   // By stage.
+	BlocksSkipped  map[string]float64 `json:"blocks_skipped"` // By reason.
+	WSReconnects   float64            `json:"ws_reconnects"`
+	BidderRecon    float64            `json:"bidder_reconnects"`
+}
+
+// Status describes the reporter for the status server.
+type Status struct {
+	Enabled    bool      `json:"enabled"`
+	Endpoint   string    `json:"endpoint,omitempty"`
+	Interval   string    `json:"interval,omitempty"`
+	LastSent   time.Time `json:"last_sent,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+	LastReport *Report   `json:"last_report,omitempty"` // Exactly what was sent last.
+	Fields     []string  `json:"fields"`                // Everything a report may contain.
+}
+
+// Reporter builds reports from a metrics gatherer and sends them.
+type Reporter struct {
+	endpoint string
+	interval time.Duration
+	gatherer prometheus.Gatherer
+	client   *http.Client
+	started  time.Time
+	base     Report
+
+	mu       sync.Mutex
+	lastSent time.Time
+	lastErr  error
+	last     *Report
+}
+
+// New returns a reporter sending reports about the bidder of version on
+// network to endpoint every interval. A nil reporter is disabled.
+func New(endpoint string, interval time.Duration, gatherer prometheus.Gatherer, version, network string) *Reporter {
+	session := make([]byte, 8)
+	_, _ = rand.Read(session)
+	return &Reporter{
+		endpoint: endpoint,
+		interval: interval,
+		gatherer: gatherer,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		started:  time.Now(),
+		base: Report{
+			Session:  hex.EncodeToString(session),
+			Version:  version,
+			Platform: runtime.GOOS + "/" + runtime.GOARCH,
+			Network:  network,
+		},
+	}
+}
+
+// Build returns the current report.
+func (r *Reporter) Build() Report {
+	report := r.base
+	report.Uptime = time.Since(r.started).Round(time.Second).Seconds()
+	report.BidFailures = make(map[string]float64)
+	report.BlocksSkipped = make(map[string]float64)
+	families, err := r.gatherer.Gather()
+	if err != nil {
+		slog.Debug("Failed to gather metrics for telemetry", "error", err)
+	}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			v := m.GetCounter().GetValue()
+			switch family.GetName() {
+			case familyBidsSent:
+				report.BidsSent += v
+			case familyBidsAccepted:
+				report.BidsAccepted += v
+			case familyBidFailures:
+				report.BidFailures[label(m, "stage")] += v
+			case familyBlocksSkipped:
+				report.BlocksSkipped[label(m, "reason")] += v
+			case familyWSReconnects:
+				report.WSReconnects += v
+			case familyBidderRecon:
+				report.BidderRecon += v
+			}
+		}
+	}
+	if report.BidsSent > 0 {
+		report.AcceptanceRate = report.BidsAccepted / report.BidsSent
+	}
+	return report
+}
+
+func label(m *dto.Metric, name string) string {
+	for _, l := range m.GetLabel() {
+		if l.GetName() == name {
+			return l.GetValue()
+		}
+	}
+	return ""
+}
+
+// Send posts the current report.
+func (r *Reporter) Send(ctx context.Context) error {
+	report := r.Build()
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		var resp *http.Response
+		if resp, err = r.client.Do(req); err == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode >= 300 {
+				err = fmt.Errorf("telemetry endpoint returned HTTP %d", resp.StatusCode)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastErr = err
+	if err == nil {
+		r.lastSent, r.last = time.Now(), &report
+	}
+	return err
+}
+
+// Run sends a report every interval until ctx is canceled. The first report
+// is sent after one interval, so short runs send nothing.
+func (r *Reporter) Run(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Send(ctx); err != nil && ctx.Err() == nil {
+				slog.Debug("Failed to send telemetry", "error", err)
+			}
+		}
+	}
+}
+
+// Status returns the reporter's status; a nil reporter is disabled.
+func (r *Reporter) Status() Status {
+	s := Status{Fields: Fields()}
+	if r == nil {
+		return s
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s.Enabled = true
+	s.Endpoint = r.endpoint
+	s.Interval = r.interval.String()
+	s.LastSent = r.lastSent
+	s.LastReport = r.last
+	if r.lastErr != nil {
+		s.LastError = r.lastErr.Error()
+	}
+	return s
+}
+
+// ServeHTTP serves the status as JSON.
+func (r *Reporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(struct {
+		Telemetry Status `json:"telemetry"`
+	}{r.Status()}); err != nil {
+		slog.Warn("Failed to write telemetry status", "error", err)
+	}
+}
+
+// Fields returns the JSON names of every field a report may contain.
+func Fields() []string {
+	t := reflect.TypeOf(Report{})
+	fields := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+	}
+	return fields
+}
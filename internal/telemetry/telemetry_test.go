@@ -0,0 +1,68 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporterSendsAggregates(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	sent := prometheus.NewCounter(prometheus.CounterOpts{Name: familyBidsSent})
+	accepted := prometheus.NewCounter(prometheus.CounterOpts{Name: familyBidsAccepted})
+	failures := prometheus.NewCounterVec(prometheus.CounterOpts{Name: familyBidFailures}, []string{"stage"})
+	wsReconnects := prometheus.NewCounter(prometheus.CounterOpts{Name: familyWSReconnects})
+	registry.MustRegister(sent, accepted, failures, wsReconnects)
+	sent.Add(4)
+	accepted.Add(3)
+	failures.WithLabelValues("send").Add(2)
+	wsReconnects.Add(5)
+
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer srv.Close()
+
+	r := New(srv.URL, time.Hour, registry, "v1.0.0", "testnet")
+	require.NoError(t, r.Send(context.Background()))
+	require.Equal(t, "v1.0.0", got["version"])
+	require.Equal(t, "testnet", got["network"])
+	require.Equal(t, 0.75, got["acceptance_rate"])
+	require.Equal(t, map[string]any{"send": 2.0}, got["bid_failures"])
+	require.Equal(t, 5.0, got["ws_reconnects"])
+	fields := Fields()
+	for key := range got {
+		require.Contains(t, fields, key, "every sent field is documented")
+	}
+
+	status := r.Status()
+	require.True(t, status.Enabled)
+	require.NotNil(t, status.LastReport)
+	require.Empty(t, status.LastError)
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	require.ErrorContains(t, r.Send(context.Background()), "HTTP 503")
+	require.Contains(t, r.Status().LastError, "HTTP 503")
+}
+
+func TestDisabledReporter(t *testing.T) {
+	var r *Reporter
+	r.Run(context.Background())
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/telemetry", nil))
+	var body struct {
+		Telemetry Status `json:"telemetry"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.False(t, body.Telemetry.Enabled)
+	require.Contains(t, body.Telemetry.Fields, "acceptance_rate")
+}
@@ -0,0 +1,130 @@
+// Package tracing follows a bid through the bidding path with OpenTelemetry
+// spans: the header it was built for, building and signing its transaction,
+// sending it to the bidder node and receiving its commitments. The trace
+// context goes along with the gRPC calls to the bidder node, so its own spans
+// join the bidder's. Spans are exported to an OTLP endpoint, where the
+// latency of every stage can be compared; without one they cost next to
+// nothing.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// Stages of the bidding path, the names of their spans.
+const (
+	Header     = "header"     // A new header, from its arrival until it is handed to the lanes.
+	BuildTx    = "build_tx"   // Building the transaction of a bid, including its signature.
+	Sign       = "sign"       // Signing the transaction.
+	SendBid    = "send_bid"   // Sending a bid, until its response stream ends.
+	Commitment = "commitment" // Receiving the commitments of a sent bid.
+)
+
+// DefaultSampleRatio traces every header.
+const DefaultSampleRatio = 1.0
+
+// tracerName is the instrumentation scope of the bidder's spans.
+const tracerName = "github.com/primev/preconf_blob_bidder"
+
+// tracesPath is where collectors receive spans over OTLP/HTTP.
+const tracesPath = "/v1/traces"
+
+// Config is where spans are exported and how many traces are kept.
+type Config struct {
+	Endpoint    string  // OTLP/HTTP endpoint URL, e.g. http://localhost:4318; empty disables tracing.
+	SampleRatio float64 // Share of traces kept, between 0 and 1; a child follows its parent.
+	Service     string  // Service name of the exported spans.
+	Version     string
+}
+
+// ValidateEndpoint checks an OTLP endpoint is an HTTP(S) URL.
+func ValidateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid OTLP endpoint %q, must be an http or https URL", endpoint)
+	}
+	return nil
+}
+
+// tracesURL returns the URL spans are posted to at endpoint, the standard
+// path unless it has one.
+func tracesURL(endpoint string) (string, error) {
+	if err := ValidateEndpoint(endpoint); err != nil {
+		return "", err
+	}
+	u, _ := url.Parse(endpoint)
+	if u.Path == "" || u.Path == "/" {
+		u.Path = tracesPath
+	}
+	return u.String(), nil
+}
+
+// Setup exports spans to cfg.Endpoint and propagates the trace context of
+// outgoing calls. The returned function flushes the spans not exported yet
+// and stops exporting. Without an endpoint, spans are not recorded and
+// shutdown does nothing.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	endpoint, err := tracesURL(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	provider := newProvider(sdktrace.WithBatcher(exporter), cfg)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// newProvider returns a tracer provider sending spans to processor, sampled
+// as cfg says.
+func newProvider(processor sdktrace.TracerProviderOption, cfg Config) *sdktrace.TracerProvider {
+	attrs := []attribute.KeyValue{attribute.String("service.name", cfg.Service)}
+	if cfg.Version != "" {
+		attrs = append(attrs, attribute.String("service.version", cfg.Version))
+	}
+	return sdktrace.NewTracerProvider(
+		processor,
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attrs...)),
+	)
+}
+
+// Start starts a span for a stage, a child of the span of ctx, and returns
+// ctx carrying it.
+func Start(ctx context.Context, stage string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, stage, trace.WithAttributes(attrs...))
+}
+
+// End ends span, marking it failed with err unless err is nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// DialOptions returns the options tracing the calls of a gRPC client, each in
+// a span of its own carrying its trace context to the server.
+func DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{grpc.WithStatsHandler(otelgrpc.NewClientHandler())}
+}
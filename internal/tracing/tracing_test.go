@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStages(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := newProvider(sdktrace.WithSyncer(exporter), Config{SampleRatio: DefaultSampleRatio, Service: "preconf_bidder"})
+	defer provider.Shutdown(context.Background())
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	ctx, header := Start(context.Background(), Header)
+	buildCtx, build := Start(ctx, BuildTx)
+	_, sign := Start(buildCtx, Sign)
+	End(sign, errors.New("signer unavailable"))
+	End(build, nil)
+	End(header, nil)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	byName := make(map[string]tracetest.SpanStub)
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	require.Equal(t, byName[Header].SpanContext.TraceID(), byName[Sign].SpanContext.TraceID())
+	require.Equal(t, byName[Header].SpanContext.SpanID(), byName[BuildTx].Parent.SpanID())
+	require.Equal(t, byName[BuildTx].SpanContext.SpanID(), byName[Sign].Parent.SpanID())
+	require.Equal(t, codes.Error, byName[Sign].Status.Code)
+	require.Equal(t, "signer unavailable", byName[Sign].Status.Description)
+	require.Equal(t, codes.Unset, byName[BuildTx].Status.Code)
+}
+
+func TestSetupWithoutEndpoint(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), Config{Endpoint: "localhost:4318"})
+	require.Error(t, err)
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	require.NoError(t, ValidateEndpoint("http://localhost:4318"))
+	require.NoError(t, ValidateEndpoint("https://otel.example.com/v1/traces"))
+	require.Error(t, ValidateEndpoint("localhost:4318"))
+	require.Error(t, ValidateEndpoint("grpc://localhost:4317"))
+
+	u, err := tracesURL("http://localhost:4318")
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:4318/v1/traces", u)
+	u, err = tracesURL("https://otel.example.com/custom/traces")
+	require.NoError(t, err)
+	require.Equal(t, "https://otel.example.com/custom/traces", u)
+}
@@ -0,0 +1,421 @@
+// Package tui draws a live status screen in the terminal with bubbletea, for
+// operators who watch the bidder rather than scroll through its logs: the
+// head block, the last bids and their commitments, the deposits of the
+// current bidding windows, connection health and error counts. While the
+// screen runs, log records are kept for its bottom pane instead of being
+// written out, and keys stop the bidder, refresh the deposits or expand the
+// log pane.
+package tui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/primev/preconf_blob_bidder/internal/dashboard"
+	"github.com/primev/preconf_blob_bidder/internal/money"
+	"github.com/primev/preconf_blob_bidder/internal/outcome"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultInterval is how often the screen is redrawn.
+	DefaultInterval = time.Second
+	// DefaultDepositInterval is how often the window deposits are fetched.
+	DefaultDepositInterval = 30 * time.Second
+
+	maxBids         = 10
+	maxLogs         = 8 // Log lines shown, or maxExpandedLogs with the log pane expanded.
+	maxExpandedLogs = 40
+	maxWidth        = 120 // Log lines are cut to the terminal width, or this before it is known.
+)
+
+// Keys the screen answers to.
+const (
+	keyQuit    = "q"
+	keyRefresh = "r"
+	keyLogs    = "l"
+)
+
+// ANSI colors.
+const (
+	red    = "\x1b[31m"
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+	bold   = "\x1b[1m"
+	reset  = "\x1b[0m"
+)
+
+// Metric families counted as errors, by label shown.
+var errorFamilies = []struct{ family, label string }{
+	{"preconf_bidder_bid_failures_total", "bid failures"},
+	{"preconf_bidder_tx_send_errors_total", "tx send errors"},
+	{"preconf_bidder_ws_reconnects_total", "ws reconnects"},
+	{"preconf_bidder_bidder_reconnects_total", "bidder reconnects"},
+	{"preconf_bidder_blocks_skipped_total", "skipped blocks"},
+}
+
+// Deposit is the deposit of a bidding window.
+type Deposit struct {
+	Window uint64
+	Wei    *big.Int
+	Err    error
+}
+
+// Config holds where the screen gets what it shows. Every source may be nil.
+type Config struct {
+	Title       string
+	Gatherer    prometheus.Gatherer
+	Connections func() []dashboard.Connection
+	// Deposits returns the deposits of the windows of interest for the head
+	// block.
+	Deposits func(ctx context.Context, head uint64) []Deposit
+	// Quit stops the bidder. The screen reads the keyboard, so Ctrl+C and q
+	// reach it instead of the terminal's interrupt signal.
+	Quit func()
+}
+
+// bid is a resolved bid as shown.
+type bid struct {
+	target      uint64
+	lane        string
+	amountETH   float64
+	sent        bool
+	commitments int
+	err         error
+	txHash      string
+}
+
+// Screen holds the state drawn on the terminal.
+type Screen struct {
+	in       io.Reader
+	out      io.Writer
+	fallback io.Writer
+	now      func() time.Time
+	started  time.Time
+
+	mu           sync.Mutex
+	running      bool
+	head         uint64
+	headAt       time.Time
+	bids         []bid // Newest first.
+	deposits     []Deposit
+	logs         []string // Oldest first.
+	expandedLogs bool
+	width        int // Terminal width, 0 until known.
+}
+
+// New returns a screen drawing on out and reading keys from stdin. Log
+// records written to it before Run starts and after it returns go to
+// fallback.
+func New(out, fallback io.Writer) *Screen {
+	return &Screen{in: os.Stdin, out: out, fallback: fallback, now: time.Now, started: time.Now()}
+}
+
+// Head records a new head block.
+func (s *Screen) Head(block uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.head, s.headAt = block, s.now()
+}
+
+// Bid records a resolved bid.
+func (s *Screen) Bid(o outcome.BlockOutcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bids = append([]bid{{
+		target:      o.TargetBlock,
+		lane:        o.Payload.Lane,
+		amountETH:   o.Bid.AmountETH,
+		sent:        o.Bid.Sent,
+		commitments: len(o.Commitments),
+		err:         o.Bid.Err,
+		txHash:      o.Payload.TxHash,
+	}}, s.bids...)
+	if len(s.bids) > maxBids {
+		s.bids = s.bids[:maxBids]
+	}
+}
+
+// Write takes a log record, compacting JSON records to a single line. It
+// implements io.Writer for the log handler.
+func (s *Screen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		return s.fallback.Write(p)
+	}
+	var line bytes.Buffer
+	if err := json.Compact(&line, p); err != nil {
+		line.Reset()
+		line.Write(bytes.TrimSpace(p))
+	}
+	s.logs = append(s.logs, line.String())
+	if len(s.logs) > maxExpandedLogs {
+		s.logs = s.logs[len(s.logs)-maxExpandedLogs:]
+	}
+	return len(p), nil
+}
+
+// Run shows the screen, redrawn every interval, until ctx is canceled or
+// the screen is quit, then restores the terminal.
+func (s *Screen) Run(ctx context.Context, cfg Config, interval time.Duration) {
+	s.mu.Lock()
+	s.running = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	p := tea.NewProgram(&model{screen: s, cfg: cfg, ctx: ctx, interval: interval},
+		tea.WithContext(ctx),
+		tea.WithInput(s.in),
+		tea.WithOutput(s.out),
+		tea.WithAltScreen(),
+		// SIGINT and SIGTERM are left to the bidder's own shutdown
+		tea.WithoutSignalHandler(),
+	)
+	if _, err := p.Run(); err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+		fmt.Fprintf(s.fallback, "terminal UI failed: %v\n", err)
+	}
+}
+
+// tickMsg redraws the screen, and depositsMsg carries fetched deposits.
+type (
+	tickMsg     struct{}
+	depositsMsg []Deposit
+)
+
+// model is the bubbletea model of a running screen.
+type model struct {
+	screen   *Screen
+	cfg      Config
+	ctx      context.Context
+	interval time.Duration
+	fetching bool
+	fetched  time.Time
+}
+
+func (m *model) Init() tea.Cmd {
+	return tea.Batch(m.tick(), m.fetchDeposits())
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.screen
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case keyQuit, "ctrl+c":
+			if m.cfg.Quit != nil {
+				m.cfg.Quit()
+			}
+			return m, tea.Quit
+		case keyRefresh:
+			m.fetched = time.Time{}
+			return m, m.fetchDeposits()
+		case keyLogs:
+			s.mu.Lock()
+			s.expandedLogs = !s.expandedLogs
+			s.mu.Unlock()
+		}
+	case tea.WindowSizeMsg:
+		s.mu.Lock()
+		s.width = msg.Width
+		s.mu.Unlock()
+	case depositsMsg:
+		m.fetching, m.fetched = false, s.now()
+		s.mu.Lock()
+		s.deposits = msg
+		s.mu.Unlock()
+	case tickMsg:
+		var cmd tea.Cmd
+		if s.now().Sub(m.fetched) >= DefaultDepositInterval {
+			cmd = m.fetchDeposits()
+		}
+		return m, tea.Batch(m.tick(), cmd)
+	}
+	return m, nil
+}
+
+func (m *model) View() string {
+	var frame strings.Builder
+	m.screen.Render(&frame, m.cfg)
+	fmt.Fprintf(&frame, "\n%s quit  %s refresh deposits  %s expand log", keyQuit, keyRefresh, keyLogs)
+	return frame.String()
+}
+
+func (m *model) tick() tea.Cmd {
+	return tea.Tick(m.interval, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+// fetchDeposits fetches the deposits for the head block, unless they are
+// being fetched or no head block is known yet.
+func (m *model) fetchDeposits() tea.Cmd {
+	s := m.screen
+	s.mu.Lock()
+	head := s.head
+	s.mu.Unlock()
+	if m.cfg.Deposits == nil || head == 0 || m.fetching {
+		return nil
+	}
+	m.fetching = true
+	return func() tea.Msg {
+		return depositsMsg(m.cfg.Deposits(m.ctx, head))
+	}
+}
+
+// Render writes one frame of the screen to w.
+func (s *Screen) Render(w io.Writer, cfg Config) {
+	var connections []dashboard.Connection
+	if cfg.Connections != nil {
+		connections = cfg.Connections()
+	}
+	var counts map[string]map[string]float64
+	if cfg.Gatherer != nil {
+		counts = gatherErrors(cfg.Gatherer)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	fmt.Fprintf(w, "%s%s%s  up %s\n\n", bold, cfg.Title, reset, now.Sub(s.started).Round(time.Second))
+	if s.head == 0 {
+		fmt.Fprintf(w, "Head block  waiting for the first block\n")
+	} else {
+		fmt.Fprintf(w, "Head block  %d (%s ago)\n", s.head, now.Sub(s.headAt).Round(time.Second))
+	}
+
+	fmt.Fprintf(w, "\n%sConnections%s\n", bold, reset)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range connections {
+		mark := green + "up" + reset
+		if !c.Healthy {
+			mark = red + "down" + reset
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", c.Name, mark, c.Endpoint, c.Detail)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%sDeposits%s\n", bold, reset)
+	if len(s.deposits) == 0 {
+		fmt.Fprintf(w, "  unknown\n")
+	}
+	for _, d := range s.deposits {
+		if d.Err != nil {
+			fmt.Fprintf(w, "  window %d  %s%v%s\n", d.Window, red, d.Err, reset)
+		} else {
+			fmt.Fprintf(w, "  window %d  %s ETH\n", d.Window, formatETH(d.Wei))
+		}
+	}
+
+	fmt.Fprintf(w, "\n%sLast bids%s\n", bold, reset)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "  TARGET\tLANE\tAMOUNT ETH\tSTATUS\tTX\n")
+	for _, b := range s.bids {
+		fmt.Fprintf(tw, "  %d\t%s\t%.6f\t%s\t%s\n", b.target, b.lane, b.amountETH, b.status(), shortHash(b.txHash))
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%sErrors%s\n", bold, reset)
+	for _, f := range errorFamilies {
+		fmt.Fprintf(w, "  %s  %s\n", f.label, formatCounts(counts[f.family]))
+	}
+
+	fmt.Fprintf(w, "\n%sLog%s\n", bold, reset)
+	width := maxWidth
+	if s.width > 4 {
+		width = s.width
+	}
+	logs := s.logs
+	if !s.expandedLogs && len(logs) > maxLogs {
+		logs = logs[len(logs)-maxLogs:]
+	}
+	for _, line := range logs {
+		if len(line) > width-2 {
+			line = line[:width-5] + "..."
+		}
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+}
+
+// status describes the bid in a few words, colored by how it went.
+func (b bid) status() string {
+	switch {
+	case b.commitments > 0:
+		return fmt.Sprintf("%scommitted (%d)%s", green, b.commitments, reset)
+	case b.err != nil:
+		return red + b.err.Error() + reset
+	case b.sent:
+		return yellow + "no commitment" + reset
+	}
+	return yellow + "not sent" + reset
+}
+
+// gatherErrors sums the error families by their first label value, the empty
+// string for unlabeled families.
+func gatherErrors(g prometheus.Gatherer) map[string]map[string]float64 {
+	families, _ := g.Gather()
+	counts := make(map[string]map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			label := ""
+			if labels := m.GetLabel(); len(labels) > 0 {
+				label = labels[0].GetValue()
+			}
+			if counts[family.GetName()] == nil {
+				counts[family.GetName()] = make(map[string]float64)
+			}
+			counts[family.GetName()][label] += m.GetCounter().GetValue()
+		}
+	}
+	return counts
+}
+
+// formatCounts lists non-zero counts by label, or their total when
+// unlabeled.
+func formatCounts(counts map[string]float64) string {
+	if total, ok := counts[""]; ok && len(counts) == 1 {
+		return strconv.FormatFloat(total, 'f', 0, 64)
+	}
+	labels := make([]string, 0, len(counts))
+	for label, n := range counts {
+		if n > 0 {
+			labels = append(labels, fmt.Sprintf("%s %.0f", label, n))
+		}
+	}
+	if len(labels) == 0 {
+		return "0"
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, ", ")
+}
+
+// formatETH formats wei as ETH with six decimals.
+func formatETH(wei *big.Int) string {
+	if wei == nil {
+		return "0"
+	}
+	return money.FormatEthFixed(wei, 6)
+}
+
+// shortHash abbreviates a transaction hash.
+func shortHash(hash string) string {
+	if len(hash) <= 14 {
+		return hash
+	}
+	return hash[:10] + "..." + hash[len(hash)-4:]
+}
@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	pb "github.com/primev/preconf_blob_bidder/internal/bidderpb"
+	"github.com/primev/preconf_blob_bidder/internal/dashboard"
+	"github.com/primev/preconf_blob_bidder/internal/outcome"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	var fallback bytes.Buffer
+	s := New(new(bytes.Buffer), &fallback)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	s.started = now.Add(-time.Minute)
+
+	_, err := s.Write([]byte("{\n  \"msg\": \"before\"\n}\n"))
+	require.NoError(t, err)
+	require.Contains(t, fallback.String(), "before", "records go to the fallback until the screen runs")
+
+	s.running = true
+	_, err = s.Write([]byte("{\n  \"msg\": \"Bid resolved\"\n}\n"))
+	require.NoError(t, err)
+	s.Head(100)
+	now = now.Add(3 * time.Second)
+	s.Bid(outcome.BlockOutcome{
+		TargetBlock: 101,
+		Payload:     outcome.Payload{Lane: "blob", TxHash: "0x1234567890abcdef1234567890abcdef"},
+		Bid:         outcome.Bid{AmountETH: 0.0015, Sent: true},
+		Commitments: []*pb.Commitment{{}, {}},
+	})
+	s.Bid(outcome.BlockOutcome{TargetBlock: 102, Bid: outcome.Bid{Err: errors.New("deadline exceeded")}})
+	s.deposits = []Deposit{{Window: 10, Wei: big.NewInt(1e17)}}
+
+	registry := prometheus.NewRegistry()
+	skipped := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "preconf_bidder_blocks_skipped_total"}, []string{"reason"})
+	registry.MustRegister(skipped)
+	skipped.WithLabelValues("budget").Add(3)
+	skipped.WithLabelValues("busy")
+
+	var frame bytes.Buffer
+	s.Render(&frame, Config{
+		Title:    "preconf_bidder 0.8.0",
+		Gatherer: registry,
+		Connections: func() []dashboard.Connection {
+			return []dashboard.Connection{{Name: "bidder node", Healthy: true, Detail: "READY"}}
+		},
+	})
+	out := frame.String()
+	for _, want := range []string{
+		"preconf_bidder 0.8.0",
+		"up 1m3s",
+		"Head block  100 (3s ago)",
+		"bidder node",
+		"window 10  0.100000 ETH",
+		"committed (2)",
+		"0x12345678...cdef",
+		"deadline exceeded",
+		"skipped blocks  budget 3\n",
+		`{"msg":"Bid resolved"}`,
+	} {
+		require.Contains(t, out, want)
+	}
+	require.Less(t, strings.Index(out, "102"), strings.Index(out, "101"), "newest bids first")
+}
+
+func TestRunReturnsWhenCanceled(t *testing.T) {
+	var out, fallback bytes.Buffer
+	s := New(&out, &fallback)
+	s.in = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Run(ctx, Config{Title: "bidder"}, time.Second)
+	require.Empty(t, fallback.String(), "a canceled screen is not a failure")
+
+	_, err := s.Write([]byte("after\n"))
+	require.NoError(t, err)
+	require.Equal(t, "after\n", fallback.String(), "records go to the fallback once the screen stopped")
+}
+
+func TestModelKeys(t *testing.T) {
+	s := New(new(bytes.Buffer), new(bytes.Buffer))
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	s.running = true
+	for i := 0; i < maxExpandedLogs; i++ {
+		_, err := s.Write([]byte(fmt.Sprintf("record %02d\n", i)))
+		require.NoError(t, err)
+	}
+
+	quit, fetches := 0, 0
+	m := &model{screen: s, ctx: context.Background(), interval: time.Second, cfg: Config{
+		Quit: func() { quit++ },
+		Deposits: func(_ context.Context, head uint64) []Deposit {
+			fetches++
+			return []Deposit{{Window: head / 10, Wei: big.NewInt(1)}}
+		},
+	}}
+	require.Nil(t, m.fetchDeposits(), "deposits are only fetched once the head block is known")
+
+	m.Update(tea.WindowSizeMsg{Width: 40, Height: 20})
+	view := m.View()
+	require.Contains(t, view, "record 39")
+	require.NotContains(t, view, "record 31", "the log pane shows the last records")
+	require.Contains(t, view, "q quit")
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(keyLogs)})
+	require.Contains(t, m.View(), "record 00", "l expands the log pane")
+
+	s.Head(100)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(keyRefresh)})
+	require.NotNil(t, cmd, "r fetches the deposits")
+	require.Nil(t, m.fetchDeposits(), "deposits are fetched once at a time")
+	m.Update(cmd())
+	require.Equal(t, 1, fetches)
+	require.Contains(t, m.View(), "window 10")
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	require.Equal(t, 1, quit, "ctrl+c stops the bidder")
+	require.IsType(t, tea.QuitMsg{}, cmd())
+}
@@ -2,45 +2,266 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
-	"math"
 	"math/big"
 	"math/rand"
+	"net/http"
 	"net/url"
 	"os"
-	"strconv"
+	"os/signal"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	ee "github.com/primev/preconf_blob_bidder/internal/eth"
+	"github.com/primev/preconf_blob_bidder/internal/accounting"
+	"github.com/primev/preconf_blob_bidder/internal/backfill"
+	"github.com/primev/preconf_blob_bidder/internal/auth"
+	"github.com/primev/preconf_blob_bidder/internal/campaign"
+	"github.com/primev/preconf_blob_bidder/internal/canary"
+	"github.com/primev/preconf_blob_bidder/internal/clock"
+	"github.com/primev/preconf_blob_bidder/internal/competition"
+	"github.com/primev/preconf_blob_bidder/internal/config"
+	"github.com/primev/preconf_blob_bidder/internal/conflicts"
+	"github.com/primev/preconf_blob_bidder/internal/control"
+	"github.com/primev/preconf_blob_bidder/internal/coordination"
+	"github.com/primev/preconf_blob_bidder/internal/dashboard"
+	"github.com/primev/preconf_blob_bidder/internal/dispute"
+	errs "github.com/primev/preconf_blob_bidder/internal/errors"
+	"github.com/primev/preconf_blob_bidder/internal/fallback"
+	"github.com/primev/preconf_blob_bidder/internal/feedback"
+	"github.com/primev/preconf_blob_bidder/internal/health"
+	"github.com/primev/preconf_blob_bidder/internal/ident"
+	"github.com/primev/preconf_blob_bidder/internal/inclusion"
+	"github.com/primev/preconf_blob_bidder/internal/inflight"
+	"github.com/primev/preconf_blob_bidder/internal/jobs"
+	"github.com/primev/preconf_blob_bidder/internal/labels"
+	"github.com/primev/preconf_blob_bidder/internal/lanes"
+	"github.com/primev/preconf_blob_bidder/internal/logging"
+	"github.com/primev/preconf_blob_bidder/internal/latency"
+	"github.com/primev/preconf_blob_bidder/internal/metrics"
+	"github.com/primev/preconf_blob_bidder/internal/pricing"
+	"github.com/primev/preconf_blob_bidder/internal/providers"
 	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+	"github.com/primev/preconf_blob_bidder/internal/outcome"
+	"github.com/primev/preconf_blob_bidder/internal/pressure"
+	"github.com/primev/preconf_blob_bidder/internal/schedule"
+	"github.com/primev/preconf_blob_bidder/internal/skips"
+	"github.com/primev/preconf_blob_bidder/internal/slots"
+	"github.com/primev/preconf_blob_bidder/internal/status"
+	"github.com/primev/preconf_blob_bidder/internal/store"
+	"github.com/primev/preconf_blob_bidder/internal/stream"
+	"github.com/primev/preconf_blob_bidder/internal/strategy"
+	"github.com/primev/preconf_blob_bidder/internal/telemetry"
+	"github.com/primev/preconf_blob_bidder/internal/tracing"
+	"github.com/primev/preconf_blob_bidder/internal/tui"
 	"github.com/urfave/cli/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	FlagEnv                       = "env"
+	FlagConfig                    = "config"
 	FlagServerAddress             = "server-address"
 	FlagUsePayload                = "use-payload"
 	FlagRpcEndpoint               = "rpc-endpoint"
 	FlagWsEndpoint                = "ws-endpoint"
+	FlagBackfillBlocks            = "backfill-blocks"
+	FlagBidOnReconnect            = "bid-on-reconnect"
+	FlagWSStallSlots              = "ws-stall-slots"
 	FlagPrivateKey                = "private-key"
+	FlagKeystorePath              = "keystore-path"
+	FlagKeystorePassword          = "keystore-password"
+	FlagKeystorePasswordFile      = "keystore-password-file"
+	FlagRemoteSignerURL           = "remote-signer-url"
+	FlagRemoteSignerKind          = "remote-signer-kind"
+	FlagRemoteSignerAddress       = "remote-signer-address"
 	FlagOffset                    = "offset"
+	FlagTargetBlockSpan           = "target-block-span"
 	FlagBidAmount                 = "bid-amount"
 	FlagBidAmountStdDevPercentage = "bid-amount-std-dev-percentage"
 	FlagNumBlob                   = "num-blob"
+	FlagKZGTrustedSetup           = "kzg-trusted-setup"
+	FlagHourlyBudget              = "hourly-budget"
+	FlagDailyBudget               = "daily-budget"
+	FlagBudgetMode                = "budget-mode"
+	FlagMaxBidsPerBlock           = "max-bids-per-block"
+	FlagTxType                    = "tx-type"
+	FlagERC20Token                = "erc20-token"
+	FlagERC20Recipient            = "erc20-recipient"
+	FlagERC20Amount               = "erc20-amount"
+	FlagTransferAmount            = "transfer-amount"
+	FlagTransferTo                = "transfer-to"
+	FlagContractAddress           = "contract-address"
+	FlagContractABI               = "contract-abi"
+	FlagContractMethod            = "contract-method"
+	FlagContractArgs              = "contract-args"
+	FlagContractCalldata          = "contract-calldata"
+	FlagRawTxFile                 = "raw-tx-file"
+	FlagTransferPrivateKey        = "transfer-private-key"
+	FlagExtraPrivateKeys          = "extra-private-keys"
+	FlagExtraKeystorePaths        = "extra-keystore-paths"
+	FlagAccountRotation           = "account-rotation"
+	FlagNonceManager              = "nonce-manager"
+	FlagNonceResync               = "nonce-resync"
+	FlagStaleTxReplacements       = "stale-tx-replacements"
+	FlagReconcileBlocks           = "reconcile-blocks"
+	FlagDecayMin                  = "decay-min"
+	FlagDecayMax                  = "decay-max"
+	FlagDecayClamp                = "decay-clamp"
+	FlagBidSlotOffset             = "bid-slot-offset"
+	FlagGenesisTime               = "genesis-time"
 	FlagDefaultTimeout            = "default-timeout"
 	FlagRunDurationMinutes        = "run-duration-minutes"
+	FlagLatencyBudgets            = "latency-budgets"
+	FlagDryRun                    = "dry-run"
+	FlagTUI                       = "tui"
+	FlagLogFormat                 = "log-format"
+	FlagLogLevel                  = "log-level"
+	FlagLogFile                   = "log-file"
+	FlagLogMaxSizeMB              = "log-max-size-mb"
+	FlagLogMaxAge                 = "log-max-age"
+	FlagLogMaxBackups             = "log-max-backups"
+	FlagLogSampleBurst            = "log-sample-burst"
+	FlagLogSampleInterval         = "log-sample-interval"
+	FlagOTLPEndpoint              = "otlp-endpoint"
+	FlagTraceSampleRatio          = "trace-sample-ratio"
+	FlagTenant                    = "tenant"
 
 	// New flags for AppName and Version
 	FlagAppName = "app-name"
 	FlagVersion = "version"
 
-	FlagPriorityFeeGwei = "priority-fee-gwei"
+	FlagUserAgent       = "user-agent"
+	FlagRequestIDPrefix = "request-id-prefix"
+
+	FlagPriorityFeeGwei  = "priority-fee-gwei"
+	FlagGasOracle        = "gas-oracle"
+	FlagGasTipPercentile = "gas-tip-percentile"
+	FlagMaxBaseFeeGwei   = "max-base-fee-gwei"
+	FlagFeeBump          = "fee-bump"
+
+	FlagBlobFeeSource      = "blob-fee-source"
+	FlagBlobFeeBump        = "blob-fee-bump"
+	FlagMaxBlobFeeCapGwei  = "max-blob-fee-cap-gwei"
+	FlagBlobFeeCeilingGwei = "blob-fee-ceiling-gwei"
+
+	FlagRetainRawPayloads = "retain-raw-payloads"
+	FlagPayloadPrivacy    = "payload-privacy"
+
+	FlagSubmissionBackend  = "submission-backend"
+	FlagPreconfRPCEndpoint = "preconf-rpc-endpoint"
+
+	FlagPublicFallbackBlocks = "public-fallback-blocks"
+	FlagPublicRPCEndpoint    = "public-rpc-endpoint"
+
+	FlagBidderTLS       = "bidder-tls"
+	FlagBidderTLSCACert = "bidder-tls-ca-cert"
+	FlagBidderTLSCert   = "bidder-tls-cert"
+	FlagBidderTLSKey    = "bidder-tls-key"
+	FlagBidderToken     = "bidder-token"
+
+	FlagBidRetryBudget     = "bid-retry-budget"
+	FlagBidRetryBackoff    = "bid-retry-backoff"
+	FlagBidCompression     = "bid-compression"
+	FlagMaxBidPayloadBytes = "max-bid-payload-bytes"
+
+	FlagAutoRollover    = "auto-rollover"
+	FlagDepositAmount   = "deposit-amount"
+	FlagBlocksPerWindow = "blocks-per-window"
+
+	FlagAutoWithdraw       = "auto-withdraw"
+	FlagAutoWithdrawDryRun = "auto-withdraw-dry-run"
+
+	FlagHALeaseFile  = "ha-lease-file"
+	FlagHAInstanceID = "ha-instance-id"
+	FlagHALeaseTTL   = "ha-lease-ttl"
+
+	FlagSeed       = "seed"
+	FlagRecordFile = "record-file"
+
+	FlagStaleBidBlocks  = "stale-bid-blocks"
+	FlagActivityFile    = "activity-file"
+	FlagSkipLogFile     = "skip-log-file"
+	FlagConflictLogFile = "conflict-log-file"
+	FlagOwnTxFile       = "own-tx-file"
+	FlagDisputeFile     = "dispute-file"
+	FlagDisputeEndpoint = "dispute-endpoint"
+
+	FlagInclusionTracking = "inclusion-tracking"
+	FlagInclusionLogFile  = "inclusion-log-file"
+	FlagBidHistoryFile  = "bid-history-file"
+
+	FlagBidHistoryRetention = "bid-history-retention"
+
+	FlagClockSkewThreshold = "clock-skew-threshold"
+	FlagClockCompensate    = "clock-compensate"
+	FlagNTPServer          = "ntp-server"
+
+	FlagStrategy       = "strategy"
+	FlagStrategyScript = "strategy-script"
+	FlagStrategyPlugin = "strategy-plugin"
+
+	FlagAdaptiveTargetRate = "adaptive-target-rate"
+	FlagAdaptiveStep       = "adaptive-step"
+	FlagAdaptiveMinScale   = "adaptive-min-scale"
+	FlagAdaptiveMaxScale   = "adaptive-max-scale"
+	FlagProviderWeighting  = "provider-weighting"
+
+	FlagCanaryPercent = "canary-percent"
+	FlagCanaryBlocks  = "canary-blocks"
+
+	FlagBackrunTx         = "backrun-tx"
+	FlagBundleHints       = "bundle-hints"
+	FlagBundleSearchKey   = "bundle-search-key"
+	FlagBundleReplacement = "bundle-replacement"
+	FlagBundleValidity    = "bundle-validity"
+	FlagBundleRelays      = "bundle-relays"
+	FlagDisabledRelays    = "disabled-relays"
+	FlagSubmitMethod      = "submit-method"
+	FlagPrivateTxPrefs    = "private-tx-preferences"
+
+	FlagMevCommitWSEndpoint = "mev-commit-ws-endpoint"
+
+	FlagDrainTimeout = "drain-timeout"
+
+	FlagMaxRSSMB      = "max-rss-mb"
+	FlagMaxGoroutines = "max-goroutines"
+
+	FlagConfigSnapshotFile = "config-snapshot-file"
+
+	FlagStatusAddress  = "status-address"
+	FlagStatusInterval = "status-interval"
+	FlagDisableJobs    = "disable-jobs"
+
+	FlagStatusReadTokens  = "status-read-tokens"
+	FlagStatusAdminTokens = "status-admin-tokens"
+	FlagControlAuditFile  = "control-audit-file"
+
+	FlagCampaignScheduleFile = "campaign-schedule-file"
+	FlagCampaignLead         = "campaign-lead"
+
+	FlagNetwork      = "network"
+	FlagContractsURL = "contracts-url"
+
+	FlagConfirmMainnet    = "confirm-mainnet"
+	FlagAllowMainnetBlobs = "allow-mainnet-blobs"
+
+	FlagTelemetry         = "telemetry"
+	FlagTelemetryEndpoint = "telemetry-endpoint"
+	FlagTelemetryInterval = "telemetry-interval"
 )
 
 // promptForInput prompts the user for input and returns the entered string
@@ -79,6 +300,22 @@ func validateWebSocketURL(input string) (string, error) {
 	return parsedURL.String(), nil
 }
 
+// validateWebSocketURLs validates a comma-separated list of WebSocket endpoints
+func validateWebSocketURLs(input string) (string, error) {
+	endpoints := bb.ParseWSEndpoints(input)
+	if len(endpoints) == 0 {
+		return "", fmt.Errorf("endpoint cannot be empty")
+	}
+	for i, endpoint := range endpoints {
+		valid, err := validateWebSocketURL(endpoint)
+		if err != nil {
+			return "", fmt.Errorf("endpoint %d: %w", i+1, err)
+		}
+		endpoints[i] = valid
+	}
+	return strings.Join(endpoints, ","), nil
+}
+
 // validatePrivateKey ensures the private key is a 64-character hexadecimal string
 func validatePrivateKey(input string) error {
 	if len(input) != 64 {
@@ -87,98 +324,196 @@ func validatePrivateKey(input string) error {
 	return nil
 }
 
-func getOrDefault(c *cli.Context, flagName, envVar, defaultValue string) string {
-    val := c.String(flagName)
-    if val == "" {
-        val = os.Getenv(envVar)
-        if val == "" {
-            val = defaultValue
-        }
+// readRawTxFile reads the pre-signed transactions of the raw transaction type
+// and their common sender.
+func readRawTxFile(path string) ([]*types.Transaction, common.Address, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, common.Address{}, err
+    }
+    defer f.Close()
+    txs, sender, err := ee.ReadRawTransactions(f)
+    if err != nil {
+        return nil, common.Address{}, fmt.Errorf("%s: %w", path, err)
     }
-    return val
+    return txs, sender, nil
 }
 
-func getOrDefaultBool(c *cli.Context, flagName, envVar string, defaultValue bool) bool {
-    // 1. Check if the flag was explicitly set via command line
-    if c.IsSet(flagName) {
-        return c.Bool(flagName) // Use the flag's value
+// loadConfig returns the configuration from the config file, the .env file,
+// the environment and the flags. The .env file only fills in variables the
+// environment does not set, and is not copied into it.
+func loadConfig(c *cli.Context) (config.Config, error) {
+    envFile := c.String(FlagEnv)
+    if envFile == "" {
+        envFile = ".env"
     }
-
-    // 2. Check if the environment variable exists AND has a non-empty value
-    envVal, envVarExists := os.LookupEnv(envVar)
-    if envVarExists && envVal != "" {
-        // Environment variable exists and is not empty, try to parse it
-        parsedVal, err := strconv.ParseBool(envVal)
-        if err == nil {
-            return parsedVal // Parsed successfully, use parsed value
+    var fileEnv map[string]string
+    if _, err := os.Stat(envFile); err == nil {
+        fileEnv, err = config.ReadEnvFile(envFile)
+        if err != nil {
+            return config.Config{}, fmt.Errorf("failed to load %s: %w", envFile, err)
         }
-        slog.Warn("Environment variable set but could not be parsed as bool. Using default value.", "envVar", envVar, "value", envVal, "error", err, "default", defaultValue)
     }
+    cfg, err := config.Load(c.String(FlagConfig), config.LookupEnv(fileEnv), c)
+    if err != nil {
+        return cfg, err
+    }
+    // Node providers, relays and the bidder node can tell this deployment's requests apart
+    userAgent := cfg.UserAgent
+    if userAgent == "" {
+        userAgent = ident.DefaultUserAgent(cfg.AppName, cfg.Version)
+    }
+    ident.Set(userAgent, cfg.RequestIDPrefix)
+    ident.Install()
+    return cfg, nil
+}
 
-    // 3. Either the environment variable is not set, or it's empty.
-    //    In either case, use the default value (which is true in your case)
-    return defaultValue
+// resolveContracts selects the contract addresses of the configured mev-commit
+// network. Addresses set explicitly take precedence.
+func resolveContracts(ctx context.Context, cfg config.Config) error {
+    overrides := cfg.ContractOverrides()
+    if overrides.Complete() {
+        bb.UseContracts(overrides)
+        return nil
+    }
+    ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+    defer cancel()
+    manifest, err := bb.ResolveManifest(ctx, cfg.Network, cfg.ContractsURL, cacheDir())
+    if err != nil {
+        return err
+    }
+    return bb.UseManifest(manifest, overrides)
 }
 
-func getOrDefaultUint64(c *cli.Context, flagName, envVar string, defaultValue uint64) uint64 {
-    val := c.Uint64(flagName)
-    if !c.IsSet(flagName) {
-        envVal := os.Getenv(envVar)
-        if envVal == "" {
-            return defaultValue
-        }
-        parsedVal, err := strconv.ParseUint(envVal, 10, 64)
-        if err != nil {
-            return defaultValue
-        }
-        return parsedVal
+// cacheDir returns the directory for state kept between runs, or "" when the
+// user has no cache directory.
+func cacheDir() string {
+    dir, err := os.UserCacheDir()
+    if err != nil {
+        return ""
     }
-    return val
+    return filepath.Join(dir, "preconf_bidder")
 }
 
-func getOrDefaultFloat64(c *cli.Context, flagName, envVar string, defaultValue float64) float64 {
-    val := c.Float64(flagName)
-    if !c.IsSet(flagName) {
-        envVal := os.Getenv(envVar)
-        if envVal == "" {
-            return defaultValue
+// logConfigChanges logs the hash of the configuration and the settings that
+// changed since the previous run, then records this run's configuration.
+func logConfigChanges(cfg config.Config) {
+    current := cfg.Snapshot()
+    path := cfg.ConfigSnapshotFile
+    if path == "" {
+        dir := cacheDir()
+        if dir == "" {
+            slog.Info("Configuration hash", "hash", current.Hash)
+            return
         }
-        parsedVal, err := strconv.ParseFloat(envVal, 64)
-        if err != nil {
-            return defaultValue
+        path = filepath.Join(dir, "last-config.json")
+    }
+
+    previous, err := config.ReadSnapshot(path)
+    switch {
+    case errors.Is(err, fs.ErrNotExist):
+        slog.Info("Configuration hash", "hash", current.Hash, "previousHash", "none")
+    case err != nil:
+        slog.Warn("Failed to read previous configuration", "error", err, "file", path)
+        slog.Info("Configuration hash", "hash", current.Hash)
+    default:
+        changes := config.Diff(previous, current)
+        slog.Info("Configuration hash",
+            "hash", current.Hash,
+            "previousHash", previous.Hash,
+            "previousRun", previous.Time,
+            "changed", len(changes),
+        )
+        for _, ch := range changes {
+            slog.Info("Configuration changed since previous run",
+                "setting", ch.Key,
+                "previous", ch.Previous,
+                "current", ch.Current,
+            )
         }
-        return parsedVal
     }
-    return val
+
+    if err := config.WriteSnapshot(path, current); err != nil {
+        slog.Warn("Failed to record configuration", "error", err, "file", path)
+    }
 }
 
-func getOrDefaultUint(c *cli.Context, flagName, envVar string, defaultValue uint) uint {
-    val := c.Uint(flagName)
-    if !c.IsSet(flagName) {
-        envVal := os.Getenv(envVar)
-        if envVal == "" {
-            return defaultValue
+// withDefaults shows the default of every flag backed by a setting in the
+// help. The defaults come from config.Default, which is what Load falls back to
+// for flags that are not set, so they are kept in one place.
+func withDefaults(flags []cli.Flag) []cli.Flag {
+    defaults := make(map[string]string)
+    for _, key := range config.Keys() {
+        if key.Flag != "" && !key.Secret {
+            defaults[key.Flag] = key.Default
         }
-        parsedVal, err := strconv.ParseUint(envVal, 10, 64)
-        if err != nil {
-            return defaultValue
+    }
+    for _, f := range flags {
+        def := defaults[f.Names()[0]]
+        if def == "" {
+            continue
+        }
+        if text := reflect.ValueOf(f).Elem().FieldByName("DefaultText"); text.IsValid() && text.String() == "" {
+            text.SetString(def)
         }
-        return uint(parsedVal)
     }
-    return val
+    return flags
 }
 
 func main() {
     app := &cli.App{
         Name:  "Preconf Bidder",
         Usage: "A tool for bidding in mev-commit preconfirmation auctions for blobs and eth transfers.",
+        Commands: []*cli.Command{
+            schemaCommand(),
+            replayCommand(),
+            exportActivityCommand(),
+            historyCommand(),
+            doctorCommand(),
+            fanoutCommand(),
+            pingBidCommand(),
+            explainCommand(),
+            scoreCommand(),
+        },
         Action: func(c *cli.Context) error {
-            // Retrieve AppName and Version from flags or environment variables, with defaults
-            appName := getOrDefault(c, FlagAppName, "APP_NAME", "preconf_bidder")
-            version := getOrDefault(c, FlagVersion, "VERSION", "0.8.0")
+            // Settings come from the config file, the environment and flags, in increasing precedence
+            cfg, err := loadConfig(c)
+            if err != nil {
+                slog.Error("Configuration error", "error", err)
+                return err
+            }
+            rootCtx := c.Context
+            appName := cfg.AppName
+            version := cfg.Version
 
-            // Initialize the custom pretty-print JSON handler with INFO level
-            handler := NewCustomJSONHandler(os.Stderr, slog.LevelInfo)
+            // Records are written in the configured format and level to the console, and the log file if set;
+            // with the terminal UI the records go to its log pane while it runs
+            var logOut io.Writer = os.Stderr
+            var screen *tui.Screen
+            var quitScreen context.CancelFunc
+            if cfg.TUI {
+                screen = tui.New(os.Stdout, os.Stderr)
+                logOut = screen
+                // The screen reads the keyboard, so Ctrl+C reaches it rather than the signal handler
+                rootCtx, quitScreen = context.WithCancel(rootCtx)
+                defer quitScreen()
+            }
+            if cfg.LogFile != "" {
+                logFile, err := logging.OpenFile(cfg.LogFile, cfg.LogRotation())
+                if err != nil {
+                    return err
+                }
+                defer logFile.Close()
+                logOut = io.MultiWriter(logOut, logFile)
+            }
+            logFormat, _ := logging.ParseFormat(cfg.LogFormat)
+            logLevel, _ := logging.ParseLevel(cfg.LogLevel)
+            // Every record carries the tenant and running campaign, and recent ones are kept for /logs
+            labelSource := labels.NewSource(cfg.Tenant)
+            logBuffer := labels.NewBuffer(labels.DefaultBufferSize)
+            handler := logging.Canonical(labels.NewHandler(logging.NewHandler(logOut, logFormat, logLevel), labelSource, logBuffer))
+            // A failure repeating every block is written a few times per interval, then counted
+            handler = logging.Sample(handler, int(cfg.LogSampleBurst), cfg.LogSampleInterval)
 
             // Add default attributes to every log entry
             logger := slog.New(handler).With(
@@ -186,7 +521,26 @@ func main() {
                 slog.String("version", version),
             )
 
-            slog.SetDefault(logger)
+            logging.SetDefault(logger)
+
+            // Spans of the bidding path go to the OTLP endpoint if set; those not exported yet are flushed on exit
+            shutdownTracing, err := tracing.Setup(rootCtx, tracing.Config{
+                Endpoint:    cfg.OTLPEndpoint,
+                SampleRatio: cfg.TraceSampleRatio,
+                Service:     appName,
+                Version:     version,
+            })
+            if err != nil {
+                slog.Error("Failed to set up tracing", "error", err)
+                return err
+            }
+            defer func() {
+                ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+                defer cancel()
+                if err := shutdownTracing(ctx); err != nil {
+                    slog.Warn("Failed to export the remaining spans", "error", err)
+                }
+            }()
 
             fmt.Println("-----------------------------------------------------------------------------------------------")
             fmt.Println("Welcome to Preconf Bidder!")
@@ -209,32 +563,286 @@ func main() {
             fmt.Println("  --run-duration-minutes   Duration to run the bidder in minutes (0 for infinite)")
             fmt.Println("  --app-name               Application name for logging")
             fmt.Println("  --version                Application version for logging")
+            fmt.Println("  --retain-raw-payloads    Keep raw transaction bytes in logs (default: only tx hash and size)")
             fmt.Println("")
             fmt.Println("You can also set environment variables like WS_ENDPOINT and PRIVATE_KEY.")
             fmt.Println("For more details, check the documentation: https://docs.primev.xyz/get-started/bidders/best-practices")
             fmt.Println("-----------------------------------------------------------------------------------------------")
             fmt.Println()
 
-            // Get values from flags, environment, or use defaults
-            serverAddress := getOrDefault(c, FlagServerAddress, "SERVER_ADDRESS", "localhost:13524")
-            usePayload := getOrDefaultBool(c, FlagUsePayload, "USE_PAYLOAD", true)
-            rpcEndpoint := getOrDefault(c, FlagRpcEndpoint, "RPC_ENDPOINT", "https://ethereum-holesky-rpc.publicnode.com")
-            wsEndpoint := getOrDefault(c, FlagWsEndpoint, "WS_ENDPOINT", "wss://ethereum-holesky-rpc.publicnode.com")
-            privateKeyHex := getOrDefault(c, FlagPrivateKey, "PRIVATE_KEY", "") // No default, required
-            offset := getOrDefaultUint64(c, FlagOffset, "OFFSET", 1)
-            bidAmount := getOrDefaultFloat64(c, FlagBidAmount, "BID_AMOUNT", 0.001)
-            priorityFeeGwei := getOrDefaultUint64(c, FlagPriorityFeeGwei, "PRIORITY_FEE_GWEI", 1)
-            stdDevPercentage := getOrDefaultFloat64(c, FlagBidAmountStdDevPercentage, "BID_AMOUNT_STD_DEV_PERCENTAGE", 100.0)
-            numBlob := getOrDefaultUint(c, FlagNumBlob, "NUM_BLOB", 0)
-            defaultTimeoutSeconds := getOrDefaultUint(c, FlagDefaultTimeout, "DEFAULT_TIMEOUT", 15)
-            runDurationMinutes := getOrDefaultUint(c, FlagRunDurationMinutes, "RUN_DURATION_MINUTES", 0)
-
-            // Validate wsEndpoint if provided
+            // Get values from the configuration
+            serverAddress := cfg.ServerAddress
+            bidderCfg := cfg.BidderConfig()
+            usePayload := cfg.UsePayload
+            rpcEndpoint := cfg.RPCEndpoint
+            wsEndpoint := cfg.WSEndpoint
+            backfillBlocks := cfg.BackfillBlocks
+            bidOnReconnect := cfg.BidOnReconnect
+            wsStallSlots := cfg.WSStallSlots
+            privateKeyHex := cfg.PrivateKey // No default, required unless a keystore is given
+            keystorePath := cfg.KeystorePath
+            remoteSignerURL := cfg.RemoteSignerURL
+            offset := cfg.Offset
+            bidAmount := cfg.BidAmount
+            priorityFeeGwei := cfg.PriorityFeeGwei
+            gasOracle := cfg.GasOracle
+            feeOracle := cfg.FeeOracle()
+            spend := cfg.SpendBudget()
+            blobFeeOracle := cfg.BlobFeeOracle()
+            stdDevPercentage := cfg.StdDevPercentage
+            numBlob := cfg.NumBlob
+            txType := cfg.TransactionType()
+            var contractCalldata []byte
+            if txType == config.TxCall {
+                contractCalldata, err = ee.ContractCallData(cfg.ContractCalldata, cfg.ContractABI, cfg.ContractMethod, cfg.ContractCallArgs())
+                if err != nil {
+                    slog.Error("Contract call validation error", "error", err)
+                    return err
+                }
+            }
+            rawTxFile := cfg.RawTxFile
+            var (
+                rawTxs    []*types.Transaction
+                rawSender common.Address
+            )
+            if txType == config.TxRaw {
+                rawTxs, rawSender, err = readRawTxFile(rawTxFile)
+                if err != nil {
+                    slog.Error("Raw transaction file error", "error", err, "file", rawTxFile)
+                    return err
+                }
+                slog.Info("Loaded pre-signed transactions", "count", len(rawTxs), "sender", rawSender.Hex())
+            }
+            transferPrivateKeyHex := cfg.TransferPrivateKey
+            var transferPayment lanes.ETHTransfer
+            if cfg.TransferAmount != "" {
+                transferPayment.Amount, _ = new(big.Int).SetString(cfg.TransferAmount, 10)
+            }
+            if cfg.TransferTo != "" {
+                transferPayment.Recipient = common.HexToAddress(cfg.TransferTo)
+            }
+            extraPrivateKeys := cfg.ExtraPrivateKeyList()
+            extraKeystorePaths := cfg.ExtraKeystoreList()
+            accountRotation, _ := lanes.ParseRotation(cfg.AccountRotation)
+            nonceManagerEnabled := cfg.NonceManager
+            nonceResync := cfg.NonceResync
+            staleTxReplacements := cfg.StaleTxReplacements
+            reconcileBlocks := cfg.ReconcileBlocks
+            defaultTimeoutSeconds := cfg.DefaultTimeout
+            runDurationMinutes := cfg.RunDurationMinutes
+            dryRun := cfg.DryRun
+            latencyBudgets, err := latency.Parse(cfg.LatencyBudgets)
+            if err != nil {
+                slog.Error("LATENCY_BUDGETS validation error", "error", err)
+                return err
+            }
+            latency.Set(latencyBudgets)
+            retainRawPayloads := cfg.RetainRawPayloads
+            autoRollover := cfg.AutoRollover
+            autoWithdraw := cfg.AutoWithdraw
+            autoWithdrawDryRun := cfg.AutoWithdrawDryRun
+            depositAmount := cfg.DepositAmount
+            blocksPerWindow := cfg.BlocksPerWindow
+            haLeaseFile := cfg.HALeaseFile
+            haInstanceID := cfg.HAInstanceID
+            haLeaseTTLSeconds := cfg.HALeaseTTL
+            recordFile := cfg.RecordFile
+            activityFile := cfg.ActivityFile
+            skipLogFile := cfg.SkipLogFile
+            conflictLogFile := cfg.ConflictLogFile
+            ownTxFile := cfg.OwnTxFile
+            disputeFile := cfg.DisputeFile
+            disputeEndpoint := cfg.DisputeEndpoint
+            inclusionTracking := cfg.InclusionTracking
+            inclusionLogFile := cfg.InclusionLogFile
+            bidHistoryFile := cfg.BidHistoryFile
+            bidHistoryRetention := cfg.BidHistoryRetention
+            clockSkewThreshold := cfg.ClockSkewThreshold
+            clockCompensate := cfg.ClockCompensate
+            ntpServer := cfg.NTPServer
+            staleBidBlocks := cfg.StaleBidBlocks
+            mevCommitWSEndpoint := cfg.MevCommitWSEndpoint
+            drainTimeout := cfg.DrainTimeout
+            maxRSSMB := cfg.MaxRSSMB
+            maxGoroutines := cfg.MaxGoroutines
+            statusAddress := cfg.StatusAddress
+            statusInterval := cfg.StatusInterval
+            disabledJobs, _ := jobs.ParseDisabled(cfg.DisableJobs)
+            campaignScheduleFile := cfg.CampaignScheduleFile
+            campaignLead := cfg.CampaignLead
+            statusGuard := auth.NewGuard(auth.ParseTokens(cfg.StatusReadTokens), auth.ParseTokens(cfg.StatusAdminTokens))
+            network := cfg.Network
+            confirmMainnet := cfg.ConfirmMainnet
+            allowMainnetBlobs := cfg.AllowMainnetBlobs
+            telemetryEnabled := cfg.Telemetry
+            telemetryEndpoint := cfg.TelemetryEndpoint
+            telemetryInterval := cfg.TelemetryInterval
+            if err := resolveContracts(c.Context, cfg); err != nil {
+                slog.Error("Failed to resolve contract addresses", "error", err)
+                return err
+            }
+            seed := cfg.Seed
+            if seed == 0 {
+                // A random seed is still recorded, so the campaign stays replayable
+                seed = rand.Int63()
+            }
+
+            // Invalid decay windows are caught here rather than rejected by providers bid after bid;
+            // every target block of a span gets the window of its own offset
+            targetBlockSpan := cfg.TargetBlockSpan
+            bidSlotOffset := cfg.BidSlotOffset
+            decays, err := decayWindows(cfg.DecayBounds(), offset, int(targetBlockSpan))
+            if err != nil {
+                slog.Error("Decay window validation error", "offset", offset, "error", err)
+                return err
+            }
+            for i := range decays {
+                targetOffset := offset + uint64(i)
+                if decays[i] != bb.DecayWindow(targetOffset) {
+                    slog.Warn("Decay window adjusted to the allowed bounds",
+                        "offset", targetOffset,
+                        "decay", bb.DecayWindow(targetOffset),
+                        "adjusted", decays[i],
+                    )
+                }
+            }
+            decay := decays[0]
+
+            payloadPrivacy, err := bb.ParsePayloadPrivacy(cfg.PayloadPrivacy, usePayload)
+            if err != nil {
+                slog.Error("PAYLOAD_PRIVACY validation error", "error", err)
+                return err
+            }
+            if !payloadPrivacy.SendsRawPayload() && rpcEndpoint == "" {
+                return fmt.Errorf("payload privacy mode %q requires --%s to submit the transaction", payloadPrivacy, FlagRpcEndpoint)
+            }
+
+            // The preconf RPC bids for submitted transactions itself, so bids skip the bidder node
+            submissionBackend, err := bb.ParseSubmissionBackend(cfg.SubmissionBackend)
+            if err != nil {
+                slog.Error("SUBMISSION_BACKEND validation error", "error", err)
+                return err
+            }
+            preconfRPCEndpoint := cfg.PreconfRPCEndpoint
+            if submissionBackend == bb.BackendPreconfRPC && !payloadPrivacy.SendsRawPayload() {
+                return fmt.Errorf("submission backend %s receives the raw transaction; use --%s payload", submissionBackend, FlagPayloadPrivacy)
+            }
+            publicFallbackBlocks := cfg.PublicFallbackBlocks
+            publicRPCEndpoint := cfg.PublicRPCEndpoint
+            if publicRPCEndpoint == "" {
+                publicRPCEndpoint = rpcEndpoint
+            }
+
+            // External strategies register themselves when their plugin is loaded
+            strategyName := cfg.Strategy
+            strategyPlugins := cfg.StrategyPlugin
+            if err := loadStrategyPlugins(strategyPlugins); err != nil {
+                slog.Error("STRATEGY_PLUGIN validation error", "error", err)
+                return err
+            }
+            strategyScript, err := readStrategyScript(cfg.StrategyScript)
+            if err != nil {
+                slog.Error("STRATEGY_SCRIPT validation error", "error", err)
+                return err
+            }
+            bidParams := strategy.Params{
+                BidAmount:        bidAmount,
+                StdDevPercentage: stdDevPercentage,
+                Offset:           offset,
+                Script:           strategyScript,
+                ProviderWeighting: cfg.ProviderWeighting,
+            }
+            bidStrategy, err := strategy.New(strategyName, bidParams)
+            if err != nil {
+                slog.Error("STRATEGY validation error", "error", err)
+                return err
+            }
+            // The adaptive strategy scales bids with the commitment rate of recent bids
+            pricingCfg := cfg.Pricing()
+            var pricer *pricing.Controller
+            if strategyName == "adaptive" {
+                pricer = pricing.NewController(pricingCfg)
+            }
+            canaryPercent := cfg.CanaryPercent
+            canaryBlocks := cfg.CanaryBlocks
+            // Parameters changed at runtime are tried on a share of blocks before they replace these
+            bidCanary := canary.New(canary.Config{Percent: canaryPercent, Blocks: canaryBlocks}, canary.Setting{Strategy: bidStrategy, Params: bidParams})
+            // The admin API changes parameters through the canary and the offset directly;
+            // a configuration reload may change the decay bounds
+            decayBounds := new(atomic.Pointer[bb.DecayBounds])
+            startBounds := cfg.DecayBounds()
+            decayBounds.Store(&startBounds)
+            controls, err := control.New(bidParams, control.Config{
+                Propose: func(p strategy.Params) error {
+                    s, err := strategy.New(strategyName, p)
+                    if err != nil {
+                        return err
+                    }
+                    bidCanary.Propose(canary.Setting{Strategy: s, Params: p})
+                    return nil
+                },
+                CheckOffset: func(o uint64) error {
+                    _, err := decayWindows(*decayBounds.Load(), o, len(decays))
+                    return err
+                },
+                AuditFile: cfg.ControlAuditFile,
+            })
+            if err != nil {
+                return err
+            }
+            defer controls.Close()
+
+            bundleHints, err := ee.ParseBundleHints(
+                cfg.BackrunTx,
+                cfg.BundleHints,
+            )
+            if err != nil {
+                slog.Error("BUNDLE_HINTS validation error", "error", err)
+                return err
+            }
+            if !bundleHints.IsZero() && payloadPrivacy.SendsRawPayload() {
+                return fmt.Errorf("bundle hints only apply to bundle submissions; use --%s hash or commit-reveal", FlagPayloadPrivacy)
+            }
+            // Relays such as Flashbots only accept requests signed with a search key
+            if cfg.BundleSearchKey != "" {
+                bundleHints.SearchKey, _ = crypto.HexToECDSA(cfg.BundleSearchKey)
+            }
+            bundleReplacement := cfg.BundleReplacement
+            bundleValidity := cfg.BundleValidity
+            if (bundleHints.SearchKey != nil || bundleReplacement || bundleValidity > 0) && payloadPrivacy.SendsRawPayload() {
+                slog.Warn("Bundle signing, replacement and validity only apply to bundle submissions", "payloadPrivacy", payloadPrivacy)
+            }
+            // Bundles go to the RPC endpoint and every further relay at once
+            bundleRelays, _ := ee.ParseRelays(cfg.BundleRelays)
+            disabledRelays, _ := ee.ParseDisabledRelays(cfg.DisabledRelays, bundleRelays)
+            enabledRelays := 0
+            if !disabledRelays[ee.DefaultRelay] {
+                enabledRelays++
+            }
+            for _, relay := range bundleRelays {
+                if !disabledRelays[relay.Name] {
+                    enabledRelays++
+                }
+            }
+            if enabledRelays == 0 && !payloadPrivacy.SendsRawPayload() {
+                return fmt.Errorf("payload privacy mode %q requires a relay, --%s disables all of them", payloadPrivacy, FlagDisabledRelays)
+            }
+            // Some relays take single private transactions instead of bundles
+            submitMethod, _ := ee.ParseSubmitMethod(cfg.SubmitMethod)
+            privateTxPrefs, _ := ee.ParsePrivateTxPreferences(cfg.PrivateTxPreferences)
+            if submitMethod == ee.SubmitPrivateTx && (len(bundleHints.Extra) > 0 || bundleReplacement || bundleValidity > 0) {
+                slog.Warn("Bundle hints, replacement and validity do not apply to private transactions", "submitMethod", submitMethod)
+            }
+
+            // Raw transaction bytes may contain sensitive calldata; only hashes and sizes are kept by default
+            bb.SetRetainRawPayloads(retainRawPayloads)
+
+            // Validate wsEndpoint if provided; it may list fallbacks after the primary
             if wsEndpoint != "" {
                 var err error
-                wsEndpoint, err = validateWebSocketURL(wsEndpoint)
+                wsEndpoint, err = validateWebSocketURLs(wsEndpoint)
                 if err != nil {
-                    slog.Error("WS_ENDPOINT validation error", "err", err)
+                    slog.Error("WS_ENDPOINT validation error", "error", err)
                     return err
                 }
             }
@@ -257,7 +865,7 @@ func main() {
                 fmt.Println()
             }
 
-            if privateKeyHex == "" {
+            if privateKeyHex == "" && keystorePath == "" && remoteSignerURL == "" && txType != config.TxRaw {
                 fmt.Println("A private key is needed to sign transactions.")
                 fmt.Println("A private key is a 64-character hexadecimal string.")
                 fmt.Println()
@@ -278,6 +886,10 @@ func main() {
             if runDurationMinutes > 0 {
                 endTime = time.Now().Add(time.Duration(runDurationMinutes) * time.Minute)
                 slog.Info("Bidder will run until", "endTime", endTime)
+                // Run duration and signals share the same shutdown path
+                var stop context.CancelFunc
+                rootCtx, stop = context.WithDeadline(rootCtx, endTime)
+                defer stop()
             } else {
                 slog.Info("Bidder will run indefinitely")
             }
@@ -287,10 +899,13 @@ func main() {
             fmt.Printf(" - Private Key: Provided (hidden)\n")
             fmt.Printf(" - Server Address: %s\n", serverAddress)
             fmt.Printf(" - Use Payload: %v\n", usePayload)
+            fmt.Printf(" - Payload Privacy: %s\n", payloadPrivacy)
             fmt.Printf(" - Bid Amount: %f ETH\n", bidAmount)
 			fmt.Printf(" - Priority Fee: %d gwei\n", priorityFeeGwei)
+            fmt.Printf(" - Gas Oracle: %s\n", gasOracle)
             fmt.Printf(" - Standard Deviation: %f%%\n", stdDevPercentage)
             fmt.Printf(" - Number of Blobs: %d\n", numBlob)
+            fmt.Printf(" - Transaction Type: %s\n", txType)
             fmt.Printf(" - Default Timeout: %d seconds\n", defaultTimeoutSeconds)
             if runDurationMinutes > 0 {
                 fmt.Printf(" - Run Duration: %d minutes\n", runDurationMinutes)
@@ -306,151 +921,1266 @@ func main() {
                 "appName", appName,
                 "version", version,
                 "serverAddress", serverAddress,
+                "bidderTLS", bidderCfg.UsesTLS(),
+                "bidderTokenProvided", bidderCfg.Token != "",
+                "bidRetryBudget", bidderCfg.Retry.MaxRetries,
+                "bidRetryBackoff", bidderCfg.Retry.InitialBackoff,
+                "bidCompression", bidderCfg.Compression,
+                "maxBidPayloadBytes", bidderCfg.MaxPayload,
                 "rpcEndpoint", bb.MaskEndpoint(rpcEndpoint),
                 "wsEndpoint", bb.MaskEndpoint(wsEndpoint),
+                "backfillBlocks", backfillBlocks,
+                "bidOnReconnect", bidOnReconnect,
+                "wsStallSlots", wsStallSlots,
                 "offset", offset,
+                "decay", decay,
+                "targetBlockSpan", targetBlockSpan,
+                "bidSlotOffset", bidSlotOffset,
+                "genesisTime", cfg.GenesisTime,
                 "usePayload", usePayload,
+                "payloadPrivacy", payloadPrivacy,
+                "submissionBackend", submissionBackend,
+                "preconfRPCEndpoint", bb.MaskEndpoint(preconfRPCEndpoint),
+                "publicFallbackBlocks", publicFallbackBlocks,
+                "publicRPCEndpoint", bb.MaskEndpoint(publicRPCEndpoint),
                 "bidAmount", bidAmount,
                 "priorityFeeGwei", priorityFeeGwei,
+                "gasOracle", gasOracle,
+                "gasTipPercentile", cfg.GasTipPercentile,
+                "maxBaseFeeGwei", cfg.MaxBaseFeeGwei,
+                "feeBump", cfg.FeeBump,
+                "blobFeeSource", cfg.BlobFeeSource,
+                "blobFeeBump", cfg.BlobFeeBump,
+                "maxBlobFeeCapGwei", cfg.MaxBlobFeeCapGwei,
+                "blobFeeCeilingGwei", cfg.BlobFeeCeilingGwei,
                 "stdDevPercentage", stdDevPercentage,
+                "hourlyBudget", cfg.HourlyBudget,
+                "dailyBudget", cfg.DailyBudget,
+                "budgetMode", cfg.BudgetMode,
+                "maxBidsPerBlock", cfg.MaxBidsPerBlock,
                 "numBlob", numBlob,
+                "kzgTrustedSetup", cfg.KZGTrustedSetup,
+                "txType", txType,
+                "transferAmount", cfg.TransferAmount,
+                "transferTo", cfg.TransferTo,
+                "rawTxFile", rawTxFile,
                 "privateKeyProvided", privateKeyHex != "",
+                "keystorePath", keystorePath,
+                "remoteSigner", bb.MaskEndpoint(remoteSignerURL),
+                "remoteSignerKind", cfg.RemoteSignerKind,
+                "remoteSignerAddress", cfg.RemoteSignerAddress,
+                "transferPrivateKeyProvided", transferPrivateKeyHex != "",
+                "extraPrivateKeys", len(extraPrivateKeys),
+                "extraKeystorePaths", extraKeystorePaths,
+                "accountRotation", accountRotation,
+                "nonceManager", nonceManagerEnabled,
+                "nonceResync", nonceResync,
+                "staleTxReplacements", staleTxReplacements,
+                "reconcileBlocks", reconcileBlocks,
                 "defaultTimeoutSeconds", defaultTimeoutSeconds,
+                "latencyBudgets", fmt.Sprint(latency.Current()),
+                "dryRun", dryRun,
+                "tui", screen != nil,
+                "logFormat", cfg.LogFormat,
+                "logLevel", cfg.LogLevel,
+                "logFile", cfg.LogFile,
+                "logSampleBurst", cfg.LogSampleBurst,
+                "logSampleInterval", cfg.LogSampleInterval,
+                "otlpEndpoint", cfg.OTLPEndpoint,
+                "traceSampleRatio", cfg.TraceSampleRatio,
+                "tenant", cfg.Tenant,
+                "userAgent", ident.UserAgent(),
+                "requestIDPrefix", cfg.RequestIDPrefix,
+                "retainRawPayloads", retainRawPayloads,
+                "autoRollover", autoRollover,
+                "autoWithdraw", autoWithdraw,
+                "autoWithdrawDryRun", autoWithdrawDryRun,
+                "depositAmount", depositAmount,
+                "blocksPerWindow", blocksPerWindow,
+                "haLeaseFile", haLeaseFile,
+                "haInstanceID", haInstanceID,
+                "seed", seed,
+                "recordFile", recordFile,
+                "staleBidBlocks", staleBidBlocks,
+                "activityFile", activityFile,
+                "skipLogFile", skipLogFile,
+                "conflictLogFile", conflictLogFile,
+                "ownTxFile", ownTxFile,
+                "disputeFile", disputeFile,
+                "disputeEndpoint", disputeEndpoint,
+                "inclusionTracking", inclusionTracking,
+                "inclusionLogFile", inclusionLogFile,
+                "bidHistoryFile", bidHistoryFile,
+                "bidHistoryRetention", bidHistoryRetention,
+                "clockSkewThreshold", clockSkewThreshold,
+                "clockCompensate", clockCompensate,
+                "ntpServer", ntpServer,
+                "mevCommitWSEndpoint", mevCommitWSEndpoint,
+                "drainTimeout", drainTimeout,
+                "maxRSSMB", maxRSSMB,
+                "maxGoroutines", maxGoroutines,
+                "statusAddress", statusAddress,
+                "statusInterval", statusInterval,
+                "disableJobs", cfg.DisableJobs,
+                "statusAuth", statusGuard.Enabled(),
+                "controlAuditFile", cfg.ControlAuditFile,
+                "campaignScheduleFile", campaignScheduleFile,
+                "campaignLead", campaignLead,
+                "network", network,
+                "confirmMainnet", confirmMainnet,
+                "allowMainnetBlobs", allowMainnetBlobs,
+                "telemetry", telemetryEnabled,
+                "telemetryEndpoint", telemetryEndpoint,
+                "telemetryInterval", telemetryInterval,
+                "bidderRegistry", bb.BidderRegistryAddress.Hex(),
+                "blockTracker", bb.BlockTrackerAddress.Hex(),
+                "preconfManager", bb.PreconfManagerAddress.Hex(),
+                "strategy", bidStrategy.Name(),
+                "adaptiveTargetRate", pricingCfg.TargetRate,
+                "adaptiveStep", pricingCfg.Step,
+                "adaptiveScale", fmt.Sprintf("%g-%g", pricingCfg.MinScale, pricingCfg.MaxScale),
+                "providerWeighting", bidParams.ProviderWeighting,
+                "canaryPercent", canaryPercent,
+                "canaryBlocks", canaryBlocks,
+                "strategyPlugins", strategyPlugins,
+                "backrunTx", bundleHints.BackrunTxHash,
+                "bundleHints", bundleHints.Extra,
+                "bundleSigned", bundleHints.SearchKey != nil,
+                "bundleReplacement", bundleReplacement,
+                "bundleValidity", bundleValidity,
+                "bundleRelays", len(bundleRelays),
+                "disabledRelays", cfg.DisabledRelays,
+                "submitMethod", submitMethod,
+                "privateTxPreferences", cfg.PrivateTxPreferences,
+            )
+            logConfigChanges(cfg)
+
+            // The pool fails over between the configured endpoints, the first is the primary
+            wsPool := bb.NewWSPool(bb.ParseWSEndpoints(wsEndpoint))
+            headers := make(chan *types.Header)
+            wsClient, sub, err := wsPool.ConnectContext(rootCtx, headers)
+            if err != nil {
+                slog.Error("Failed to connect to WebSocket client", "error", err)
+                return fmt.Errorf("failed to connect to WebSocket client: %w", err)
+            }
+            // Transactions are built on txClient, which is replaced along with wsClient
+            txClient := ee.NewClient(wsClient)
+            // The client is replaced on reconnects, close whichever is current
+            defer func() { wsClient.Close() }()
+            slog.Info("Geth client connected (ws)",
+                "wsEndpoint", bb.MaskEndpoint(wsPool.Current()),
+                "endpoints", len(wsPool.Endpoints()),
             )
+            go wsPool.Run(rootCtx, bb.DefaultWSHealthInterval)
+
+            // Mainnet defaults are testnet friendly, the guardrails make every spending decision explicit
+            ctx, cancel := context.WithTimeout(rootCtx, defaultTimeout)
+            chainID, err := wsClient.ChainID(ctx)
+            cancel()
+            if err != nil {
+                slog.Error("Failed to fetch the chain ID", "error", err)
+                return fmt.Errorf("failed to fetch the chain ID: %w", err)
+            }
+            if chainID.Int64() == config.MainnetChainID {
+                guarded := cfg
+                guarded.PrivateKey = privateKeyHex // Including a prompted key
+                if err := guarded.CheckMainnet(); err != nil {
+                    slog.Error("Refusing to bid on mainnet", "error", err)
+                    return err
+                }
+                slog.Warn("Bidding on Ethereum mainnet", "chainID", chainID)
+            }
 
-            cfg := bb.BidderConfig{
-                ServerAddress: serverAddress,
+            // The trusted setup takes a while to load, keep it off the first bid
+            if numBlob > 0 || cfg.KZGTrustedSetup != "" {
+                took, err := ee.InitKZG(cfg.KZGTrustedSetup)
+                if err != nil {
+                    slog.Error("Failed to initialize the KZG trusted setup", "error", err)
+                    return err
+                }
+                source := cfg.KZGTrustedSetup
+                if source == "" {
+                    source = "embedded"
+                }
+                slog.Info("KZG trusted setup initialized", "source", source, "took", took)
             }
 
-            bidderClient, err := bb.NewBidderClient(cfg)
+            bidderClient, err := bb.NewBidderClient(bidderCfg)
             if err != nil {
                 slog.Error("Failed to connect to mev-commit bidder API", "error", err)
                 return fmt.Errorf("failed to connect to mev-commit bidder API: %w", err)
             }
 
+            defer bidderClient.Close()
             slog.Info("Connected to mev-commit client")
+            go bidderClient.Watch(rootCtx)
+
+            var ledger *accounting.Ledger
+            if activityFile != "" {
+                ledger, err = accounting.NewLedger(activityFile)
+                if err != nil {
+                    return err
+                }
+                defer ledger.Close()
+            }
+
+            var history *store.Store
+            if bidHistoryFile != "" {
+                history, err = store.Open(bidHistoryFile)
+                if err != nil {
+                    return err
+                }
+                defer history.Close()
+            }
+
+            recordDepositEvent := func(ev bb.DepositEvent) {
+                if ledger == nil {
+                    return
+                }
+                kind := accounting.KindDeposit
+                if ev.Withdrawal {
+                    kind = accounting.KindWithdrawal
+                }
+                if err := ledger.Add(accounting.Entry{
+                    Kind:         kind,
+                    Amount:       ev.Amount,
+                    Counterparty: fmt.Sprintf("window %d", ev.Window),
+                }); err != nil {
+                    slog.Warn("Failed to record wallet activity", "error", err)
+                }
+            }
+
+            // A dry run sends no bids, so it funds no windows and withdraws nothing
+            if dryRun && autoRollover {
+                slog.Warn("Dry run, automatic deposits disabled")
+                autoRollover = false
+            }
+            if dryRun && autoWithdraw {
+                autoWithdraw, autoWithdrawDryRun = false, true
+            }
+            var depositManager *bb.DepositManager
+            if autoRollover {
+                depositManager = bb.NewDepositManager(bidderClient, bb.DepositConfig{
+                    AmountPerWindow: bb.EthToWei(depositAmount),
+                    BlocksPerWindow: blocksPerWindow,
+                    Notify:          recordDepositEvent,
+                })
+            }
+
+            // Settled windows are withdrawn from, or only reported in dry-run mode
+            var withdrawer *bb.Withdrawer
+            if autoWithdraw || autoWithdrawDryRun {
+                withdrawer = bb.NewWithdrawer(bidderClient, bb.WithdrawConfig{
+                    BlocksPerWindow: blocksPerWindow,
+                    DryRun:          autoWithdrawDryRun,
+                    Notify:          recordDepositEvent,
+                })
+            }
 
             timeout := defaultTimeout
 
             var rpcClient *ethclient.Client
-            if !usePayload {
+            if !payloadPrivacy.SendsRawPayload() {
                 rpcClient = bb.ConnectRPCClientWithRetries(rpcEndpoint, 5, timeout)
                 if rpcClient == nil {
                     slog.Error("Failed to connect to RPC client", "rpcEndpoint", bb.MaskEndpoint(rpcEndpoint))
                 } else {
+                    defer rpcClient.Close()
                     slog.Info("Geth client connected (rpc)",
                         "endpoint", bb.MaskEndpoint(rpcEndpoint),
                     )
                 }
             }
 
-            wsClient, err := bb.ConnectWSClient(wsEndpoint)
-            if err != nil {
-                slog.Error("Failed to connect to WebSocket client", "error", err)
-                return fmt.Errorf("failed to connect to WebSocket client: %w", err)
+            if withdrawer != nil {
+                // Deposits left in recent windows by earlier runs are withdrawn too
+                ctx, cancel := context.WithTimeout(rootCtx, defaultTimeout)
+                head, err := wsClient.BlockNumber(ctx)
+                cancel()
+                if err != nil {
+                    slog.Warn("Failed to fetch the head block, only windows bid into are withdrawn from", "error", err)
+                } else {
+                    withdrawer.TrackLookback(head+offset, bb.DefaultWithdrawLookback)
+                }
             }
-            slog.Info("Geth client connected (ws)",
-                "endpoint", bb.MaskEndpoint(wsEndpoint),
-            )
 
-            headers := make(chan *types.Header)
-            sub, err := wsClient.SubscribeNewHead(context.Background(), headers)
-            if err != nil {
-                slog.Error("Failed to subscribe to new blocks", "error", err)
-                return fmt.Errorf("failed to subscribe to new blocks: %w", err)
-            }
             
-            if privateKeyHex == "" {
+            if privateKeyHex == "" && keystorePath == "" && remoteSignerURL == "" && txType != config.TxRaw {
 				slog.Error("Private key is required")
 				return fmt.Errorf("private key is required")
 			}
 
-            authAcct, err := bb.AuthenticateAddress(privateKeyHex, wsClient)
-            if err != nil {
-                slog.Error("Failed to authenticate private key", "error", err)
-                return fmt.Errorf("failed to authenticate private key: %w", err)
+            // Raw transactions are signed already, the private key is optional then
+            // A remote signer keeps the key out of the process entirely
+            var authAcct bb.AuthAcct
+            var remoteSigner *ee.RemoteSigner
+            signing := cfg
+            signing.PrivateKey = privateKeyHex // Including a prompted key
+            if signerSource(signing) != "" {
+                ctx, cancel := context.WithTimeout(rootCtx, defaultTimeout)
+                account, err := openPrimaryAccount(ctx, signing, chainID)
+                cancel()
+                if err != nil {
+                    slog.Error("Failed to open the primary account", "error", err, "source", signerSource(signing))
+                    return err
+                }
+                defer account.Close()
+                authAcct, remoteSigner = account.AuthAcct, account.Remote
+                slog.Info("Signing with primary account", "source", account.Source, "address", account.Address.Hex())
             }
 
-            for {
-                if runDurationMinutes > 0 && time.Now().After(endTime) {
-                    slog.Info("Run duration reached, shutting down")
-                    return nil
+            // Blob and transfer bids run in separate lanes when a second account is
+            // configured, so a stuck blob nonce cannot hold up transfers
+            newLane := func(acct bb.AuthAcct) *lanes.Lane {
+                switch txType {
+                case config.TxERC20:
+                    amount, _ := new(big.Int).SetString(cfg.ERC20Amount, 10)
+                    return lanes.NewERC20(acct, lanes.TokenTransfer{
+                        Token:     common.HexToAddress(cfg.ERC20Token),
+                        Recipient: common.HexToAddress(cfg.ERC20Recipient),
+                        Amount:    amount,
+                    })
+                case config.TxCall:
+                    return lanes.NewContractCall(acct, lanes.ContractCall{
+                        To:   common.HexToAddress(cfg.ContractAddress),
+                        Data: contractCalldata,
+                    })
+                case config.TxRaw:
+                    return lanes.NewRaw(rawSender, rawTxs)
+                case config.TxBlob:
+                    return lanes.New(lanes.Blob, acct, numBlob)
                 }
-
-                select {
-                case err := <-sub.Err():
-                    slog.Warn("Subscription error", "error", err)
-                    wsClient, sub = bb.ReconnectWSClient(wsEndpoint, headers)
-                    continue
-                case header := <-headers:
-                    var signedTx *types.Transaction
-                    var blockNumber uint64
-                    if numBlob == 0 {
-                        // Perform ETH Transfer
-                        amount := big.NewInt(1e15)
-                        signedTx, blockNumber, err = ee.SelfETHTransfer(wsClient, authAcct, amount, offset, big.NewInt(int64(priorityFeeGwei)))
-                    } else {
-                        // Execute Blob Transaction
-                        signedTx, blockNumber, err = ee.ExecuteBlobTransaction(wsClient, authAcct, int(numBlob), offset, big.NewInt(int64(priorityFeeGwei)))
+                return lanes.NewTransfer(acct, transferPayment)
+            }
+            primaryLanes := []*lanes.Lane{newLane(authAcct)}
+            if remoteSigner != nil && txType != config.TxRaw {
+                primaryLanes[0].Signer = remoteSigner
+            }
+            // Extra accounts bid the same transaction from their own nonces, taking turns
+            // per block or all at once
+            for _, key := range extraPrivateKeys {
+                acct, err := bb.AuthenticateAddress(key, wsClient)
+                if err != nil {
+                    slog.Error("Failed to authenticate extra private key", "error", err, "account", len(primaryLanes))
+                    return fmt.Errorf("failed to authenticate extra private key %d: %w", len(primaryLanes), err)
+                }
+                primaryLanes = append(primaryLanes, newLane(acct))
+            }
+            if len(extraKeystorePaths) > 0 {
+                password, err := cfg.ReadKeystorePassword()
+                if err != nil {
+                    slog.Error("Failed to read keystore password", "error", err)
+                    return err
+                }
+                for _, path := range extraKeystorePaths {
+                    acct, err := bb.AuthenticateKeystore(path, password, wsClient)
+                    if err != nil {
+                        slog.Error("Failed to authenticate extra keystore", "error", err, "keystore", path)
+                        return fmt.Errorf("failed to authenticate extra keystore %s: %w", path, err)
                     }
-
-                    if signedTx == nil {
-                        slog.Error("Transaction was not signed or created.")
-                    } else {
-                        slog.Info("Transaction sent successfully")
+                    primaryLanes = append(primaryLanes, newLane(acct))
+                }
+            }
+            bidPools := []*lanes.Pool{lanes.NewPool(accountRotation, primaryLanes...)}
+            if transferPrivateKeyHex != "" {
+                transferAcct, err := bb.AuthenticateAddress(transferPrivateKeyHex, wsClient)
+                if err != nil {
+                    slog.Error("Failed to authenticate transfer private key", "error", err)
+                    return fmt.Errorf("failed to authenticate transfer private key: %w", err)
+                }
+                bidPools = append(bidPools, lanes.NewPool(accountRotation, lanes.NewTransfer(transferAcct, transferPayment)))
+            }
+            var bidLanes []*lanes.Lane
+            for _, pool := range bidPools {
+                bidLanes = append(bidLanes, pool.Lanes()...)
+            }
+            // With the nonce manager a transaction built while the last one is in flight
+            // takes the next nonce instead of the node's pending nonce again
+            var nonces *ee.NonceManager
+            if nonceManagerEnabled {
+                nonces = ee.NewNonceManager(nonceResync)
+                for _, lane := range bidLanes {
+                    if lane.Signer != nil {
+                        lane.Signer = nonces.Manage(lane.Signer)
                     }
+                }
+            }
+            // A nonce whose transaction went without a commitment is bid on with that
+            // transaction again, its fees bumped to replace it; commitments to preconf
+            // RPC submissions are not known, so they are never replaced
+            var staleTxs *ee.StaleTxs
+            if staleTxReplacements > 0 && submissionBackend == bb.BackendBidder {
+                staleTxs = ee.NewStaleTxs(int(staleTxReplacements))
+            }
 
-                    if err != nil {
-                        slog.Error("Failed to execute transaction", "error", err)
-                    }
+            // In active/standby mode every instance keeps its connections and state warm,
+            // but only the holder of the coordination lease bids
+            var elector *coordination.Elector
+            if haLeaseFile != "" {
+                elector = coordination.NewElector(
+                    coordination.NewFileLease(haLeaseFile),
+                    haInstanceID,
+                    time.Duration(haLeaseTTLSeconds)*time.Second,
+                    nil,
+                )
+                electorCtx, stopElector := context.WithCancel(rootCtx)
+                defer stopElector()
+                elector.Tick(electorCtx)
+                go elector.Run(electorCtx)
+            }
 
-                    slog.Info("New block received",
-                        "blockNumber", header.Number.Uint64(),
-                        "timestamp", header.Time,
-                        "hash", header.Hash().String(),
-                    )
+            // Per-account nonce, balance and bid counts; a stuck account shows up here first
+            accounts := status.NewBoard(status.DefaultStuckAfter)
+            for _, lane := range bidLanes {
+                accounts.Register(lane.Account.Address)
+                slog.Info("Bid lane ready", "lane", lane.Kind, "address", lane.Account.Address.Hex())
+            }
+            // With a campaign schedule the bidder idles until a campaign starts
+            var scheduler *schedule.Scheduler
+            if campaignScheduleFile != "" {
+                addrs := make([]common.Address, 0, len(bidLanes))
+                for _, lane := range bidLanes {
+                    addrs = append(addrs, lane.Account.Address)
+                }
+                check := campaignCheck(wsPool.Current, bidderClient, addrs, blocksPerWindow)
+                scheduler, err = schedule.Open(campaignScheduleFile, check, campaignLead)
+                if err != nil {
+                    slog.Error("Failed to open campaign schedule", "error", err)
+                    return err
+                }
+                go scheduler.Run(rootCtx, schedule.DefaultInterval)
+                labelSource.SetCampaign(func() string {
+                    c, _ := scheduler.Running()
+                    return c.ID
+                })
+            }
+            // Optional work is shed under memory or goroutine pressure, before bidding is affected
+            shedder := pressure.New(pressure.Thresholds{MaxRSS: maxRSSMB << 20, MaxGoroutines: int(maxGoroutines)})
+            go shedder.Run(rootCtx, pressure.DefaultInterval)
+            // Telemetry is opt-in; without it the reporter stays nil and /telemetry says so
+            var reporter *telemetry.Reporter
+            if telemetryEnabled {
+                reporter = telemetry.New(telemetryEndpoint, telemetryInterval, metrics.Registry, version, network)
+                go shedder.Optional(rootCtx, "telemetry", reporter.Run)
+                slog.Info("Anonymized telemetry enabled", "endpoint", telemetryEndpoint, "interval", telemetryInterval)
+            }
+            // Maintenance runs off the bidding path, each job isolated from the others
+            maintenance := jobs.New(jobs.DefaultJitter, disabledJobs)
+            var latestTarget atomic.Uint64 // Target block of the latest bid
+            if depositManager != nil {
+                // Funding returns at once while the window does not change
+                maintenance.Add(jobs.Job{Name: jobs.Deposits, Interval: bb.SlotDuration / 4, Run: func(ctx context.Context) error {
+                    ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+                    defer cancel()
+                    return depositManager.OnTargetBlock(ctx, latestTarget.Load())
+                }})
+            }
+            if withdrawer != nil {
+                maintenance.Add(jobs.Job{Name: jobs.Withdrawals, Interval: bb.SlotDuration, Run: func(ctx context.Context) error {
+                    ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+                    defer cancel()
+                    _, err := withdrawer.OnTargetBlock(ctx, latestTarget.Load())
+                    return err
+                }})
+            }
+            if statusInterval > 0 {
+                addrs := make([]common.Address, 0, len(bidLanes))
+                for _, lane := range bidLanes {
+                    addrs = append(addrs, lane.Account.Address)
+                }
+                maintenance.Add(jobs.Job{Name: jobs.Balances, Interval: statusInterval, Run: func(ctx context.Context) error {
+                    err := refreshBalances(ctx, accounts, wsPool.Current(), addrs, statusInterval)
+                    accounts.LogSummary()
+                    return err
+                }})
+            }
+            if history != nil && bidHistoryRetention > 0 {
+                maintenance.Add(jobs.Job{Name: jobs.Compaction, Interval: store.DefaultCompactInterval, Run: func(ctx context.Context) error {
+                    return history.Retain(ctx, bidHistoryRetention)
+                }})
+            }
+            tracker := inflight.NewTracker(staleBidBlocks, nil)
+            // The bid lifecycle is pushed to /events clients; without any, publishing is a no-op
+            bidEvents := stream.New()
+            // Liveness, readiness and status for supervisors; only served with the status server
+            var probes *health.Monitor
+            if statusAddress != "" {
+                probes = health.New(func() []dashboard.Connection {
+                    return dashboardConnections(bidderClient, wsPool)
+                }, tracker.Len)
+                probes.Pressure = shedder.State
+                probes.Jobs = maintenance.Status
+                mux := http.NewServeMux()
+                // Probes carry no secrets and cannot always send a token
+                mux.HandleFunc("GET /healthz", probes.ServeHealthz)
+                mux.HandleFunc("GET /readyz", probes.ServeReadyz)
+                mux.Handle("GET /status", statusGuard.Require(auth.ReadOnly, http.HandlerFunc(probes.ServeStatus)))
+                mux.Handle("/accounts", statusGuard.Require(auth.ReadOnly, accounts))
+                mux.Handle("/metrics", statusGuard.Require(auth.ReadOnly, metrics.LabeledHandler(func() map[string]string {
+                    return labelSource.Current().Map()
+                })))
+                mux.Handle("GET /logs", statusGuard.Require(auth.ReadOnly, logBuffer))
+                mux.Handle("GET /events", statusGuard.Require(auth.ReadOnly, bidEvents))
+                mux.Handle("/canary", statusGuard.Require(auth.ReadOnly, bidCanary))
+                mux.Handle("GET /control", statusGuard.Require(auth.ReadOnly, http.HandlerFunc(controls.ServeState)))
+                mux.Handle("PATCH /control/params", statusGuard.Require(auth.Admin, http.HandlerFunc(controls.ServeParams)))
+                mux.Handle("POST /control/pause", statusGuard.Require(auth.Admin, http.HandlerFunc(controls.ServePause)))
+                mux.Handle("POST /control/resume", statusGuard.Require(auth.Admin, http.HandlerFunc(controls.ServeResume)))
+                mux.Handle("/telemetry", statusGuard.Require(auth.ReadOnly, reporter))
+                if scheduler != nil {
+                    mux.Handle("GET /campaigns", statusGuard.Require(auth.ReadOnly, http.HandlerFunc(scheduler.ServeList)))
+                    mux.Handle("POST /campaigns", statusGuard.Require(auth.Admin, http.HandlerFunc(scheduler.ServeAdd)))
+                    mux.Handle("DELETE /campaigns/{id}", statusGuard.Require(auth.Admin, http.HandlerFunc(scheduler.ServeCancel)))
+                }
+                dash := dashboard.New(metrics.Registry, func() []dashboard.Connection {
+                    return dashboardConnections(bidderClient, wsPool)
+                })
+                go shedder.Optional(rootCtx, "dashboard sampling", func(ctx context.Context) {
+                    dash.Run(ctx, dashboard.DefaultInterval)
+                })
+                // The page itself holds no data, only what it fetches from /dashboard/data
+                mux.Handle("/dashboard/", http.StripPrefix("/dashboard", dash))
+                mux.Handle("/dashboard/data", statusGuard.Require(auth.ReadOnly, http.StripPrefix("/dashboard", dash)))
+                // The schema holds no data, so integrators can fetch it without a token
+                apiDoc, err := serveAPIDocument(version)
+                if err != nil {
+                    return fmt.Errorf("failed to render API document: %w", err)
+                }
+                mux.Handle("GET /openapi.json", apiDoc)
+                mux.Handle("/", http.RedirectHandler("/dashboard/", http.StatusFound))
+                statusServer := &http.Server{Addr: statusAddress, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+                go func() {
+                    if err := statusServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+                        slog.Error("Status server failed", "error", err, "address", statusAddress)
+                    }
+                }()
+                defer statusServer.Close()
+            }
 
-                    stdDev := bidAmount * stdDevPercentage / 100.0
-                    randomEthAmount := rand.NormFloat64()*stdDev + bidAmount
-                    randomEthAmount = math.Max(randomEthAmount, bidAmount)
+            dispatcher := &bidDispatcher{
+                bidder:      bidderClient,
+                rpcEndpoint: rpcEndpoint,
+                privacy:     payloadPrivacy,
+                hints:       bundleHints,
+                replacement: bundleReplacement,
+                validity:    bundleValidity,
+                relays:      bundleRelays,
+                disabled:    disabledRelays,
+                method:      submitMethod,
+                preferences: privateTxPrefs,
+                backend:     submissionBackend,
+                preconfRPC:  preconfRPCEndpoint,
+                dryRun:      dryRun,
+                labels:      labelSource,
+            }
+            // Transactions no provider commits to for a while are broadcast publicly, so they still land
+            var publicFallback *fallback.Fallback
+            switch {
+            case publicFallbackBlocks > 0 && dryRun:
+                slog.Info("Dry run, transactions are not broadcast to the public mempool")
+            case publicFallbackBlocks > 0:
+                publicFallback = fallback.New(int(publicFallbackBlocks), fallback.RawTransaction(publicRPCEndpoint))
+            }
+            // SIGHUP applies changed bidding parameters, decay bounds and endpoints without a restart
+            reloads := &reloader{
+                load:         func() (config.Config, error) { return loadConfig(c) },
+                strategyName: strategyName,
+                span:         len(decays),
+                privacy:      payloadPrivacy,
+                controls:     controls,
+                dispatcher:   dispatcher,
+                decayBounds:  decayBounds,
+                running:      cfg,
+            }
+            go reloads.Run(rootCtx)
 
-                    if usePayload {
-                        bb.SendPreconfBid(bidderClient, signedTx, int64(blockNumber), randomEthAmount)
-                    } else {
-                        _, err = ee.SendBundle(rpcEndpoint, signedTx, blockNumber)
+            // Decay timestamps come from the local clock; keep an estimate of its skew
+            skew := clock.NewEstimator(clock.DefaultWindow, clockSkewThreshold)
+            if ntpServer != "" {
+                go func() {
+                    ticker := time.NewTicker(10 * time.Minute)
+                    defer ticker.Stop()
+                    for {
+                        ctx, cancel := context.WithTimeout(rootCtx, 5*time.Second)
+                        d, err := clock.QueryNTP(ctx, ntpServer)
+                        cancel()
                         if err != nil {
-                            slog.Error("Failed to send transaction",
-                                "rpcEndpoint", bb.MaskEndpoint(rpcEndpoint),
-                                "error", err,
-                            )
+                            slog.Warn("NTP query failed, using block timestamps for clock skew", "error", err, "ntpServer", ntpServer)
+                        } else {
+                            skew.SetNTPOffset(d)
+                            skew.Update(clockCompensate)
+                        }
+                        select {
+                        case <-rootCtx.Done():
+                            return
+                        case <-ticker.C:
                         }
-                        bb.SendPreconfBid(bidderClient, signedTx.Hash().String(), int64(blockNumber), randomEthAmount)
                     }
+                }()
+            }
 
-                    if err != nil {
-                        slog.Error("Failed to execute transaction", "error", err)
-                        continue
-                    }
+            // Competition is only observable when a mev-commit chain endpoint is configured
+            // Our commitments and inclusions are told apart from the market's by the
+            // addresses of every lane and the hashes of the transactions sent
+            var competitors *competition.Observer
+            var ownership *competition.Ownership
+            if mevCommitWSEndpoint != "" {
+                addrs := make([]common.Address, 0, len(bidLanes))
+                for _, lane := range bidLanes {
+                    addrs = append(addrs, lane.Account.Address)
                 }
+                ownership, err = competition.OpenOwnership(ownTxFile, addrs)
+                if err != nil {
+                    return err
+                }
+                defer ownership.Close()
+                competitors = competition.NewObserver(competition.DefaultWindowSlots)
+                competitors.Ownership = ownership
+                competitionCtx, stopCompetition := context.WithCancel(rootCtx)
+                defer stopCompetition()
+                go shedder.Optional(competitionCtx, "competition observer", func(ctx context.Context) {
+                    competition.Listen(ctx, mevCommitWSEndpoint, bb.PreconfManagerAddress, competitors)
+                })
             }
-        },
-        Flags: []cli.Flag{
-            &cli.StringFlag{
-                Name:    FlagEnv,
-                Usage:   "Path to .env file",
-                EnvVars: []string{"ENV_FILE"},
-            },
-            &cli.StringFlag{
-                Name:    FlagServerAddress,
-                Usage:   "Address of the server",
-                EnvVars: []string{"SERVER_ADDRESS"},
-                Value:   "localhost:13524",
-            },
-            &cli.BoolFlag{
-                Name:    FlagUsePayload,
+
+            // Our stored commitments are checked against their target block, and
+            // the evidence of those not honored is reported
+            var disputes *dispute.Reporter
+            if mevCommitWSEndpoint != "" {
+                disputes, err = dispute.Open(disputeFile, disputeEndpoint, ownership.IsTx)
+                if err != nil {
+                    return err
+                }
+                defer disputes.Close()
+                // The oracle settles reported commitments later, by slashing their provider or not
+                go watchSettlements(rootCtx, mevCommitWSEndpoint, disputes)
+            } else if disputeFile != "" || disputeEndpoint != "" {
+                slog.Warn("Commitments are not checked for disputes without --" + FlagMevCommitWSEndpoint)
+            }
+
+            // The target block of every bid is checked for its transaction, and
+            // compared with the commitments the bid received
+            var inclusions *inclusion.Watcher
+            if inclusionTracking {
+                inclusions, err = inclusion.Open(inclusionLogFile)
+                if err != nil {
+                    return err
+                }
+                defer inclusions.Close()
+            }
+
+            // Transactions a previous run left in the mempool are adopted, and raw
+            // transactions that landed meanwhile are not bid for again
+            if reconcileBlocks > 0 {
+                if err := reconcileLanes(rootCtx, wsClient, bidLanes, reconcileBlocks, nonces, ownership); err != nil {
+                    slog.Warn("Failed to reconcile transactions of a previous run", "error", err)
+                }
+            }
+
+            // Bids are matched with the commitments stored for them on the mev-commit chain
+            var commitmentFeedback *feedback.Tracker
+            if mevCommitWSEndpoint != "" {
+                commitmentFeedback = feedback.NewTracker(feedback.DefaultTimeout)
+                commitmentFeedback.Notify = func(o feedback.Outcome) {
+                    if history != nil {
+                        recordOutcome(history, o)
+                    }
+                    bidEvents.Publish(stream.BidSettled, stream.Settlement{
+                        TargetBlock: o.TargetBlock,
+                        TxHash:      o.TxHash,
+                        Committed:   o.Committed(),
+                        Providers:   o.Providers,
+                        LatencyMs:   o.Latency.Milliseconds(),
+                    })
+                }
+                maintenance.Add(jobs.Job{Name: jobs.Settlement, Interval: 10 * time.Second, Run: func(context.Context) error {
+                    commitmentFeedback.Expire()
+                    return nil
+                }})
+                go watchCommitments(rootCtx, mevCommitWSEndpoint, commitmentFeedback, competitors, disputes)
+            }
+            // The adaptive strategy counts commitments by the stake and commit rate of their providers
+            var scoreboard *providers.Scoreboard
+            if pricer != nil && commitmentFeedback != nil && providers.Weighting(bidParams.ProviderWeighting) != providers.None {
+                scoreboard = providers.New(providers.Weighting(bidParams.ProviderWeighting), commitmentFeedback.Providers, providers.RegistryStake(mevCommitWSEndpoint, bb.PreconfManagerAddress))
+                go scoreboard.Run(rootCtx, providers.DefaultInterval)
+            }
+            if submissionBackend == bb.BackendPreconfRPC && commitmentFeedback == nil {
+                slog.Warn("Preconf RPC submissions are not matched with commitments without --" + FlagMevCommitWSEndpoint)
+            }
+
+            skipLog, err := skips.Open(skipLogFile)
+            if err != nil {
+                return err
+            }
+            defer skipLog.Close()
+            skipLog.Labels = labelSource
+            skipLog.Notify = func(r skips.Record) { bidEvents.Publish(stream.BidSkipped, r) }
+            go skipLog.Run(rootCtx)
+
+            // A nonce holding a commitment is not bid on again with another transaction
+            nonceConflicts, err := conflicts.Open(conflictLogFile)
+            if err != nil {
+                return err
+            }
+            defer nonceConflicts.Close()
+
+            var recorder *campaign.Recorder
+            if recordFile != "" {
+                recorder, err = campaign.NewRecorder(recordFile)
+                if err != nil {
+                    return err
+                }
+                defer recorder.Close()
+            }
+
+            // Each lane builds its transactions in its own worker, from its own account
+            // Every reporter consumes the same outcome of a bid
+            reportOutcome := func(o outcome.BlockOutcome, arm canary.Arm, span *spanOutcome) {
+                slog.Info("Bid resolved", o.LogAttrs()...)
+                bidEvents.Publish(stream.BidResolved, stream.Resolved(o))
+                if screen != nil {
+                    screen.Bid(o)
+                }
+                if probes != nil {
+                    probes.Outcome(o)
+                }
+                accounts.BidResolved(o.Payload.From, len(o.Commitments))
+                resolved, committed := true, o.Committed()
+                if len(decays) > 1 {
+                    resolved, committed = span.resolve(o.TargetBlock, len(o.Commitments))
+                }
+                if resolved {
+                    settleNonce(nonces, o, submissionBackend, committed)
+                }
+                if o.Committed() {
+                    // The first committed transaction keeps the nonce; the loser's other bids are dropped
+                    if incident, conflict := nonceConflicts.Committed(o.Payload.From, o.Payload.Nonce, o.Payload.TxHash, o.TargetBlock); conflict {
+                        tracker.Cancel(incident.Loser)
+                    }
+                }
+                recordActivity(ledger, o)
+                recordBid(history, o)
+                // Commitments to preconf RPC submissions are not known here, they would read as misses
+                if o.Bid.Sent && submissionBackend == bb.BackendBidder {
+                    inclusions.Watch(inclusion.Bid{TxHash: o.Payload.TxHash, TargetBlock: o.TargetBlock, Providers: o.Providers()})
+                    bidCanary.Observe(arm, o.Committed(), o.Bid.AmountETH)
+                    if pricer != nil {
+                        pricer.ObserveWeighted(scoreboard.Weight(o.Providers()))
+                    }
+                }
+                if competitors != nil {
+                    for _, c := range o.Commitments {
+                        competitors.MarkOwn(c.CommitmentDigest)
+                    }
+                }
+            }
+
+            bidOn := func(lane *lanes.Lane, job lanes.Job) {
+                if rootCtx.Err() != nil {
+                    return
+                }
+                header, client := job.Header, job.Client
+                ctx := job.Ctx
+                if ctx == nil {
+                    ctx = rootCtx
+                }
+                if controls.Paused() {
+                    skipLog.Skip(header.Number.Uint64(), skips.Paused, string(lane.Kind), "paused through the admin API")
+                    return
+                }
+                if scheduler != nil {
+                    if _, running := scheduler.Running(); !running {
+                        skipLog.Skip(header.Number.Uint64(), skips.Paused, string(lane.Kind), "no campaign running")
+                        return
+                    }
+                }
+                if statusInterval > 0 && accounts.BalanceOlderThan(lane.Account.Address, statusInterval) {
+                    go refreshBalance(rootCtx, accounts, client.Client, lane.Account.Address)
+                }
+
+                // The offset and decay bounds may have changed through the admin API or a reload
+                offset := controls.Offset()
+                decays, err := decayWindows(*decayBounds.Load(), offset, int(targetBlockSpan))
+                if err != nil {
+                    // Checked before the transaction is built, so no nonce is taken
+                    slog.Error("Decay bounds reject the offset in use, skipping block", "error", err, "offset", offset, "lane", lane.Kind)
+                    skipLog.Skip(header.Number.Uint64(), skips.Decay, string(lane.Kind), err.Error())
+                    return
+                }
+                signedTx, blockNumber, err := lane.BuildTx(ctx, client, offset, feeOracle, blobFeeOracle)
+
+                if signedTx == nil {
+                    slog.Error("Transaction was not signed or created.")
+                } else {
+                    slog.Info("Transaction sent successfully")
+                }
+
+                if err != nil {
+                    metrics.BidFailures.WithLabelValues(metrics.StageTx).Inc()
+                    slog.Error("Failed to execute transaction", "error", err, "lane", lane.Kind)
+                    if budget, ok := latency.Exceeded(err); ok {
+                        slog.Warn("Skipping block, latency budget exceeded",
+                            "budget", budget,
+                            "limit", latency.Get(budget),
+                            "blockNumber", header.Number.Uint64(),
+                            "lane", lane.Kind,
+                        )
+                        skipLog.Skip(header.Number.Uint64(), skips.Deadline, string(lane.Kind), string(budget))
+                        return
+                    }
+                }
+
+                if elector != nil && !elector.IsActive() {
+                    slog.Info("Standby instance, skipping bid",
+                        "instance", elector.ID(),
+                        "blockNumber", blockNumber,
+                    )
+                    skipLog.Skip(header.Number.Uint64(), skips.Paused, string(lane.Kind), "standby")
+                    if nonces != nil && signedTx != nil {
+                        nonces.Settle(lane.Account.Address, signedTx.Nonce(), false)
+                    }
+                    return
+                }
+
+                if blockNumber > 0 {
+                    // The deposit and withdrawal jobs follow the latest target block
+                    latestTarget.Store(blockNumber)
+                }
+
+                marketInputs := strategy.InputsFromHeader(header)
+                if competitors != nil {
+                    marketInputs.Competition = competitors.Intensity(time.Now())
+                }
+                if pricer != nil {
+                    marketInputs.BidScale = pricer.Scale()
+                }
+                setting, arm := bidCanary.Pick(marketInputs.BlockNumber)
+                decision := setting.Strategy.Decide(marketInputs, strategy.BlockRand(seed, marketInputs.BlockNumber))
+                randomEthAmount := decision.BidAmount
+                record := campaign.Record{
+                    Seed:     seed,
+                    Strategy: setting.Strategy.Name(),
+                    Params:   setting.Params,
+                    Inputs:   marketInputs,
+                    Decision: decision,
+                }
+                current := labelSource.Current()
+                record.Tenant, record.Campaign = current.Tenant, current.Campaign
+
+                if signedTx == nil {
+                    recordDecision(recorder, record)
+                    dispatcher.dispatch(ctx, signedTx, blockNumber, randomEthAmount, decays[0], nil)
+                    skipLog.Skip(header.Number.Uint64(), txSkipReason(err), string(lane.Kind), fmt.Sprint(err))
+                    return
+                }
+                // With a span the transaction backs several bids and is left as is
+                if len(decays) == 1 {
+                    if replacement, replaced := staleTxs.Replace(lane.Signer, signedTx); replaced {
+                        metrics.StaleTxReplacements.Inc()
+                        signedTx = replacement
+                    }
+                }
+                if err := nonceConflicts.Check(lane.Account.Address, signedTx.Nonce(), signedTx.Hash().String()); err != nil {
+                    skipLog.Skip(header.Number.Uint64(), skips.Conflict, string(lane.Kind), err.Error())
+                    if nonces != nil {
+                        nonces.Settle(lane.Account.Address, signedTx.Nonce(), false)
+                    }
+                    return
+                }
+                // Every bid of the span reserves its amount until it resolves
+                amount, err := spend.Reserve(blockNumber, randomEthAmount, len(decays))
+                if err != nil {
+                    skipLog.Skip(header.Number.Uint64(), skips.Budget, string(lane.Kind), err.Error())
+                    if nonces != nil {
+                        nonces.Settle(lane.Account.Address, signedTx.Nonce(), false)
+                    }
+                    return
+                }
+                if amount < randomEthAmount {
+                    slog.Info("Bid reduced to the remaining budget", "decided", randomEthAmount, "amount", amount, "blockNumber", blockNumber)
+                    randomEthAmount = amount
+                }
+
+                // Bids run concurrently so a slow provider cannot hold up the next header;
+                // the tracker closes streams of bids that outlive their target block.
+                // They are not tied to rootCtx so shutdown can drain them, but stay in the header's trace.
+                // With a target block span the same transaction is bid for consecutive blocks
+                if commitmentFeedback != nil {
+                    commitmentFeedback.Track(signedTx.Hash().String(), blockNumber)
+                }
+                ownership.AddTx(signedTx.Hash().String())
+                span := newSpanOutcome(signedTx.Hash().String(), len(decays))
+                // Rejected submissions are rebuilt until the target slot starts; with a span
+                // the transaction backs several bids and is left as is
+                var recovery *ee.Recovery
+                if lane.Signer != nil && len(decays) == 1 {
+                    recovery = &ee.Recovery{
+                        Client:   client.Client,
+                        Signer:   lane.Signer,
+                        Deadline: time.Unix(int64(header.Time), 0).Add(time.Duration(blockNumber-header.Number.Uint64()) * bb.SlotDuration),
+                    }
+                }
+                for i, targetDecay := range decays {
+                    target := blockNumber + uint64(i)
+                    bidCtx, bidDone := tracker.StartTarget(context.WithoutCancel(ctx), signedTx.Hash().String(), target, signedTx.Nonce())
+                    accounts.BidSent(lane.Account.Address, signedTx.Nonce())
+                    go func(signedTx *types.Transaction, blockNumber uint64, amount float64, decay time.Duration, client *ethclient.Client, record campaign.Record) {
+                        defer bidDone()
+                        bidEvents.Publish(stream.BidSent, stream.Bid{
+                            TargetBlock: blockNumber,
+                            TxHash:      signedTx.Hash().String(),
+                            Lane:        string(lane.Kind),
+                            From:        lane.Account.Address.Hex(),
+                            Nonce:       signedTx.Nonce(),
+                            AmountETH:   amount,
+                            Decay:       decay.String(),
+                            Tenant:      record.Tenant,
+                            Campaign:    record.Campaign,
+                        })
+                        o, sentTx := dispatcher.dispatch(bidCtx, signedTx, blockNumber, amount, decay, recovery)
+                        spend.Settle(amount, o.Committed())
+                        if o.Payload.TxHash != signedTx.Hash().String() {
+                            if commitmentFeedback != nil {
+                                commitmentFeedback.Track(o.Payload.TxHash, blockNumber)
+                            }
+                            ownership.AddTx(o.Payload.TxHash)
+                        }
+                        o.Payload.Lane, o.Payload.From = string(lane.Kind), lane.Account.Address
+                        o.Bid.Signature = signBid(lane.Account, o)
+                        reportOutcome(o, arm, span)
+                        publicFallback.Resolved(rootCtx, lane.Account.Address, sentTx, o.Committed())
+                        if recovery != nil {
+                            staleTxs.Resolved(lane.Account.Address, sentTx, o.Committed())
+                        }
+                        if recorder != nil {
+                            if shedder.Allow("market snapshot") {
+                                record.Market = marketSnapshot(client, competitors)
+                            }
+                            record.Outcome = campaign.NewOutcome(o)
+                            recordDecision(recorder, record)
+                        }
+                    }(signedTx, target, randomEthAmount, targetDecay, client.Client, record)
+                }
+            }
+            if screen != nil {
+                title := appName + " " + version
+                if dryRun {
+                    title += " (dry run)"
+                }
+                go screen.Run(rootCtx, tui.Config{
+                    Title:    title,
+                    Gatherer: metrics.Registry,
+                    Connections: func() []dashboard.Connection {
+                        return dashboardConnections(bidderClient, wsPool)
+                    },
+                    Deposits: tuiDeposits(bidderClient, blocksPerWindow, defaultTimeout),
+                    Quit:     quitScreen,
+                }, tui.DefaultInterval)
+            }
+            go maintenance.Run(rootCtx, jobs.DefaultTick)
+            stopLanes := lanes.Start(bidOn, bidLanes...)
+            defer stopLanes()
+            offerJob := func(job lanes.Job) {
+                for _, pool := range bidPools {
+                    for _, lane := range pool.Next() {
+                        if skipped := lane.Offer(job); skipped != nil {
+                            skipLog.Skip(skipped.Header.Number.Uint64(), skips.Busy, string(lane.Kind), "")
+                        }
+                    }
+                }
+            }
+
+            // With a slot offset the latest block waits for that point of the next slot, so every
+            // bid starts its decay at the same time into the slot; without a genesis time the slots
+            // are aligned to the first block
+            var slotTicks <-chan uint64
+            if bidSlotOffset > 0 && cfg.GenesisTime > 0 {
+                slotTicks = slots.New(time.Unix(int64(cfg.GenesisTime), 0)).Ticks(rootCtx, bidSlotOffset)
+            }
+            var pending *lanes.Job
+            var pendingSpan trace.Span
+            // bid offers header to the lanes, right away or at the next slot tick; span ends once it is offered
+            bid := func(ctx context.Context, header *types.Header, span trace.Span) {
+                job := lanes.Job{Header: header, Client: txClient, Ctx: ctx}
+                if bidSlotOffset == 0 {
+                    offerJob(job)
+                    span.End()
+                    return
+                }
+                if slotTicks == nil {
+                    slotClock := slots.FromHeader(header.Time)
+                    slotTicks = slotClock.Ticks(rootCtx, bidSlotOffset)
+                    slog.Info("Slot clock aligned to the first block", "genesis", slotClock.Genesis(), "bidSlotOffset", bidSlotOffset)
+                }
+                if pending != nil {
+                    skipLog.Skip(pending.Header.Number.Uint64(), skips.Superseded, "", fmt.Sprintf("block %d arrived first", header.Number.Uint64()))
+                    pendingSpan.End()
+                }
+                pending, pendingSpan = &job, span
+            }
+
+            // advance moves the per-block bookkeeping on to header, whether received or backfilled
+            var last *types.Header
+            advance := func(header *types.Header) {
+                last = header
+                tracker.Reap(header.Number.Uint64())
+                nonceConflicts.Prune(header.Number.Uint64())
+                spend.Prune(header.Number.Uint64())
+                if screen != nil {
+                    screen.Head(header.Number.Uint64())
+                }
+                if probes != nil {
+                    probes.Head(header.Number.Uint64())
+                }
+                if competitors != nil && shedder.Allow("inclusion observer") {
+                    go observeInclusions(rootCtx, wsClient, header, competitors)
+                }
+                for _, target := range disputes.Due(header.Number.Uint64()) {
+                    go checkDisputes(rootCtx, wsClient, target, disputes)
+                }
+                for _, target := range inclusions.Due(header.Number.Uint64()) {
+                    go checkInclusions(rootCtx, wsClient, target, inclusions)
+                }
+            }
+
+            // A reconnected subscription only delivers new headers; those of the blocks missed
+            // meanwhile are read from the node, and the newest is bid on with bidOnReconnect
+            backfillMissed := func(after uint64) {
+                rpcEndpoint, _ := dispatcher.endpoints()
+                reader, closeReader := backfillReader(rootCtx, rpcClient, rpcEndpoint, wsClient)
+                defer closeReader()
+                missed, dropped, err := backfill.Missed(rootCtx, reader, after, backfillBlocks)
+                if err != nil {
+                    slog.Warn("Failed to backfill missed blocks", "error", err, "lastBlock", after)
+                    return
+                }
+                if len(missed) == 0 {
+                    return
+                }
+                metrics.BackfilledBlocks.Add(float64(len(missed)))
+                newest := missed[len(missed)-1]
+                slog.Info("Backfilled blocks missed while reconnecting",
+                    "fromBlock", missed[0].Number.Uint64(),
+                    "toBlock", newest.Number.Uint64(),
+                    "dropped", dropped,
+                )
+                for _, header := range missed {
+                    advance(header)
+                    if header == newest && bidOnReconnect {
+                        headerCtx, headerSpan := tracing.Start(rootCtx, tracing.Header,
+                            attribute.Int64("blockNumber", header.Number.Int64()),
+                            attribute.String("blockHash", header.Hash().String()),
+                            attribute.Bool("backfilled", true),
+                        )
+                        slog.Info("Bidding on the newest block after reconnecting", "blockNumber", header.Number.Uint64())
+                        bid(headerCtx, header, headerSpan)
+                        continue
+                    }
+                    skipLog.Skip(header.Number.Uint64(), skips.Missed, "", "block subscription down")
+                }
+            }
+
+            // A subscription that stops delivering headers without closing is torn down once no
+            // header arrived for wsStallSlots slots, instead of waiting for the node to drop it
+            stallTimeout := time.Duration(wsStallSlots) * slots.SlotDuration
+            var stall *time.Timer
+            var stallC <-chan time.Time
+            if stallTimeout > 0 {
+                stall = time.NewTimer(stallTimeout)
+                defer stall.Stop()
+                stallC = stall.C
+            }
+            lastHeaderAt := time.Now()
+            resetStall := func() {
+                if stall != nil {
+                    stall.Reset(stallTimeout)
+                }
+            }
+
+            // reconnect replaces the subscription that failed with cause and backfills the blocks
+            // missed meanwhile; sub is nil when every endpoint failed
+            reconnect := func(cause error) {
+                metrics.WSReconnects.Inc()
+                wsClient.Close()
+                wsClient, sub = wsPool.ReconnectContext(rootCtx, headers, cause)
+                txClient = ee.NewClient(wsClient)
+                if sub == nil {
+                    return
+                }
+                resetStall()
+                if last != nil {
+                    backfillMissed(last.Number.Uint64())
+                }
+            }
+
+        loop:
+            for {
+                select {
+                case <-rootCtx.Done():
+                    break loop
+                case err := <-sub.Err():
+                    slog.Warn("Subscription error", "error", err, "wsEndpoint", bb.MaskEndpoint(wsPool.Current()))
+                    reconnect(err)
+                    if sub == nil {
+                        if rootCtx.Err() != nil {
+                            break loop
+                        }
+                        return fmt.Errorf("failed to reconnect to any of %d WebSocket endpoints", len(wsPool.Endpoints()))
+                    }
+                    continue
+                case <-stallC:
+                    var lastBlock uint64
+                    if last != nil {
+                        lastBlock = last.Number.Uint64()
+                    }
+                    metrics.WSStalls.Inc()
+                    slog.Warn("WebSocket stall detected",
+                        "event", "ws_stall_detected",
+                        "wsEndpoint", bb.MaskEndpoint(wsPool.Current()),
+                        "lastBlock", lastBlock,
+                        "sinceLastHeader", time.Since(lastHeaderAt).Round(time.Second),
+                        "stallTimeout", stallTimeout,
+                    )
+                    sub.Unsubscribe()
+                    reconnect(fmt.Errorf("%w: no header for %s", errs.ErrWSStalled, stallTimeout))
+                    if sub == nil {
+                        if rootCtx.Err() != nil {
+                            break loop
+                        }
+                        return fmt.Errorf("failed to reconnect to any of %d WebSocket endpoints", len(wsPool.Endpoints()))
+                    }
+                    continue
+                case <-wsPool.Recovered():
+                    primaryClient, primarySub := wsPool.ReturnToPrimary(rootCtx, headers)
+                    if primarySub == nil {
+                        continue
+                    }
+                    sub.Unsubscribe()
+                    wsClient.Close()
+                    wsClient, sub = primaryClient, primarySub
+                    txClient = ee.NewClient(wsClient)
+                    resetStall()
+                    slog.Info("Returned to the primary WebSocket endpoint")
+                    continue
+                case header := <-headers:
+                    lastHeaderAt = time.Now()
+                    resetStall()
+                    if last != nil && header.Hash() == last.Hash() {
+                        // Already read when backfilling after a reconnect
+                        continue
+                    }
+                    // The bids of every lane for the header are traced under its span
+                    headerCtx, headerSpan := tracing.Start(rootCtx, tracing.Header,
+                        attribute.Int64("blockNumber", header.Number.Int64()),
+                        attribute.String("blockHash", header.Hash().String()),
+                    )
+                    skew.ObserveBlock(time.Unix(int64(header.Time), 0), time.Now())
+                    skew.Update(clockCompensate)
+                    advance(header)
+                    slog.Info("New block received",
+                        "blockNumber", header.Number.Uint64(),
+                        "timestamp", header.Time,
+                        "hash", header.Hash().String(),
+                    )
+                    bid(headerCtx, header, headerSpan)
+                case slot := <-slotTicks:
+                    if pending == nil {
+                        slog.Debug("No new block for the slot", "slot", slot)
+                        continue
+                    }
+                    // The connection may have been replaced while the block waited
+                    pending.Client = txClient
+                    offerJob(*pending)
+                    pendingSpan.End()
+                    pending, pendingSpan = nil, nil
+                }
+            }
+
+            if runDurationMinutes > 0 && time.Now().After(endTime) {
+                slog.Info("Run duration reached, shutting down")
+            } else {
+                slog.Info("Shutdown requested")
+            }
+            sub.Unsubscribe()
+            if pendingSpan != nil {
+                pendingSpan.End()
+            }
+            stopLanes()
+            slog.Info("Draining in-flight bids", "bids", tracker.Len(), "drainTimeout", drainTimeout)
+            drainCtx, cancelDrain := context.WithTimeout(context.Background(), drainTimeout)
+            defer cancelDrain()
+            if err := tracker.Drain(drainCtx); err != nil {
+                slog.Warn("In-flight bids did not finish before the drain timeout", "drainTimeout", drainTimeout)
+            }
+            slog.Info("Shutdown complete")
+            return nil
+        },
+        Flags: withDefaults([]cli.Flag{
+            &cli.StringFlag{
+                Name:    FlagEnv,
+                Usage:   "Path to .env file",
+                EnvVars: []string{"ENV_FILE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagConfig,
+                Usage:   "Path to a YAML config file; the environment and flags take precedence over it",
+                EnvVars: []string{"CONFIG_FILE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagServerAddress,
+                Usage:   "Address of the server",
+                EnvVars: []string{"SERVER_ADDRESS"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagBidderTLS,
+                Usage:   "Connect to the bidder node over TLS, verified with the system roots unless --" + FlagBidderTLSCACert + " is set",
+                EnvVars: []string{"BIDDER_TLS"},
+            },
+            &cli.StringFlag{
+                Name:    FlagBidderTLSCACert,
+                Usage:   "PEM file of the CA certificate verifying the bidder node; implies --" + FlagBidderTLS,
+                EnvVars: []string{"BIDDER_TLS_CA_CERT"},
+            },
+            &cli.StringFlag{
+                Name:    FlagBidderTLSCert,
+                Usage:   "PEM file of the client certificate presented to the bidder node; implies --" + FlagBidderTLS,
+                EnvVars: []string{"BIDDER_TLS_CERT"},
+            },
+            &cli.StringFlag{
+                Name:    FlagBidderTLSKey,
+                Usage:   "PEM file of the client certificate's private key",
+                EnvVars: []string{"BIDDER_TLS_KEY"},
+            },
+            &cli.StringFlag{
+                Name:    FlagBidderToken,
+                Usage:   "Bearer token sent to the bidder node with every call; requires TLS",
+                EnvVars: []string{"BIDDER_TOKEN"},
+            },
+            &cli.UintFlag{
+                Name:    FlagBidRetryBudget,
+                Usage:   "How often a bid is sent again while the bidder node is unavailable, e.g. restarting (0 disables retries)",
+                EnvVars: []string{"BID_RETRY_BUDGET"},
+            },
+            &cli.DurationFlag{
+                Name:    FlagBidRetryBackoff,
+                Usage:   "Wait before the first retry of a bid, doubled for every further retry",
+                EnvVars: []string{"BID_RETRY_BACKOFF"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagBidCompression,
+                Usage:   "Gzip compress bids carrying raw transactions; the bidder node must accept gzip",
+                EnvVars: []string{"BID_COMPRESSION"},
+            },
+            &cli.UintFlag{
+                Name:    FlagMaxBidPayloadBytes,
+                Usage:   "Largest bid sent to the bidder node, in bytes; larger bids fail suggesting hash privacy (0 for no limit)",
+                EnvVars: []string{"MAX_BID_PAYLOAD_BYTES"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagUsePayload,
                 Usage:   "Use payload for transactions",
                 EnvVars: []string{"USE_PAYLOAD"},
-                Value:   true,
             },
             &cli.StringFlag{
                 Name:     FlagRpcEndpoint,
@@ -460,11 +2190,25 @@ func main() {
             },
             &cli.StringFlag{
                 Name:     FlagWsEndpoint,
-                Usage:    "WebSocket endpoint for transactions",
+                Usage:    "WebSocket endpoint for transactions; a comma-separated list fails over to the next endpoint and back to the first once it recovers",
                 EnvVars:  []string{"WS_ENDPOINT"},
-                Value:    "wss://ethereum-holesky-rpc.publicnode.com",
                 Required: false,
             },
+            &cli.Uint64Flag{
+                Name:    FlagBackfillBlocks,
+                Usage:   "Blocks missed while the block subscription was down whose headers are read after it reconnects, newest first (0 disables backfilling)",
+                EnvVars: []string{"BACKFILL_BLOCKS"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagBidOnReconnect,
+                Usage:   "Bid on the newest backfilled block right after the block subscription reconnects instead of waiting for the next block",
+                EnvVars: []string{"BID_ON_RECONNECT"},
+            },
+            &cli.Uint64Flag{
+                Name:    FlagWSStallSlots,
+                Usage:   "Slots without a new block after which the block subscription is considered stalled and re-established (0 waits for the node to close it)",
+                EnvVars: []string{"WS_STALL_SLOTS"},
+            },
             &cli.StringFlag{
                 Name:      FlagPrivateKey,
                 Usage:     "Private key for signing transactions",
@@ -473,127 +2217,716 @@ func main() {
                 Hidden:    true,
                 TakesFile: false,
             },
+            &cli.StringFlag{
+                Name:      FlagKeystorePath,
+                Usage:     "Encrypted geth keystore JSON file holding the signing key, instead of --private-key",
+                EnvVars:   []string{"KEYSTORE_PATH"},
+                TakesFile: true,
+            },
+            &cli.StringFlag{
+                Name:    FlagKeystorePassword,
+                Usage:   "Password of the keystore file",
+                EnvVars: []string{"KEYSTORE_PASSWORD"},
+                Hidden:  true,
+            },
+            &cli.StringFlag{
+                Name:      FlagKeystorePasswordFile,
+                Usage:     "File holding the password of the keystore file",
+                EnvVars:   []string{"KEYSTORE_PASSWORD_FILE"},
+                TakesFile: true,
+            },
+            &cli.StringFlag{
+                Name:    FlagRemoteSignerURL,
+                Usage:   "JSON-RPC endpoint of a Clef or web3signer instance signing transactions, instead of a local key",
+                EnvVars: []string{"REMOTE_SIGNER_URL"},
+            },
+            &cli.StringFlag{
+                Name:    FlagRemoteSignerKind,
+                Usage:   "Remote signer API: clef or web3signer",
+                EnvVars: []string{"REMOTE_SIGNER_KIND"},
+            },
+            &cli.StringFlag{
+                Name:    FlagRemoteSignerAddress,
+                Usage:   "Account the remote signer signs for",
+                EnvVars: []string{"REMOTE_SIGNER_ADDRESS"},
+            },
             &cli.Uint64Flag{
                 Name:    FlagOffset,
                 Usage:   "Offset is how many blocks ahead to bid for the preconf transaction",
                 EnvVars: []string{"OFFSET"},
-                Value:   1,
+            },
+            &cli.Uint64Flag{
+                Name:    FlagTargetBlockSpan,
+                Usage:   "Bid for this many consecutive target blocks, starting at the offset, with the same transaction",
+                EnvVars: []string{"TARGET_BLOCK_SPAN"},
             },
             &cli.Float64Flag{
                 Name:    FlagBidAmount,
                 Usage:   "Amount to bid (in ETH)",
                 EnvVars: []string{"BID_AMOUNT"},
-                Value:   0.001,
             },
             &cli.Float64Flag{
                 Name:    FlagBidAmountStdDevPercentage,
                 Usage:   "Standard deviation percentage for bid amount",
                 EnvVars: []string{"BID_AMOUNT_STD_DEV_PERCENTAGE"},
-                Value:   100.0,
+            },
+            &cli.Float64Flag{
+                Name:    FlagHourlyBudget,
+                Usage:   "Most ETH committed in bids within any hour (0 for no cap)",
+                EnvVars: []string{"HOURLY_BUDGET"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagDailyBudget,
+                Usage:   "Most ETH committed in bids within any 24 hours (0 for no cap)",
+                EnvVars: []string{"DAILY_BUDGET"},
+            },
+            &cli.StringFlag{
+                Name:    FlagBudgetMode,
+                Usage:   "What bids larger than the remaining budget do: stop (skip the block) or reduce (bid what is left)",
+                EnvVars: []string{"BUDGET_MODE"},
+            },
+            &cli.UintFlag{
+                Name:    FlagMaxBidsPerBlock,
+                Usage:   "Most bids per target block over all accounts and lanes (0 for no limit)",
+                EnvVars: []string{"MAX_BIDS_PER_BLOCK"},
             },
             &cli.UintFlag{
                 Name:    FlagNumBlob,
                 Usage:   "Number of blobs to send (0 for ETH transfer)",
                 EnvVars: []string{"NUM_BLOB"},
-                Value:   0,
+            },
+            &cli.StringFlag{
+                Name:    FlagKZGTrustedSetup,
+                Usage:   "KZG trusted setup JSON file blob commitments are computed with, loaded at startup; empty for the setup built into the binary",
+                EnvVars: []string{"KZG_TRUSTED_SETUP"},
+            },
+            &cli.StringFlag{
+                Name:    FlagTxType,
+                Usage:   "Transaction to bid with: transfer, blob, erc20, contract-call or raw (defaults to transfer or blob based on num-blob)",
+                EnvVars: []string{"TX_TYPE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagERC20Token,
+                Usage:   "ERC-20 token contract transferred by the erc20 transaction type",
+                EnvVars: []string{"ERC20_TOKEN"},
+            },
+            &cli.StringFlag{
+                Name:    FlagERC20Recipient,
+                Usage:   "Recipient of the erc20 transaction type",
+                EnvVars: []string{"ERC20_RECIPIENT"},
+            },
+            &cli.StringFlag{
+                Name:    FlagERC20Amount,
+                Usage:   "Amount transferred by the erc20 transaction type, in token base units",
+                EnvVars: []string{"ERC20_AMOUNT"},
+            },
+            &cli.StringFlag{
+                Name:    FlagTransferAmount,
+                Usage:   "Amount sent by transfer bids, in wei (default 0.001 ETH)",
+                EnvVars: []string{"TRANSFER_AMOUNT"},
+            },
+            &cli.StringFlag{
+                Name:    FlagTransferTo,
+                Usage:   "Recipient of transfer bids (defaults to the sending account)",
+                EnvVars: []string{"TRANSFER_TO"},
+            },
+            &cli.StringFlag{
+                Name:    FlagContractAddress,
+                Usage:   "Contract called by the contract-call transaction type",
+                EnvVars: []string{"CONTRACT_ADDRESS"},
+            },
+            &cli.StringFlag{
+                Name:    FlagContractABI,
+                Usage:   "ABI JSON file of the contract, used with --" + FlagContractMethod,
+                EnvVars: []string{"CONTRACT_ABI"},
+            },
+            &cli.StringFlag{
+                Name:    FlagContractMethod,
+                Usage:   "Method called by the contract-call transaction type",
+                EnvVars: []string{"CONTRACT_METHOD"},
+            },
+            &cli.StringFlag{
+                Name:    FlagContractArgs,
+                Usage:   "Comma-separated arguments of the contract method",
+                EnvVars: []string{"CONTRACT_ARGS"},
+            },
+            &cli.StringFlag{
+                Name:    FlagContractCalldata,
+                Usage:   "Raw calldata hex of the contract-call transaction type, instead of an ABI method",
+                EnvVars: []string{"CONTRACT_CALLDATA"},
+            },
+            &cli.StringFlag{
+                Name:    FlagRawTxFile,
+                Usage:   "File of pre-signed transactions, one hex per line, bid with by the raw transaction type",
+                EnvVars: []string{"RAW_TX_FILE"},
+            },
+            &cli.DurationFlag{
+                Name:    FlagDecayMin,
+                Usage:   "Shortest allowed bid decay window",
+                EnvVars: []string{"DECAY_MIN"},
+            },
+            &cli.DurationFlag{
+                Name:    FlagDecayMax,
+                Usage:   "Longest allowed bid decay window (0 for no maximum)",
+                EnvVars: []string{"DECAY_MAX"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagDecayClamp,
+                Usage:   "Clamp decay windows outside the allowed bounds instead of refusing to start",
+                EnvVars: []string{"DECAY_CLAMP"},
+            },
+            &cli.DurationFlag{
+                Name:    FlagBidSlotOffset,
+                Usage:   "Dispatch the bids for the latest block this long after the start of every slot, e.g. 200ms, instead of when it arrives (0 bids on arrival)",
+                EnvVars: []string{"BID_SLOT_OFFSET"},
+            },
+            &cli.Uint64Flag{
+                Name:    FlagGenesisTime,
+                Usage:   "Unix time of the beacon chain genesis the slots of --" + FlagBidSlotOffset + " count from (0 aligns them to the first block)",
+                EnvVars: []string{"GENESIS_TIME"},
+            },
+            &cli.StringFlag{
+                Name:    FlagTransferPrivateKey,
+                Usage:   "Private key of a second account sending ETH transfer bids alongside blob bids (optional)",
+                EnvVars: []string{"TRANSFER_PRIVATE_KEY"},
+            },
+            &cli.StringFlag{
+                Name:    FlagExtraPrivateKeys,
+                Usage:   "Comma-separated private keys of extra accounts bidding the same transactions as the primary account",
+                EnvVars: []string{"EXTRA_PRIVATE_KEYS"},
+                Hidden:  true,
+            },
+            &cli.StringFlag{
+                Name:    FlagExtraKeystorePaths,
+                Usage:   "Comma-separated keystore files of extra accounts, decrypted with the keystore password",
+                EnvVars: []string{"EXTRA_KEYSTORE_PATHS"},
+            },
+            &cli.StringFlag{
+                Name:    FlagAccountRotation,
+                Usage:   "How blocks are spread over the primary and extra accounts: round-robin or parallel",
+                EnvVars: []string{"ACCOUNT_ROTATION"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagNonceManager,
+                Usage:   "Issue nonces locally, so transactions built while earlier ones are in flight take the next nonce",
+                EnvVars: []string{"NONCE_MANAGER"},
+            },
+            &cli.DurationFlag{
+                Name:    FlagNonceResync,
+                Usage:   "How often the nonce manager syncs with the node's pending nonce",
+                EnvVars: []string{"NONCE_RESYNC"},
+            },
+            &cli.Uint64Flag{
+                Name:    FlagStaleTxReplacements,
+                Usage:   "How often the transaction of a nonce whose bid went without a commitment is bid on again with bumped fees, 0 to build a new one every block",
+                EnvVars: []string{"STALE_TX_REPLACEMENTS"},
+            },
+            &cli.Uint64Flag{
+                Name:    FlagReconcileBlocks,
+                Usage:   "How many recent blocks are scanned on startup for transactions of a previous run, 0 to skip",
+                EnvVars: []string{"RECONCILE_BLOCKS"},
             },
             &cli.UintFlag{
                 Name:    FlagDefaultTimeout,
                 Usage:   "Default timeout in seconds",
                 EnvVars: []string{"DEFAULT_TIMEOUT"},
-                Value:   15,
+            },
+            &cli.StringFlag{
+                Name:    FlagLatencyBudgets,
+                Usage:   "Per-dependency timeouts as name=duration pairs, e.g. nonce_fetch=1s,send_bid=8s (ws_subscribe, nonce_fetch, header_fetch, chain_id, gas_estimate, bundle_post, raw_tx_post, send_bid)",
+                EnvVars: []string{"LATENCY_BUDGETS"},
             },
             &cli.UintFlag{
                 Name:    FlagRunDurationMinutes,
                 Usage:   "Duration to run the bidder in minutes (0 to run indefinitely)",
                 EnvVars: []string{"RUN_DURATION_MINUTES"},
-                Value:   0,
+            },
+            &cli.BoolFlag{
+                Name:    FlagTUI,
+                Usage:   "Show a live status screen of the head block, last bids, deposits, connections and errors instead of scrolling logs",
+                EnvVars: []string{"TUI"},
+            },
+            &cli.StringFlag{
+                Name:    FlagLogFormat,
+                Usage:   "Format of log records: json (one object per line), text (key=value pairs) or pretty (indented JSON)",
+                EnvVars: []string{"LOG_FORMAT"},
+            },
+            &cli.StringFlag{
+                Name:    FlagLogLevel,
+                Usage:   "Lowest level of log records written: debug, info, warn or error",
+                EnvVars: []string{"LOG_LEVEL"},
+            },
+            &cli.StringFlag{
+                Name:    FlagLogFile,
+                Usage:   "Also append log records to this file, rotated by size and age",
+                EnvVars: []string{"LOG_FILE"},
+            },
+            &cli.Uint64Flag{
+                Name:    FlagLogMaxSizeMB,
+                Usage:   "Rotate the log file once it would grow past this many megabytes, 0 to never rotate by size",
+                EnvVars: []string{"LOG_MAX_SIZE_MB"},
+            },
+            &cli.DurationFlag{
+                Name:    FlagLogMaxAge,
+                Usage:   "Rotate the log file once it has been written to for this long, 0 to never rotate by age",
+                EnvVars: []string{"LOG_MAX_AGE"},
+            },
+            &cli.Uint64Flag{
+                Name:    FlagLogMaxBackups,
+                Usage:   "Rotated log files kept, the oldest are removed; 0 keeps every rotated file",
+                EnvVars: []string{"LOG_MAX_BACKUPS"},
+            },
+            &cli.Uint64Flag{
+                Name:    FlagLogSampleBurst,
+                Usage:   "Records of the same warning or error message written per sample interval before the rest are suppressed, 0 to write every record",
+                EnvVars: []string{"LOG_SAMPLE_BURST"},
+            },
+            &cli.DurationFlag{
+                Name:    FlagLogSampleInterval,
+                Usage:   "Interval the sample burst of repeated warnings and errors applies to",
+                EnvVars: []string{"LOG_SAMPLE_INTERVAL"},
+            },
+            &cli.StringFlag{
+                Name:    FlagOTLPEndpoint,
+                Usage:   "OTLP/HTTP endpoint URL the spans of the bidding path are exported to, e.g. http://localhost:4318; tracing is off when empty",
+                EnvVars: []string{"OTLP_ENDPOINT"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagTraceSampleRatio,
+                Usage:   "Share of headers whose bids are traced, between 0 and 1",
+                EnvVars: []string{"TRACE_SAMPLE_RATIO"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagDryRun,
+                Usage:   "Build and sign transactions and compute bids, but only log the bids, bundles and submissions instead of sending them; their raw transactions are only logged with --" + FlagRetainRawPayloads,
+                EnvVars: []string{"DRY_RUN"},
             },
             &cli.StringFlag{
                 Name:    FlagAppName,
                 Usage:   "Application name, for logging purposes",
                 EnvVars: []string{"APP_NAME"},
-                Value:   "preconf_bidder",
+            },
+            &cli.StringFlag{
+                Name:    FlagTenant,
+                Usage:   "Tenant the bidder runs for, added to every log record, metric and stored row of deployments shared between customers",
+                EnvVars: []string{"TENANT"},
             },
             &cli.StringFlag{
                 Name:    FlagVersion,
                 Usage:   "mev-commit version, for logging purposes",
                 EnvVars: []string{"VERSION"},
-                Value:   "0.8.0",
+            },
+            &cli.StringFlag{
+                Name:    FlagUserAgent,
+                Usage:   "User-Agent identifying this deployment on every HTTP, WebSocket and gRPC request (default app-name/version)",
+                EnvVars: []string{"USER_AGENT"},
+            },
+            &cli.StringFlag{
+                Name:    FlagRequestIDPrefix,
+                Usage:   "Prefix of the X-Request-ID sent with every request, e.g. the deployment name",
+                EnvVars: []string{"REQUEST_ID_PREFIX"},
             },
             &cli.Int64Flag{
                 Name:    FlagPriorityFeeGwei,
-                Usage:   "Priority fee in gwei",
+                Usage:   "Priority fee in gwei, tipped by the fixed gas oracle",
                 EnvVars: []string{"PRIORITY_FEE_GWEI"},
-                Value:   1,
             },
-        },
+            &cli.StringFlag{
+                Name:    FlagGasOracle,
+                Usage:   "How the priority fee is picked: fixed (priority-fee-gwei) or fee-history (a percentile of recent blocks' tips from eth_feeHistory)",
+                EnvVars: []string{"GAS_ORACLE"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagGasTipPercentile,
+                Usage:   "Percentile of recent blocks' priority fees tipped by the fee-history gas oracle, the target inclusion percentile",
+                EnvVars: []string{"GAS_TIP_PERCENTILE"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagMaxBaseFeeGwei,
+                Usage:   "Skip blocks whose base fee exceeds this many gwei (0 for no maximum)",
+                EnvVars: []string{"MAX_BASE_FEE_GWEI"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagFeeBump,
+                Usage:   "Factor the priority fee grows by each consecutive block the same nonce is bid for, between 1 and 2",
+                EnvVars: []string{"FEE_BUMP"},
+            },
+            &cli.StringFlag{
+                Name:    FlagBlobFeeSource,
+                Usage:   "Where the blob base fee comes from: header (computed from the head's excess blob gas) or rpc (eth_blobBaseFee)",
+                EnvVars: []string{"BLOB_FEE_SOURCE"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagBlobFeeBump,
+                Usage:   "Factor the blob fee cap exceeds the target block's expected blob base fee by, between 1 and 10",
+                EnvVars: []string{"BLOB_FEE_BUMP"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagMaxBlobFeeCapGwei,
+                Usage:   "Most gwei per blob gas a blob transaction offers (0 for no maximum)",
+                EnvVars: []string{"MAX_BLOB_FEE_CAP_GWEI"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagBlobFeeCeilingGwei,
+                Usage:   "Skip blocks whose blob base fee exceeds this many gwei (0 for no ceiling)",
+                EnvVars: []string{"BLOB_FEE_CEILING_GWEI"},
+            },
+            &cli.StringFlag{
+                Name:    FlagPayloadPrivacy,
+                Usage:   "How much of the transaction is disclosed in bids: payload, hash or commit-reveal (defaults to payload or hash based on use-payload)",
+                EnvVars: []string{"PAYLOAD_PRIVACY"},
+            },
+            &cli.StringFlag{
+                Name:    FlagSubmissionBackend,
+                Usage:   "Where transactions are sent: bidder (bid through the bidder node) or preconf-rpc (submit to the mev-commit preconf RPC, which bids for them)",
+                EnvVars: []string{"SUBMISSION_BACKEND"},
+            },
+            &cli.StringFlag{
+                Name:    FlagPreconfRPCEndpoint,
+                Usage:   "mev-commit preconf RPC endpoint used by the preconf-rpc submission backend",
+                EnvVars: []string{"PRECONF_RPC_ENDPOINT"},
+            },
+            &cli.Uint64Flag{
+                Name:    FlagPublicFallbackBlocks,
+                Usage:   "Broadcast an account's transaction to the public mempool after this many blocks in a row without a commitment, 0 to never broadcast",
+                EnvVars: []string{"PUBLIC_FALLBACK_BLOCKS"},
+            },
+            &cli.StringFlag{
+                Name:    FlagPublicRPCEndpoint,
+                Usage:   "Endpoint public mempool broadcasts are sent to with eth_sendRawTransaction (default the RPC endpoint)",
+                EnvVars: []string{"PUBLIC_RPC_ENDPOINT"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagAutoRollover,
+                Usage:   "Keep the bidding window funded and roll unused deposit from settled windows into the next window",
+                EnvVars: []string{"AUTO_ROLLOVER"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagDepositAmount,
+                Usage:   "Deposit to keep in each bidding window when auto-rollover is enabled (in ETH)",
+                EnvVars: []string{"DEPOSIT_AMOUNT"},
+            },
+            &cli.Uint64Flag{
+                Name:    FlagBlocksPerWindow,
+                Usage:   "Number of L1 blocks per mev-commit bidding window",
+                EnvVars: []string{"BLOCKS_PER_WINDOW"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagAutoWithdraw,
+                Usage:   "Withdraw the unused deposit of settled windows the bidder bid into",
+                EnvVars: []string{"AUTO_WITHDRAW"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagAutoWithdrawDryRun,
+                Usage:   "Only report the deposits auto-withdraw would withdraw",
+                EnvVars: []string{"AUTO_WITHDRAW_DRY_RUN"},
+            },
+            &cli.StringFlag{
+                Name:    FlagHALeaseFile,
+                Usage:   "Shared lease file enabling active/standby failover; only the lease holder bids",
+                EnvVars: []string{"HA_LEASE_FILE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagHAInstanceID,
+                Usage:   "Unique ID of this instance for the coordination lease (defaults to hostname-pid)",
+                EnvVars: []string{"HA_INSTANCE_ID"},
+            },
+            &cli.UintFlag{
+                Name:    FlagHALeaseTTL,
+                Usage:   "Coordination lease time-to-live in seconds; a standby takes over after it expires",
+                EnvVars: []string{"HA_LEASE_TTL"},
+            },
+            &cli.Int64Flag{
+                Name:    FlagSeed,
+                Usage:   "Seed for bid amount randomization (0 picks a random seed); recorded for replay",
+                EnvVars: []string{"SEED"},
+            },
+            &cli.StringFlag{
+                Name:    FlagRecordFile,
+                Usage:   "Append every bid decision with its seed and market inputs to this campaign file for later replay",
+                EnvVars: []string{"RECORD_FILE"},
+            },
+            &cli.Uint64Flag{
+                Name:    FlagStaleBidBlocks,
+                Usage:   "Blocks past its target block after which an unresolved bid is reaped and its stream closed",
+                EnvVars: []string{"STALE_BID_BLOCKS"},
+            },
+            &cli.StringFlag{
+                Name:    FlagNetwork,
+                Usage:   "mev-commit network whose contract addresses are used: " + strings.Join(bb.NetworkNames(), ", "),
+                EnvVars: []string{"NETWORK"},
+            },
+            &cli.StringFlag{
+                Name:    FlagContractsURL,
+                Usage:   "Contracts JSON endpoint overriding the network's official one",
+                EnvVars: []string{"CONTRACTS_URL"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagConfirmMainnet,
+                Usage:   "Confirm bidding on Ethereum mainnet; the bidder refuses to start there without it",
+                EnvVars: []string{"CONFIRM_MAINNET"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagAllowMainnetBlobs,
+                Usage:   "Allow bidding with random blob transactions on Ethereum mainnet",
+                EnvVars: []string{"ALLOW_MAINNET_BLOBS"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagTelemetry,
+                Usage:   "Opt in to periodic anonymized usage reports to the maintainers (off by default, see /telemetry on the status server)",
+                EnvVars: []string{"TELEMETRY"},
+            },
+            &cli.StringFlag{
+                Name:    FlagTelemetryEndpoint,
+                Usage:   "Endpoint receiving telemetry reports",
+                EnvVars: []string{"TELEMETRY_ENDPOINT"},
+            },
+            &cli.DurationFlag{
+                Name:    FlagTelemetryInterval,
+                Usage:   "How often a telemetry report is sent",
+                EnvVars: []string{"TELEMETRY_INTERVAL"},
+            },
+            &cli.DurationFlag{
+                Name:    FlagDrainTimeout,
+                Usage:   "How long to wait for in-flight bids on shutdown before canceling them",
+                EnvVars: []string{"DRAIN_TIMEOUT"},
+            },
+            &cli.Uint64Flag{
+                Name:    FlagMaxRSSMB,
+                Usage:   "Resident memory in MB above which optional work (market snapshots, competition observing, telemetry, dashboard sampling) is shed; 0 disables it",
+                EnvVars: []string{"MAX_RSS_MB"},
+            },
+            &cli.Uint64Flag{
+                Name:    FlagMaxGoroutines,
+                Usage:   "Goroutine count above which optional work is shed; 0 disables it",
+                EnvVars: []string{"MAX_GOROUTINES"},
+            },
+            &cli.StringFlag{
+                Name:    FlagConfigSnapshotFile,
+                Usage:   "File recording the configuration of the previous run, to log what changed (default in the user cache directory)",
+                EnvVars: []string{"CONFIG_SNAPSHOT_FILE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagStatusAddress,
+                Usage:   "Address of the HTTP status server serving per-account status on /accounts and Prometheus metrics on /metrics (disabled when empty)",
+                EnvVars: []string{"STATUS_ADDRESS"},
+            },
+            &cli.DurationFlag{
+                Name:    FlagStatusInterval,
+                Usage:   "How often to log the per-account status summary (0 disables it)",
+                EnvVars: []string{"STATUS_INTERVAL"},
+            },
+            &cli.StringFlag{
+                Name:    FlagDisableJobs,
+                Usage:   "Comma-separated maintenance jobs not to run: deposits, withdrawals, balances, settlement or compaction",
+                EnvVars: []string{"DISABLE_JOBS"},
+            },
+            &cli.StringFlag{
+                Name:    FlagStatusReadTokens,
+                Usage:   "Comma-separated bearer tokens allowed to view the status server; without tokens it is open to anyone who can reach it",
+                EnvVars: []string{"STATUS_READ_TOKENS"},
+                Hidden:  true,
+            },
+            &cli.StringFlag{
+                Name:    FlagStatusAdminTokens,
+                Usage:   "Comma-separated bearer tokens allowed to view the status server and use its controls",
+                EnvVars: []string{"STATUS_ADMIN_TOKENS"},
+                Hidden:  true,
+            },
+            &cli.StringFlag{
+                Name:    FlagControlAuditFile,
+                Usage:   "Append every change made through the admin API (parameters, pause and resume) to this JSON lines file",
+                EnvVars: []string{"CONTROL_AUDIT_FILE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagCampaignScheduleFile,
+                Usage:   "File keeping the scheduled campaigns; when set, bids are only made while a campaign runs (campaigns are managed on /campaigns of the status server)",
+                EnvVars: []string{"CAMPAIGN_SCHEDULE_FILE"},
+            },
+            &cli.DurationFlag{
+                Name:    FlagCampaignLead,
+                Usage:   "How long before a campaign's start its balances and deposit are first checked",
+                EnvVars: []string{"CAMPAIGN_LEAD"},
+            },
+            &cli.StringFlag{
+                Name:    FlagMevCommitWSEndpoint,
+                Usage:   "Websocket endpoint of the mev-commit chain used to observe competing commitments (optional)",
+                EnvVars: []string{"MEV_COMMIT_WS_ENDPOINT"},
+            },
+            &cli.StringFlag{
+                Name:    FlagClockSkewThreshold,
+                Usage:   "Estimated clock offset from chain time (e.g. 2s) above which skew is reported and compensated",
+                EnvVars: []string{"CLOCK_SKEW_THRESHOLD"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagClockCompensate,
+                Usage:   "Correct bid decay timestamps by the clock skew measured with --ntp-server; skew estimated from block timestamps is only logged",
+                EnvVars: []string{"CLOCK_COMPENSATE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagNTPServer,
+                Usage:   "Optional NTP server (e.g. pool.ntp.org) used instead of block timestamps to measure clock skew",
+                EnvVars: []string{"NTP_SERVER"},
+            },
+            &cli.StringFlag{
+                Name:    FlagStrategy,
+                Usage:   "Bid strategy: gaussian, adaptive, script, or a name registered by a strategy plugin",
+                EnvVars: []string{"STRATEGY"},
+            },
+            &cli.StringFlag{
+                Name:    FlagStrategyScript,
+                Usage:   "Expression for the script strategy returning the bid in ETH, or @path to read it from a file",
+                EnvVars: []string{"STRATEGY_SCRIPT"},
+            },
+            &cli.StringFlag{
+                Name:    FlagStrategyPlugin,
+                Usage:   "Comma separated Go plugin files (-buildmode=plugin) that register bid strategies",
+                EnvVars: []string{"STRATEGY_PLUGIN"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagAdaptiveTargetRate,
+                Usage:   "Share of bids the adaptive strategy aims to get committed, between 0 and 1",
+                EnvVars: []string{"ADAPTIVE_TARGET_RATE"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagAdaptiveStep,
+                Usage:   "How fast the adaptive strategy changes its bid scale after each bid",
+                EnvVars: []string{"ADAPTIVE_STEP"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagAdaptiveMinScale,
+                Usage:   "Lowest multiple of the bid amount the adaptive strategy bids",
+                EnvVars: []string{"ADAPTIVE_MIN_SCALE"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagAdaptiveMaxScale,
+                Usage:   "Highest multiple of the bid amount the adaptive strategy bids",
+                EnvVars: []string{"ADAPTIVE_MAX_SCALE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagProviderWeighting,
+                Usage:   "How the adaptive strategy counts commitments by their provider: none, stake, reliability or stake-reliability",
+                EnvVars: []string{"PROVIDER_WEIGHTING"},
+            },
+            &cli.Float64Flag{
+                Name:    FlagCanaryPercent,
+                Usage:   "Share of blocks (0-100) bid on with bidding parameters changed at runtime before they replace the current ones; 0 applies them at once",
+                EnvVars: []string{"CANARY_PERCENT"},
+            },
+            &cli.IntFlag{
+                Name:    FlagCanaryBlocks,
+                Usage:   "Canary bids whose outcomes are compared with the current parameters before promoting or rolling back",
+                EnvVars: []string{"CANARY_BLOCKS"},
+            },
+            &cli.StringFlag{
+                Name:    FlagBackrunTx,
+                Usage:   "Submit bundles as MEV-Share backruns placed right after this pending transaction hash",
+                EnvVars: []string{"BACKRUN_TX"},
+            },
+            &cli.StringFlag{
+                Name:    FlagBundleHints,
+                Usage:   "Builder specific eth_sendBundle fields as name=value pairs (e.g. ordering or top-of-block hints)",
+                EnvVars: []string{"BUNDLE_HINTS"},
+            },
+            &cli.StringFlag{
+                Name:    FlagBundleSearchKey,
+                Usage:   "Private key signing bundle requests for the X-Flashbots-Signature header; it needs no funds",
+                EnvVars: []string{"BUNDLE_SEARCH_KEY"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagBundleReplacement,
+                Usage:   "Send a replacementUuid per account and target block, so resubmitted bundles replace earlier ones",
+                EnvVars: []string{"BUNDLE_REPLACEMENT"},
+            },
+            &cli.DurationFlag{
+                Name:    FlagBundleValidity,
+                Usage:   "Bound bundles with minTimestamp and maxTimestamp to this long after submission, 0 to leave them open",
+                EnvVars: []string{"BUNDLE_VALIDITY"},
+            },
+            &cli.StringFlag{
+                Name:    FlagBundleRelays,
+                Usage:   "Further relays bundles are sent to along with the RPC endpoint, as name=url pairs (e.g. flashbots=https://relay.flashbots.net)",
+                EnvVars: []string{"BUNDLE_RELAYS"},
+            },
+            &cli.StringFlag{
+                Name:    FlagDisabledRelays,
+                Usage:   "Comma-separated names of relays bundles are not sent to, rpc for the RPC endpoint",
+                EnvVars: []string{"DISABLED_RELAYS"},
+            },
+            &cli.StringFlag{
+                Name:    FlagSubmitMethod,
+                Usage:   "How transactions are submitted to relays: bundle (eth_sendBundle) or private-tx (eth_sendPrivateRawTransaction)",
+                EnvVars: []string{"SUBMIT_METHOD"},
+            },
+            &cli.StringFlag{
+                Name:    FlagPrivateTxPrefs,
+                Usage:   "Preferences of private transactions as name=value pairs (e.g. fast=true)",
+                EnvVars: []string{"PRIVATE_TX_PREFERENCES"},
+            },
+            &cli.StringFlag{
+                Name:    FlagActivityFile,
+                Usage:   "Append L1 transactions, bid payments, deposits and withdrawals to this ledger for export-activity",
+                EnvVars: []string{"ACTIVITY_FILE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagSkipLogFile,
+                Usage:   "Append every skipped block, with its reason, and hourly skip summaries to this JSON lines file",
+                EnvVars: []string{"SKIP_LOG_FILE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagConflictLogFile,
+                Usage:   "Append every commitment conflicting with another committed transaction of the same nonce to this JSON lines file",
+                EnvVars: []string{"CONFLICT_LOG_FILE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagOwnTxFile,
+                Usage:   "Keep the hashes of sent transactions in this JSON lines file, so observed commitments and inclusions are still told apart as ours after a restart",
+                EnvVars: []string{"OWN_TX_FILE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagDisputeFile,
+                Usage:   "Append the evidence of every commitment of ours whose transactions were missing from the target block to this JSON lines file",
+                EnvVars: []string{"DISPUTE_FILE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagDisputeEndpoint,
+                Usage:   "Post the evidence of every commitment of ours whose transactions were missing from the target block to this reporting endpoint",
+                EnvVars: []string{"DISPUTE_ENDPOINT"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagInclusionTracking,
+                Usage:   "Check every bid's target block for its transaction, and count preconfirmations honored and violated by provider",
+                EnvVars: []string{"INCLUSION_TRACKING"},
+            },
+            &cli.StringFlag{
+                Name:    FlagInclusionLogFile,
+                Usage:   "Append every bid whose inclusion disagrees with its commitments to this JSON lines file",
+                EnvVars: []string{"INCLUSION_LOG_FILE"},
+            },
+            &cli.StringFlag{
+                Name:    FlagBidHistoryFile,
+                Usage:   "Record every bid, its bidder node response and commitment status in this SQLite database for the history command",
+                EnvVars: []string{"BID_HISTORY_FILE"},
+            },
+            &cli.DurationFlag{
+                Name:    FlagBidHistoryRetention,
+                Usage:   "How long bids are kept in the bid history before being compacted into hourly aggregates (0 keeps them all)",
+                EnvVars: []string{"BID_HISTORY_RETENTION"},
+            },
+            &cli.BoolFlag{
+                Name:    FlagRetainRawPayloads,
+                Usage:   "Keep raw transaction bytes in logs and records (by default only the tx hash and size are retained)",
+                EnvVars: []string{"RETAIN_RAW_PAYLOADS"},
+            },
+        }),
     }
 
-    if err := app.Run(os.Args); err != nil {
+    // SIGINT and SIGTERM cancel the root context; the bidder then drains in-flight bids
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    err := app.RunContext(ctx, os.Args)
+    stop()
+    if err != nil {
         slog.Error("Application error", "error", err)
         os.Exit(1)
     }
 }
-
-// CustomJSONHandler is a custom slog.Handler that formats logs as pretty-printed JSON with customized timestamp
-type CustomJSONHandler struct {
-	encoder *json.Encoder
-	level   slog.Level
-}
-
-// NewCustomJSONHandler creates a new instance of CustomJSONHandler
-func NewCustomJSONHandler(w io.Writer, level slog.Level) *CustomJSONHandler {
-	encoder := json.NewEncoder(w)
-	encoder.SetIndent("", "  ") // Set indentation for pretty-printing
-	return &CustomJSONHandler{
-		encoder: encoder,
-		level:   level,
-	}
-}
-
-// Handle processes each log record
-func (h *CustomJSONHandler) Handle(ctx context.Context, r slog.Record) error {
-	if r.Level < h.level {
-		return nil // Skip logs below the set level
-	}
-
-	// Create a map to hold the log entry
-	logEntry := make(map[string]interface{})
-
-	// Customize the timestamp to include only milliseconds
-	logEntry["time"] = r.Time.Format("2006-01-02T15:04:05.000Z07:00") // RFC3339 with milliseconds
-
-	// Set the log level
-	logEntry["level"] = r.Level.String()
-
-	// Set the message
-	logEntry["msg"] = r.Message
-
-	// Add all other attributes
-	r.Attrs(func(attr slog.Attr) bool {
-		logEntry[attr.Key] = attr.Value.Any()
-		return true
-	})
-
-	// Encode the log entry as pretty JSON
-	return h.encoder.Encode(logEntry)
-}
-
-// Enabled checks if the handler is enabled for the given level
-func (h *CustomJSONHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return level >= h.level
-}
-
-// WithAttrs returns a new handler with the given attributes
-func (h *CustomJSONHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	// Create a new handler and copy attributes if necessary
-	// Since we're retaining field names, we don't need to handle attrs specially here
-	return h
-}
-
-// WithGroup returns a new handler with the given group name
-func (h *CustomJSONHandler) WithGroup(name string) slog.Handler {
-	// Groups can be handled if needed, but for simplicity, we ignore them here
-	return h
-}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/primev/preconf_blob_bidder/internal/config"
+	ee "github.com/primev/preconf_blob_bidder/internal/eth"
+	"github.com/primev/preconf_blob_bidder/internal/ident"
+	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	FlagPingAmount           = "amount"
+	FlagPingInclusionTimeout = "inclusion-timeout"
+)
+
+// pingResult is the end-to-end result of a ping bid, printed as JSON.
+type pingResult struct {
+	Address           string   `json:"address"`
+	TxHash            string   `json:"tx_hash"`
+	TargetBlock       uint64   `json:"target_block"`
+	AmountWei         string   `json:"amount_wei"`
+	Sent              bool     `json:"sent"`
+	Error             string   `json:"error,omitempty"`
+	StreamMs          int64    `json:"stream_ms"`
+	FirstCommitmentMs int64    `json:"first_commitment_ms,omitempty"`
+	Commitments       int      `json:"commitments"`
+	Providers         []string `json:"providers,omitempty"`
+	Included          bool     `json:"included"`
+	IncludedBlock     uint64   `json:"included_block,omitempty"`
+}
+
+// pingBidCommand sends one minimal bid for the next block and reports how it
+// went end to end, from the bidder node accepting it to inclusion. It is the
+// connectivity test to run, and attach, when asking for support.
+func pingBidCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ping-bid",
+		Usage: "Send a minimal bid for the next block and report stream latency, commitments and inclusion",
+		Flags: []cli.Flag{
+			&cli.Float64Flag{
+				Name:  FlagPingAmount,
+				Usage: "Bid amount in ETH",
+				Value: 0.000001,
+			},
+			&cli.DurationFlag{
+				Name:  FlagPingInclusionTimeout,
+				Usage: "How long to wait for the transaction to be included after the target block",
+				Value: 3 * bb.SlotDuration,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := loadConfig(c)
+			if err != nil {
+				return err
+			}
+			decay, err := cfg.DecayBounds().Apply(bb.DecayWindow(1))
+			if err != nil {
+				return err
+			}
+
+			client, err := ident.DialEth(c.Context, bb.ParseWSEndpoints(cfg.WSEndpoint)[0])
+			if err != nil {
+				return fmt.Errorf("failed to connect to the WebSocket endpoint: %w", err)
+			}
+			defer client.Close()
+			chainID, err := client.ChainID(c.Context)
+			if err != nil {
+				return fmt.Errorf("failed to fetch the chain ID: %w", err)
+			}
+			if chainID.Int64() == config.MainnetChainID {
+				// A ping bid pays for a real bid and transaction on mainnet
+				if err := cfg.CheckMainnet(); err != nil {
+					return err
+				}
+			}
+			account, err := openPrimaryAccount(c.Context, cfg, chainID)
+			if err != nil {
+				return err
+			}
+			defer account.Close()
+
+			bidderClient, err := bb.NewBidderClient(cfg.BidderConfig())
+			if err != nil {
+				return fmt.Errorf("failed to connect to mev-commit bidder API: %w", err)
+			}
+			defer bidderClient.Close()
+
+			tx, target, err := ee.SelfETHTransfer(ee.NewClient(client), account.Signer, big.NewInt(0), 1, cfg.FeeOracle())
+			if err != nil {
+				return fmt.Errorf("failed to build the ping transaction: %w", err)
+			}
+			result := pingResult{
+				Address:     account.Address.Hex(),
+				TxHash:      tx.Hash().Hex(),
+				TargetBlock: target,
+			}
+			report := bb.SendPreconfBidReport(c.Context, bidderClient, tx, int64(target), c.Float64(FlagPingAmount), decay)
+			result.AmountWei, result.Sent = report.Amount, report.Sent
+			result.StreamMs = report.Stream.Milliseconds()
+			result.FirstCommitmentMs = report.FirstCommitment.Milliseconds()
+			result.Commitments = len(report.Commitments)
+			for _, commitment := range report.Commitments {
+				result.Providers = append(result.Providers, commitment.GetProviderAddress())
+			}
+			if report.Err != nil {
+				result.Error = report.Err.Error()
+			}
+			if report.Sent {
+				result.IncludedBlock, err = awaitInclusion(c.Context, client, tx.Hash(), target, c.Duration(FlagPingInclusionTimeout), inclusionPollInterval)
+				if err != nil {
+					slog.Warn("Failed to check inclusion", "error", err, "txHash", result.TxHash)
+				}
+				result.Included = result.IncludedBlock > 0
+			}
+
+			enc := json.NewEncoder(c.App.Writer)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(result); err != nil {
+				return err
+			}
+			return result.exit()
+		},
+	}
+}
+
+// exit returns the exit error of the ping bid: it fails unless the bid was
+// sent and at least one provider committed to it.
+func (r pingResult) exit() error {
+	switch {
+	case !r.Sent:
+		return cli.Exit("the bidder node did not accept the ping bid", 1)
+	case r.Commitments == 0:
+		return cli.Exit("no provider committed to the ping bid", 1)
+	}
+	return nil
+}
+
+// inclusionPollInterval is how often a ping bid's inclusion is checked.
+const inclusionPollInterval = 2 * time.Second
+
+// receiptReader is the part of the Ethereum client awaitInclusion uses.
+type receiptReader interface {
+	TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// awaitInclusion polls every interval until the transaction hash is included,
+// or timeout after the target block, and returns the block it was included
+// in, or 0.
+func awaitInclusion(ctx context.Context, client receiptReader, hash common.Hash, target uint64, timeout, interval time.Duration) (uint64, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var deadline time.Time
+	for {
+		receipt, err := client.TransactionReceipt(ctx, hash)
+		switch {
+		case err == nil:
+			return receipt.BlockNumber.Uint64(), nil
+		case !errors.Is(err, ethereum.NotFound):
+			return 0, err
+		}
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if head >= target && deadline.IsZero() {
+			deadline = time.Now().Add(timeout)
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return 0, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// fakeChain includes a transaction once its head reaches included, and
+// advances the head by one block each time it is read.
+type fakeChain struct {
+	mu       sync.Mutex
+	head     uint64
+	included uint64 // 0 never includes it
+	err      error
+	reads    int
+}
+
+func (f *fakeChain) TransactionReceipt(context.Context, common.Hash) (*types.Receipt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.included != 0 && f.head >= f.included {
+		return &types.Receipt{BlockNumber: new(big.Int).SetUint64(f.included)}, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (f *fakeChain) BlockNumber(context.Context) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reads++
+	f.head++
+	return f.head, nil
+}
+
+func TestAwaitInclusion(t *testing.T) {
+	ctx := context.Background()
+	chain := &fakeChain{head: 10, included: 13}
+	block, err := awaitInclusion(ctx, chain, common.Hash{}, 12, time.Minute, time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, uint64(13), block, "not found is polled until included")
+}
+
+func TestAwaitInclusionDeadline(t *testing.T) {
+	ctx := context.Background()
+	chain := &fakeChain{head: 10}
+	start := time.Now()
+	block, err := awaitInclusion(ctx, chain, common.Hash{}, 100, 20*time.Millisecond, time.Millisecond)
+	require.NoError(t, err)
+	require.Zero(t, block)
+	require.GreaterOrEqual(t, chain.reads, 90, "the deadline starts at the target block")
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestAwaitInclusionError(t *testing.T) {
+	chain := &fakeChain{head: 10, err: errors.New("connection reset")}
+	_, err := awaitInclusion(context.Background(), chain, common.Hash{}, 12, time.Minute, time.Millisecond)
+	require.ErrorContains(t, err, "connection reset")
+	require.Zero(t, chain.reads)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = awaitInclusion(ctx, &fakeChain{head: 10}, common.Hash{}, 12, time.Minute, time.Millisecond)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestPingResultExit(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		result pingResult
+		msg    string
+	}{
+		{"not sent", pingResult{Sent: false}, "the bidder node did not accept the ping bid"},
+		{"not sent with commitments", pingResult{Sent: false, Commitments: 1}, "the bidder node did not accept the ping bid"},
+		{"no commitments", pingResult{Sent: true}, "no provider committed to the ping bid"},
+	} {
+		err := tt.result.exit()
+		var exit cli.ExitCoder
+		require.ErrorAs(t, err, &exit, tt.name)
+		require.Equal(t, 1, exit.ExitCode(), tt.name)
+		require.EqualError(t, err, tt.msg, tt.name)
+	}
+	require.NoError(t, pingResult{Sent: true, Commitments: 2}.exit())
+}
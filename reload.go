@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/primev/preconf_blob_bidder/internal/config"
+	"github.com/primev/preconf_blob_bidder/internal/control"
+	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+	"github.com/primev/preconf_blob_bidder/internal/strategy"
+)
+
+// reloadable are the settings a reload applies to the running bidder.
+// Changes to any other setting are logged and take effect on the next start.
+var reloadable = map[string]bool{
+	"bid_amount":                    true,
+	"bid_amount_std_dev_percentage": true,
+	"offset":                        true,
+	"decay_min":                     true,
+	"decay_max":                     true,
+	"decay_clamp":                   true,
+	"rpc_endpoint":                  true,
+	"preconf_rpc_endpoint":          true,
+}
+
+// reloader applies the configuration to the running bidder again on SIGHUP.
+type reloader struct {
+	load         func() (config.Config, error)
+	strategyName string
+	span         int // Target blocks per transaction.
+	privacy      bb.PayloadPrivacy
+	controls     *control.Controller
+	dispatcher   *bidDispatcher
+	decayBounds  *atomic.Pointer[bb.DecayBounds]
+
+	// running is the configuration in effect: as loaded at startup, with the
+	// reloadable settings of later reloads.
+	running config.Config
+}
+
+// Run reloads the configuration on every SIGHUP until ctx is canceled.
+func (r *reloader) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if err := r.reload(); err != nil {
+				slog.Error("Configuration reload failed, keeping the running configuration", "error", err)
+			}
+		}
+	}
+}
+
+// reload loads the configuration and, once every reloadable change has been
+// validated, applies them all.
+func (r *reloader) reload() error {
+	next, err := r.load()
+	if err != nil {
+		return err
+	}
+	changes := config.Diff(r.running.Snapshot(), next.Snapshot())
+	if len(changes) == 0 {
+		slog.Info("Configuration reloaded, nothing changed")
+		return nil
+	}
+
+	var update control.Update
+	var applied []config.Change
+	for _, ch := range changes {
+		if !reloadable[ch.Key] {
+			previous, current := logValues(ch)
+			slog.Warn("Configuration changed, restart to apply", "setting", ch.Key, "previous", previous, "current", current)
+			continue
+		}
+		applied = append(applied, ch)
+		switch ch.Key {
+		case "bid_amount":
+			update.BidAmount = &next.BidAmount
+		case "bid_amount_std_dev_percentage":
+			update.StdDevPercentage = &next.StdDevPercentage
+		case "offset":
+			update.Offset = &next.Offset
+		}
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+
+	// Validate before anything is swapped, so a reload applies all or nothing.
+	// Settings the reload leaves alone keep their running value, which may
+	// have been changed through the admin API.
+	params := r.controls.State().Params
+	if update.BidAmount != nil {
+		params.BidAmount = *update.BidAmount
+	}
+	if update.StdDevPercentage != nil {
+		params.StdDevPercentage = *update.StdDevPercentage
+	}
+	if update.Offset != nil {
+		params.Offset = *update.Offset
+	}
+	bounds := next.DecayBounds()
+	if _, err := decayWindows(bounds, params.Offset, r.span); err != nil {
+		return err
+	}
+	if !r.privacy.SendsRawPayload() && next.RPCEndpoint == "" {
+		return errors.New("rpc_endpoint is required by the payload privacy mode " + string(r.privacy))
+	}
+	if _, err := strategy.New(r.strategyName, params); err != nil {
+		return err
+	}
+
+	// The controls check a new offset against the decay bounds, so the new
+	// bounds are in place while they apply, and restored if they fail
+	previousBounds := r.decayBounds.Swap(&bounds)
+	if _, err := r.controls.Apply(update, "config reload", "SIGHUP"); err != nil {
+		r.decayBounds.Store(previousBounds)
+		return err
+	}
+	r.dispatcher.setEndpoints(next.RPCEndpoint, next.PreconfRPCEndpoint)
+	for _, ch := range applied {
+		previous, current := logValues(ch)
+		slog.Info("Configuration reloaded", "setting", ch.Key, "previous", previous, "current", current)
+	}
+	r.running.BidAmount, r.running.StdDevPercentage, r.running.Offset = next.BidAmount, next.StdDevPercentage, next.Offset
+	r.running.DecayMin, r.running.DecayMax, r.running.DecayClamp = next.DecayMin, next.DecayMax, next.DecayClamp
+	r.running.RPCEndpoint, r.running.PreconfRPCEndpoint = next.RPCEndpoint, next.PreconfRPCEndpoint
+	return nil
+}
+
+// logValues returns the previous and current value of ch as logged, with
+// endpoints masked.
+func logValues(ch config.Change) (previous, current string) {
+	if strings.HasSuffix(ch.Key, "_endpoint") {
+		return bb.MaskEndpoint(ch.Previous), bb.MaskEndpoint(ch.Current)
+	}
+	return ch.Previous, ch.Current
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/primev/preconf_blob_bidder/internal/config"
+	"github.com/primev/preconf_blob_bidder/internal/control"
+	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+	"github.com/primev/preconf_blob_bidder/internal/strategy"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestReloader returns a reloader running running that loads *next, and
+// whose controls check offsets against the decay bounds and then check.
+func newTestReloader(t *testing.T, running config.Config, next *config.Config, check func(uint64) error) *reloader {
+	t.Helper()
+	bounds := new(atomic.Pointer[bb.DecayBounds])
+	start := running.DecayBounds()
+	bounds.Store(&start)
+	params := strategy.Params{BidAmount: running.BidAmount, StdDevPercentage: running.StdDevPercentage, Offset: running.Offset}
+	controls, err := control.New(params, control.Config{
+		CheckOffset: func(o uint64) error {
+			if _, err := decayWindows(*bounds.Load(), o, 1); err != nil {
+				return err
+			}
+			if check != nil {
+				return check(o)
+			}
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { controls.Close() })
+	dispatcher := &bidDispatcher{}
+	dispatcher.setEndpoints(running.RPCEndpoint, running.PreconfRPCEndpoint)
+	return &reloader{
+		load:         func() (config.Config, error) { return *next, nil },
+		strategyName: "gaussian",
+		span:         1,
+		privacy:      bb.PrivacyPayload,
+		controls:     controls,
+		dispatcher:   dispatcher,
+		decayBounds:  bounds,
+		running:      running,
+	}
+}
+
+func testConfig() config.Config {
+	cfg := config.Default()
+	cfg.BidAmount, cfg.Offset = 0.001, 1
+	cfg.DecayMin, cfg.DecayMax, cfg.DecayClamp = time.Second, 0, false
+	cfg.RPCEndpoint = "http://old.example"
+	return cfg
+}
+
+func TestReloadRejected(t *testing.T) {
+	running := testConfig()
+	next := running
+	next.Offset, next.DecayMax = 3, 48*time.Second // 60s decay window
+	next.RPCEndpoint = "http://new.example"
+	r := newTestReloader(t, running, &next, nil)
+
+	require.ErrorIs(t, r.reload(), bb.ErrDecayOutOfBounds)
+	require.Equal(t, uint64(1), r.controls.Offset())
+	require.Equal(t, running.DecayBounds(), *r.decayBounds.Load())
+	rpc, _ := r.dispatcher.endpoints()
+	require.Equal(t, "http://old.example", rpc)
+	require.Equal(t, running, r.running)
+}
+
+func TestReloadApplyFails(t *testing.T) {
+	running := testConfig()
+	next := running
+	next.Offset, next.DecayMax = 2, 48*time.Second
+	next.RPCEndpoint = "http://new.example"
+	r := newTestReloader(t, running, &next, func(uint64) error { return errors.New("changed concurrently") })
+
+	require.ErrorContains(t, r.reload(), "changed concurrently")
+	require.Equal(t, uint64(1), r.controls.Offset())
+	require.Equal(t, running.DecayBounds(), *r.decayBounds.Load(), "the bounds are restored")
+	rpc, _ := r.dispatcher.endpoints()
+	require.Equal(t, "http://old.example", rpc)
+	require.Equal(t, running, r.running)
+}
+
+func TestReloadKeepsAdminOffset(t *testing.T) {
+	running := testConfig()
+	next := running
+	r := newTestReloader(t, running, &next, nil)
+	offset := uint64(3) // 60s decay window
+	_, err := r.controls.Apply(control.Update{Offset: &offset}, "admin", "")
+	require.NoError(t, err)
+
+	// The file still has offset 1, but the bounds must hold for the offset running
+	next.DecayMax = 48 * time.Second
+	require.ErrorIs(t, r.reload(), bb.ErrDecayOutOfBounds)
+	require.Equal(t, running.DecayBounds(), *r.decayBounds.Load())
+
+	next.DecayMax = 72 * time.Second
+	next.RPCEndpoint = "http://new.example"
+	require.NoError(t, r.reload())
+	require.Equal(t, uint64(3), r.controls.Offset(), "the admin offset is kept")
+	require.Equal(t, next.DecayBounds(), *r.decayBounds.Load())
+	rpc, _ := r.dispatcher.endpoints()
+	require.Equal(t, "http://new.example", rpc)
+}
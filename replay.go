@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/primev/preconf_blob_bidder/internal/campaign"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	FlagReplayFile   = "file"
+	FlagReplayPlugin = "strategy-plugin"
+)
+
+// replayCommand replays a recorded campaign against the current decision
+// logic and reports every decision that changed.
+func replayCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "replay",
+		Usage: "Replay a recorded campaign against the current strategy code and diff the decisions",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     FlagReplayFile,
+				Usage:    "Campaign file written with --record-file",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  FlagReplayPlugin,
+				Usage: "Comma separated strategy plugins needed by the recorded strategies",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if err := loadStrategyPlugins(c.String(FlagReplayPlugin)); err != nil {
+				return err
+			}
+
+			f, err := os.Open(c.String(FlagReplayFile))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			records, err := campaign.ReadRecords(f)
+			if err != nil {
+				return fmt.Errorf("failed to read campaign: %w", err)
+			}
+
+			report, err := campaign.Replay(records)
+			if err != nil {
+				return err
+			}
+
+			enc := json.NewEncoder(c.App.Writer)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return err
+			}
+			if len(report.Diffs) > 0 {
+				return cli.Exit(fmt.Sprintf("%d of %d decisions changed", len(report.Diffs), report.Total), 1)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/primev/preconf_blob_bidder/internal/apispec"
+	"github.com/urfave/cli/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	FlagSchemaFormat = "format"
+	FlagSchemaOut    = "out"
+
+	schemaFormatOpenAPI        = "openapi"
+	schemaFormatDescriptorSet  = "descriptor-set"
+	schemaFormatDescriptorJSON = "descriptor-json"
+)
+
+// schemaCommand exports the API schemas of the bidder so integrators can
+// generate their own clients.
+func schemaCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "schema",
+		Usage: "Export OpenAPI and protobuf schemas for the APIs used and exposed by the bidder",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  FlagSchemaFormat,
+				Usage: "Output format: openapi, descriptor-set (binary FileDescriptorSet) or descriptor-json",
+				Value: schemaFormatOpenAPI,
+			},
+			&cli.StringFlag{
+				Name:  FlagSchemaOut,
+				Usage: "Write the schema to this file instead of stdout",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			version := c.String(FlagVersion)
+
+			var out []byte
+			var err error
+			switch c.String(FlagSchemaFormat) {
+			case schemaFormatOpenAPI:
+				out, err = apiDocument(version).JSON()
+			case schemaFormatDescriptorSet:
+				if c.String(FlagSchemaOut) == "" {
+					return fmt.Errorf("--%s is required for the binary %s format", FlagSchemaOut, schemaFormatDescriptorSet)
+				}
+				out, err = apispec.BidderDescriptorSet()
+			case schemaFormatDescriptorJSON:
+				out, err = protojson.MarshalOptions{Multiline: true}.Marshal(
+					apispec.DescriptorSet(apispec.BidderService().ParentFile()),
+				)
+			default:
+				return fmt.Errorf("unknown schema format %q", c.String(FlagSchemaFormat))
+			}
+			if err != nil {
+				return fmt.Errorf("failed to render schema: %w", err)
+			}
+
+			if path := c.String(FlagSchemaOut); path != "" {
+				return os.WriteFile(path, out, 0o644)
+			}
+			_, err = fmt.Fprintln(c.App.Writer, string(out))
+			return err
+		},
+	}
+}
+
+// apiDocument assembles the OpenAPI document covering every HTTP API the
+// bidder talks to or serves.
+func apiDocument(version string) *apispec.Document {
+	doc := apispec.New("Preconf Bidder", version)
+	doc.Info.Description = "HTTP APIs used and exposed by the preconf bidder. " +
+		"The Status tag documents the status server of the bidder, also served on /openapi.json, " +
+		"and the Bidder tag the mev-commit bidder node gateway the bot connects to."
+	doc.Merge(statusAPI(version))
+	doc.Merge(apispec.FromService("mev-commit bidder", version, apispec.BidderService()))
+	return doc
+}
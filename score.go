@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/primev/preconf_blob_bidder/internal/pricing"
+	"github.com/primev/preconf_blob_bidder/internal/strategy"
+	"github.com/primev/preconf_blob_bidder/internal/strategy/strategytest"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	FlagScoreStrategy = "strategy"
+	FlagScoreBid      = "bid-amount"
+	FlagScoreStdDev   = "std-dev-percentage"
+	FlagScoreScript   = "script"
+	FlagScorePlugin   = "strategy-plugin"
+	FlagScoreSeed     = "seed"
+	FlagScoreJSON     = "json"
+)
+
+// scoreCommand runs strategies through the synthetic market scenarios of the
+// strategy test kit and prints their score reports side by side.
+func scoreCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "score",
+		Usage: "Score bid strategies on synthetic high competition, low competition and fee spike markets",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  FlagScoreStrategy,
+				Usage: "Comma separated strategies to score",
+				Value: "gaussian,adaptive",
+			},
+			&cli.Float64Flag{
+				Name:  FlagScoreBid,
+				Usage: "Bid amount in ETH",
+				Value: 0.0015,
+			},
+			&cli.Float64Flag{
+				Name:  FlagScoreStdDev,
+				Usage: "Standard deviation percentage of the gaussian strategy",
+				Value: 100.0,
+			},
+			&cli.StringFlag{
+				Name:  FlagScoreScript,
+				Usage: "Expression for the script strategy, or @path to read it from a file",
+			},
+			&cli.StringFlag{
+				Name:  FlagScorePlugin,
+				Usage: "Comma separated strategy plugins registering the strategies to score",
+			},
+			&cli.Int64Flag{
+				Name:  FlagScoreSeed,
+				Usage: "Seed of the random source of the strategies",
+				Value: strategytest.DefaultSeed,
+			},
+			&cli.BoolFlag{
+				Name:  FlagScoreJSON,
+				Usage: "Print the reports as JSON",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if err := loadStrategyPlugins(c.String(FlagScorePlugin)); err != nil {
+				return err
+			}
+			params := strategy.Params{
+				BidAmount:        c.Float64(FlagScoreBid),
+				StdDevPercentage: c.Float64(FlagScoreStdDev),
+				Script:           c.String(FlagScoreScript),
+			}
+			// Scored with the default controller, as the bidder runs it
+			pricingCfg := pricing.Config{
+				TargetRate: pricing.DefaultTargetRate,
+				Step:       pricing.DefaultStep,
+				MinScale:   pricing.DefaultMinScale,
+				MaxScale:   pricing.DefaultMaxScale,
+			}
+
+			var reports []strategytest.Report
+			for _, name := range strings.Split(c.String(FlagScoreStrategy), ",") {
+				name = strings.TrimSpace(name)
+				s, err := strategy.New(name, params)
+				if err != nil {
+					return err
+				}
+				opts := strategytest.Options{Seed: c.Int64(FlagScoreSeed)}
+				if name == "adaptive" {
+					opts.Pricing = &pricingCfg
+				}
+				scored, err := strategytest.RunAll(s, opts)
+				if err != nil {
+					return err
+				}
+				reports = append(reports, scored...)
+			}
+
+			if c.Bool(FlagScoreJSON) {
+				enc := json.NewEncoder(c.App.Writer)
+				enc.SetIndent("", "  ")
+				return enc.Encode(reports)
+			}
+			w := tabwriter.NewWriter(c.App.Writer, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "STRATEGY\tSCENARIO\tWON\tWIN RATE\tSPEND ETH\tOVERPAY ETH\tMEAN BID ETH\tCOST PER WIN ETH")
+			for _, r := range reports {
+				fmt.Fprintf(w, "%s\t%s\t%d/%d\t%.1f%%\t%.6f\t%.6f\t%.6f\t%.6f\n",
+					r.Strategy, r.Scenario, r.Won, r.Blocks, r.WinRate*100, r.SpendETH, r.OverpayETH, r.MeanBidETH, r.CostPerWinETH)
+			}
+			return w.Flush()
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/primev/preconf_blob_bidder/internal/config"
+	ee "github.com/primev/preconf_blob_bidder/internal/eth"
+	bb "github.com/primev/preconf_blob_bidder/internal/mevcommit"
+)
+
+// Where the primary account signs from, in order of precedence.
+const (
+	sourceRemoteSigner = "remote signer"
+	sourceKeystore     = "keystore"
+	sourcePrivateKey   = "private key"
+)
+
+// errNoSigner is returned when no primary account is configured.
+var errNoSigner = errors.New("requires PRIVATE_KEY, KEYSTORE_PATH or REMOTE_SIGNER_URL")
+
+// primaryAccount is the bidder's primary account and the signer bids from it
+// are signed with.
+type primaryAccount struct {
+	bb.AuthAcct
+	Signer ee.Signer
+	// Remote is the remote signer, nil when the key is held in process.
+	Remote *ee.RemoteSigner
+	Source string
+}
+
+// signerSource returns where cfg has the primary account sign from, or "".
+func signerSource(cfg config.Config) string {
+	switch {
+	case cfg.RemoteSignerURL != "":
+		return sourceRemoteSigner
+	case cfg.KeystorePath != "":
+		return sourceKeystore
+	case cfg.PrivateKey != "":
+		return sourcePrivateKey
+	}
+	return ""
+}
+
+// openPrimaryAccount opens the bidder's primary account from the remote
+// signer, the keystore or the private key, in that order. The transaction
+// options are set for chainID, and left nil without one. Close releases the
+// account.
+func openPrimaryAccount(ctx context.Context, cfg config.Config, chainID *big.Int) (*primaryAccount, error) {
+	source := signerSource(cfg)
+	if source == sourceRemoteSigner {
+		kind, _ := ee.ParseRemoteSignerKind(cfg.RemoteSignerKind)
+		signer, err := ee.NewRemoteSigner(ctx, cfg.RemoteSignerURL, kind, common.HexToAddress(cfg.RemoteSignerAddress))
+		if err != nil {
+			return nil, err
+		}
+		if err := signer.Check(ctx); err != nil {
+			signer.Close()
+			return nil, fmt.Errorf("remote signer check failed: %w", err)
+		}
+		acct := bb.AuthAcct{Address: signer.Address()}
+		if chainID != nil {
+			acct.Auth = ee.TransactOpts(signer, chainID)
+		}
+		return &primaryAccount{AuthAcct: acct, Signer: signer, Remote: signer, Source: source}, nil
+	}
+
+	var (
+		key *ecdsa.PrivateKey
+		err error
+	)
+	switch source {
+	case sourceKeystore:
+		password, perr := cfg.ReadKeystorePassword()
+		if perr != nil {
+			return nil, perr
+		}
+		key, err = bb.LoadKeystore(cfg.KeystorePath, password)
+	case sourcePrivateKey:
+		key, err = crypto.HexToECDSA(strings.TrimPrefix(cfg.PrivateKey, "0x"))
+		if err != nil {
+			err = fmt.Errorf("private key is invalid: %w", err)
+		}
+	default:
+		return nil, errNoSigner
+	}
+	if err != nil {
+		return nil, err
+	}
+	acct := bb.AuthAcct{PrivateKey: key, PublicKey: &key.PublicKey, Address: crypto.PubkeyToAddress(key.PublicKey)}
+	if chainID != nil {
+		if acct, err = bb.NewAuthAcct(key, chainID); err != nil {
+			return nil, fmt.Errorf("failed to authenticate %s: %w", source, err)
+		}
+	}
+	return &primaryAccount{AuthAcct: acct, Signer: ee.NewLocalSigner(key), Source: source}, nil
+}
+
+// Close releases the remote signer, if any.
+func (a *primaryAccount) Close() {
+	if a.Remote != nil {
+		a.Remote.Close()
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+	"github.com/primev/preconf_blob_bidder/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+// accountsAPI serves web3signer's account list.
+type accountsAPI struct{ address common.Address }
+
+func (a accountsAPI) Accounts() []common.Address { return []common.Address{a.address} }
+
+func TestOpenPrimaryAccount(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	chainID := big.NewInt(17000)
+
+	_, err = openPrimaryAccount(ctx, config.Default(), chainID)
+	require.ErrorIs(t, err, errNoSigner)
+
+	cfg := config.Default()
+	cfg.PrivateKey = "0x" + common.Bytes2Hex(crypto.FromECDSA(key))
+	account, err := openPrimaryAccount(ctx, cfg, chainID)
+	require.NoError(t, err)
+	require.Equal(t, sourcePrivateKey, account.Source)
+	require.Equal(t, address, account.Address)
+	require.Equal(t, address, account.Signer.Address())
+	require.NotNil(t, account.Auth)
+	require.Nil(t, account.Remote)
+	account, err = openPrimaryAccount(ctx, cfg, nil)
+	require.NoError(t, err)
+	require.Nil(t, account.Auth, "no transaction options without a chain ID")
+
+	cfg.PrivateKey = "0x1234"
+	_, err = openPrimaryAccount(ctx, cfg, chainID)
+	require.ErrorContains(t, err, "private key is invalid")
+
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{Id: uuid.New(), Address: address, PrivateKey: key},
+		"s3cret", keystore.LightScryptN, keystore.LightScryptP)
+	require.NoError(t, err)
+	cfg.KeystorePath = filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(cfg.KeystorePath, keyJSON, 0o600))
+	cfg.KeystorePassword = "s3cret"
+	account, err = openPrimaryAccount(ctx, cfg, chainID)
+	require.NoError(t, err, "the keystore takes precedence over the private key")
+	require.Equal(t, sourceKeystore, account.Source)
+	require.Equal(t, address, account.Address)
+
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", accountsAPI{address}))
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	cfg.RemoteSignerURL, cfg.RemoteSignerKind, cfg.RemoteSignerAddress = ts.URL, "web3signer", address.Hex()
+	account, err = openPrimaryAccount(ctx, cfg, chainID)
+	require.NoError(t, err, "the remote signer takes precedence over the keystore")
+	defer account.Close()
+	require.Equal(t, sourceRemoteSigner, account.Source)
+	require.Equal(t, address, account.Address)
+	require.NotNil(t, account.Remote)
+	require.NotNil(t, account.Auth)
+
+	cfg.RemoteSignerAddress = common.Address{1}.Hex()
+	_, err = openPrimaryAccount(ctx, cfg, chainID)
+	require.ErrorContains(t, err, "holds no key")
+}
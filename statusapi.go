@@ -0,0 +1,242 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/primev/preconf_blob_bidder/internal/apispec"
+	"github.com/primev/preconf_blob_bidder/internal/canary"
+	"github.com/primev/preconf_blob_bidder/internal/control"
+	"github.com/primev/preconf_blob_bidder/internal/dashboard"
+	"github.com/primev/preconf_blob_bidder/internal/health"
+	"github.com/primev/preconf_blob_bidder/internal/labels"
+	"github.com/primev/preconf_blob_bidder/internal/schedule"
+	"github.com/primev/preconf_blob_bidder/internal/skips"
+	"github.com/primev/preconf_blob_bidder/internal/status"
+	"github.com/primev/preconf_blob_bidder/internal/stream"
+	"github.com/primev/preconf_blob_bidder/internal/telemetry"
+)
+
+// Security schemes of the status server, see package auth.
+const (
+	securityReadOnly = "readOnlyToken"
+	securityAdmin    = "adminToken"
+)
+
+// statusTag groups the operations of the status server.
+const statusTag = "Status"
+
+// statusAPI describes the HTTP API served on --status-address: probes,
+// status, logs, events, runtime controls, campaigns and the dashboard.
+func statusAPI(version string) *apispec.Document {
+	doc := apispec.New("Preconf Bidder status server", version)
+	doc.AddTag(statusTag, "HTTP API served by the bidder on --"+FlagStatusAddress+".")
+	doc.Components.SecuritySchemes = map[string]*apispec.SecurityScheme{
+		securityReadOnly: {
+			Type: "http", Scheme: "bearer",
+			Description: "A --" + FlagStatusReadTokens + " token; an admin token is accepted too. The token may also be passed as the token query parameter. Without any tokens configured every request is served.",
+		},
+		securityAdmin: {
+			Type: "http", Scheme: "bearer",
+			Description: "A --" + FlagStatusAdminTokens + " token. The token may also be passed as the token query parameter.",
+		},
+	}
+	doc.AddSchema("Error", &apispec.Schema{
+		Type:       "object",
+		Properties: map[string]*apispec.Schema{"error": {Type: "string"}},
+		Required:   []string{"error"},
+	})
+	errorResponse := func(description string) *apispec.Response {
+		return &apispec.Response{Description: description, Content: apispec.JSONBody(apispec.Ref("Error"))}
+	}
+	object := func(field string, schema *apispec.Schema) *apispec.Schema {
+		return &apispec.Schema{Type: "object", Properties: map[string]*apispec.Schema{field: schema}, Required: []string{field}}
+	}
+	text := func(description string) *apispec.Response {
+		return &apispec.Response{Description: description, Content: map[string]*apispec.MediaType{
+			"text/plain": {Schema: &apispec.Schema{Type: "string"}},
+		}}
+	}
+	jsonResponse := func(description string, schema *apispec.Schema) map[string]*apispec.Response {
+		return map[string]*apispec.Response{"200": {Description: description, Content: apispec.JSONBody(schema)}}
+	}
+	add := func(method, path string, scope string, op *apispec.Operation) {
+		op.Tags = []string{statusTag}
+		switch scope {
+		case securityReadOnly:
+			op.Security = []map[string][]string{{securityReadOnly: {}}, {securityAdmin: {}}}
+		case securityAdmin:
+			op.Security = []map[string][]string{{securityAdmin: {}}}
+		}
+		if op.Responses == nil {
+			op.Responses = map[string]*apispec.Response{}
+		}
+		if scope != "" {
+			op.Responses["401"] = text("Missing or unknown token.")
+		}
+		if scope == securityAdmin {
+			op.Responses["403"] = text("The token is read-only.")
+		}
+		doc.AddOperation(method, path, op)
+	}
+
+	add(http.MethodGet, "/healthz", "", &apispec.Operation{
+		OperationID: "healthz",
+		Summary:     "Liveness probe",
+		Responses: map[string]*apispec.Response{
+			"200": text("Head blocks keep arriving."),
+			"503": text("No head block for " + health.DefaultLiveAfter.String() + "."),
+		},
+	})
+	add(http.MethodGet, "/readyz", "", &apispec.Operation{
+		OperationID: "readyz",
+		Summary:     "Readiness probe",
+		Responses: map[string]*apispec.Response{
+			"200": text("The bidder can bid."),
+			"503": text("Why the bidder cannot bid, one problem per line."),
+		},
+	})
+	add(http.MethodGet, "/status", securityReadOnly, &apispec.Operation{
+		OperationID: "status",
+		Summary:     "What the bidder is doing",
+		Responses:   jsonResponse("The status.", doc.SchemaOf(health.Status{})),
+	})
+	add(http.MethodGet, "/accounts", securityReadOnly, &apispec.Operation{
+		OperationID: "accounts",
+		Summary:     "Nonce, balance and bids of every bidding account",
+		Responses:   jsonResponse("The accounts.", object("accounts", doc.SchemaOf([]status.Account{}))),
+	})
+	add(http.MethodGet, "/metrics", securityReadOnly, &apispec.Operation{
+		OperationID: "metrics",
+		Summary:     "Prometheus metrics",
+		Description: "Metrics in the Prometheus text exposition format, labeled with the tenant and campaign.",
+		Responses:   map[string]*apispec.Response{"200": text("The metrics.")},
+	})
+	add(http.MethodGet, "/logs", securityReadOnly, &apispec.Operation{
+		OperationID: "logs",
+		Summary:     "Recent log records, oldest first",
+		Parameters: []apispec.Parameter{
+			{Name: labels.CampaignKey, In: "query", Description: "Only records of this campaign.", Schema: &apispec.Schema{Type: "string"}},
+			{Name: labels.TenantKey, In: "query", Description: "Only records of this tenant.", Schema: &apispec.Schema{Type: "string"}},
+			{Name: "level", In: "query", Description: "Only records at this level or above.", Schema: &apispec.Schema{Type: "string", Enum: []string{"debug", "info", "warn", "error"}}},
+			{Name: "limit", In: "query", Description: "At most the last this many records.", Schema: &apispec.Schema{Type: "integer", Format: "int64"}},
+		},
+		Responses: map[string]*apispec.Response{
+			"200": {Description: "The records.", Content: apispec.JSONBody(doc.SchemaOf([]labels.Entry{}))},
+			"400": text("Invalid level or limit."),
+		},
+	})
+	doc.SchemaOf(stream.Bid{})
+	doc.SchemaOf(stream.Settlement{})
+	doc.SchemaOf(skips.Record{})
+	add(http.MethodGet, "/events", securityReadOnly, &apispec.Operation{
+		OperationID: "events",
+		Summary:     "Server-sent events of the bid lifecycle",
+		Description: "Each event is sent with its id, its type as the event name and the JSON event as data. " +
+			"The data of " + stream.BidSkipped + " is a skips_Record, of " + stream.BidSent + " and " + stream.BidResolved +
+			" a stream_Bid and of " + stream.BidSettled + " a stream_Settlement.",
+		Parameters: []apispec.Parameter{{
+			Name: "types", In: "query",
+			Description: "Comma-separated event types to stream, all by default: " + strings.Join(stream.Types, ", ") + ".",
+			Schema:      &apispec.Schema{Type: "string"},
+		}},
+		Responses: map[string]*apispec.Response{
+			"200": {Description: "The event stream.", Content: map[string]*apispec.MediaType{
+				"text/event-stream": {Schema: doc.SchemaOf(stream.Event{})},
+			}},
+			"400": text("Unknown event type."),
+		},
+	})
+	add(http.MethodGet, "/canary", securityReadOnly, &apispec.Operation{
+		OperationID: "canary",
+		Summary:     "Running or last finished canary of new bid parameters",
+		Responses:   jsonResponse("The canary, null if there was none.", object("canary", doc.SchemaOf(canary.Report{}))),
+	})
+	add(http.MethodGet, "/control", securityReadOnly, &apispec.Operation{
+		OperationID: "getControl",
+		Summary:     "Bid parameters set at runtime and whether bidding is paused",
+		Responses:   jsonResponse("The control state.", doc.SchemaOf(control.State{})),
+	})
+	controlResponses := func() map[string]*apispec.Response {
+		r := jsonResponse("The control state after the change.", doc.SchemaOf(control.State{}))
+		r["400"] = errorResponse("Invalid body or parameters.")
+		return r
+	}
+	add(http.MethodPatch, "/control/params", securityAdmin, &apispec.Operation{
+		OperationID: "setControlParams",
+		Summary:     "Change the bid amount, deviation or offset",
+		Description: "Only the parameters set are changed. Unknown fields are rejected.",
+		RequestBody: &apispec.RequestBody{Required: true, Content: apispec.JSONBody(doc.SchemaOf(control.Update{}))},
+		Responses:   controlResponses(),
+	})
+	add(http.MethodPost, "/control/pause", securityAdmin, &apispec.Operation{
+		OperationID: "pause",
+		Summary:     "Pause bidding",
+		RequestBody: &apispec.RequestBody{Content: apispec.JSONBody(&apispec.Schema{
+			Type:       "object",
+			Properties: map[string]*apispec.Schema{"reason": {Type: "string"}},
+		})},
+		Responses: controlResponses(),
+	})
+	add(http.MethodPost, "/control/resume", securityAdmin, &apispec.Operation{
+		OperationID: "resume",
+		Summary:     "Resume bidding",
+		Responses:   jsonResponse("The control state after the change.", doc.SchemaOf(control.State{})),
+	})
+	add(http.MethodGet, "/telemetry", securityReadOnly, &apispec.Operation{
+		OperationID: "telemetry",
+		Summary:     "Telemetry reporter and the last report sent",
+		Responses:   jsonResponse("The telemetry status.", object("telemetry", doc.SchemaOf(telemetry.Status{}))),
+	})
+	const campaignsServed = "Only served with --" + FlagCampaignScheduleFile + "."
+	add(http.MethodGet, "/campaigns", securityReadOnly, &apispec.Operation{
+		OperationID: "listCampaigns",
+		Summary:     "Scheduled campaigns",
+		Description: campaignsServed,
+		Responses:   jsonResponse("The campaigns.", object("campaigns", doc.SchemaOf([]schedule.Campaign{}))),
+	})
+	add(http.MethodPost, "/campaigns", securityAdmin, &apispec.Operation{
+		OperationID: "addCampaign",
+		Summary:     "Schedule a campaign",
+		Description: campaignsServed,
+		RequestBody: &apispec.RequestBody{Required: true, Content: apispec.JSONBody(doc.SchemaOf(schedule.Campaign{}))},
+		Responses: map[string]*apispec.Response{
+			"201": {Description: "The scheduled campaign.", Content: apispec.JSONBody(doc.SchemaOf(schedule.Campaign{}))},
+			"400": errorResponse("Invalid campaign."),
+		},
+	})
+	add(http.MethodDelete, "/campaigns/{id}", securityAdmin, &apispec.Operation{
+		OperationID: "cancelCampaign",
+		Summary:     "Cancel a campaign",
+		Description: campaignsServed,
+		Parameters:  []apispec.Parameter{{Name: "id", In: "path", Required: true, Schema: &apispec.Schema{Type: "string"}}},
+		Responses: map[string]*apispec.Response{
+			"200": {Description: "The canceled campaign.", Content: apispec.JSONBody(doc.SchemaOf(schedule.Campaign{}))},
+			"404": errorResponse("No such campaign."),
+			"409": errorResponse("The campaign has already finished or was canceled."),
+		},
+	})
+	add(http.MethodGet, "/dashboard/data", securityReadOnly, &apispec.Operation{
+		OperationID: "dashboardData",
+		Summary:     "Recent metric samples and connections shown on the dashboard",
+		Responses:   jsonResponse("The dashboard data.", doc.SchemaOf(dashboard.Data{})),
+	})
+	add(http.MethodGet, "/openapi.json", "", &apispec.Operation{
+		OperationID: "openapi",
+		Summary:     "This document",
+		Responses:   jsonResponse("The OpenAPI document of every HTTP API of the bidder.", &apispec.Schema{Type: "object"}),
+	})
+	return doc
+}
+
+// serveAPIDocument serves the OpenAPI document of the bidder's HTTP APIs.
+func serveAPIDocument(version string) (http.Handler, error) {
+	raw, err := apiDocument(version).JSON()
+	if err != nil {
+		return nil, err
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(raw)
+	}), nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/primev/preconf_blob_bidder/internal/apispec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusAPIScopes(t *testing.T) {
+	doc := statusAPI("test")
+
+	// Probes and the document itself are served without a token
+	for _, path := range []string{"/healthz", "/readyz", "/openapi.json"} {
+		require.Empty(t, doc.Paths[path].Get.Security, path)
+	}
+	require.Equal(t, []map[string][]string{{securityReadOnly: {}}, {securityAdmin: {}}}, doc.Paths["/status"].Get.Security)
+	for path, op := range map[string]*apispec.Operation{
+		"/control/params": doc.Paths["/control/params"].Patch,
+		"/control/pause":  doc.Paths["/control/pause"].Post,
+		"/campaigns":      doc.Paths["/campaigns"].Post,
+		"/campaigns/{id}": doc.Paths["/campaigns/{id}"].Delete,
+	} {
+		require.Equal(t, []map[string][]string{{securityAdmin: {}}}, op.Security, path)
+		require.Contains(t, op.Responses, "403", path)
+	}
+	require.Equal(t, "#/components/schemas/control_Update",
+		doc.Paths["/control/params"].Patch.RequestBody.Content["application/json"].Schema.Ref)
+	require.Contains(t, doc.Components.Schemas, "health_Status")
+	require.Contains(t, doc.Components.Schemas, "stream_Bid")
+}
+
+func TestServeAPIDocument(t *testing.T) {
+	handler, err := serveAPIDocument("test")
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var doc struct {
+		Paths map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	// The status server and the bidder node gateway are both described
+	require.Contains(t, doc.Paths, "/status")
+	require.Contains(t, doc.Paths, "/dashboard/data")
+	require.Contains(t, doc.Paths, "/v1/bidder/bid")
+}
@@ -0,0 +1,202 @@
+// Package statusclient is a Go client of the bidder's status server, the API
+// described on its /openapi.json. Read-only calls need a read-only or admin
+// token, the controls and campaign changes an admin token.
+package statusclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/primev/preconf_blob_bidder/internal/canary"
+	"github.com/primev/preconf_blob_bidder/internal/control"
+	"github.com/primev/preconf_blob_bidder/internal/dashboard"
+	"github.com/primev/preconf_blob_bidder/internal/health"
+	"github.com/primev/preconf_blob_bidder/internal/labels"
+	"github.com/primev/preconf_blob_bidder/internal/schedule"
+	"github.com/primev/preconf_blob_bidder/internal/status"
+	"github.com/primev/preconf_blob_bidder/internal/telemetry"
+)
+
+// Client calls a status server.
+type Client struct {
+	base  string
+	token string
+	http  *http.Client
+}
+
+// New returns a client of the status server at base, such as
+// http://localhost:8080, presenting token if it is not empty. A nil
+// httpClient uses http.DefaultClient.
+func New(base, token string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{base: strings.TrimSuffix(base, "/"), token: token, http: httpClient}
+}
+
+// Error is a response with an unexpected status.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("status server answered %d: %s", e.StatusCode, e.Message)
+}
+
+// LogQuery filters the records returned by Logs; zero values do not filter.
+type LogQuery struct {
+	Campaign string
+	Tenant   string
+	Level    string
+	Limit    int
+}
+
+// Status returns what the bidder is doing.
+func (c *Client) Status(ctx context.Context) (health.Status, error) {
+	var out health.Status
+	return out, c.do(ctx, http.MethodGet, "/status", nil, http.StatusOK, &out)
+}
+
+// Accounts returns the bidding accounts.
+func (c *Client) Accounts(ctx context.Context) ([]status.Account, error) {
+	var out struct {
+		Accounts []status.Account `json:"accounts"`
+	}
+	return out.Accounts, c.do(ctx, http.MethodGet, "/accounts", nil, http.StatusOK, &out)
+}
+
+// Logs returns the recent log records matching q, oldest first.
+func (c *Client) Logs(ctx context.Context, q LogQuery) ([]labels.Entry, error) {
+	v := url.Values{}
+	for key, value := range map[string]string{labels.CampaignKey: q.Campaign, labels.TenantKey: q.Tenant, "level": q.Level} {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	path := "/logs"
+	if len(v) > 0 {
+		path += "?" + v.Encode()
+	}
+	var out []labels.Entry
+	return out, c.do(ctx, http.MethodGet, path, nil, http.StatusOK, &out)
+}
+
+// Canary returns the running or last finished canary, nil if there was none.
+func (c *Client) Canary(ctx context.Context) (*canary.Report, error) {
+	var out struct {
+		Canary *canary.Report `json:"canary"`
+	}
+	return out.Canary, c.do(ctx, http.MethodGet, "/canary", nil, http.StatusOK, &out)
+}
+
+// Control returns the bid parameters set at runtime and the pause.
+func (c *Client) Control(ctx context.Context) (control.State, error) {
+	var out control.State
+	return out, c.do(ctx, http.MethodGet, "/control", nil, http.StatusOK, &out)
+}
+
+// SetParams changes the parameters set in u.
+func (c *Client) SetParams(ctx context.Context, u control.Update) (control.State, error) {
+	var out control.State
+	return out, c.do(ctx, http.MethodPatch, "/control/params", u, http.StatusOK, &out)
+}
+
+// Pause pauses bidding for reason, which may be empty.
+func (c *Client) Pause(ctx context.Context, reason string) (control.State, error) {
+	var out control.State
+	body := struct {
+		Reason string `json:"reason,omitempty"`
+	}{reason}
+	return out, c.do(ctx, http.MethodPost, "/control/pause", body, http.StatusOK, &out)
+}
+
+// Resume resumes bidding.
+func (c *Client) Resume(ctx context.Context) (control.State, error) {
+	var out control.State
+	return out, c.do(ctx, http.MethodPost, "/control/resume", nil, http.StatusOK, &out)
+}
+
+// Telemetry returns the telemetry reporter's status.
+func (c *Client) Telemetry(ctx context.Context) (telemetry.Status, error) {
+	var out struct {
+		Telemetry telemetry.Status `json:"telemetry"`
+	}
+	return out.Telemetry, c.do(ctx, http.MethodGet, "/telemetry", nil, http.StatusOK, &out)
+}
+
+// Campaigns returns the scheduled campaigns.
+func (c *Client) Campaigns(ctx context.Context) ([]schedule.Campaign, error) {
+	var out struct {
+		Campaigns []schedule.Campaign `json:"campaigns"`
+	}
+	return out.Campaigns, c.do(ctx, http.MethodGet, "/campaigns", nil, http.StatusOK, &out)
+}
+
+// AddCampaign schedules campaign and returns it as registered.
+func (c *Client) AddCampaign(ctx context.Context, campaign schedule.Campaign) (schedule.Campaign, error) {
+	var out schedule.Campaign
+	return out, c.do(ctx, http.MethodPost, "/campaigns", campaign, http.StatusCreated, &out)
+}
+
+// CancelCampaign cancels the campaign id.
+func (c *Client) CancelCampaign(ctx context.Context, id string) (schedule.Campaign, error) {
+	var out schedule.Campaign
+	return out, c.do(ctx, http.MethodDelete, "/campaigns/"+url.PathEscape(id), nil, http.StatusOK, &out)
+}
+
+// Dashboard returns the samples and connections shown on the dashboard.
+func (c *Client) Dashboard(ctx context.Context) (dashboard.Data, error) {
+	var out dashboard.Data
+	return out, c.do(ctx, http.MethodGet, "/dashboard/data", nil, http.StatusOK, &out)
+}
+
+// do sends body as JSON, if not nil, and decodes a response of status into
+// out.
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, want int, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != want {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		msg := strings.TrimSpace(string(raw))
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(raw, &e) == nil && e.Error != "" {
+			msg = e.Error
+		}
+		return &Error{StatusCode: resp.StatusCode, Message: msg}
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,66 @@
+package statusclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/primev/preconf_blob_bidder/internal/auth"
+	"github.com/primev/preconf_blob_bidder/internal/control"
+	"github.com/primev/preconf_blob_bidder/internal/strategy"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	readToken  = "read-token-0123456789"
+	adminToken = "admin-token-0123456789"
+)
+
+func TestClientControls(t *testing.T) {
+	controls, err := control.New(strategy.Params{BidAmount: 0.001, StdDevPercentage: 100, Offset: 1}, control.Config{})
+	require.NoError(t, err)
+	guard := auth.NewGuard([]string{readToken}, []string{adminToken})
+	mux := http.NewServeMux()
+	mux.Handle("GET /control", guard.Require(auth.ReadOnly, http.HandlerFunc(controls.ServeState)))
+	mux.Handle("PATCH /control/params", guard.Require(auth.Admin, http.HandlerFunc(controls.ServeParams)))
+	mux.Handle("POST /control/pause", guard.Require(auth.Admin, http.HandlerFunc(controls.ServePause)))
+	mux.Handle("POST /control/resume", guard.Require(auth.Admin, http.HandlerFunc(controls.ServeResume)))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	ctx := context.Background()
+
+	admin := New(srv.URL+"/", adminToken, srv.Client())
+	amount := 0.002
+	state, err := admin.SetParams(ctx, control.Update{BidAmount: &amount})
+	require.NoError(t, err)
+	require.Equal(t, 0.002, state.Params.BidAmount)
+
+	state, err = admin.Pause(ctx, "maintenance")
+	require.NoError(t, err)
+	require.NotNil(t, state.Paused)
+	require.Equal(t, "maintenance", state.Paused.Reason)
+
+	reader := New(srv.URL, readToken, srv.Client())
+	state, err = reader.Control(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, state.Paused)
+
+	// A read-only token cannot use the controls
+	_, err = reader.Resume(ctx)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, http.StatusForbidden, e.StatusCode)
+
+	// Errors of the controls are decoded from their JSON body
+	offset := uint64(0)
+	_, err = admin.SetParams(ctx, control.Update{Offset: &offset})
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, http.StatusBadRequest, e.StatusCode)
+	require.Equal(t, "offset must be at least 1", e.Message)
+
+	state, err = admin.Resume(ctx)
+	require.NoError(t, err)
+	require.Nil(t, state.Paused)
+}